@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/addon"
+	"github.com/spf13/cobra"
+)
+
+var (
+	applyAddonOutput  string
+	applyAddonName    string
+	applyAddonVersion string
+)
+
+// applyAddonCmd merges an addon export into a base faction export.
+var applyAddonCmd = &cobra.Command{
+	Use:   "apply-addon <base-faction-dir> <addon-dir>",
+	Short: "Merge an addon export into the faction it extends",
+	Long: `Combine a base faction folder and an addon folder (both produced by
+describe-faction) into a new faction folder, so consumers get one dataset
+instead of stitching the addon in at runtime.
+
+The addon's units are added to the base faction's (an addon unit with the same
+ID replaces the base one) and the build tree is recomputed over all of them:
+build relationships from each unit's buildableTypes, then accessibility, tech
+paths, build menus and assist economy from the base faction's commanders.
+Addon units the base faction still can't reach are left out; they belong to
+another faction the addon extends. Unit files, the background image and
+credits from both folders are copied.
+
+Both folders must use the same --layout. Run apply-addon once per base
+faction to merge an addon into several.`,
+	Example: `  pa-pedia apply-addon ./factions/MLA ./factions/Second-Wave
+  pa-pedia apply-addon ./factions/Legion ./factions/Second-Wave --name "Legion (Second Wave)" --output ./merged`,
+	Args: cobra.ExactArgs(2),
+	RunE: runApplyAddon,
+}
+
+func init() {
+	rootCmd.AddCommand(applyAddonCmd)
+
+	applyAddonCmd.Flags().StringVar(&applyAddonOutput, "output", "./factions", "Output directory for the merged faction folder")
+	applyAddonCmd.Flags().StringVar(&applyAddonName, "name", "", "Display name of the merged faction (default \"<base> + <addon>\")")
+	applyAddonCmd.Flags().StringVar(&applyAddonVersion, "version", "", "Version of the merged faction (default the base faction's)")
+}
+
+func runApplyAddon(cmd *cobra.Command, args []string) error {
+	result, err := addon.Apply(args[0], args[1], applyAddonOutput, addon.Options{
+		DisplayName: applyAddonName,
+		Version:     applyAddonVersion,
+	})
+	if err != nil {
+		return err
+	}
+
+	printStatus("✓ Added %d addon unit(s) (%d units in total)\n", len(result.Added), result.Units)
+	if len(result.Replaced) > 0 {
+		printStatus("⚠ %d base unit(s) replaced by the addon's: %s\n", len(result.Replaced), strings.Join(result.Replaced, ", "))
+	}
+	if len(result.Dropped) > 0 {
+		fmt.Printf("%d addon unit(s) aren't buildable in this faction and were left out\n", len(result.Dropped))
+		logVerbose("Left out: %s", strings.Join(result.Dropped, ", "))
+	}
+	if err := warnReferenceIssues(result.FactionDir); err != nil {
+		return err
+	}
+	fmt.Printf("Merged faction written to: %s\n", result.FactionDir)
+	return nil
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/fixtures"
+	"github.com/jamiemulcahy/pa-pedia/pkg/i18n"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var assertTolerance float64
+
+// assertCmd checks an exported faction against committed per-unit fixtures
+var assertCmd = &cobra.Command{
+	Use:   "assert <fixtures-dir> <faction-dir>",
+	Short: "Fail when an export's unit stats drift from committed fixtures",
+	Long: `Compare a faction folder produced by describe-faction against fixtures
+written earlier with describe-faction --fixtures, for a mod's CI: commit the
+fixtures next to the mod, export on every change and run assert.
+
+Each fixture is a small <unit-id>.json holding the unit's tier and headline
+stats (dps, health, cost, speed). A stat drifts when it differs from its
+fixture by more than --tolerance percent (0, the default, requires an exact
+match to 2 decimal places). The command exits with an error when any stat
+drifted or a unit with a fixture is no longer exported. Exported units
+without a fixture are listed but don't fail the run; regenerate the fixtures
+with --fixtures to accept them, or any intended change.
+
+With --json the result is printed as {"checked", "drift": [{"id",
+"displayName", "stat", "expected", "actual"}], "missing", "new"}.`,
+	Example: `  pa-pedia describe-faction --profile my-mod --fixtures ./test/fixtures
+  pa-pedia assert ./test/fixtures ./factions/MyMod
+  pa-pedia assert ./test/fixtures ./factions/MyMod --tolerance 1 --json`,
+	Args: cobra.ExactArgs(2),
+	RunE: runAssert,
+}
+
+func init() {
+	rootCmd.AddCommand(assertCmd)
+	addJSONFlag(assertCmd)
+
+	assertCmd.Flags().Float64Var(&assertTolerance, "tolerance", 0, "Allowed difference from each fixture, as a percentage of the expected value")
+}
+
+func runAssert(cmd *cobra.Command, args []string) error {
+	if assertTolerance < 0 {
+		return fmt.Errorf("--tolerance must be 0 or more, got %v", assertTolerance)
+	}
+	expected, err := fixtures.Read(args[0])
+	if err != nil {
+		return fmt.Errorf("%w\n\nWrite fixtures with describe-faction --fixtures %s", err, args[0])
+	}
+	if len(expected) == 0 {
+		return fmt.Errorf("no fixtures in %s\n\nWrite them with describe-faction --fixtures %s", args[0], args[0])
+	}
+	_, units, err := loadDiffSide(args[1])
+	if err != nil {
+		return err
+	}
+
+	result := fixtures.Check(expected, units, assertTolerance)
+	if jsonOutput {
+		if err := printJSON(result); err != nil {
+			return err
+		}
+	} else {
+		for _, d := range result.Drift {
+			printStatus("✗ %s\n", d.Describe())
+		}
+		for _, u := range result.Missing {
+			printStatus("✗ %s: has a fixture but isn't exported\n", u.ID)
+		}
+		for _, u := range result.New {
+			printStatus("⚠ %s: no fixture\n", u.ID)
+		}
+	}
+	if result.Failed() {
+		return i18n.Errorf("%d stat(s) drifted and %d unit(s) missing in %s\n\nRegenerate the fixtures with describe-faction --fixtures if the changes are intended", len(result.Drift), len(result.Missing), args[1])
+	}
+	if !jsonOutput {
+		printStatus("✓ %d units match their fixtures\n", result.Checked)
+	}
+	return nil
+}
+
+// writeFixtures writes the accessible units' fixtures to --fixtures
+func writeFixtures(units []models.Unit) error {
+	var accessible []*models.Unit
+	for i := range units {
+		if units[i].Accessible && !units[i].BaseTemplate {
+			accessible = append(accessible, &units[i])
+		}
+	}
+	n, err := fixtures.Write(fixturesDir, accessible)
+	if err != nil {
+		return err
+	}
+	logVerbose("Wrote %d fixtures to %s", n, fixturesDir)
+	return nil
+}
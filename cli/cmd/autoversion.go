@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/versions"
+)
+
+// autoVersion applies the suggested version instead of only printing it
+var autoVersion bool
+
+// readPreviousExport snapshots the export about to be overwritten so the new one can be
+// diffed against it. It returns nil when there is none or it can't be read; with
+// --auto-version an unreadable export or a version that isn't semver is an error, checked
+// before any units are parsed.
+func readPreviousExport(factionDir string) (*versions.Snapshot, error) {
+	previous, err := versions.ReadSnapshot(factionDir)
+	if err != nil {
+		if autoVersion {
+			return nil, fmt.Errorf("failed to read the previous export for --auto-version: %w", err)
+		}
+		printStatus("⚠ Could not read the previous export, no version will be suggested: %v\n", err)
+		return nil, nil
+	}
+	if previous == nil {
+		if autoVersion {
+			fmt.Printf("No previous export in %s; --auto-version keeps the profile's version\n", factionDir)
+		}
+		return nil, nil
+	}
+	if _, err := versions.NextVersion(previous.Metadata.Version, versions.BumpPatch); err != nil {
+		if autoVersion {
+			return nil, fmt.Errorf("%w\n\n--auto-version needs the previous export (%s) to have a MAJOR.MINOR.PATCH version; pass --version once to start one", err, factionDir)
+		}
+		logVerbose("No version suggestion: %v", err)
+		return nil, nil
+	}
+	return previous, nil
+}
+
+// suggestVersion diffs the new export in factionDir against the previous one and prints
+// the semantic version its changes call for: major when units were added or removed,
+// minor when unit data changed, patch when only other files did. With --auto-version the
+// suggestion is written to metadata.json.
+func suggestVersion(previous *versions.Snapshot, factionDir string) error {
+	current, err := versions.ReadSnapshot(factionDir)
+	if err != nil {
+		return fmt.Errorf("failed to read the new export for a version suggestion: %w", err)
+	}
+	change := versions.Diff(previous, current)
+	next, err := versions.NextVersion(previous.Metadata.Version, change.Bump)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nSince the previous export (%s): %s\n", previous.Metadata.Version, change.Reason())
+	logVerbose("  Added: %v", change.Added)
+	logVerbose("  Removed: %v", change.Removed)
+	logVerbose("  Changed: %v", change.Changed)
+	if change.Bump == versions.BumpNone {
+		fmt.Printf("Suggested version: %s (unchanged)\n", next)
+	} else {
+		fmt.Printf("Suggested version: %s (%s bump)\n", next, change.Bump)
+	}
+
+	metadata := current.Metadata
+	switch {
+	case metadata.Version == next:
+		printStatus("✓ Exported as %s\n", next)
+	case autoVersion:
+		metadata.Version = next
+		if err := exporter.WriteFactionMetadata(factionDir, *metadata); err != nil {
+			return err
+		}
+		printStatus("✓ Version set to %s\n", next)
+	default:
+		printStatus("⚠ Exported as %s; pass --auto-version (or --version %s) to use the suggestion\n", metadata.Version, next)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/compat"
+	"github.com/spf13/cobra"
+)
+
+// compatCheckCmd reports what this version's models can't read from a faction folder.
+var compatCheckCmd = &cobra.Command{
+	Use:   "compat-check <faction-dir>",
+	Short: "Check that a faction folder loads with this version of pa-pedia",
+	Long: `Load a faction folder with this version's models and report every field that
+won't survive: values it can't parse, fields it doesn't know and would drop,
+values that read back differently and required fields that are absent.
+
+Use it before pairing faction data exported by an older (or newer) pa-pedia
+with consumers built against this version's schema/. metadata.json and
+units.json are required; CREDITS.json, conflicts.json, id-aliases.json,
+cross-faction.json, counters.json and missiles.json are checked when present.
+
+The command exits with an error when any issues are found, for CI. With
+--json the result is printed as {"checked", "issues": [{"file", "path",
+"line", "kind", "message"}]}, where kind is unparseable, dropped, changed or
+missing, and the exit status is the same.`,
+	Example: `  pa-pedia compat-check ./factions/MLA
+  pa-pedia compat-check ./old-exports/Legion --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCompatCheck,
+}
+
+func init() {
+	rootCmd.AddCommand(compatCheckCmd)
+	addJSONFlag(compatCheckCmd)
+}
+
+func runCompatCheck(cmd *cobra.Command, args []string) error {
+	factionDir := args[0]
+	report, err := compat.Check(factionDir)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		logVerbose("Checked %s", strings.Join(report.Checked, ", "))
+		if len(report.Issues) == 0 {
+			printStatus("✓ %d file(s) load without losing any fields\n", len(report.Checked))
+			return nil
+		}
+		for _, issue := range report.Issues {
+			printStatus("✗ %s\n", issue)
+		}
+	}
+	if len(report.Issues) > 0 {
+		return fmt.Errorf("%d compatibility issue(s) in %s\n\nRe-export the faction with this version of pa-pedia, or use a consumer built for the version that exported it", len(report.Issues), factionDir)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/i18n"
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/profiles"
+)
+
+// --conflicts modes: ask which mod's copy of each conflicting unit to use, or record the
+// first-wins choice without asking. Either way the decisions are saved to the profile.
+const (
+	conflictsPrompt = "prompt"
+	conflictsReport = "report"
+)
+
+var conflictsFlag string
+
+// validateConflictsFlag rejects unknown --conflicts modes, and prompting without a terminal
+func validateConflictsFlag() error {
+	switch conflictsFlag {
+	case "", conflictsReport:
+		return nil
+	case conflictsPrompt:
+		// Only stdin matters: asked for explicitly, prompting is fine in a container too
+		if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return i18n.Errorf("--conflicts prompt needs a terminal\n\nUse --conflicts report to record the first-wins choices instead")
+		}
+		return nil
+	}
+	return i18n.Errorf("unknown --conflicts mode '%s' (expected prompt or report)", conflictsFlag)
+}
+
+// resolveUnitConflicts decides which mod's copy of each conflicting unit to use (see
+// --conflicts), applies the decisions to the loader and saves the new ones into the profile's
+// conflictPreferences. Units the profile already decided are kept as they are.
+func resolveUnitConflicts(profile *models.FactionProfile, l *loader.Loader, conflicts []loader.UnitConflict) error {
+	if len(conflicts) == 0 {
+		i18n.Println("No unit conflicts between selected mods")
+		fmt.Println()
+		return nil
+	}
+
+	i18n.Printf("%d unit(s) defined by more than one selected mod:\n", len(conflicts))
+	in := bufio.NewReader(os.Stdin)
+	decided := make(map[string]string)
+	for _, c := range conflicts {
+		if profile.ConflictPreferences[c.ResourcePath] == c.Winner {
+			i18n.Printf("  - %s: using %s (from profile)\n", c.ResourcePath, c.Winner)
+			continue
+		}
+		choice := c.Winner
+		if conflictsFlag == conflictsPrompt {
+			var err error
+			if choice, err = promptConflict(in, c); err != nil {
+				return err
+			}
+		}
+		decided[c.ResourcePath] = choice
+		i18n.Printf("  - %s: using %s (overrides %s)\n", c.ResourcePath, choice, strings.Join(otherProviders(c, choice), ", "))
+	}
+	fmt.Println()
+	if len(decided) == 0 {
+		return nil
+	}
+
+	prefs := make(map[string]string, len(profile.ConflictPreferences)+len(decided))
+	for unit, source := range profile.ConflictPreferences {
+		prefs[unit] = source
+	}
+	for unit, source := range decided {
+		prefs[unit] = source
+	}
+	profile.ConflictPreferences = prefs
+	l.SetSourcePreferences(prefs)
+
+	path, err := profiles.SaveConflictPreferences(profile, profileDirFlag, prefs)
+	if err != nil {
+		snippet, _ := json.MarshalIndent(map[string]any{"conflictPreferences": prefs}, "", "  ")
+		printStatus("⚠ Could not save conflict decisions: %v\nAdd them to a profile to keep them:\n%s\n\n", err, snippet)
+		return nil
+	}
+	printStatus("✓ Saved %d conflict decision(s) to %s\n\n", len(decided), path)
+	return nil
+}
+
+// promptConflict asks which provider of a conflicting unit to use; Enter (or end of input)
+// keeps the current winner
+func promptConflict(in *bufio.Reader, c loader.UnitConflict) (string, error) {
+	providers := append([]string{c.Winner}, c.Overridden...)
+	i18n.Printf("\n  %s is defined by:\n", c.ResourcePath)
+	for i, p := range providers {
+		current := ""
+		if i == 0 {
+			current = i18n.T(" (current)")
+		}
+		fmt.Printf("    %d) %s%s\n", i+1, p, current)
+	}
+	for {
+		fmt.Print(i18n.T("  Use which mod? [1]: "))
+		line, err := in.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("failed to read choice: %w", err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			return c.Winner, nil
+		}
+		if n, convErr := strconv.Atoi(line); convErr == nil && n >= 1 && n <= len(providers) {
+			return providers[n-1], nil
+		}
+		if err == io.EOF {
+			return c.Winner, nil
+		}
+		i18n.Printf("  Enter a number from 1 to %d\n", len(providers))
+	}
+}
+
+// otherProviders lists a conflict's providers other than the chosen one
+func otherProviders(c loader.UnitConflict, chosen string) []string {
+	var others []string
+	for _, p := range append([]string{c.Winner}, c.Overridden...) {
+		if p != chosen {
+			others = append(others, p)
+		}
+	}
+	return others
+}
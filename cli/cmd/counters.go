@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/combat"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	countersUnit      string
+	countersOpponents string
+	countersConfig    string
+	countersOutput    string
+)
+
+// countersCmd suggests which units beat which, from weapon layers, DPS, health, range and cost.
+var countersCmd = &cobra.Command{
+	Use:   "counters <faction-dir>",
+	Short: "Suggest counters for each unit of an exported faction",
+	Long: `Score every pairing of units in an exported faction and list, for each unit,
+the units that beat it for their cost (counters) and the units it beats
+(vulnerable).
+
+A matchup only counts weapons that can hit the target's layer (land, water,
+underwater, air, orbital). Scores combine the equal-metal trade (DPS, health
+and cost on both sides), range advantage and speed advantage; see
+combat.CounterConfig for the formula. Tune the weights with --config, a JSON
+file with any of: trade, range, speed, maxTrade, minScore, limit,
+includeStructures, includeUnarmed.
+
+Without --unit the lists are written as counters.json in the faction folder
+(or --output). With --unit the lists for that unit are printed instead.
+--opponents draws the counters from another exported faction.`,
+	Example: `  pa-pedia counters ./factions/MLA
+  pa-pedia counters ./factions/MLA --unit tank_heavy_armor
+  pa-pedia counters ./factions/MLA --unit bomber --opponents ./factions/Legion
+  pa-pedia counters ./factions/MLA --config counter-weights.json --output counters.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCounters,
+}
+
+func init() {
+	rootCmd.AddCommand(countersCmd)
+
+	countersCmd.Flags().StringVar(&countersUnit, "unit", "", "Print the counters of this unit ID instead of writing counters.json")
+	countersCmd.Flags().StringVar(&countersOpponents, "opponents", "", "Exported faction folder to draw counters from (default: the faction itself)")
+	countersCmd.Flags().StringVar(&countersConfig, "config", "", "JSON file overriding the scoring weights (see combat.CounterConfig)")
+	countersCmd.Flags().StringVar(&countersOutput, "output", "", "Where to write the counters (default: <faction-dir>/counters.json)")
+}
+
+func runCounters(cmd *cobra.Command, args []string) error {
+	factionDir := args[0]
+
+	config := combat.DefaultCounterConfig()
+	if countersConfig != "" {
+		var err error
+		if config, err = combat.LoadCounterConfig(countersConfig); err != nil {
+			return err
+		}
+	}
+
+	name, units, err := loadStatsUnits(factionDir)
+	if err != nil {
+		return err
+	}
+	opponents := units
+	if countersOpponents != "" {
+		if _, opponents, err = loadStatsUnits(countersOpponents); err != nil {
+			return err
+		}
+	}
+
+	if countersUnit != "" {
+		var unit *models.Unit
+		for _, u := range units {
+			if u.ID == countersUnit {
+				unit = u
+			}
+		}
+		if unit == nil {
+			return fmt.Errorf("unit %q not found among the accessible units of %s", countersUnit, factionDir)
+		}
+
+		lists := combat.Counters([]*models.Unit{unit}, opponents, config)[unit.ID]
+		fmt.Printf("=== PA-Pedia Counters: %s (%s) ===\n", unit.DisplayName, unit.ID)
+		printMatchups(fmt.Sprintf("Countered by (%s layer)", combat.Layer(unit)), lists.Counters)
+		printMatchups("Strong against", lists.Vulnerable)
+		return nil
+	}
+
+	report := combat.CountersReport{Faction: name, Config: config, Units: combat.Counters(units, opponents, config)}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal counters: %w", err)
+	}
+	output := countersOutput
+	if output == "" {
+		output = filepath.Join(factionDir, "counters.json")
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return fmt.Errorf("failed to write counters: %w", err)
+	}
+	printStatus("✓ Wrote counters for %d units to %s\n", len(report.Units), output)
+	return nil
+}
+
+// printMatchups prints one counter list with scores, trades and times to kill
+func printMatchups(title string, entries []combat.Matchup) {
+	fmt.Println()
+	fmt.Printf("%s:\n", title)
+	if len(entries) == 0 {
+		fmt.Println("  (none above the minimum score)")
+		return
+	}
+	for i, m := range entries {
+		fmt.Printf("  %d. %-34s score %5.2f  trade %5.2fx  ttk %.1fs  equal-metal kill %.1fs\n", i+1, fmt.Sprintf("%s (%s)", m.DisplayName, m.ID), m.Score, m.Trade, m.TTK, m.KillTime)
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/coverage"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/style"
+	"github.com/spf13/cobra"
+)
+
+var (
+	coveragePlace      []string
+	coverageLayout     string
+	coverageResolution float64
+	coverageOutput     string
+)
+
+// coverageCmd maps the weapon ranges of a defensive layout
+var coverageCmd = &cobra.Command{
+	Use:   "coverage <faction-dir>",
+	Short: "Map the weapon coverage of a defensive layout",
+	Long: `Place structures from an exported faction on a flat plane and work out where
+their weapons reach: each active weapon's range circle, how much area each
+target layer (land, water, underwater, air, orbital) is covered by one or more
+structures, where coverage overlaps and the combined DPS there.
+
+Placements are unit@x,y in game units (metres), given with --place
+(repeatable) or as a JSON array of {"unit", "x", "y"} in --layout. Areas come
+from a grid (--resolution, default 1/50th of the longest range); the overlap
+of each pair of structures is exact.
+
+--output writes the analysis as a GeoJSON FeatureCollection (- for stdout):
+a Point per placement, a Polygon per range circle and a MultiPolygon per
+coverage region, with kind, unit, weapon, layer, dps and area properties.
+Coordinates are plane positions, not longitude/latitude.`,
+	Example: `  pa-pedia coverage ./factions/MLA --place laser_defense@0,0 --place laser_defense@120,0 --place air_defense@60,40
+  pa-pedia coverage ./factions/MLA --layout base.json --output base.geojson`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCoverage,
+}
+
+func init() {
+	rootCmd.AddCommand(coverageCmd)
+
+	coverageCmd.Flags().StringArrayVar(&coveragePlace, "place", nil, "Structure to place, as unit@x,y (repeatable)")
+	coverageCmd.Flags().StringVar(&coverageLayout, "layout", "", "JSON file with an array of {\"unit\", \"x\", \"y\"} placements")
+	coverageCmd.Flags().Float64Var(&coverageResolution, "resolution", 0, "Grid cell size in game units for coverage areas (default: 1/50th of the longest range)")
+	coverageCmd.Flags().StringVar(&coverageOutput, "output", "", "Write the coverage as GeoJSON to this file (- for stdout)")
+}
+
+func runCoverage(cmd *cobra.Command, args []string) error {
+	factionDir := args[0]
+
+	var placements []coverage.Placement
+	if coverageLayout != "" {
+		data, err := os.ReadFile(coverageLayout)
+		if err != nil {
+			return fmt.Errorf("failed to read layout: %w", err)
+		}
+		if err := json.Unmarshal(data, &placements); err != nil {
+			return fmt.Errorf("failed to parse layout %s: %w\n\nThe layout is a JSON array such as [{\"unit\": \"laser_defense\", \"x\": 0, \"y\": 0}]", coverageLayout, err)
+		}
+	}
+	for _, spec := range coveragePlace {
+		p, err := coverage.ParsePlacement(spec)
+		if err != nil {
+			return fmt.Errorf("invalid --place: %w", err)
+		}
+		placements = append(placements, p)
+	}
+
+	_, list, err := loadStatsUnits(factionDir)
+	if err != nil {
+		return err
+	}
+	units := make(map[string]*models.Unit, len(list))
+	for _, u := range list {
+		units[u.ID] = u
+	}
+
+	analysis, err := coverage.Analyze(placements, units, coverage.Options{Resolution: coverageResolution})
+	if err != nil {
+		return err
+	}
+	logVerbose("Sampled %d range circle(s) on a %.2f grid", len(analysis.Circles), analysis.Resolution)
+
+	if coverageOutput != "" {
+		data, err := json.MarshalIndent(analysis.GeoJSON(), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal coverage: %w", err)
+		}
+		if coverageOutput == "-" {
+			fmt.Println(string(data))
+			return nil
+		}
+		if err := os.WriteFile(coverageOutput, data, 0644); err != nil {
+			return fmt.Errorf("failed to write coverage: %w", err)
+		}
+	}
+
+	fmt.Println("=== PA-Pedia Coverage ===")
+	fmt.Println()
+	for i, p := range analysis.Placements {
+		var ranges []string
+		for _, c := range analysis.Circles {
+			if c.Placement == i {
+				ranges = append(ranges, fmt.Sprintf("%s %.0fm (%s)", c.Weapon, c.Radius, strings.Join(c.Layers, ", ")))
+			}
+		}
+		if len(ranges) == 0 {
+			ranges = []string{"no weapons"}
+		}
+		fmt.Printf("  %d. %s at (%g, %g): %s\n", i+1, p.Unit, p.X, p.Y, strings.Join(ranges, "; "))
+	}
+	fmt.Println()
+
+	if len(analysis.Layers) == 0 {
+		printStatus("⚠ None of the placed structures have weapons\n")
+		return nil
+	}
+	rows := make([][]string, len(analysis.Layers))
+	for i, l := range analysis.Layers {
+		rows[i] = []string{l.Layer, fmt.Sprintf("%.0f", l.Area), fmt.Sprintf("%.0f", l.OverlapArea), fmt.Sprintf("%.1f", l.PeakDPS)}
+	}
+	if err := style.Table(os.Stdout, []string{"LAYER", "COVERED (m²)", "OVERLAP (m²)", "PEAK DPS"}, rows); err != nil {
+		return err
+	}
+	if overlaps := analysis.Overlaps(); len(overlaps) > 0 {
+		fmt.Println()
+		fmt.Println("Overlapping reach:")
+		for _, o := range overlaps {
+			fmt.Printf("  %d + %d on %s: %.0f m²\n", o.A+1, o.B+1, o.Layer, o.Area)
+		}
+	}
+	if coverageOutput != "" {
+		fmt.Println()
+		printStatus("✓ Wrote coverage to %s\n", coverageOutput)
+	}
+	return nil
+}
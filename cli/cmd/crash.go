@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/crash"
+	"github.com/jamiemulcahy/pa-pedia/pkg/i18n"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/parallel"
+)
+
+var (
+	crashReport bool
+
+	// recentOutput holds the latest status and verbose lines (verbose ones even without
+	// --verbose) for crash bundles
+	recentOutput = crash.NewLog(500)
+
+	// activeProfile is the profile describeFaction is extracting, for crash bundles
+	activeProfile *models.FactionProfile
+)
+
+// reportPanic writes a crash bundle for a recovered panic and returns the error to print
+// instead of the runtime's trace
+func reportPanic(r any) error {
+	stack := debug.Stack()
+	if p, ok := r.(*parallel.Panic); ok {
+		r, stack = p.Value, p.Stack
+	}
+	report := newCrashReport()
+	report.Panic = fmt.Sprint(r)
+	report.Stack = string(stack)
+	path, err := writeCrashBundle(report)
+	if err != nil {
+		return fmt.Errorf("pa-pedia crashed: %v\n\n%s\nThe crash report couldn't be written (%v); please include the above in a bug report at %s", r, stack, err, crash.IssuesURL)
+	}
+	return fmt.Errorf("pa-pedia crashed: %v\n\n%s", r, crashInstructions(path))
+}
+
+// reportFailure writes a crash bundle for a command that failed with --crash-report
+func reportFailure(failure error) error {
+	report := newCrashReport()
+	report.Error = failure.Error()
+	path, err := writeCrashBundle(report)
+	if err != nil {
+		return fmt.Errorf("%w\n\n%v", failure, err)
+	}
+	return fmt.Errorf("%w\n\n%s", failure, crashInstructions(path))
+}
+
+func newCrashReport() crash.Report {
+	env := crash.NewEnvironment()
+	env.Locale = i18n.Locale()
+	env.Interactive = interactive()
+	if paRoot != "" {
+		env.PABuild = detectPAVersion(paRoot)
+	}
+	return crash.Report{
+		Time:        time.Now(),
+		Version:     Version,
+		Commit:      Commit,
+		Built:       Date,
+		Args:        os.Args[1:],
+		Environment: env,
+	}
+}
+
+// writeCrashBundle writes to the working directory, or the temp directory if that's not
+// writable
+func writeCrashBundle(report crash.Report) (string, error) {
+	bundle := crash.Bundle{Report: report, Log: recentOutput.Lines()}
+	if activeProfile != nil {
+		bundle.Profile = profileListing{ID: activeProfile.ID, Path: activeProfile.Path, FactionProfile: activeProfile}
+	}
+	redactor := crash.NewRedactor()
+	path, err := crash.Write(".", bundle, redactor)
+	if err != nil {
+		path, err = crash.Write(os.TempDir(), bundle, redactor)
+	}
+	return path, err
+}
+
+func crashInstructions(path string) string {
+	return i18n.Sprintf("A crash report was written to %s\nPlease open an issue at %s, describe what you were doing and attach the file.\nPaths under your home folder and secrets are redacted; look inside before posting.", path, crash.IssuesURL)
+}
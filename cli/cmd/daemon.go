@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/profiles"
+	"github.com/jamiemulcahy/pa-pedia/pkg/schedule"
+	"github.com/jamiemulcahy/pa-pedia/pkg/versions"
+	"github.com/spf13/cobra"
+)
+
+var (
+	daemonSchedule   string
+	daemonProfiles   []string
+	daemonProfileDir string
+	daemonPARoot     string
+	daemonDataRoot   string
+	daemonOutput     string
+	daemonKeep       int
+	daemonLogFile    string
+	daemonRunNow     bool
+)
+
+// daemonStaging is the folder, inside the output directory, that exports are written
+// to before being moved into place
+const daemonStaging = ".staging"
+
+// daemonCmd runs describe-faction for a set of profiles on a cron schedule.
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run scheduled extractions into a versioned output directory",
+	Long: `Run describe-faction for one or more profiles on a cron schedule, for
+unattended data pipelines.
+
+Each run exports every profile, moves the result into a versioned output
+directory and updates that faction's versions.json:
+
+  <output>/mla/124664/            one folder per faction version
+  <output>/mla/versions.json      versions on disk, most recent first
+
+Exports are written to <output>/.staging first, so a failed run never
+touches the versions already on disk. Re-exporting a version that is already
+there (e.g. no PA update since the last run) replaces it, keeping its pin and
+tags. After each export only the --keep most recently exported versions of
+that faction are kept, plus any pinned or tagged with pa-pedia prune.
+
+serve reads this layout directly: pa-pedia serve <output>.
+
+The schedule is a five-field cron expression in local time (minute hour
+day-of-month month day-of-week) or @hourly, @daily, @weekly, @monthly.
+A run that is still going when the next one is due delays it rather than
+overlapping. One line per profile is logged for each run, to stdout and to
+--log when set. SIGTERM or Ctrl+C stops the daemon after the current
+extraction.`,
+	Example: `  pa-pedia daemon --schedule "0 4 * * *" --profile mla --profile legion \
+    --pa-root /data/pa/media --data-root /data/pa --output /srv/factions
+  pa-pedia daemon --schedule @daily --profile mla --pa-root /data/pa/media --keep 10 --log daemon.log --run-now`,
+	RunE: runDaemon,
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+
+	daemonCmd.Flags().StringVar(&daemonSchedule, "schedule", "", `Cron expression for extraction runs, e.g. "0 4 * * *" for 04:00 daily`)
+	daemonCmd.Flags().StringArrayVar(&daemonProfiles, "profile", nil, "Profile ID to extract on each run (repeatable)")
+	daemonCmd.Flags().StringVar(&daemonProfileDir, "profile-dir", "./profiles", "Directory for custom faction profiles")
+	daemonCmd.Flags().StringVar(&daemonPARoot, "pa-root", "", "Path to PA Titans media directory")
+	daemonCmd.Flags().StringVar(&daemonDataRoot, "data-root", "", "Path to PA data directory (required when mods are involved)")
+	daemonCmd.Flags().StringVar(&daemonOutput, "output", "./factions", "Versioned output directory (<id>/<version>/ per export)")
+	daemonCmd.Flags().IntVar(&daemonKeep, "keep", 5, "Versions to keep per faction, most recently exported first; pinned and tagged versions are always kept (0 keeps all)")
+	daemonCmd.Flags().StringVar(&daemonLogFile, "log", "", "Also append run results to this file")
+	daemonCmd.Flags().BoolVar(&daemonRunNow, "run-now", false, "Run once at startup instead of waiting for the first scheduled time")
+	daemonCmd.MarkFlagRequired("schedule")
+	daemonCmd.MarkFlagRequired("profile")
+	daemonCmd.MarkFlagRequired("pa-root")
+}
+
+func runDaemon(cmd *cobra.Command, args []string) error {
+	sched, err := schedule.Parse(daemonSchedule)
+	if err != nil {
+		return err
+	}
+	if sched.Next(time.Now()).IsZero() {
+		return fmt.Errorf("schedule %q never fires", daemonSchedule)
+	}
+
+	profileLoader, err := profiles.NewLoader()
+	if err != nil {
+		return fmt.Errorf("failed to initialize profile loader: %w", err)
+	}
+	if err := profileLoader.LoadLocalProfiles(daemonProfileDir); err != nil {
+		return fmt.Errorf("failed to load local profiles: %w", err)
+	}
+	// Check every profile up front rather than at 4am
+	for _, id := range daemonProfiles {
+		profile, err := resolveProfileFromFlags(profileLoader, id, "", "", nil)
+		if err != nil {
+			return err
+		}
+		if err := validateFactionInputs(profile, daemonPARoot, daemonDataRoot); err != nil {
+			return err
+		}
+	}
+
+	logOut := io.Writer(os.Stdout)
+	if daemonLogFile != "" {
+		f, err := os.OpenFile(daemonLogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		defer f.Close()
+		logOut = io.MultiWriter(os.Stdout, f)
+	}
+	logger := log.New(logOut, "", log.LstdFlags)
+
+	store := &versions.Store{Root: daemonOutput}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger.Printf("Daemon started: %d profiles on schedule %q into %s (keeping %d versions)", len(daemonProfiles), daemonSchedule, daemonOutput, daemonKeep)
+	if daemonRunNow {
+		runDaemonExtractions(profileLoader, store, logger)
+	}
+	for {
+		next := sched.Next(time.Now())
+		logger.Printf("Next run at %s", next.Format("2006-01-02 15:04 MST"))
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			logger.Printf("Daemon stopped")
+			return nil
+		case <-timer.C:
+		}
+		runDaemonExtractions(profileLoader, store, logger)
+		if ctx.Err() != nil {
+			logger.Printf("Daemon stopped")
+			return nil
+		}
+	}
+}
+
+// runDaemonExtractions runs one scheduled extraction of every profile. A failing profile
+// is logged and doesn't stop the others.
+func runDaemonExtractions(pl *profiles.Loader, store *versions.Store, logger *log.Logger) {
+	start := time.Now()
+	failed := 0
+	// Shared within a run only: PA may be updated between runs
+	sharedBaseCache = loader.NewBaseCache()
+	defer func() { sharedBaseCache = nil }()
+	for _, id := range daemonProfiles {
+		profileStart := time.Now()
+		version, pruned, err := runDaemonExtraction(pl, store, id)
+		if err != nil {
+			failed++
+			logger.Printf("✗ %s: %v", id, err)
+			continue
+		}
+		logger.Printf("✓ %s: version %s exported in %s", id, version, time.Since(profileStart).Round(time.Second))
+		for _, v := range pruned {
+			logger.Printf("  removed old version %s (exported %s)", v.Version, v.ExportedAt)
+		}
+	}
+	logger.Printf("Run finished in %s: %d succeeded, %d failed", time.Since(start).Round(time.Second), len(daemonProfiles)-failed, failed)
+}
+
+// runDaemonExtraction exports one profile into the staging folder with the
+// describe-faction code path, installs it into the store and applies --keep. It returns
+// the exported version and the versions pruned.
+func runDaemonExtraction(pl *profiles.Loader, store *versions.Store, id string) (string, []models.VersionEntry, error) {
+	profile, err := resolveProfileFromFlags(pl, id, "", "", nil)
+	if err != nil {
+		return "", nil, err
+	}
+	// The detected version changes with PA updates, so never write it to the shared profile
+	run := *profile
+	applyDetectedVersion(&run, daemonPARoot)
+
+	staging := filepath.Join(daemonOutput, daemonStaging)
+	factionDir := filepath.Join(staging, exporter.SanitizeFolderName(run.DisplayName))
+	if err := os.RemoveAll(factionDir); err != nil {
+		return "", nil, fmt.Errorf("failed to clear staging folder: %w", err)
+	}
+
+	// Unit IDs are kept stable against the latest stored version, not the cleared staging folder
+	manifest, err := store.Manifest(run.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	idHistoryDir = ""
+	for _, v := range manifest.Versions {
+		if v.Version == manifest.Latest {
+			idHistoryDir = filepath.Join(store.FactionDir(run.ID), v.Path)
+		}
+	}
+
+	// Scheduled runs follow whatever PA build is installed, so they never write a lockfile
+	paRoot, paDataRoot, outputDir, lockfilePath = daemonPARoot, daemonDataRoot, staging, ""
+	if err := describeFaction(&run, false); err != nil {
+		return "", nil, err
+	}
+
+	manifest, err = store.Install(factionDir, time.Now())
+	if err != nil {
+		return "", nil, err
+	}
+	pruned, err := store.Prune(manifest.Identifier, daemonKeep)
+	if err != nil {
+		return manifest.Latest, pruned, fmt.Errorf("version %s exported but pruning failed: %w", manifest.Latest, err)
+	}
+	return manifest.Latest, pruned, nil
+}
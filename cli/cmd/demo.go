@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/demo"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var demoAddr string
+
+// demoCmd explores the embedded demo faction (binaries built with -tags demo)
+var demoCmd = &cobra.Command{
+	Use:   "demo",
+	Short: "Try PA-Pedia on a small embedded MLA subset (no PA install needed)",
+	Long: `Explore a small pre-extracted subset of the MLA faction embedded in the binary,
+so you can try the tooling before pointing it at a PA install.
+
+Demo data is only present in demo builds:
+  just cli-build-demo        (or: go build -tags demo -o pa-pedia .)`,
+	Example: `  pa-pedia demo list
+  pa-pedia demo compare tank_light_laser assault_bot
+  pa-pedia demo serve --addr localhost:8080`,
+}
+
+var demoListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the units in the demo faction",
+	Args:  cobra.NoArgs,
+	RunE:  runDemoList,
+}
+
+var demoCompareCmd = &cobra.Command{
+	Use:   "compare <unit-id> <unit-id> [unit-id...]",
+	Short: "Compare key stats of demo units side by side",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runDemoCompare,
+}
+
+var demoServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the demo faction folder over HTTP",
+	Long: `Serve the embedded demo faction folder (metadata.json, units.json and assets)
+over HTTP, laid out exactly like a describe-faction export.`,
+	Args: cobra.NoArgs,
+	RunE: runDemoServe,
+}
+
+func init() {
+	rootCmd.AddCommand(demoCmd)
+	demoCmd.AddCommand(demoListCmd, demoCompareCmd, demoServeCmd)
+
+	addJSONFlag(demoListCmd)
+	demoServeCmd.Flags().StringVar(&demoAddr, "addr", "localhost:8080", "Address to listen on")
+}
+
+// demoUnit is one entry of `demo list --json`
+type demoUnit struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	Tier        int    `json:"tier"`
+}
+
+func runDemoList(cmd *cobra.Command, args []string) error {
+	faction, err := demo.Load()
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		units := make([]demoUnit, 0, len(faction.Order))
+		for _, id := range faction.Order {
+			unit := faction.Units[id]
+			units = append(units, demoUnit{ID: id, DisplayName: unit.DisplayName, Tier: unit.Tier})
+		}
+		return printJSON(units)
+	}
+
+	fmt.Printf("=== %s demo (%d units) ===\n\n", faction.Metadata.DisplayName, len(faction.Order))
+	for _, id := range faction.Order {
+		unit := faction.Units[id]
+		fmt.Printf("  %-22s %-20s T%d\n", id, unit.DisplayName, unit.Tier)
+	}
+	return nil
+}
+
+func runDemoCompare(cmd *cobra.Command, args []string) error {
+	faction, err := demo.Load()
+	if err != nil {
+		return err
+	}
+
+	units := make([]*models.Unit, 0, len(args))
+	for _, id := range args {
+		unit, ok := faction.Units[id]
+		if !ok {
+			return fmt.Errorf("unit '%s' is not in the demo data\n\nRun 'pa-pedia demo list' to see available units", id)
+		}
+		units = append(units, unit)
+	}
+
+	rows := []struct {
+		label string
+		value func(*models.Unit) float64
+	}{
+		{"Health", func(u *models.Unit) float64 {
+			if u.Specs.Combat == nil {
+				return 0
+			}
+			return u.Specs.Combat.Health
+		}},
+		{"DPS", func(u *models.Unit) float64 {
+			if u.Specs.Combat == nil {
+				return 0
+			}
+			return u.Specs.Combat.DPS
+		}},
+		{"Max range", maxWeaponRange},
+		{"Build cost", func(u *models.Unit) float64 {
+			if u.Specs.Economy == nil {
+				return 0
+			}
+			return u.Specs.Economy.BuildCost
+		}},
+		{"Move speed", func(u *models.Unit) float64 {
+			if u.Specs.Mobility == nil {
+				return 0
+			}
+			return u.Specs.Mobility.MoveSpeed
+		}},
+		{"Vision", func(u *models.Unit) float64 {
+			if u.Specs.Recon == nil {
+				return 0
+			}
+			return u.Specs.Recon.VisionRadius
+		}},
+	}
+
+	fmt.Printf("%-12s", "")
+	for _, unit := range units {
+		fmt.Printf(" %14s", unit.DisplayName)
+	}
+	fmt.Println()
+	for _, row := range rows {
+		fmt.Printf("%-12s", row.label)
+		for _, unit := range units {
+			fmt.Printf(" %14.1f", row.value(unit))
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// maxWeaponRange returns the longest MaxRange across a unit's weapons
+func maxWeaponRange(u *models.Unit) float64 {
+	if u.Specs.Combat == nil {
+		return 0
+	}
+	longest := 0.0
+	for _, w := range u.Specs.Combat.Weapons {
+		if w.MaxRange > longest {
+			longest = w.MaxRange
+		}
+	}
+	return longest
+}
+
+func runDemoServe(cmd *cobra.Command, args []string) error {
+	faction, err := demo.Load()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Serving %s demo faction (%d units) at http://%s/%s/\n", faction.Metadata.DisplayName, len(faction.Order), demoAddr, demo.FactionDir)
+	fmt.Println("Press Ctrl+C to stop")
+	return http.ListenAndServe(demoAddr, http.FileServer(http.FS(demo.Data)))
+}
@@ -1,28 +1,42 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/jamiemulcahy/pa-pedia/pkg/checkpoint"
+	"github.com/jamiemulcahy/pa-pedia/pkg/combat"
 	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/i18n"
 	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/lockfile"
 	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/parser"
 	"github.com/jamiemulcahy/pa-pedia/pkg/profiles"
+	"github.com/jamiemulcahy/pa-pedia/pkg/style"
+	"github.com/jamiemulcahy/pa-pedia/pkg/upload"
 	"github.com/spf13/cobra"
 )
 
 var (
 	// Profile-based approach (recommended)
-	profileFlag    string
+	profileFlags   []string
 	profileDirFlag string
 	listProfiles   bool
+	listMods       bool
 
 	// Args-based approach (fallback)
 	factionNameFlag     string
 	factionUnitTypeFlag string
 	modIDs              []string
+	modsFlag            string
 
 	// Common flags
 	paRoot      string
@@ -30,8 +44,30 @@ var (
 	outputDir   string
 	allowEmpty  bool
 	versionFlag string
+	layoutFlag  string
+	uploadFlag  string
+	publishFlag string
+	ipfsAPIFlag string
+	memoryLimit string
+	resumeFlag  bool
+	pruneEmpty  bool
+	minifyJSON  bool
+	formatFlag  string
+	zipFlag     bool
+	graphFlag   string
+	noCache     bool
+	fixturesDir string
+
+	combatValueConfig string
+	silhouetteSize    int
+	stripIcons        bool
+	noAssets          bool
+	contentQA         bool
 )
 
+// maxSilhouetteSize bounds --silhouettes; each image is size×size RGBA plus a depth buffer
+const maxSilhouetteSize = 2048
+
 // describeFactionCmd represents the describe-faction command
 var describeFactionCmd = &cobra.Command{
 	Use:   "describe-faction",
@@ -55,6 +91,11 @@ PROFILES (Recommended):
 
   Custom profiles can be added to ./profiles/ directory.
 
+  Repeat --profile to export several factions in one run. Base game and
+  expansion specs are read once and shared between them; each faction still
+  gets its own self-contained folder. A failing faction doesn't stop the
+  others.
+
 MANUAL MODE (Fallback):
   Use --name with --faction-unit-type and optional --mod flags for custom
   configurations without creating a profile.`,
@@ -62,12 +103,26 @@ MANUAL MODE (Fallback):
   pa-pedia describe-faction --profile mla --pa-root "C:/PA/media"
   pa-pedia describe-faction --profile legion --pa-root "C:/PA/media" --data-root "%LOCALAPPDATA%/..."
 
-  # List available profiles
+  # List available profiles, or the mods installed under --data-root
   pa-pedia describe-faction --list-profiles
+  pa-pedia describe-faction --list-mods --data-root "%LOCALAPPDATA%/..." --json
 
   # GitHub repository as mod source (no local download needed)
   pa-pedia describe-faction --profile mla --mod "github.com/user/my-mod" --pa-root "C:/PA/media"
   pa-pedia describe-faction --profile mla --mod "github.com/user/repo/tree/v2.0" --pa-root "C:/PA/media"
+  pa-pedia describe-faction --profile mla --mod "github.com/user/my-mod" --pa-root "C:/PA/media" --pin-github-refs
+
+  # Several factions in one run, reading the base game once
+  pa-pedia describe-faction --profile mla --profile legion --pa-root "C:/PA/media" --data-root "%LOCALAPPDATA%/..."
+
+  # Exactly the mods enabled in PA's mod manager
+  pa-pedia describe-faction --profile mla --mods enabled --pa-root "C:/PA/media"
+
+  # Build tree for Graphviz (dot -Tsvg factions/Legion/build-tree.dot -o legion.svg)
+  pa-pedia describe-faction --profile legion --pa-root "C:/PA/media" --graph dot
+
+  # A single zip ready for GitHub Releases or the web app's upload
+  pa-pedia describe-faction --profile mla --pa-root "C:/PA/media" --zip
 
   # Manual mode (fallback)
   pa-pedia describe-faction --name MLA --faction-unit-type Custom58 --pa-root "C:/PA/media"
@@ -81,21 +136,51 @@ func init() {
 	rootCmd.AddCommand(describeFactionCmd)
 
 	// Profile-based flags (recommended)
-	describeFactionCmd.Flags().StringVar(&profileFlag, "profile", "", "Profile ID to use (recommended approach)")
+	describeFactionCmd.Flags().StringArrayVar(&profileFlags, "profile", nil, "Profile ID to use (recommended approach; repeatable to export several factions in one run)")
 	describeFactionCmd.Flags().StringVar(&profileDirFlag, "profile-dir", "./profiles", "Directory for custom faction profiles")
 	describeFactionCmd.Flags().BoolVar(&listProfiles, "list-profiles", false, "List available profiles and exit")
+	describeFactionCmd.Flags().BoolVar(&listMods, "list-mods", false, "List the mods discovered under --data-root (server_mods, client_mods, download) and exit")
+	addJSONFlag(describeFactionCmd)
 
 	// Args-based flags (fallback)
 	describeFactionCmd.Flags().StringVar(&factionNameFlag, "name", "", "Faction display name (fallback mode)")
 	describeFactionCmd.Flags().StringVar(&factionUnitTypeFlag, "faction-unit-type", "", "Faction unit type identifier (e.g., Custom58 for MLA, Custom1 for Legion)")
 	describeFactionCmd.Flags().StringArrayVar(&modIDs, "mod", []string{}, "Mod source(s) to include - local mod ID or GitHub URL (repeatable, first has priority)")
+	describeFactionCmd.Flags().StringVar(&modsFlag, "mods", "", "enabled: also include every mod enabled in PA's mod manager (server_mods and client_mods mods.json under --data-root)")
+	describeFactionCmd.Flags().StringVar(&loadOrderFlag, "load-order", loader.LoadOrderManual, "Order of the selected mods: manual (as listed, first wins) or game (by modinfo.json priority and dependencies, as PA mounts them)")
 
 	// Common flags
 	describeFactionCmd.Flags().StringVar(&paRoot, "pa-root", "", "Path to PA Titans media directory")
 	describeFactionCmd.Flags().StringVar(&paDataRoot, "data-root", "", "Path to PA data directory (required when mods are involved)")
+	describeFactionCmd.Flags().StringArrayVar(&overrideOptOut, "override-opt-out", nil, "Extract this mod even though its author opted out of exports (repeatable; each opted-out mod must be named)")
+	describeFactionCmd.Flags().StringVar(&conflictsFlag, "conflicts", "", "Decide units defined by several selected mods: prompt asks per unit, report records first-wins; decisions are saved to the profile")
 	describeFactionCmd.Flags().StringVar(&outputDir, "output", "./factions", "Output directory for faction folders")
 	describeFactionCmd.Flags().BoolVar(&allowEmpty, "allow-empty", false, "Allow exporting factions with 0 units (normally an error)")
 	describeFactionCmd.Flags().StringVar(&versionFlag, "version", "", "Faction version (required if not auto-detected from mod)")
+	describeFactionCmd.Flags().BoolVar(&noCache, "no-cache", false, "Re-derive the base game units for addon exports instead of using the per-build unit cache")
+	describeFactionCmd.Flags().BoolVar(&autoVersion, "auto-version", false, "Set the version from the changes since the previous export in --output: major for added/removed units, minor for stat changes, patch for other files")
+	describeFactionCmd.Flags().StringVar(&lockfilePath, "lockfile", lockfile.DefaultPath, "Lockfile recording each faction's inputs (mod hashes, PA build, CLI version, profile hash); empty to skip")
+	describeFactionCmd.Flags().BoolVar(&frozen, "frozen", false, "Fail if the inputs differ from the lockfile instead of updating it")
+	describeFactionCmd.Flags().BoolVar(&pinGitHubRefs, "pin-github-refs", false, "Download GitHub mods at the commit in the lockfile (or the branch's current commit) and record it in metadata.json and the lockfile")
+	describeFactionCmd.Flags().StringVar(&layoutFlag, "layout", exporter.LayoutMirrored, "Unit file layout: mirrored (assets/pa/...) or flat (units/<id>/...)")
+	describeFactionCmd.Flags().StringVar(&publishFlag, "publish", "", "Experimental: publish the faction folder to a content-addressed store (ipfs) and record its CID in metadata.json")
+	describeFactionCmd.Flags().StringVar(&ipfsAPIFlag, "ipfs-api", upload.DefaultIPFSAPI, "IPFS node HTTP API address used by --publish ipfs")
+	describeFactionCmd.Flags().StringVar(&uploadFlag, "upload", "", "Also upload the faction folder to object storage (s3://bucket/prefix or gs://bucket/prefix)")
+	describeFactionCmd.Flags().StringVar(&memoryLimit, "memory-limit", "", "Soft memory ceiling for extraction, e.g. 2GiB (unset by default; peak use is always reported)")
+	describeFactionCmd.Flags().BoolVar(&pruneEmpty, "prune-empty", false, "Drop empty optional fields (zero, false, empty strings/lists/objects) from units.json")
+	describeFactionCmd.Flags().BoolVar(&minifyJSON, "minify", false, "Write units.json as compact JSON instead of pretty-printed")
+	describeFactionCmd.Flags().BoolVar(&zipFlag, "zip", false, "Write the faction as a single <faction>.zip in --output instead of a folder")
+	describeFactionCmd.Flags().StringVar(&graphFlag, "graph", "", "Also write the build tree as a graph: dot (Graphviz build-tree.dot) or graphml (build-tree.graphml)")
+	describeFactionCmd.Flags().StringVar(&fixturesDir, "fixtures", "", "Also write per-unit expected stats to this directory for the assert command (replaces the fixtures already in it)")
+	describeFactionCmd.Flags().StringVar(&formatFlag, "format", exporter.IndexFormatJSON, "Index format: json (units.json) or pb (units.json plus protobuf units.pb)")
+	describeFactionCmd.Flags().StringVar(&combatValueConfig, "combat-value-config", "", "JSON file overriding the combatValue scoring weights (see combat.ValueConfig)")
+	describeFactionCmd.Flags().IntVar(&silhouetteSize, "silhouettes", 0, "Render top and side silhouette PNGs of each unit's model at this size in pixels, e.g. 256 (0 = off)")
+	describeFactionCmd.Flags().BoolVar(&contentQA, "content-qa", false, "Check display names and descriptions (empty or name-only descriptions, unstripped !LOC markers, control characters) and record problems as unit warnings")
+	describeFactionCmd.Flags().BoolVar(&resumeFlag, "resume", false, "Reuse the parsed units from a previous run that failed after parsing (skips straight to export)")
+	describeFactionCmd.Flags().IntVar(&parallelismFlag, "parallelism", 0, "Goroutines for reading unit JSON and copying files (0 = GOMAXPROCS)")
+	describeFactionCmd.Flags().BoolVar(&stripIcons, "strip-icons", false, "Replace game icons with generated placeholders (recorded as assets: icons-stripped in metadata.json)")
+	describeFactionCmd.Flags().BoolVar(&noAssets, "no-assets", false, "Copy no game files: units.json data and generated placeholders only (recorded as assets: none in metadata.json)")
+	describeFactionCmd.Flags().IntVar(&ioLimitFlag, "io-limit", 0, "Files read at once across parsing, copying and mod downloads (0 = pick from the PA root's disk type)")
 }
 
 func runDescribeFaction(cmd *cobra.Command, args []string) error {
@@ -110,60 +195,214 @@ func runDescribeFaction(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load local profiles: %w", err)
 	}
 
-	// Handle --list-profiles
+	// Handle --list-profiles and --list-mods
 	if listProfiles {
 		return listAvailableProfiles(profileLoader)
 	}
+	if listMods {
+		return listInstalledMods(paDataRoot)
+	}
+	if jsonOutput {
+		return fmt.Errorf("--json only applies to --list-profiles and --list-mods\n\nAn extraction writes its results to --output")
+	}
 
-	// Determine which mode we're in (profile vs manual)
-	profile, err := resolveProfileFromFlags(profileLoader, profileFlag, factionNameFlag, factionUnitTypeFlag, modIDs)
-	if err != nil {
-		return err
+	if versionFlag != "" && autoVersion {
+		return fmt.Errorf("--version and --auto-version can't be combined\n\n--auto-version derives the version from the previous export")
+	}
+	if versionFlag != "" && len(profileFlags) > 1 {
+		return fmt.Errorf("--version can't be combined with several --profile flags\n\nSet the version in each profile, or use --auto-version")
+	}
+	if fixturesDir != "" && len(profileFlags) > 1 {
+		return fmt.Errorf("--fixtures can't be combined with several --profile flags\n\nEach faction needs its own fixtures directory; export them one at a time")
 	}
 
-	// Apply --version flag override (takes priority over profile/mod version)
-	if versionFlag != "" {
-		profile.Version = versionFlag
+	if modsFlag != "" {
+		if err := addEnabledMods(); err != nil {
+			return err
+		}
 	}
 
-	// Auto-detect version from version.txt for base game factions (no mods)
-	// Priority: --version flag > profile.Version > version.txt > mod version > error
-	if profile.Version == "" && len(profile.Mods) == 0 && paRoot != "" {
-		if detected := detectPAVersion(paRoot); detected != "" {
-			logVerbose("Auto-detected PA version from game files: %s", detected)
-			profile.Version = detected
+	// Determine which mode we're in (profile vs manual); manual mode has no profile IDs
+	ids := profileFlags
+	if len(ids) == 0 {
+		ids = []string{""}
+	}
+	batch := make([]*models.FactionProfile, 0, len(ids))
+	for _, id := range ids {
+		profile, err := resolveProfileFromFlags(profileLoader, id, factionNameFlag, factionUnitTypeFlag, modIDs)
+		if err != nil {
+			return err
+		}
+
+		// Apply --version flag override (takes priority over profile/mod version)
+		if versionFlag != "" {
+			profile.Version = versionFlag
+		}
+
+		// Priority: --version flag > profile.Version > version.txt > mod version > error
+		applyDetectedVersion(profile, paRoot)
+
+		// Validate --pa-root / --data-root
+		if err := validateFactionInputs(profile, paRoot, paDataRoot); err != nil {
+			return err
 		}
+		batch = append(batch, profile)
 	}
 
-	// Validate --pa-root / --data-root
-	if err := validateFactionInputs(profile, paRoot, paDataRoot); err != nil {
+	// Fail fast on a bad --layout or --format before any units are loaded
+	if err := validateLimits(); err != nil {
+		return err
+	}
+	if err := validateConflictsFlag(); err != nil {
+		return err
+	}
+	if err := validateLockfileFlags(); err != nil {
+		return err
+	}
+	if loadOrderFlag != loader.LoadOrderManual && loadOrderFlag != loader.LoadOrderGame {
+		return fmt.Errorf("unknown --load-order '%s' (expected %s or %s)", loadOrderFlag, loader.LoadOrderManual, loader.LoadOrderGame)
+	}
+	if _, err := exporter.ParseLayout(layoutFlag); err != nil {
+		return err
+	}
+	if _, err := exporter.ParseIndexFormat(formatFlag); err != nil {
+		return err
+	}
+	if graphFlag != "" {
+		if _, err := exporter.ParseGraphFormat(graphFlag); err != nil {
+			return err
+		}
+	}
+
+	// Same for --upload, so a typo doesn't surface only after a full export
+	if uploadFlag != "" {
+		if _, err := upload.ParseTarget(uploadFlag); err != nil {
+			return err
+		}
+	}
+	if publishFlag != "" && publishFlag != "ipfs" {
+		return fmt.Errorf("unknown --publish target '%s' (expected ipfs)", publishFlag)
+	}
+
+	limit, err := applyMemoryLimit(memoryLimit)
+	if err != nil {
 		return err
 	}
 
 	logVerbose("PA Root: %s", paRoot)
 	logVerbose("Data Root: %s", paDataRoot)
 	logVerbose("Output: %s", outputDir)
+	ioLimit, disk := extractionIOLimit(paRoot)
+	logVerbose("Parallelism: %d, IO limit: %d (%s disk)", extractionWorkers(), ioLimit, disk)
 
 	// Execute faction extraction
-	return describeFaction(profile, allowEmpty)
+	if len(batch) > 1 {
+		err = describeFactions(batch)
+	} else {
+		err = describeFaction(batch[0], allowEmpty)
+	}
+	if err != nil {
+		return err
+	}
+	printMemoryReport(limit)
+	return nil
+}
+
+// addEnabledMods adds the mods enabled in PA's mod manager to --mod for --mods enabled,
+// after any named explicitly. --data-root defaults to the platform's PA data directory.
+func addEnabledMods() error {
+	if modsFlag != "enabled" {
+		return fmt.Errorf("unknown --mods '%s' (expected enabled)", modsFlag)
+	}
+	if paDataRoot == "" {
+		detected, err := loader.GetDefaultPADataRoot()
+		if err != nil {
+			return fmt.Errorf("could not determine the PA data directory: %w\n\nPass --data-root", err)
+		}
+		paDataRoot = detected
+	}
+	enabled, err := loader.EnabledMods(paDataRoot)
+	if err != nil {
+		return err
+	}
+	if len(enabled) == 0 {
+		return fmt.Errorf("no enabled mods found in %s\n\nEnable mods in PA's mod manager (it writes server_mods/%s), or name them with --mod", paDataRoot, loader.ModsFile)
+	}
+	for _, id := range enabled {
+		if !slices.Contains(modIDs, id) {
+			modIDs = append(modIDs, id)
+		}
+	}
+	fmt.Printf("Enabled mods: %s\n", strings.Join(enabled, ", "))
+	return nil
+}
+
+// describeFactions exports several profiles in turn, sharing base game and expansion specs
+// between their loaders. A failing faction is reported and doesn't stop the others.
+func describeFactions(batch []*models.FactionProfile) error {
+	sharedBaseCache = loader.NewBaseCache()
+	defer func() { sharedBaseCache = nil }()
+
+	var failed []string
+	for i, profile := range batch {
+		if i > 0 {
+			fmt.Println()
+		}
+		if err := describeFaction(profile, allowEmpty); err != nil {
+			printStatus("✗ %s: %v\n", profile.ID, err)
+			failed = append(failed, profile.ID)
+		}
+	}
+
+	fmt.Println()
+	printStatus("✓ Exported %d of %d factions\n", len(batch)-len(failed), len(batch))
+	logVerbose("Shared base game cache: %d spec paths looked up once for all factions", sharedBaseCache.Len())
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d factions failed: %s\n\nSee the errors above; rerun with just the failed --profile flags once fixed", len(failed), len(batch), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// applyDetectedVersion auto-detects the version of base game factions (no mods) from
+// version.txt when neither --version nor the profile set one
+func applyDetectedVersion(profile *models.FactionProfile, paRoot string) {
+	if profile.Version == "" && len(profile.Mods) == 0 && paRoot != "" {
+		if detected := detectPAVersion(paRoot); detected != "" {
+			logVerbose("Auto-detected PA version from game files: %s", detected)
+			profile.Version = detected
+		}
+	}
 }
 
 // listAvailableProfiles displays all available profiles
+// profileListing is one entry of `--list-profiles --json`: the profile as it would be
+// written to a file, plus its ID and, for local profiles, where it was loaded from
+type profileListing struct {
+	ID   string `json:"id"`
+	Path string `json:"path,omitempty"`
+	*models.FactionProfile
+}
+
 func listAvailableProfiles(pl *profiles.Loader) error {
 	allProfiles := pl.GetAllProfiles()
 
+	if jsonOutput {
+		listings := make([]profileListing, 0, len(allProfiles))
+		for _, p := range allProfiles {
+			listings = append(listings, profileListing{ID: p.ID, Path: p.Path, FactionProfile: p})
+		}
+		return printJSON(listings)
+	}
+
 	fmt.Println("Available faction profiles:")
 	fmt.Println()
 
+	rows := make([][]string, 0, len(allProfiles))
 	for _, p := range allProfiles {
-		modInfo := ""
-		if len(p.Mods) > 0 {
-			modInfo = " (requires mods)"
-		}
-		fmt.Printf("  %-12s %s%s\n", p.ID, p.DisplayName, modInfo)
-		if p.Description != "" {
-			fmt.Printf("               %s\n", p.Description)
-		}
+		rows = append(rows, []string{p.ID, p.DisplayName, strconv.Itoa(len(p.Mods)), p.Description})
+	}
+	if err := style.Table(os.Stdout, []string{"ID", "NAME", "MODS", "DESCRIPTION"}, rows); err != nil {
+		return err
 	}
 
 	fmt.Println()
@@ -175,6 +414,72 @@ func listAvailableProfiles(pl *profiles.Loader) error {
 	return nil
 }
 
+// modListing is one entry of `--list-mods --json`
+type modListing struct {
+	Identifier  string   `json:"identifier"`
+	DisplayName string   `json:"displayName"`
+	Version     string   `json:"version,omitempty"`
+	Author      string   `json:"author,omitempty"`
+	Categories  []string `json:"categories,omitempty"`
+	Source      string   `json:"source"` // server_mods, client_mods or download
+	Path        string   `json:"path"`   // Mod folder, or zip file for downloads
+	OptedOut    bool     `json:"optedOut,omitempty"`
+}
+
+// listInstalledMods prints the mods FindAllMods discovers under dataRoot, or under the
+// platform's default PA data directory when dataRoot is empty
+func listInstalledMods(dataRoot string) error {
+	if dataRoot == "" {
+		detected, err := loader.GetDefaultPADataRoot()
+		if err != nil {
+			return fmt.Errorf("could not determine the PA data directory: %w\n\nPass --data-root", err)
+		}
+		dataRoot = detected
+	}
+	if err := validateDataRoot(dataRoot); err != nil {
+		return fmt.Errorf("invalid --data-root: %w", err)
+	}
+	allMods, err := loader.FindAllMods(dataRoot, false)
+	if err != nil {
+		return fmt.Errorf("failed to discover mods: %w", err)
+	}
+
+	listings := make([]modListing, 0, len(allMods))
+	for _, mod := range allMods {
+		path := mod.Directory
+		if mod.IsZipped {
+			path = mod.ZipPath
+		}
+		listings = append(listings, modListing{
+			Identifier:  mod.Identifier,
+			DisplayName: mod.DisplayName,
+			Version:     mod.Version,
+			Author:      mod.Author,
+			Categories:  mod.Categories,
+			Source:      string(mod.SourceType),
+			Path:        path,
+			OptedOut:    mod.OptedOut(),
+		})
+	}
+	sort.Slice(listings, func(i, j int) bool { return listings[i].Identifier < listings[j].Identifier })
+
+	if jsonOutput {
+		return printJSON(listings)
+	}
+
+	fmt.Printf("Mods found in %s:\n\n", dataRoot)
+	rows := make([][]string, 0, len(listings))
+	for _, m := range listings {
+		rows = append(rows, []string{m.Identifier, m.DisplayName, m.Version, m.Source})
+	}
+	if err := style.Table(os.Stdout, []string{"ID", "NAME", "VERSION", "SOURCE"}, rows); err != nil {
+		return err
+	}
+	fmt.Println()
+	fmt.Println("Pass IDs to --mod, or list them in a profile's mods")
+	return nil
+}
+
 // validateDataRoot checks if the provided data root looks like a valid PA data directory
 func validateDataRoot(dataRoot string) error {
 	// Check if directory exists
@@ -212,6 +517,8 @@ func validateDataRoot(dataRoot string) error {
 // All factions (base game and modded) use the same logic - the only difference
 // is whether the profile has mods or not.
 func describeFaction(profile *models.FactionProfile, allowEmpty bool) error {
+	activeProfile = profile
+
 	// Validate we have a faction unit type (not required for addons, but useful for categorization)
 	// This is defensive: profiles loaded from files are validated in loader.go,
 	// but profiles built from CLI flags (manual mode) bypass that validation.
@@ -232,40 +539,331 @@ func describeFaction(profile *models.FactionProfile, allowEmpty bool) error {
 	}
 	fmt.Println()
 
-	// Resolve mods, build the overlay loader, and load units (shared with extract-models)
-	l, units, resolvedMods, baseFactions, err := loadFactionUnits(profile, paRoot, paDataRoot, allowEmpty)
+	valueConfig := combat.DefaultValueConfig()
+	if combatValueConfig != "" {
+		var err error
+		if valueConfig, err = combat.LoadValueConfig(combatValueConfig); err != nil {
+			return err
+		}
+	}
+
+	// Resolve mods and build the overlay loader (shared with extract-models)
+	l, resolvedMods, err := openFactionLoader(profile, paRoot, paDataRoot)
 	if err != nil {
 		return err
 	}
 	defer l.Close()
 
+	if frozen {
+		if err := checkFrozen(profile, resolvedMods); err != nil {
+			return err
+		}
+	}
+
+	// Snapshot the export about to be overwritten, to suggest a version from what changed
+	factionDir := filepath.Join(outputDir, exporter.SanitizeFolderName(profile.DisplayName))
+	previous, err := readPreviousExport(factionDir)
+	if err != nil {
+		return err
+	}
+
+	// Keep the unit IDs of the earlier export, so web app links survive new colliding files
+	ids, err := pinUnitIDs(l, factionDir)
+	if err != nil {
+		return err
+	}
+
+	// Parsed units are checkpointed until the export succeeds, so --resume can skip parsing
+	checkpointPath := checkpoint.Path(outputDir, exporter.SanitizeFolderName(profile.DisplayName))
+	checkpointKey, err := factionCheckpointKey(profile, resolvedMods, allowEmpty)
+	if err != nil {
+		return err
+	}
+
+	var units []models.Unit
+	var addon *models.CrossFactionLinks
+	if resumeFlag {
+		cp, err := checkpoint.Load(checkpointPath, checkpointKey)
+		switch {
+		case err == nil:
+			units = cp.Units
+			if profile.IsAddon {
+				addon = &models.CrossFactionLinks{BaseFactions: cp.BaseFactions, Edges: cp.CrossFaction}
+			}
+			printStatus("✓ Resuming from checkpoint: %d units parsed at %s\n", len(units), cp.CreatedAt.Local().Format("2006-01-02 15:04"))
+		case errors.Is(err, checkpoint.ErrNotFound):
+			i18n.Println("No checkpoint to resume from, running a full extraction")
+		case errors.Is(err, checkpoint.ErrStale):
+			printStatus("⚠ Checkpoint was written for different inputs (profile, mods, PA build or CLI version), running a full extraction\n")
+		default:
+			printStatus("⚠ Ignoring unreadable checkpoint: %v\n", err)
+		}
+	}
+
+	if units == nil {
+		units, addon, err = parseFactionUnits(l, profile, allowEmpty)
+		if err != nil {
+			return err
+		}
+		cp := checkpoint.New(checkpointKey, units, nil)
+		if addon != nil {
+			cp.BaseFactions, cp.CrossFaction = addon.BaseFactions, addon.Edges
+		}
+		if err := checkpoint.Save(checkpointPath, cp); err != nil {
+			printStatus("⚠ Could not write checkpoint (--resume won't be available): %v\n", err)
+		} else {
+			logVerbose("Checkpoint written: %s", checkpointPath)
+		}
+	}
+
+	combat.AssignValues(units, valueConfig)
+	if contentQA {
+		if n := parser.CheckContent(units); n > 0 {
+			printStatus("⚠ Content QA: %d problem(s) recorded as unit warnings\n", n)
+		} else {
+			printStatus("✓ Content QA: no problems found\n")
+		}
+	}
+
+	locked, err := lockInputs(profile, resolvedMods)
+	if err != nil {
+		return err
+	}
+
+	// --auto-version replaces the version after export; until then the previous one stands in
+	if autoVersion && previous != nil && profile.Version == "" {
+		profile.Version = previous.Metadata.Version
+	}
+
 	// Create metadata from profile
 	metadata, err := exporter.CreateMetadataFromProfile(profile, resolvedMods)
 	if err != nil {
 		return err
 	}
 
+	// Record the installation's build so `pa-pedia status` can spot stale exports
+	metadata.PABuild = detectPAVersion(paRoot)
+
 	// Set addon flag and detect base factions if this is an addon
 	if profile.IsAddon {
 		metadata.IsAddon = true
-		metadata.BaseFactions = baseFactions
+		metadata.BaseFactions = addon.BaseFactions
+	}
+
+	// --zip streams the export into <faction>.zip (see ExportFactionZip). Publishing,
+	// uploading and the version suggestion need every file on disk, so with any of them the
+	// folder is built in a hidden staging directory and zipped once it is complete; the
+	// earlier export read above stays where it is
+	streamZip := zipFlag && previous == nil && publishFlag == "" && uploadFlag == ""
+	exportDir := outputDir
+	if zipFlag && !streamZip {
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+		if exportDir, err = os.MkdirTemp(outputDir, ".zip-staging-"); err != nil {
+			return fmt.Errorf("failed to create staging directory: %w", err)
+		}
+		defer os.RemoveAll(exportDir)
+		factionDir = filepath.Join(exportDir, exporter.SanitizeFolderName(profile.DisplayName))
 	}
 
 	// Export faction
 	fmt.Println("\nExporting faction folder...")
-	exp := exporter.NewFactionExporter(outputDir, l, verbose)
-	if err := exp.ExportFaction(metadata, units); err != nil {
-		return fmt.Errorf("failed to export faction: %w", err)
+	exp, err := exporter.NewExporter(layoutFlag, exporter.IndexOptions{
+		Format: formatFlag,
+		JSON:   exporter.JSONOptions{Minify: minifyJSON, PruneEmpty: pruneEmpty},
+	}, exportDir, l, verbose)
+	if err != nil {
+		return err
+	}
+	if silhouetteSize > maxSilhouetteSize {
+		return fmt.Errorf("--silhouettes %d is too large (max %d pixels)", silhouetteSize, maxSilhouetteSize)
+	}
+	if silhouetteSize > 0 && assetsMode() == exporter.AssetsNone {
+		printStatus("⚠ --silhouettes is ignored with --no-assets\n")
+	}
+	fe, ok := exp.(*exporter.FactionExporter)
+	if ok {
+		fe.Silhouettes = silhouetteSize
+		fe.Parallelism = extractionWorkers()
+		fe.Assets = assetsMode()
+		fe.Credits = true
+		fe.Mods = resolvedMods
 	}
 
-	// Copy background image if specified
-	factionDir := filepath.Join(outputDir, exporter.SanitizeFolderName(metadata.DisplayName))
-	if err := copyBackgroundImage(profile, factionDir, exp); err != nil {
-		return fmt.Errorf("failed to copy background image: %w", err)
+	// finish writes the reports and extra files beside units.json; when streaming it runs
+	// on the staging folder of top-level files before they're added to the archive
+	finish := func(factionDir string) error {
+		if err := warnReferenceIssues(factionDir); err != nil {
+			return err
+		}
+		if addon != nil {
+			if err := exporter.WriteCrossFactionLinks(factionDir, *addon); err != nil {
+				return err
+			}
+		}
+		if len(resolvedMods) > 1 {
+			if err := writeConflictReport(l, resolvedMods, units, factionDir); err != nil {
+				return err
+			}
+		}
+		if missiles := exporter.BuildMissileReport(units); len(missiles.Launchers) > 0 {
+			if err := exporter.WriteMissileReport(factionDir, missiles); err != nil {
+				return err
+			}
+			logVerbose("Wrote %s: %d missile launcher(s) and interceptor(s)", exporter.MissilesFile, len(missiles.Launchers))
+		}
+		if graphFlag != "" {
+			if err := exporter.WriteBuildGraph(factionDir, graphFlag, units); err != nil {
+				return err
+			}
+			logVerbose("Wrote %s", exporter.GraphFileName(graphFlag))
+		}
+		if fixturesDir != "" {
+			if err := writeFixtures(units); err != nil {
+				return err
+			}
+		}
+		// Copy background image if specified (it's mod art, so not in stripped exports)
+		if assetsMode() == "" {
+			if err := copyBackgroundImage(profile, factionDir, exp); err != nil {
+				return fmt.Errorf("failed to copy background image: %w", err)
+			}
+		}
+
+		if previous != nil {
+			if err := suggestVersion(previous, factionDir); err != nil {
+				return err
+			}
+		}
+		return recordUnitIDs(ids, factionDir, units)
 	}
 
-	fmt.Println("\n✓ Faction extraction complete!")
-	fmt.Printf("Faction '%s' exported to: %s\n", profile.DisplayName, outputDir)
+	exportedTo := outputDir
+	if streamZip && ok {
+		fe.ZipFinish = finish
+		if exportedTo, err = fe.ExportFactionZip(metadata, units); err != nil {
+			return i18n.Errorf("failed to export faction: %w\n\nParsed units were checkpointed; rerun with --resume to skip parsing", err)
+		}
+	} else {
+		if err := exp.ExportFaction(metadata, units); err != nil {
+			return i18n.Errorf("failed to export faction: %w\n\nParsed units were checkpointed; rerun with --resume to skip parsing", err)
+		}
+		if err := finish(factionDir); err != nil {
+			return err
+		}
+	}
+	if err := checkpoint.Remove(checkpointPath); err != nil {
+		printStatus("⚠ Could not remove checkpoint %s: %v\n", checkpointPath, err)
+	}
+	if err := updateLockfile(profile.ID, locked); err != nil {
+		return err
+	}
+
+	// Publish before uploading so the uploaded metadata.json carries the CID
+	if publishFlag == "ipfs" {
+		if err := publishFactionToIPFS(factionDir); err != nil {
+			return err
+		}
+	}
+
+	if uploadFlag != "" {
+		if err := uploadFactionFolder(factionDir); err != nil {
+			return err
+		}
+	}
+
+	if zipFlag && !streamZip {
+		exportedTo = filepath.Join(outputDir, exporter.SanitizeFolderName(profile.DisplayName)+exporter.ZipExtension)
+		if err := exporter.ZipFaction(factionDir, exportedTo); err != nil {
+			return err
+		}
+	}
+
+	printStatus("\n✓ Faction extraction complete!\n")
+	i18n.Printf("Faction '%s' exported to: %s\n", profile.DisplayName, exportedTo)
+	printReadReport(l.ReadStats())
+	return nil
+}
+
+// writeConflictReport writes conflicts.json: every exported unit file that several selected
+// mods provide, with the fields their copies disagree on
+func writeConflictReport(l *loader.Loader, mods []*loader.ModInfo, units []models.Unit, factionDir string) error {
+	ids := make([]string, len(mods))
+	for i, mod := range mods {
+		ids[i] = mod.Identifier
+	}
+	report, err := exporter.BuildConflictReport(l, ids, units)
+	if err != nil {
+		return fmt.Errorf("failed to build conflict report: %w", err)
+	}
+	if err := exporter.WriteConflictReport(factionDir, report); err != nil {
+		return err
+	}
+	if len(report.Units) > 0 {
+		printStatus("⚠ %d unit(s) have files from more than one selected mod (see conflicts.json)\n", len(report.Units))
+	} else {
+		logVerbose("No unit files provided by more than one selected mod")
+	}
+	return nil
+}
+
+// factionCheckpointKey fingerprints everything that determines the parsed units, so a
+// checkpoint is never resumed against a changed profile, mod, PA build or CLI version
+func factionCheckpointKey(profile *models.FactionProfile, resolvedMods []*loader.ModInfo, allowEmpty bool) (string, error) {
+	profileJSON, err := json.Marshal(profile)
+	if err != nil {
+		return "", fmt.Errorf("failed to fingerprint profile: %w", err)
+	}
+
+	parts := []string{Version, string(profileJSON), paRoot, paDataRoot, detectPAVersion(paRoot), fmt.Sprint(allowEmpty)}
+	for _, mod := range resolvedMods {
+		parts = append(parts, mod.Identifier, mod.Version, mod.ZipPath, mod.Directory)
+	}
+	return checkpoint.Key(parts...), nil
+}
+
+// publishFactionToIPFS adds the exported faction folder to the --ipfs-api node and
+// records the resulting CID in metadata.json
+func publishFactionToIPFS(factionDir string) error {
+	fmt.Printf("\nPublishing to IPFS via %s...\n", ipfsAPIFlag)
+	cid, err := upload.NewIPFSPublisher(ipfsAPIFlag).Publish(factionDir)
+	if err != nil {
+		return fmt.Errorf("IPFS publish failed: %w", err)
+	}
+
+	metadata, err := exporter.ReadFactionMetadata(factionDir)
+	if err != nil {
+		return err
+	}
+	metadata.IPFSCID = cid
+	if err := exporter.WriteFactionMetadata(factionDir, *metadata); err != nil {
+		return err
+	}
+
+	printStatus("✓ Published snapshot: ipfs://%s\n", cid)
+	return nil
+}
+
+// uploadFactionFolder pushes the exported faction folder to the --upload target
+func uploadFactionFolder(factionDir string) error {
+	target, err := upload.ParseTarget(uploadFlag)
+	if err != nil {
+		return err
+	}
+
+	uploader, err := upload.NewUploader(target)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nUploading to %s...\n", target)
+	count, err := upload.UploadPath(uploader, target, factionDir, verbose)
+	if err != nil {
+		return fmt.Errorf("upload failed after %d files: %w", count, err)
+	}
+
+	printStatus("✓ Uploaded %d files to %s/%s\n", count, target, filepath.Base(factionDir))
 	return nil
 }
 
@@ -279,10 +877,22 @@ func showAvailableMods(missingModID string, allMods map[string]*loader.ModInfo)
 	fmt.Println()
 }
 
+// assetsMode maps --strip-icons and --no-assets to the exporter's asset mode; --no-assets
+// already strips icons, so it wins
+func assetsMode() string {
+	switch {
+	case noAssets:
+		return exporter.AssetsNone
+	case stripIcons:
+		return exporter.AssetsIconsStripped
+	}
+	return ""
+}
+
 // copyBackgroundImage copies the background image from mod sources to faction output.
 // The background image path is a PA resource path (e.g., "/ui/mods/my_mod/img/bg.png").
 // The image is copied to assets/ mirroring the original path structure.
-func copyBackgroundImage(profile *models.FactionProfile, factionDir string, exp *exporter.FactionExporter) error {
+func copyBackgroundImage(profile *models.FactionProfile, factionDir string, exp exporter.Exporter) error {
 	// No background image specified
 	if profile.BackgroundImage == "" {
 		return nil
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/diff"
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffFields    []string
+	diffStatsOnly bool
+)
+
+// diffFactionsCmd compares two exported faction folders unit by unit
+var diffFactionsCmd = &cobra.Command{
+	Use:   "diff-factions <before-dir> <after-dir>",
+	Short: "Compare two exports of a faction unit by unit",
+	Long: `Compare two faction folders produced by describe-faction, e.g. the same
+faction exported from two PA builds or two versions of a balance mod.
+
+Accessible units are matched by ID. Units only in the second folder are
+listed as added, units only in the first as removed. For units in both, the
+headline stats (dps, health, cost, speed) that changed are shown with their
+delta and percentage, followed by every other exported field that changed,
+named like CSV columns (specs.combat.weapons.0.damage).
+
+--fields keeps only fields under the given prefixes and --stats-only drops
+the field list. With --json the comparison is printed as {"before", "after",
+"added", "removed", "changed": [{"id", "displayName", "stats", "fields":
+[{"field", "before", "after", "delta", "percent"}]}], "unchanged"}.`,
+	Example: `  pa-pedia diff-factions ./factions-old/MLA ./factions/MLA
+  pa-pedia diff-factions ./releases/Legion-1.2 ./releases/Legion-1.3 --fields specs.combat,specs.economy
+  pa-pedia diff-factions ./old/MLA ./new/MLA --stats-only --json`,
+	Args: cobra.ExactArgs(2),
+	RunE: runDiffFactions,
+}
+
+func init() {
+	rootCmd.AddCommand(diffFactionsCmd)
+	addJSONFlag(diffFactionsCmd)
+
+	diffFactionsCmd.Flags().StringSliceVar(&diffFields, "fields", nil, "Only compare fields under these prefixes (comma-separated, e.g. specs.combat,specs.economy)")
+	diffFactionsCmd.Flags().BoolVar(&diffStatsOnly, "stats-only", false, "Only report the headline stats (dps, health, cost, speed)")
+}
+
+func runDiffFactions(cmd *cobra.Command, args []string) error {
+	before, beforeUnits, err := loadDiffSide(args[0])
+	if err != nil {
+		return err
+	}
+	after, afterUnits, err := loadDiffSide(args[1])
+	if err != nil {
+		return err
+	}
+
+	report, err := diff.Compare(beforeUnits, afterUnits, diff.Options{Fields: diffFields})
+	if err != nil {
+		return err
+	}
+	report.Before, report.After = before, after
+	if diffStatsOnly {
+		changed := report.Changed[:0]
+		for _, u := range report.Changed {
+			u.Fields = []diff.Change{}
+			if len(u.Stats) > 0 {
+				changed = append(changed, u)
+			} else {
+				report.Unchanged++
+			}
+		}
+		report.Changed = changed
+	}
+	if jsonOutput {
+		return printJSON(report)
+	}
+
+	fmt.Printf("=== PA-Pedia Diff: %s → %s ===\n", diffLabel(before), diffLabel(after))
+	fmt.Printf("%d units → %d units: %d added, %d removed, %d changed, %d unchanged\n",
+		before.Units, after.Units, len(report.Added), len(report.Removed), len(report.Changed), report.Unchanged)
+
+	if len(report.Added) > 0 {
+		fmt.Println()
+		fmt.Printf("Added (%d):\n", len(report.Added))
+		for _, u := range report.Added {
+			fmt.Printf("  + %s (%s)\n", u.DisplayName, u.ID)
+		}
+	}
+	if len(report.Removed) > 0 {
+		fmt.Println()
+		fmt.Printf("Removed (%d):\n", len(report.Removed))
+		for _, u := range report.Removed {
+			fmt.Printf("  - %s (%s)\n", u.DisplayName, u.ID)
+		}
+	}
+	if len(report.Changed) > 0 {
+		fmt.Println()
+		fmt.Printf("Changed (%d):\n", len(report.Changed))
+		for _, u := range report.Changed {
+			fmt.Printf("  ~ %s (%s)\n", u.DisplayName, u.ID)
+			for _, c := range u.Stats {
+				fmt.Printf("      %s\n", c.Describe())
+			}
+			for _, c := range u.Fields {
+				fmt.Printf("      · %s\n", c.Describe())
+			}
+		}
+	}
+	if len(report.Added) == 0 && len(report.Removed) == 0 && len(report.Changed) == 0 {
+		fmt.Println()
+		printStatus("✓ No unit differences\n")
+	}
+	return nil
+}
+
+// loadDiffSide reads one faction folder for diff-factions: its identity and accessible units
+func loadDiffSide(factionDir string) (diff.Faction, []*models.Unit, error) {
+	index, err := exporter.ReadFactionIndex(factionDir)
+	if err != nil {
+		return diff.Faction{}, nil, fmt.Errorf("failed to load faction from %s: %w\n\nPass a folder produced by describe-faction", factionDir, err)
+	}
+	faction := diff.Faction{Dir: factionDir, Name: factionDir}
+	if metadata, err := exporter.ReadFactionMetadata(factionDir); err == nil {
+		faction.Name, faction.Version, faction.PABuild = metadata.DisplayName, metadata.Version, metadata.PABuild
+	}
+	units := stats.Units(index)
+	faction.Units = len(units)
+	return faction, units, nil
+}
+
+// diffLabel names one side of a diff, e.g. "MLA 1.2.0"
+func diffLabel(f diff.Faction) string {
+	if f.Version == "" {
+		return f.Name
+	}
+	return f.Name + " " + f.Version
+}
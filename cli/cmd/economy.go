@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/economy"
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ecoFactionDir string
+	ecoUnits      string
+)
+
+// economyCmd computes the combined economy of a described base from an exported faction folder.
+var economyCmd = &cobra.Command{
+	Use:   "economy",
+	Short: "Calculate the net economy of a described base",
+	Long: `Calculate the net metal/energy income, storage and build power of a base
+described as a list of unit counts, using an exported faction folder.
+
+Every unit is assumed to be working flat out: fabricators and factories building,
+weapons with resource costs firing. Resources whose demand exceeds income are
+flagged as stalls, with the fraction of full speed the base can sustain and how
+long its storage lasts.
+
+Unit IDs are the faction's unit identifiers (e.g. metal_extractor, energy_plant,
+fabrication_bot). A bare ID counts as one.
+
+--json prints the snapshot as {"units": [{"unitId", "count"}], "metalIncome",
+"metalDemand", "netMetal", "energyIncome", "energyDemand", "netEnergy",
+"metalStorage", "energyStorage", "buildPower", "stalls": [{"resource",
+"deficit", "efficiency", "secondsToEmpty"}]}.`,
+	Example: `  pa-pedia economy --faction ./factions/MLA --units metal_extractor:10,energy_plant:8,fabrication_bot:6
+  pa-pedia economy --faction ./factions/Legion --units l_mex:12,l_energy_plant:6,l_fabrication_bot:4,l_bot_factory`,
+	RunE: runEconomy,
+}
+
+func init() {
+	rootCmd.AddCommand(economyCmd)
+
+	economyCmd.Flags().StringVar(&ecoFactionDir, "faction", "", "Path to an exported faction folder (containing units.json)")
+	economyCmd.Flags().StringVar(&ecoUnits, "units", "", "Comma-separated unit counts, e.g. metal_extractor:10,energy_plant:8")
+	economyCmd.MarkFlagRequired("faction")
+	economyCmd.MarkFlagRequired("units")
+	addJSONFlag(economyCmd)
+}
+
+// economyReport is the --json output of the economy command
+type economyReport struct {
+	Units []economy.UnitCount `json:"units"`
+	*economy.Snapshot
+	NetMetal  float64 `json:"netMetal"`
+	NetEnergy float64 `json:"netEnergy"`
+}
+
+func runEconomy(cmd *cobra.Command, args []string) error {
+	counts, err := economy.ParseUnitCounts(ecoUnits)
+	if err != nil {
+		return fmt.Errorf("invalid --units: %w", err)
+	}
+
+	index, err := exporter.ReadFactionIndex(ecoFactionDir)
+	if err != nil {
+		return fmt.Errorf("failed to load faction from %s: %w\n\nPoint --faction at a folder produced by describe-faction", ecoFactionDir, err)
+	}
+
+	units := make(map[string]*models.Unit, len(index.Units))
+	for i := range index.Units {
+		units[index.Units[i].Unit.ID] = &index.Units[i].Unit
+	}
+	logVerbose("Loaded %d units from %s", len(units), ecoFactionDir)
+
+	snapshot, err := economy.Calculate(units, counts)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		if snapshot.Stalls == nil {
+			snapshot.Stalls = []economy.Stall{}
+		}
+		return printJSON(economyReport{Units: counts, Snapshot: snapshot, NetMetal: snapshot.NetMetal(), NetEnergy: snapshot.NetEnergy()})
+	}
+
+	fmt.Println("=== PA-Pedia Economy Snapshot ===")
+	fmt.Println()
+	for _, c := range counts {
+		fmt.Printf("  %4d x %s (%s)\n", c.Count, units[c.UnitID].DisplayName, c.UnitID)
+	}
+	fmt.Println()
+	fmt.Printf("Metal:  +%.2f / -%.2f  net %+.2f/s  storage %.0f\n", snapshot.MetalIncome, snapshot.MetalDemand, snapshot.NetMetal(), snapshot.MetalStorage)
+	fmt.Printf("Energy: +%.2f / -%.2f  net %+.2f/s  storage %.0f\n", snapshot.EnergyIncome, snapshot.EnergyDemand, snapshot.NetEnergy(), snapshot.EnergyStorage)
+	fmt.Printf("Build power: %.2f metal/s\n", snapshot.BuildPower)
+	fmt.Println()
+
+	if len(snapshot.Stalls) == 0 {
+		printStatus("✓ No stalls: income covers demand\n")
+		return nil
+	}
+
+	for _, stall := range snapshot.Stalls {
+		printStatus("⚠ %s stall: %.2f/s short, running at %.0f%% speed", stall.Resource, stall.Deficit, stall.Efficiency*100)
+		if stall.SecondsToEmpty > 0 {
+			fmt.Printf(" once storage empties (%.1fs from full)", stall.SecondsToEmpty)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
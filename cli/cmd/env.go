@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// envPrefix starts the environment variables that supply flag values. Every flag can be
+// set as PA_PEDIA_<COMMAND>_<FLAG> (e.g. PA_PEDIA_SERVE_ADDR) or, for all commands that
+// have it, PA_PEDIA_<FLAG> (e.g. PA_PEDIA_PA_ROOT); dashes become underscores. The
+// command-specific variable wins, and a flag given on the command line wins over both.
+const envPrefix = "PA_PEDIA_"
+
+// applyEnvFlags sets the flags of cmd, and the persistent flags it inherits, from the
+// environment. It runs before flag parsing, so command-line values override what it
+// sets; flags it sets count as given for required-flag checks. Repeatable flags take a
+// comma-separated list.
+func applyEnvFlags(cmd *cobra.Command) error {
+	var err error
+	apply := func(scope string) func(*pflag.Flag) {
+		return func(f *pflag.Flag) {
+			if err != nil || f.Name == "help" {
+				return
+			}
+			name := envName(f.Name)
+			value, ok := os.LookupEnv(envPrefix + scope + name)
+			if !ok && scope != "" {
+				value, ok = os.LookupEnv(envPrefix + name)
+			}
+			if !ok {
+				return
+			}
+			if setErr := setFlagFromEnv(f, value); setErr != nil {
+				err = fmt.Errorf("invalid value %q for --%s from the environment: %w", value, f.Name, setErr)
+			}
+		}
+	}
+
+	cmd.LocalFlags().VisitAll(apply(commandScope(cmd)))
+	for c := cmd.Parent(); c != nil; c = c.Parent() {
+		c.PersistentFlags().VisitAll(apply(commandScope(c)))
+	}
+	return err
+}
+
+func setFlagFromEnv(f *pflag.Flag, value string) error {
+	// Replace rather than Set, so repeated flags on the command line replace the list
+	// instead of appending to it
+	if slice, ok := f.Value.(pflag.SliceValue); ok {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		if err := slice.Replace(items); err != nil {
+			return err
+		}
+	} else if err := f.Value.Set(value); err != nil {
+		return err
+	}
+	f.Changed = true
+	return nil
+}
+
+// commandScope is the command part of a command-specific variable name: "SERVE_" for
+// serve, "DEMO_SERVE_" for demo serve, and "" for the root command
+func commandScope(cmd *cobra.Command) string {
+	if !cmd.HasParent() {
+		return ""
+	}
+	path := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	return envName(strings.ReplaceAll(path, " ", "_")) + "_"
+}
+
+func envName(name string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+// interactive reports whether a person is likely at the terminal: stdin and stdout are
+// terminals and we're not inside a container. Unattended runs (docker, CI, cron, pipes)
+// must never block or have their binary replaced underneath them.
+func interactive() bool {
+	for _, f := range []*os.File{os.Stdin, os.Stdout} {
+		info, err := f.Stat()
+		if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return false
+		}
+	}
+	for _, marker := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(marker); err == nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// newEnvTestCommands builds "pa-pedia serve" with a persistent --pa-root on the root and
+// --addr, --port, --watch and --mod on serve, like the real command tree
+func newEnvTestCommands() (root, serve *cobra.Command) {
+	root = &cobra.Command{Use: "pa-pedia"}
+	root.PersistentFlags().String("pa-root", "", "")
+	serve = &cobra.Command{Use: "serve", RunE: func(*cobra.Command, []string) error { return nil }}
+	serve.Flags().String("addr", ":8080", "")
+	serve.Flags().Int("port", 0, "")
+	serve.Flags().Bool("watch", false, "")
+	serve.Flags().StringArray("mod", nil, "")
+	root.AddCommand(serve)
+	return root, serve
+}
+
+func TestApplyEnvFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		args    []string
+		want    map[string]string // flag → value after parsing
+		wantErr string
+	}{
+		{
+			name: "command-specific variable",
+			env:  map[string]string{"PA_PEDIA_SERVE_ADDR": ":9000"},
+			want: map[string]string{"addr": ":9000"},
+		},
+		{
+			name: "global variable",
+			env:  map[string]string{"PA_PEDIA_ADDR": ":9000", "PA_PEDIA_PA_ROOT": "/pa/media"},
+			want: map[string]string{"addr": ":9000", "pa-root": "/pa/media"},
+		},
+		{
+			name: "command-specific wins over global",
+			env:  map[string]string{"PA_PEDIA_ADDR": ":9000", "PA_PEDIA_SERVE_ADDR": ":9001"},
+			want: map[string]string{"addr": ":9001"},
+		},
+		{
+			name: "flag wins over environment",
+			env:  map[string]string{"PA_PEDIA_SERVE_ADDR": ":9001", "PA_PEDIA_PA_ROOT": "/env/media"},
+			args: []string{"--addr", ":9002", "--pa-root", "/flag/media"},
+			want: map[string]string{"addr": ":9002", "pa-root": "/flag/media"},
+		},
+		{
+			name: "repeatable flag from a list",
+			env:  map[string]string{"PA_PEDIA_SERVE_MOD": "a, b,,c"},
+			want: map[string]string{"mod": "[a,b,c]"},
+		},
+		{
+			name: "repeated flags replace the environment list",
+			env:  map[string]string{"PA_PEDIA_SERVE_MOD": "a,b"},
+			args: []string{"--mod", "x", "--mod", "y"},
+			want: map[string]string{"mod": "[x,y]"},
+		},
+		{
+			name: "bool",
+			env:  map[string]string{"PA_PEDIA_SERVE_WATCH": "true"},
+			want: map[string]string{"watch": "true"},
+		},
+		{
+			name:    "bad int",
+			env:     map[string]string{"PA_PEDIA_SERVE_PORT": "eighty"},
+			wantErr: `invalid value "eighty" for --port from the environment`,
+		},
+		{
+			name:    "bad bool",
+			env:     map[string]string{"PA_PEDIA_WATCH": "sometimes"},
+			wantErr: `invalid value "sometimes" for --watch from the environment`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			_, serve := newEnvTestCommands()
+
+			err := applyEnvFlags(serve)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("applyEnvFlags() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyEnvFlags() error = %v", err)
+			}
+			if err := serve.ParseFlags(tt.args); err != nil {
+				t.Fatalf("ParseFlags(%v) error = %v", tt.args, err)
+			}
+
+			got := make(map[string]string, len(tt.want))
+			for name := range tt.want {
+				f := serve.Flags().Lookup(name)
+				if f == nil {
+					t.Fatalf("no flag --%s", name)
+				}
+				got[name] = f.Value.String()
+				if !f.Changed {
+					t.Errorf("--%s not marked as set", name)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("flags = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandScope(t *testing.T) {
+	root, serve := newEnvTestCommands()
+	sub := &cobra.Command{Use: "list-all"}
+	serve.AddCommand(sub)
+
+	for cmd, want := range map[*cobra.Command]string{root: "", serve: "SERVE_", sub: "SERVE_LIST_ALL_"} {
+		if got := commandScope(cmd); got != want {
+			t.Errorf("commandScope(%s) = %q, want %q", cmd.Name(), got, want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// extractCmd catches the pre-profile `extract base|mod` invocations, which describe-faction
+// replaced, and prints the equivalent describe-faction command instead of "unknown command".
+var extractCmd = &cobra.Command{
+	Use:                "extract",
+	Short:              "Removed: use describe-faction",
+	Hidden:             true,
+	DisableFlagParsing: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("the extract command was removed; describe-faction replaces extract base and extract mod\n\nRun instead:\n  pa-pedia %s\n\nSee pa-pedia describe-faction --list-profiles for the built-in factions", describeFactionEquivalent(args))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(extractCmd)
+}
+
+// describeFactionEquivalent maps an old extract invocation onto describe-faction. `extract base`
+// becomes the MLA profile; `extract mod` keeps its --mod and needs a name and faction unit
+// type, which it never took. --pa-root, --data-root and --output carry over as they are.
+func describeFactionEquivalent(args []string) string {
+	parts := []string{"describe-faction"}
+	var mods []string
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--pa-root", "--data-root", "--output", "--mod":
+			if !hasValue {
+				if i+1 >= len(args) {
+					continue
+				}
+				i++
+				value = args[i]
+			}
+			if name == "--mod" {
+				mods = append(mods, value)
+				continue
+			}
+			parts = append(parts, name, quoteArg(value))
+		}
+	}
+
+	if len(args) > 0 && args[0] == "mod" {
+		parts = append(parts[:1], append([]string{"--name", "<faction name>", "--faction-unit-type", "<e.g. Custom58>"}, parts[1:]...)...)
+		if len(mods) == 0 {
+			mods = append(mods, "<mod id>")
+		}
+		for _, mod := range mods {
+			parts = append(parts, "--mod", quoteArg(mod))
+		}
+	} else {
+		parts = append(parts[:1], append([]string{"--profile", "mla"}, parts[1:]...)...)
+	}
+	return strings.Join(parts, " ")
+}
+
+func quoteArg(value string) string {
+	if strings.ContainsAny(value, " \t") {
+		return `"` + value + `"`
+	}
+	return value
+}
@@ -74,6 +74,7 @@ func init() {
 
 	extractModelsCmd.Flags().StringVar(&emPaRoot, "pa-root", "", "Path to PA Titans media directory")
 	extractModelsCmd.Flags().StringVar(&emPaDataRoot, "data-root", "", "Path to PA data directory (required when local mods are involved)")
+	extractModelsCmd.Flags().StringArrayVar(&overrideOptOut, "override-opt-out", nil, "Extract this mod even though its author opted out of exports (repeatable; each opted-out mod must be named)")
 	extractModelsCmd.Flags().StringVar(&emOutputDir, "output", "./models", "Output directory for faction model bundles")
 
 	extractModelsCmd.Flags().StringVar(&emBlenderPath, "blender", "", "Path to the Blender executable (default: $BLENDER, then 'blender' on PATH)")
@@ -139,7 +140,7 @@ func runExtractModels(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Println()
-	fmt.Println("✓ Model extraction complete!")
+	printStatus("✓ Model extraction complete!\n")
 	fmt.Printf("  Units considered: %d\n", stats.Total)
 	fmt.Printf("  Converted:        %d\n", stats.Converted)
 	fmt.Printf("  Skipped (no model): %d\n", stats.Skipped)
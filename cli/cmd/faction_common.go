@@ -2,13 +2,23 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/jamiemulcahy/pa-pedia/pkg/basecache"
 	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
 	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/parallel"
 	"github.com/jamiemulcahy/pa-pedia/pkg/parser"
 	"github.com/jamiemulcahy/pa-pedia/pkg/profiles"
 )
 
+// overrideOptOut is --override-opt-out: mods to extract even though their authors opted out.
+// Each must be named, so nobody overrides an opt-out they haven't seen.
+var overrideOptOut []string
+
 // resolveProfileFromFlags turns the profile/manual-mode flags into a
 // FactionProfile, applying the same rules as describe-faction (mutually
 // exclusive --profile/--name, CLI --mod flags prepended at highest priority).
@@ -24,9 +34,22 @@ func resolveProfileFromFlags(pl *profiles.Loader, profileID, name, unitType stri
 			return nil, fmt.Errorf("profile '%s' not found\n\nUse --list-profiles to see available profiles", profileID)
 		}
 		logVerbose("Using profile: %s (%s)", profile.ID, profile.DisplayName)
-		// CLI --mod flags go first (highest priority)
+		if len(profile.Includes) > 0 {
+			profile, err = pl.ExpandComposite(profile)
+			if err != nil {
+				return nil, err
+			}
+			logVerbose("Composite profile includes: %v", profile.Includes)
+		}
+		// CLI --mod flags go first (highest priority); a mod the profile also lists is kept once
 		if len(mods) > 0 {
-			profile.Mods = append(mods, profile.Mods...)
+			merged := append([]string{}, mods...)
+			for _, mod := range profile.Mods {
+				if !slices.Contains(merged, mod) {
+					merged = append(merged, mod)
+				}
+			}
+			profile.Mods = merged
 		}
 		return profile, nil
 	}
@@ -84,12 +107,36 @@ func validateFactionInputs(profile *models.FactionProfile, paRoot, paDataRoot st
 // resources (specs, icons, .papa models) from the same overlay. Callers MUST
 // defer l.Close().
 //
-// baseFactions is populated (from detected unit faction types) only for addon
-// profiles; it is nil otherwise.
+// addon is populated (base factions detected from unit faction types, and the
+// addon units' build relationships to base units) only for addon profiles; it
+// is nil otherwise.
 //
 // Shared by `describe-faction` and `extract-models` so both consume identical
 // overlay/provenance resolution.
-func loadFactionUnits(profile *models.FactionProfile, paRoot, paDataRoot string, allowEmpty bool) (*loader.Loader, []models.Unit, []*loader.ModInfo, []string, error) {
+func loadFactionUnits(profile *models.FactionProfile, paRoot, paDataRoot string, allowEmpty bool) (*loader.Loader, []models.Unit, []*loader.ModInfo, *models.CrossFactionLinks, error) {
+	l, resolvedMods, err := openFactionLoader(profile, paRoot, paDataRoot)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	units, addon, err := parseFactionUnits(l, profile, allowEmpty)
+	if err != nil {
+		l.Close()
+		return nil, nil, nil, nil, err
+	}
+	return l, units, resolvedMods, addon, nil
+}
+
+// loadOrderFlag is describe-faction's --load-order; other commands keep the listed order
+var loadOrderFlag string
+
+// sharedBaseCache, when set, is shared by every loader openFactionLoader opens, for runs that
+// export several factions (describe-faction with several --profile flags, daemon runs)
+var sharedBaseCache *loader.BaseCache
+
+// openFactionLoader resolves a profile's mod sources and builds the overlay loader: the
+// first phase of loadFactionUnits. Callers MUST close the returned loader.
+func openFactionLoader(profile *models.FactionProfile, paRoot, paDataRoot string) (*loader.Loader, []*loader.ModInfo, error) {
 	var resolvedMods []*loader.ModInfo
 
 	// If profile has mods, discover and resolve them
@@ -110,14 +157,27 @@ func loadFactionUnits(profile *models.FactionProfile, paRoot, paDataRoot string,
 		// Resolve GitHub mods first (they have highest priority as they appear first in the list)
 		if len(githubModURLs) > 0 {
 			fmt.Println("Resolving GitHub mods...")
-			for _, url := range githubModURLs {
-				modInfo, err := loader.ResolveGitHubMod(url, verbose)
-				if err != nil {
-					return nil, nil, nil, nil, fmt.Errorf("failed to resolve GitHub mod: %w", err)
+			commits, err := pinnedGitHubCommits(profile, githubModURLs)
+			if err != nil {
+				return nil, nil, err
+			}
+			// Download concurrently under the IO limit, then report in profile order
+			ioLimit, _ := extractionIOLimit(paRoot)
+			githubMods := make([]*loader.ModInfo, len(githubModURLs))
+			githubErrs := make([]error, len(githubModURLs))
+			parallel.ForEach(len(githubModURLs), ioLimit, func(i int) {
+				githubMods[i], githubErrs[i] = loader.ResolveGitHubMod(githubModURLs[i], commits[githubModURLs[i]], verbose)
+			})
+			for i, modInfo := range githubMods {
+				if err := githubErrs[i]; err != nil {
+					return nil, nil, fmt.Errorf("failed to resolve GitHub mod: %w", err)
 				}
 				resolvedMods = append(resolvedMods, modInfo)
-				fmt.Printf("  ✓ %s (%s) [%s]\n", modInfo.Identifier, modInfo.DisplayName, modInfo.SourceType)
+				printStatus("  ✓ %s (%s) [%s]\n", modInfo.Identifier, modInfo.DisplayName, modInfo.SourceType)
 				fmt.Printf("    Source: %s (zip)\n", modInfo.ZipPath)
+				if modInfo.GitHubCommit != "" {
+					fmt.Printf("    Commit: %s (%s)\n", modInfo.GitHubCommit, modInfo.GitHubRef)
+				}
 			}
 			fmt.Println()
 		}
@@ -127,7 +187,7 @@ func loadFactionUnits(profile *models.FactionProfile, paRoot, paDataRoot string,
 			fmt.Println("Discovering local mods...")
 			allMods, err := loader.FindAllMods(paDataRoot, verbose)
 			if err != nil {
-				return nil, nil, nil, nil, fmt.Errorf("failed to discover mods: %w", err)
+				return nil, nil, fmt.Errorf("failed to discover mods: %w", err)
 			}
 
 			fmt.Printf("Found %d total mods across all locations\n", len(allMods))
@@ -143,11 +203,11 @@ func loadFactionUnits(profile *models.FactionProfile, paRoot, paDataRoot string,
 				modInfo, ok := allMods[modID]
 				if !ok {
 					showAvailableMods(modID, allMods)
-					return nil, nil, nil, nil, fmt.Errorf("mod not found: %s", modID)
+					return nil, nil, fmt.Errorf("mod not found: %s", modID)
 				}
 
 				resolvedMods = append(resolvedMods, modInfo)
-				fmt.Printf("  ✓ %s (%s) [%s]\n", modInfo.Identifier, modInfo.DisplayName, modInfo.SourceType)
+				printStatus("  ✓ %s (%s) [%s]\n", modInfo.Identifier, modInfo.DisplayName, modInfo.SourceType)
 				if modInfo.IsZipped {
 					fmt.Printf("    Source: %s (zip)\n", modInfo.ZipPath)
 				} else {
@@ -158,17 +218,67 @@ func loadFactionUnits(profile *models.FactionProfile, paRoot, paDataRoot string,
 		}
 	}
 
+	if loadOrderFlag == loader.LoadOrderGame && len(resolvedMods) > 1 {
+		ordered, warnings, err := loader.GameLoadOrder(resolvedMods)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, w := range warnings {
+			printStatus("⚠ %s\n", w)
+		}
+		resolvedMods = ordered
+		fmt.Println("Game load order (first wins):")
+		for i, mod := range resolvedMods {
+			fmt.Printf("  %d. %s\n", i+1, mod.Identifier)
+		}
+		fmt.Println()
+	}
+
+	if profile.IconOverrides != "" {
+		if info, err := os.Stat(profile.IconOverrides); err != nil || !info.IsDir() {
+			return nil, nil, fmt.Errorf("icon overrides folder not found: %s\n\nCreate it or fix iconOverrides in profile '%s' (relative paths resolve against the profile's folder)", profile.IconOverrides, profile.ID)
+		}
+		fmt.Printf("Icon overrides: %s\n", profile.IconOverrides)
+	}
+
+	if err := checkOptOuts(profile, resolvedMods); err != nil {
+		return nil, nil, err
+	}
+
 	// Create multi-source loader (works for both base game and modded)
 	fmt.Println("Initializing loader...")
 	l, err := loader.NewMultiSourceLoader(paRoot, "pa_ex1", resolvedMods)
 	if err != nil {
-		return nil, nil, nil, nil, fmt.Errorf("failed to create loader: %w", err)
+		return nil, nil, fmt.Errorf("failed to create loader: %w", err)
+	}
+	if sharedBaseCache != nil {
+		l.SetBaseCache(sharedBaseCache)
+	}
+	l.SetIconOverrides(profile.Icons)
+	l.SetIconOverrideDir(profile.IconOverrides)
+	l.SetSourcePreferences(profile.ConflictPreferences)
+	ioLimit, _ := extractionIOLimit(paRoot)
+	l.SetIOLimit(ioLimit)
+
+	if verbose {
+		atlases, atlasErrs := l.IconAtlases()
+		for _, atlas := range atlases {
+			logVerbose("Icon atlas %s (%s): %d icons", atlas.ResourcePath, atlas.Source, len(atlas.Frames))
+		}
+		for _, err := range atlasErrs {
+			logVerbose("Skipped %v", err)
+		}
 	}
 
-	// From here on, any error must close the loader before returning.
-	fail := func(err error) (*loader.Loader, []models.Unit, []*loader.ModInfo, []string, error) {
-		l.Close()
-		return nil, nil, nil, nil, err
+	return l, resolvedMods, nil
+}
+
+// parseFactionUnits loads the faction's units through an open loader: the second, expensive
+// phase of loadFactionUnits (merged unit list, parsing, build tree and faction filtering).
+// The loader is left open on error; the caller owns it.
+func parseFactionUnits(l *loader.Loader, profile *models.FactionProfile, allowEmpty bool) ([]models.Unit, *models.CrossFactionLinks, error) {
+	fail := func(err error) ([]models.Unit, *models.CrossFactionLinks, error) {
+		return nil, nil, err
 	}
 
 	// Load merged unit list (for verbose output)
@@ -191,14 +301,28 @@ func loadFactionUnits(profile *models.FactionProfile, paRoot, paDataRoot string,
 			}
 		}
 		fmt.Println()
+
+		// Composite profiles stack several mods that may define the same unit.
+		// The overlay already resolved these first-wins (or by the profile's
+		// conflictPreferences); report who won, or decide with --conflicts.
+		if conflictsFlag != "" {
+			if err := resolveUnitConflicts(profile, l, l.FindUnitConflicts(unitPaths)); err != nil {
+				return fail(err)
+			}
+		} else if len(profile.Includes) > 0 {
+			reportUnitConflicts(l.FindUnitConflicts(unitPaths))
+		}
 	}
 
 	// Create database parser and load units
 	fmt.Println("Loading units...")
 	db := parser.NewDatabase(l)
+	db.Commanders = profile.Commanders
+	db.ExcludeCommanders = profile.ExcludeCommanders
+	db.Parallelism = extractionWorkers()
 
 	var units []models.Unit
-	var baseFactions []string
+	var addon *models.CrossFactionLinks
 
 	if profile.IsAddon {
 		// ADDON PATH: Load all units, then filter out base game units
@@ -206,52 +330,139 @@ func loadFactionUnits(profile *models.FactionProfile, paRoot, paDataRoot string,
 			return fail(fmt.Errorf("failed to load units: %w", err))
 		}
 
-		// Load base game units for comparison (MLA = Custom58).
+		// Find the base game units (MLA = Custom58) among the units just parsed.
 		// All PA addon mods shadow MLA units regardless of which factions they extend.
-		fmt.Println("\nLoading base game units for comparison...")
-		baseLoader, err := loader.NewMultiSourceLoader(paRoot, "pa_ex1", nil)
+		baseUnitIDs, err := loadBaseGameUnitIDs(l, db)
 		if err != nil {
-			return fail(fmt.Errorf("failed to create base game loader: %w", err))
-		}
-		defer baseLoader.Close()
-
-		baseDB := parser.NewDatabase(baseLoader)
-		if err := baseDB.LoadUnitsNoFilter(verbose); err != nil {
-			return fail(fmt.Errorf("failed to load base game units: %w", err))
+			return fail(err)
 		}
-
-		baseUnitIDs := baseDB.GetUnitIDs()
-		fmt.Printf("Loaded %d base game units for comparison\n", len(baseUnitIDs))
+		baseDB := db.Subset(baseUnitIDs)
 
 		filteredCount := db.FilterOutUnits(baseUnitIDs)
-		fmt.Printf("Filtered out %d base game units, keeping %d addon units\n", filteredCount, len(db.Units))
+		fmt.Printf("Filtered out %d base game units, keeping %d addon units\n", filteredCount, db.Len())
 
-		if len(db.Units) == 0 {
+		if db.Len() == 0 {
 			if allowEmpty {
-				fmt.Printf("\n⚠ WARNING: No new units found in addon (all units exist in base game)\n")
+				printStatus("\n⚠ WARNING: No new units found in addon (all units exist in base game)\n")
 				fmt.Printf("   The faction export will contain 0 units (--allow-empty is set).\n\n")
 			} else {
 				return fail(fmt.Errorf("no new units found in addon (all units exist in base game)\n\nThe addon appears to only shadow base game units without adding new ones.\nTo allow empty exports, use the --allow-empty flag"))
 			}
 		}
 
-		units = db.GetUnitsArray()
+		units = db.Snapshot().Units()
 		fmt.Printf("\nLoaded %d addon units\n", len(units))
 
 		// Auto-detect which base factions this addon extends from the
 		// remaining units' faction types (used for the "Extends: ..." UI).
-		baseFactions = db.DetectBaseFactions()
+		baseFactions := db.DetectBaseFactions()
 		if verbose && len(baseFactions) > 0 {
 			fmt.Printf("Detected base factions: %v\n", baseFactions)
 		}
+
+		// Relationships to the filtered-out base units place the addon in their tech trees
+		addon = &models.CrossFactionLinks{BaseFactions: baseFactions, Edges: db.CrossFactionEdges(baseDB)}
+		logVerbose("Found %d build relationships with base faction units", len(addon.Edges))
 	} else {
 		// NORMAL PATH: Filter by faction unit type
 		if err := db.LoadUnits(verbose, profile.FactionUnitType, allowEmpty); err != nil {
 			return fail(fmt.Errorf("failed to load units: %w", err))
 		}
-		units = db.GetUnitsArray()
+		units = db.Snapshot().Units()
 		fmt.Printf("\nLoaded %d units (filtered by UNITTYPE_%s)\n", len(units), profile.FactionUnitType)
 	}
 
-	return l, units, resolvedMods, baseFactions, nil
+	return units, addon, nil
+}
+
+// loadBaseGameUnitIDs returns the IDs of every base game unit, used to filter addon
+// exports. The set is cached per PA build (see pkg/basecache); on a miss, or with
+// --no-cache, the base game's own unit list picks the units out of db, so the base game
+// isn't parsed a second time.
+func loadBaseGameUnitIDs(l *loader.Loader, db *parser.Database) (map[string]bool, error) {
+	build := baseGameBuild(l)
+	cacheDir, cacheErr := basecache.DefaultDir()
+	useCache := !noCache && build != "" && cacheErr == nil
+
+	if useCache {
+		ids, ok, err := basecache.Load(cacheDir, build)
+		if err != nil {
+			fmt.Printf("⚠ Ignoring base unit cache: %v\n", err)
+		} else if ok {
+			fmt.Printf("Using cached base game units for build %s (%d units)\n", build, len(ids))
+			return ids, nil
+		}
+	} else if build == "" {
+		logVerbose("PA build not detected; base game unit cache disabled")
+	}
+
+	basePaths, err := l.LoadBaseUnitList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load base game unit list: %w", err)
+	}
+	ids := db.UnitIDsForResources(basePaths)
+	fmt.Printf("Found %d base game units for comparison\n", len(ids))
+
+	if useCache {
+		if err := basecache.Save(cacheDir, build, ids); err != nil {
+			fmt.Printf("⚠ Could not cache base game units: %v\n", err)
+		} else {
+			logVerbose("Cached base game units for build %s in %s", build, cacheDir)
+		}
+	}
+	return ids, nil
+}
+
+// baseGameBuild detects the PA build of the loader's base game source (see
+// detectPAVersion); empty when there is none or it can't be read
+func baseGameBuild(l *loader.Loader) string {
+	for _, src := range l.Sources() {
+		if src.Type == loader.ModSourceBaseGame {
+			return detectPAVersion(filepath.Dir(src.Path))
+		}
+	}
+	return ""
+}
+
+// reportUnitConflicts prints the units defined by more than one mod in a
+// composite profile, naming the mod whose definition was kept.
+func reportUnitConflicts(conflicts []loader.UnitConflict) {
+	if len(conflicts) == 0 {
+		fmt.Println("No unit conflicts between included mods")
+		fmt.Println()
+		return
+	}
+
+	fmt.Printf("Resolved %d unit conflict(s) between included mods (first wins):\n", len(conflicts))
+	for _, c := range conflicts {
+		fmt.Printf("  - %s: using %s (overrides %s)\n", c.ResourcePath, c.Winner, strings.Join(c.Overridden, ", "))
+	}
+	fmt.Println()
+}
+
+// checkOptOuts refuses mods whose authors opted out of extraction (see loader.OptedOutMods)
+// unless each was named with --override-opt-out
+func checkOptOuts(profile *models.FactionProfile, mods []*loader.ModInfo) error {
+	overridden := make(map[string]bool, len(overrideOptOut))
+	for _, id := range overrideOptOut {
+		overridden[id] = true
+	}
+
+	var refused []loader.OptOut
+	for _, o := range loader.OptedOutMods(mods, profile.OptOutMods) {
+		if overridden[o.Mod.Identifier] {
+			printStatus("⚠ Extracting %s despite its author's opt-out (--override-opt-out)\n", o.Mod.Identifier)
+		} else {
+			refused = append(refused, o)
+		}
+	}
+	if len(refused) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	for _, o := range refused {
+		fmt.Fprintf(&b, "  %s (%s): %s\n", o.Mod.Identifier, o.Mod.DisplayName, o.Reason)
+	}
+	return fmt.Errorf("%d mod(s) opted out of pa-pedia exports:\n%s\nTheir authors asked not to have their content mirrored. If you have permission, name each one with --override-opt-out (e.g. --override-opt-out %s)",
+		len(refused), b.String(), refused[0].Mod.Identifier)
 }
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/combat"
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/synthetic"
+	"github.com/spf13/cobra"
+)
+
+var (
+	genUnits  int
+	genSeed   int64
+	genName   string
+	genOutput string
+)
+
+// maxGenUnits bounds --units; pagination tests don't need more
+const maxGenUnits = 10000
+
+// genTestdataCmd writes a synthetic faction folder for web app development.
+var genTestdataCmd = &cobra.Command{
+	Use:   "gen-testdata",
+	Short: "Generate a synthetic faction folder for testing (no PA assets)",
+	Long: `Fabricate a faction of random but plausible units (commander, fabricators,
+factories, economy, defenses and land, air and naval combat units) and export it
+exactly as describe-faction would, so the web app can be tested for pagination,
+search and comparisons without distributing real PA assets.
+
+The specs are generated into a temporary PA layout and run through the normal
+parser and exporter; icons are generated placeholders. The same --seed and
+--units always produce the same units.`,
+	Example: `  pa-pedia gen-testdata --units 50 --seed 42
+  pa-pedia gen-testdata --units 2000 --name "Big Synthetic" --output ../web/public/factions`,
+	Args: cobra.NoArgs,
+	RunE: runGenTestdata,
+}
+
+func init() {
+	rootCmd.AddCommand(genTestdataCmd)
+
+	genTestdataCmd.Flags().IntVar(&genUnits, "units", 50, "Number of units to generate, including the commander")
+	genTestdataCmd.Flags().Int64Var(&genSeed, "seed", 1, "Random seed; the same seed gives the same faction")
+	genTestdataCmd.Flags().StringVar(&genName, "name", "Synthetic", "Faction display name (also the folder name)")
+	genTestdataCmd.Flags().StringVar(&genOutput, "output", "./factions", "Output directory for the faction folder")
+}
+
+func runGenTestdata(cmd *cobra.Command, args []string) error {
+	if genUnits < 1 || genUnits > maxGenUnits {
+		return fmt.Errorf("--units must be between 1 and %d, got %d", maxGenUnits, genUnits)
+	}
+
+	root, err := os.MkdirTemp("", "pa-pedia-testdata-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(root)
+
+	if _, err := synthetic.WriteSpecs(root, synthetic.Options{Units: genUnits, Seed: genSeed}); err != nil {
+		return fmt.Errorf("failed to generate unit specs: %w", err)
+	}
+	logVerbose("Generated specs in %s", root)
+
+	l, err := loader.NewMultiSourceLoader(root, "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create loader: %w", err)
+	}
+	defer l.Close()
+
+	profile := &models.FactionProfile{
+		ID:              "synthetic",
+		DisplayName:     genName,
+		FactionUnitType: synthetic.UnitType,
+	}
+	units, _, err := parseFactionUnits(l, profile, false)
+	if err != nil {
+		return err
+	}
+	combat.AssignValues(units, combat.DefaultValueConfig())
+
+	metadata := models.FactionMetadata{
+		Identifier:  fmt.Sprintf("pa-pedia.synthetic.%d", genSeed),
+		DisplayName: genName,
+		Version:     "0.0.0-synthetic",
+		Author:      "pa-pedia gen-testdata",
+		Description: fmt.Sprintf("Synthetic test data (%d units, seed %d), not real PA data", genUnits, genSeed),
+		Type:        "mod",
+	}
+	exp := exporter.NewFactionExporter(genOutput, l, verbose)
+	if err := exp.ExportFaction(metadata, units); err != nil {
+		return fmt.Errorf("failed to export faction: %w", err)
+	}
+
+	printStatus("\n✓ Wrote %d synthetic units to %s\n", len(units), filepath.Join(genOutput, exporter.SanitizeFolderName(genName)))
+	return nil
+}
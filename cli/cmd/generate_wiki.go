@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/stats"
+	"github.com/jamiemulcahy/pa-pedia/pkg/wiki"
+	"github.com/spf13/cobra"
+)
+
+var (
+	wikiFormat   string
+	wikiTemplate string
+	wikiOutput   string
+	wikiUnit     string
+)
+
+// generateWikiCmd renders an exported faction as wiki infoboxes.
+var generateWikiCmd = &cobra.Command{
+	Use:   "generate-wiki <faction-dir>",
+	Short: "Generate wiki infobox markup for every unit of an exported faction",
+	Long: `Render each accessible unit of a faction folder produced by describe-faction
+as an infobox template call, so wiki maintainers can bulk-update unit pages
+from extracted data.
+
+--format mediawiki writes one <unit-id>.wiki file per unit into --output. Each
+holds a single {{Infobox unit ...}} call (rename the template with --template)
+with named parameters: name, id, image, description, faction, tier, types,
+health, cost, dps, salvo_damage, range, weapons, combat_value, speed,
+acceleration, turn_speed, vision, underwater_vision, radar, sonar, economy
+rates and storage, build_rate, build_range, built_by and builds. Empty
+parameters are left out; built_by and builds link to the units' pages by
+display name.
+
+With --unit, the infobox for that unit is printed instead.`,
+	Example: `  pa-pedia generate-wiki ./factions/MLA --format mediawiki --output ./wiki/MLA
+  pa-pedia generate-wiki ./factions/MLA --unit tank_light_laser
+  pa-pedia generate-wiki ./factions/Legion --template "Legion unit" --output ./wiki/Legion`,
+	Args: cobra.ExactArgs(1),
+	RunE: runGenerateWiki,
+}
+
+func init() {
+	rootCmd.AddCommand(generateWikiCmd)
+
+	generateWikiCmd.Flags().StringVar(&wikiFormat, "format", wiki.FormatMediaWiki, "Wiki markup format: mediawiki")
+	generateWikiCmd.Flags().StringVar(&wikiTemplate, "template", wiki.DefaultTemplate, "Infobox template name")
+	generateWikiCmd.Flags().StringVar(&wikiOutput, "output", "./wiki", "Directory for the generated <unit-id>.wiki files")
+	generateWikiCmd.Flags().StringVar(&wikiUnit, "unit", "", "Print the infobox of this unit ID instead of writing files")
+}
+
+func runGenerateWiki(cmd *cobra.Command, args []string) error {
+	factionDir := args[0]
+	if _, err := wiki.ParseFormat(wikiFormat); err != nil {
+		return err
+	}
+
+	index, err := exporter.ReadFactionIndex(factionDir)
+	if err != nil {
+		return fmt.Errorf("failed to load faction from %s: %w\n\nPass a folder produced by describe-faction", factionDir, err)
+	}
+	faction := factionDir
+	if metadata, err := exporter.ReadFactionMetadata(factionDir); err == nil {
+		faction = metadata.DisplayName
+	}
+
+	units := stats.Units(index)
+	names := make(map[string]string, len(units))
+	for _, u := range units {
+		names[u.ID] = wiki.PageTitle(u)
+	}
+
+	if wikiUnit != "" {
+		for _, u := range units {
+			if u.ID == wikiUnit {
+				fmt.Print(wiki.Infobox(u, wikiTemplate, faction, names))
+				return nil
+			}
+		}
+		return fmt.Errorf("unit %q not found among the accessible units of %s", wikiUnit, factionDir)
+	}
+
+	if err := os.MkdirAll(wikiOutput, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	for _, u := range units {
+		path := filepath.Join(wikiOutput, u.ID+".wiki")
+		if err := os.WriteFile(path, []byte(wiki.Infobox(u, wikiTemplate, faction, names)), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	printStatus("✓ Wrote %d infoboxes to %s\n", len(units), wikiOutput)
+	return nil
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/history"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/serve"
+	"github.com/jamiemulcahy/pa-pedia/pkg/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyLibrary string
+	historyFaction string
+	historyAll     bool
+	historyOutput  string
+	historyCSV     string
+)
+
+// historyCmd prints how a unit's stats changed across the versions of a faction.
+var historyCmd = &cobra.Command{
+	Use:   "history <unit-id>",
+	Short: "Show a unit's stats across the exported versions of its faction",
+	Long: `Show how a unit changed across several exported versions of the same faction:
+every numeric stat (health, build cost, weapon damage and range, speed, ...)
+with its value in each version, oldest first, and the versions that added or
+removed the unit.
+
+Versions are read from a library directory laid out like the serve command's:
+faction folders, versioned <id>/<version>/ folders (see daemon) and release
+zips or .pafaction bundles, side by side. When the unit ID exists in more than
+one faction, pick one with --faction.
+
+Only stats that changed are listed unless --all is given. --output writes the
+timeline as JSON for balance history graphs in the web app, and --csv as a
+spreadsheet with a row per version. --json prints the same timeline on stdout
+instead of the table.`,
+	Example: `  pa-pedia history tank --library ./factions
+  pa-pedia history tank --library ./factions --faction mla --all
+  pa-pedia history tank --library ./releases --output tank-history.json --csv tank-history.csv`,
+	Args: cobra.ExactArgs(1),
+	RunE: runHistory,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().StringVar(&historyLibrary, "library", "./factions", "Directory of exported faction versions (folders, <id>/<version>/ folders and archives)")
+	historyCmd.Flags().StringVar(&historyFaction, "faction", "", "Faction identifier to follow the unit in (needed when several factions have it)")
+	historyCmd.Flags().BoolVar(&historyAll, "all", false, "List unchanged stats too")
+	historyCmd.Flags().StringVar(&historyOutput, "output", "", "Write the timeline as JSON to this file (e.g. tank-history.json)")
+	historyCmd.Flags().StringVar(&historyCSV, "csv", "", "Write the timeline as CSV to this file, one row per version")
+	addJSONFlag(historyCmd)
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	unitID := args[0]
+	defer progressToStderr()()
+
+	lib, errs := serve.ScanLibrary(historyLibrary, nil)
+	for _, err := range errs {
+		printStatus("⚠ %v\n", err)
+	}
+
+	factionID, err := historyFactionFor(lib, unitID)
+	if err != nil {
+		return err
+	}
+	versions := lib.Versions(factionID)
+	snapshots, err := history.Collect(versions, unitID)
+	if err != nil {
+		return err
+	}
+	h, err := history.Build(factionID, unitID, snapshots, !historyAll)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(h.Versions, func(v models.HistoryVersion) bool { return v.Present }) {
+		return fmt.Errorf("no version of %s has a unit '%s'\n\nUse the unit identifier from units.json (e.g. tank)", factionID, unitID)
+	}
+
+	if jsonOutput {
+		if err := printJSON(h); err != nil {
+			return err
+		}
+	} else {
+		printHistory(versions[0].Metadata.DisplayName, h)
+	}
+
+	if historyOutput != "" {
+		data, err := json.MarshalIndent(h, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal history: %w", err)
+		}
+		if err := os.WriteFile(historyOutput, data, 0644); err != nil {
+			return fmt.Errorf("failed to write history: %w", err)
+		}
+		printStatus("✓ Wrote history to %s\n", historyOutput)
+	}
+	if historyCSV != "" {
+		f, err := os.Create(historyCSV)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", historyCSV, err)
+		}
+		if err := history.Table(h).WriteCSV(f); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write %s: %w", historyCSV, err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to write %s: %w", historyCSV, err)
+		}
+		printStatus("✓ Wrote history to %s\n", historyCSV)
+	}
+	return nil
+}
+
+// historyFactionFor returns --faction, or the only faction in the library with the unit in
+// its newest version
+func historyFactionFor(lib *serve.Library, unitID string) (string, error) {
+	if historyFaction != "" {
+		if len(lib.Versions(historyFaction)) == 0 {
+			return "", fmt.Errorf("no versions of faction '%s' in %s\n\nAvailable factions: %s", historyFaction, historyLibrary, strings.Join(lib.IDs(), ", "))
+		}
+		return strings.ToLower(historyFaction), nil
+	}
+
+	var matches []string
+	for _, id := range lib.IDs() {
+		snapshots, err := history.Collect(lib.Versions(id), unitID)
+		if err != nil {
+			return "", err
+		}
+		for _, s := range snapshots {
+			if s.Unit != nil {
+				matches = append(matches, id)
+				break
+			}
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no faction in %s has a unit '%s'\n\nUse the unit identifier from units.json (e.g. tank)", historyLibrary, unitID)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("unit '%s' exists in several factions: %s\n\nPick one with --faction", unitID, strings.Join(matches, ", "))
+	}
+}
+
+// printHistory prints a stat per row with a column per version
+func printHistory(factionName string, h models.UnitHistory) {
+	fmt.Printf("=== PA-Pedia Unit History: %s (%s), %s ===\n", h.DisplayName, h.Unit, factionName)
+	fmt.Println()
+
+	width := len("Stat")
+	for _, s := range h.Stats {
+		width = max(width, len(s.Stat))
+	}
+	cells := make([]int, len(h.Versions))
+	for i, v := range h.Versions {
+		cells[i] = max(len(v.Version), 8)
+	}
+
+	fmt.Printf("  %-*s", width, "Stat")
+	for i, v := range h.Versions {
+		fmt.Printf("  %*s", cells[i], v.Version)
+	}
+	fmt.Println()
+	fmt.Printf("  %-*s", width, "(present)")
+	for i, v := range h.Versions {
+		present := "yes"
+		if !v.Present {
+			present = "no"
+		}
+		fmt.Printf("  %*s", cells[i], present)
+	}
+	fmt.Println()
+	for _, s := range h.Stats {
+		fmt.Printf("  %-*s", width, s.Stat)
+		for i, v := range s.Values {
+			value := "-"
+			if v != nil {
+				value = stats.FormatStat(*v)
+			}
+			fmt.Printf("  %*s", cells[i], value)
+		}
+		fmt.Println()
+	}
+	fmt.Println()
+
+	if len(h.Versions) < 2 {
+		printStatus("⚠ Only one version of %s found; export more versions to see changes\n", h.Faction)
+	} else if len(h.Stats) == 0 {
+		printStatus("✓ No stat changes across %d versions\n", len(h.Versions))
+	}
+}
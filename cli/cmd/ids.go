@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// idHistoryDir is the earlier export unit IDs are kept stable against; empty means the
+// faction folder being overwritten. The daemon points it at the latest stored version.
+var idHistoryDir string
+
+// unitIDHistory is the earlier export's unit IDs, pinned in the loader before parsing
+type unitIDHistory struct {
+	aliases         *models.IDAliases
+	previous        *models.FactionIndex // nil without an earlier export
+	previousVersion string
+}
+
+// pinUnitIDs reads the unit IDs of the earlier export and pins them in the loader, so units
+// keep their IDs when new files would otherwise claim them first
+func pinUnitIDs(l *loader.Loader, factionDir string) (*unitIDHistory, error) {
+	dir := idHistoryDir
+	if dir == "" {
+		dir = factionDir
+	}
+	aliases, err := exporter.ReadIDAliases(dir)
+	if err != nil {
+		return nil, err
+	}
+	history := &unitIDHistory{aliases: aliases}
+	if index, err := exporter.ReadFactionIndex(dir); err == nil {
+		history.previous = index
+		if metadata, err := exporter.ReadFactionMetadata(dir); err == nil {
+			history.previousVersion = metadata.Version
+		}
+	}
+
+	skipped := l.PinSafeNames(aliases.IDs)
+	logVerbose("Pinned %d unit IDs from %s", len(aliases.IDs)-len(skipped), dir)
+	for _, resourceName := range skipped {
+		printStatus("⚠ Could not keep ID '%s' for %s: another unit already has it\n", aliases.IDs[resourceName], resourceName)
+	}
+	return history, nil
+}
+
+// recordUnitIDs writes id-aliases.json with this export's IDs, listing units whose ID
+// changed since the earlier export under the version in metadata.json
+func recordUnitIDs(history *unitIDHistory, factionDir string, units []models.Unit) error {
+	metadata, err := exporter.ReadFactionMetadata(factionDir)
+	if err != nil {
+		return err
+	}
+	renames := exporter.UpdateIDAliases(history.aliases, history.previous, history.previousVersion, metadata.Version, units)
+	if err := exporter.WriteIDAliases(factionDir, history.aliases); err != nil {
+		return err
+	}
+	if len(renames) == 0 {
+		return nil
+	}
+
+	pairs := make([]string, 0, len(renames))
+	for old, id := range renames {
+		pairs = append(pairs, old+" → "+id)
+	}
+	sort.Strings(pairs)
+	printStatus("⚠ %d unit ID(s) changed since the previous export (recorded in %s): %s\n", len(renames), exporter.IDAliasesFile, strings.Join(pairs, ", "))
+	return nil
+}
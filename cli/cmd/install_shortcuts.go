@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/shortcuts"
+	"github.com/spf13/cobra"
+)
+
+var (
+	shortcutsUninstall bool
+	shortcutsPath      bool
+	shortcutsDryRun    bool
+)
+
+// installShortcutsCmd registers Explorer menu entries and the .pafaction file type on Windows.
+var installShortcutsCmd = &cobra.Command{
+	Use:   "install-shortcuts",
+	Short: "Add Explorer menu entries and open .pafaction bundles with pa-pedia (Windows)",
+	Long: `Register pa-pedia with Windows Explorer for the current user (no
+administrator rights needed):
+
+  - "Extract faction here" on folders and folder backgrounds, which opens
+    pa-pedia ui with that folder as the output directory
+  - .pafaction bundles open by unpacking them beside the bundle, in a console
+    window that stays open to show the result
+
+The entries point at this pa-pedia.exe, so run the command again after moving
+it. --add-to-path also adds its folder to the user PATH, for manual
+downloads; scoop and winget installs are already on PATH. The scoop manifest
+in packaging/scoop runs install-shortcuts after installing and
+install-shortcuts --uninstall before removing.
+
+--uninstall removes the entries (and with --add-to-path the PATH entry);
+--dry-run prints the registry values instead of writing them, on any OS.`,
+	Example: `  pa-pedia install-shortcuts
+  pa-pedia install-shortcuts --add-to-path
+  pa-pedia install-shortcuts --uninstall --add-to-path
+  pa-pedia install-shortcuts --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runInstallShortcuts,
+}
+
+func init() {
+	rootCmd.AddCommand(installShortcutsCmd)
+
+	installShortcutsCmd.Flags().BoolVar(&shortcutsUninstall, "uninstall", false, "Remove the entries instead")
+	installShortcutsCmd.Flags().BoolVar(&shortcutsPath, "add-to-path", false, "Also add this executable's folder to the user PATH (with --uninstall, remove it)")
+	installShortcutsCmd.Flags().BoolVar(&shortcutsDryRun, "dry-run", false, "Print the registry changes without making them")
+}
+
+func runInstallShortcuts(cmd *cobra.Command, args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+	if exe, err = filepath.Abs(exe); err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+
+	if shortcutsDryRun {
+		if shortcutsUninstall {
+			for _, key := range shortcuts.Keys() {
+				fmt.Printf("delete HKCU\\%s\n", key)
+			}
+		} else {
+			for _, v := range shortcuts.Values(exe) {
+				fmt.Printf("set %s\n", v)
+			}
+		}
+		if shortcutsPath {
+			action := "append to"
+			if shortcutsUninstall {
+				action = "remove from"
+			}
+			fmt.Printf("%s HKCU\\Environment Path: %s\n", action, filepath.Dir(exe))
+		}
+		return nil
+	}
+
+	if shortcutsUninstall {
+		if err := shortcuts.Uninstall(exe, shortcutsPath); err != nil {
+			return fmt.Errorf("%w\n\nUse --dry-run to see the registry changes", err)
+		}
+		printStatus("✓ Removed the Explorer entries and .pafaction association\n")
+		if shortcutsPath {
+			printStatus("✓ Removed %s from the user PATH\n", filepath.Dir(exe))
+		}
+		return nil
+	}
+
+	if err := shortcuts.Install(exe, shortcutsPath); err != nil {
+		return fmt.Errorf("%w\n\nUse --dry-run to see the registry changes", err)
+	}
+	printStatus("✓ Added \"Extract faction here\" to Explorer and associated .pafaction bundles with %s\n", exe)
+	if shortcutsPath {
+		printStatus("✓ Added %s to the user PATH; open a new terminal to use it\n", filepath.Dir(exe))
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/parallel"
+)
+
+// --parallelism and --io-limit; 0 picks the default
+var (
+	parallelismFlag int
+	ioLimitFlag     int
+)
+
+// validateLimits rejects negative --parallelism and --io-limit values
+func validateLimits() error {
+	if parallelismFlag < 0 {
+		return fmt.Errorf("--parallelism must be 0 (default) or more, got %d", parallelismFlag)
+	}
+	if ioLimitFlag < 0 {
+		return fmt.Errorf("--io-limit must be 0 (default) or more, got %d", ioLimitFlag)
+	}
+	return nil
+}
+
+// extractionWorkers returns the goroutines for reading unit JSON and copying files:
+// --parallelism, or GOMAXPROCS
+func extractionWorkers() int {
+	if parallelismFlag > 0 {
+		return parallelismFlag
+	}
+	return parallel.DefaultWorkers()
+}
+
+// extractionIOLimit returns how many files to read at once: --io-limit, or a default for the
+// disk holding paRoot (see parallel.DefaultIOLimit)
+func extractionIOLimit(paRoot string) (int, parallel.DiskKind) {
+	limit, disk := parallel.DefaultIOLimit(paRoot)
+	if ioLimitFlag > 0 {
+		limit = ioLimitFlag
+	}
+	return limit, disk
+}
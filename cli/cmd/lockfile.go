@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/lockfile"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+var (
+	lockfilePath  string
+	frozen        bool
+	pinGitHubRefs bool
+)
+
+// validateLockfileFlags rejects --frozen combinations that can't hold the inputs still
+func validateLockfileFlags() error {
+	if !frozen {
+		return nil
+	}
+	if lockfilePath == "" {
+		return fmt.Errorf("--frozen needs a lockfile to check against\n\nDrop --lockfile \"\" or --frozen")
+	}
+	if conflictsFlag == "prompt" {
+		return fmt.Errorf("--frozen can't be combined with --conflicts prompt, which saves answers to the profile\n\nResolve conflicts in an unfrozen run and commit the profile and %s", lockfilePath)
+	}
+	return nil
+}
+
+// checkFrozen fails unless the run's inputs match the profile's entry in the lockfile
+func checkFrozen(profile *models.FactionProfile, mods []*loader.ModInfo) error {
+	lock, err := lockfile.Read(lockfilePath)
+	if err != nil {
+		return err
+	}
+	locked, ok := lock.Factions[profile.ID]
+	if !ok {
+		return fmt.Errorf("%s has no entry for profile '%s'\n\nRun describe-faction once without --frozen to lock its inputs", lockfilePath, profile.ID)
+	}
+	current, err := lockfile.Entry(profile, mods, Version, detectPAVersion(paRoot))
+	if err != nil {
+		return err
+	}
+	if diffs := lockfile.Diff(locked, current); len(diffs) > 0 {
+		return fmt.Errorf("inputs differ from %s (--frozen):\n  - %s\n\nRestore the locked inputs, or rerun without --frozen to update the lockfile", lockfilePath, strings.Join(diffs, "\n  - "))
+	}
+	printStatus("✓ Inputs match %s\n", lockfilePath)
+	return nil
+}
+
+// pinnedGitHubCommits returns the commit each of the profile's GitHub mods should be
+// downloaded at under --pin-github-refs: the one in the lockfile, else the ref's current
+// commit from the GitHub API. Nil without --pin-github-refs, so refs download as named.
+func pinnedGitHubCommits(profile *models.FactionProfile, urls []string) (map[string]string, error) {
+	if !pinGitHubRefs {
+		return nil, nil
+	}
+	commits := make(map[string]string)
+	if lockfilePath != "" {
+		lock, err := lockfile.Read(lockfilePath)
+		if err != nil {
+			return nil, err
+		}
+		if locked, ok := lock.Factions[profile.ID]; ok {
+			commits = lockfile.GitHubCommits(locked)
+		}
+	}
+	for _, url := range urls {
+		if commits[url] != "" {
+			logVerbose("Pinned %s to %s from %s", url, commits[url], lockfilePath)
+			continue
+		}
+		commit, err := loader.ResolveGitHubCommit(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pin GitHub mod: %w", err)
+		}
+		logVerbose("Pinned %s to its current commit %s", url, commit)
+		commits[url] = commit
+	}
+	return commits, nil
+}
+
+// lockInputs captures the inputs of this run for updateLockfile. Call it once the profile
+// is final (after --conflicts answers are saved) so the next --frozen run matches.
+func lockInputs(profile *models.FactionProfile, mods []*loader.ModInfo) (*models.LockedFaction, error) {
+	if lockfilePath == "" || frozen {
+		return nil, nil
+	}
+	entry, err := lockfile.Entry(profile, mods, Version, detectPAVersion(paRoot))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock inputs: %w", err)
+	}
+	return &entry, nil
+}
+
+// updateLockfile records a successful extraction's inputs under the profile's ID
+func updateLockfile(profileID string, entry *models.LockedFaction) error {
+	if entry == nil {
+		return nil
+	}
+	lock, err := lockfile.Read(lockfilePath)
+	if err != nil {
+		return err
+	}
+	lock.Factions[profileID] = *entry
+	if err := lockfile.Write(lockfilePath, lock); err != nil {
+		return err
+	}
+	logVerbose("Locked inputs in %s", lockfilePath)
+	return nil
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// byteUnits maps size suffixes accepted by --memory-limit to their multipliers
+var byteUnits = []struct {
+	suffix string
+	scale  float64
+}{
+	{"TIB", 1 << 40}, {"GIB", 1 << 30}, {"MIB", 1 << 20}, {"KIB", 1 << 10},
+	{"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"KB", 1e3},
+	{"B", 1},
+}
+
+// parseByteSize parses sizes such as "512MiB", "2GB" or a plain byte count
+func parseByteSize(s string) (int64, error) {
+	text := strings.ToUpper(strings.TrimSpace(s))
+	scale := 1.0
+	for _, u := range byteUnits {
+		if strings.HasSuffix(text, u.suffix) {
+			text = strings.TrimSpace(strings.TrimSuffix(text, u.suffix))
+			scale = u.scale
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil || n < 0 || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("invalid size '%s'\n\nUse a byte count or a size like 512MiB or 2GB", s)
+	}
+	return int64(n * scale), nil
+}
+
+// formatBytes renders a byte count in binary units for the extraction report
+func formatBytes(n uint64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.2f GiB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	default:
+		return fmt.Sprintf("%d KiB", n>>10)
+	}
+}
+
+// applyMemoryLimit sets the Go runtime's soft memory limit from --memory-limit. The runtime
+// collects garbage harder as the heap nears the limit, keeping enormous mods within it where
+// the live data allows. Returns the limit in bytes (0 when unset).
+func applyMemoryLimit(flag string) (int64, error) {
+	if flag == "" {
+		return 0, nil
+	}
+	limit, err := parseByteSize(flag)
+	if err != nil {
+		return 0, fmt.Errorf("--memory-limit: %w", err)
+	}
+	if limit > 0 {
+		debug.SetMemoryLimit(limit)
+	}
+	return limit, nil
+}
+
+// printMemoryReport summarises the extraction's memory use: the most the runtime obtained from
+// the OS (its high-water mark, since it rarely returns address space) and the heap still live
+func printMemoryReport(limit int64) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	fmt.Printf("Memory: %s peak from OS, %s heap in use, %d GC cycles", formatBytes(stats.Sys), formatBytes(stats.HeapAlloc), stats.NumGC)
+	if limit > 0 {
+		fmt.Printf(" (limit %s)", formatBytes(uint64(limit)))
+		if stats.Sys > uint64(limit) {
+			printStatus("\n⚠ Peak exceeded --memory-limit; the live data for this faction needs more than the limit allows")
+		}
+	}
+	fmt.Println()
+}
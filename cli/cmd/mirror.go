@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/mirror"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mirrorDelete bool
+	mirrorDryRun bool
+)
+
+// mirrorCmd syncs faction data between storage targets.
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror <src> <dst>",
+	Short: "Sync faction data between local folders, archives, S3 and GitHub releases",
+	Long: `Make <dst> match <src>, copying only files whose SHA-256 differs. Either end
+can be:
+
+  ./factions, /srv/data              local folder
+  ./MLA.zip, ./MLA.pafaction         zip or .pafaction archive
+  s3://bucket/prefix                 S3 or S3-compatible object store
+  github://owner/repo/<tag>/MLA.zip  asset of an existing GitHub release
+
+Folders and archives are created as destinations. Archives and release assets
+are rewritten once, and only if something changed; a .pafaction destination is
+repacked canonically (see pack). Dot-prefixed files in folders (checkpoints,
+daemon staging) are skipped.
+
+S3 uses the standard AWS environment variables (see --upload on
+describe-faction). Object listings don't carry SHA-256 hashes, so an S3
+destination records its files in .pa-pedia-mirror.json under the prefix; an
+S3 source must have been written by mirror. GitHub releases read GITHUB_TOKEN
+(or GH_TOKEN), which is required to write.
+
+Files in <dst> that <src> doesn't have are kept unless --delete is given.`,
+	Example: `  pa-pedia mirror ./factions s3://my-bucket/factions --delete
+  pa-pedia mirror ./factions/MLA ./dist/MLA.pafaction
+  pa-pedia mirror ./factions/MLA github://me/my-mod/v1.2.0/MLA.zip
+  pa-pedia mirror s3://my-bucket/factions ./backup --dry-run`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMirror,
+}
+
+func init() {
+	rootCmd.AddCommand(mirrorCmd)
+
+	mirrorCmd.Flags().BoolVar(&mirrorDelete, "delete", false, "Delete destination files that the source doesn't have")
+	mirrorCmd.Flags().BoolVar(&mirrorDryRun, "dry-run", false, "Show what would be copied or deleted without writing anything")
+}
+
+func runMirror(cmd *cobra.Command, args []string) error {
+	createdBy := "pa-pedia " + Version
+	src, err := mirror.Open(args[0], createdBy)
+	if err != nil {
+		return fmt.Errorf("failed to open source: %w", err)
+	}
+	dst, err := mirror.Open(args[1], createdBy)
+	if err != nil {
+		return fmt.Errorf("failed to open destination: %w", err)
+	}
+
+	result, err := mirror.Sync(src, dst, mirror.Options{
+		Delete: mirrorDelete,
+		DryRun: mirrorDryRun,
+		Logf: func(format string, args ...any) {
+			if mirrorDryRun || verbose {
+				fmt.Printf(format+"\n", args...)
+			}
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	summary := fmt.Sprintf("%d copied (%s), %d deleted, %d unchanged", len(result.Copied), formatBytes(uint64(result.Bytes)), len(result.Deleted), result.Unchanged)
+	switch {
+	case mirrorDryRun:
+		fmt.Printf("Would sync %s -> %s: %s (dry run)\n", src, dst, summary)
+	case !result.Changed():
+		printStatus("✓ %s is up to date (%d files)\n", dst, result.Unchanged)
+	default:
+		printStatus("✓ Mirrored %s -> %s: %s\n", src, dst, summary)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/profiles"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Faction selection (mirrors describe-faction)
+	miProfileFlag    string
+	miProfileDirFlag string
+	miFactionName    string
+	miFactionType    string
+	miModIDs         []string
+
+	miPaRoot     string
+	miPaDataRoot string
+)
+
+// missingIconsCmd reports units without a build bar icon and where it was looked for.
+var missingIconsCmd = &cobra.Command{
+	Use:   "missing-icons",
+	Short: "Report units without buildbar icons, with the paths searched and near misses",
+	Long: `List every unit of a faction that has no build bar icon in any source, so mod
+authors can fix their asset layout. describe-faction exports a generated
+placeholder for these units.
+
+For each unit the report shows the locations searched in every source (the
+unit's folder, a sibling icon_atlas folder and ui/mods/<unit folder> in
+folders; anywhere in zips) and near misses: PNG files whose name, less
+_icon_buildbar, _icon or icon_, is within one or two edits of the unit ID.
+
+A near miss can be used without changing the mod by mapping it in the
+profile's "icons" (unit ID to resource path), which takes priority over the
+discovered icon:
+
+  "icons": { "l_tank": "/ui/mods/legion/img/l_tank.png" }
+
+Mapped paths that no source has are reported too. Files in the profile's
+"iconOverrides" folder (<unit id>.png or <unit id>_icon_buildbar.png) take
+priority over both.
+
+--json prints the report on stdout as {"units", "missing": [{"id",
+"displayName", "resourceName", "searched", "nearMisses"}], "brokenMappings":
+[{"id", "path"}]}, locations being {"source", "resourcePath"}; loading
+progress goes to stderr.`,
+	Example: `  pa-pedia missing-icons --profile legion --pa-root "C:/PA/media" --data-root "%LOCALAPPDATA%/Uber Entertainment/Planetary Annihilation"
+  pa-pedia missing-icons --name "My Faction" --faction-unit-type Custom3 --mod com.example.faction --pa-root "C:/PA/media" --data-root "..."`,
+	RunE: runMissingIcons,
+}
+
+func init() {
+	rootCmd.AddCommand(missingIconsCmd)
+
+	missingIconsCmd.Flags().StringVar(&miProfileFlag, "profile", "", "Profile ID to use (recommended approach)")
+	missingIconsCmd.Flags().StringVar(&miProfileDirFlag, "profile-dir", "./profiles", "Directory for custom faction profiles")
+	missingIconsCmd.Flags().StringVar(&miFactionName, "name", "", "Faction display name (fallback/manual mode)")
+	missingIconsCmd.Flags().StringVar(&miFactionType, "faction-unit-type", "", "Faction unit type identifier (e.g., Custom58 for MLA)")
+	missingIconsCmd.Flags().StringArrayVar(&miModIDs, "mod", []string{}, "Mod source(s) - local mod ID or GitHub URL (repeatable, first has priority)")
+
+	missingIconsCmd.Flags().StringVar(&miPaRoot, "pa-root", "", "Path to PA Titans media directory")
+	missingIconsCmd.Flags().StringVar(&miPaDataRoot, "data-root", "", "Path to PA data directory (required when local mods are involved)")
+	missingIconsCmd.Flags().StringArrayVar(&overrideOptOut, "override-opt-out", nil, "Extract this mod even though its author opted out of exports (repeatable; each opted-out mod must be named)")
+	addJSONFlag(missingIconsCmd)
+}
+
+// missingIconsReport is the --json output of the missing-icons command
+type missingIconsReport struct {
+	Units          int                 `json:"units"`
+	Missing        []missingIcon       `json:"missing"`
+	BrokenMappings []brokenIconMapping `json:"brokenMappings"`
+}
+
+type missingIcon struct {
+	ID           string         `json:"id"`
+	DisplayName  string         `json:"displayName"`
+	ResourceName string         `json:"resourceName"`
+	Searched     []iconLocation `json:"searched"`
+	NearMisses   []iconLocation `json:"nearMisses"`
+}
+
+type iconLocation struct {
+	Source       string `json:"source"`
+	ResourcePath string `json:"resourcePath"`
+}
+
+// brokenIconMapping is a profile "icons" entry whose path no source has
+type brokenIconMapping struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+}
+
+func iconLocations(locations []loader.IconLocation) []iconLocation {
+	out := make([]iconLocation, len(locations))
+	for i, loc := range locations {
+		out[i] = iconLocation{Source: loc.Source, ResourcePath: loc.ResourcePath}
+	}
+	return out
+}
+
+func runMissingIcons(cmd *cobra.Command, args []string) error {
+	defer progressToStderr()()
+	profileLoader, err := profiles.NewLoader()
+	if err != nil {
+		return fmt.Errorf("failed to initialize profile loader: %w", err)
+	}
+	if err := profileLoader.LoadLocalProfiles(miProfileDirFlag); err != nil {
+		return fmt.Errorf("failed to load local profiles: %w", err)
+	}
+
+	profile, err := resolveProfileFromFlags(profileLoader, miProfileFlag, miFactionName, miFactionType, miModIDs)
+	if err != nil {
+		return err
+	}
+	if err := validateFactionInputs(profile, miPaRoot, miPaDataRoot); err != nil {
+		return err
+	}
+
+	l, units, _, _, err := loadFactionUnits(profile, miPaRoot, miPaDataRoot, true)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	sort.Slice(units, func(i, j int) bool { return units[i].ID < units[j].ID })
+
+	if jsonOutput {
+		report := missingIconsReport{Units: len(units), Missing: []missingIcon{}, BrokenMappings: []brokenIconMapping{}}
+		for _, unit := range units {
+			search, err := l.SearchIcon(unit.ResourceName)
+			if err != nil {
+				return fmt.Errorf("failed to search icons for %s: %w", unit.ID, err)
+			}
+			if search.OverrideMissing {
+				report.BrokenMappings = append(report.BrokenMappings, brokenIconMapping{ID: unit.ID, Path: search.Override})
+			}
+			if search.Found == nil {
+				report.Missing = append(report.Missing, missingIcon{
+					ID:           unit.ID,
+					DisplayName:  unit.DisplayName,
+					ResourceName: unit.ResourceName,
+					Searched:     iconLocations(search.Searched),
+					NearMisses:   iconLocations(search.NearMisses),
+				})
+			}
+		}
+		return printJSON(report)
+	}
+
+	fmt.Println()
+	missing, brokenOverrides := 0, 0
+	for _, unit := range units {
+		search, err := l.SearchIcon(unit.ResourceName)
+		if err != nil {
+			return fmt.Errorf("failed to search icons for %s: %w", unit.ID, err)
+		}
+		if search.OverrideMissing {
+			brokenOverrides++
+			printStatus("⚠ %s: mapped icon %s not found in any source\n", unit.ID, search.Override)
+		}
+		if search.Found != nil {
+			logVerbose("✓ %s: %s (%s)", unit.ID, search.Found.FullPath, search.Found.Source)
+			continue
+		}
+
+		missing++
+		printStatus("✗ %s (%s) %s\n", unit.ID, unit.DisplayName, unit.ResourceName)
+		fmt.Println("  Searched:")
+		for _, loc := range search.Searched {
+			fmt.Printf("    %s  [%s]\n", loc.ResourcePath, loc.Source)
+		}
+		if len(search.NearMisses) == 0 {
+			fmt.Println("  No similarly named PNGs found")
+		} else {
+			fmt.Println("  Near misses:")
+			for _, loc := range search.NearMisses {
+				fmt.Printf("    %s  [%s]\n", loc.ResourcePath, loc.Source)
+			}
+			fmt.Printf("  Suggested profile mapping: \"icons\": { %q: %q }\n", unit.ID, search.NearMisses[0].ResourcePath)
+		}
+		fmt.Println()
+	}
+
+	switch {
+	case missing == 0 && brokenOverrides == 0:
+		printStatus("✓ All %d units have icons\n", len(units))
+	case missing == 0:
+		printStatus("⚠ All %d units have icons, but %d icon mappings point at missing files\n", len(units), brokenOverrides)
+	default:
+		printStatus("✗ %d of %d units have no icon (describe-faction will generate placeholders)\n", missing, len(units))
+	}
+	return nil
+}
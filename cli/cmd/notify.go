@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/notify"
+	"github.com/jamiemulcahy/pa-pedia/pkg/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	notifyWebhook   string
+	notifyPrevious  string
+	notifyChangelog string
+	notifyUsername  string
+	notifyDryRun    bool
+)
+
+// notifyCmd announces an export to a Discord channel.
+var notifyCmd = &cobra.Command{
+	Use:   "notify <faction-dir>",
+	Short: "Post an export summary to a Discord webhook",
+	Long: `Post a summary of an exported faction to a Discord channel webhook: faction,
+version, PA build and unit count, and with --previous the units added, removed
+and whose dps, health, cost or speed changed since that earlier export.
+
+--changelog attaches a Markdown file (e.g. release notes) to the message. The
+webhook URL is read from --webhook or the ` + notify.WebhookEnv + ` environment
+variable; prefer the variable in CI so the token stays out of logs.
+
+Use --dry-run to print the summary without posting it.`,
+	Example: `  pa-pedia notify ./factions/MLA --previous ./factions-previous/MLA
+  pa-pedia notify ./factions/Legion --changelog CHANGELOG.md --webhook https://discord.com/api/webhooks/<id>/<token>
+  pa-pedia notify ./factions/MLA --previous ./factions-previous/MLA --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runNotify,
+}
+
+func init() {
+	rootCmd.AddCommand(notifyCmd)
+
+	notifyCmd.Flags().StringVar(&notifyWebhook, "webhook", "", "Discord webhook URL (default $"+notify.WebhookEnv+")")
+	notifyCmd.Flags().StringVar(&notifyPrevious, "previous", "", "Earlier export of the same faction to list changes against")
+	notifyCmd.Flags().StringVar(&notifyChangelog, "changelog", "", "Markdown file to attach to the message")
+	notifyCmd.Flags().StringVar(&notifyUsername, "username", "PA-Pedia", "Name the message is posted under (empty for the webhook's default)")
+	notifyCmd.Flags().BoolVar(&notifyDryRun, "dry-run", false, "Print the summary instead of posting it")
+}
+
+func runNotify(cmd *cobra.Command, args []string) error {
+	webhook := notifyWebhook
+	if webhook == "" {
+		webhook = os.Getenv(notify.WebhookEnv)
+	}
+	if webhook == "" && !notifyDryRun {
+		return fmt.Errorf("no webhook URL\n\nPass --webhook or set %s", notify.WebhookEnv)
+	}
+
+	var attachment *notify.Attachment
+	if notifyChangelog != "" {
+		data, err := os.ReadFile(notifyChangelog)
+		if err != nil {
+			return fmt.Errorf("failed to read changelog: %w", err)
+		}
+		attachment = &notify.Attachment{Name: filepath.Base(notifyChangelog), Data: data}
+	}
+
+	metadata, units, err := loadNotifyFaction(args[0])
+	if err != nil {
+		return err
+	}
+	var previous *models.FactionMetadata
+	var previousUnits []*models.Unit
+	if notifyPrevious != "" {
+		if previous, previousUnits, err = loadNotifyFaction(notifyPrevious); err != nil {
+			return err
+		}
+	}
+	summary := notify.Summarize(metadata, units, previous, previousUnits)
+
+	if notifyDryRun {
+		fmt.Println(summary.Title())
+		fmt.Println(strings.Join(summary.Lines(0), "\n"))
+		if attachment != nil {
+			fmt.Printf("\nAttachment: %s (%d bytes)\n", attachment.Name, len(attachment.Data))
+		}
+		return nil
+	}
+
+	discord, err := notify.NewDiscord(webhook)
+	if err != nil {
+		return err
+	}
+	discord.Username = notifyUsername
+	if err := discord.Send(summary, attachment); err != nil {
+		return err
+	}
+	printStatus("✓ Posted %q to Discord\n", summary.Title())
+	return nil
+}
+
+// loadNotifyFaction reads the metadata and accessible units of an exported faction
+func loadNotifyFaction(factionDir string) (*models.FactionMetadata, []*models.Unit, error) {
+	index, err := exporter.ReadFactionIndex(factionDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load faction from %s: %w\n\nPass a folder produced by describe-faction", factionDir, err)
+	}
+	metadata, err := exporter.ReadFactionMetadata(factionDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read metadata from %s: %w", factionDir, err)
+	}
+	return metadata, stats.Units(index), nil
+}
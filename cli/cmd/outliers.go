@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	outliersFaction    string
+	outliersBaseline   string
+	outliersThreshold  float64
+	outliersThresholds string
+	outliersReport     string
+)
+
+// outliersCmd flags units of a (modded) faction whose stats stray far from a vanilla baseline.
+var outliersCmd = &cobra.Command{
+	Use:   "outliers",
+	Short: "Flag units whose efficiency deviates from a baseline faction",
+	Long: `Compare an exported faction against a baseline export (usually vanilla MLA)
+and list the units a balance reviewer should look at before release.
+
+Units that also exist in the baseline (same ID) are regressions when any of
+dps, health, cost, speed, hpPerMetal or dpsPerMetal changed by more than the
+threshold ratio, or when they gained or lost a stat entirely. Other units are
+outliers when hpPerMetal or dpsPerMetal is beyond the threshold from the
+median of baseline units of the same tier and build bar category (or the whole
+tier when the baseline has no such category). Commanders are skipped.
+
+A threshold of 2 flags ratios of 2x or more and 0.5x or less. Use --thresholds
+to tighten or loosen individual metrics. The command exits non-zero when
+anything is flagged, so it can gate a release script.
+
+--json prints the findings as {"faction", "baseline", "threshold",
+"regressions", "outliers"}; each finding has "kind", "unitId", "displayName",
+"tier", "metric", "metricUnit", "value", "baseline", "ratio" (null when the
+stat was gained or lost) and "reference".`,
+	Example: `  pa-pedia outliers --faction ./factions/MyBalanceMod --baseline ./factions/MLA
+  pa-pedia outliers --faction ./factions/Legion --baseline ./factions/MLA --threshold 3
+  pa-pedia outliers --faction ./factions/MyBalanceMod --baseline ./factions/MLA \
+    --thresholds hpPerMetal=1.5,dpsPerMetal=1.5 --report review.md`,
+	RunE: runOutliers,
+}
+
+func init() {
+	rootCmd.AddCommand(outliersCmd)
+
+	outliersCmd.Flags().StringVar(&outliersFaction, "faction", "", "Exported faction folder to review (required)")
+	outliersCmd.Flags().StringVar(&outliersBaseline, "baseline", "", "Exported faction folder to compare against, e.g. vanilla MLA (required)")
+	outliersCmd.Flags().Float64Var(&outliersThreshold, "threshold", stats.DefaultThreshold, "Flag ratios at or beyond this factor either way")
+	outliersCmd.Flags().StringVar(&outliersThresholds, "thresholds", "", "Per-metric overrides as metric=ratio pairs (e.g. hpPerMetal=1.5,dps=3)")
+	outliersCmd.Flags().StringVar(&outliersReport, "report", "", "Write the findings as a Markdown review report to this file")
+	outliersCmd.MarkFlagRequired("faction")
+	outliersCmd.MarkFlagRequired("baseline")
+	addJSONFlag(outliersCmd)
+}
+
+// outliersJSON is the --json output of the outliers command
+type outliersJSON struct {
+	Faction     string           `json:"faction"`
+	Baseline    string           `json:"baseline"`
+	Threshold   float64          `json:"threshold"`
+	Regressions []outlierFinding `json:"regressions"`
+	Outliers    []outlierFinding `json:"outliers"`
+}
+
+// outlierFinding replaces Finding's ratio, which JSON can't hold when it's infinite
+type outlierFinding struct {
+	stats.Finding
+	Ratio *float64 `json:"ratio"` // null when the stat was gained or lost
+}
+
+func outlierFindings(findings []stats.Finding) []outlierFinding {
+	out := make([]outlierFinding, len(findings))
+	for i, f := range findings {
+		out[i].Finding = f
+		if !math.IsInf(f.Ratio, 0) {
+			ratio := f.Ratio
+			out[i].Ratio = &ratio
+		}
+	}
+	return out
+}
+
+func runOutliers(cmd *cobra.Command, args []string) error {
+	if outliersThreshold <= 1 {
+		return fmt.Errorf("--threshold must be above 1, got %g", outliersThreshold)
+	}
+	thresholds, err := stats.ParseThresholds(outliersThresholds)
+	if err != nil {
+		return fmt.Errorf("invalid --thresholds: %w", err)
+	}
+
+	name, units, err := loadStatsUnits(outliersFaction)
+	if err != nil {
+		return err
+	}
+	baselineName, baseline, err := loadStatsUnits(outliersBaseline)
+	if err != nil {
+		return err
+	}
+
+	findings := stats.Compare(units, baseline, stats.CompareOptions{Threshold: outliersThreshold, Thresholds: thresholds})
+	var regressions, outliers []stats.Finding
+	for _, f := range findings {
+		if f.Kind == stats.FindingRegression {
+			regressions = append(regressions, f)
+		} else {
+			outliers = append(outliers, f)
+		}
+	}
+
+	if jsonOutput {
+		defer progressToStderr()()
+		if err := printJSON(outliersJSON{
+			Faction:     name,
+			Baseline:    baselineName,
+			Threshold:   outliersThreshold,
+			Regressions: outlierFindings(regressions),
+			Outliers:    outlierFindings(outliers),
+		}); err != nil {
+			return err
+		}
+	} else {
+		printOutliers(name, baselineName, thresholds, regressions, outliers)
+	}
+
+	if outliersReport != "" {
+		report := outliersMarkdown(name, baselineName, regressions, outliers)
+		if err := os.WriteFile(outliersReport, []byte(report), 0644); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		printStatus("✓ Wrote review report to %s\n", outliersReport)
+	}
+
+	if len(findings) == 0 {
+		printStatus("✓ No units beyond the thresholds\n")
+		return nil
+	}
+	return fmt.Errorf("%d findings across %d units need review", len(findings), countUnits(findings))
+}
+
+// printOutliers prints the findings for the terminal
+func printOutliers(name, baselineName string, thresholds map[string]float64, regressions, outliers []stats.Finding) {
+	fmt.Printf("=== PA-Pedia Outliers: %s vs %s ===\n", name, baselineName)
+	fmt.Println()
+	fmt.Printf("Threshold: %gx", outliersThreshold)
+	if len(thresholds) > 0 {
+		fmt.Printf(" (overrides: %s)", outliersThresholds)
+	}
+	fmt.Println()
+
+	printFindings("Changed from baseline", regressions)
+	printFindings("Outliers against baseline tier medians", outliers)
+	fmt.Println()
+}
+
+// loadStatsUnits reads an exported faction's display name and accessible units
+func loadStatsUnits(factionDir string) (string, []*models.Unit, error) {
+	index, err := exporter.ReadFactionIndex(factionDir)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load faction from %s: %w\n\nPass a folder produced by describe-faction", factionDir, err)
+	}
+	name := factionDir
+	if metadata, err := exporter.ReadFactionMetadata(factionDir); err == nil {
+		name = metadata.DisplayName
+	}
+	return name, stats.Units(index), nil
+}
+
+// printFindings prints one section of findings, one line each
+func printFindings(title string, findings []stats.Finding) {
+	if len(findings) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Printf("%s (%d):\n", title, len(findings))
+	for _, f := range findings {
+		printStatus("  ⚠ %s\n", f.Describe())
+	}
+}
+
+// outliersMarkdown renders the findings as a review checklist
+func outliersMarkdown(name, baselineName string, regressions, outliers []stats.Finding) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Balance review: %s vs %s\n\n", name, baselineName)
+	fmt.Fprintf(&b, "Threshold: %gx", outliersThreshold)
+	if outliersThresholds != "" {
+		fmt.Fprintf(&b, " (overrides: `%s`)", outliersThresholds)
+	}
+	b.WriteString("\n")
+
+	section := func(title string, findings []stats.Finding) {
+		fmt.Fprintf(&b, "\n## %s (%d)\n\n", title, len(findings))
+		if len(findings) == 0 {
+			b.WriteString("Nothing flagged.\n")
+			return
+		}
+		for _, f := range findings {
+			fmt.Fprintf(&b, "- [ ] %s\n", f.Describe())
+		}
+	}
+	section("Changed from baseline", regressions)
+	section("Outliers against baseline tier medians", outliers)
+	return b.String()
+}
+
+// countUnits returns the number of distinct units among findings
+func countUnits(findings []stats.Finding) int {
+	seen := make(map[string]bool)
+	for _, f := range findings {
+		seen[f.UnitID] = true
+	}
+	return len(seen)
+}
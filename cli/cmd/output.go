@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/i18n"
+	"github.com/jamiemulcahy/pa-pedia/pkg/style"
+	"github.com/spf13/cobra"
+)
+
+// jsonOutput is --json on the informational commands that support it
+var jsonOutput bool
+
+// printStatus prints a result line such as "✓ Wrote units.json", translated (see i18n.T)
+// and with its leading ✓, ⚠ or ✗ marker coloured on a terminal
+func printStatus(format string, a ...any) {
+	line := fmt.Sprintf(i18n.T(format), a...)
+	recentOutput.Add(line)
+	fmt.Print(style.Marked(line))
+}
+
+// addJSONFlag gives an informational command --json. --output is already the output
+// directory or file on most commands, so the machine-readable mode has its own flag.
+func addJSONFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the result as JSON on stdout instead of formatted text, for scripts and GUIs")
+}
+
+// jsonStdout is the real stdout while progressToStderr has redirected os.Stdout
+var jsonStdout *os.File
+
+// progressToStderr sends everything printed to os.Stdout (loading progress, warnings) to
+// stderr during a --json run, so stdout carries only the document printJSON writes. Call
+// the returned function to restore os.Stdout.
+func progressToStderr() func() {
+	if !jsonOutput {
+		return func() {}
+	}
+	saved := os.Stdout
+	jsonStdout, os.Stdout = saved, os.Stderr
+	return func() {
+		os.Stdout, jsonStdout = saved, nil
+	}
+}
+
+// printJSON writes v to stdout as indented JSON, the whole output of a command run with --json
+func printJSON(v any) error {
+	out := os.Stdout
+	if jsonStdout != nil {
+		out = jsonStdout
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/bundle"
+	"github.com/spf13/cobra"
+)
+
+var (
+	packFactionDir string
+	packOutput     string
+)
+
+// packCmd packs an exported faction folder into a single .pafaction bundle.
+var packCmd = &cobra.Command{
+	Use:   "pack",
+	Short: "Pack an exported faction folder into a .pafaction bundle",
+	Long: `Pack a faction folder produced by describe-faction into a single .pafaction
+file for sharing.
+
+A bundle is a zip archive with a canonical layout: manifest.json (format version,
+faction identity and a SHA-256 for every file) followed by metadata.json,
+units.json and the remaining files sorted by path. Packing the same folder twice
+produces identical bytes. The PA-Pedia web app accepts bundles as uploads, and
+'pa-pedia unpack' verifies every checksum before extracting.`,
+	Example: `  pa-pedia pack --faction ./factions/MLA
+  pa-pedia pack --faction ./factions/Legion --output ./dist/legion.pafaction`,
+	RunE: runPack,
+}
+
+func init() {
+	rootCmd.AddCommand(packCmd)
+
+	packCmd.Flags().StringVar(&packFactionDir, "faction", "", "Path to an exported faction folder (containing metadata.json and units.json)")
+	packCmd.Flags().StringVar(&packOutput, "output", "", "Bundle file to write (default: <faction folder>.pafaction next to the folder)")
+	packCmd.MarkFlagRequired("faction")
+}
+
+func runPack(cmd *cobra.Command, args []string) error {
+	dest := packOutput
+	if dest == "" {
+		dest = filepath.Clean(packFactionDir) + bundle.Extension
+	}
+
+	manifest, err := bundle.Pack(packFactionDir, dest, "pa-pedia "+Version)
+	if err != nil {
+		return fmt.Errorf("failed to pack %s: %w\n\nPoint --faction at a folder produced by describe-faction", packFactionDir, err)
+	}
+
+	var total int64
+	for _, f := range manifest.Files {
+		total += f.Size
+		logVerbose("  %s (%s)", f.Path, formatBytes(uint64(f.Size)))
+	}
+	printStatus("✓ Packed %s %s (%d files, %s) into %s\n", manifest.DisplayName, manifest.Version, len(manifest.Files), formatBytes(uint64(total)), dest)
+	return nil
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/versions"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneKeep     int
+	pruneFactions []string
+	prunePin      []string
+	pruneUnpin    []string
+	pruneTag      []string
+	pruneUntag    []string
+	pruneDryRun   bool
+)
+
+// pruneCmd removes old versions from a versioned output directory.
+var pruneCmd = &cobra.Command{
+	Use:   "prune [output-dir]",
+	Short: "Remove old faction versions from a versioned output directory",
+	Long: `Remove old versions from a versioned output directory written by daemon
+(default ./factions): <id>/<version>/ folders listed in <id>/versions.json.
+
+For each faction the --keep most recently exported versions are kept, and so
+is every pinned or tagged version however old; the rest are deleted and
+dropped from versions.json. daemon applies the same rule after every export.
+
+Pin a version to keep it forever, or tag it (e.g. stable, tournament) to keep
+it with a label; versions are written <id>@<version>. Pins and tags are
+applied before pruning, and can be changed without pruning by leaving out
+--keep.`,
+	Example: `  pa-pedia prune ./factions --keep 5
+  pa-pedia prune ./factions --keep 3 --faction mla --dry-run
+  pa-pedia prune ./factions --pin mla@124664
+  pa-pedia prune ./factions --tag legion@2.1.0=tournament --keep 5`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPrune,
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+
+	pruneCmd.Flags().IntVar(&pruneKeep, "keep", 0, "Versions to keep per faction, most recently exported first (pinned and tagged versions are always kept)")
+	pruneCmd.Flags().StringArrayVar(&pruneFactions, "faction", nil, "Only prune this faction identifier (repeatable, default all)")
+	pruneCmd.Flags().StringArrayVar(&prunePin, "pin", nil, "Pin <id>@<version> so it is never pruned (repeatable)")
+	pruneCmd.Flags().StringArrayVar(&pruneUnpin, "unpin", nil, "Remove the pin from <id>@<version> (repeatable)")
+	pruneCmd.Flags().StringArrayVar(&pruneTag, "tag", nil, "Tag <id>@<version>=<tag>; tagged versions are never pruned (repeatable)")
+	pruneCmd.Flags().StringArrayVar(&pruneUntag, "untag", nil, "Remove a tag: <id>@<version>=<tag> (repeatable)")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "Show what would be removed without deleting anything")
+}
+
+func runPrune(cmd *cobra.Command, args []string) error {
+	root := "./factions"
+	if len(args) == 1 {
+		root = args[0]
+	}
+	if pruneKeep < 0 {
+		return fmt.Errorf("--keep can't be negative")
+	}
+	labels := len(prunePin) + len(pruneUnpin) + len(pruneTag) + len(pruneUntag)
+	if pruneKeep == 0 && labels == 0 {
+		return fmt.Errorf("nothing to do\n\nPass --keep N to prune, or --pin/--unpin/--tag/--untag to protect versions")
+	}
+	if pruneDryRun && labels > 0 {
+		return fmt.Errorf("--dry-run only previews pruning\n\nApply --pin/--unpin/--tag/--untag without it first")
+	}
+	store := &versions.Store{Root: root}
+
+	if err := applyVersionLabels(store); err != nil {
+		return err
+	}
+	if pruneKeep == 0 {
+		return nil
+	}
+
+	ids := pruneFactions
+	if len(ids) == 0 {
+		var err error
+		if ids, err = store.Factions(); err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			printStatus("⚠ No versioned factions in %s (expected <id>/versions.json, as written by daemon)\n", root)
+			return nil
+		}
+	}
+
+	total := 0
+	for _, id := range ids {
+		var removed []models.VersionEntry
+		var err error
+		if pruneDryRun {
+			removed, err = store.Expired(id, pruneKeep)
+		} else {
+			removed, err = store.Prune(id, pruneKeep)
+		}
+		for _, v := range removed {
+			fmt.Printf("  - %s@%s (exported %s)\n", id, v.Version, v.ExportedAt)
+		}
+		total += len(removed)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case pruneDryRun:
+		fmt.Printf("Would remove %d versions (dry run)\n", total)
+	case total == 0:
+		printStatus("✓ Nothing to prune\n")
+	default:
+		printStatus("✓ Removed %d versions\n", total)
+	}
+	return nil
+}
+
+// applyVersionLabels applies --pin, --unpin, --tag and --untag
+func applyVersionLabels(store *versions.Store) error {
+	type change struct {
+		refs  []string
+		label bool // <id>@<version>=<tag>
+		edit  func(v *models.VersionEntry, tag string)
+		done  string
+	}
+	changes := []change{
+		{prunePin, false, func(v *models.VersionEntry, _ string) { v.Pinned = true }, "Pinned"},
+		{pruneUnpin, false, func(v *models.VersionEntry, _ string) { v.Pinned = false }, "Unpinned"},
+		{pruneTag, true, func(v *models.VersionEntry, tag string) {
+			for _, t := range v.Tags {
+				if t == tag {
+					return
+				}
+			}
+			v.Tags = append(v.Tags, tag)
+		}, "Tagged"},
+		{pruneUntag, true, func(v *models.VersionEntry, tag string) {
+			kept := v.Tags[:0]
+			for _, t := range v.Tags {
+				if t != tag {
+					kept = append(kept, t)
+				}
+			}
+			v.Tags = kept
+		}, "Untagged"},
+	}
+
+	for _, c := range changes {
+		for _, ref := range c.refs {
+			target, tag := ref, ""
+			if c.label {
+				var ok bool
+				if target, tag, ok = strings.Cut(ref, "="); !ok || tag == "" {
+					return fmt.Errorf("invalid tag %q (expected <id>@<version>=<tag>)", ref)
+				}
+			}
+			id, version, ok := strings.Cut(target, "@")
+			if !ok || id == "" || version == "" {
+				return fmt.Errorf("invalid version %q (expected <id>@<version>, e.g. mla@124664)", target)
+			}
+			if err := store.Edit(id, version, func(v *models.VersionEntry) { c.edit(v, tag) }); err != nil {
+				return err
+			}
+			if tag != "" {
+				printStatus("✓ %s %s@%s (%s)\n", c.done, id, version, tag)
+			} else {
+				printStatus("✓ %s %s@%s\n", c.done, id, version)
+			}
+		}
+	}
+	return nil
+}
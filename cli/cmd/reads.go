@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+)
+
+// printReadReport summarises the files read per source for the extraction report, warning
+// about sources that dominated read time (see loader.SlowSources)
+func printReadReport(stats []loader.SourceReadStats) {
+	if len(stats) == 0 {
+		return
+	}
+	var files int
+	var bytes int64
+	var total time.Duration
+	for _, s := range stats {
+		files += s.Files
+		bytes += s.Bytes
+		total += s.Duration
+	}
+	fmt.Printf("Reads: %d files, %s from %d sources in %s\n", files, formatBytes(uint64(bytes)), len(stats), total.Round(time.Millisecond))
+	if verbose {
+		for _, s := range stats {
+			fmt.Printf("  %s: %d files, %s in %s\n", s.Source, s.Files, formatBytes(uint64(s.Bytes)), s.Duration.Round(time.Millisecond))
+		}
+	}
+
+	for _, s := range loader.SlowSources(stats) {
+		share := 100 * float64(s.Duration) / float64(total)
+		printStatus("⚠ %s took %.0f%% of read time (%s for %d files)", s.Source, share, s.Duration.Round(time.Millisecond), s.Files)
+		if s.IsZip {
+			fmt.Println("; if the zip is on a network or slow drive, extract it to a local folder")
+		} else {
+			fmt.Println("; if the folder is on a network or slow drive, copy it to a local disk")
+		}
+	}
+}
@@ -5,12 +5,16 @@ import (
 	"os"
 	"os/exec"
 
+	"github.com/jamiemulcahy/pa-pedia/pkg/i18n"
+	"github.com/jamiemulcahy/pa-pedia/pkg/style"
 	"github.com/jamiemulcahy/pa-pedia/pkg/updater"
 	"github.com/spf13/cobra"
 )
 
 var (
-	verbose bool
+	verbose    bool
+	localeFlag string
+	noColor    bool
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -25,15 +29,38 @@ It supports extracting data from:
 - Server mods (including zip files)
 
 Generated faction folders can be used with the PA-Pedia web application
-or shared with other users.`,
+or shared with other users.
+
+Every flag can also be set from the environment, for containers and
+automated pipelines: PA_PEDIA_<COMMAND>_<FLAG> (e.g. PA_PEDIA_SERVE_ADDR)
+or PA_PEDIA_<FLAG> for every command with that flag (e.g. PA_PEDIA_PA_ROOT).
+Flags on the command line take precedence.`,
 	SilenceUsage:      true,
 	SilenceErrors:     true,
-	PersistentPreRunE: checkForUpdates,
+	PersistentPreRunE: beforeCommand,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
-func Execute() error {
-	return rootCmd.Execute()
+// A panic is turned into an error pointing at a crash bundle (see reportPanic), as is a
+// failure under --crash-report.
+func Execute() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = reportPanic(r)
+		}
+	}()
+	if target, _, err := rootCmd.Find(os.Args[1:]); err == nil {
+		if err := applyEnvFlags(target); err != nil {
+			return err
+		}
+	}
+	if err := rootCmd.Execute(); err != nil {
+		if crashReport {
+			return reportFailure(err)
+		}
+		return err
+	}
+	return nil
 }
 
 func init() {
@@ -42,12 +69,30 @@ func init() {
 
 	// Global flags
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose logging")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Never colour output (also NO_COLOR; colour is only used on a terminal)")
+	rootCmd.PersistentFlags().BoolVar(&crashReport, "crash-report", false, "Write a redacted diagnostics bundle (pa-pedia-crash-<time>.zip) if the command fails, for a bug report; panics always write one")
+	rootCmd.PersistentFlags().StringVar(&localeFlag, "locale", "", "Language of prompts, errors and summaries: en, de or fr (default: from LC_ALL/LANG)")
+}
+
+// beforeCommand sets up output styling and language, then checks for updates
+func beforeCommand(cmd *cobra.Command, args []string) error {
+	style.Configure(noColor)
+	locale := localeFlag
+	if locale == "" {
+		locale = i18n.Detect()
+	}
+	if err := i18n.SetLocale(locale); err != nil {
+		return fmt.Errorf("%w\n\nPass --locale en, de or fr", err)
+	}
+	return checkForUpdates(cmd, args)
 }
 
-// Helper function for verbose logging
+// Helper function for verbose logging. Lines are kept for crash bundles even without --verbose.
 func logVerbose(format string, args ...interface{}) {
+	line := fmt.Sprintf("[VERBOSE] "+format, args...)
+	recentOutput.Add(line)
 	if verbose {
-		fmt.Fprintf(os.Stderr, "[VERBOSE] "+format+"\n", args...)
+		fmt.Fprintln(os.Stderr, line)
 	}
 }
 
@@ -59,12 +104,23 @@ func checkForUpdates(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Nothing may precede the document a --json run prints
+	if jsonOutput {
+		return nil
+	}
+
 	// Skip if disabled via environment variable
 	if disableUpdateCheck {
 		logVerbose("Update check disabled via PA_PEDIA_NO_UPDATE_CHECK")
 		return nil
 	}
 
+	// Never replace the binary under an unattended run (containers, CI, cron, pipes)
+	if !interactive() {
+		logVerbose("Skipping update check: not running interactively")
+		return nil
+	}
+
 	// Skip in development mode
 	if updater.IsDevelopmentVersion(Version) {
 		logVerbose("Skipping update check in development mode")
@@ -86,18 +142,18 @@ func checkForUpdates(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	fmt.Printf("New version available: %s (current: %s)\n", info.LatestVersion, info.CurrentVersion)
-	fmt.Println("Updating...")
+	i18n.Printf("New version available: %s (current: %s)\n", info.LatestVersion, info.CurrentVersion)
+	i18n.Println("Updating...")
 
 	result, err := updater.PerformUpdate(Version)
 	if err != nil {
 		// Log error but don't block the user's command
-		fmt.Fprintf(os.Stderr, "Update failed: %v\n", err)
-		fmt.Fprintln(os.Stderr, "Continuing with current version...")
+		fmt.Fprintf(os.Stderr, i18n.T("Update failed: %v\n"), err)
+		fmt.Fprintln(os.Stderr, i18n.T("Continuing with current version..."))
 		return nil
 	}
 
-	fmt.Printf("Successfully updated to %s\n\n", result.LatestVersion)
+	i18n.Printf("Successfully updated to %s\n\n", result.LatestVersion)
 
 	// Re-exec the command with the new binary
 	return reExecWithNewBinary()
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/jamiemulcahy/pa-pedia/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+var schemaOutput string
+
+// schemaCmd regenerates the JSON Schemas and protobuf definition from the Go models.
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Generate JSON schemas and faction-index.proto from the Go models",
+	Long: `Write a JSON Schema for each file format PA-Pedia produces or reads
+(metadata.json, units.json, faction profiles, credits, reports, ...) and the
+protobuf definition of units.pb to --output.
+
+The repository's schema/ directory is generated this way; the web app's
+TypeScript types are generated from it in turn (just schema-sync). This is a
+development command: run it after changing the models.`,
+	Example: `  pa-pedia schema --output ../schema`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logVerbose("Output directory: %s", schemaOutput)
+		names, err := schema.Generate(schemaOutput)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			printStatus("✓ Generated: %s\n", name)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+
+	schemaCmd.Flags().StringVar(&schemaOutput, "output", "./schema", "Output directory for schema files")
+}
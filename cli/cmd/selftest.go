@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/selftest"
+	"github.com/spf13/cobra"
+)
+
+var selftestPARoot string
+
+// selftestCmd smoke-tests extraction against a real PA install
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Smoke-test extraction against a PA installation",
+	Long: `Parse a handful of well-known MLA units from a PA installation and check them
+against invariants that should hold across balance patches (the Ant has one
+weapon, the commander has a build arm, the metal extractor produces metal...).
+
+Only those units are parsed, so this finishes in seconds. Exits non-zero if any
+check fails, making it suitable for CI after a PA update. --json prints the
+checks as {"checks": [{"case", "check", "passed", "error"}], "failed"}.`,
+	Example: `  pa-pedia selftest --pa-root "C:/Program Files (x86)/Steam/steamapps/common/Planetary Annihilation Titans/media"`,
+	RunE:    runSelftest,
+}
+
+func init() {
+	rootCmd.AddCommand(selftestCmd)
+
+	selftestCmd.Flags().StringVar(&selftestPARoot, "pa-root", "", "Path to PA Titans media directory")
+	selftestCmd.MarkFlagRequired("pa-root")
+	addJSONFlag(selftestCmd)
+}
+
+// selftestReport is the --json output of the selftest command
+type selftestReport struct {
+	Checks []selftestCheck `json:"checks"`
+	Failed int             `json:"failed"`
+}
+
+type selftestCheck struct {
+	Case   string `json:"case"`
+	Check  string `json:"check"`
+	Passed bool   `json:"passed"`
+	Error  string `json:"error,omitempty"` // Set when the unit could not be found or parsed
+}
+
+func runSelftest(cmd *cobra.Command, args []string) error {
+	l, err := loader.NewMultiSourceLoader(selftestPARoot, "pa_ex1", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create loader: %w", err)
+	}
+	defer l.Close()
+
+	if jsonOutput {
+		return printSelftestJSON(l)
+	}
+
+	fmt.Println("=== PA-Pedia Self-Test ===")
+	fmt.Println()
+
+	results, err := selftest.Run(l, selftest.MLACases())
+	if err != nil {
+		return err
+	}
+
+	lastCase := ""
+	for _, r := range results {
+		if r.Case != lastCase {
+			fmt.Println(r.Case)
+			lastCase = r.Case
+		}
+		switch {
+		case r.Err != nil:
+			printStatus("  ✗ %v\n", r.Err)
+		case r.Passed:
+			printStatus("  ✓ %s\n", r.Check)
+		default:
+			printStatus("  ✗ %s\n", r.Check)
+		}
+	}
+	fmt.Println()
+
+	if failed := selftest.Failures(results); failed > 0 {
+		return fmt.Errorf("self-test failed: %d of %d checks failed", failed, len(results))
+	}
+
+	printStatus("✓ All %d checks passed\n", len(results))
+	return nil
+}
+
+// printSelftestJSON runs the checks and prints them as a selftestReport
+func printSelftestJSON(l *loader.Loader) error {
+	defer progressToStderr()()
+	results, err := selftest.Run(l, selftest.MLACases())
+	if err != nil {
+		return err
+	}
+	report := selftestReport{Checks: make([]selftestCheck, 0, len(results)), Failed: selftest.Failures(results)}
+	for _, r := range results {
+		check := selftestCheck{Case: r.Case, Check: r.Check, Passed: r.Passed}
+		if r.Err != nil {
+			check.Error = r.Err.Error()
+		}
+		report.Checks = append(report.Checks, check)
+	}
+	if err := printJSON(report); err != nil {
+		return err
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("self-test failed: %d of %d checks failed", report.Failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/serve"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr          string
+	serveWatch         bool
+	serveWatchInterval time.Duration
+	serveCacheMaxAge   time.Duration
+	serveCORSOrigins   []string
+	serveTLSCert       string
+	serveTLSKey        string
+)
+
+// serveCmd serves exported faction folders over HTTP for the web app.
+var serveCmd = &cobra.Command{
+	Use:   "serve [factions-dir]",
+	Short: "Serve exported faction folders and versions over HTTP (read-only)",
+	Long: `Serve a directory of faction folders produced by describe-faction (default
+./factions) at /factions/<folder>/, the layout the web app loads from.
+
+The directory may also hold versioned folders (<id>/<version>/metadata.json)
+and release archives (*.zip with the faction files at their root, or
+.pafaction bundles), so one read-only server can host many faction versions:
+
+  GET /factions                       factions and their versions (JSON)
+  GET /factions/mla/versions          versions of mla, newest first
+  GET /factions/mla@1.2.0/units       units of version 1.2.0
+  GET /factions/mla/units             units of the newest version
+
+Unit listings are JSON by default; ?format=ndjson or ?format=csv (or an
+Accept header of application/x-ndjson or text/csv) return one JSON object
+per line, or a flat table with dotted column names for spreadsheets.
+  GET /factions/mla@1.2.0/<file>      any file of that version
+
+Versions are read from each source's metadata.json.
+
+With --watch the directory is polled for re-exports, and every change is
+pushed as a JSON message to WebSocket clients connected to /ws:
+
+  {"type":"unit-changed","faction":"MLA","unit":"tank_light_laser","change":"modified"}
+  {"type":"faction-reloaded","faction":"MLA","change":"modified"}
+
+unit-changed is sent for each added, removed or modified unit, followed by one
+faction-reloaded per faction folder (change is added, modified or removed).
+A web app in development can refetch on these instead of being refreshed by
+hand after each describe-faction run.
+
+Faction files carry a strong ETag (a SHA-256 of their content, cached until
+the file's size or mtime changes) and Cache-Control: no-cache, so browsers
+keep them but revalidate on each load and get 304 Not Modified until a
+re-export actually changes a file. --cache-max-age skips revalidation for
+that long instead, for hosts whose data rarely changes.
+
+To expose the server to a web app hosted elsewhere without a reverse proxy,
+allow its origin with --cors-origin (repeatable, or * for any) and serve
+HTTPS with --tls-cert and --tls-key; browsers block plain-HTTP fetches
+from HTTPS pages. Bind a public interface with --addr, e.g. :8443.
+
+For monitoring, GET /healthz answers 200 while the server is up, GET /readyz
+answers 503 until at least one faction is loaded, and GET /metrics exposes
+Prometheus metrics (requests by route and status, faction load times, ETag
+hash cache hits and misses). SIGTERM stops the server gracefully.`,
+	Example: `  pa-pedia serve
+  pa-pedia serve ./factions --watch
+  pa-pedia serve ./factions --addr localhost:9000 --watch --watch-interval 500ms
+  pa-pedia serve ./factions --addr :8443 --tls-cert cert.pem --tls-key key.pem \
+    --cors-origin https://pa-pedia.example.com`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "localhost:8080", "Address to listen on (e.g. :8080 for all interfaces)")
+	serveCmd.Flags().BoolVar(&serveWatch, "watch", false, "Watch for re-exports and push change notifications on /ws")
+	serveCmd.Flags().DurationVar(&serveCacheMaxAge, "cache-max-age", 0, "Let browsers reuse faction files this long without revalidating (default: always revalidate)")
+	serveCmd.Flags().DurationVar(&serveWatchInterval, "watch-interval", serve.DefaultWatchInterval, "How often --watch polls the factions directory")
+	serveCmd.Flags().StringArrayVar(&serveCORSOrigins, "cors-origin", nil, "Origin allowed to fetch faction data cross-origin, e.g. https://pa-pedia.example.com (repeatable, * for any)")
+	serveCmd.Flags().StringVar(&serveTLSCert, "tls-cert", "", "PEM certificate file; serve HTTPS (requires --tls-key)")
+	serveCmd.Flags().StringVar(&serveTLSKey, "tls-key", "", "PEM private key file for --tls-cert")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	root := "./factions"
+	if len(args) == 1 {
+		root = args[0]
+	}
+	origins, err := serve.ParseOrigins(serveCORSOrigins)
+	if err != nil {
+		return err
+	}
+	if (serveTLSCert == "") != (serveTLSKey == "") {
+		return fmt.Errorf("--tls-cert and --tls-key must be used together")
+	}
+	if serveTLSCert != "" {
+		// Fail before printing the banner rather than when the listener starts
+		if _, err := tls.LoadX509KeyPair(serveTLSCert, serveTLSKey); err != nil {
+			return fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+	}
+
+	if _, err := os.Stat(root); err != nil {
+		return fmt.Errorf("failed to read factions directory: %w\n\nPass the --output directory of describe-faction", err)
+	}
+
+	server := serve.New(root, serve.Options{
+		Watch:         serveWatch,
+		WatchInterval: serveWatchInterval,
+		CacheMaxAge:   serveCacheMaxAge,
+		CORSOrigins:   origins,
+		TLSCert:       serveTLSCert,
+		TLSKey:        serveTLSKey,
+		Logf: func(format string, args ...any) {
+			fmt.Printf(format+"\n", args...)
+		},
+	})
+
+	for _, err := range server.Rescan() {
+		printStatus("⚠ %v\n", err)
+	}
+	factions, versions := server.Count()
+	if factions == 0 && !serveWatch {
+		printStatus("⚠ No factions in %s yet\n", root)
+	}
+
+	scheme, wsScheme := "http", "ws"
+	if server.TLS() {
+		scheme, wsScheme = "https", "wss"
+	}
+	fmt.Printf("Serving %d factions (%d versions) from %s at %s://%s/factions/\n", factions, versions, root, scheme, serveAddr)
+	if len(origins) > 0 {
+		fmt.Printf("Allowing cross-origin requests from %s\n", strings.Join(origins, ", "))
+	}
+	if serveWatch {
+		fmt.Printf("Watching for changes; notifications on %s://%s/ws\n", wsScheme, serveAddr)
+	}
+	fmt.Println("Press Ctrl+C to stop")
+
+	// SIGTERM is what systemd and docker stop send
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return server.ListenAndServe(ctx, serveAddr)
+}
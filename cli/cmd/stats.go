@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsDistributions    bool
+	statsDistributionsOut string
+	statsBins             int
+)
+
+// statsCmd prints a summary dashboard of an exported faction folder.
+var statsCmd = &cobra.Command{
+	Use:   "stats <faction-dir>",
+	Short: "Summarize an exported faction in the terminal",
+	Long: `Print a dashboard for a faction folder produced by describe-faction: units
+per tier and build bar category, the top DPS units, the cheapest and most
+expensive units, per-tier averages and units with missing icons.
+
+Only accessible units (buildable from a commander) are counted. Use it as a
+quick sanity check after extraction without opening the web app.
+
+For balance analysis, --distributions adds DPS, health, cost and speed
+histograms with percentiles for each tier, and --distributions-out writes the
+same data (including the units in each bucket) as JSON. --json prints the
+dashboard on stdout as {"faction", "units", "inaccessible", "byTier",
+"byCategory", "topDps", "cheapest", "mostExpensive", "averages",
+"missingIcons"}, with "distributions" added by --distributions.`,
+	Example: `  pa-pedia stats ./factions/MLA
+  pa-pedia stats ./factions/Legion --distributions --bins 8
+  pa-pedia stats ./factions/MLA --distributions-out distributions.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runStats,
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().BoolVar(&statsDistributions, "distributions", false, "Print per-tier histograms and percentiles of DPS, health, cost and speed")
+	statsCmd.Flags().StringVar(&statsDistributionsOut, "distributions-out", "", "Write the distributions as JSON to this file (e.g. distributions.json)")
+	statsCmd.Flags().IntVar(&statsBins, "bins", stats.DefaultBins, "Histogram buckets per distribution")
+	addJSONFlag(statsCmd)
+}
+
+// statsReport is the --json output of the stats command
+type statsReport struct {
+	Faction string `json:"faction"`
+	*stats.Summary
+	Distributions []stats.Distribution `json:"distributions,omitempty"`
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	factionDir := args[0]
+
+	index, err := exporter.ReadFactionIndex(factionDir)
+	if err != nil {
+		return fmt.Errorf("failed to load faction from %s: %w\n\nPass a folder produced by describe-faction", factionDir, err)
+	}
+	name := factionDir
+	if metadata, err := exporter.ReadFactionMetadata(factionDir); err == nil {
+		name = metadata.DisplayName
+	}
+
+	summary := stats.Summarize(index, factionDir)
+	if summary.Units == 0 {
+		return fmt.Errorf("%s has no accessible units", factionDir)
+	}
+	if jsonOutput {
+		defer progressToStderr()()
+		report := statsReport{Faction: name, Summary: summary}
+		if statsDistributions || statsDistributionsOut != "" {
+			distributions, err := writeStatsDistributions(name, index)
+			if err != nil {
+				return err
+			}
+			if statsDistributions {
+				report.Distributions = distributions
+			}
+		}
+		return printJSON(report)
+	}
+
+	fmt.Printf("=== PA-Pedia Faction Stats: %s ===\n", name)
+	fmt.Println()
+	fmt.Printf("Units: %d accessible", summary.Units)
+	if summary.Inaccessible > 0 {
+		fmt.Printf(" (%d inaccessible not counted)", summary.Inaccessible)
+	}
+	fmt.Println()
+
+	tiers := make([]string, len(summary.ByTier))
+	for i, t := range summary.ByTier {
+		tiers[i] = fmt.Sprintf("T%d %d", t.Tier, t.Count)
+	}
+	fmt.Printf("By tier:     %s\n", strings.Join(tiers, "  "))
+
+	categories := make([]string, len(summary.ByCategory))
+	for i, c := range summary.ByCategory {
+		categories[i] = fmt.Sprintf("%s %d", c.Category, c.Count)
+	}
+	fmt.Printf("By category: %s\n", strings.Join(categories, "  "))
+
+	printRanked("Top DPS", summary.TopDPS, "%.1f dps")
+	printRanked("Cheapest", summary.Cheapest, "%.0f metal")
+	printRanked("Most expensive", summary.MostExpensive, "%.0f metal")
+
+	fmt.Println()
+	fmt.Println("Averages per tier (commanders excluded; DPS over armed units, speed over mobile units):")
+	fmt.Printf("  %-4s %6s %10s %10s %9s %7s\n", "Tier", "Units", "Health", "Cost", "DPS", "Speed")
+	for _, avg := range summary.Averages {
+		fmt.Printf("  T%-3d %6d %10.0f %10.0f %9.1f %7.1f\n", avg.Tier, avg.Units, avg.Health, avg.BuildCost, avg.DPS, avg.MoveSpeed)
+	}
+	fmt.Println()
+
+	if len(summary.MissingIcons) == 0 {
+		printStatus("✓ Every unit has an icon\n")
+	} else {
+		printStatus("⚠ %d units are missing icons: %s\n", len(summary.MissingIcons), strings.Join(summary.MissingIcons, ", "))
+	}
+
+	if !statsDistributions && statsDistributionsOut == "" {
+		return nil
+	}
+	distributions, err := writeStatsDistributions(name, index)
+	if err != nil {
+		return err
+	}
+	if statsDistributions {
+		printDistributions(distributions)
+	}
+	return nil
+}
+
+// writeStatsDistributions computes the distributions, writing them to --distributions-out
+// when it's set
+func writeStatsDistributions(name string, index *models.FactionIndex) ([]stats.Distribution, error) {
+	if statsBins < 1 {
+		return nil, fmt.Errorf("--bins must be at least 1, got %d", statsBins)
+	}
+	distributions := stats.Distributions(stats.Units(index), statsBins)
+
+	if statsDistributionsOut != "" {
+		report := stats.DistributionReport{Faction: name, Bins: statsBins, Distributions: distributions}
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal distributions: %w", err)
+		}
+		if err := os.WriteFile(statsDistributionsOut, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write distributions: %w", err)
+		}
+		printStatus("✓ Wrote distributions to %s\n", statsDistributionsOut)
+	}
+	return distributions, nil
+}
+
+// printDistributions prints an ASCII histogram with percentiles per metric and tier
+func printDistributions(distributions []stats.Distribution) {
+	units := make(map[string]string, len(stats.Metrics))
+	for _, m := range stats.Metrics {
+		units[m.Name] = m.Unit
+	}
+
+	for _, d := range distributions {
+		fmt.Println()
+		fmt.Printf("%s T%d (%d units, %s): p10 %.1f  p25 %.1f  median %.1f  p75 %.1f  p90 %.1f  mean %.1f\n",
+			d.Metric, d.Tier, d.Count, units[d.Metric],
+			d.Percentiles.P10, d.Percentiles.P25, d.Percentiles.P50, d.Percentiles.P75, d.Percentiles.P90, d.Mean)
+		for _, line := range stats.Chart(d, 40) {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+}
+
+// printRanked prints a leaderboard with values formatted by valueFormat
+func printRanked(title string, entries []stats.Ranked, valueFormat string) {
+	if len(entries) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Printf("%s:\n", title)
+	for i, e := range entries {
+		fmt.Printf("  %d. %-34s T%d  %s\n", i+1, fmt.Sprintf("%s (%s)", e.DisplayName, e.ID), e.Tier, fmt.Sprintf(valueFormat, e.Value))
+	}
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusPARoot      string
+	statusFactionsDir string
+)
+
+// statusCmd reports which exported factions were extracted from an older PA build
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which exported factions are stale against the installed PA build",
+	Long: `Compare the build of the local PA installation with the build each exported
+faction was extracted from (paBuild in metadata.json), and highlight factions
+whose underlying game data has changed since export.
+
+With --json, prints {"installedBuild", "factions": [{"name", "dir", "build",
+"state"}]} where state is current, stale or unknown.
+
+Exports made before paBuild was recorded fall back to the version of base-game
+factions; other factions are reported as unknown until re-exported.`,
+	Example: `  pa-pedia status --pa-root "C:/Program Files (x86)/Steam/steamapps/common/Planetary Annihilation Titans/media"
+  pa-pedia status --pa-root /path/to/media --factions ../factions`,
+	RunE: runStatus,
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().StringVar(&statusPARoot, "pa-root", "", "Path to PA Titans media directory")
+	statusCmd.Flags().StringVar(&statusFactionsDir, "factions", "./factions", "Directory containing exported faction folders")
+	statusCmd.MarkFlagRequired("pa-root")
+	addJSONFlag(statusCmd)
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	installed := detectPAVersion(statusPARoot)
+	if installed == "" {
+		return fmt.Errorf("could not detect the PA build from %s\n\nExpected version.txt or build.txt in the install root (parent of media/)", statusPARoot)
+	}
+
+	factions, err := exporter.ScanFactions(statusFactionsDir)
+	if err != nil {
+		return err
+	}
+	if len(factions) == 0 {
+		return fmt.Errorf("no exported factions found in %s", statusFactionsDir)
+	}
+
+	if jsonOutput {
+		return printJSON(exportStatus(installed, factions))
+	}
+
+	fmt.Println("=== PA-Pedia Export Status ===")
+	fmt.Println()
+	fmt.Printf("Installed PA build: %s\n\n", installed)
+
+	stale, unknown := 0, 0
+	for _, f := range factions {
+		name := filepath.Base(f.Dir)
+		exported := f.ExportedBuild()
+		switch {
+		case exported == "":
+			unknown++
+			fmt.Printf("  ? %-16s build unknown (re-export to record it)\n", name)
+		case exported == installed:
+			printStatus("  ✓ %-16s build %s\n", name, exported)
+		default:
+			stale++
+			printStatus("  ⚠ %-16s build %s, game data has changed since export\n", name, exported)
+		}
+	}
+	fmt.Println()
+
+	if stale > 0 {
+		printStatus("⚠ %d of %d factions are stale; re-run describe-faction for them\n", stale, len(factions))
+	} else if unknown == 0 {
+		printStatus("✓ All factions match the installed build\n")
+	}
+	return nil
+}
+
+// statusReport is the --json output of the status command
+type statusReport struct {
+	InstalledBuild string          `json:"installedBuild"`
+	Factions       []factionStatus `json:"factions"`
+}
+
+type factionStatus struct {
+	Name  string `json:"name"`
+	Dir   string `json:"dir"`
+	Build string `json:"build,omitempty"` // Empty when unknown
+	State string `json:"state"`           // current, stale or unknown
+}
+
+func exportStatus(installed string, factions []exporter.ExportedFaction) statusReport {
+	report := statusReport{InstalledBuild: installed, Factions: make([]factionStatus, 0, len(factions))}
+	for _, f := range factions {
+		status := factionStatus{Name: filepath.Base(f.Dir), Dir: f.Dir, Build: f.ExportedBuild()}
+		switch status.Build {
+		case "":
+			status.State = "unknown"
+		case installed:
+			status.State = "current"
+		default:
+			status.State = "stale"
+		}
+		report.Factions = append(report.Factions, status)
+	}
+	return report
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"syscall"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/i18n"
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/profiles"
+	"github.com/jamiemulcahy/pa-pedia/pkg/serve"
+	"github.com/jamiemulcahy/pa-pedia/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	uiAddr       string
+	uiPARoot     string
+	uiDataRoot   string
+	uiProfileDir string
+	uiOutput     string
+	uiOpen       bool
+)
+
+// uiCmd serves a browser frontend for extracting and browsing factions.
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Open a local web page for extracting and browsing factions",
+	Long: `Start a local web server with a small graphical frontend, for players who
+would rather not use describe-faction's flags:
+
+  - pick a faction profile, point it at the PA install and extract it, with
+    the extraction's progress shown as it runs
+  - browse the exported factions' units, with a filter
+
+The page is embedded in the binary. Extractions run describe-faction with the
+chosen profile into --output, one at a time; everything in --output is also
+served at /factions/ as by pa-pedia serve. --pa-root and --data-root only
+prefill the form (the data root defaults to the platform's PA data
+directory), and the page remembers what was last entered.
+
+The page opens in the default browser unless --open=false or the session isn't
+interactive. Ctrl+C stops the server and any running extraction.`,
+	Example: `  pa-pedia ui
+  pa-pedia ui --pa-root "C:/Program Files (x86)/Steam/steamapps/common/Planetary Annihilation Titans/media"
+  pa-pedia ui --addr localhost:9000 --output ./factions --open=false`,
+	Args: cobra.NoArgs,
+	RunE: runUI,
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+
+	uiCmd.Flags().StringVar(&uiAddr, "addr", "localhost:8080", "Address to listen on")
+	uiCmd.Flags().StringVar(&uiPARoot, "pa-root", "", "Path to PA Titans media directory, to prefill the form")
+	uiCmd.Flags().StringVar(&uiDataRoot, "data-root", "", "Path to PA data directory, to prefill the form (default: the platform's PA data directory)")
+	uiCmd.Flags().StringVar(&uiProfileDir, "profile-dir", "./profiles", "Directory for custom faction profiles")
+	uiCmd.Flags().StringVar(&uiOutput, "output", "./factions", "Directory extractions are written to and browsed from")
+	uiCmd.Flags().BoolVar(&uiOpen, "open", true, "Open the page in the default browser")
+}
+
+func runUI(cmd *cobra.Command, args []string) error {
+	profileLoader, err := profiles.NewLoader()
+	if err != nil {
+		return fmt.Errorf("failed to initialize profile loader: %w", err)
+	}
+	if err := profileLoader.LoadLocalProfiles(uiProfileDir); err != nil {
+		return fmt.Errorf("failed to load local profiles: %w", err)
+	}
+	var uiProfiles []ui.Profile
+	for _, p := range profileLoader.GetAllProfiles() {
+		uiProfiles = append(uiProfiles, ui.Profile{ID: p.ID, DisplayName: p.DisplayName, Description: p.Description, IsAddon: p.IsAddon, Mods: p.Mods})
+	}
+
+	dataRoot := uiDataRoot
+	if dataRoot == "" {
+		if detected, err := loader.GetDefaultPADataRoot(); err == nil {
+			if _, err := os.Stat(detected); err == nil {
+				dataRoot = detected
+			}
+		}
+	}
+	if err := os.MkdirAll(uiOutput, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	server := serve.New(uiOutput, serve.Options{})
+	for _, err := range server.Rescan() {
+		printStatus("⚠ %v\n", err)
+	}
+	frontend := ui.New(server, ui.Options{
+		Profiles: uiProfiles,
+		PARoot:   uiPARoot,
+		DataRoot: dataRoot,
+		Extract:  runUIExtraction,
+	})
+
+	url := "http://" + browserAddr(uiAddr) + "/"
+	fmt.Printf("PA-Pedia is running at %s\n", url)
+	fmt.Println("Press Ctrl+C to stop")
+	if uiOpen && interactive() {
+		if err := openBrowser(url); err != nil {
+			logVerbose("Could not open a browser: %v", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return frontend.ListenAndServe(ctx, uiAddr)
+}
+
+// runUIExtraction runs describe-faction for a ui request in a child process, so its output
+// can be streamed to the page and its package-level flag state stays its own
+func runUIExtraction(ctx context.Context, req ui.Request, w io.Writer) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+	args := []string{"describe-faction",
+		"--profile", req.Profile,
+		"--profile-dir", uiProfileDir,
+		"--pa-root", req.PARoot,
+		"--output", uiOutput,
+		"--no-color",
+		"--locale", i18n.Locale(),
+	}
+	if req.DataRoot != "" {
+		args = append(args, "--data-root", req.DataRoot)
+	}
+	logVerbose("Running %s %v", exe, args)
+
+	child := exec.CommandContext(ctx, exe, args...)
+	child.Stdout = w
+	child.Stderr = w
+	if err := child.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return fmt.Errorf("describe-faction exited with status %d; see its output", exitErr.ExitCode())
+		}
+		return fmt.Errorf("failed to run describe-faction: %w", err)
+	}
+	return nil
+}
+
+// browserAddr turns a listen address into one a browser can open: ":8080" listens on
+// every interface, so the page is opened on localhost
+func browserAddr(addr string) string {
+	if len(addr) > 0 && addr[0] == ':' {
+		return "localhost" + addr
+	}
+	return addr
+}
+
+// openBrowser opens url in the platform's default browser
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	case "darwin":
+		cmd = exec.Command("open", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/bundle"
+	"github.com/spf13/cobra"
+)
+
+var (
+	unpackBundle string
+	unpackOutput string
+	unpackForce  bool
+)
+
+// unpackCmd verifies a .pafaction bundle and extracts it as a faction folder.
+var unpackCmd = &cobra.Command{
+	Use:   "unpack",
+	Short: "Verify and extract a .pafaction bundle into a faction folder",
+	Long: `Extract a .pafaction bundle (see 'pa-pedia pack') into a faction folder.
+
+Every file is checked against the size and SHA-256 recorded in the bundle's
+manifest. Files missing from the manifest, listed files missing from the bundle,
+and checksum mismatches abort the unpack without touching the output directory.`,
+	Example: `  pa-pedia unpack --bundle ./MLA.pafaction
+  pa-pedia unpack --bundle ./legion.pafaction --output ./factions --force`,
+	RunE: runUnpack,
+}
+
+func init() {
+	rootCmd.AddCommand(unpackCmd)
+
+	unpackCmd.Flags().StringVar(&unpackBundle, "bundle", "", "Path to the .pafaction bundle")
+	unpackCmd.Flags().StringVar(&unpackOutput, "output", "./factions", "Output directory for faction folders")
+	unpackCmd.Flags().BoolVar(&unpackForce, "force", false, "Replace the faction folder if it already exists")
+	unpackCmd.MarkFlagRequired("bundle")
+}
+
+func runUnpack(cmd *cobra.Command, args []string) error {
+	manifest, factionDir, err := bundle.Unpack(unpackBundle, unpackOutput, unpackForce)
+	if err != nil {
+		return fmt.Errorf("failed to unpack %s: %w", unpackBundle, err)
+	}
+
+	logVerbose("Bundle packed by %s, format version %d", manifest.CreatedBy, manifest.FormatVersion)
+	printStatus("✓ Verified and unpacked %s %s (%d files) into %s\n", manifest.DisplayName, manifest.Version, len(manifest.Files), factionDir)
+	return nil
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+// validateCmd cross-checks the build references of an exported faction folder.
+var validateCmd = &cobra.Command{
+	Use:   "validate <faction-dir>",
+	Short: "Check that every unit an export references exists",
+	Long: `Cross-check the build graph of a faction folder produced by describe-faction.
+Every unit ID in builds, builtBy, reachability, techPath and buildMenu, and
+every resource path in spawnUnitOnDeath and initialBuildSpec, must be a unit
+in units.json. Every accessible unit must also have a builder unless it's a
+commander or spawned by another unit.
+
+Addons (isAddon in metadata.json) reference their base factions' units, so
+IDs outside the folder are accepted for them.
+
+describe-faction runs the same checks after exporting and prints them as
+warnings; this command exits with an error when any are found, for CI.
+With --json the problems are printed as {"units", "issues": [{"unit", "field",
+"ref", "message"}]} and the exit status is the same.`,
+	Example: `  pa-pedia validate ./factions/MLA
+  pa-pedia validate ./factions/Second-Wave`,
+	Args: cobra.ExactArgs(1),
+	RunE: runValidate,
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	addJSONFlag(validateCmd)
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	factionDir := args[0]
+	issues, units, err := validateExport(factionDir)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		report := validationReport{Units: units, Issues: issues}
+		if report.Issues == nil {
+			report.Issues = []exporter.ReferenceIssue{} // [] rather than null
+		}
+		if err := printJSON(report); err != nil {
+			return err
+		}
+		if len(issues) > 0 {
+			return i18n.Errorf("%d reference problem(s) in %s", len(issues), factionDir)
+		}
+		return nil
+	}
+	if len(issues) == 0 {
+		printStatus("✓ %d units, all references resolve\n", units)
+		return nil
+	}
+	for _, issue := range issues {
+		printStatus("✗ %s\n", issue)
+	}
+	return i18n.Errorf("%d reference problem(s) in %s", len(issues), factionDir)
+}
+
+// validationReport is the --json output of the validate command
+type validationReport struct {
+	Units  int                       `json:"units"`
+	Issues []exporter.ReferenceIssue `json:"issues"`
+}
+
+// validateExport reads a faction folder back and cross-checks its references. It also
+// returns the number of units in the index.
+func validateExport(factionDir string) ([]exporter.ReferenceIssue, int, error) {
+	index, err := exporter.ReadFactionIndex(factionDir)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load faction from %s: %w\n\nPass a folder produced by describe-faction", factionDir, err)
+	}
+	metadata, err := exporter.ReadFactionMetadata(factionDir)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load faction from %s: %w\n\nPass a folder produced by describe-faction", factionDir, err)
+	}
+	return exporter.ValidateReferences(index, metadata.IsAddon), len(index.Units), nil
+}
+
+// warnReferenceIssues validates a fresh export and prints what it finds as warnings
+func warnReferenceIssues(factionDir string) error {
+	issues, _, err := validateExport(factionDir)
+	if err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		logVerbose("All build references resolve")
+		return nil
+	}
+	printStatus("⚠ %d build reference problem(s) in the export:\n", len(issues))
+	for i, issue := range issues {
+		if i == 10 && !verbose {
+			i18n.Printf("  ... and %d more (run pa-pedia validate %s)\n", len(issues)-i, factionDir)
+			break
+		}
+		fmt.Printf("  %s\n", issue)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"github.com/jamiemulcahy/pa-pedia/pkg/i18n"
+	"github.com/jamiemulcahy/pa-pedia/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+// validateFactionCmd checks an exported faction folder against the generated JSON schemas
+var validateFactionCmd = &cobra.Command{
+	Use:   "validate-faction <faction-dir>",
+	Short: "Check that an export conforms to the JSON schemas",
+	Long: `Validate a faction folder produced by describe-faction against the schemas
+in schema/: metadata.json against faction-metadata and units.json against
+faction-index, which covers every resolved unit in it with the unit schema.
+
+Each violation is reported with its path, line and the unit it belongs to,
+e.g. "units.json: tank: units[3].unit.specs.combat.dps must be a number at
+line 120". The schemas are generated from this version's models, so an export
+written by a different pa-pedia version may fail where its fields changed;
+compat-check explains what such a folder loses on load.
+
+The command exits with an error when any violations are found, for CI. With
+--json the result is printed as {"units", "violations": [{"file", "unit",
+"path", "line", "column", "message"}]}.`,
+	Example: `  pa-pedia validate-faction ./factions/MLA
+  pa-pedia validate-faction ./factions/Legion --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runValidateFaction,
+}
+
+func init() {
+	rootCmd.AddCommand(validateFactionCmd)
+	addJSONFlag(validateFactionCmd)
+}
+
+func runValidateFaction(cmd *cobra.Command, args []string) error {
+	factionDir := args[0]
+	result, err := schema.ValidateFaction(factionDir)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		if err := printJSON(result); err != nil {
+			return err
+		}
+	} else {
+		for _, v := range result.Violations {
+			printStatus("✗ %s\n", v)
+		}
+	}
+	if len(result.Violations) > 0 {
+		return i18n.Errorf("%d schema violation(s) in %s", len(result.Violations), factionDir)
+	}
+	if !jsonOutput {
+		printStatus("✓ metadata.json and %d units match the schemas\n", result.Units)
+	}
+	return nil
+}
@@ -23,17 +23,31 @@ Use the --verbose (-v) flag to see additional build information:
   pa-pedia version -v
   pa-pedia v1.0.0
     commit: abc1234
-    built:  2025-01-15T10:30:00Z`,
-	Run: func(cmd *cobra.Command, args []string) {
+    built:  2025-01-15T10:30:00Z
+
+Use --json for {"version", "commit", "date"} instead.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if jsonOutput {
+			return printJSON(versionInfo{Version: Version, Commit: Commit, Date: Date})
+		}
 		fmt.Printf("pa-pedia %s\n", Version)
 		// verbose is a persistent flag defined on rootCmd and inherited by all subcommands
 		if verbose {
 			fmt.Printf("  commit: %s\n", Commit)
 			fmt.Printf("  built:  %s\n", Date)
 		}
+		return nil
 	},
 }
 
+// versionInfo is the --json output of the version command
+type versionInfo struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	Date    string `json:"date"`
+}
+
 func init() {
 	rootCmd.AddCommand(versionCmd)
+	addJSONFlag(versionCmd)
 }
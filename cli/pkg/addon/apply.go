@@ -0,0 +1,303 @@
+// Package addon merges an addon export into the export of a faction it extends, so
+// consumers get one faction folder instead of stitching the two together at runtime.
+package addon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/parser"
+)
+
+// Options adjusts the merged faction's metadata
+type Options struct {
+	DisplayName string // Defaults to "<base> + <addon>"
+	Version     string // Defaults to the base faction's version
+	JSON        exporter.JSONOptions
+}
+
+// Result summarizes a merge
+type Result struct {
+	FactionDir string   // Merged faction folder (empty for Merge)
+	Units      int      // Units in the merged index
+	Added      []string // Addon unit IDs in the merged faction
+	Replaced   []string // Base unit IDs the addon provides its own unit for
+	Dropped    []string // Addon units no base commander can reach (they extend another faction)
+}
+
+// Merge combines a base faction's index with an addon's. An addon unit replaces a base unit
+// with the same ID. Build relationships, accessibility and tech paths are recomputed over
+// the combined units (see parser.Relink) from the base faction's commanders, and addon units
+// that are still inaccessible are left out, since they belong to another faction the addon
+// extends. Build menus and assist economy are then rebuilt as the exporter does.
+func Merge(base, addon *models.FactionIndex) (*models.FactionIndex, *Result, error) {
+	if layoutOf(base).Name() != layoutOf(addon).Name() {
+		return nil, nil, fmt.Errorf("base faction uses the %s layout but the addon uses %s\n\nExport both with the same --layout", layoutOf(base).Name(), layoutOf(addon).Name())
+	}
+
+	result := &Result{}
+	entries := make(map[string]models.UnitIndexEntry, len(base.Units)+len(addon.Units))
+	fromAddon := make(map[string]bool, len(addon.Units))
+	var commanders []string
+	for _, entry := range base.Units {
+		entries[entry.Identifier] = entry
+		if r := entry.Unit.Reachability; r != nil && r.Method == models.ReachCommander {
+			commanders = append(commanders, entry.Identifier)
+		}
+	}
+	for _, entry := range addon.Units {
+		if _, ok := entries[entry.Identifier]; ok {
+			result.Replaced = append(result.Replaced, entry.Identifier)
+		}
+		entries[entry.Identifier] = entry
+		fromAddon[entry.Identifier] = true
+	}
+
+	relink := func() ([]models.Unit, error) {
+		units := make([]models.Unit, 0, len(entries))
+		for _, entry := range entries {
+			units = append(units, entry.Unit)
+		}
+		return parser.Relink(units, commanders, false)
+	}
+	units, err := relink()
+	if err != nil {
+		return nil, nil, err
+	}
+	// Unreachable addon units would otherwise linger in inaccessible builders' lists
+	for _, unit := range units {
+		if fromAddon[unit.ID] && !unit.Accessible {
+			result.Dropped = append(result.Dropped, unit.ID)
+			delete(entries, unit.ID)
+		}
+	}
+	if len(result.Dropped) > 0 {
+		if units, err = relink(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	byID := make(map[string]*models.Unit, len(units))
+	for i := range units {
+		byID[units[i].ID] = &units[i]
+	}
+	factories := exporter.AssistFactories(units)
+
+	merged := &models.FactionIndex{Layout: base.Layout, Units: make([]models.UnitIndexEntry, 0, len(units))}
+	for _, unit := range units {
+		unit.BuildMenu = exporter.BuildMenu(unit.BuildRelationships.Builds, byID)
+		if unit.Specs.Economy != nil {
+			economy := *unit.Specs.Economy // Copy so the combined units aren't modified
+			economy.Assist = exporter.AssistEconomy(&unit, factories)
+			unit.Specs.Economy = &economy
+		}
+
+		entry := entries[unit.ID]
+		entry.Unit = unit
+		merged.Units = append(merged.Units, entry)
+		if fromAddon[unit.ID] {
+			result.Added = append(result.Added, unit.ID)
+		}
+	}
+	result.Units = len(merged.Units)
+	return merged, result, nil
+}
+
+// Apply merges the addon folder addonDir into the base faction folder baseDir (see Merge) and
+// writes the result to a new faction folder in outputDir. Unit files are copied from the base
+// folder and then the addon's, so the addon's copy wins where both have a file. Credits from
+// both folders are combined.
+func Apply(baseDir, addonDir, outputDir string, opts Options) (*Result, error) {
+	baseMeta, baseIndex, err := readFaction(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	addonMeta, addonIndex, err := readFaction(addonDir)
+	if err != nil {
+		return nil, err
+	}
+	if !addonMeta.IsAddon {
+		return nil, fmt.Errorf("%s is not an addon export (isAddon is not set in metadata.json)\n\nPass an export of an addon profile as the addon", addonDir)
+	}
+	if baseMeta.IsAddon {
+		return nil, fmt.Errorf("%s is an addon export\n\nPass the export of the faction the addon extends as the base", baseDir)
+	}
+
+	index, result, err := Merge(baseIndex, addonIndex)
+	if err != nil {
+		return nil, err
+	}
+	metadata := mergeMetadata(*baseMeta, *addonMeta, opts)
+	if metadata.ContentHash, err = exporter.IndexContentHash(index); err != nil {
+		return nil, err
+	}
+
+	factionDir := filepath.Join(outputDir, exporter.SanitizeFolderName(metadata.DisplayName))
+	if err := os.MkdirAll(factionDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create faction directory: %w", err)
+	}
+	layoutDir := layoutOf(index).Dir()
+	for _, dir := range []string{baseDir, addonDir} {
+		if err := copyTree(filepath.Join(dir, layoutDir), filepath.Join(factionDir, layoutDir)); err != nil {
+			return nil, fmt.Errorf("failed to copy unit files from %s: %w", dir, err)
+		}
+	}
+	if metadata.BackgroundImage != "" {
+		image := filepath.FromSlash(metadata.BackgroundImage)
+		if err := copyFile(filepath.Join(baseDir, image), filepath.Join(factionDir, image)); err != nil {
+			return nil, fmt.Errorf("failed to copy background image: %w", err)
+		}
+	}
+
+	if err := exporter.WriteFactionMetadata(factionDir, metadata); err != nil {
+		return nil, err
+	}
+	if err := exporter.WriteFactionIndex(factionDir, index, opts.JSON); err != nil {
+		return nil, err
+	}
+	var credits []models.Credits
+	for _, dir := range []string{baseDir, addonDir} {
+		c, err := readCredits(dir)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			credits = append(credits, *c)
+		}
+	}
+	if credits != nil {
+		if err := exporter.WriteCredits(factionDir, exporter.MergeCredits(metadata.DisplayName, credits, index, metadata.PABuild)); err != nil {
+			return nil, err
+		}
+	}
+
+	result.FactionDir = factionDir
+	return result, nil
+}
+
+// mergeMetadata describes the merged faction: the base faction's metadata, renamed, with the
+// addon's mods and authors added
+func mergeMetadata(base, addon models.FactionMetadata, opts Options) models.FactionMetadata {
+	merged := base
+	merged.Identifier = base.Identifier + "-" + addon.Identifier
+	merged.DisplayName = opts.DisplayName
+	if merged.DisplayName == "" {
+		merged.DisplayName = base.DisplayName + " + " + addon.DisplayName
+	}
+	if opts.Version != "" {
+		merged.Version = opts.Version
+	}
+	merged.Description = fmt.Sprintf("%s with the %s addon", base.DisplayName, addon.DisplayName)
+	merged.Type = "mod"
+	merged.Mods = appendMissing(append([]string(nil), base.Mods...), addon.Mods...)
+	authors := appendMissing(splitAuthors(base.Author), splitAuthors(addon.Author)...)
+	merged.Author = strings.Join(authors, ", ")
+	merged.IPFSCID = ""
+	merged.ModVersions = append(append([]models.ModVersion(nil), base.ModVersions...), addon.ModVersions...)
+	merged.GitHubCommits = append(append([]models.GitHubCommit(nil), base.GitHubCommits...), addon.GitHubCommits...)
+	if addon.Assets == exporter.AssetsNone || (addon.Assets != "" && merged.Assets == "") {
+		merged.Assets = addon.Assets
+	}
+	return merged
+}
+
+func readFaction(dir string) (*models.FactionMetadata, *models.FactionIndex, error) {
+	metadata, err := exporter.ReadFactionMetadata(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load faction from %s: %w\n\nPass a folder produced by describe-faction", dir, err)
+	}
+	index, err := exporter.ReadFactionIndex(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load faction from %s: %w\n\nPass a folder produced by describe-faction", dir, err)
+	}
+	return metadata, index, nil
+}
+
+// readCredits reads CREDITS.json from a faction folder; folders without one give nil
+func readCredits(dir string) (*models.Credits, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "CREDITS.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credits: %w", err)
+	}
+	var credits models.Credits
+	if err := json.Unmarshal(data, &credits); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(dir, "CREDITS.json"), err)
+	}
+	return &credits, nil
+}
+
+func layoutOf(index *models.FactionIndex) exporter.Layout {
+	if index.Layout == exporter.LayoutFlat {
+		return exporter.FlatLayout{}
+	}
+	return exporter.MirroredLayout{}
+}
+
+// copyTree copies every file under src into dst, overwriting files dst already has. A
+// missing src (an export without unit files) copies nothing.
+func copyTree(src, dst string) error {
+	if _, err := os.Stat(src); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		return copyFile(path, filepath.Join(dst, rel))
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func splitAuthors(author string) []string {
+	var authors []string
+	for _, a := range strings.Split(author, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			authors = append(authors, a)
+		}
+	}
+	return authors
+}
+
+func appendMissing(list []string, values ...string) []string {
+	for _, v := range values {
+		if !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
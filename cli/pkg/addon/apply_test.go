@@ -0,0 +1,169 @@
+package addon
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func entry(id string, types []string, buildable string, builtBy ...string) models.UnitIndexEntry {
+	return models.UnitIndexEntry{
+		Identifier: id,
+		UnitTypes:  types,
+		Files:      []models.UnitFile{{Path: "pa/units/" + id + "/" + id + ".json", Source: "pa"}},
+		Unit: models.Unit{
+			ID:                 id,
+			ResourceName:       "/pa/units/" + id + "/" + id + ".json",
+			DisplayName:        id,
+			Tier:               1,
+			UnitTypes:          types,
+			BuildableTypes:     buildable,
+			BuildRelationships: models.BuildRelationships{BuiltBy: builtBy},
+			Specs:              models.UnitSpecs{Economy: &models.EconomySpecs{BuildCost: 100}},
+		},
+	}
+}
+
+func testIndexes() (base, addon *models.FactionIndex) {
+	commander := entry("commander", []string{"Custom58", "Commander", "Mobile"}, "Custom58 & Structure")
+	commander.Unit.Accessible = true
+	commander.Unit.Reachability = &models.Reachability{Method: models.ReachCommander}
+	base = &models.FactionIndex{Units: []models.UnitIndexEntry{
+		commander,
+		entry("factory", []string{"Custom58", "Structure", "Factory"}, "Custom58 & Mobile - Commander", "commander"),
+		entry("tank", []string{"Custom58", "Mobile", "Tank"}, "", "factory"),
+	}}
+	addon = &models.FactionIndex{Units: []models.UnitIndexEntry{
+		entry("addon_tank", []string{"Custom58", "Mobile", "Tank"}, "", "factory"),
+		entry("addon_wall", []string{"Custom58", "Structure"}, "", "commander"),
+		entry("legion_tank", []string{"Custom1", "Mobile", "Tank"}, "", "l_factory"),
+	}}
+	addon.Units[2].Warnings = []string{"kept warning"}
+	return base, addon
+}
+
+func TestMerge(t *testing.T) {
+	base, addon := testIndexes()
+	merged, result, err := Merge(base, addon)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	byID := make(map[string]models.UnitIndexEntry)
+	for _, e := range merged.Units {
+		byID[e.Identifier] = e
+	}
+	if len(merged.Units) != 5 || result.Units != 5 {
+		t.Fatalf("got %d merged units, want 5: %v", len(merged.Units), result)
+	}
+	if !reflect.DeepEqual(result.Dropped, []string{"legion_tank"}) {
+		t.Errorf("dropped = %v, want [legion_tank]", result.Dropped)
+	}
+	if got := byID["commander"].Unit.BuildRelationships.Builds; !reflect.DeepEqual(got, []string{"factory", "addon_wall"}) && !reflect.DeepEqual(got, []string{"addon_wall", "factory"}) {
+		t.Errorf("commander builds = %v, want factory and addon_wall", got)
+	}
+	tank := byID["addon_tank"].Unit
+	if !tank.Accessible || tank.TechPath == nil || !reflect.DeepEqual(tank.TechPath.Path, []string{"commander", "factory", "addon_tank"}) {
+		t.Errorf("addon_tank not linked into the tech tree: accessible %v techPath %+v", tank.Accessible, tank.TechPath)
+	}
+	if len(byID["factory"].Unit.BuildMenu) == 0 {
+		t.Error("factory build menu not rebuilt")
+	}
+	if issues := exporter.ValidateReferences(merged, false); len(issues) > 0 {
+		t.Errorf("merged index has reference problems: %v", issues)
+	}
+}
+
+func TestMergeReplacesBaseUnit(t *testing.T) {
+	base, addon := testIndexes()
+	replacement := entry("tank", []string{"Custom58", "Mobile", "Tank"}, "")
+	replacement.DisplayName = "Addon Tank"
+	addon.Units = append(addon.Units, replacement)
+
+	merged, result, err := Merge(base, addon)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.Replaced, []string{"tank"}) {
+		t.Errorf("replaced = %v, want [tank]", result.Replaced)
+	}
+	for _, e := range merged.Units {
+		if e.Identifier == "tank" && e.DisplayName != "Addon Tank" {
+			t.Errorf("tank should come from the addon, got %q", e.DisplayName)
+		}
+	}
+}
+
+func TestMergeLayoutMismatch(t *testing.T) {
+	base, addon := testIndexes()
+	addon.Layout = exporter.LayoutFlat
+	if _, _, err := Merge(base, addon); err == nil {
+		t.Error("expected an error for different layouts")
+	}
+}
+
+func TestApply(t *testing.T) {
+	root := t.TempDir()
+	base, addon := testIndexes()
+	write := func(name string, metadata models.FactionMetadata, index *models.FactionIndex, credits models.Credits) string {
+		dir := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Join(dir, "assets", "pa"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := exporter.WriteFactionMetadata(dir, metadata); err != nil {
+			t.Fatal(err)
+		}
+		if err := exporter.WriteFactionIndex(dir, index, exporter.JSONOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := exporter.WriteCredits(dir, credits); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "assets", "pa", name+".txt"), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	baseDir := write("base", models.FactionMetadata{Identifier: "mla", DisplayName: "MLA", Version: "1.0.0", Type: "base-game", Author: "Uber", ContentHash: "stale"},
+		base, models.Credits{Sources: []models.CreditEntry{{Source: "pa", Name: "Planetary Annihilation"}}})
+	addonDir := write("addon", models.FactionMetadata{Identifier: "com.test.addon", DisplayName: "Addon", Version: "2.0", Type: "mod", IsAddon: true, Mods: []string{"com.test.addon"}, Author: "Modder"},
+		addon, models.Credits{Sources: []models.CreditEntry{{Source: "com.test.addon", Name: "Addon", License: "MIT"}}})
+
+	if _, err := Apply(addonDir, baseDir, root, Options{}); err == nil {
+		t.Error("expected an error with base and addon swapped")
+	}
+
+	result, err := Apply(baseDir, addonDir, filepath.Join(root, "out"), Options{})
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if result.FactionDir != filepath.Join(root, "out", exporter.SanitizeFolderName("MLA + Addon")) {
+		t.Errorf("faction dir = %s", result.FactionDir)
+	}
+	metadata, err := exporter.ReadFactionMetadata(result.FactionDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.IsAddon || metadata.Version != "1.0.0" || metadata.Type != "mod" || metadata.Author != "Uber, Modder" || !reflect.DeepEqual(metadata.Mods, []string{"com.test.addon"}) {
+		t.Errorf("unexpected merged metadata: %+v", metadata)
+	}
+	index, err := exporter.ReadFactionIndex(result.FactionDir)
+	if err != nil || len(index.Units) != 5 {
+		t.Fatalf("merged index: %v, %d units", err, len(index.Units))
+	}
+	if hash, _ := exporter.IndexContentHash(index); metadata.ContentHash != hash {
+		t.Errorf("contentHash = %q, want the merged index's %q", metadata.ContentHash, hash)
+	}
+	for _, name := range []string{"base", "addon"} {
+		if _, err := os.Stat(filepath.Join(result.FactionDir, "assets", "pa", name+".txt")); err != nil {
+			t.Errorf("file from %s folder not copied: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(result.FactionDir, "CREDITS.json")); err != nil {
+		t.Errorf("credits not written: %v", err)
+	}
+}
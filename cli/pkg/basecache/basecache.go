@@ -0,0 +1,89 @@
+// Package basecache caches the set of base-game unit IDs used to filter addon
+// exports, keyed by PA build number, so repeat addon extractions skip the second
+// full parse of the base game.
+package basecache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// formatVersion is bumped whenever the cached data's meaning changes
+// (e.g. a parser fix that alters which units the base game yields). 2: spawns made
+// only by a mod's shadow of a base unit are no longer counted as base units.
+const formatVersion = 2
+
+type cacheFile struct {
+	FormatVersion int      `json:"formatVersion"`
+	Build         string   `json:"build"`
+	UnitIDs       []string `json:"unitIds"`
+}
+
+// unsafeChars matches characters not allowed in cache file names
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// DefaultDir returns the per-user cache directory (e.g. ~/.cache/pa-pedia).
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pa-pedia"), nil
+}
+
+func cachePath(dir, build string) string {
+	return filepath.Join(dir, fmt.Sprintf("base-units-%s.json", unsafeChars.ReplaceAllString(build, "_")))
+}
+
+// Load returns the cached base-game unit IDs for build. The bool is false on a miss,
+// including a cache written by an older format or for a different build.
+func Load(dir, build string) (map[string]bool, bool, error) {
+	data, err := os.ReadFile(cachePath(dir, build))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read base unit cache: %w", err)
+	}
+
+	var cached cacheFile
+	if err := json.Unmarshal(data, &cached); err != nil {
+		// A corrupt cache is just a miss; it will be rewritten
+		return nil, false, nil
+	}
+	if cached.FormatVersion != formatVersion || cached.Build != build || len(cached.UnitIDs) == 0 {
+		return nil, false, nil
+	}
+
+	ids := make(map[string]bool, len(cached.UnitIDs))
+	for _, id := range cached.UnitIDs {
+		ids[id] = true
+	}
+	return ids, true, nil
+}
+
+// Save writes the base-game unit IDs for build.
+func Save(dir, build string, unitIDs map[string]bool) error {
+	ids := make([]string, 0, len(unitIDs))
+	for id := range unitIDs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	data, err := json.MarshalIndent(cacheFile{FormatVersion: formatVersion, Build: build, UnitIDs: ids}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal base unit cache: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(cachePath(dir, build), data, 0644); err != nil {
+		return fmt.Errorf("failed to write base unit cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package basecache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSave(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, ok, err := Load(dir, "124664"); ok || err != nil {
+		t.Fatalf("Load() on empty cache = ok %v, err %v; want miss", ok, err)
+	}
+
+	want := map[string]bool{"tank": true, "bot_factory": true}
+	if err := Save(dir, "124664", want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, ok, err := Load(dir, "124664")
+	if err != nil || !ok {
+		t.Fatalf("Load() = ok %v, err %v; want hit", ok, err)
+	}
+	if len(got) != len(want) || !got["tank"] || !got["bot_factory"] {
+		t.Errorf("Load() = %v, want %v", got, want)
+	}
+
+	// A different build is a miss
+	if _, ok, _ := Load(dir, "124700"); ok {
+		t.Error("Load() for another build should miss")
+	}
+
+	// A corrupt file is a miss, not an error
+	if err := os.WriteFile(filepath.Join(dir, "base-units-124700.json"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := Load(dir, "124700"); ok || err != nil {
+		t.Errorf("Load() on corrupt cache = ok %v, err %v; want miss", ok, err)
+	}
+}
+
+func TestCachePathSanitizesBuild(t *testing.T) {
+	got := filepath.Base(cachePath("dir", "../1.2 beta"))
+	if got != "base-units-.._1.2_beta.json" {
+		t.Errorf("cachePath() = %q", got)
+	}
+}
@@ -0,0 +1,372 @@
+// Package bundle packs an exported faction folder into a single .pafaction file and
+// unpacks it again, verifying every file against the checksums in its manifest.
+//
+// A bundle is a zip archive with a canonical layout: manifest.json first, then
+// metadata.json and units.json, then every other file sorted by path, all at the
+// archive root. Entries carry a fixed timestamp so packing the same folder twice
+// produces identical bytes. Because the faction files sit at the root, the web
+// app's zip upload reads a bundle as-is.
+package bundle
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+const (
+	// Extension is the file extension of a faction bundle
+	Extension = ".pafaction"
+	// ManifestName is the manifest entry at the root of a bundle
+	ManifestName = "manifest.json"
+	// FormatVersion is the newest container format this package reads and the one it writes
+	FormatVersion = 1
+)
+
+// ErrIntegrity is wrapped by Unpack errors for bundles whose contents don't match their manifest.
+var ErrIntegrity = errors.New("bundle failed integrity check")
+
+// entryTime is the modification time written on every entry (the zip epoch)
+var entryTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// leadingFiles are written straight after the manifest so readers can stop early
+var leadingFiles = []string{"metadata.json", "units.json"}
+
+// Pack writes the faction folder factionDir to the bundle file dest, replacing it
+// atomically. createdBy is recorded in the manifest (e.g. "pa-pedia v1.4.0").
+// Dotfiles and any manifest.json already in the folder are skipped.
+func Pack(factionDir, dest, createdBy string) (*models.BundleManifest, error) {
+	metadata, err := exporter.ReadFactionMetadata(factionDir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(factionDir, "units.json")); err != nil {
+		return nil, fmt.Errorf("failed to read index file: %w", err)
+	}
+
+	paths, err := collectFiles(factionDir)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &models.BundleManifest{
+		FormatVersion: FormatVersion,
+		Identifier:    metadata.Identifier,
+		DisplayName:   metadata.DisplayName,
+		Version:       metadata.Version,
+		CreatedBy:     createdBy,
+	}
+	for _, rel := range paths {
+		file, err := describeFile(factionDir, rel)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create bundle directory: %w", err)
+	}
+	tmp := dest + ".tmp"
+	if err := writeBundle(tmp, factionDir, manifest); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// collectFiles returns the slash-separated paths under factionDir in canonical order
+func collectFiles(factionDir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(factionDir, func(p string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if p != factionDir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(factionDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel != ManifestName {
+			paths = append(paths, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan faction folder: %w", err)
+	}
+
+	rank := func(rel string) int {
+		for i, name := range leadingFiles {
+			if rel == name {
+				return i
+			}
+		}
+		return len(leadingFiles)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		ri, rj := rank(paths[i]), rank(paths[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return paths[i] < paths[j]
+	})
+	return paths, nil
+}
+
+// describeFile sizes and hashes one file for the manifest
+func describeFile(factionDir, rel string) (models.BundleFile, error) {
+	f, err := os.Open(filepath.Join(factionDir, filepath.FromSlash(rel)))
+	if err != nil {
+		return models.BundleFile{}, fmt.Errorf("failed to read %s: %w", rel, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return models.BundleFile{}, fmt.Errorf("failed to read %s: %w", rel, err)
+	}
+	return models.BundleFile{Path: rel, Size: size, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+func writeBundle(dest, factionDir string, manifest *models.BundleManifest) error {
+	out, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := writeEntry(zw, ManifestName, strings.NewReader(string(manifestData))); err != nil {
+		return err
+	}
+
+	for _, file := range manifest.Files {
+		src, err := os.Open(filepath.Join(factionDir, filepath.FromSlash(file.Path)))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file.Path, err)
+		}
+		err = writeEntry(zw, file.Path, src)
+		src.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	return out.Close()
+}
+
+func writeEntry(zw *zip.Writer, name string, r io.Reader) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: entryTime})
+	if err != nil {
+		return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+	}
+	return nil
+}
+
+// ReadManifest returns the manifest of a bundle without extracting or verifying it.
+func ReadManifest(bundlePath string) (*models.BundleManifest, error) {
+	zr, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer zr.Close()
+
+	return readManifest(&zr.Reader)
+}
+
+func readManifest(zr *zip.Reader) (*models.BundleManifest, error) {
+	for _, f := range zr.File {
+		if f.Name != ManifestName {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest: %w", err)
+		}
+		defer rc.Close()
+
+		var manifest models.BundleManifest
+		if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest: %w", err)
+		}
+		if manifest.FormatVersion < 1 {
+			return nil, fmt.Errorf("manifest has no formatVersion")
+		}
+		if manifest.FormatVersion > FormatVersion {
+			return nil, fmt.Errorf("bundle format version %d is newer than this pa-pedia supports (%d)\n\nUpdate pa-pedia to unpack it", manifest.FormatVersion, FormatVersion)
+		}
+		if exporter.SanitizeFolderName(manifest.DisplayName) == "" {
+			return nil, fmt.Errorf("manifest displayName %q gives no faction folder name", manifest.DisplayName)
+		}
+		return &manifest, nil
+	}
+	return nil, fmt.Errorf("%s not found; not a %s bundle", ManifestName, Extension)
+}
+
+// Unpack verifies a bundle and extracts it into outputDir/<faction folder>, returning the
+// manifest and the folder written. Every file must be listed in the manifest with a
+// matching size and SHA-256; nothing is left in outputDir when verification fails. An
+// existing faction folder is only replaced when overwrite is set.
+func Unpack(bundlePath, outputDir string, overwrite bool) (*models.BundleManifest, string, error) {
+	zr, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer zr.Close()
+
+	manifest, err := readManifest(&zr.Reader)
+	if err != nil {
+		return nil, "", err
+	}
+
+	factionDir := filepath.Join(outputDir, exporter.SanitizeFolderName(manifest.DisplayName))
+	// Overwriting removes factionDir, so it must be a folder of its own inside outputDir
+	if rel, err := filepath.Rel(outputDir, factionDir); err != nil || rel == "." || !filepath.IsLocal(rel) || strings.ContainsRune(rel, filepath.Separator) {
+		return nil, "", fmt.Errorf("refusing to unpack into %s: not a folder directly inside %s", factionDir, outputDir)
+	}
+	if _, err := os.Stat(factionDir); err == nil && !overwrite {
+		return nil, "", fmt.Errorf("%s already exists\n\nRemove it or pass --force to replace it", factionDir)
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	staging, err := os.MkdirTemp(outputDir, ".unpack-")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	if err := extractVerified(&zr.Reader, manifest, staging); err != nil {
+		return nil, "", err
+	}
+
+	if overwrite {
+		if err := os.RemoveAll(factionDir); err != nil {
+			return nil, "", fmt.Errorf("failed to replace %s: %w", factionDir, err)
+		}
+	}
+	if err := os.Rename(staging, factionDir); err != nil {
+		return nil, "", fmt.Errorf("failed to move unpacked faction into place: %w", err)
+	}
+
+	return manifest, factionDir, nil
+}
+
+// extractVerified writes every listed file into dir, checking sizes and hashes
+func extractVerified(zr *zip.Reader, manifest *models.BundleManifest, dir string) error {
+	listed := make(map[string]models.BundleFile, len(manifest.Files))
+	for _, file := range manifest.Files {
+		if !isSafePath(file.Path) {
+			return fmt.Errorf("%w: unsafe path %q in manifest", ErrIntegrity, file.Path)
+		}
+		listed[file.Path] = file
+	}
+
+	found := make(map[string]bool, len(listed))
+	for _, f := range zr.File {
+		if f.Name == ManifestName || strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+		want, ok := listed[f.Name]
+		if !ok {
+			return fmt.Errorf("%w: %s is not listed in the manifest", ErrIntegrity, f.Name)
+		}
+		if found[f.Name] {
+			return fmt.Errorf("%w: %s appears more than once", ErrIntegrity, f.Name)
+		}
+		found[f.Name] = true
+
+		if err := extractFile(f, want, filepath.Join(dir, filepath.FromSlash(f.Name))); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range manifest.Files {
+		if !found[file.Path] {
+			return fmt.Errorf("%w: %s is listed in the manifest but missing", ErrIntegrity, file.Path)
+		}
+	}
+	return nil
+}
+
+func extractFile(f *zip.File, want models.BundleFile, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", f.Name, err)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", f.Name, err)
+	}
+	defer out.Close()
+
+	// Read at most one byte past the declared size so an oversized entry is caught without
+	// writing all of it
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(out, h), io.LimitReader(rc, want.Size+1))
+	if err != nil {
+		return fmt.Errorf("failed to extract %s: %w", f.Name, err)
+	}
+	if size != want.Size {
+		return fmt.Errorf("%w: %s is %d bytes, manifest says %d", ErrIntegrity, f.Name, size, want.Size)
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != want.SHA256 {
+		return fmt.Errorf("%w: %s checksum mismatch", ErrIntegrity, f.Name)
+	}
+	return out.Close()
+}
+
+// isSafePath reports whether a bundle path stays inside the extraction folder
+func isSafePath(p string) bool {
+	if p == "" || strings.Contains(p, "\\") || path.IsAbs(p) {
+		return false
+	}
+	return filepath.IsLocal(filepath.FromSlash(p)) && path.Clean(p) == p
+}
@@ -0,0 +1,237 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// writeFaction creates a minimal exported faction folder and returns its path
+func writeFaction(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "Test Faction")
+	files := map[string]string{
+		"metadata.json":                       `{"identifier":"test","displayName":"Test Faction","version":"1.0.0","type":"mod"}`,
+		"units.json":                          `{"units":[]}`,
+		"assets/pa/units/land/tank/tank.json": `{"max_health":100}`,
+		".DS_Store":                           "ignored",
+	}
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestPackUnpackRoundTrip(t *testing.T) {
+	factionDir := writeFaction(t)
+	bundlePath := filepath.Join(t.TempDir(), "test"+Extension)
+
+	manifest, err := Pack(factionDir, bundlePath, "pa-pedia test")
+	if err != nil {
+		t.Fatalf("Pack() error = %v", err)
+	}
+
+	var paths []string
+	for _, f := range manifest.Files {
+		paths = append(paths, f.Path)
+	}
+	want := "metadata.json,units.json,assets/pa/units/land/tank/tank.json"
+	if got := strings.Join(paths, ","); got != want {
+		t.Errorf("manifest files = %s, want %s", got, want)
+	}
+
+	zr, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		t.Fatalf("failed to open bundle: %v", err)
+	}
+	if zr.File[0].Name != ManifestName {
+		t.Errorf("first entry = %s, want %s", zr.File[0].Name, ManifestName)
+	}
+	zr.Close()
+
+	outputDir := t.TempDir()
+	_, unpacked, err := Unpack(bundlePath, outputDir, false)
+	if err != nil {
+		t.Fatalf("Unpack() error = %v", err)
+	}
+	if unpacked != filepath.Join(outputDir, "Test-Faction") {
+		t.Errorf("unpacked into %s", unpacked)
+	}
+	data, err := os.ReadFile(filepath.Join(unpacked, "assets", "pa", "units", "land", "tank", "tank.json"))
+	if err != nil || string(data) != `{"max_health":100}` {
+		t.Errorf("tank.json = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(unpacked, ".DS_Store")); !os.IsNotExist(err) {
+		t.Error("dotfiles should not be bundled")
+	}
+
+	if _, _, err := Unpack(bundlePath, outputDir, false); err == nil {
+		t.Error("Unpack() over an existing folder should fail without overwrite")
+	}
+	if _, _, err := Unpack(bundlePath, outputDir, true); err != nil {
+		t.Errorf("Unpack() with overwrite error = %v", err)
+	}
+}
+
+func TestPackIsReproducible(t *testing.T) {
+	factionDir := writeFaction(t)
+	out := t.TempDir()
+
+	first, second := filepath.Join(out, "a"+Extension), filepath.Join(out, "b"+Extension)
+	if _, err := Pack(factionDir, first, "pa-pedia test"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Pack(factionDir, second, "pa-pedia test"); err != nil {
+		t.Fatal(err)
+	}
+
+	a, _ := os.ReadFile(first)
+	b, _ := os.ReadFile(second)
+	if !bytes.Equal(a, b) {
+		t.Error("packing the same folder twice should produce identical bundles")
+	}
+}
+
+// writeZip writes a bundle from raw entries, bypassing Pack
+func writeZip(t *testing.T, manifest *models.BundleManifest, entries map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "crafted"+Extension)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	if manifest != nil {
+		data, _ := json.Marshal(manifest)
+		w, _ := zw.Create(ManifestName)
+		w.Write(data)
+	}
+	for name, content := range entries {
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestUnpackRejectsBadBundles(t *testing.T) {
+	// sha256("units")
+	const unitsSum = "c981a30c0e7b420b0ba50ce7d1d4174a511fdf20567b852ab02c774cb9a3591e"
+	good := models.BundleFile{Path: "units.json", Size: 5, SHA256: unitsSum}
+	manifest := func(version int, files ...models.BundleFile) *models.BundleManifest {
+		return &models.BundleManifest{FormatVersion: version, Identifier: "test", DisplayName: "Crafted", Version: "1", Files: files}
+	}
+
+	tests := []struct {
+		name      string
+		manifest  *models.BundleManifest
+		entries   map[string]string
+		integrity bool
+	}{
+		{
+			name:     "missing manifest",
+			manifest: nil,
+			entries:  map[string]string{"units.json": "units"},
+		},
+		{
+			name:     "newer format version",
+			manifest: manifest(FormatVersion+1, good),
+			entries:  map[string]string{"units.json": "units"},
+		},
+		{
+			name:      "checksum mismatch",
+			manifest:  manifest(FormatVersion, good),
+			entries:   map[string]string{"units.json": "UNITS"},
+			integrity: true,
+		},
+		{
+			name:      "size mismatch",
+			manifest:  manifest(FormatVersion, good),
+			entries:   map[string]string{"units.json": "units and more"},
+			integrity: true,
+		},
+		{
+			name:      "unlisted file",
+			manifest:  manifest(FormatVersion),
+			entries:   map[string]string{"extra.json": "{}"},
+			integrity: true,
+		},
+		{
+			name:      "listed file missing",
+			manifest:  manifest(FormatVersion, good),
+			entries:   map[string]string{},
+			integrity: true,
+		},
+		{
+			name:     "display name gives no folder name",
+			manifest: &models.BundleManifest{FormatVersion: FormatVersion, Identifier: "test", DisplayName: "!!!", Version: "1", Files: []models.BundleFile{good}},
+			entries:  map[string]string{"units.json": "units"},
+		},
+		{
+			name:      "path escapes the faction folder",
+			manifest:  manifest(FormatVersion, models.BundleFile{Path: "../evil.json", Size: 2, SHA256: unitsSum}),
+			entries:   map[string]string{"../evil.json": "{}"},
+			integrity: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundlePath := writeZip(t, tt.manifest, tt.entries)
+			outputDir := t.TempDir()
+
+			_, _, err := Unpack(bundlePath, outputDir, false)
+			if err == nil {
+				t.Fatal("Unpack() should fail")
+			}
+			if got := errors.Is(err, ErrIntegrity); got != tt.integrity {
+				t.Errorf("errors.Is(err, ErrIntegrity) = %v, want %v (err: %v)", got, tt.integrity, err)
+			}
+
+			entries, _ := os.ReadDir(outputDir)
+			if len(entries) != 0 {
+				t.Errorf("output directory should be left empty, found %d entries", len(entries))
+			}
+		})
+	}
+}
+
+func TestUnpackOverwriteKeepsOtherFactions(t *testing.T) {
+	const unitsSum = "c981a30c0e7b420b0ba50ce7d1d4174a511fdf20567b852ab02c774cb9a3591e"
+	manifest := &models.BundleManifest{FormatVersion: FormatVersion, Identifier: "test", DisplayName: "", Version: "1",
+		Files: []models.BundleFile{{Path: "units.json", Size: 5, SHA256: unitsSum}}}
+	bundlePath := writeZip(t, manifest, map[string]string{"units.json": "units"})
+
+	outputDir := t.TempDir()
+	other := filepath.Join(outputDir, "MLA", "units.json")
+	if err := os.MkdirAll(filepath.Dir(other), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(other, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := Unpack(bundlePath, outputDir, true); err == nil {
+		t.Error("Unpack() with an empty displayName should fail")
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("another faction in the output directory was removed: %v", err)
+	}
+}
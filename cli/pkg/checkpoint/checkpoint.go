@@ -0,0 +1,137 @@
+// Package checkpoint persists the parsed units of a describe-faction run so an extraction
+// that fails after parsing (e.g. during asset copy) can resume at the export phase.
+package checkpoint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// Dir is the folder, inside the output directory, that holds checkpoints.
+const Dir = ".checkpoints"
+
+// ErrNotFound is returned by Load when no checkpoint exists.
+var ErrNotFound = errors.New("no checkpoint found")
+
+// ErrStale is returned by Load when a checkpoint was written for different inputs.
+var ErrStale = errors.New("checkpoint was written for different inputs")
+
+// Checkpoint is the state saved between the parse and export phases.
+type Checkpoint struct {
+	Key          string                    `json:"key"`
+	CreatedAt    time.Time                 `json:"createdAt"`
+	Units        []models.Unit             `json:"units"`
+	Warnings     map[string][]string       `json:"warnings,omitempty"` // Unit.Warnings isn't serialized on the unit
+	BaseFactions []string                  `json:"baseFactions,omitempty"`
+	CrossFaction []models.CrossFactionEdge `json:"crossFaction,omitempty"`
+}
+
+// Key fingerprints the inputs of an extraction. A checkpoint is only reused when every part
+// (CLI version, profile, paths, PA build, mod versions) matches.
+func Key(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		// Length-prefix each part so ("ab", "c") and ("a", "bc") differ
+		fmt.Fprintf(h, "%d:%s;", len(part), part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Path returns the checkpoint file for a faction folder name within outputDir.
+func Path(outputDir, factionFolder string) string {
+	return filepath.Join(outputDir, Dir, factionFolder+".json")
+}
+
+// New captures units (including their warnings) under key.
+func New(key string, units []models.Unit, baseFactions []string) *Checkpoint {
+	cp := &Checkpoint{
+		Key:          key,
+		CreatedAt:    time.Now().UTC(),
+		Units:        units,
+		BaseFactions: baseFactions,
+	}
+	for _, unit := range units {
+		if len(unit.Warnings) > 0 {
+			if cp.Warnings == nil {
+				cp.Warnings = make(map[string][]string)
+			}
+			cp.Warnings[unit.ID] = unit.Warnings
+		}
+	}
+	return cp
+}
+
+// Save writes the checkpoint atomically (temp file then rename), so a crash mid-write never
+// leaves a truncated checkpoint behind.
+func Save(path string, cp *Checkpoint) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Load reads the checkpoint at path and restores unit warnings. It returns ErrNotFound when
+// there is none and ErrStale when it was written for a different key.
+func Load(path, key string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	if cp.Key != key {
+		return nil, ErrStale
+	}
+
+	for i := range cp.Units {
+		cp.Units[i].Warnings = cp.Warnings[cp.Units[i].ID]
+	}
+	return &cp, nil
+}
+
+// Remove deletes the checkpoint at path, and the checkpoint folder once it is empty.
+func Remove(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	// Only succeeds when no other faction's checkpoint remains
+	os.Remove(filepath.Dir(path))
+	return nil
+}
@@ -0,0 +1,60 @@
+package checkpoint
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestKey(t *testing.T) {
+	if Key("ab", "c") == Key("a", "bc") {
+		t.Error("Key() should distinguish how input is split into parts")
+	}
+	if Key("v1", "mla") != Key("v1", "mla") {
+		t.Error("Key() should be deterministic")
+	}
+}
+
+func TestSaveLoadRemove(t *testing.T) {
+	outputDir := t.TempDir()
+	path := Path(outputDir, "MLA")
+
+	units := []models.Unit{
+		{ID: "tank", DisplayName: "Ant", Tier: 1, Warnings: []string{"weapon x: zero rate of fire"}},
+		{ID: "bot", DisplayName: "Dox", Tier: 1},
+	}
+	if err := Save(path, New("key-1", units, []string{"MLA"})); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	cp, err := Load(path, "key-1")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cp.Units) != 2 || cp.Units[0].DisplayName != "Ant" {
+		t.Errorf("Load() units = %+v", cp.Units)
+	}
+	if len(cp.Units[0].Warnings) != 1 || cp.Units[1].Warnings != nil {
+		t.Errorf("Load() warnings = %v / %v, want restored on tank only", cp.Units[0].Warnings, cp.Units[1].Warnings)
+	}
+	if len(cp.BaseFactions) != 1 || cp.BaseFactions[0] != "MLA" {
+		t.Errorf("Load() baseFactions = %v", cp.BaseFactions)
+	}
+
+	if _, err := Load(path, "key-2"); !errors.Is(err, ErrStale) {
+		t.Errorf("Load() with another key error = %v, want ErrStale", err)
+	}
+
+	if err := Remove(path); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := Load(path, "key-1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load() after Remove error = %v, want ErrNotFound", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, Dir)); !os.IsNotExist(err) {
+		t.Errorf("empty checkpoint folder not removed: %v", err)
+	}
+}
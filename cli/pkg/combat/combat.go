@@ -0,0 +1,104 @@
+// Package combat models one-on-one matchups between exported units: which weapons can
+// reach a unit's layer, how quickly they kill it, and from how far. The counters command
+// ranks these matchups into "what beats X" suggestions.
+package combat
+
+import (
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// Layers a unit can occupy, named like weapon target layers without the WL_ prefix
+const (
+	LayerLand       = "LandHorizontal"
+	LayerWater      = "WaterSurface"
+	LayerUnderwater = "Underwater"
+	LayerAir        = "Air"
+	LayerOrbital    = "Orbital"
+)
+
+// Layer returns the layer a unit fights on, from its unit types. Structures sit on land,
+// water or in orbit; amphibious land units count as land since that is where they usually
+// fight.
+func Layer(u *models.Unit) string {
+	types := make(map[string]bool, len(u.UnitTypes))
+	for _, t := range u.UnitTypes {
+		types[t] = true
+	}
+
+	switch {
+	case types["Orbital"]:
+		return LayerOrbital
+	case types["Structure"]:
+		if types["Naval"] && !types["Land"] {
+			return LayerWater
+		}
+		return LayerLand
+	case types["Air"]:
+		return LayerAir
+	case types["Land"]:
+		return LayerLand
+	case types["Sub"]:
+		return LayerUnderwater
+	case types["Naval"]:
+		return LayerWater
+	default:
+		return LayerLand
+	}
+}
+
+// CanTarget reports whether a weapon can fire at units on layer. Comparison ignores case
+// since specs mix "Seafloor" and "SeaFloor".
+func CanTarget(w *models.Weapon, layer string) bool {
+	for _, target := range w.TargetLayers {
+		target = strings.TrimPrefix(target, "WL_")
+		if strings.EqualFold(target, layer) {
+			return true
+		}
+		if strings.EqualFold(target, "AnyHorizontalGroundOrWaterSurface") && (layer == LayerLand || layer == LayerWater) {
+			return true
+		}
+	}
+	return false
+}
+
+// Active reports whether a weapon fires in normal combat: not a death or self-destruct
+// explosion, not disabled and not waiting on an ability toggle. These match the weapons
+// counted in unit DPS.
+func Active(w *models.Weapon) bool {
+	if w.DeathExplosion || w.SelfDestruct || w.RequiresToggle {
+		return false
+	}
+	return w.Enabled == nil || *w.Enabled
+}
+
+// Engagement is what one attacker can do to one target
+type Engagement struct {
+	DPS   float64 // Combined DPS of the attacker's active weapons that can hit the target's layer
+	Range float64 // Longest range among those weapons
+	// TTK is the seconds one attacker needs to destroy one target (0 when DPS is 0)
+	TTK float64
+}
+
+// Engage computes how attacker fares against target. An attacker without weapons that
+// reach the target's layer gets a zero Engagement.
+func Engage(attacker, target *models.Unit) Engagement {
+	var e Engagement
+	if attacker.Specs.Combat == nil {
+		return e
+	}
+	layer := Layer(target)
+	for i := range attacker.Specs.Combat.Weapons {
+		w := &attacker.Specs.Combat.Weapons[i]
+		if !Active(w) || !CanTarget(w, layer) {
+			continue
+		}
+		e.DPS += w.DPS
+		e.Range = max(e.Range, w.MaxRange)
+	}
+	if e.DPS > 0 && target.Specs.Combat != nil {
+		e.TTK = target.Specs.Combat.Health / e.DPS
+	}
+	return e
+}
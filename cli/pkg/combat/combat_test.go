@@ -0,0 +1,212 @@
+package combat
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// testUnit builds a unit with one weapon per entry of layers, each dealing dps at rng
+func testUnit(id string, types []string, health, cost, speed, dps, rng float64, layers ...[]string) *models.Unit {
+	unit := &models.Unit{ID: id, DisplayName: id, UnitTypes: types, Accessible: true}
+	unit.Specs.Combat = &models.CombatSpecs{Health: health}
+	unit.Specs.Economy = &models.EconomySpecs{BuildCost: cost}
+	if speed > 0 {
+		unit.Specs.Mobility = &models.MobilitySpecs{MoveSpeed: speed}
+	}
+	for _, l := range layers {
+		unit.Specs.Combat.Weapons = append(unit.Specs.Combat.Weapons, models.Weapon{Count: 1, DPS: dps, MaxRange: rng, TargetLayers: l})
+		unit.Specs.Combat.DPS += dps
+	}
+	return unit
+}
+
+var (
+	ground = []string{"LandHorizontal", "WaterSurface", "Seafloor"}
+	air    = []string{"Air"}
+)
+
+func TestLayer(t *testing.T) {
+	tests := []struct {
+		types []string
+		want  string
+	}{
+		{[]string{"Mobile", "Tank", "Land"}, LayerLand},
+		{[]string{"Amphibious", "Land", "Mobile", "Sub"}, LayerLand},
+		{[]string{"Mobile", "Air"}, LayerAir},
+		{[]string{"Air", "Structure", "Factory"}, LayerLand},
+		{[]string{"Mobile", "Naval"}, LayerWater},
+		{[]string{"Mobile", "Naval", "Sub"}, LayerUnderwater},
+		{[]string{"Naval", "Structure"}, LayerWater},
+		{[]string{"Land", "Naval", "Structure"}, LayerLand},
+		{[]string{"Orbital", "Structure"}, LayerOrbital},
+		{[]string{"Mobile", "Orbital"}, LayerOrbital},
+	}
+	for _, tt := range tests {
+		if got := Layer(&models.Unit{UnitTypes: tt.types}); got != tt.want {
+			t.Errorf("Layer(%v) = %s, want %s", tt.types, got, tt.want)
+		}
+	}
+}
+
+func TestCanTarget(t *testing.T) {
+	tests := []struct {
+		layers []string
+		layer  string
+		want   bool
+	}{
+		{[]string{"LandHorizontal", "WaterSurface"}, LayerLand, true},
+		{[]string{"WL_Air"}, LayerAir, true},
+		{[]string{"Air"}, LayerLand, false},
+		{[]string{"waterSurface", "SeaFloor"}, LayerWater, true},
+		{[]string{"AnyHorizontalGroundOrWaterSurface"}, LayerWater, true},
+		{[]string{"AnyHorizontalGroundOrWaterSurface"}, LayerAir, false},
+		{nil, LayerLand, false},
+	}
+	for _, tt := range tests {
+		if got := CanTarget(&models.Weapon{TargetLayers: tt.layers}, tt.layer); got != tt.want {
+			t.Errorf("CanTarget(%v, %s) = %v, want %v", tt.layers, tt.layer, got, tt.want)
+		}
+	}
+}
+
+func TestEngage(t *testing.T) {
+	off := false
+	attacker := testUnit("flak", []string{"Mobile", "Land"}, 100, 100, 10, 20, 80, air, ground)
+	attacker.Specs.Combat.Weapons[1].MaxRange = 40
+	attacker.Specs.Combat.Weapons = append(attacker.Specs.Combat.Weapons,
+		models.Weapon{DPS: 1000, MaxRange: 10, TargetLayers: ground, DeathExplosion: true},
+		models.Weapon{DPS: 1000, MaxRange: 10, TargetLayers: ground, Enabled: &off},
+		models.Weapon{DPS: 1000, MaxRange: 10, TargetLayers: ground, RequiresToggle: true},
+	)
+
+	tank := testUnit("tank", []string{"Mobile", "Land"}, 200, 100, 10, 0, 0)
+	if got, want := Engage(attacker, tank), (Engagement{DPS: 20, Range: 40, TTK: 10}); got != want {
+		t.Errorf("Engage(flak, tank) = %+v, want %+v", got, want)
+	}
+
+	orbital := testUnit("satellite", []string{"Mobile", "Orbital"}, 200, 100, 10, 0, 0)
+	if got := Engage(attacker, orbital); got != (Engagement{}) {
+		t.Errorf("Engage(flak, satellite) = %+v, want zero", got)
+	}
+}
+
+func TestScore(t *testing.T) {
+	config := DefaultCounterConfig()
+	config.Range, config.Speed = 0, 0
+
+	// Same health and DPS at twice the cost: an equal-metal army of b is half the size,
+	// so a's square-law strength is 4x
+	a := testUnit("a", []string{"Mobile", "Land"}, 100, 100, 10, 10, 50, ground)
+	b := testUnit("b", []string{"Mobile", "Land"}, 100, 200, 10, 10, 50, ground)
+	m, ok := Score(a, b, config)
+	if !ok || m.Trade != 4 || m.Score != 2 {
+		t.Errorf("Score(a, b) = %+v, %v, want trade 4 and score 2", m, ok)
+	}
+	if m.TTK != 10 || m.KillTime != 5 {
+		t.Errorf("Score(a, b) TTK = %v, KillTime = %v, want 10 and 5", m.TTK, m.KillTime)
+	}
+	if m, _ := Score(b, a, config); m.Trade != 0.25 || m.Score != -2 {
+		t.Errorf("Score(b, a) = %+v, want trade 0.25 and score -2", m)
+	}
+
+	// A target that can't shoot back gives the capped trade
+	bomber := testUnit("bomber", []string{"Mobile", "Air"}, 100, 100, 20, 10, 0, ground)
+	if m, _ := Score(bomber, b, config); m.Trade != config.MaxTrade || m.Score != math.Log2(config.MaxTrade) {
+		t.Errorf("Score(bomber, b) = %+v, want trade %v", m, config.MaxTrade)
+	}
+
+	// No weapon reaches the air
+	if _, ok := Score(a, bomber, config); ok {
+		t.Error("Score(a, bomber) ok = true, want false")
+	}
+
+	// Range and speed advantages add up to their weights
+	config = DefaultCounterConfig()
+	sniper := testUnit("sniper", []string{"Mobile", "Land"}, 100, 100, 20, 10, 100, ground)
+	if m, _ := Score(sniper, a, config); m.Score != round(config.Range*0.5+config.Speed*0.5) {
+		t.Errorf("Score(sniper, a) = %v, want %v", m.Score, round(config.Range*0.5+config.Speed*0.5))
+	}
+}
+
+func TestCounters(t *testing.T) {
+	tank := testUnit("tank", []string{"Mobile", "Land"}, 100, 100, 10, 10, 50, ground)
+	heavy := testUnit("heavy", []string{"Mobile", "Land"}, 100, 400, 10, 10, 50, ground)
+	bomber := testUnit("bomber", []string{"Mobile", "Air"}, 100, 100, 20, 20, 0, ground)
+	gunship := testUnit("gunship", []string{"Mobile", "Air"}, 100, 100, 20, 10, 0, ground)
+	fighter := testUnit("fighter", []string{"Mobile", "Air"}, 100, 100, 30, 10, 0, air)
+	turret := testUnit("turret", []string{"Structure", "Defense"}, 1000, 100, 0, 10, 100, ground)
+	fabber := testUnit("fabber", []string{"Mobile", "Land"}, 100, 100, 10, 0, 0)
+	commander := testUnit("commander", []string{"Commander", "Mobile", "Land"}, 10000, 100, 10, 1000, 100, ground, air)
+	units := []*models.Unit{tank, heavy, bomber, gunship, fighter, turret, fabber, commander}
+
+	got := Counters(units, units, DefaultCounterConfig())
+
+	if _, ok := got["commander"]; ok {
+		t.Error("commander has counters, want it skipped")
+	}
+	ids := func(entries []Matchup) []string {
+		out := []string{}
+		for _, m := range entries {
+			out = append(out, m.ID)
+		}
+		return out
+	}
+
+	// Bombers and gunships tie on score; the bomber kills faster for its cost
+	if c := ids(got["heavy"].Counters); !reflect.DeepEqual(c, []string{"bomber", "gunship", "tank"}) {
+		t.Errorf("heavy counters = %v", c)
+	}
+	if c := ids(got["bomber"].Counters); !reflect.DeepEqual(c, []string{"fighter"}) {
+		t.Errorf("bomber counters = %v", c)
+	}
+	// The fabber can't fight back but is unarmed, and the turret is a structure
+	if v := ids(got["bomber"].Vulnerable); !reflect.DeepEqual(v, []string{"heavy", "tank"}) {
+		t.Errorf("bomber vulnerable = %v", v)
+	}
+
+	config := DefaultCounterConfig()
+	config.IncludeStructures, config.IncludeUnarmed, config.Limit = true, true, 1
+	got = Counters(units, units, config)
+	if c := ids(got["heavy"].Counters); !reflect.DeepEqual(c, []string{"bomber"}) {
+		t.Errorf("heavy counters with limit 1 = %v", c)
+	}
+	if v := ids(got["tank"].Vulnerable); !reflect.DeepEqual(v, []string{"fabber"}) {
+		t.Errorf("tank vulnerable with unarmed = %v", v)
+	}
+}
+
+func TestLoadCounterConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	config, err := LoadCounterConfig(write("partial.json", `{"range": 2, "limit": 10}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DefaultCounterConfig()
+	want.Range, want.Limit = 2, 10
+	if config != want {
+		t.Errorf("config = %+v, want %+v", config, want)
+	}
+
+	for name, content := range map[string]string{
+		"typo.json":  `{"rnage": 2}`,
+		"cap.json":   `{"maxTrade": 1}`,
+		"limit.json": `{"limit": 0}`,
+	} {
+		if _, err := LoadCounterConfig(write(name, content)); err == nil {
+			t.Errorf("LoadCounterConfig(%s) succeeded, want error", name)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package combat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/stats"
+)
+
+// CounterConfig tunes the counter score. Every field is optional in a config file;
+// missing ones keep their DefaultCounterConfig value.
+//
+// For attacker A against target B (health h, metal cost c, DPS d against the other's
+// layer, r the range of those weapons, s move speed) the score is
+//
+//	Trade * log2(trade) + Range * (rA - rB) / max(rA, rB) + Speed * (sA - sB) / max(sA, sB)
+//
+// where trade = (hA * dA * cB²) / (hB * dB * cA²) is the ratio of the fighting strengths of
+// equal-metal armies of A and B under Lanchester's square law, clamped to
+// [1/MaxTrade, MaxTrade]. A target that cannot hit the attacker at all gives MaxTrade.
+// A positive score means A beats B for its cost; MinScore filters weak suggestions. Equal
+// scores are ranked by KillTime, so among counters a target can't shoot back at the ones
+// that destroy it fastest for their cost come first.
+type CounterConfig struct {
+	Trade             float64 `json:"trade"`             // Weight of the equal-metal trade (log2)
+	Range             float64 `json:"range"`             // Weight of the relative range advantage (-1..1)
+	Speed             float64 `json:"speed"`             // Weight of the relative speed advantage (-1..1)
+	MaxTrade          float64 `json:"maxTrade"`          // Cap on the trade ratio either way
+	MinScore          float64 `json:"minScore"`          // Scores below this are not listed
+	Limit             int     `json:"limit"`             // Entries per list
+	IncludeStructures bool    `json:"includeStructures"` // Suggest defensive structures as counters
+	IncludeUnarmed    bool    `json:"includeUnarmed"`    // List unarmed units (fabbers, economy) as vulnerable
+}
+
+// DefaultCounterConfig returns the weights the counters command uses without --config.
+// A score of 1 is roughly a 2:1 trade for equal metal.
+func DefaultCounterConfig() CounterConfig {
+	return CounterConfig{
+		Trade:    1,
+		Range:    0.5,
+		Speed:    0.25,
+		MaxTrade: 16,
+		MinScore: 1,
+		Limit:    5,
+	}
+}
+
+// LoadCounterConfig reads a JSON config over the defaults. Unknown fields are rejected so
+// typos don't silently fall back to a default.
+func LoadCounterConfig(path string) (CounterConfig, error) {
+	config := DefaultCounterConfig()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&config); err != nil {
+		return config, fmt.Errorf("invalid counter config %s: %w", path, err)
+	}
+	if config.MaxTrade <= 1 {
+		return config, fmt.Errorf("invalid counter config %s: maxTrade must be above 1", path)
+	}
+	if config.Limit < 1 {
+		return config, fmt.Errorf("invalid counter config %s: limit must be at least 1", path)
+	}
+	return config, nil
+}
+
+// Matchup is one scored pairing in a counter list
+type Matchup struct {
+	ID          string  `json:"id"`
+	DisplayName string  `json:"displayName"`
+	Score       float64 `json:"score"`
+	Trade       float64 `json:"trade"`    // Equal-metal trade ratio in favour of the counter
+	TTK         float64 `json:"ttk"`      // Seconds one counter needs to destroy one of the countered unit
+	KillTime    float64 `json:"killTime"` // Seconds an army of the counter needs to destroy an army of equal metal
+}
+
+// UnitCounters are the suggestions for one unit
+type UnitCounters struct {
+	Counters   []Matchup `json:"counters"`   // Units that beat this unit for their cost, best first
+	Vulnerable []Matchup `json:"vulnerable"` // Units this unit beats for its cost, best first
+}
+
+// CountersReport is the counters.json document, keyed by unit ID
+type CountersReport struct {
+	Faction string                  `json:"faction"`
+	Config  CounterConfig           `json:"config"`
+	Units   map[string]UnitCounters `json:"units"`
+}
+
+// Score rates attacker against target under config. The returned Matchup has every field
+// but ID and DisplayName set. ok is false when the attacker cannot damage the target or
+// either unit has no health or cost to compare.
+func Score(attacker, target *models.Unit, config CounterConfig) (m Matchup, ok bool) {
+	ha, ca := stats.Health(attacker), stats.BuildCost(attacker)
+	hb, cb := stats.Health(target), stats.BuildCost(target)
+	if ha <= 0 || ca <= 0 || hb <= 0 || cb <= 0 {
+		return m, false
+	}
+	ab, ba := Engage(attacker, target), Engage(target, attacker)
+	if ab.DPS <= 0 {
+		return m, false
+	}
+
+	trade := config.MaxTrade
+	if ba.DPS > 0 {
+		trade = (ha * ab.DPS * cb * cb) / (hb * ba.DPS * ca * ca)
+		trade = math.Min(math.Max(trade, 1/config.MaxTrade), config.MaxTrade)
+	}
+	score := config.Trade*math.Log2(trade) +
+		config.Range*advantage(ab.Range, ba.Range) +
+		config.Speed*advantage(stats.MoveSpeed(attacker), stats.MoveSpeed(target))
+
+	return Matchup{
+		Score:    round(score),
+		Trade:    round(trade),
+		TTK:      round(ab.TTK),
+		KillTime: round(hb * ca / (cb * ab.DPS)),
+	}, true
+}
+
+// advantage is (a - b) / max(a, b): 1 when only a has it, -1 when only b does
+func advantage(a, b float64) float64 {
+	if a <= 0 && b <= 0 {
+		return 0
+	}
+	return (a - b) / math.Max(a, b)
+}
+
+// Counters computes the counter lists for every unit in units. Candidates are drawn from
+// opponents (pass units again for a single faction); commanders never appear, structures
+// only when config.IncludeStructures is set and unarmed units are only listed as
+// vulnerable with config.IncludeUnarmed. Each list keeps entries scoring at least
+// config.MinScore, best first, up to config.Limit.
+func Counters(units, opponents []*models.Unit, config CounterConfig) map[string]UnitCounters {
+	var candidates []*models.Unit
+	for _, u := range opponents {
+		if stats.IsCommander(u) || (!config.IncludeStructures && isStructure(u)) {
+			continue
+		}
+		candidates = append(candidates, u)
+	}
+
+	result := make(map[string]UnitCounters, len(units))
+	for _, unit := range units {
+		if stats.IsCommander(unit) {
+			continue
+		}
+		var counters, vulnerable []Matchup
+		for _, other := range candidates {
+			if other.ID == unit.ID {
+				continue
+			}
+			if m, ok := Score(other, unit, config); ok && m.Score >= config.MinScore {
+				counters = append(counters, named(m, other))
+			}
+			if !config.IncludeUnarmed && stats.DPS(other) <= 0 {
+				continue
+			}
+			if m, ok := Score(unit, other, config); ok && m.Score >= config.MinScore {
+				vulnerable = append(vulnerable, named(m, other))
+			}
+		}
+		result[unit.ID] = UnitCounters{
+			Counters:   best(counters, config.Limit),
+			Vulnerable: best(vulnerable, config.Limit),
+		}
+	}
+	return result
+}
+
+func named(m Matchup, u *models.Unit) Matchup {
+	m.ID, m.DisplayName = u.ID, u.DisplayName
+	return m
+}
+
+// best sorts by score, then kill time, then ID and keeps the first limit entries
+func best(entries []Matchup, limit int) []Matchup {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		if entries[i].KillTime != entries[j].KillTime {
+			return entries[i].KillTime < entries[j].KillTime
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	if entries == nil {
+		entries = []Matchup{}
+	}
+	return entries
+}
+
+func hasType(u *models.Unit, unitType string) bool {
+	for _, t := range u.UnitTypes {
+		if t == unitType {
+			return true
+		}
+	}
+	return false
+}
+
+func isStructure(u *models.Unit) bool { return hasType(u, "Structure") }
+
+// round rounds to 2 decimal places
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
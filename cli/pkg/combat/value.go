@@ -0,0 +1,158 @@
+package combat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/stats"
+)
+
+// Abilities that add to a unit's combat value
+const (
+	AbilityAntiAir     = "antiAir"     // A weapon can hit air units
+	AbilityAntiOrbital = "antiOrbital" // A weapon can hit orbital units
+	AbilityAntiSub     = "antiSub"     // A weapon can hit underwater units
+	AbilitySplash      = "splash"      // A weapon deals splash damage
+	AbilityAmphibious  = "amphibious"  // Crosses land and water
+	AbilityHover       = "hover"       // Hovers over land and water
+)
+
+// maxAdvantage caps the range and speed ratios so long-range artillery and fast aircraft
+// don't dwarf everything else
+const maxAdvantage = 2.0
+
+// ValueConfig weights the combat value model. Every field is optional in a config file;
+// missing ones keep their DefaultValueConfig value.
+//
+// An armed unit with health h, DPS d, longest active weapon range r and speed s is worth
+//
+//	Scale * sqrt(d * h) * (1 + Range * min(r / RangeReference, 2) + Speed * min(s / SpeedReference, 2) + sum of Abilities)
+//
+// sqrt(d * h) is a unit's contribution to Lanchester square-law fighting strength, so the
+// values of a group can be summed and groups compared by their totals. Unarmed units are
+// worth 0.
+type ValueConfig struct {
+	Scale          float64            `json:"scale"`          // Overall multiplier
+	Range          float64            `json:"range"`          // Bonus per RangeReference of weapon range
+	RangeReference float64            `json:"rangeReference"` // Range that earns the full Range bonus
+	Speed          float64            `json:"speed"`          // Bonus per SpeedReference of move speed
+	SpeedReference float64            `json:"speedReference"` // Speed that earns the full Speed bonus
+	Abilities      map[string]float64 `json:"abilities"`      // Bonus per ability (see the Ability constants)
+}
+
+// DefaultValueConfig returns the model describe-faction uses without --combat-value-config
+func DefaultValueConfig() ValueConfig {
+	return ValueConfig{
+		Scale:          1,
+		Range:          0.25,
+		RangeReference: 100,
+		Speed:          0.25,
+		SpeedReference: 10,
+		Abilities: map[string]float64{
+			AbilityAntiAir:     0.2,
+			AbilityAntiOrbital: 0.2,
+			AbilityAntiSub:     0.2,
+			AbilitySplash:      0.2,
+			AbilityAmphibious:  0.1,
+			AbilityHover:       0.1,
+		},
+	}
+}
+
+// LoadValueConfig reads a JSON config over the defaults. Ability bonuses are merged with the
+// default ones; unknown fields and abilities are rejected.
+func LoadValueConfig(path string) (ValueConfig, error) {
+	config := DefaultValueConfig()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&config); err != nil {
+		return config, fmt.Errorf("invalid combat value config %s: %w", path, err)
+	}
+	if config.RangeReference <= 0 || config.SpeedReference <= 0 {
+		return config, fmt.Errorf("invalid combat value config %s: rangeReference and speedReference must be positive", path)
+	}
+	known := DefaultValueConfig().Abilities
+	for ability := range config.Abilities {
+		if _, ok := known[ability]; !ok {
+			return config, fmt.Errorf("invalid combat value config %s: unknown ability %q", path, ability)
+		}
+	}
+	return config, nil
+}
+
+// Abilities returns the abilities of a unit that the value model rewards, sorted
+func Abilities(u *models.Unit) []string {
+	found := make(map[string]bool)
+	if u.Specs.Combat != nil {
+		for i := range u.Specs.Combat.Weapons {
+			w := &u.Specs.Combat.Weapons[i]
+			if !Active(w) {
+				continue
+			}
+			if CanTarget(w, LayerAir) {
+				found[AbilityAntiAir] = true
+			}
+			if CanTarget(w, LayerOrbital) {
+				found[AbilityAntiOrbital] = true
+			}
+			if CanTarget(w, LayerUnderwater) {
+				found[AbilityAntiSub] = true
+			}
+			if w.SplashRadius > 0 && w.SplashDamage > 0 {
+				found[AbilitySplash] = true
+			}
+		}
+	}
+	if special := u.Specs.Special; special != nil {
+		found[AbilityAmphibious] = special.Amphibious
+		found[AbilityHover] = special.Hover
+	}
+
+	abilities := make([]string, 0, len(found))
+	for ability, ok := range found {
+		if ok {
+			abilities = append(abilities, ability)
+		}
+	}
+	sort.Strings(abilities)
+	return abilities
+}
+
+// Value computes a unit's combat value under config (see ValueConfig)
+func Value(u *models.Unit, config ValueConfig) float64 {
+	dps, health := stats.DPS(u), stats.Health(u)
+	if dps <= 0 || health <= 0 {
+		return 0
+	}
+
+	var weaponRange float64
+	for i := range u.Specs.Combat.Weapons {
+		if w := &u.Specs.Combat.Weapons[i]; Active(w) {
+			weaponRange = max(weaponRange, w.MaxRange)
+		}
+	}
+
+	multiplier := 1 +
+		config.Range*math.Min(weaponRange/config.RangeReference, maxAdvantage) +
+		config.Speed*math.Min(stats.MoveSpeed(u)/config.SpeedReference, maxAdvantage)
+	for _, ability := range Abilities(u) {
+		multiplier += config.Abilities[ability]
+	}
+	return round(config.Scale * math.Sqrt(dps*health) * multiplier)
+}
+
+// AssignValues sets CombatValue on every unit
+func AssignValues(units []models.Unit, config ValueConfig) {
+	for i := range units {
+		units[i].CombatValue = Value(&units[i], config)
+	}
+}
@@ -0,0 +1,105 @@
+package combat
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestAbilities(t *testing.T) {
+	unit := testUnit("frigate", []string{"Mobile", "Naval"}, 100, 100, 10, 10, 100,
+		[]string{"WaterSurface", "Air"}, []string{"Underwater"}, []string{"Orbital"})
+	unit.Specs.Combat.Weapons[0].SplashRadius = 5
+	unit.Specs.Combat.Weapons[0].SplashDamage = 10
+	unit.Specs.Combat.Weapons[2].DeathExplosion = true
+	unit.Specs.Special = &models.SpecialSpecs{Hover: true}
+
+	want := []string{AbilityAntiAir, AbilityAntiSub, AbilityHover, AbilitySplash}
+	if got := Abilities(unit); !reflect.DeepEqual(got, want) {
+		t.Errorf("Abilities() = %v, want %v", got, want)
+	}
+}
+
+func TestValue(t *testing.T) {
+	config := ValueConfig{Scale: 1, Range: 0.5, RangeReference: 100, Speed: 0.25, SpeedReference: 10,
+		Abilities: map[string]float64{AbilityAntiAir: 0.5}}
+
+	tests := []struct {
+		name string
+		unit *models.Unit
+		want float64
+	}{
+		{
+			// sqrt(10 * 90) = 30, range 100 and speed 10 earn their full bonus
+			name: "reference unit",
+			unit: testUnit("tank", []string{"Mobile", "Land"}, 90, 100, 10, 10, 100, ground),
+			want: 30 * 1.75,
+		},
+		{
+			name: "structure with anti-air",
+			unit: testUnit("flak", []string{"Structure"}, 90, 100, 0, 10, 50, air),
+			want: 30 * (1 + 0.25 + 0.5),
+		},
+		{
+			// Range and speed are capped at twice the reference
+			name: "artillery",
+			unit: testUnit("artillery", []string{"Mobile", "Land"}, 90, 100, 100, 10, 1000, ground),
+			want: 30 * (1 + 1 + 0.5),
+		},
+		{
+			name: "unarmed",
+			unit: testUnit("fabber", []string{"Mobile", "Land"}, 90, 100, 10, 0, 0),
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Value(tt.unit, config); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("Value() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Doubling the army doubles its summed value
+	units := []models.Unit{*tests[0].unit, *tests[0].unit, *tests[3].unit}
+	AssignValues(units, config)
+	if units[0].CombatValue+units[1].CombatValue != 2*tests[0].want || units[2].CombatValue != 0 {
+		t.Errorf("AssignValues() = %v, %v, %v", units[0].CombatValue, units[1].CombatValue, units[2].CombatValue)
+	}
+}
+
+func TestLoadValueConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	config, err := LoadValueConfig(write("partial.json", `{"scale": 0.1, "abilities": {"splash": 1}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DefaultValueConfig()
+	want.Scale = 0.1
+	want.Abilities[AbilitySplash] = 1
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("config = %+v, want %+v", config, want)
+	}
+
+	for name, content := range map[string]string{
+		"typo.json":      `{"scael": 2}`,
+		"ability.json":   `{"abilities": {"stealth": 1}}`,
+		"reference.json": `{"rangeReference": 0}`,
+	} {
+		if _, err := LoadValueConfig(write(name, content)); err == nil {
+			t.Errorf("LoadValueConfig(%s) succeeded, want error", name)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+// Package compat checks that a faction folder written by any version of the CLI still loads
+// with this version's models: every field it has must parse, and none may be lost when the
+// files are read and written back. Consumers built against newer models can then tell
+// whether an older export is safe to use before they load it.
+package compat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/schema"
+)
+
+// Kind classifies an Issue
+type Kind string
+
+const (
+	// Unparseable fields have a value the models can't read, e.g. a string where a number
+	// belongs; loading the file fails or the value is lost
+	Unparseable Kind = "unparseable"
+	// Dropped fields aren't in the models and are silently discarded when the file loads
+	Dropped Kind = "dropped"
+	// Changed fields load, but read back as a different value (e.g. a number that doesn't
+	// fit its field's type)
+	Changed Kind = "changed"
+	// Missing fields are required by the models but absent, so they load as zero values
+	Missing Kind = "missing"
+)
+
+// File is a faction folder file Check reads, with the schema entry it follows
+type File struct {
+	Name     string
+	Schema   string // Name of a schema.Entries entry
+	Required bool   // Every export has it; the others are checked when present
+}
+
+// Files are the faction folder files Check reads. Unit files under the layout directory are
+// copies of the game's own JSON, not written from the models, so they aren't checked.
+var Files = []File{
+	{"metadata.json", "faction-metadata", true},
+	{"units.json", "faction-index", true},
+	{"CREDITS.json", "faction-credits", false},
+	{"conflicts.json", "faction-conflicts", false},
+	{"id-aliases.json", "id-aliases", false},
+	{"cross-faction.json", "cross-faction-links", false},
+	{"counters.json", "counters-report", false},
+	{"missiles.json", "missile-report", false},
+}
+
+// Issue is one field of a faction folder file that won't load as it was written
+type Issue struct {
+	File    string `json:"file"`
+	Path    string `json:"path"`           // e.g. "units[3].unit.specs.combat"; empty for the whole file
+	Line    int    `json:"line,omitempty"` // 0 when the position isn't known
+	Kind    Kind   `json:"kind"`
+	Message string `json:"message"`
+}
+
+// String reads like "units.json: units[3].unit.foo is not a known field at line 120 (dropped)"
+func (i Issue) String() string {
+	subject := i.Path
+	if subject == "" {
+		subject = "document"
+	}
+	s := fmt.Sprintf("%s: %s %s", i.File, subject, i.Message)
+	if i.Line > 0 {
+		s += fmt.Sprintf(" at line %d", i.Line)
+	}
+	return s + " (" + string(i.Kind) + ")"
+}
+
+// Report is the result of checking a faction folder
+type Report struct {
+	Checked []string `json:"checked"` // File names that were present and checked
+	Issues  []Issue  `json:"issues"`
+}
+
+// Check reads every file in Files from factionDir and reports the fields this version's
+// models can't parse or would drop. A missing required file is an error; problems with the
+// files' contents are Issues.
+func Check(factionDir string) (*Report, error) {
+	report := &Report{Checked: []string{}, Issues: []Issue{}}
+	for _, file := range Files {
+		data, err := os.ReadFile(filepath.Join(factionDir, file.Name))
+		if errors.Is(err, fs.ErrNotExist) && !file.Required {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w\n\nPass a folder produced by describe-faction", file.Name, err)
+		}
+		entry, ok := entryNamed(file.Schema)
+		if !ok {
+			return nil, fmt.Errorf("no schema named %s for %s", file.Schema, file.Name)
+		}
+		report.Checked = append(report.Checked, file.Name)
+		report.Issues = append(report.Issues, CheckFile(file.Name, data, entry)...)
+	}
+	return report, nil
+}
+
+// CheckFile checks one file's contents against a schema entry. The schema catches fields the
+// model doesn't have and values of the wrong type, with their line numbers; reading the data
+// into the model and writing it back then catches anything else the model loses. Fields the
+// writer leaves out when empty don't count as lost.
+func CheckFile(name string, data []byte, entry schema.Entry) []Issue {
+	var before any
+	decodeErr := decode(data, &before)
+	nulls := make(map[string]bool)
+	if decodeErr == nil {
+		collectNulls("", before, nulls)
+	}
+
+	var issues []Issue
+	for _, v := range schema.Validate(schema.Reflect(entry), data) {
+		if nulls[v.Path] && strings.HasPrefix(v.Message, "must be ") {
+			continue // Older writers emit null for empty lists and objects; it loads as empty
+		}
+		issues = append(issues, Issue{File: name, Path: v.Path, Line: v.Line, Kind: kindOf(v), Message: v.Message})
+	}
+
+	value := reflect.New(reflect.TypeOf(entry.Type).Elem()).Interface()
+	if err := json.Unmarshal(data, value); err != nil {
+		if !slices.ContainsFunc(issues, func(i Issue) bool { return i.Kind == Unparseable }) {
+			issues = append(issues, Issue{File: name, Line: lineOf(data, err), Kind: Unparseable, Message: fmt.Sprintf("does not load (%v)", err)})
+		}
+		return issues
+	}
+	written, err := json.Marshal(value)
+	if err != nil {
+		return append(issues, Issue{File: name, Kind: Unparseable, Message: fmt.Sprintf("does not write back (%v)", err)})
+	}
+
+	var after any
+	if decodeErr != nil || decode(written, &after) != nil {
+		return issues
+	}
+	if object, ok := before.(map[string]any); ok {
+		delete(object, "$schema") // Editors' hint, accepted by the schema but not a model field
+	}
+	reported := make([]string, len(issues))
+	for i, issue := range issues {
+		reported[i] = issue.Path
+	}
+	for _, issue := range diff("", before, after) {
+		if !covered(issue.Path, reported) {
+			issue.File = name
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// kindOf classifies a schema violation
+func kindOf(v schema.Violation) Kind {
+	switch v.Message {
+	case "is not a known field":
+		return Dropped
+	case "is required":
+		return Missing
+	}
+	return Unparseable
+}
+
+// diff compares a document as written with the same document after a load and save,
+// returning the fields that were lost or came back different
+func diff(path string, before, after any) []Issue {
+	if isZero(before) && isZero(after) {
+		return nil
+	}
+	switch before := before.(type) {
+	case map[string]any:
+		afterObject, _ := after.(map[string]any)
+		keys := make([]string, 0, len(before))
+		for key := range before {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var diffs []Issue
+		for _, key := range keys {
+			memberPath := joinPath(path, key)
+			value, ok := afterObject[key]
+			if !ok {
+				if !isZero(before[key]) {
+					diffs = append(diffs, Issue{Path: memberPath, Kind: Dropped, Message: "is lost when the file loads"})
+				}
+				continue
+			}
+			diffs = append(diffs, diff(memberPath, before[key], value)...)
+		}
+		return diffs
+	case []any:
+		afterArray, ok := after.([]any)
+		if !ok || len(afterArray) != len(before) {
+			return []Issue{{Path: path, Kind: Changed, Message: fmt.Sprintf("loads as %s instead of %s", summarize(after), summarize(before))}}
+		}
+		var diffs []Issue
+		for i := range before {
+			diffs = append(diffs, diff(fmt.Sprintf("%s[%d]", path, i), before[i], afterArray[i])...)
+		}
+		return diffs
+	}
+	if !sameValue(before, after) {
+		return []Issue{{Path: path, Kind: Changed, Message: fmt.Sprintf("loads as %s instead of %s", summarize(after), summarize(before))}}
+	}
+	return nil
+}
+
+// collectNulls records the path of every null in a document
+func collectNulls(path string, v any, nulls map[string]bool) {
+	switch v := v.(type) {
+	case nil:
+		nulls[path] = true
+	case map[string]any:
+		for key, member := range v {
+			collectNulls(joinPath(path, key), member, nulls)
+		}
+	case []any:
+		for i, item := range v {
+			collectNulls(fmt.Sprintf("%s[%d]", path, i), item, nulls)
+		}
+	}
+}
+
+// sameValue compares scalars, numbers by value so 1.0 and 1 are equal
+func sameValue(a, b any) bool {
+	if an, ok := a.(json.Number); ok {
+		bn, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		af, aerr := an.Float64()
+		bf, berr := bn.Float64()
+		return aerr == nil && berr == nil && af == bf
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// isZero reports whether a value is one omitempty leaves out
+func isZero(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case json.Number:
+		f, err := v.Float64()
+		return err == nil && f == 0
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	}
+	return false
+}
+
+func summarize(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case []any:
+		return fmt.Sprintf("%d item(s)", len(v))
+	case map[string]any:
+		return "an object"
+	case string:
+		return fmt.Sprintf("%q", v)
+	}
+	return fmt.Sprint(v)
+}
+
+// covered reports whether path is, or is inside, one of the already reported paths
+func covered(path string, reported []string) bool {
+	for _, r := range reported {
+		if r == "" || path == r || strings.HasPrefix(path, r+".") || strings.HasPrefix(path, r+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func decode(data []byte, v *any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// lineOf finds the line a decoding error points at, or 0 when it doesn't say
+func lineOf(data []byte, err error) int {
+	var offset int64
+	var syntax *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntax):
+		offset = syntax.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return 0
+	}
+	offset = min(offset, int64(len(data)))
+	return 1 + bytes.Count(data[:offset], []byte("\n"))
+}
+
+func entryNamed(name string) (schema.Entry, bool) {
+	for _, entry := range schema.Entries {
+		if entry.Name == name {
+			return entry, true
+		}
+	}
+	return schema.Entry{}, false
+}
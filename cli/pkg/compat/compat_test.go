@@ -0,0 +1,186 @@
+package compat
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/schema"
+)
+
+func TestCheckFile(t *testing.T) {
+	metadata, _ := entryNamed("faction-metadata")
+	index, _ := entryNamed("faction-index")
+
+	tests := []struct {
+		name  string
+		entry schema.Entry
+		data  string
+		want  []string
+	}{
+		{
+			name:  "current metadata",
+			entry: metadata,
+			data: `{
+  "identifier": "mla",
+  "displayName": "MLA",
+  "version": "1.0.0",
+  "author": "Uber",
+  "description": "",
+  "dateCreated": "2024-01-01",
+  "build": "",
+  "type": "base-game",
+  "mods": [],
+  "x-tool": {"name": "viewer"}
+}`,
+		},
+		{
+			name:  "field from another version",
+			entry: metadata,
+			data: `{
+  "identifier": "mla",
+  "displayName": "MLA",
+  "version": "1.0.0",
+  "author": "Uber",
+  "description": "",
+  "dateCreated": "2024-01-01",
+  "build": "",
+  "type": "base-game",
+  "mods": [],
+  "factionColor": "#ff0000"
+}`,
+			want: []string{"metadata.json: factionColor is not a known field at line 11 (dropped)"},
+		},
+		{
+			name:  "wrong type",
+			entry: metadata,
+			data: `{
+  "identifier": "mla",
+  "displayName": "MLA",
+  "version": "1.0.0",
+  "author": "Uber",
+  "description": "",
+  "dateCreated": "2024-01-01",
+  "build": "",
+  "type": "base-game",
+  "mods": "com.pa.mla"
+}`,
+			want: []string{"metadata.json: mods must be an array of strings at line 10 (unparseable)"},
+		},
+		{
+			name:  "missing required field",
+			entry: metadata,
+			data: `{
+  "identifier": "mla",
+  "displayName": "MLA",
+  "author": "Uber",
+  "type": "base-game",
+  "mods": []
+}`,
+			want: []string{"metadata.json: version is required at line 1 (missing)"},
+		},
+		{
+			name:  "unknown field nested in a unit",
+			entry: index,
+			data: `{"units": [{"identifier": "tank", "displayName": "Tank", "unitTypes": [], "source": "pa", "files": [],
+  "unit": {"id": "tank", "resourceName": "/pa/units/land/tank/tank.json", "displayName": "Tank", "tier": 1,
+    "unitTypes": [], "accessible": true, "specs": {"combat": {"health": 100}}, "legacyArmor": 5}}]}`,
+			want: []string{"units.json: units[0].unit.legacyArmor is not a known field at line 3 (dropped)"},
+		},
+		{
+			name:  "invalid JSON",
+			entry: metadata,
+			data:  `{"identifier": "mla",`,
+			want:  []string{"metadata.json: document is not valid JSON (unexpected end of JSON input) at line 1 (unparseable)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := "metadata.json"
+			if tt.entry.Name == "faction-index" {
+				name = "units.json"
+			}
+			var got []string
+			for _, issue := range CheckFile(name, []byte(tt.data), tt.entry) {
+				got = append(got, issue.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := map[string]any{
+		"kept":    json.Number("1.0"),
+		"empty":   "",
+		"lost":    "value",
+		"rounded": json.Number("2.5"),
+		"list":    []any{json.Number("1"), json.Number("2")},
+	}
+	after := map[string]any{
+		"kept":    json.Number("1"),
+		"rounded": json.Number("2"),
+		"list":    []any{json.Number("1")},
+	}
+
+	var got []string
+	for _, issue := range diff("", before, after) {
+		got = append(got, issue.Path+" "+issue.Message)
+	}
+	want := []string{
+		"list loads as 1 item(s) instead of 2 item(s)",
+		"lost is lost when the file loads",
+		"rounded loads as 2 instead of 2.5",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diff() = %q, want %q", got, want)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	metadata := models.FactionMetadata{Identifier: "mla", DisplayName: "MLA", Version: "1.0.0", Type: "base-game", Mods: []string{}}
+	if err := exporter.WriteFactionMetadata(dir, metadata); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Check(dir); err == nil {
+		t.Error("Check() without units.json: expected an error")
+	}
+
+	index := &models.FactionIndex{Units: []models.UnitIndexEntry{{
+		Identifier: "tank",
+		Unit:       models.Unit{ID: "tank", DisplayName: "Tank", Tier: 1, Accessible: true},
+	}}}
+	if err := exporter.WriteFactionIndex(dir, index, exporter.JSONOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	report, err := Check(dir)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if want := []string{"metadata.json", "units.json"}; !reflect.DeepEqual(report.Checked, want) {
+		t.Errorf("Checked = %v, want %v", report.Checked, want)
+	}
+	if len(report.Issues) != 0 {
+		t.Errorf("fresh export has issues: %v", report.Issues)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "CREDITS.json"), []byte(`{"faction": "MLA", "sources": 3}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	report, err = Check(dir)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(report.Issues) == 0 || report.Issues[0].File != "CREDITS.json" {
+		t.Errorf("Check() issues = %v, want a CREDITS.json issue", report.Issues)
+	}
+}
@@ -0,0 +1,311 @@
+package coverage
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/combat"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// Layers are the target layers coverage is computed for, in report order
+var Layers = []string{combat.LayerLand, combat.LayerWater, combat.LayerUnderwater, combat.LayerAir, combat.LayerOrbital}
+
+// Placement is one structure of a layout
+type Placement struct {
+	Unit string  `json:"unit"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+}
+
+// ParsePlacement parses "unit@x,y", e.g. "laser_defense@120,-40"
+func ParsePlacement(spec string) (Placement, error) {
+	id, position, ok := strings.Cut(strings.TrimSpace(spec), "@")
+	if !ok || id == "" {
+		return Placement{}, fmt.Errorf("invalid placement %q (expected unit@x,y, e.g. laser_defense@120,-40)", spec)
+	}
+	xs, ys, ok := strings.Cut(position, ",")
+	x, xerr := strconv.ParseFloat(strings.TrimSpace(xs), 64)
+	y, yerr := strconv.ParseFloat(strings.TrimSpace(ys), 64)
+	if !ok || xerr != nil || yerr != nil {
+		return Placement{}, fmt.Errorf("invalid position in placement %q (expected two numbers, e.g. laser_defense@120,-40)", spec)
+	}
+	return Placement{Unit: id, X: x, Y: y}, nil
+}
+
+// Options tunes the coverage grid
+type Options struct {
+	// Resolution is the grid cell size in game units. Zero picks one from the longest range
+	// (1/50th of it, at least 1).
+	Resolution float64
+	// Segments is the number of edges each range circle's polygon has (default 64)
+	Segments int
+}
+
+// RangeCircle is the reach of one active weapon of one placement
+type RangeCircle struct {
+	Circle
+	Placement int      `json:"placement"` // Index into Analysis.Placements
+	Unit      string   `json:"unit"`
+	Weapon    string   `json:"weapon"`
+	DPS       float64  `json:"dps"`
+	Layers    []string `json:"layers"` // Entries of Layers the weapon can hit
+}
+
+// Region is an area every point of which is covered by the same set of weapons
+type Region struct {
+	Placements []int   `json:"placements"` // Placements with a weapon covering the area
+	Circles    []int   `json:"circles"`    // Indexes into Analysis.Circles
+	DPS        float64 `json:"dps"`        // Combined DPS of those weapons
+	Area       float64 `json:"area"`
+	Rects      []Rect  `json:"rects"` // Grid rectangles making up the area
+}
+
+// LayerCoverage summarizes coverage against targets on one layer
+type LayerCoverage struct {
+	Layer       string   `json:"layer"`
+	Area        float64  `json:"area"`        // Covered by at least one placement
+	OverlapArea float64  `json:"overlapArea"` // Covered by two or more placements
+	PeakDPS     float64  `json:"peakDps"`     // Highest combined DPS anywhere
+	Regions     []Region `json:"regions"`
+}
+
+// Analysis is the coverage of a layout
+type Analysis struct {
+	Placements []Placement     `json:"placements"`
+	Circles    []RangeCircle   `json:"circles"`
+	Layers     []LayerCoverage `json:"layers"` // Only layers some weapon can hit
+	Resolution float64         `json:"resolution"`
+	Segments   int             `json:"-"`
+}
+
+// Circles returns the range circles of a layout: one per active weapon with a range (see
+// combat.Active), in placement then weapon order. Every placement's unit must be in units,
+// keyed by ID.
+func Circles(placements []Placement, units map[string]*models.Unit) ([]RangeCircle, error) {
+	var circles []RangeCircle
+	for i, p := range placements {
+		unit, ok := units[p.Unit]
+		if !ok {
+			return nil, fmt.Errorf("unit '%s' not found in faction", p.Unit)
+		}
+		if unit.Specs.Combat == nil {
+			continue
+		}
+		for j := range unit.Specs.Combat.Weapons {
+			w := &unit.Specs.Combat.Weapons[j]
+			if !combat.Active(w) || w.MaxRange <= 0 {
+				continue
+			}
+			var layers []string
+			for _, layer := range Layers {
+				if combat.CanTarget(w, layer) {
+					layers = append(layers, layer)
+				}
+			}
+			if len(layers) == 0 {
+				continue
+			}
+			name := w.Name
+			if name == "" {
+				name = w.SafeName
+			}
+			circles = append(circles, RangeCircle{
+				Circle:    Circle{Center: Point{p.X, p.Y}, Radius: w.MaxRange},
+				Placement: i,
+				Unit:      p.Unit,
+				Weapon:    name,
+				DPS:       w.DPS,
+				Layers:    layers,
+			})
+		}
+	}
+	return circles, nil
+}
+
+// Analyze computes the coverage of a layout. The plane around it is sampled on a grid: each
+// cell counts as covered by a weapon when its centre is in range, and cells covered by the
+// same weapons are gathered into a Region, so areas are accurate to about a cell's width
+// along each range's edge.
+func Analyze(placements []Placement, units map[string]*models.Unit, opts Options) (*Analysis, error) {
+	if len(placements) == 0 {
+		return nil, fmt.Errorf("no placements given (expected e.g. laser_defense@0,0)")
+	}
+	circles, err := Circles(placements, units)
+	if err != nil {
+		return nil, err
+	}
+	analysis := &Analysis{Placements: placements, Circles: circles, Layers: []LayerCoverage{}, Segments: opts.Segments}
+	if analysis.Segments <= 0 {
+		analysis.Segments = 64
+	}
+	if len(circles) == 0 {
+		return analysis, nil
+	}
+
+	bounds := circles[0].Bounds()
+	longest := 0.0
+	for _, c := range circles {
+		bounds = bounds.Union(c.Bounds())
+		longest = max(longest, c.Radius)
+	}
+	resolution := opts.Resolution
+	if resolution <= 0 {
+		resolution = max(1, longest/50)
+	}
+	analysis.Resolution = resolution
+
+	for _, layer := range Layers {
+		var onLayer []int
+		for i, c := range circles {
+			if slices.Contains(c.Layers, layer) {
+				onLayer = append(onLayer, i)
+			}
+		}
+		if len(onLayer) > 0 {
+			analysis.Layers = append(analysis.Layers, coverLayer(layer, circles, onLayer, bounds, resolution))
+		}
+	}
+	return analysis, nil
+}
+
+// coverLayer samples the grid for the circles in onLayer. Each row's cells are merged into
+// runs with the same covering circles, and a run continues the rectangle above it when the
+// previous row had an identical one.
+func coverLayer(layer string, circles []RangeCircle, onLayer []int, bounds Rect, resolution float64) LayerCoverage {
+	columns := int(math.Ceil((bounds[2] - bounds[0]) / resolution))
+	rows := int(math.Ceil((bounds[3] - bounds[1]) / resolution))
+	cellArea := resolution * resolution
+
+	regions := make(map[string]*Region)
+	var order []string
+	open := make(map[[3]string]int) // (key, x0, x1) → index of the region's rect ending on the previous row
+	coverage := LayerCoverage{Layer: layer}
+
+	for row := range rows {
+		y0 := bounds[1] + float64(row)*resolution
+		y1 := y0 + resolution
+		next := make(map[[3]string]int)
+		key, start := "", 0
+		var covering []int
+		flush := func(end int) {
+			if key == "" {
+				return
+			}
+			region, ok := regions[key]
+			if !ok {
+				region = newRegion(circles, covering)
+				regions[key] = region
+				order = append(order, key)
+			}
+			x0 := round(bounds[0] + float64(start)*resolution)
+			x1 := round(bounds[0] + float64(end)*resolution)
+			span := [3]string{key, fmt.Sprint(x0), fmt.Sprint(x1)}
+			if i, ok := open[span]; ok {
+				region.Rects[i][3] = round(y1)
+				next[span] = i
+			} else {
+				region.Rects = append(region.Rects, Rect{x0, round(y0), x1, round(y1)})
+				next[span] = len(region.Rects) - 1
+			}
+			cells := float64(end - start)
+			region.Area += cells * cellArea
+			coverage.Area += cells * cellArea
+			if len(region.Placements) > 1 {
+				coverage.OverlapArea += cells * cellArea
+			}
+		}
+		for column := range columns + 1 {
+			var here []int
+			if column < columns {
+				center := Point{bounds[0] + (float64(column)+0.5)*resolution, (y0 + y1) / 2}
+				for _, i := range onLayer {
+					if circles[i].Contains(center) {
+						here = append(here, i)
+					}
+				}
+			}
+			if k := circleKey(here); k != key {
+				flush(column)
+				key, start, covering = k, column, here
+			}
+		}
+		open = next
+	}
+
+	coverage.Regions = make([]Region, 0, len(order))
+	for _, key := range order {
+		region := regions[key]
+		region.Area = round(region.Area)
+		coverage.PeakDPS = max(coverage.PeakDPS, region.DPS)
+		coverage.Regions = append(coverage.Regions, *region)
+	}
+	// Most heavily defended first
+	sort.SliceStable(coverage.Regions, func(i, j int) bool { return coverage.Regions[i].DPS > coverage.Regions[j].DPS })
+	coverage.Area = round(coverage.Area)
+	coverage.OverlapArea = round(coverage.OverlapArea)
+	return coverage
+}
+
+func newRegion(circles []RangeCircle, covering []int) *Region {
+	region := &Region{Circles: covering}
+	for _, i := range covering {
+		region.DPS += circles[i].DPS
+		if !slices.Contains(region.Placements, circles[i].Placement) {
+			region.Placements = append(region.Placements, circles[i].Placement)
+		}
+	}
+	region.DPS = round(region.DPS)
+	return region
+}
+
+// circleKey identifies a set of covering circles; empty for none
+func circleKey(covering []int) string {
+	if len(covering) == 0 {
+		return ""
+	}
+	parts := make([]string, len(covering))
+	for i, c := range covering {
+		parts[i] = strconv.Itoa(c)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Overlap is the area two placements' longest ranges on a layer share
+type Overlap struct {
+	Layer string  `json:"layer"`
+	A     int     `json:"a"`
+	B     int     `json:"b"`
+	Area  float64 `json:"area"`
+}
+
+// Overlaps returns every pair of placements whose reach on the same layer overlaps, computed
+// exactly from each placement's longest range there
+func (a *Analysis) Overlaps() []Overlap {
+	var overlaps []Overlap
+	for _, layer := range a.Layers {
+		reach := make(map[int]Circle)
+		for _, c := range a.Circles {
+			if slices.Contains(c.Layers, layer.Layer) && c.Radius > reach[c.Placement].Radius {
+				reach[c.Placement] = c.Circle
+			}
+		}
+		for i := range a.Placements {
+			for j := i + 1; j < len(a.Placements); j++ {
+				ci, iok := reach[i]
+				cj, jok := reach[j]
+				if !iok || !jok {
+					continue
+				}
+				if area := OverlapArea(ci, cj); area > 0 {
+					overlaps = append(overlaps, Overlap{Layer: layer.Layer, A: i, B: j, Area: round(area)})
+				}
+			}
+		}
+	}
+	return overlaps
+}
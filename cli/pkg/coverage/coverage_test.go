@@ -0,0 +1,143 @@
+package coverage
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestOverlapArea(t *testing.T) {
+	unit := Circle{Radius: 1}
+	tests := []struct {
+		name string
+		a, b Circle
+		want float64
+	}{
+		{"apart", unit, Circle{Center: Point{3, 0}, Radius: 1}, 0},
+		{"touching", unit, Circle{Center: Point{2, 0}, Radius: 1}, 0},
+		{"same circle", unit, unit, math.Pi},
+		{"contained", Circle{Radius: 5}, Circle{Center: Point{1, 1}, Radius: 1}, math.Pi},
+		// Two unit circles one radius apart share 2π/3 - √3/2
+		{"lens", unit, Circle{Center: Point{1, 0}, Radius: 1}, 2*math.Pi/3 - math.Sqrt(3)/2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OverlapArea(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("OverlapArea() = %v, want %v", got, tt.want)
+			}
+			if got := OverlapArea(tt.b, tt.a); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("OverlapArea() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePlacement(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Placement
+		wantErr bool
+	}{
+		{spec: "laser_defense@120,-40", want: Placement{"laser_defense", 120, -40}},
+		{spec: " flak@0.5, 2 ", want: Placement{"flak", 0.5, 2}},
+		{spec: "laser_defense", wantErr: true},
+		{spec: "@1,2", wantErr: true},
+		{spec: "laser_defense@1", wantErr: true},
+		{spec: "laser_defense@x,2", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePlacement(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePlacement(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParsePlacement(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func turret(id string, weapons ...models.Weapon) *models.Unit {
+	return &models.Unit{ID: id, Specs: models.UnitSpecs{Combat: &models.CombatSpecs{Weapons: weapons}}}
+}
+
+func TestAnalyze(t *testing.T) {
+	units := map[string]*models.Unit{
+		"laser": turret("laser", models.Weapon{SafeName: "beam", MaxRange: 100, DPS: 50, TargetLayers: []string{"WL_LandHorizontal", "WL_WaterSurface"}}),
+		"flak": turret("flak",
+			models.Weapon{SafeName: "flak", MaxRange: 80, DPS: 30, TargetLayers: []string{"WL_Air"}},
+			models.Weapon{SafeName: "death", MaxRange: 20, DPS: 1000, DeathExplosion: true, TargetLayers: []string{"WL_Air"}},
+		),
+		"wall": {ID: "wall"},
+	}
+	placements := []Placement{{"laser", 0, 0}, {"laser", 100, 0}, {"flak", 0, 0}, {"wall", 50, 50}}
+
+	analysis, err := Analyze(placements, units, Options{Resolution: 1})
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if len(analysis.Circles) != 3 {
+		t.Fatalf("got %d circles, want 3 (death explosions and unarmed units have none)", len(analysis.Circles))
+	}
+
+	layers := make(map[string]LayerCoverage)
+	for _, l := range analysis.Layers {
+		layers[l.Layer] = l
+	}
+	if len(layers) != 3 {
+		t.Errorf("covered layers = %v, want land, water and air", analysis.Layers)
+	}
+
+	land := layers["LandHorizontal"]
+	laser := Circle{Radius: 100}
+	union := 2*laser.Area() - OverlapArea(laser, Circle{Center: Point{100, 0}, Radius: 100})
+	if math.Abs(land.Area-union)/union > 0.01 {
+		t.Errorf("land area = %v, want about %v", land.Area, union)
+	}
+	lens := OverlapArea(laser, Circle{Center: Point{100, 0}, Radius: 100})
+	if math.Abs(land.OverlapArea-lens)/lens > 0.02 {
+		t.Errorf("land overlap = %v, want about %v", land.OverlapArea, lens)
+	}
+	if land.PeakDPS != 100 {
+		t.Errorf("land peak DPS = %v, want 100", land.PeakDPS)
+	}
+	if len(land.Regions) != 3 || land.Regions[0].DPS != 100 || len(land.Regions[0].Placements) != 2 {
+		t.Errorf("land regions = %+v, want the shared lens first and one region per laser", land.Regions)
+	}
+
+	air := layers["Air"]
+	if air.PeakDPS != 30 || air.OverlapArea != 0 {
+		t.Errorf("air = peak %v overlap %v, want 30 and 0", air.PeakDPS, air.OverlapArea)
+	}
+
+	overlaps := analysis.Overlaps()
+	if len(overlaps) != 2 {
+		t.Errorf("Overlaps() = %+v, want the two lasers on land and water", overlaps)
+	}
+
+	fc := analysis.GeoJSON()
+	kinds := make(map[string]int)
+	for _, f := range fc.Features {
+		kinds[f.Properties["kind"].(string)]++
+	}
+	if kinds["placement"] != 4 || kinds["range"] != 3 || kinds["coverage"] != 7 {
+		t.Errorf("feature kinds = %v, want 4 placements, 3 ranges and 7 coverage regions", kinds)
+	}
+}
+
+func TestAnalyzeUnknownUnit(t *testing.T) {
+	if _, err := Analyze([]Placement{{"missing", 0, 0}}, map[string]*models.Unit{}, Options{}); err == nil {
+		t.Error("Analyze() with an unknown unit: expected an error")
+	}
+}
+
+func TestRingIsClosed(t *testing.T) {
+	ring := Circle{Center: Point{10, 10}, Radius: 5}.Ring(8)
+	if len(ring) != 9 || ring[0] != ring[8] {
+		t.Errorf("Ring(8) = %v, want 9 positions ending where it starts", ring)
+	}
+	if ring[0] != [2]float64{15, 10} || ring[2] != [2]float64{10, 15} {
+		t.Errorf("Ring(8) = %v, want counter-clockwise from the positive x axis", ring)
+	}
+}
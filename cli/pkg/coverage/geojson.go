@@ -0,0 +1,72 @@
+package coverage
+
+// FeatureCollection is a GeoJSON feature collection. Coordinates are layout-plane game units
+// rather than longitude and latitude, so plot it with an identity projection.
+type FeatureCollection struct {
+	Type     string    `json:"type"` // Always "FeatureCollection"
+	Features []Feature `json:"features"`
+}
+
+// Feature is a GeoJSON feature; Properties["kind"] says which part of the analysis it is:
+// "placement" (a Point), "range" (a weapon's range circle as a Polygon) or "coverage" (a
+// Region as a MultiPolygon of grid rectangles)
+type Feature struct {
+	Type       string         `json:"type"` // Always "Feature"
+	Geometry   Geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// Geometry is a GeoJSON Point, Polygon or MultiPolygon
+type Geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// GeoJSON returns the analysis as features: each placement, each range circle, then each
+// layer's coverage regions
+func (a *Analysis) GeoJSON() FeatureCollection {
+	fc := FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+	for i, p := range a.Placements {
+		fc.Features = append(fc.Features, Feature{
+			Type:       "Feature",
+			Geometry:   Geometry{Type: "Point", Coordinates: [2]float64{p.X, p.Y}},
+			Properties: map[string]any{"kind": "placement", "placement": i, "unit": p.Unit},
+		})
+	}
+	for _, c := range a.Circles {
+		fc.Features = append(fc.Features, Feature{
+			Type:     "Feature",
+			Geometry: Geometry{Type: "Polygon", Coordinates: [][][2]float64{c.Ring(a.Segments)}},
+			Properties: map[string]any{
+				"kind":      "range",
+				"placement": c.Placement,
+				"unit":      c.Unit,
+				"weapon":    c.Weapon,
+				"range":     c.Radius,
+				"dps":       c.DPS,
+				"layers":    c.Layers,
+			},
+		})
+	}
+	for _, layer := range a.Layers {
+		for _, region := range layer.Regions {
+			polygons := make([][][][2]float64, len(region.Rects))
+			for i, r := range region.Rects {
+				polygons[i] = [][][2]float64{r.Ring()}
+			}
+			fc.Features = append(fc.Features, Feature{
+				Type:     "Feature",
+				Geometry: Geometry{Type: "MultiPolygon", Coordinates: polygons},
+				Properties: map[string]any{
+					"kind":       "coverage",
+					"layer":      layer.Layer,
+					"placements": region.Placements,
+					"count":      len(region.Placements),
+					"dps":        region.DPS,
+					"area":       region.Area,
+				},
+			})
+		}
+	}
+	return fc
+}
@@ -0,0 +1,87 @@
+// Package coverage works out where placed defensive structures can fire: each weapon's
+// range circle, the areas several of them overlap, and the combined DPS over each area,
+// exported as GeoJSON-style features for plotting. Positions are on a flat plane in game
+// units (metres); over the few hundred metres of a base layout a planet's curvature is
+// negligible.
+package coverage
+
+import "math"
+
+// Point is a position on the layout plane
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Distance returns the straight-line distance between two points
+func (p Point) Distance(q Point) float64 {
+	return math.Hypot(p.X-q.X, p.Y-q.Y)
+}
+
+// Circle is a weapon's reach around the structure carrying it
+type Circle struct {
+	Center Point   `json:"center"`
+	Radius float64 `json:"radius"`
+}
+
+// Contains reports whether p is inside the circle or on its edge
+func (c Circle) Contains(p Point) bool {
+	return c.Center.Distance(p) <= c.Radius
+}
+
+// Area returns the circle's area
+func (c Circle) Area() float64 {
+	return math.Pi * c.Radius * c.Radius
+}
+
+// Bounds returns the circle's bounding box
+func (c Circle) Bounds() Rect {
+	return Rect{c.Center.X - c.Radius, c.Center.Y - c.Radius, c.Center.X + c.Radius, c.Center.Y + c.Radius}
+}
+
+// Ring approximates the circle's edge with a closed ring of segments+1 [x, y] positions,
+// counter-clockwise and starting and ending on the positive x axis, as GeoJSON polygons
+// expect
+func (c Circle) Ring(segments int) [][2]float64 {
+	ring := make([][2]float64, segments+1)
+	for i := range segments {
+		angle := 2 * math.Pi * float64(i) / float64(segments)
+		ring[i] = [2]float64{round(c.Center.X + c.Radius*math.Cos(angle)), round(c.Center.Y + c.Radius*math.Sin(angle))}
+	}
+	ring[segments] = ring[0]
+	return ring
+}
+
+// OverlapArea returns the area two circles have in common: zero when they're apart, the
+// smaller circle's area when one contains the other, and the lens between them otherwise
+func OverlapArea(a, b Circle) float64 {
+	d := a.Center.Distance(b.Center)
+	switch {
+	case d >= a.Radius+b.Radius:
+		return 0
+	case d <= math.Abs(a.Radius-b.Radius):
+		return min(a.Area(), b.Area())
+	}
+	r1, r2 := a.Radius, b.Radius
+	alpha := math.Acos((d*d + r1*r1 - r2*r2) / (2 * d * r1))
+	beta := math.Acos((d*d + r2*r2 - r1*r1) / (2 * d * r2))
+	return r1*r1*(alpha-math.Sin(2*alpha)/2) + r2*r2*(beta-math.Sin(2*beta)/2)
+}
+
+// Rect is an axis-aligned rectangle: [minX, minY, maxX, maxY]
+type Rect [4]float64
+
+// Union returns the smallest rectangle containing both
+func (r Rect) Union(o Rect) Rect {
+	return Rect{min(r[0], o[0]), min(r[1], o[1]), max(r[2], o[2]), max(r[3], o[3])}
+}
+
+// Ring returns the rectangle as a closed counter-clockwise ring
+func (r Rect) Ring() [][2]float64 {
+	return [][2]float64{{r[0], r[1]}, {r[2], r[1]}, {r[2], r[3]}, {r[0], r[3]}, {r[0], r[1]}}
+}
+
+// round keeps coordinates and areas to 2 decimal places so output stays readable
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
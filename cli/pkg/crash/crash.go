@@ -0,0 +1,247 @@
+// Package crash writes the diagnostics bundle pa-pedia leaves behind when it panics (or
+// fails with --crash-report): a pa-pedia-crash-<timestamp>.zip holding the error and stack,
+// environment details, recent output and the active faction profile, with the user's home
+// folder and secrets redacted so it can be attached to a public issue.
+package crash
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IssuesURL is where users are asked to attach bundles
+const IssuesURL = "https://github.com/jamiemulcahy/pa-pedia/issues/new"
+
+// Report describes one crash or failed command
+type Report struct {
+	Time        time.Time   `json:"time"`
+	Version     string      `json:"version"`
+	Commit      string      `json:"commit"`
+	Built       string      `json:"built"`
+	Args        []string    `json:"args"` // Command line without the program name
+	Error       string      `json:"error,omitempty"`
+	Panic       string      `json:"panic,omitempty"`
+	Stack       string      `json:"stack,omitempty"`
+	Environment Environment `json:"environment"`
+}
+
+// Environment is the machine and settings a report was made on
+type Environment struct {
+	OS          string            `json:"os"`
+	Arch        string            `json:"arch"`
+	GoVersion   string            `json:"goVersion"`
+	CPUs        int               `json:"cpus"`
+	Locale      string            `json:"locale"`
+	Interactive bool              `json:"interactive"`
+	PABuild     string            `json:"paBuild,omitempty"`   // Detected from --pa-root, when given
+	Variables   map[string]string `json:"variables,omitempty"` // PA_PEDIA_* settings (see applyEnvFlags)
+}
+
+// NewEnvironment describes the running process. PA_PEDIA_* variables are included with
+// secret-looking values masked.
+func NewEnvironment() Environment {
+	env := Environment{
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		GoVersion: runtime.Version(),
+		CPUs:      runtime.NumCPU(),
+	}
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, "PA_PEDIA_") {
+			continue
+		}
+		if env.Variables == nil {
+			env.Variables = make(map[string]string)
+		}
+		if secretName.MatchString(name) {
+			value = masked
+		}
+		env.Variables[name] = value
+	}
+	return env
+}
+
+// Bundle is everything written to a crash zip
+type Bundle struct {
+	Report  Report
+	Log     []string // Recent output, oldest first
+	Profile any      // The faction profile being extracted, or nil
+}
+
+// Write redacts b and writes it to dir as pa-pedia-crash-<timestamp>.zip, returning the
+// file's path. The zip holds report.json, diagnostics.txt (the same report for reading),
+// log.txt and, with a profile, profile.json.
+func Write(dir string, b Bundle, r Redactor) (string, error) {
+	b.Report.Args = RedactArgs(b.Report.Args)
+	report, err := json.MarshalIndent(b.Report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	files := []struct {
+		name string
+		data string
+	}{
+		{"report.json", string(report)},
+		{"diagnostics.txt", Diagnostics(b.Report)},
+		{"log.txt", strings.Join(b.Log, "\n") + "\n"},
+	}
+	if b.Profile != nil {
+		profile, err := json.MarshalIndent(b.Profile, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		files = append(files, struct {
+			name string
+			data string
+		}{"profile.json", string(profile)})
+	}
+
+	path := filepath.Join(dir, "pa-pedia-crash-"+b.Report.Time.Format("20060102-150405")+".zip")
+	out, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create crash report: %w", err)
+	}
+	zw := zip.NewWriter(out)
+	for _, f := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: b.Report.Time})
+		if err == nil {
+			_, err = w.Write([]byte(r.Redact(f.data)))
+		}
+		if err != nil {
+			zw.Close()
+			out.Close()
+			return "", fmt.Errorf("failed to write crash report: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		return "", fmt.Errorf("failed to write crash report: %w", err)
+	}
+	return path, out.Close()
+}
+
+// Diagnostics formats a report for reading
+func Diagnostics(r Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "pa-pedia %s (commit %s, built %s)\n", r.Version, r.Commit, r.Built)
+	fmt.Fprintf(&b, "Time:        %s\n", r.Time.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Command:     pa-pedia %s\n", strings.Join(r.Args, " "))
+	e := r.Environment
+	fmt.Fprintf(&b, "Platform:    %s/%s, %s, %d CPUs\n", e.OS, e.Arch, e.GoVersion, e.CPUs)
+	fmt.Fprintf(&b, "Locale:      %s\n", e.Locale)
+	fmt.Fprintf(&b, "Interactive: %t\n", e.Interactive)
+	if e.PABuild != "" {
+		fmt.Fprintf(&b, "PA build:    %s\n", e.PABuild)
+	}
+	names := make([]string, 0, len(e.Variables))
+	for name := range e.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "Env:         %s=%s\n", name, e.Variables[name])
+	}
+	if r.Error != "" {
+		fmt.Fprintf(&b, "\nError:\n%s\n", r.Error)
+	}
+	if r.Panic != "" {
+		fmt.Fprintf(&b, "\nPanic: %s\n\n%s", r.Panic, r.Stack)
+	}
+	return b.String()
+}
+
+// masked replaces secret values
+const masked = "<redacted>"
+
+// secretName matches flag and variable names whose values are credentials
+var secretName = regexp.MustCompile(`(?i)(token|secret|password|key|webhook|credential)`)
+
+// RedactArgs masks the values of secret-looking flags, such as --webhook, in args
+func RedactArgs(args []string) []string {
+	out := make([]string, len(args))
+	copy(out, args)
+	for i := 0; i < len(out); i++ {
+		name, _, hasValue := strings.Cut(out[i], "=")
+		if !strings.HasPrefix(name, "--") || !secretName.MatchString(name) {
+			continue
+		}
+		if hasValue {
+			out[i] = name + "=" + masked
+		} else if i+1 < len(out) && !strings.HasPrefix(out[i+1], "-") {
+			i++
+			out[i] = masked
+		}
+	}
+	return out
+}
+
+// Redactor replaces the user's home folder with ~ and the user name in other user profile
+// paths (C:\Users\<name>, /home/<name>, /Users/<name>) with <user>
+type Redactor struct {
+	home string
+}
+
+// NewRedactor redacts the current user's home folder
+func NewRedactor() Redactor {
+	home, _ := os.UserHomeDir()
+	return Redactor{home: home}
+}
+
+var userDir = regexp.MustCompile(`(?i)([\\/]+(?:Users|home)[\\/]+)[^\\/"\s]+`)
+
+// Redact applies the redactions to text, including JSON-escaped (doubled backslash) paths
+func (r Redactor) Redact(text string) string {
+	if len(r.home) > 1 {
+		for _, home := range []string{r.home, strings.ReplaceAll(r.home, `\`, `\\`), strings.ReplaceAll(r.home, `\`, "/")} {
+			text = replaceFold(text, home, "~")
+		}
+	}
+	return userDir.ReplaceAllString(text, "${1}<user>")
+}
+
+// replaceFold replaces old in s case-insensitively, as Windows paths compare
+func replaceFold(s, old, repl string) string {
+	re := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(old))
+	return re.ReplaceAllLiteralString(s, repl)
+}
+
+// Log keeps the most recent lines of output for a crash bundle
+type Log struct {
+	mu    sync.Mutex
+	max   int
+	lines []string
+}
+
+// NewLog keeps up to max lines
+func NewLog(max int) *Log {
+	return &Log{max: max}
+}
+
+// Add records text, split into lines
+func (l *Log) Add(text string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		l.lines = append(l.lines, line)
+	}
+	if excess := len(l.lines) - l.max; excess > 0 {
+		l.lines = append(l.lines[:0:0], l.lines[excess:]...)
+	}
+}
+
+// Lines returns the recorded lines, oldest first
+func (l *Log) Lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.lines...)
+}
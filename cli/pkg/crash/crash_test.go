@@ -0,0 +1,116 @@
+package crash
+
+import (
+	"archive/zip"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedact(t *testing.T) {
+	r := Redactor{home: `C:\Users\Jamie`}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`--pa-root C:\Users\Jamie\PA\media`, `--pa-root ~\PA\media`},
+		{`"paRoot": "c:\\users\\jamie\\PA"`, `"paRoot": "~\\PA"`},
+		{`C:/Users/Jamie/PA`, `~/PA`},
+		{`D:\Users\someone\mods and /home/alex/pa`, `D:\Users\<user>\mods and /home/<user>/pa`},
+		{`/opt/pa/media`, `/opt/pa/media`},
+	}
+	for _, tt := range tests {
+		if got := r.Redact(tt.in); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	args := []string{"notify", "--webhook", "https://discord.com/api/webhooks/1/abc", "--ipfs-api=http://x", "--api-key=s3cret", "--profile", "mla"}
+	want := []string{"notify", "--webhook", masked, "--ipfs-api=http://x", "--api-key=" + masked, "--profile", "mla"}
+	if got := RedactArgs(args); !reflect.DeepEqual(got, want) {
+		t.Errorf("RedactArgs() = %q, want %q", got, want)
+	}
+	if args[2] == masked {
+		t.Error("RedactArgs() modified its argument")
+	}
+}
+
+func TestLog(t *testing.T) {
+	l := NewLog(3)
+	l.Add("one\n")
+	l.Add("two\nthree\nfour\n")
+	if got, want := l.Lines(), []string{"two", "three", "four"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	bundle := Bundle{
+		Report: Report{
+			Time:    time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC),
+			Version: "1.2.3",
+			Args:    []string{"describe-faction", "--pa-root", "/home/sam/pa/media"},
+			Panic:   "index out of range",
+			Stack:   "goroutine 1 [running]:\n",
+		},
+		Log:     []string{"✓ Loaded 3 units"},
+		Profile: map[string]any{"displayName": "MLA", "path": "/home/sam/profiles/mla.json"},
+	}
+	path, err := Write(dir, bundle, Redactor{home: "/home/sam"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(path, "pa-pedia-crash-20260301-123000.zip") {
+		t.Errorf("path = %s", path)
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+	for _, name := range []string{"report.json", "diagnostics.txt", "log.txt", "profile.json"} {
+		content, ok := files[name]
+		if !ok {
+			t.Errorf("bundle is missing %s", name)
+			continue
+		}
+		if strings.Contains(content, "/home/sam") {
+			t.Errorf("%s still contains the home folder:\n%s", name, content)
+		}
+	}
+	for name, want := range map[string]string{
+		"diagnostics.txt": "Command:     pa-pedia describe-faction --pa-root ~/pa/media",
+		"profile.json":    `"path": "~/profiles/mla.json"`,
+		"log.txt":         "✓ Loaded 3 units",
+		"report.json":     `"panic": "index out of range"`,
+	} {
+		if !strings.Contains(files[name], want) {
+			t.Errorf("%s doesn't contain %q:\n%s", name, want, files[name])
+		}
+	}
+}
+
+func TestNewEnvironment(t *testing.T) {
+	t.Setenv("PA_PEDIA_PA_ROOT", "/opt/pa")
+	t.Setenv("PA_PEDIA_NOTIFY_WEBHOOK", "https://discord.com/api/webhooks/1/abc")
+	env := NewEnvironment()
+	if env.Variables["PA_PEDIA_PA_ROOT"] != "/opt/pa" || env.Variables["PA_PEDIA_NOTIFY_WEBHOOK"] != masked {
+		t.Errorf("Variables = %v", env.Variables)
+	}
+	if env.OS == "" || env.CPUs == 0 {
+		t.Errorf("Environment = %+v", env)
+	}
+}
@@ -0,0 +1,69 @@
+// Package demo exposes a small pre-extracted MLA faction folder for trying the
+// tooling without a PA install. The data is only embedded in binaries built with
+// -tags demo (just cli-build-demo); otherwise Available reports false.
+package demo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// FactionDir is the folder inside Data holding the demo faction.
+const FactionDir = "MLA"
+
+// Data is the embedded demo data (FactionDir/metadata.json, units.json and assets).
+// Nil unless the binary was built with -tags demo.
+var Data fs.FS
+
+// Available reports whether demo data is embedded in this binary.
+func Available() bool {
+	return Data != nil
+}
+
+// Faction is the demo faction loaded from Data.
+type Faction struct {
+	Metadata models.FactionMetadata
+	Units    map[string]*models.Unit
+	Order    []string // Unit IDs in index order
+}
+
+// Load reads the demo faction's metadata and unit index from Data.
+func Load() (*Faction, error) {
+	if !Available() {
+		return nil, fmt.Errorf("this binary was built without demo data\n\nBuild with: just cli-build-demo (or go build -tags demo)")
+	}
+
+	var faction Faction
+	if err := readJSON(path.Join(FactionDir, "metadata.json"), &faction.Metadata); err != nil {
+		return nil, err
+	}
+
+	var index models.FactionIndex
+	if err := readJSON(path.Join(FactionDir, "units.json"), &index); err != nil {
+		return nil, err
+	}
+
+	faction.Units = make(map[string]*models.Unit, len(index.Units))
+	for i := range index.Units {
+		unit := &index.Units[i].Unit
+		faction.Units[unit.ID] = unit
+		faction.Order = append(faction.Order, unit.ID)
+	}
+
+	return &faction, nil
+}
+
+func readJSON(name string, v interface{}) error {
+	data, err := fs.ReadFile(Data, name)
+	if err != nil {
+		return fmt.Errorf("failed to read demo %s: %w", name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse demo %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package demo
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	saved := Data
+	defer func() { Data = saved }()
+
+	Data = nil
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() without demo data should fail")
+	}
+
+	Data = fstest.MapFS{
+		"MLA/metadata.json": {Data: []byte(`{"identifier":"mla","displayName":"MLA","version":"1","type":"base-game"}`)},
+		"MLA/units.json": {Data: []byte(`{"units":[
+			{"identifier":"tank","unit":{"id":"tank","displayName":"Ant"}},
+			{"identifier":"bot","unit":{"id":"bot","displayName":"Dox"}}
+		]}`)},
+	}
+
+	faction, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if faction.Metadata.DisplayName != "MLA" {
+		t.Errorf("Metadata.DisplayName = %q, want MLA", faction.Metadata.DisplayName)
+	}
+	if len(faction.Order) != 2 || faction.Order[0] != "tank" || faction.Order[1] != "bot" {
+		t.Errorf("Order = %v, want [tank bot]", faction.Order)
+	}
+	if faction.Units["bot"].DisplayName != "Dox" {
+		t.Errorf("Units[bot].DisplayName = %q, want Dox", faction.Units["bot"].DisplayName)
+	}
+}
@@ -0,0 +1,19 @@
+//go:build demo
+
+package demo
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:data
+var embedded embed.FS
+
+func init() {
+	sub, err := fs.Sub(embedded, "data")
+	if err != nil {
+		panic(err)
+	}
+	Data = sub
+}
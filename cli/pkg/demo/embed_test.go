@@ -0,0 +1,25 @@
+//go:build demo
+
+package demo
+
+import "testing"
+
+func TestEmbeddedData(t *testing.T) {
+	faction, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(faction.Order) == 0 {
+		t.Fatal("embedded demo faction has no units")
+	}
+
+	// Every build relationship should resolve within the subset
+	for _, id := range faction.Order {
+		rel := faction.Units[id].BuildRelationships
+		for _, other := range append(rel.Builds, rel.BuiltBy...) {
+			if _, ok := faction.Units[other]; !ok {
+				t.Errorf("%s references %s, which is not in the demo data", id, other)
+			}
+		}
+	}
+}
@@ -0,0 +1,214 @@
+// Package diff compares two exports of a faction for the diff-factions command: the units
+// one has and the other doesn't, and for units in both, the headline stats and every
+// exported field that changed.
+package diff
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/stats"
+	"github.com/jamiemulcahy/pa-pedia/pkg/table"
+)
+
+// Faction identifies one side of a comparison
+type Faction struct {
+	Dir     string `json:"dir"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PABuild string `json:"paBuild,omitempty"`
+	Units   int    `json:"units"`
+}
+
+// UnitRef names a unit in a report
+type UnitRef struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+}
+
+// Change is one value that differs between the two exports. Before and After are numbers
+// when the field is numeric, strings otherwise, and null where an export lacks the field.
+type Change struct {
+	Field   string   `json:"field"`
+	Before  any      `json:"before"`
+	After   any      `json:"after"`
+	Delta   *float64 `json:"delta,omitempty"`   // After - Before, when both are numbers
+	Percent *float64 `json:"percent,omitempty"` // Delta as a percentage of Before, when Before isn't 0
+}
+
+// UnitDiff lists what changed in a unit both exports have
+type UnitDiff struct {
+	UnitRef
+	Stats  []Change `json:"stats"`  // stats.Metrics (dps, health, cost, speed) that changed
+	Fields []Change `json:"fields"` // Every changed field as flattened by table.Flatten, in field order
+}
+
+// Report is a structured comparison of two faction exports
+type Report struct {
+	Before    Faction    `json:"before"`
+	After     Faction    `json:"after"`
+	Added     []UnitRef  `json:"added"`   // Only in After
+	Removed   []UnitRef  `json:"removed"` // Only in Before
+	Changed   []UnitDiff `json:"changed"`
+	Unchanged int        `json:"unchanged"`
+}
+
+// Options narrows a comparison
+type Options struct {
+	// Fields keeps only flattened fields starting with one of these prefixes (e.g.
+	// "specs.combat"); empty keeps every field. Stats are always compared.
+	Fields []string
+}
+
+// Compare matches units by ID and reports the differences between before and after. Lists
+// are sorted by unit ID.
+func Compare(before, after []*models.Unit, opts Options) (*Report, error) {
+	report := &Report{Added: []UnitRef{}, Removed: []UnitRef{}, Changed: []UnitDiff{}}
+	old := make(map[string]*models.Unit, len(before))
+	for _, u := range before {
+		old[u.ID] = u
+	}
+	seen := make(map[string]bool, len(after))
+	for _, u := range after {
+		seen[u.ID] = true
+		previous, ok := old[u.ID]
+		if !ok {
+			report.Added = append(report.Added, UnitRef{u.ID, u.DisplayName})
+			continue
+		}
+		unitDiff, err := compareUnit(previous, u, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(unitDiff.Stats) == 0 && len(unitDiff.Fields) == 0 {
+			report.Unchanged++
+			continue
+		}
+		report.Changed = append(report.Changed, unitDiff)
+	}
+	for _, u := range before {
+		if !seen[u.ID] {
+			report.Removed = append(report.Removed, UnitRef{u.ID, u.DisplayName})
+		}
+	}
+
+	sort.Slice(report.Added, func(i, j int) bool { return report.Added[i].ID < report.Added[j].ID })
+	sort.Slice(report.Removed, func(i, j int) bool { return report.Removed[i].ID < report.Removed[j].ID })
+	sort.Slice(report.Changed, func(i, j int) bool { return report.Changed[i].ID < report.Changed[j].ID })
+	return report, nil
+}
+
+func compareUnit(before, after *models.Unit, opts Options) (UnitDiff, error) {
+	unitDiff := UnitDiff{UnitRef: UnitRef{after.ID, after.DisplayName}, Stats: []Change{}, Fields: []Change{}}
+	for _, metric := range stats.Metrics {
+		b, a := metric.Value(before), metric.Value(after)
+		if math.Abs(a-b) > 1e-9 {
+			unitDiff.Stats = append(unitDiff.Stats, numberChange(metric.Name, b, a))
+		}
+	}
+
+	flat, err := table.Flatten([]*models.Unit{before, after})
+	if err != nil {
+		return unitDiff, fmt.Errorf("failed to flatten %s: %w", after.ID, err)
+	}
+	for c, column := range flat.Columns {
+		if !selected(column, opts.Fields) {
+			continue
+		}
+		b, a := flat.Rows[0][c], flat.Rows[1][c]
+		if b == a {
+			continue
+		}
+		bn, berr := strconv.ParseFloat(b, 64)
+		an, aerr := strconv.ParseFloat(a, 64)
+		switch {
+		case berr == nil && aerr == nil:
+			if bn != an {
+				unitDiff.Fields = append(unitDiff.Fields, numberChange(column, bn, an))
+			}
+		default:
+			unitDiff.Fields = append(unitDiff.Fields, Change{Field: column, Before: cell(b), After: cell(a)})
+		}
+	}
+	return unitDiff, nil
+}
+
+func numberChange(field string, before, after float64) Change {
+	change := Change{Field: field, Before: before, After: after}
+	delta := round(after - before)
+	change.Delta = &delta
+	if before != 0 {
+		percent := round(delta / math.Abs(before) * 100)
+		change.Percent = &percent
+	}
+	return change
+}
+
+// cell converts a flattened cell back into a JSON value: a number, a string or nil when empty
+func cell(s string) any {
+	if s == "" {
+		return nil
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v
+	}
+	return s
+}
+
+func selected(field string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, p := range prefixes {
+		if field == p || strings.HasPrefix(field, p+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Describe renders a change for the terminal, e.g. "health 1000 → 1200 (+200, +20%)"
+func (c Change) Describe() string {
+	text := fmt.Sprintf("%s %s → %s", c.Field, describeValue(c.Before), describeValue(c.After))
+	if c.Delta != nil {
+		text += " (" + signed(*c.Delta)
+		if c.Percent != nil {
+			text += ", " + signed(*c.Percent) + "%"
+		}
+		text += ")"
+	}
+	return text
+}
+
+func describeValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "(none)"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return strconv.Quote(v)
+	}
+	return fmt.Sprint(v)
+}
+
+func signed(v float64) string {
+	if v > 0 {
+		return "+" + formatNumber(v)
+	}
+	return formatNumber(v)
+}
+
+// formatNumber prints a delta to at most 2 decimal places, without trailing zeros
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(round(v), 'f', -1, 64)
+}
+
+// round keeps deltas to 2 decimal places
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
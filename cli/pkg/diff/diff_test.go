@@ -0,0 +1,83 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func unit(id string, health, cost float64, types ...string) *models.Unit {
+	return &models.Unit{
+		ID:          id,
+		DisplayName: id,
+		UnitTypes:   types,
+		Specs: models.UnitSpecs{
+			Combat:  &models.CombatSpecs{Health: health},
+			Economy: &models.EconomySpecs{BuildCost: cost},
+		},
+	}
+}
+
+func TestCompare(t *testing.T) {
+	before := []*models.Unit{unit("tank", 200, 100, "Land"), unit("bot", 100, 50, "Land"), unit("old", 10, 10)}
+	after := []*models.Unit{unit("tank", 250, 100, "Land", "Tank"), unit("bot", 100, 50, "Land"), unit("new", 10, 10)}
+
+	report, err := Compare(before, after, Options{})
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if want := []UnitRef{{"new", "new"}}; !reflect.DeepEqual(report.Added, want) {
+		t.Errorf("Added = %v, want %v", report.Added, want)
+	}
+	if want := []UnitRef{{"old", "old"}}; !reflect.DeepEqual(report.Removed, want) {
+		t.Errorf("Removed = %v, want %v", report.Removed, want)
+	}
+	if report.Unchanged != 1 {
+		t.Errorf("Unchanged = %d, want 1", report.Unchanged)
+	}
+	if len(report.Changed) != 1 {
+		t.Fatalf("Changed = %+v, want tank", report.Changed)
+	}
+
+	tank := report.Changed[0]
+	var stats []string
+	for _, c := range tank.Stats {
+		stats = append(stats, c.Describe())
+	}
+	if want := []string{"health 200 → 250 (+50, +25%)"}; !reflect.DeepEqual(stats, want) {
+		t.Errorf("Stats = %q, want %q", stats, want)
+	}
+	var fields []string
+	for _, c := range tank.Fields {
+		fields = append(fields, c.Describe())
+	}
+	want := []string{`unitTypes "Land" → "Land;Tank"`, "specs.combat.health 200 → 250 (+50, +25%)"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("Fields = %q, want %q", fields, want)
+	}
+
+	report, err = Compare(before, after, Options{Fields: []string{"specs.combat"}})
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if fields := report.Changed[0].Fields; len(fields) != 1 || fields[0].Field != "specs.combat.health" {
+		t.Errorf("Fields with a specs.combat filter = %+v, want only specs.combat.health", fields)
+	}
+}
+
+func TestChangeDescribe(t *testing.T) {
+	tests := []struct {
+		change Change
+		want   string
+	}{
+		{numberChange("cost", 100, 80), "cost 100 → 80 (-20, -20%)"},
+		{numberChange("speed", 0, 12), "speed 0 → 12 (+12)"},
+		{Change{Field: "description", Before: nil, After: "Fast"}, `description (none) → "Fast"`},
+	}
+	for _, tt := range tests {
+		if got := tt.change.Describe(); got != tt.want {
+			t.Errorf("Describe() = %q, want %q", got, tt.want)
+		}
+	}
+}
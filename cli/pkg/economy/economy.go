@@ -0,0 +1,156 @@
+package economy
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// UnitCount is one entry of a described base: a unit ID and how many of it there are.
+type UnitCount struct {
+	UnitID string `json:"unitId"`
+	Count  int    `json:"count"`
+}
+
+// Snapshot is the combined economy of a described base, with every unit working flat out
+// (fabricators and factories building, weapons firing).
+type Snapshot struct {
+	MetalIncome   float64 `json:"metalIncome"`
+	MetalDemand   float64 `json:"metalDemand"`
+	EnergyIncome  float64 `json:"energyIncome"`
+	EnergyDemand  float64 `json:"energyDemand"`
+	MetalStorage  float64 `json:"metalStorage"`
+	EnergyStorage float64 `json:"energyStorage"`
+	BuildPower    float64 `json:"buildPower"` // Metal per second the base's build arms can spend
+	Stalls        []Stall `json:"stalls"`
+}
+
+// NetMetal returns metal income minus demand per second.
+func (s *Snapshot) NetMetal() float64 {
+	return round(s.MetalIncome - s.MetalDemand)
+}
+
+// NetEnergy returns energy income minus demand per second.
+func (s *Snapshot) NetEnergy() float64 {
+	return round(s.EnergyIncome - s.EnergyDemand)
+}
+
+// Stall describes a resource whose demand exceeds its income.
+type Stall struct {
+	Resource string  `json:"resource"` // "metal" or "energy"
+	Deficit  float64 `json:"deficit"`  // Shortfall per second
+	// Efficiency is income / demand: the fraction of full speed the base can sustain
+	// once storage is empty.
+	Efficiency float64 `json:"efficiency"`
+	// SecondsToEmpty is how long full storage lasts at this deficit (0 with no storage).
+	SecondsToEmpty float64 `json:"secondsToEmpty"`
+}
+
+// ParseUnitCounts parses a comma-separated "id:count" list (e.g. "mex:10,energy_plant:8").
+// A bare ID counts as 1. Repeated IDs are summed.
+func ParseUnitCounts(spec string) ([]UnitCount, error) {
+	counts := make([]UnitCount, 0)
+	index := make(map[string]int)
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		id, countStr, hasCount := strings.Cut(part, ":")
+		id = strings.TrimSpace(id)
+		if id == "" {
+			return nil, fmt.Errorf("missing unit ID in %q", part)
+		}
+
+		count := 1
+		if hasCount {
+			n, err := strconv.Atoi(strings.TrimSpace(countStr))
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid count for '%s': %q (expected a non-negative whole number)", id, countStr)
+			}
+			count = n
+		}
+
+		if i, ok := index[id]; ok {
+			counts[i].Count += count
+			continue
+		}
+		index[id] = len(counts)
+		counts = append(counts, UnitCount{UnitID: id, Count: count})
+	}
+
+	if len(counts) == 0 {
+		return nil, fmt.Errorf("no units given (expected e.g. metal_extractor:10,energy_plant:8)")
+	}
+
+	return counts, nil
+}
+
+// Calculate sums the economy of the described base. Every unit ID must exist in units.
+func Calculate(units map[string]*models.Unit, counts []UnitCount) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+
+	for _, c := range counts {
+		unit, ok := units[c.UnitID]
+		if !ok {
+			return nil, fmt.Errorf("unit '%s' not found in faction", c.UnitID)
+		}
+		econ := unit.Specs.Economy
+		if econ == nil {
+			continue
+		}
+
+		n := float64(c.Count)
+		snapshot.MetalIncome += econ.Production.Metal * n
+		snapshot.EnergyIncome += econ.Production.Energy * n
+		// MetalRate/EnergyRate are already net of production, so demand is the remainder
+		snapshot.MetalDemand += (econ.Production.Metal - econ.MetalRate) * n
+		snapshot.EnergyDemand += (econ.Production.Energy - econ.EnergyRate) * n
+		snapshot.MetalStorage += econ.Storage.Metal * n
+		snapshot.EnergyStorage += econ.Storage.Energy * n
+		snapshot.BuildPower += econ.BuildRate * n
+	}
+
+	snapshot.MetalIncome = round(snapshot.MetalIncome)
+	snapshot.MetalDemand = round(snapshot.MetalDemand)
+	snapshot.EnergyIncome = round(snapshot.EnergyIncome)
+	snapshot.EnergyDemand = round(snapshot.EnergyDemand)
+	snapshot.BuildPower = round(snapshot.BuildPower)
+
+	if stall := detectStall("metal", snapshot.MetalIncome, snapshot.MetalDemand, snapshot.MetalStorage); stall != nil {
+		snapshot.Stalls = append(snapshot.Stalls, *stall)
+	}
+	if stall := detectStall("energy", snapshot.EnergyIncome, snapshot.EnergyDemand, snapshot.EnergyStorage); stall != nil {
+		snapshot.Stalls = append(snapshot.Stalls, *stall)
+	}
+
+	return snapshot, nil
+}
+
+// detectStall returns a Stall when demand exceeds income, or nil.
+func detectStall(resource string, income, demand, storage float64) *Stall {
+	if demand <= income {
+		return nil
+	}
+
+	deficit := round(demand - income)
+	stall := &Stall{
+		Resource:   resource,
+		Deficit:    deficit,
+		Efficiency: round(income / demand),
+	}
+	if storage > 0 {
+		stall.SecondsToEmpty = round(storage / deficit)
+	}
+	return stall
+}
+
+// round rounds to 2 decimal places, matching the parser's economy rates
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
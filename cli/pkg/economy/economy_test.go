@@ -0,0 +1,140 @@
+package economy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// TestParseUnitCounts tests parsing of the --units list
+func TestParseUnitCounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		expected []UnitCount
+		errorMsg string
+	}{
+		{
+			name:     "counts",
+			spec:     "mex:10,energy_plant:8",
+			expected: []UnitCount{{"mex", 10}, {"energy_plant", 8}},
+		},
+		{
+			name:     "bare ID counts as one and whitespace is ignored",
+			spec:     " fabber , mex : 2 ",
+			expected: []UnitCount{{"fabber", 1}, {"mex", 2}},
+		},
+		{
+			name:     "repeated IDs are summed",
+			spec:     "mex:2,mex:3",
+			expected: []UnitCount{{"mex", 5}},
+		},
+		{
+			name:     "invalid count",
+			spec:     "mex:ten",
+			errorMsg: "invalid count",
+		},
+		{
+			name:     "negative count",
+			spec:     "mex:-1",
+			errorMsg: "invalid count",
+		},
+		{
+			name:     "missing ID",
+			spec:     ":4",
+			errorMsg: "missing unit ID",
+		},
+		{
+			name:     "empty",
+			spec:     " , ",
+			errorMsg: "no units given",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts, err := ParseUnitCounts(tt.spec)
+			if tt.errorMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+					t.Fatalf("expected error containing %q, got %v", tt.errorMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(counts) != len(tt.expected) {
+				t.Fatalf("got %v, want %v", counts, tt.expected)
+			}
+			for i := range counts {
+				if counts[i] != tt.expected[i] {
+					t.Errorf("got %v, want %v", counts, tt.expected)
+					break
+				}
+			}
+		})
+	}
+}
+
+// TestCalculate tests summing a described base and flagging stalls
+func TestCalculate(t *testing.T) {
+	units := map[string]*models.Unit{
+		"mex": {ID: "mex", Specs: models.UnitSpecs{Economy: &models.EconomySpecs{
+			Production: models.Resources{Metal: 7},
+			MetalRate:  7,
+		}}},
+		"energy_plant": {ID: "energy_plant", Specs: models.UnitSpecs{Economy: &models.EconomySpecs{
+			Production: models.Resources{Energy: 600},
+			EnergyRate: 600,
+		}}},
+		"storage": {ID: "storage", Specs: models.UnitSpecs{Economy: &models.EconomySpecs{
+			Storage: models.Resources{Metal: 1000},
+		}}},
+		"fabber": {ID: "fabber", Specs: models.UnitSpecs{Economy: &models.EconomySpecs{
+			ToolConsumption: models.Resources{Metal: 7, Energy: 525},
+			BuildRate:       7,
+			MetalRate:       -7,
+			EnergyRate:      -525,
+		}}},
+	}
+
+	t.Run("balanced base", func(t *testing.T) {
+		snapshot, err := Calculate(units, []UnitCount{{"mex", 2}, {"energy_plant", 2}, {"fabber", 2}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if snapshot.NetMetal() != 0 || snapshot.NetEnergy() != 150 {
+			t.Errorf("net = %v metal / %v energy, want 0 / 150", snapshot.NetMetal(), snapshot.NetEnergy())
+		}
+		if snapshot.BuildPower != 14 {
+			t.Errorf("BuildPower = %v, want 14", snapshot.BuildPower)
+		}
+		if len(snapshot.Stalls) != 0 {
+			t.Errorf("expected no stalls, got %+v", snapshot.Stalls)
+		}
+	})
+
+	t.Run("metal stall drains storage", func(t *testing.T) {
+		snapshot, err := Calculate(units, []UnitCount{{"mex", 2}, {"energy_plant", 5}, {"fabber", 4}, {"storage", 1}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(snapshot.Stalls) != 1 {
+			t.Fatalf("expected 1 stall, got %+v", snapshot.Stalls)
+		}
+		stall := snapshot.Stalls[0]
+		if stall.Resource != "metal" || stall.Deficit != 14 || stall.Efficiency != 0.5 {
+			t.Errorf("stall = %+v, want metal deficit 14 at 0.5 efficiency", stall)
+		}
+		if stall.SecondsToEmpty != 71.43 {
+			t.Errorf("SecondsToEmpty = %v, want 71.43", stall.SecondsToEmpty)
+		}
+	})
+
+	t.Run("unknown unit", func(t *testing.T) {
+		if _, err := Calculate(units, []UnitCount{{"tank", 1}}); err == nil {
+			t.Error("expected error for unknown unit")
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// IDAliasesFile is the unit ID history kept in a faction folder
+const IDAliasesFile = "id-aliases.json"
+
+// ReadIDAliases reads id-aliases.json from a faction folder. A folder without one gets its
+// IDs from units.json, so exports made before the file existed keep their IDs too; a
+// folder with neither gives empty aliases.
+func ReadIDAliases(factionDir string) (*models.IDAliases, error) {
+	aliases := &models.IDAliases{IDs: make(map[string]string), Renames: []models.IDRename{}}
+	data, err := os.ReadFile(filepath.Join(factionDir, IDAliasesFile))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, aliases); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", IDAliasesFile, err)
+		}
+		if aliases.IDs == nil {
+			aliases.IDs = make(map[string]string)
+		}
+		if aliases.Renames == nil {
+			aliases.Renames = []models.IDRename{}
+		}
+		return aliases, nil
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("failed to read %s: %w", IDAliasesFile, err)
+	}
+
+	index, err := ReadFactionIndex(factionDir)
+	if err != nil {
+		return aliases, nil // No previous export
+	}
+	for _, entry := range index.Units {
+		aliases.IDs[entry.Unit.ResourceName] = entry.Identifier
+	}
+	return aliases, nil
+}
+
+// UpdateIDAliases records the IDs of an export's units in aliases and returns the units
+// renamed since the previous export (nil when none), which are also appended to
+// aliases.Renames under version. A unit counts as renamed when its resource got a new ID,
+// or when a unit of previous (the folder's units.json before this export, nil for none)
+// is gone and exactly one unit new to the folder comes from a file of the same name, as
+// when a mod moves a unit's folder. An old ID that some unit still has is never aliased.
+func UpdateIDAliases(aliases *models.IDAliases, previous *models.FactionIndex, previousVersion, version string, units []models.Unit) map[string]string {
+	current := make(map[string]bool, len(units))
+	resources := make(map[string]bool, len(units))
+	for _, u := range units {
+		current[u.ID] = true
+		resources[u.ResourceName] = true
+	}
+
+	renames := make(map[string]string)
+	var added []models.Unit // Units from resources the folder has never exported
+	for _, u := range units {
+		old, known := aliases.IDs[u.ResourceName]
+		switch {
+		case !known:
+			added = append(added, u)
+		case old != u.ID && !current[old]:
+			renames[old] = u.ID
+		}
+	}
+
+	if previous != nil {
+		for _, entry := range previous.Units {
+			old := entry.Unit
+			if resources[old.ResourceName] || current[old.ID] {
+				continue
+			}
+			var match *models.Unit
+			for i, u := range added {
+				if path.Base(u.ResourceName) != path.Base(old.ResourceName) {
+					continue
+				}
+				if match != nil {
+					match = nil // Ambiguous
+					break
+				}
+				match = &added[i]
+			}
+			if match != nil {
+				renames[old.ID] = match.ID
+			}
+		}
+	}
+
+	for _, u := range units {
+		aliases.IDs[u.ResourceName] = u.ID
+	}
+	if len(renames) == 0 {
+		return nil
+	}
+	aliases.Renames = append(aliases.Renames, models.IDRename{Version: version, Previous: previousVersion, Aliases: renames})
+	return renames
+}
+
+// WriteIDAliases writes id-aliases.json to a faction folder
+func WriteIDAliases(factionDir string, aliases *models.IDAliases) error {
+	data, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal ID aliases: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(factionDir, IDAliasesFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", IDAliasesFile, err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package exporter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestUpdateIDAliases(t *testing.T) {
+	unit := func(id, resourceName string) models.Unit {
+		return models.Unit{ID: id, ResourceName: resourceName}
+	}
+	previous := &models.FactionIndex{Units: []models.UnitIndexEntry{
+		{Identifier: "tank", Unit: unit("tank", "/pa/units/land/tank/tank.json")},
+		{Identifier: "bot", Unit: unit("bot", "/pa/units/land/bot/bot.json")},
+		{Identifier: "fighter", Unit: unit("fighter", "/pa/units/air/fighter/fighter.json")},
+		{Identifier: "gone", Unit: unit("gone", "/pa/units/land/gone/gone.json")},
+	}}
+	aliases := &models.IDAliases{IDs: map[string]string{}, Renames: []models.IDRename{}}
+	for _, entry := range previous.Units {
+		aliases.IDs[entry.Unit.ResourceName] = entry.Identifier
+	}
+
+	units := []models.Unit{
+		unit("tank", "/pa/units/land/tank/tank.json"),               // Unchanged
+		unit("bot_2", "/pa/units/land/bot/bot.json"),                // Same file, new ID
+		unit("fighter_v2", "/pa/units/air/fighter_v2/fighter.json"), // Folder moved
+		unit("new", "/pa/units/land/new/new.json"),                  // Added
+	}
+	renames := UpdateIDAliases(aliases, previous, "1.0.0", "1.1.0", units)
+
+	want := map[string]string{"bot": "bot_2", "fighter": "fighter_v2"}
+	if len(renames) != len(want) {
+		t.Fatalf("renames = %v, want %v", renames, want)
+	}
+	for old, id := range want {
+		if renames[old] != id {
+			t.Errorf("renames[%s] = %q, want %q", old, renames[old], id)
+		}
+	}
+	if len(aliases.Renames) != 1 || aliases.Renames[0].Version != "1.1.0" || aliases.Renames[0].Previous != "1.0.0" {
+		t.Errorf("unexpected rename history: %+v", aliases.Renames)
+	}
+	if aliases.IDs["/pa/units/land/gone/gone.json"] != "gone" {
+		t.Error("removed unit's ID not kept reserved")
+	}
+	if aliases.IDs["/pa/units/land/new/new.json"] != "new" {
+		t.Error("new unit's ID not recorded")
+	}
+
+	if again := UpdateIDAliases(aliases, nil, "1.1.0", "1.1.1", units); again != nil {
+		t.Errorf("unchanged export reported renames: %v", again)
+	}
+}
+
+func TestReadIDAliases(t *testing.T) {
+	dir := t.TempDir()
+	aliases, err := ReadIDAliases(dir)
+	if err != nil || len(aliases.IDs) != 0 {
+		t.Fatalf("ReadIDAliases of an empty folder = %+v, %v", aliases, err)
+	}
+
+	// An export from before id-aliases.json existed seeds the IDs from units.json
+	index, _ := json.Marshal(models.FactionIndex{Units: []models.UnitIndexEntry{
+		{Identifier: "tank", Unit: models.Unit{ID: "tank", ResourceName: "/pa/units/land/tank/tank.json"}},
+	}})
+	if err := os.WriteFile(filepath.Join(dir, "units.json"), index, 0644); err != nil {
+		t.Fatal(err)
+	}
+	aliases, err = ReadIDAliases(dir)
+	if err != nil || aliases.IDs["/pa/units/land/tank/tank.json"] != "tank" {
+		t.Fatalf("IDs not seeded from units.json: %+v, %v", aliases, err)
+	}
+
+	aliases.IDs["/pa/units/land/bot/bot.json"] = "bot"
+	if err := WriteIDAliases(dir, aliases); err != nil {
+		t.Fatalf("WriteIDAliases failed: %v", err)
+	}
+	read, err := ReadIDAliases(dir)
+	if err != nil || len(read.IDs) != 2 {
+		t.Errorf("id-aliases.json not preferred over units.json: %+v, %v", read, err)
+	}
+}
@@ -0,0 +1,89 @@
+package exporter
+
+import (
+	"math"
+	"sort"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// AssistFactories returns the export's factories with build arms, sorted by display name
+// then ID, for AssistEconomy
+func AssistFactories(units []models.Unit) []*models.Unit {
+	var factories []*models.Unit
+	for i := range units {
+		if hasUnitType(units[i].UnitTypes, "Factory") && buildMetal(&units[i]) > 0 {
+			factories = append(factories, &units[i])
+		}
+	}
+	sort.Slice(factories, func(i, j int) bool {
+		if factories[i].DisplayName != factories[j].DisplayName {
+			return factories[i].DisplayName < factories[j].DisplayName
+		}
+		return factories[i].ID < factories[j].ID
+	})
+	return factories
+}
+
+// AssistEconomy works out what a mobile builder spends helping each factory: its build
+// arms run alongside the factory's, so the pair's combined energy per metal sits between
+// the two units' own buildInefficiency. A builder that can only assist with what it could
+// build itself (can_only_assist_with_buildable_items) gets only factories sharing one of
+// its builds. Returns nil for units that aren't mobile builders.
+//
+// Factory specs carry no inefficiency field of their own; a factory's spending comes from
+// its build arms' construction_demand, parsed like any other builder's.
+func AssistEconomy(builder *models.Unit, factories []*models.Unit) []models.AssistEconomy {
+	if !hasUnitType(builder.UnitTypes, "Mobile") || hasUnitType(builder.UnitTypes, "Factory") || buildMetal(builder) <= 0 {
+		return nil
+	}
+	assistOnlyOwn := builder.AssistBuildOnly != nil && *builder.AssistBuildOnly
+	own := make(map[string]bool, len(builder.BuildRelationships.Builds))
+	for _, id := range builder.BuildRelationships.Builds {
+		own[id] = true
+	}
+
+	var assists []models.AssistEconomy
+	for _, factory := range factories {
+		if factory.ID == builder.ID {
+			continue
+		}
+		if assistOnlyOwn && !sharesBuild(factory.BuildRelationships.Builds, own) {
+			continue
+		}
+		metal := builder.Specs.Economy.ToolConsumption.Metal + factory.Specs.Economy.ToolConsumption.Metal
+		energy := builder.Specs.Economy.ToolConsumption.Energy + factory.Specs.Economy.ToolConsumption.Energy
+		assists = append(assists, models.AssistEconomy{
+			Factory:           factory.ID,
+			Metal:             metal,
+			Energy:            energy,
+			BuildInefficiency: math.Round(energy/metal*100) / 100,
+		})
+	}
+	return assists
+}
+
+func buildMetal(unit *models.Unit) float64 {
+	if unit.Specs.Economy == nil {
+		return 0
+	}
+	return unit.Specs.Economy.ToolConsumption.Metal
+}
+
+func sharesBuild(builds []string, own map[string]bool) bool {
+	for _, id := range builds {
+		if own[id] {
+			return true
+		}
+	}
+	return false
+}
+
+func hasUnitType(unitTypes []string, t string) bool {
+	for _, ut := range unitTypes {
+		if ut == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package exporter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestAssistEconomy(t *testing.T) {
+	builder := func(id string, types []string, metal, energy float64, builds ...string) models.Unit {
+		return models.Unit{
+			ID:                 id,
+			DisplayName:        id,
+			UnitTypes:          types,
+			BuildRelationships: models.BuildRelationships{Builds: builds},
+			Specs: models.UnitSpecs{Economy: &models.EconomySpecs{
+				ToolConsumption: models.Resources{Metal: metal, Energy: energy},
+			}},
+		}
+	}
+	yes := true
+	units := []models.Unit{
+		builder("vehicle_factory", []string{"Structure", "Factory"}, 15, 450, "tank", "vehicle_fab"),
+		builder("air_factory", []string{"Structure", "Factory"}, 10, 200, "fighter"),
+		builder("vehicle_fab", []string{"Mobile", "Fabber"}, 10, 1000, "vehicle_factory", "tank"),
+		builder("bot_fab", []string{"Mobile", "Fabber"}, 5, 500, "air_factory"),
+		builder("turret", []string{"Structure"}, 0, 0),
+	}
+	units[3].AssistBuildOnly = &yes
+
+	factories := AssistFactories(units)
+	if len(factories) != 2 || factories[0].ID != "air_factory" || factories[1].ID != "vehicle_factory" {
+		t.Fatalf("AssistFactories() = %v, want air_factory, vehicle_factory", factories)
+	}
+
+	tests := []struct {
+		unit int
+		want []models.AssistEconomy
+	}{
+		// 1000 E/M building directly; 1200/20 with the air factory, 1450/25 with the vehicle factory
+		{2, []models.AssistEconomy{
+			{Factory: "air_factory", Metal: 20, Energy: 1200, BuildInefficiency: 60},
+			{Factory: "vehicle_factory", Metal: 25, Energy: 1450, BuildInefficiency: 58},
+		}},
+		// Can only assist with its own builds: neither factory builds the air factory
+		{3, nil},
+		// Factories and non-builders don't assist
+		{0, nil},
+		{4, nil},
+	}
+	for _, tt := range tests {
+		got := AssistEconomy(&units[tt.unit], factories)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AssistEconomy(%s) = %+v, want %+v", units[tt.unit].ID, got, tt.want)
+		}
+	}
+}
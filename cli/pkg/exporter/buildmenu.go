@@ -0,0 +1,96 @@
+package exporter
+
+import (
+	"sort"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// BuildMenu lays a builder's resolved Builds out the way the in-game build bar does: a tab
+// per category (in models.BuildMenuCategories order) and a row per tier, units sorted by
+// display name then ID. IDs missing from byID (e.g. base game units left out of an addon
+// export) are skipped so every menu entry resolves within the export. Returns nil when
+// nothing resolves.
+func BuildMenu(builds []string, byID map[string]*models.Unit) []models.BuildMenuGroup {
+	rows := make(map[string]map[int][]*models.Unit)
+	for _, id := range builds {
+		unit, ok := byID[id]
+		if !ok {
+			continue
+		}
+		category := BuildMenuCategory(unit.UnitTypes)
+		if rows[category] == nil {
+			rows[category] = make(map[int][]*models.Unit)
+		}
+		rows[category][unit.Tier] = append(rows[category][unit.Tier], unit)
+	}
+
+	var menu []models.BuildMenuGroup
+	for _, category := range models.BuildMenuCategories {
+		byTier, ok := rows[category]
+		if !ok {
+			continue
+		}
+
+		tiers := make([]int, 0, len(byTier))
+		for tier := range byTier {
+			tiers = append(tiers, tier)
+		}
+		sort.Ints(tiers)
+
+		group := models.BuildMenuGroup{Category: category}
+		for _, tier := range tiers {
+			row := byTier[tier]
+			sort.Slice(row, func(i, j int) bool {
+				if row[i].DisplayName != row[j].DisplayName {
+					return row[i].DisplayName < row[j].DisplayName
+				}
+				return row[i].ID < row[j].ID
+			})
+
+			ids := make([]string, len(row))
+			for k, unit := range row {
+				ids[k] = unit.ID
+			}
+			group.Tiers = append(group.Tiers, models.BuildMenuTier{Tier: tier, Units: ids})
+		}
+		menu = append(menu, group)
+	}
+
+	return menu
+}
+
+// BuildMenuCategory picks the build bar tab for a unit from its unit types. Structures split
+// into factory, combat and utility tabs; mobile units go to their domain's tab.
+func BuildMenuCategory(unitTypes []string) string {
+	types := make(map[string]bool, len(unitTypes))
+	for _, t := range unitTypes {
+		types[t] = true
+	}
+
+	if types["Structure"] {
+		switch {
+		case types["Factory"]:
+			return models.BuildMenuFactory
+		case types["Defense"], types["Artillery"], types["Nuke"]:
+			return models.BuildMenuCombat
+		default:
+			return models.BuildMenuUtility
+		}
+	}
+
+	switch {
+	case types["Orbital"]:
+		return models.BuildMenuOrbital
+	case types["Air"]:
+		return models.BuildMenuAir
+	case types["Naval"]:
+		return models.BuildMenuSea
+	case types["Bot"]:
+		return models.BuildMenuBot
+	case types["Land"]:
+		return models.BuildMenuVehicle
+	default:
+		return models.BuildMenuUtility
+	}
+}
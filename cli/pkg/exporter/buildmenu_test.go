@@ -0,0 +1,69 @@
+package exporter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestBuildMenuCategory(t *testing.T) {
+	tests := []struct {
+		name      string
+		unitTypes []string
+		want      string
+	}{
+		{"factory", []string{"Structure", "Factory", "Land", "Basic"}, models.BuildMenuFactory},
+		{"defense", []string{"Structure", "Defense", "Land"}, models.BuildMenuCombat},
+		{"artillery", []string{"Structure", "Artillery", "Advanced"}, models.BuildMenuCombat},
+		{"economy structure", []string{"Structure", "Economy", "MetalProduction"}, models.BuildMenuUtility},
+		{"orbital factory is a factory", []string{"Structure", "Factory", "Orbital"}, models.BuildMenuFactory},
+		{"tank", []string{"Mobile", "Tank", "Land", "Basic"}, models.BuildMenuVehicle},
+		{"bot", []string{"Mobile", "Bot", "Land", "Basic"}, models.BuildMenuBot},
+		{"fighter", []string{"Mobile", "Air", "Basic"}, models.BuildMenuAir},
+		{"naval", []string{"Mobile", "Naval", "Basic"}, models.BuildMenuSea},
+		{"orbital fabber", []string{"Mobile", "Orbital", "Fabber"}, models.BuildMenuOrbital},
+		{"untyped", nil, models.BuildMenuUtility},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildMenuCategory(tt.unitTypes); got != tt.want {
+				t.Errorf("BuildMenuCategory(%v) = %q, want %q", tt.unitTypes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildMenu(t *testing.T) {
+	units := []models.Unit{
+		{ID: "tank", DisplayName: "Ant", Tier: 1, UnitTypes: []string{"Mobile", "Tank", "Land"}},
+		{ID: "tank_light_laser", DisplayName: "Dox", Tier: 1, UnitTypes: []string{"Mobile", "Tank", "Land"}},
+		{ID: "tank_heavy_armor", DisplayName: "Vanguard", Tier: 2, UnitTypes: []string{"Mobile", "Tank", "Land"}},
+		{ID: "bot_factory", DisplayName: "Bot Factory", Tier: 1, UnitTypes: []string{"Structure", "Factory"}},
+		{ID: "metal_extractor", DisplayName: "Metal Extractor", Tier: 1, UnitTypes: []string{"Structure", "Economy"}},
+		{ID: "metal_extractor_copy", DisplayName: "Metal Extractor", Tier: 1, UnitTypes: []string{"Structure", "Economy"}},
+	}
+	byID := make(map[string]*models.Unit, len(units))
+	for i := range units {
+		byID[units[i].ID] = &units[i]
+	}
+
+	builds := []string{"tank_heavy_armor", "tank_light_laser", "metal_extractor_copy", "tank", "bot_factory", "metal_extractor", "base_game_only"}
+	got := BuildMenu(builds, byID)
+	want := []models.BuildMenuGroup{
+		{Category: models.BuildMenuFactory, Tiers: []models.BuildMenuTier{{Tier: 1, Units: []string{"bot_factory"}}}},
+		{Category: models.BuildMenuUtility, Tiers: []models.BuildMenuTier{{Tier: 1, Units: []string{"metal_extractor", "metal_extractor_copy"}}}},
+		{Category: models.BuildMenuVehicle, Tiers: []models.BuildMenuTier{
+			{Tier: 1, Units: []string{"tank", "tank_light_laser"}},
+			{Tier: 2, Units: []string{"tank_heavy_armor"}},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildMenu() =\n  %+v\nwant\n  %+v", got, want)
+	}
+
+	if menu := BuildMenu([]string{"base_game_only"}, byID); menu != nil {
+		t.Errorf("BuildMenu() with no resolvable IDs = %+v, want nil", menu)
+	}
+}
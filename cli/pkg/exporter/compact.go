@@ -0,0 +1,266 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Index formats accepted by ParseIndexFormat
+const (
+	IndexFormatJSON = "json"
+	IndexFormatPB   = "pb"
+)
+
+// IndexOptions controls which index files are written and how
+type IndexOptions struct {
+	// Format is IndexFormatJSON (units.json only, the default) or IndexFormatPB
+	// (units.json plus a protobuf units.pb described by schema/faction-index.proto).
+	Format string
+	// JSON controls the encoding of units.json
+	JSON JSONOptions
+}
+
+// ParseIndexFormat validates a --format flag value
+func ParseIndexFormat(name string) (string, error) {
+	switch strings.ToLower(name) {
+	case "", IndexFormatJSON:
+		return IndexFormatJSON, nil
+	case IndexFormatPB:
+		return IndexFormatPB, nil
+	default:
+		return "", fmt.Errorf("unknown index format '%s' (expected %s or %s)", name, IndexFormatJSON, IndexFormatPB)
+	}
+}
+
+// JSONOptions controls how units.json is encoded. The zero value produces the
+// default pretty-printed output.
+type JSONOptions struct {
+	// Minify writes compact JSON without indentation or newlines.
+	Minify bool
+	// PruneEmpty drops optional fields whose value is empty: "", 0, false,
+	// null, [] or {}. Fields marked required in the schema and optional
+	// pointer scalars (where false/0 is meaningful, e.g. weapon "enabled") are
+	// always kept, so pruned output still validates.
+	PruneEmpty bool
+}
+
+// Marshal encodes v according to the options.
+func (o JSONOptions) Marshal(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.PruneEmpty {
+		data, err = pruneEmpty(data, keptKeysFor(reflect.TypeOf(v)))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if o.Minify {
+		return data, nil
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// keptKeyCache memoises keptKeysFor per root type
+var keptKeyCache sync.Map // reflect.Type -> map[string]bool
+
+// keptKeysFor returns the JSON names that pruning must never drop for the
+// types reachable from t. Names are collected across all structs, so a name
+// that is required anywhere is kept everywhere; this only means less pruning.
+func keptKeysFor(t reflect.Type) map[string]bool {
+	if cached, ok := keptKeyCache.Load(t); ok {
+		return cached.(map[string]bool)
+	}
+	keys := make(map[string]bool)
+	collectKeptKeys(t, keys, make(map[reflect.Type]bool))
+	keptKeyCache.Store(t, keys)
+	return keys
+}
+
+func collectKeptKeys(t reflect.Type, keys map[string]bool, seen map[reflect.Type]bool) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schemaTag := field.Tag.Get("jsonschema")
+		if schemaTag == "required" || strings.HasPrefix(schemaTag, "required,") {
+			keys[name] = true
+		}
+		if field.Type.Kind() == reflect.Pointer && field.Type.Elem().Kind() != reflect.Struct {
+			keys[name] = true
+		}
+
+		collectKeptKeys(field.Type, keys, seen)
+	}
+}
+
+// jsonField is one key/value pair of an objectNode
+type jsonField struct {
+	Key   string
+	Value any
+}
+
+// objectNode is a JSON object that keeps its keys in source order, so pruned
+// output lists fields in the same order as the Go structs.
+type objectNode []jsonField
+
+// MarshalJSON implements json.Marshaler
+func (o objectNode) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.Key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// pruneEmpty removes empty values from every object in data, except keys in keep
+func pruneEmpty(data []byte, keep map[string]bool) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	tree, err := decodeOrdered(dec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JSON for pruning: %w", err)
+	}
+	return json.Marshal(pruneValue(tree, keep))
+}
+
+// decodeOrdered reads one JSON value, decoding objects as objectNode
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+
+	switch delim {
+	case '{':
+		obj := objectNode{}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := keyTok.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected object key %v", keyTok)
+			}
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, jsonField{Key: key, Value: value})
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case '[':
+		arr := []any{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return arr, nil
+	}
+	return nil, io.ErrUnexpectedEOF
+}
+
+// pruneValue prunes children first so objects that become empty are dropped too.
+// Array elements are never removed, only pruned inside.
+func pruneValue(v any, keep map[string]bool) any {
+	switch val := v.(type) {
+	case objectNode:
+		pruned := make(objectNode, 0, len(val))
+		for _, f := range val {
+			child := pruneValue(f.Value, keep)
+			if !keep[f.Key] && isEmptyJSON(child) {
+				continue
+			}
+			pruned = append(pruned, jsonField{Key: f.Key, Value: child})
+		}
+		return pruned
+	case []any:
+		for i := range val {
+			val[i] = pruneValue(val[i], keep)
+		}
+		return val
+	}
+	return v
+}
+
+// isEmptyJSON reports whether a decoded value is "", 0, false, null, [] or {}
+func isEmptyJSON(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return val == ""
+	case bool:
+		return !val
+	case json.Number:
+		f, err := strconv.ParseFloat(string(val), 64)
+		return err == nil && f == 0
+	case []any:
+		return len(val) == 0
+	case objectNode:
+		return len(val) == 0
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+type compactInner struct {
+	Count int `json:"count,omitempty" jsonschema:"description=Optional count"`
+}
+
+type compactSample struct {
+	ID       string         `json:"id" jsonschema:"required,description=Identifier"`
+	Tier     int            `json:"tier" jsonschema:"required,description=Tier"`
+	Name     string         `json:"name" jsonschema:"description=Optional name"`
+	Rate     float64        `json:"rate" jsonschema:"description=Optional rate"`
+	Flag     bool           `json:"flag" jsonschema:"description=Optional flag"`
+	Enabled  *bool          `json:"enabled,omitempty" jsonschema:"description=Explicit toggle"`
+	Tags     []string       `json:"tags" jsonschema:"description=Optional tags"`
+	Inner    compactInner   `json:"inner" jsonschema:"description=Nested object"`
+	Children []compactInner `json:"children" jsonschema:"description=Nested list"`
+}
+
+func TestJSONOptionsMarshal(t *testing.T) {
+	disabled := false
+	sample := compactSample{
+		ID:       "tank",
+		Enabled:  &disabled,
+		Tags:     []string{},
+		Children: []compactInner{{Count: 0}, {Count: 2}},
+	}
+
+	tests := []struct {
+		name string
+		opts JSONOptions
+		want string
+	}{
+		{
+			name: "minify keeps every field",
+			opts: JSONOptions{Minify: true},
+			want: `{"id":"tank","tier":0,"name":"","rate":0,"flag":false,"enabled":false,"tags":[],"inner":{},"children":[{},{"count":2}]}`,
+		},
+		{
+			name: "prune keeps required and pointer fields in struct order",
+			opts: JSONOptions{Minify: true, PruneEmpty: true},
+			want: `{"id":"tank","tier":0,"enabled":false,"children":[{},{"count":2}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.opts.Marshal(sample)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal() = %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONOptionsDefaultMatchesMarshalIndent(t *testing.T) {
+	sample := compactSample{ID: "tank", Tier: 2, Name: "Ant <T1>"}
+
+	got, err := JSONOptions{}.Marshal(sample)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want, _ := json.MarshalIndent(sample, "", "  ")
+	if !bytes.Equal(got, want) {
+		t.Errorf("default Marshal() differs from MarshalIndent:\n%s\nwant\n%s", got, want)
+	}
+}
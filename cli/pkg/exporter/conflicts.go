@@ -0,0 +1,141 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// BuildConflictReport lists, per unit, every file (the unit JSON, its referenced specs and its
+// buildbar icon) that more than one of the loader's mods provides, with the JSON fields that
+// differ between the copies. Units without conflicts are left out.
+func BuildConflictReport(l *loader.Loader, mods []string, units []models.Unit) (models.ConflictReport, error) {
+	report := models.ConflictReport{Mods: mods, Units: []models.UnitConflicts{}}
+	for _, unit := range units {
+		specs, err := l.GetReferencedSpecFiles(unit.ResourceName, false)
+		if err != nil {
+			return report, fmt.Errorf("failed to collect spec files for %s: %w", unit.ID, err)
+		}
+		paths := make([]string, 0, len(specs)+1)
+		for p := range specs {
+			if p != unit.ResourceName {
+				paths = append(paths, p)
+			}
+		}
+		sort.Strings(paths)
+		icon := path.Join(path.Dir(unit.ResourceName), loader.IconName(strings.TrimSuffix(path.Base(unit.ResourceName), ".json")))
+		paths = append(append([]string{unit.ResourceName}, paths...), icon)
+
+		var files []models.FileConflict
+		for _, p := range paths {
+			sources, copies, err := l.ModCopies(p)
+			if err != nil {
+				return report, err
+			}
+			if len(sources) < 2 {
+				continue
+			}
+			files = append(files, models.FileConflict{
+				ResourcePath:    p,
+				Sources:         sources,
+				Identical:       identical(copies),
+				DifferingFields: differingFields(copies),
+			})
+		}
+		if files != nil {
+			report.Units = append(report.Units, models.UnitConflicts{Identifier: unit.ID, ResourceName: unit.ResourceName, Files: files})
+		}
+	}
+	return report, nil
+}
+
+// WriteConflictReport writes conflicts.json to a faction folder
+func WriteConflictReport(factionDir string, report models.ConflictReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal conflict report: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(factionDir, "conflicts.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write conflicts.json: %w", err)
+	}
+	return nil
+}
+
+func identical(copies [][]byte) bool {
+	for _, c := range copies[1:] {
+		if !bytes.Equal(c, copies[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// differingFields returns the dotted JSON fields whose values aren't the same in every copy.
+// Objects are compared field by field and anything else (arrays included) as a whole. Copies
+// that aren't all JSON, such as icons, give nil.
+func differingFields(copies [][]byte) []string {
+	values := make([]any, len(copies))
+	for i, c := range copies {
+		if err := json.Unmarshal(c, &values[i]); err != nil {
+			return nil
+		}
+	}
+	var fields []string
+	collectDifferences(values, "", &fields)
+	return fields
+}
+
+func collectDifferences(values []any, prefix string, fields *[]string) {
+	objects := make([]map[string]any, len(values))
+	allObjects := true
+	for i, v := range values {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			allObjects = false
+			break
+		}
+		objects[i] = obj
+	}
+
+	if !allObjects {
+		for _, v := range values[1:] {
+			if !reflect.DeepEqual(v, values[0]) {
+				name := strings.TrimSuffix(prefix, ".")
+				if name == "" {
+					name = "(root)"
+				}
+				*fields = append(*fields, name)
+				return
+			}
+		}
+		return
+	}
+
+	keySet := make(map[string]bool)
+	for _, obj := range objects {
+		for k := range obj {
+			keySet[k] = true
+		}
+	}
+	keys := make([]string, 0, len(keySet))
+	for k := range keySet {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		sub := make([]any, len(objects))
+		for i, obj := range objects {
+			sub[i] = obj[k] // nil when a copy lacks the field
+		}
+		collectDifferences(sub, prefix+k+".", fields)
+	}
+}
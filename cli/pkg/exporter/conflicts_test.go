@@ -0,0 +1,52 @@
+package exporter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDifferingFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		copies []string
+		want   []string
+	}{
+		{
+			name:   "same values",
+			copies: []string{`{"max_health": 100, "unit_types": ["A", "B"]}`, `{"unit_types": ["A", "B"], "max_health": 100}`},
+			want:   nil,
+		},
+		{
+			name:   "nested and missing fields",
+			copies: []string{`{"max_health": 100, "navigation": {"move_speed": 10, "brake": 5}}`, `{"max_health": 100, "navigation": {"move_speed": 12, "brake": 5}, "armor": 2}`},
+			want:   []string{"armor", "navigation.move_speed"},
+		},
+		{
+			name:   "arrays compare whole",
+			copies: []string{`{"tools": [{"spec_id": "a"}]}`, `{"tools": [{"spec_id": "a"}, {"spec_id": "b"}]}`, `{"tools": [{"spec_id": "a"}]}`},
+			want:   []string{"tools"},
+		},
+		{
+			name:   "object replaced by value",
+			copies: []string{`{"recon": {"radius": 5}}`, `{"recon": 5}`},
+			want:   []string{"recon"},
+		},
+		{
+			name:   "not JSON",
+			copies: []string{"\x89PNG one", "\x89PNG two"},
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			copies := make([][]byte, len(tt.copies))
+			for i, c := range tt.copies {
+				copies[i] = []byte(c)
+			}
+			if got := differingFields(copies); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("differingFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
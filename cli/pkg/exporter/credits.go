@@ -0,0 +1,149 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// Base game sources have no modinfo.json; they're credited with these
+var baseGameCredits = []models.CreditEntry{
+	{Source: "pa_ex1", Name: "Planetary Annihilation: TITANS", Author: "Uber Entertainment", URL: "https://planetaryannihilation.com"},
+	{Source: "pa", Name: "Planetary Annihilation", Author: "Uber Entertainment", URL: "https://planetaryannihilation.com"},
+}
+
+// BuildCredits credits every mod (in priority order, whether or not its files ended up in the
+// index) and then the base game sources that provided files. Units whose files weren't copied
+// (--no-assets) are credited to the source l resolves their unit JSON to; l may be nil in
+// tests. paBuild versions the base game entries.
+func BuildCredits(l *loader.Loader, factionName string, mods []*loader.ModInfo, index *models.FactionIndex, paBuild string) models.Credits {
+	units := make(map[string]int)
+	for _, entry := range index.Units {
+		// entry.Source is only a path heuristic for modded units, so trust the copied files
+		sources := make(map[string]bool)
+		for _, f := range entry.Files {
+			if !f.Generated {
+				sources[f.Source] = true
+			}
+		}
+		if len(sources) == 0 {
+			source := entry.Source
+			if l != nil {
+				if info := l.ResolveResource(entry.Unit.ResourceName); info != nil {
+					source = info.Source
+				}
+			}
+			sources[source] = true
+		}
+		for source := range sources {
+			units[source]++
+		}
+	}
+
+	credits := models.Credits{Faction: factionName, Sources: []models.CreditEntry{}}
+	for _, mod := range mods {
+		name := mod.DisplayName
+		if name == "" {
+			name = mod.Identifier
+		}
+		credits.Sources = append(credits.Sources, models.CreditEntry{
+			Source:  mod.Identifier,
+			Name:    name,
+			Author:  mod.Author,
+			Version: mod.Version,
+			License: mod.License,
+			URL:     mod.Forum,
+			Units:   units[mod.Identifier],
+		})
+	}
+	for _, base := range baseGameCredits {
+		if units[base.Source] == 0 {
+			continue
+		}
+		base.Version = paBuild
+		base.Units = units[base.Source]
+		credits.Sources = append(credits.Sources, base)
+	}
+	return credits
+}
+
+// MergeCredits credits a faction assembled from other exports (see addon.Apply): the mods of
+// each credits file in order, keeping the first entry per mod, then the base game sources,
+// with unit counts taken from index.
+func MergeCredits(factionName string, credits []models.Credits, index *models.FactionIndex, paBuild string) models.Credits {
+	var mods []*loader.ModInfo
+	seen := make(map[string]bool)
+	for _, c := range credits {
+		for _, entry := range c.Sources {
+			if seen[entry.Source] || isBaseGameSource(entry.Source) {
+				continue
+			}
+			seen[entry.Source] = true
+			mods = append(mods, &loader.ModInfo{
+				Identifier:  entry.Source,
+				DisplayName: entry.Name,
+				Author:      entry.Author,
+				Version:     entry.Version,
+				License:     entry.License,
+				Forum:       entry.URL,
+			})
+		}
+	}
+	return BuildCredits(nil, factionName, mods, index, paBuild)
+}
+
+func isBaseGameSource(source string) bool {
+	for _, base := range baseGameCredits {
+		if base.Source == source {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteCredits writes CREDITS.json and a readable CREDITS.md to a faction folder
+func WriteCredits(factionDir string, credits models.Credits) error {
+	data, err := json.MarshalIndent(credits, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal credits: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(factionDir, "CREDITS.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write CREDITS.json: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(factionDir, "CREDITS.md"), []byte(creditsMarkdown(credits)), 0644); err != nil {
+		return fmt.Errorf("failed to write CREDITS.md: %w", err)
+	}
+	return nil
+}
+
+// creditsMarkdown renders credits as a section per source
+func creditsMarkdown(credits models.Credits) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Credits: %s\n\n", credits.Faction)
+	b.WriteString("This folder contains data extracted from the game and mods below. Their authors keep all rights to it; check each license before redistributing.\n")
+	for _, c := range credits.Sources {
+		fmt.Fprintf(&b, "\n## %s\n\n", c.Name)
+		fmt.Fprintf(&b, "- Source: `%s`\n", c.Source)
+		if c.Author != "" {
+			fmt.Fprintf(&b, "- Author: %s\n", c.Author)
+		}
+		if c.Version != "" {
+			fmt.Fprintf(&b, "- Version: %s\n", c.Version)
+		}
+		if c.License != "" {
+			fmt.Fprintf(&b, "- License: %s\n", c.License)
+		} else {
+			b.WriteString("- License: not stated; ask the author before redistributing\n")
+		}
+		if c.URL != "" {
+			fmt.Fprintf(&b, "- Link: %s\n", c.URL)
+		}
+		fmt.Fprintf(&b, "- Units: %d\n", c.Units)
+	}
+	return b.String()
+}
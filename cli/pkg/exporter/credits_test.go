@@ -0,0 +1,113 @@
+package exporter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestBuildCredits(t *testing.T) {
+	mods := []*loader.ModInfo{
+		{Identifier: "com.test.units", DisplayName: "Test Units", Author: "Modder", Version: "1.2", License: "CC BY 4.0", Forum: "https://example.com/units"},
+		{Identifier: "com.test.balance", Author: "Balancer"},
+	}
+	index := &models.FactionIndex{Units: []models.UnitIndexEntry{
+		// Mod unit whose entry source is the path heuristic; its files say otherwise
+		{Identifier: "tank", Source: "pa", Files: []models.UnitFile{
+			{Path: "pa/units/land/tank/tank.json", Source: "com.test.units"},
+			{Path: "pa/units/land/tank/tank_icon_buildbar.png", Source: "pa"},
+		}},
+		{Identifier: "bot", Source: "pa", Files: []models.UnitFile{
+			{Path: "pa/units/land/bot/bot.json", Source: "pa"},
+			{Path: "pa/units/land/bot/bot_icon_buildbar.png", Source: PlaceholderIconSource, Generated: true},
+		}},
+		{Identifier: "titan", Source: "pa_ex1"},
+	}}
+
+	credits := BuildCredits(nil, "Test Faction", mods, index, "123456")
+
+	want := []struct {
+		source  string
+		name    string
+		version string
+		units   int
+	}{
+		{"com.test.units", "Test Units", "1.2", 1},
+		{"com.test.balance", "com.test.balance", "", 0},
+		{"pa_ex1", "Planetary Annihilation: TITANS", "123456", 1},
+		{"pa", "Planetary Annihilation", "123456", 2},
+	}
+	if len(credits.Sources) != len(want) {
+		t.Fatalf("got %d credited sources, want %d: %+v", len(credits.Sources), len(want), credits.Sources)
+	}
+	for i, w := range want {
+		got := credits.Sources[i]
+		if got.Source != w.source || got.Name != w.name || got.Version != w.version || got.Units != w.units {
+			t.Errorf("source %d = %+v, want source %s name %q version %q units %d", i, got, w.source, w.name, w.version, w.units)
+		}
+	}
+	if credits.Sources[0].License != "CC BY 4.0" || credits.Sources[0].URL != "https://example.com/units" {
+		t.Errorf("mod license and forum not carried over: %+v", credits.Sources[0])
+	}
+}
+
+func TestCreditsMarkdown(t *testing.T) {
+	md := creditsMarkdown(models.Credits{Faction: "Test Faction", Sources: []models.CreditEntry{
+		{Source: "com.test.units", Name: "Test Units", Author: "Modder", License: "CC BY 4.0", Units: 3},
+		{Source: "com.test.balance", Name: "Balance", Units: 0},
+	}})
+
+	for _, want := range []string{
+		"# Credits: Test Faction",
+		"## Test Units",
+		"- Author: Modder",
+		"- License: CC BY 4.0",
+		"- Units: 3",
+		"## Balance",
+		"- License: not stated; ask the author before redistributing",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestMergeCredits(t *testing.T) {
+	base := models.Credits{Faction: "Legion", Sources: []models.CreditEntry{
+		{Source: "com.pa.legion-expansion", Name: "Legion", License: "CC BY 4.0", Units: 90},
+		{Source: "pa", Name: "Planetary Annihilation", Version: "100", Units: 30},
+	}}
+	addon := models.Credits{Faction: "Second Wave", Sources: []models.CreditEntry{
+		{Source: "com.pa.second-wave", Name: "Second Wave", Author: "Addon Author", Units: 2},
+		{Source: "com.pa.legion-expansion", Name: "Legion (old)", Units: 1},
+	}}
+	index := &models.FactionIndex{Units: []models.UnitIndexEntry{
+		{Identifier: "l_tank", Source: "com.pa.legion-expansion"},
+		{Identifier: "sw_tank", Source: "com.pa.second-wave"},
+		{Identifier: "tank", Source: "pa"},
+	}}
+
+	merged := MergeCredits("Legion + Second Wave", []models.Credits{base, addon}, index, "123")
+	want := []struct {
+		source, name string
+		units        int
+	}{
+		{"com.pa.legion-expansion", "Legion", 1},
+		{"com.pa.second-wave", "Second Wave", 1},
+		{"pa", "Planetary Annihilation", 1},
+	}
+	if len(merged.Sources) != len(want) {
+		t.Fatalf("got %d credited sources, want %d: %+v", len(merged.Sources), len(want), merged.Sources)
+	}
+	for i, w := range want {
+		got := merged.Sources[i]
+		if got.Source != w.source || got.Name != w.name || got.Units != w.units {
+			t.Errorf("source %d = %+v, want source %s name %q units %d", i, got, w.source, w.name, w.units)
+		}
+	}
+	if merged.Sources[1].Author != "Addon Author" || merged.Sources[2].Version != "123" {
+		t.Errorf("author or base game version not carried over: %+v", merged.Sources)
+	}
+}
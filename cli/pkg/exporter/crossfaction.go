@@ -0,0 +1,28 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// WriteCrossFactionLinks writes cross-faction.json to an addon's faction folder
+func WriteCrossFactionLinks(factionDir string, links models.CrossFactionLinks) error {
+	if links.BaseFactions == nil {
+		links.BaseFactions = []string{}
+	}
+	if links.Edges == nil {
+		links.Edges = []models.CrossFactionEdge{}
+	}
+	data, err := json.MarshalIndent(links, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cross-faction links: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(factionDir, "cross-faction.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write cross-faction.json: %w", err)
+	}
+	return nil
+}
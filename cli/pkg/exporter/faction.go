@@ -1,15 +1,21 @@
 package exporter
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
 	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/parallel"
+	"github.com/jamiemulcahy/pa-pedia/pkg/protoindex"
 )
 
 // FactionExporter handles exporting faction data to the Phase 1.5 structure
@@ -17,18 +23,55 @@ type FactionExporter struct {
 	OutputDir string
 	Loader    *loader.Loader
 	Verbose   bool
+	Layout    Layout       // Where unit files go; defaults to MirroredLayout
+	Index     IndexOptions // Index files to write; defaults to pretty-printed units.json
+	// Silhouettes is the edge in pixels of top and side silhouettes rendered from unit
+	// models (see SilhouetteName); 0 renders none, as does Assets AssetsNone
+	Silhouettes int
+	// Parallelism is how many of a unit's spec files are copied at once, each also holding
+	// a loader IO slot (see loader.SetIOLimit); 0 or 1 copies them one at a time
+	Parallelism int
+	// Assets leaves game files out of the export (AssetsIconsStripped, AssetsNone); empty
+	// copies everything
+	Assets string
+	// Credits writes CREDITS.json and CREDITS.md (see BuildCredits), crediting Mods and the
+	// base game sources units came from
+	Credits bool
+	Mods    []*loader.ModInfo
+	// ZipFinish runs during ExportFactionZip once the unit files are in the archive, on the
+	// staging folder holding the top-level files; whatever it writes there is archived too
+	ZipFinish func(factionDir string) error
 }
 
-// NewFactionExporter creates a new faction exporter
+var _ Exporter = (*FactionExporter)(nil)
+
+// NewFactionExporter creates a new faction exporter using the mirrored assets layout
 func NewFactionExporter(outputDir string, l *loader.Loader, verbose bool) *FactionExporter {
 	return &FactionExporter{
 		OutputDir: outputDir,
 		Loader:    l,
 		Verbose:   verbose,
+		Layout:    MirroredLayout{},
+	}
+}
+
+// NewExporter creates an exporter for the named layout (see ParseLayout) that
+// writes its index files with the given options
+func NewExporter(layout string, index IndexOptions, outputDir string, l *loader.Loader, verbose bool) (Exporter, error) {
+	lay, err := ParseLayout(layout)
+	if err != nil {
+		return nil, err
+	}
+	if index.Format, err = ParseIndexFormat(index.Format); err != nil {
+		return nil, err
 	}
+	e := NewFactionExporter(outputDir, l, verbose)
+	e.Layout = lay
+	e.Index = index
+	return e, nil
 }
 
-// ExportFaction exports a faction using the new assets structure
+// ExportFaction exports a faction using the configured layout
 func (e *FactionExporter) ExportFaction(metadata models.FactionMetadata, units []models.Unit) error {
 	// Create faction folder
 	factionDir := filepath.Join(e.OutputDir, SanitizeFolderName(metadata.DisplayName))
@@ -41,43 +84,80 @@ func (e *FactionExporter) ExportFaction(metadata models.FactionMetadata, units [
 		return fmt.Errorf("failed to create faction directory: %w", err)
 	}
 
-	// Create assets subdirectory
-	assetsDir := filepath.Join(factionDir, "assets")
-	if err := os.MkdirAll(assetsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create assets directory: %w", err)
+	if e.Layout == nil {
+		e.Layout = MirroredLayout{}
 	}
 
-	// Write metadata.json
-	if err := e.writeMetadata(factionDir, metadata); err != nil {
-		return fmt.Errorf("failed to write metadata: %w", err)
+	// Create unit files subdirectory (assets/ for mirrored, units/ for flat)
+	if err := os.MkdirAll(filepath.Join(factionDir, e.Layout.Dir()), 0755); err != nil {
+		return fmt.Errorf("failed to create %s directory: %w", e.Layout.Dir(), err)
+	}
+
+	index, err := e.writeFaction(factionDir, dirSink{root: factionDir}, metadata, units)
+	if err != nil {
+		return err
+	}
+
+	if e.Verbose {
+		fmt.Printf("Successfully exported faction to %s\n", factionDir)
+		fmt.Printf("  - Metadata: metadata.json\n")
+		fmt.Printf("  - Index: %d units in units.json\n", len(index.Units))
+		if e.Credits {
+			fmt.Printf("  - Credits: CREDITS.json, CREDITS.md\n")
+		}
+		fmt.Printf("  - Assets: %s layout in %s/\n", e.Layout.Name(), e.Layout.Dir())
+	}
+
+	return nil
+}
+
+// writeFaction writes the unit files to files and the top-level files (units.json,
+// metadata.json, credits) to factionDir, returning the index
+func (e *FactionExporter) writeFaction(factionDir string, files fileSink, metadata models.FactionMetadata, units []models.Unit) (*models.FactionIndex, error) {
+	if e.Assets != "" {
+		metadata.Assets = e.Assets
+		metadata.BackgroundImage = "" // Mod art, not copied
 	}
 
 	// Build lightweight index and export unit files to assets
 	// For addon mods, skip base game spec files (they're not part of the addon)
-	index, err := e.exportUnitsToAssets(assetsDir, units, metadata.IsAddon)
+	index, err := e.exportUnitsToAssets(files, units, metadata.IsAddon)
 	if err != nil {
-		return fmt.Errorf("failed to export units: %w", err)
+		return nil, fmt.Errorf("failed to export units: %w", err)
 	}
+	index.Layout = e.Layout.Name()
 
 	// Write lightweight units.json index
 	if err := e.writeIndex(factionDir, index); err != nil {
-		return fmt.Errorf("failed to write index: %w", err)
+		return nil, fmt.Errorf("failed to write index: %w", err)
 	}
 
-	if e.Verbose {
-		fmt.Printf("Successfully exported faction to %s\n", factionDir)
-		fmt.Printf("  - Metadata: metadata.json\n")
-		fmt.Printf("  - Index: %d units in units.json\n", len(index.Units))
-		fmt.Printf("  - Assets: mirrored PA structure in assets/\n")
+	// Write metadata.json, once the index it hashes is known
+	if metadata.ContentHash, err = IndexContentHash(index); err != nil {
+		return nil, err
+	}
+	if err := e.writeMetadata(factionDir, metadata); err != nil {
+		return nil, fmt.Errorf("failed to write metadata: %w", err)
 	}
 
-	return nil
+	if e.Credits {
+		credits := BuildCredits(e.Loader, metadata.DisplayName, e.Mods, index, metadata.PABuild)
+		if err := WriteCredits(factionDir, credits); err != nil {
+			return nil, err
+		}
+	}
+	return index, nil
+}
+
+// unitFilePath is the path of a unit file relative to the faction folder
+func (e *FactionExporter) unitFilePath(assetPath string) string {
+	return e.Layout.Dir() + "/" + assetPath
 }
 
-// exportUnitsToAssets exports all unit files and referenced specs to assets folder
-// Uses PA path structure (e.g., assets/pa/units/land/tank/tank.json)
+// exportUnitsToAssets exports all unit files and referenced specs to the layout's folder
+// (e.g., assets/pa/units/land/tank/tank.json mirrored, units/tank/tank.json flat)
 // When isAddon is true, only spec files from mod sources are exported (base game specs are skipped)
-func (e *FactionExporter) exportUnitsToAssets(assetsDir string, units []models.Unit, isAddon bool) (*models.FactionIndex, error) {
+func (e *FactionExporter) exportUnitsToAssets(files fileSink, units []models.Unit, isAddon bool) (*models.FactionIndex, error) {
 	index := &models.FactionIndex{
 		Units: make([]models.UnitIndexEntry, 0, len(units)),
 	}
@@ -90,6 +170,19 @@ func (e *FactionExporter) exportUnitsToAssets(assetsDir string, units []models.U
 	// Track skipped base game specs for addon export summary
 	skippedBaseGameSpecs := 0
 
+	// Units given a placeholder icon because no source had one
+	generatedIcons := 0
+
+	// Units with silhouettes rendered from their model
+	silhouettes := 0
+
+	// Build menus and assist economy only reference units in this export
+	byID := make(map[string]*models.Unit, len(units))
+	for i := range units {
+		byID[units[i].ID] = &units[i]
+	}
+	factories := AssistFactories(units)
+
 	for i, unit := range units {
 		// Report progress at 10% intervals or on completion for smoother feedback
 		if e.Verbose {
@@ -102,20 +195,28 @@ func (e *FactionExporter) exportUnitsToAssets(assetsDir string, units []models.U
 		}
 
 		// Collect all referenced spec files for this unit
-		specFiles, err := e.Loader.GetReferencedSpecFiles(unit.ResourceName, e.Verbose)
-		if err != nil {
-			if e.Verbose {
-				fmt.Fprintf(os.Stderr, "\nWarning: Failed to collect spec files for %s: %v\n", unit.ID, err)
+		var specFiles map[string]*loader.SpecFileInfo
+		if e.Assets != AssetsNone {
+			var err error
+			specFiles, err = e.Loader.GetReferencedSpecFiles(unit.ResourceName, e.Verbose)
+			if err != nil {
+				if e.Verbose {
+					fmt.Fprintf(os.Stderr, "\nWarning: Failed to collect spec files for %s: %v\n", unit.ID, err)
+				}
 			}
 		}
 
-		// Also get unit files (for icon)
-		unitFiles, err := e.Loader.GetAllFilesForUnit(unit.ResourceName)
-		if err != nil {
-			if e.Verbose {
-				fmt.Fprintf(os.Stderr, "\nWarning: Failed to discover files for %s: %v\n", unit.ID, err)
+		// Also get unit files (for icon); stripped exports use placeholders instead
+		unitFiles := make(map[string]*loader.UnitFileInfo)
+		if e.Assets == "" {
+			files, err := e.Loader.GetAllFilesForUnit(unit.ResourceName)
+			if err != nil {
+				if e.Verbose {
+					fmt.Fprintf(os.Stderr, "\nWarning: Failed to discover files for %s: %v\n", unit.ID, err)
+				}
+			} else {
+				unitFiles = files
 			}
-			unitFiles = make(map[string]*loader.UnitFileInfo)
 		}
 
 		// Track files for this unit's index entry
@@ -123,10 +224,22 @@ func (e *FactionExporter) exportUnitsToAssets(assetsDir string, units []models.U
 		primaryJSONFound := false
 		iconFound := false
 
-		// Copy all spec files to assets with PA path structure
+		// Copy all spec files to the layout's folder: decide what to copy, copy concurrently,
+		// then record the results in order
+		type specCopy struct {
+			assetPath string
+			specInfo  *loader.SpecFileInfo
+			primary   bool // The unit's own JSON
+		}
+		var copies []specCopy
+		resourcePaths := make([]string, 0, len(specFiles))
+		for resourcePath := range specFiles {
+			resourcePaths = append(resourcePaths, resourcePath)
+		}
+		// Convert resource paths to asset paths (e.g., /pa/units/land/tank/tank.json -> pa/units/land/tank/tank.json)
+		assetPaths := AssetPaths(e.Layout, unit.ID, unit.ResourceName, resourcePaths)
 		for resourcePath, specInfo := range specFiles {
-			// Convert resource path to assets path (e.g., /pa/units/land/tank/tank.json -> pa/units/land/tank/tank.json)
-			assetPath := strings.TrimPrefix(resourcePath, "/")
+			assetPath := assetPaths[resourcePath]
 
 			// For addon mods, skip spec files from base game sources
 			if shouldSkipSpecFileForAddon(isAddon, resourcePath, unit.ResourceName, specInfo) {
@@ -147,21 +260,18 @@ func (e *FactionExporter) exportUnitsToAssets(assetsDir string, units []models.U
 				continue
 			}
 
-			// Create destination path
-			destPath := filepath.Join(assetsDir, filepath.FromSlash(assetPath))
-
-			// Ensure directory exists
-			if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
-				if e.Verbose {
-					fmt.Fprintf(os.Stderr, "\nWarning: Failed to create directory for %s: %v\n", assetPath, err)
-				}
-				continue
-			}
+			copies = append(copies, specCopy{assetPath, specInfo, resourcePath == unit.ResourceName})
+		}
 
-			// Copy the file
-			if err := e.copySpecFile(specInfo, destPath); err != nil {
+		copyErrs := make([]error, len(copies))
+		parallel.ForEach(len(copies), e.Parallelism, func(i int) {
+			copyErrs[i] = e.copySpecFile(copies[i].specInfo, files, e.unitFilePath(copies[i].assetPath))
+		})
+		for i, c := range copies {
+			assetPath, specInfo := c.assetPath, c.specInfo
+			if err := copyErrs[i]; err != nil {
 				// Check if this is the primary unit JSON
-				if resourcePath == unit.ResourceName {
+				if c.primary {
 					fmt.Fprintf(os.Stderr, "\nError: Failed to copy primary file for unit %s: %v\n", unit.ID, err)
 					criticalFailures = append(criticalFailures, unit.ID)
 				} else if e.Verbose {
@@ -173,7 +283,7 @@ func (e *FactionExporter) exportUnitsToAssets(assetsDir string, units []models.U
 			copiedAssets[assetPath] = true
 
 			// Track primary JSON for this unit
-			if resourcePath == unit.ResourceName {
+			if c.primary {
 				primaryJSONFound = true
 				indexFiles = append(indexFiles, models.UnitFile{
 					Path:   assetPath,
@@ -191,8 +301,8 @@ func (e *FactionExporter) exportUnitsToAssets(assetsDir string, units []models.U
 			}
 
 			// Determine asset path for icon - use same directory as unit JSON
-			unitDir := strings.TrimPrefix(filepath.ToSlash(filepath.Dir(unit.ResourceName)), "/")
-			assetPath := filepath.ToSlash(filepath.Join(unitDir, filename))
+			unitDir := filepath.ToSlash(filepath.Dir(unit.ResourceName))
+			assetPath := e.Layout.AssetPath(unit.ID, unitDir+"/"+filename)
 
 			// Skip if already copied
 			if copiedAssets[assetPath] {
@@ -202,18 +312,9 @@ func (e *FactionExporter) exportUnitsToAssets(assetsDir string, units []models.U
 				continue
 			}
 
-			destPath := filepath.Join(assetsDir, filepath.FromSlash(assetPath))
-
-			// Ensure directory exists
-			if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
-				if e.Verbose {
-					fmt.Fprintf(os.Stderr, "\nWarning: Failed to create directory for icon %s: %v\n", assetPath, err)
-				}
-				continue
-			}
-
 			// Copy icon file
-			if err := e.copyFile(fileInfo, filepath.Dir(destPath)); err != nil {
+			destPath := path.Join(path.Dir(e.unitFilePath(assetPath)), fileInfo.RelativePath)
+			if err := e.copyFile(fileInfo, files, destPath); err != nil {
 				if e.Verbose {
 					fmt.Fprintf(os.Stderr, "\nWarning: Failed to copy icon %s for unit %s: %v\n", filename, unit.ID, err)
 				}
@@ -229,20 +330,61 @@ func (e *FactionExporter) exportUnitsToAssets(assetsDir string, units []models.U
 			})
 		}
 
+		// No art in any source: generate a placeholder so the web app never shows a
+		// broken image
+		if !iconFound {
+			unitDir := filepath.ToSlash(filepath.Dir(unit.ResourceName))
+			assetPath := e.Layout.AssetPath(unit.ID, unitDir+"/"+loader.IconName(unit.ID))
+			if err := e.writePlaceholderIcon(unit, files, e.unitFilePath(assetPath)); err != nil {
+				if e.Verbose {
+					fmt.Fprintf(os.Stderr, "\nWarning: Failed to generate icon for unit %s: %v\n", unit.ID, err)
+				}
+			} else {
+				copiedAssets[assetPath] = true
+				iconFound = true
+				iconAssetPath = assetPath
+				generatedIcons++
+				indexFiles = append(indexFiles, models.UnitFile{
+					Path:      assetPath,
+					Source:    PlaceholderIconSource,
+					Generated: true,
+				})
+			}
+		}
+
+		if e.Silhouettes > 0 && e.Assets != AssetsNone {
+			rendered, err := e.writeSilhouettes(unit, files)
+			if err != nil {
+				if e.Verbose {
+					fmt.Fprintf(os.Stderr, "\nWarning: Failed to render silhouettes for unit %s: %v\n", unit.ID, err)
+				}
+			} else if len(rendered) > 0 {
+				silhouettes++
+				indexFiles = append(indexFiles, rendered...)
+			}
+		}
+
 		// Warn if primary JSON wasn't found
-		if !primaryJSONFound {
+		if !primaryJSONFound && e.Assets != AssetsNone {
 			fmt.Fprintf(os.Stderr, "\nWarning: Primary file not found for unit %s\n", unit.ID)
 		}
 
 		// Only set unit image path if an icon was actually found and copied
 		// Use the actual icon filename, not a constructed one based on unit ID
 		if iconFound && iconAssetPath != "" {
-			unit.Image = filepath.ToSlash(filepath.Join("assets", iconAssetPath))
+			unit.Image = filepath.ToSlash(filepath.Join(e.Layout.Dir(), iconAssetPath))
 		} else {
 			// Clear any default image path since no icon exists
 			unit.Image = ""
 		}
 
+		unit.BuildMenu = BuildMenu(unit.BuildRelationships.Builds, byID)
+		if assists := AssistEconomy(&unit, factories); assists != nil {
+			economy := *unit.Specs.Economy // Copy so the caller's unit isn't modified
+			economy.Assist = assists
+			unit.Specs.Economy = &economy
+		}
+
 		// Create index entry with embedded unit data
 		indexEntry := models.UnitIndexEntry{
 			Identifier:  unit.ID,
@@ -251,6 +393,7 @@ func (e *FactionExporter) exportUnitsToAssets(assetsDir string, units []models.U
 			Source:      determineUnitSource(unit.ResourceName),
 			Files:       indexFiles,
 			Unit:        unit,
+			Warnings:    unit.Warnings,
 		}
 
 		index.Units = append(index.Units, indexEntry)
@@ -259,6 +402,14 @@ func (e *FactionExporter) exportUnitsToAssets(assetsDir string, units []models.U
 	if e.Verbose {
 		fmt.Println() // New line after progress indicator
 		fmt.Printf("  Total unique assets copied: %d\n", len(copiedAssets))
+		if e.Assets != "" {
+			fmt.Printf("  Game assets left out (%s); generated placeholder icons for %d units\n", e.Assets, generatedIcons)
+		} else if generatedIcons > 0 {
+			fmt.Printf("  Generated placeholder icons for %d units without buildbar icons\n", generatedIcons)
+		}
+		if silhouettes > 0 {
+			fmt.Printf("  Rendered silhouettes for %d units\n", silhouettes)
+		}
 		if isAddon && skippedBaseGameSpecs > 0 {
 			fmt.Printf("  Skipped %d base game spec files (addon export only includes mod content)\n", skippedBaseGameSpecs)
 		}
@@ -276,8 +427,23 @@ func (e *FactionExporter) exportUnitsToAssets(assetsDir string, units []models.U
 	return index, nil
 }
 
-// copySpecFile copies a spec file from source to destination
-func (e *FactionExporter) copySpecFile(specInfo *loader.SpecFileInfo, destPath string) error {
+// writePlaceholderIcon writes a generated icon (see PlaceholderIcon) for a unit
+func (e *FactionExporter) writePlaceholderIcon(unit models.Unit, files fileSink, rel string) error {
+	name := unit.DisplayName
+	if name == "" {
+		name = unit.ID
+	}
+	data, err := PlaceholderIcon(name, unit.Tier)
+	if err != nil {
+		return err
+	}
+	return writeSinkFile(files, rel, data)
+}
+
+// copySpecFile copies a spec file from its source to rel in files
+func (e *FactionExporter) copySpecFile(specInfo *loader.SpecFileInfo, files fileSink, rel string) error {
+	defer e.Loader.AcquireIO()()
+	start := time.Now()
 	if specInfo.IsFromZip {
 		// Find the source in the loader
 		var source *loader.Source
@@ -317,34 +483,51 @@ func (e *FactionExporter) copySpecFile(specInfo *loader.SpecFileInfo, destPath s
 		}
 		defer rc.Close()
 
-		destFile, err := os.Create(destPath)
+		n, err := copyToSink(files, rel, rc)
 		if err != nil {
-			return fmt.Errorf("failed to create destination file: %w", err)
-		}
-		defer destFile.Close()
-
-		if _, err := io.Copy(destFile, rc); err != nil {
-			return fmt.Errorf("failed to copy file data: %w", err)
+			return err
 		}
+		e.Loader.RecordRead(specInfo.Source, n, time.Since(start))
 
 		return nil
 	}
 
 	// Copy from filesystem
-	return e.copyFromFilesystem(specInfo.FullPath, destPath)
+	n, err := copyFileToSink(specInfo.FullPath, files, rel)
+	if err != nil {
+		return err
+	}
+	e.Loader.RecordRead(specInfo.Source, n, time.Since(start))
+	return nil
 }
 
-// copyFile copies a unit file from source to destination
-func (e *FactionExporter) copyFile(fileInfo *loader.UnitFileInfo, destDir string) error {
-	destPath := filepath.Join(destDir, fileInfo.RelativePath)
+// copyFile copies a unit file from its source to rel in files
+func (e *FactionExporter) copyFile(fileInfo *loader.UnitFileInfo, files fileSink, rel string) error {
+	if fileInfo.Atlas != nil {
+		// Cropped from an icon atlas
+		data, err := e.Loader.ReadUnitFile(fileInfo)
+		if err != nil {
+			return err
+		}
+		return writeSinkFile(files, rel, data)
+	}
 
+	defer e.Loader.AcquireIO()()
+	start := time.Now()
+	var n int64
+	var err error
 	if fileInfo.IsFromZip {
 		// Copy from zip file
-		return e.copyFromZip(fileInfo, destPath)
+		n, err = e.copyFromZip(fileInfo, files, rel)
+	} else {
+		// Copy from filesystem
+		n, err = copyFileToSink(fileInfo.FullPath, files, rel)
 	}
-
-	// Copy from filesystem
-	return e.copyFromFilesystem(fileInfo.FullPath, destPath)
+	if err != nil {
+		return err
+	}
+	e.Loader.RecordRead(fileInfo.Source, n, time.Since(start))
+	return nil
 }
 
 // Security limits for zip extraction to prevent zip bomb attacks
@@ -361,8 +544,8 @@ const (
 	maxTotalSize = 500 * 1024 * 1024 // 500MB total (tracked elsewhere if needed)
 )
 
-// copyFromZip extracts a file from a zip archive
-func (e *FactionExporter) copyFromZip(fileInfo *loader.UnitFileInfo, destPath string) error {
+// copyFromZip extracts a file from a zip archive to rel in files, returning its size
+func (e *FactionExporter) copyFromZip(fileInfo *loader.UnitFileInfo, files fileSink, rel string) (int64, error) {
 	// Find the source in the loader
 	var source *loader.Source
 	for _, src := range e.Loader.Sources() {
@@ -374,7 +557,7 @@ func (e *FactionExporter) copyFromZip(fileInfo *loader.UnitFileInfo, destPath st
 	}
 
 	if source == nil || source.ZipReader == nil {
-		return fmt.Errorf("zip reader not found for source %s", fileInfo.Source)
+		return 0, fmt.Errorf("zip reader not found for source %s", fileInfo.Source)
 	}
 
 	// Normalize paths for comparison
@@ -390,43 +573,36 @@ func (e *FactionExporter) copyFromZip(fileInfo *loader.UnitFileInfo, destPath st
 	// Use zip index for O(1) lookup instead of O(n) scan
 	file, found := source.ZipIndex()[normalizedFullPath]
 	if !found {
-		return fmt.Errorf("file not found in zip: %s", fileInfo.FullPath)
+		return 0, fmt.Errorf("file not found in zip: %s", fileInfo.FullPath)
 	}
 
 	// Validate path to prevent path traversal attacks
 	if strings.Contains(file.Name, "..") {
-		return fmt.Errorf("invalid path in zip (contains ..): %s", file.Name)
+		return 0, fmt.Errorf("invalid path in zip (contains ..): %s", file.Name)
 	}
 
 	// Check file size to prevent zip bomb attacks
 	if file.UncompressedSize64 > maxFileSize {
-		return fmt.Errorf("file too large: %s (%d bytes, max %d bytes)", file.Name, file.UncompressedSize64, maxFileSize)
+		return 0, fmt.Errorf("file too large: %s (%d bytes, max %d bytes)", file.Name, file.UncompressedSize64, maxFileSize)
 	}
 
-	// Use anonymous function to ensure deferred closes happen immediately
-	err := func() error {
-		rc, err := file.Open()
-		if err != nil {
-			return fmt.Errorf("failed to open file in zip: %w", err)
-		}
-		defer rc.Close()
-
-		// Create destination file
-		destFile, err := os.Create(destPath)
-		if err != nil {
-			return fmt.Errorf("failed to create destination file: %w", err)
-		}
-		defer destFile.Close()
-
-		// Copy data
-		if _, err := io.Copy(destFile, rc); err != nil {
-			return fmt.Errorf("failed to copy file data: %w", err)
-		}
+	rc, err := file.Open()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file in zip: %w", err)
+	}
+	defer rc.Close()
 
-		return nil
-	}()
+	return copyToSink(files, rel, rc)
+}
 
-	return err
+// copyFileToSink copies a file from the filesystem to rel in files, returning its size
+func copyFileToSink(srcPath string, files fileSink, rel string) (int64, error) {
+	srcFile, err := os.Open(srcPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer srcFile.Close()
+	return copyToSink(files, rel, srcFile)
 }
 
 // copyFromFilesystem copies a file from the filesystem
@@ -541,8 +717,22 @@ func (e *FactionExporter) CopyResourceToFile(resourcePath, destPath string) erro
 
 // writeMetadata writes the metadata.json file
 func (e *FactionExporter) writeMetadata(factionDir string, metadata models.FactionMetadata) error {
+	if err := WriteFactionMetadata(factionDir, metadata); err != nil {
+		return err
+	}
+
+	if e.Verbose {
+		fmt.Printf("  ✓ Wrote metadata.json\n")
+	}
+
+	return nil
+}
+
+// WriteFactionMetadata writes (or rewrites) metadata.json in a faction folder.
+func WriteFactionMetadata(factionDir string, metadata models.FactionMetadata) error {
 	metadataPath := filepath.Join(factionDir, "metadata.json")
 
+	metadata.Schema = models.SchemaURL("faction-metadata")
 	data, err := json.MarshalIndent(metadata, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
@@ -552,28 +742,69 @@ func (e *FactionExporter) writeMetadata(factionDir string, metadata models.Facti
 		return fmt.Errorf("failed to write metadata file: %w", err)
 	}
 
-	if e.Verbose {
-		fmt.Printf("  ✓ Wrote metadata.json\n")
-	}
+	return nil
+}
 
+// WriteFactionIndex writes (or rewrites) units.json in a faction folder.
+func WriteFactionIndex(factionDir string, index *models.FactionIndex, opts JSONOptions) error {
+	stamped := *index
+	stamped.Schema = models.SchemaURL("faction-index")
+	data, err := opts.Marshal(&stamped)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(factionDir, "units.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write index file: %w", err)
+	}
 	return nil
 }
 
+// IndexContentHash returns the hex SHA-256 of an index in canonical form: compact JSON
+// without $schema, pruning or indentation, so it identifies the units whatever JSONOptions
+// units.json was written with
+func IndexContentHash(index *models.FactionIndex) (string, error) {
+	canonical := *index
+	canonical.Schema = ""
+	data, err := json.Marshal(&canonical)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash index: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 // writeIndex writes the lightweight units.json index
 func (e *FactionExporter) writeIndex(factionDir string, index *models.FactionIndex) error {
-	indexPath := filepath.Join(factionDir, "units.json")
+	if err := WriteFactionIndex(factionDir, index, e.Index.JSON); err != nil {
+		return err
+	}
+
+	if e.Verbose {
+		fmt.Printf("  ✓ Wrote units.json index (%d units)\n", len(index.Units))
+	}
+
+	if e.Index.Format == IndexFormatPB {
+		return e.writeBinaryIndex(factionDir, index)
+	}
 
-	data, err := json.MarshalIndent(index, "", "  ")
+	return nil
+}
+
+// writeBinaryIndex writes units.pb, the protobuf encoding of the index
+func (e *FactionExporter) writeBinaryIndex(factionDir string, index *models.FactionIndex) error {
+	indexPath := filepath.Join(factionDir, "units.pb")
+
+	data, err := protoindex.Marshal(index)
 	if err != nil {
-		return fmt.Errorf("failed to marshal index: %w", err)
+		return fmt.Errorf("failed to encode binary index: %w", err)
 	}
 
 	if err := os.WriteFile(indexPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write index file: %w", err)
+		return fmt.Errorf("failed to write binary index file: %w", err)
 	}
 
 	if e.Verbose {
-		fmt.Printf("  ✓ Wrote units.json index (%d units)\n", len(index.Units))
+		fmt.Printf("  ✓ Wrote units.pb binary index (%d bytes)\n", len(data))
 	}
 
 	return nil
@@ -782,6 +1013,19 @@ func CreateMetadataFromProfile(profile *models.FactionProfile, resolvedMods []*l
 		metadata.BackgroundImage = "assets/" + normalizedPath
 	}
 
+	// Record the mods as resolved, and the commits pinned GitHub mods were downloaded at
+	for _, mod := range resolvedMods {
+		metadata.ModVersions = append(metadata.ModVersions, models.ModVersion{
+			Identifier: mod.Identifier,
+			Version:    mod.Version,
+			Source:     string(mod.SourceType),
+			Commit:     mod.GitHubCommit,
+		})
+		if mod.GitHubCommit != "" {
+			metadata.GitHubCommits = append(metadata.GitHubCommits, models.GitHubCommit{URL: mod.GitHubURL, Ref: mod.GitHubRef, Commit: mod.GitHubCommit})
+		}
+	}
+
 	// Carry the faction's default team-paint colours into the metadata so the
 	// web app can seed the 3D model viewer's colour picker.
 	if profile.TeamColors != nil {
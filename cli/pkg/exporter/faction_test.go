@@ -1,9 +1,11 @@
 package exporter
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
 )
 
 // TestShouldSkipSpecFileForAddon tests the addon spec file filtering logic
@@ -140,3 +142,102 @@ func TestSanitizeFolderName(t *testing.T) {
 		})
 	}
 }
+
+func TestParseLayout(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantName  string
+		wantDir   string
+		assetPath string
+		wantErr   bool
+	}{
+		{name: "default is mirrored", input: "", wantName: LayoutMirrored, wantDir: "assets", assetPath: "pa/units/land/tank/tank_ammo.json"},
+		{name: "mirrored", input: "mirrored", wantName: LayoutMirrored, wantDir: "assets", assetPath: "pa/units/land/tank/tank_ammo.json"},
+		{name: "flat (case-insensitive)", input: "Flat", wantName: LayoutFlat, wantDir: "units", assetPath: "tank/tank_ammo.json"},
+		{name: "unknown", input: "nested", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout, err := ParseLayout(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseLayout(%q) expected error", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLayout(%q) unexpected error: %v", tt.input, err)
+			}
+			if layout.Name() != tt.wantName || layout.Dir() != tt.wantDir {
+				t.Errorf("ParseLayout(%q) = %s in %s/, want %s in %s/", tt.input, layout.Name(), layout.Dir(), tt.wantName, tt.wantDir)
+			}
+			if got := layout.AssetPath("tank", "/pa/units/land/tank/tank_ammo.json"); got != tt.assetPath {
+				t.Errorf("AssetPath() = %q, want %q", got, tt.assetPath)
+			}
+		})
+	}
+}
+
+func TestAssetPaths(t *testing.T) {
+	resources := []string{
+		"/pa/units/land/tank/weapon.json",
+		"/pa/units/land/tank/tank.json",
+		"/pa/tools/weapon.json",
+		"/pa/ammo/tools/weapon.json",
+		"/pa/units/land/tank/tank_ammo.json",
+	}
+
+	flat := AssetPaths(FlatLayout{}, "tank", "/pa/units/land/tank/tank.json", resources)
+	want := map[string]string{
+		"/pa/units/land/tank/tank.json":      "tank/tank.json",
+		"/pa/units/land/tank/tank_ammo.json": "tank/tank_ammo.json",
+		"/pa/ammo/tools/weapon.json":         "tank/weapon.json",
+		"/pa/tools/weapon.json":              "tank/tools_weapon.json",
+		"/pa/units/land/tank/weapon.json":    "tank/tank_weapon.json",
+	}
+	if !reflect.DeepEqual(flat, want) {
+		t.Errorf("AssetPaths(flat) = %v, want %v", flat, want)
+	}
+
+	// A second clash on the parent folder gets a number
+	numbered := AssetPaths(FlatLayout{}, "tank", "", []string{"/a/tools/weapon.json", "/b/tools/weapon.json", "/c/tools/weapon.json"})
+	if got := numbered["/c/tools/weapon.json"]; got != "tank/tools_weapon-2.json" {
+		t.Errorf("third weapon.json = %q, want tank/tools_weapon-2.json", got)
+	}
+
+	for resourcePath, assetPath := range AssetPaths(MirroredLayout{}, "tank", "/pa/units/land/tank/tank.json", resources) {
+		if assetPath != resourcePath[1:] {
+			t.Errorf("AssetPaths(mirrored)[%s] = %q, want the mirrored path", resourcePath, assetPath)
+		}
+	}
+}
+
+func TestIndexContentHash(t *testing.T) {
+	index := func() *models.FactionIndex {
+		return &models.FactionIndex{Units: []models.UnitIndexEntry{{
+			Identifier: "tank",
+			Files:      []models.UnitFile{{Path: "pa/units/land/tank/tank.json", Source: "pa"}},
+			Unit:       models.Unit{ID: "tank", Specs: models.UnitSpecs{Combat: &models.CombatSpecs{Health: 200}}},
+		}}}
+	}
+	hash, err := IndexContentHash(index())
+	if err != nil {
+		t.Fatalf("IndexContentHash failed: %v", err)
+	}
+	if len(hash) != 64 {
+		t.Errorf("hash = %q, want 64 hex digits", hash)
+	}
+
+	stamped := index()
+	stamped.Schema = models.SchemaURL("faction-index")
+	if got, _ := IndexContentHash(stamped); got != hash {
+		t.Error("$schema changed the hash")
+	}
+	changed := index()
+	changed.Units[0].Unit.Specs.Combat.Health = 250
+	if got, _ := IndexContentHash(changed); got == hash {
+		t.Error("a changed unit kept the hash")
+	}
+}
@@ -0,0 +1,210 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// Build tree graph formats for describe-faction --graph
+const (
+	GraphDOT     = "dot"     // Graphviz
+	GraphGraphML = "graphml" // GraphML, for yEd, Gephi and the like
+)
+
+// GraphFile is the base name of the build tree graph in a faction folder; the format is
+// the extension
+const GraphFile = "build-tree"
+
+// ParseGraphFormat validates a --graph value
+func ParseGraphFormat(format string) (string, error) {
+	switch format {
+	case GraphDOT, GraphGraphML:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown graph format '%s' (expected %s or %s)", format, GraphDOT, GraphGraphML)
+}
+
+// GraphFileName is the file WriteBuildGraph writes for a format, e.g. build-tree.dot
+func GraphFileName(format string) string {
+	return GraphFile + "." + format
+}
+
+// graphNode is one unit of the build tree
+type graphNode struct {
+	ID          string
+	DisplayName string
+	Tier        int
+	BuildCost   float64 // Metal
+}
+
+// buildGraph returns the units as nodes in ID order and each builds relationship as a
+// builder -> built edge, in the same order; relationships to units outside the export are
+// left out
+func buildGraph(units []models.Unit) ([]graphNode, [][2]string) {
+	nodes := make([]graphNode, 0, len(units))
+	known := make(map[string]bool, len(units))
+	for _, u := range units {
+		node := graphNode{ID: u.ID, DisplayName: u.DisplayName, Tier: u.Tier}
+		if u.Specs.Economy != nil {
+			node.BuildCost = u.Specs.Economy.BuildCost
+		}
+		nodes = append(nodes, node)
+		known[u.ID] = true
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+
+	byID := make(map[string]models.Unit, len(units))
+	for _, u := range units {
+		byID[u.ID] = u
+	}
+	var edges [][2]string
+	for _, node := range nodes {
+		builds := append([]string{}, byID[node.ID].BuildRelationships.Builds...)
+		sort.Strings(builds)
+		for _, built := range builds {
+			if known[built] {
+				edges = append(edges, [2]string{node.ID, built})
+			}
+		}
+	}
+	return nodes, edges
+}
+
+// WriteBuildGraph writes the faction's build tree to build-tree.<format> in factionDir
+func WriteBuildGraph(factionDir, format string, units []models.Unit) error {
+	if _, err := ParseGraphFormat(format); err != nil {
+		return err
+	}
+	name := GraphFileName(format)
+	f, err := os.Create(filepath.Join(factionDir, name))
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", name, err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	nodes, edges := buildGraph(units)
+	if format == GraphDOT {
+		err = writeDOT(w, nodes, edges)
+	} else {
+		err = writeGraphML(w, nodes, edges)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return f.Close()
+}
+
+// dotQuote quotes a DOT ID or attribute value
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// writeDOT writes a Graphviz digraph. Tier and build cost are node attributes Graphviz
+// ignores, kept for tools that read them; the label shows both.
+func writeDOT(w io.Writer, nodes []graphNode, edges [][2]string) error {
+	var b strings.Builder
+	b.WriteString("digraph \"build-tree\" {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, n := range nodes {
+		label := fmt.Sprintf("%s\nT%d · %s metal", n.DisplayName, n.Tier, formatCost(n.BuildCost))
+		fmt.Fprintf(&b, "  %s [label=%s, tier=%d, buildCost=%s];\n", dotQuote(n.ID), dotQuote(label), n.Tier, formatCost(n.BuildCost))
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&b, "  %s -> %s;\n", dotQuote(e[0]), dotQuote(e[1]))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatCost(cost float64) string {
+	return strconv.FormatFloat(cost, 'f', -1, 64)
+}
+
+// GraphML documents (http://graphml.graphdrawing.org/)
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// writeGraphML writes a directed GraphML graph with displayName, tier and buildCost node
+// attributes; edges carry relation=builds
+func writeGraphML(w io.Writer, nodes []graphNode, edges [][2]string) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "displayName", For: "node", Name: "displayName", Type: "string"},
+			{ID: "tier", For: "node", Name: "tier", Type: "int"},
+			{ID: "buildCost", For: "node", Name: "buildCost", Type: "double"},
+			{ID: "relation", For: "edge", Name: "relation", Type: "string"},
+		},
+		Graph: graphMLGraph{ID: GraphFile, EdgeDefault: "directed"},
+	}
+	for _, n := range nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{ID: n.ID, Data: []graphMLData{
+			{Key: "displayName", Value: n.DisplayName},
+			{Key: "tier", Value: strconv.Itoa(n.Tier)},
+			{Key: "buildCost", Value: formatCost(n.BuildCost)},
+		}})
+	}
+	for _, e := range edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: e[0], Target: e[1], Data: []graphMLData{{Key: "relation", Value: "builds"}}})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
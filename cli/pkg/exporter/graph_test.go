@@ -0,0 +1,95 @@
+package exporter
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func graphUnits() []models.Unit {
+	return []models.Unit{
+		{
+			ID: "vehicle_factory", DisplayName: "Vehicle Factory", Tier: 1,
+			Specs:              models.UnitSpecs{Economy: &models.EconomySpecs{BuildCost: 600}},
+			BuildRelationships: models.BuildRelationships{Builds: []string{"tank", "fabrication_vehicle", "gone"}},
+		},
+		{
+			ID: "tank", DisplayName: `Ant "Mk1"`, Tier: 1,
+			Specs:              models.UnitSpecs{Economy: &models.EconomySpecs{BuildCost: 150.5}},
+			BuildRelationships: models.BuildRelationships{BuiltBy: []string{"vehicle_factory"}},
+		},
+		{
+			ID: "fabrication_vehicle", DisplayName: "Fabricator", Tier: 1,
+			BuildRelationships: models.BuildRelationships{BuiltBy: []string{"vehicle_factory"}},
+		},
+	}
+}
+
+func TestWriteBuildGraphDOT(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteBuildGraph(dir, GraphDOT, graphUnits()); err != nil {
+		t.Fatalf("WriteBuildGraph failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "build-tree.dot"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `digraph "build-tree" {
+  rankdir=LR;
+  node [shape=box];
+  "fabrication_vehicle" [label="Fabricator\nT1 · 0 metal", tier=1, buildCost=0];
+  "tank" [label="Ant \"Mk1\"\nT1 · 150.5 metal", tier=1, buildCost=150.5];
+  "vehicle_factory" [label="Vehicle Factory\nT1 · 600 metal", tier=1, buildCost=600];
+  "vehicle_factory" -> "fabrication_vehicle";
+  "vehicle_factory" -> "tank";
+}
+`
+	if string(data) != want {
+		t.Errorf("build-tree.dot =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestWriteBuildGraphGraphML(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteBuildGraph(dir, GraphGraphML, graphUnits()); err != nil {
+		t.Fatalf("WriteBuildGraph failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "build-tree.graphml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc graphML
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("build-tree.graphml isn't valid XML: %v", err)
+	}
+	if doc.Graph.EdgeDefault != "directed" || len(doc.Graph.Nodes) != 3 {
+		t.Fatalf("graph = %+v, want 3 nodes, directed", doc.Graph)
+	}
+	tank := doc.Graph.Nodes[1]
+	wantData := []graphMLData{{"displayName", `Ant "Mk1"`}, {"tier", "1"}, {"buildCost", "150.5"}}
+	if tank.ID != "tank" || !reflect.DeepEqual(tank.Data, wantData) {
+		t.Errorf("tank node = %+v, want data %+v", tank, wantData)
+	}
+	var edges [][2]string
+	for _, e := range doc.Graph.Edges {
+		edges = append(edges, [2]string{e.Source, e.Target})
+	}
+	if want := [][2]string{{"vehicle_factory", "fabrication_vehicle"}, {"vehicle_factory", "tank"}}; !reflect.DeepEqual(edges, want) {
+		t.Errorf("edges = %v, want %v", edges, want)
+	}
+}
+
+func TestParseGraphFormat(t *testing.T) {
+	if _, err := ParseGraphFormat("svg"); err == nil {
+		t.Error("ParseGraphFormat(svg) succeeded, want an error")
+	}
+	if f, err := ParseGraphFormat(GraphGraphML); err != nil || f != GraphGraphML {
+		t.Errorf("ParseGraphFormat(graphml) = %q, %v", f, err)
+	}
+}
@@ -0,0 +1,151 @@
+package exporter
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"unicode"
+)
+
+// PlaceholderIconSize is the width and height of generated icons, matching PA's
+// buildbar icons
+const PlaceholderIconSize = 60
+
+// PlaceholderIconSource is the UnitFile source recorded for files pa-pedia generates
+// (placeholder icons and silhouettes)
+const PlaceholderIconSource = "pa-pedia"
+
+// Asset modes for FactionExporter.Assets, recorded as metadata.json "assets"
+const (
+	AssetsIconsStripped = "icons-stripped" // Placeholders instead of game icons
+	AssetsNone          = "none"           // No copied game files at all: placeholders and units.json only
+)
+
+// tierColors are the placeholder backgrounds by unit tier (1=Basic 2=Advanced 3=Titan)
+var tierColors = map[int]color.RGBA{
+	1: {0x2f, 0x5f, 0x8f, 0xff},
+	2: {0x9a, 0x6a, 0x16, 0xff},
+	3: {0x8f, 0x2b, 0x2b, 0xff},
+}
+
+var placeholderDefault = color.RGBA{0x4a, 0x4a, 0x4a, 0xff}
+
+// glyphs is a 5x7 bitmap font for initials, one bit per pixel with the most
+// significant of the five bits on the left
+var glyphs = map[rune][7]uint8{
+	'A': {0b01110, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001},
+	'B': {0b11110, 0b10001, 0b10001, 0b11110, 0b10001, 0b10001, 0b11110},
+	'C': {0b01110, 0b10001, 0b10000, 0b10000, 0b10000, 0b10001, 0b01110},
+	'D': {0b11110, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b11110},
+	'E': {0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b11111},
+	'F': {0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b10000},
+	'G': {0b01110, 0b10001, 0b10000, 0b10111, 0b10001, 0b10001, 0b01111},
+	'H': {0b10001, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001},
+	'I': {0b01110, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'J': {0b00111, 0b00010, 0b00010, 0b00010, 0b00010, 0b10010, 0b01100},
+	'K': {0b10001, 0b10010, 0b10100, 0b11000, 0b10100, 0b10010, 0b10001},
+	'L': {0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b11111},
+	'M': {0b10001, 0b11011, 0b10101, 0b10101, 0b10001, 0b10001, 0b10001},
+	'N': {0b10001, 0b10001, 0b11001, 0b10101, 0b10011, 0b10001, 0b10001},
+	'O': {0b01110, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110},
+	'P': {0b11110, 0b10001, 0b10001, 0b11110, 0b10000, 0b10000, 0b10000},
+	'Q': {0b01110, 0b10001, 0b10001, 0b10001, 0b10101, 0b10010, 0b01101},
+	'R': {0b11110, 0b10001, 0b10001, 0b11110, 0b10100, 0b10010, 0b10001},
+	'S': {0b01111, 0b10000, 0b10000, 0b01110, 0b00001, 0b00001, 0b11110},
+	'T': {0b11111, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100},
+	'U': {0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110},
+	'V': {0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01010, 0b00100},
+	'W': {0b10001, 0b10001, 0b10001, 0b10101, 0b10101, 0b10101, 0b01010},
+	'X': {0b10001, 0b10001, 0b01010, 0b00100, 0b01010, 0b10001, 0b10001},
+	'Y': {0b10001, 0b10001, 0b01010, 0b00100, 0b00100, 0b00100, 0b00100},
+	'Z': {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b10000, 0b11111},
+	'0': {0b01110, 0b10001, 0b10011, 0b10101, 0b11001, 0b10001, 0b01110},
+	'1': {0b00100, 0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'2': {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b01000, 0b11111},
+	'3': {0b11111, 0b00010, 0b00100, 0b00010, 0b00001, 0b10001, 0b01110},
+	'4': {0b00010, 0b00110, 0b01010, 0b10010, 0b11111, 0b00010, 0b00010},
+	'5': {0b11111, 0b10000, 0b11110, 0b00001, 0b00001, 0b10001, 0b01110},
+	'6': {0b00110, 0b01000, 0b10000, 0b11110, 0b10001, 0b10001, 0b01110},
+	'7': {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b01000, 0b01000},
+	'8': {0b01110, 0b10001, 0b10001, 0b01110, 0b10001, 0b10001, 0b01110},
+	'9': {0b01110, 0b10001, 0b10001, 0b01111, 0b00001, 0b00010, 0b01100},
+	'?': {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b00000, 0b00100},
+}
+
+// Initials returns up to two characters for a placeholder icon: the first letter or
+// digit of the first two words of the name (e.g. "Advanced Fabrication Bot" -> "AF",
+// "Ant" -> "A"), or "?" if the name has none the icon font can draw
+func Initials(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var initials []rune
+	for _, word := range words {
+		r := unicode.ToUpper([]rune(word)[0])
+		if _, ok := glyphs[r]; !ok {
+			continue
+		}
+		initials = append(initials, r)
+		if len(initials) == 2 {
+			break
+		}
+	}
+	if len(initials) == 0 {
+		return "?"
+	}
+	return string(initials)
+}
+
+// PlaceholderIcon renders a PNG icon for a unit without art: its initials (see
+// Initials) in white on a background colored by tier
+func PlaceholderIcon(name string, tier int) ([]byte, error) {
+	bg, ok := tierColors[tier]
+	if !ok {
+		bg = placeholderDefault
+	}
+	border := color.RGBA{bg.R / 2, bg.G / 2, bg.B / 2, 0xff}
+
+	img := image.NewRGBA(image.Rect(0, 0, PlaceholderIconSize, PlaceholderIconSize))
+	for y := 0; y < PlaceholderIconSize; y++ {
+		for x := 0; x < PlaceholderIconSize; x++ {
+			c := bg
+			if x < 2 || y < 2 || x >= PlaceholderIconSize-2 || y >= PlaceholderIconSize-2 {
+				c = border
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	// Glyphs are 5x7 with a 1 pixel gap, drawn at 4x and centered
+	const scale = 4
+	text := []rune(Initials(name))
+	width := (len(text)*6 - 1) * scale
+	left := (PlaceholderIconSize - width) / 2
+	top := (PlaceholderIconSize - 7*scale) / 2
+	white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	for i, r := range text {
+		glyph := glyphs[r]
+		for row, bits := range glyph {
+			for col := 0; col < 5; col++ {
+				if bits&(1<<(4-col)) == 0 {
+					continue
+				}
+				x0 := left + (i*6+col)*scale
+				y0 := top + row*scale
+				for dy := 0; dy < scale; dy++ {
+					for dx := 0; dx < scale; dx++ {
+						img.SetRGBA(x0+dx, y0+dy, white)
+					}
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,65 @@
+package exporter
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestInitials(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Ant", "A"},
+		{"Advanced Fabrication Bot", "AF"},
+		{"mod_tank", "MT"},
+		{"T2 Laser Defense", "TL"},
+		{"3rd-party Turret", "3P"},
+		{"Ægis Wall", "W"},
+		{"", "?"},
+		{"—", "?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Initials(tt.name); got != tt.want {
+				t.Errorf("Initials(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlaceholderIcon(t *testing.T) {
+	tests := []struct {
+		tier int
+		want color.RGBA
+	}{
+		{1, tierColors[1]},
+		{2, tierColors[2]},
+		{3, tierColors[3]},
+		{0, placeholderDefault},
+	}
+
+	for _, tt := range tests {
+		data, err := PlaceholderIcon("Ant", tt.tier)
+		if err != nil {
+			t.Fatalf("PlaceholderIcon(tier %d) error = %v", tt.tier, err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("tier %d: invalid PNG: %v", tt.tier, err)
+		}
+		if b := img.Bounds(); b.Dx() != PlaceholderIconSize || b.Dy() != PlaceholderIconSize {
+			t.Errorf("tier %d: size = %dx%d", tt.tier, b.Dx(), b.Dy())
+		}
+		if got := color.RGBAModel.Convert(img.At(5, 5)).(color.RGBA); got != tt.want {
+			t.Errorf("tier %d: background = %v, want %v", tt.tier, got, tt.want)
+		}
+		// Center of the "A" crossbar is drawn in white
+		if got := color.RGBAModel.Convert(img.At(30, 29)).(color.RGBA); got != (color.RGBA{0xff, 0xff, 0xff, 0xff}) {
+			t.Errorf("tier %d: glyph pixel = %v, want white", tt.tier, got)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package exporter
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// Exporter writes a faction folder (metadata.json, units.json and unit files) for parsed units.
+type Exporter interface {
+	ExportFaction(metadata models.FactionMetadata, units []models.Unit) error
+	// CopyResourceToFile copies a single PA resource from the loader's sources to destPath.
+	CopyResourceToFile(resourcePath, destPath string) error
+}
+
+// Layout decides where exported unit files live inside a faction folder.
+type Layout interface {
+	// Name is the layout identifier recorded in units.json (e.g. "mirrored").
+	Name() string
+	// Dir is the folder under the faction folder that holds unit files.
+	Dir() string
+	// AssetPath maps a PA resource path exported for a unit to a slash-separated path
+	// relative to Dir(). Index file paths use this form; unit images are prefixed with Dir().
+	AssetPath(unitID, resourcePath string) string
+}
+
+// Layout names accepted by ParseLayout
+const (
+	LayoutMirrored = "mirrored"
+	LayoutFlat     = "flat"
+)
+
+// MirroredLayout mirrors PA resource paths under assets/ (e.g. assets/pa/units/land/tank/tank.json).
+// Shared specs are written once. This is the default and the layout the web app reads.
+type MirroredLayout struct{}
+
+func (MirroredLayout) Name() string { return LayoutMirrored }
+func (MirroredLayout) Dir() string  { return "assets" }
+func (MirroredLayout) AssetPath(unitID, resourcePath string) string {
+	return strings.TrimPrefix(resourcePath, "/")
+}
+
+// FlatLayout writes every file a unit references into units/<id>/ (the Phase 1.0 style).
+// Shared specs are copied into each unit folder that references them.
+type FlatLayout struct{}
+
+func (FlatLayout) Name() string { return LayoutFlat }
+func (FlatLayout) Dir() string  { return "units" }
+func (FlatLayout) AssetPath(unitID, resourcePath string) string {
+	return unitID + "/" + path.Base(resourcePath)
+}
+
+// AssetPaths maps each of a unit's resource paths to its asset path in layout. Paths the
+// layout would write to the same file (two specs named weapon.json in the flat layout)
+// are told apart by prefixing the resource's parent folder name, then by a number: the
+// unit's own JSON (primary) keeps its plain name, the rest are taken in path order.
+func AssetPaths(layout Layout, unitID, primary string, resourcePaths []string) map[string]string {
+	sorted := append([]string(nil), resourcePaths...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if (sorted[i] == primary) != (sorted[j] == primary) {
+			return sorted[i] == primary
+		}
+		return sorted[i] < sorted[j]
+	})
+
+	assetPaths := make(map[string]string, len(sorted))
+	taken := make(map[string]bool, len(sorted))
+	for _, resourcePath := range sorted {
+		assetPath := layout.AssetPath(unitID, resourcePath)
+		if taken[assetPath] {
+			dir, name := path.Split(assetPath)
+			parent := path.Base(path.Dir(resourcePath))
+			assetPath = dir + parent + "_" + name
+			ext := path.Ext(name)
+			for n := 2; taken[assetPath]; n++ {
+				assetPath = fmt.Sprintf("%s%s_%s-%d%s", dir, parent, strings.TrimSuffix(name, ext), n, ext)
+			}
+		}
+		taken[assetPath] = true
+		assetPaths[resourcePath] = assetPath
+	}
+	return assetPaths
+}
+
+// ParseLayout returns the layout for a --layout flag value.
+func ParseLayout(name string) (Layout, error) {
+	switch strings.ToLower(name) {
+	case "", LayoutMirrored:
+		return MirroredLayout{}, nil
+	case LayoutFlat:
+		return FlatLayout{}, nil
+	default:
+		return nil, fmt.Errorf("unknown layout '%s' (expected %s or %s)", name, LayoutMirrored, LayoutFlat)
+	}
+}
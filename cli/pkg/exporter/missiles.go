@@ -0,0 +1,127 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// MissilesFile is the missile report written beside units.json
+const MissilesFile = "missiles.json"
+
+// BuildMissileReport lists the missile weapons of units: command-fired weapons with
+// factory-built ammo (nukes) and interceptors that shoot projectiles down (anti-nukes,
+// anti_entity_targets). Missiles are priced at the unit's own build arms, and each missile
+// launcher lists the faction's interceptors whose targets include its missile.
+func BuildMissileReport(units []models.Unit) models.MissileReport {
+	report := models.MissileReport{Launchers: []models.MissileLauncher{}}
+	for _, unit := range units {
+		if unit.Specs.Combat == nil {
+			continue
+		}
+		for _, w := range unit.Specs.Combat.Weapons {
+			var role string
+			switch {
+			case len(w.AntiEntityTargets) > 0:
+				role = models.MissileRoleInterceptor
+			case w.AmmoSource == "factory" && w.ManualFire != nil && w.ManualFire.Trigger == models.ManualFireCommand:
+				role = models.MissileRoleMissile
+			default:
+				continue
+			}
+			report.Launchers = append(report.Launchers, missileLauncher(unit, w, role))
+		}
+	}
+
+	interceptors := make(map[string][]string)
+	for _, l := range report.Launchers {
+		for _, target := range l.Intercepts {
+			interceptors[target] = append(interceptors[target], l.Unit)
+		}
+	}
+	for i := range report.Launchers {
+		l := &report.Launchers[i]
+		if l.Role == models.MissileRoleMissile && l.Missile != "" {
+			l.InterceptedBy = dedupe(interceptors[l.Missile])
+		}
+	}
+
+	sort.SliceStable(report.Launchers, func(i, j int) bool {
+		a, b := report.Launchers[i], report.Launchers[j]
+		if a.Unit != b.Unit {
+			return a.Unit < b.Unit
+		}
+		return a.Weapon < b.Weapon
+	})
+	return report
+}
+
+func missileLauncher(unit models.Unit, w models.Weapon, role string) models.MissileLauncher {
+	l := models.MissileLauncher{
+		Unit:        unit.ID,
+		DisplayName: unit.DisplayName,
+		Weapon:      w.SafeName,
+		Role:        role,
+		Intercepts:  w.AntiEntityTargets,
+	}
+	if w.Ammo != nil {
+		l.Missile = w.Ammo.ResourceName
+	}
+	if w.AmmoSource != "factory" {
+		return l
+	}
+
+	if w.Ammo != nil {
+		l.MetalCost = w.Ammo.MetalCost
+	}
+	if unit.Specs.Economy != nil {
+		for _, arm := range unit.Specs.Economy.BuildArms {
+			l.BuildPower += arm.MetalConsumption * float64(arm.Count)
+			l.EnergyRate += arm.EnergyConsumption * float64(arm.Count)
+		}
+	}
+	if l.BuildPower > 0 && l.MetalCost > 0 {
+		buildTime := l.MetalCost / l.BuildPower
+		l.BuildTime = math.Round(buildTime*100) / 100
+		l.EnergyCost = math.Round(buildTime*l.EnergyRate*100) / 100
+	}
+	if s := unit.Specs.Storage; s != nil && s.StoredUnitType == "missile" {
+		l.Storage = s.UnitStorage
+	}
+	return l
+}
+
+// dedupe returns ids sorted without repeats, or nil when empty
+func dedupe(ids []string) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	sort.Strings(ids)
+	out := ids[:1]
+	for _, id := range ids[1:] {
+		if id != out[len(out)-1] {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// WriteMissileReport writes missiles.json to a faction folder
+func WriteMissileReport(factionDir string, report models.MissileReport) error {
+	if report.Launchers == nil {
+		report.Launchers = []models.MissileLauncher{}
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal missile report: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(factionDir, MissilesFile), data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", MissilesFile, err)
+	}
+	return nil
+}
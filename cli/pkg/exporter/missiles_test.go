@@ -0,0 +1,73 @@
+package exporter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestBuildMissileReport(t *testing.T) {
+	const nukeAmmo = "/pa/units/land/nuke_launcher/nuke_launcher_ammo.json"
+	const legionNukeAmmo = "/pa/units/land/l_nuke_launcher/l_nuke_launcher_ammo.json"
+	buildArm := []models.BuildArm{{Count: 1, MetalConsumption: 60, EnergyConsumption: 4000}}
+	missileStorage := &models.StorageSpecs{UnitStorage: 4, StoredUnitType: "missile"}
+
+	units := []models.Unit{
+		{
+			ID: "nuke_launcher", DisplayName: "Nuke Launcher",
+			Specs: models.UnitSpecs{
+				Combat: &models.CombatSpecs{Weapons: []models.Weapon{{
+					SafeName:   "nuke_launcher_tool_weapon",
+					AmmoSource: "factory",
+					Ammo:       &models.Ammo{ResourceName: nukeAmmo, MetalCost: 36000},
+					ManualFire: &models.ManualFire{Trigger: models.ManualFireCommand},
+				}}},
+				Economy: &models.EconomySpecs{BuildArms: buildArm},
+				Storage: &models.StorageSpecs{UnitStorage: 1, StoredUnitType: "missile"},
+			},
+		},
+		{
+			ID: "anti_nuke_launcher", DisplayName: "Anti-Nuke",
+			Specs: models.UnitSpecs{
+				Combat: &models.CombatSpecs{Weapons: []models.Weapon{{
+					SafeName:          "anti_nuke_launcher_tool_weapon",
+					AmmoSource:        "factory",
+					Ammo:              &models.Ammo{ResourceName: "/pa/units/land/anti_nuke_launcher/anti_nuke_launcher_ammo.json", MetalCost: 5000},
+					AntiEntityTargets: []string{nukeAmmo, legionNukeAmmo},
+				}}},
+				Economy: &models.EconomySpecs{BuildArms: buildArm},
+				Storage: missileStorage,
+			},
+		},
+		{
+			ID: "commander",
+			Specs: models.UnitSpecs{Combat: &models.CombatSpecs{Weapons: []models.Weapon{{
+				SafeName:   "uber_cannon",
+				AmmoSource: "energy",
+				ManualFire: &models.ManualFire{Trigger: models.ManualFireCommand},
+			}}}},
+		},
+	}
+
+	report := BuildMissileReport(units)
+	want := []models.MissileLauncher{
+		{
+			Unit: "anti_nuke_launcher", DisplayName: "Anti-Nuke", Weapon: "anti_nuke_launcher_tool_weapon",
+			Role:      models.MissileRoleInterceptor,
+			Missile:   "/pa/units/land/anti_nuke_launcher/anti_nuke_launcher_ammo.json",
+			MetalCost: 5000, BuildPower: 60, EnergyRate: 4000, BuildTime: 83.33, EnergyCost: 333333.33, Storage: 4,
+			Intercepts: []string{nukeAmmo, legionNukeAmmo},
+		},
+		{
+			Unit: "nuke_launcher", DisplayName: "Nuke Launcher", Weapon: "nuke_launcher_tool_weapon",
+			Role:      models.MissileRoleMissile,
+			Missile:   nukeAmmo,
+			MetalCost: 36000, BuildPower: 60, EnergyRate: 4000, BuildTime: 600, EnergyCost: 2400000, Storage: 1,
+			InterceptedBy: []string{"anti_nuke_launcher"},
+		},
+	}
+	if !reflect.DeepEqual(report.Launchers, want) {
+		t.Errorf("Launchers =\n%+v\nwant\n%+v", report.Launchers, want)
+	}
+}
@@ -0,0 +1,121 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// ReadFactionIndex reads the units.json index from an exported faction folder.
+func ReadFactionIndex(factionDir string) (*models.FactionIndex, error) {
+	indexPath := filepath.Join(factionDir, "units.json")
+
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index file: %w", err)
+	}
+
+	var index models.FactionIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse index file %s: %w", indexPath, err)
+	}
+
+	return &index, nil
+}
+
+// ReadFactionMetadata reads metadata.json from an exported faction folder.
+func ReadFactionMetadata(factionDir string) (*models.FactionMetadata, error) {
+	metadataPath := filepath.Join(factionDir, "metadata.json")
+
+	data, err := os.ReadFile(metadataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata file: %w", err)
+	}
+
+	var metadata models.FactionMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata file %s: %w", metadataPath, err)
+	}
+
+	return &metadata, nil
+}
+
+// ExportedFaction is a faction folder found by ScanFactions.
+type ExportedFaction struct {
+	Dir      string
+	Metadata *models.FactionMetadata
+}
+
+// ExportedBuild returns the PA build a faction was extracted from: PABuild, or for
+// base-game exports made before PABuild was recorded, the auto-detected Version.
+// Empty when unknown.
+func (f ExportedFaction) ExportedBuild() string {
+	if f.Metadata.PABuild != "" {
+		return f.Metadata.PABuild
+	}
+	if f.Metadata.Type == "base-game" {
+		return f.Metadata.Version
+	}
+	return ""
+}
+
+// ScanFactions reads metadata.json from every faction folder directly under dir,
+// sorted by folder name. Subfolders without metadata.json are skipped.
+func ScanFactions(dir string) ([]ExportedFaction, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read factions directory: %w", err)
+	}
+
+	var factions []ExportedFaction
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		factionDir := filepath.Join(dir, entry.Name())
+		if _, err := os.Stat(filepath.Join(factionDir, "metadata.json")); err != nil {
+			continue
+		}
+		metadata, err := ReadFactionMetadata(factionDir)
+		if err != nil {
+			return nil, err
+		}
+		factions = append(factions, ExportedFaction{Dir: factionDir, Metadata: metadata})
+	}
+
+	sort.Slice(factions, func(i, j int) bool { return factions[i].Dir < factions[j].Dir })
+	return factions, nil
+}
+
+// ReadFactionIndexFS reads units.json from the root of fsys, e.g. an opened faction zip.
+func ReadFactionIndexFS(fsys fs.FS) (*models.FactionIndex, error) {
+	data, err := fs.ReadFile(fsys, "units.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index file: %w", err)
+	}
+
+	var index models.FactionIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse index file: %w", err)
+	}
+	return &index, nil
+}
+
+// ReadFactionMetadataFS reads metadata.json from the root of fsys.
+func ReadFactionMetadataFS(fsys fs.FS) (*models.FactionMetadata, error) {
+	data, err := fs.ReadFile(fsys, "metadata.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata file: %w", err)
+	}
+
+	var metadata models.FactionMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata file: %w", err)
+	}
+	return &metadata, nil
+}
@@ -0,0 +1,53 @@
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestScanFactions(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, metadata models.FactionMetadata) {
+		factionDir := filepath.Join(dir, name)
+		if err := os.MkdirAll(factionDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteFactionMetadata(factionDir, metadata); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("MLA", models.FactionMetadata{DisplayName: "MLA", Version: "124664", Type: "base-game"})
+	write("Legion", models.FactionMetadata{DisplayName: "Legion", Version: "1.32.1", Build: "124615", Type: "mod"})
+	write("Bugs", models.FactionMetadata{DisplayName: "Bugs", Version: "2.0", Type: "mod", PABuild: "124700"})
+	if err := os.MkdirAll(filepath.Join(dir, "not-a-faction"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	factions, err := ScanFactions(dir)
+	if err != nil {
+		t.Fatalf("ScanFactions() error = %v", err)
+	}
+
+	want := []struct {
+		name  string
+		build string
+	}{
+		{"Bugs", "124700"}, // recorded PABuild
+		{"Legion", ""},     // mod target build is not the extraction build
+		{"MLA", "124664"},  // base-game version doubles as the build
+	}
+	if len(factions) != len(want) {
+		t.Fatalf("got %d factions, want %d", len(factions), len(want))
+	}
+	for i, w := range want {
+		if factions[i].Metadata.DisplayName != w.name {
+			t.Errorf("factions[%d] = %s, want %s", i, factions[i].Metadata.DisplayName, w.name)
+		}
+		if got := factions[i].ExportedBuild(); got != w.build {
+			t.Errorf("%s ExportedBuild() = %q, want %q", w.name, got, w.build)
+		}
+	}
+}
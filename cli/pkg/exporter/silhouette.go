@@ -0,0 +1,58 @@
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"path/filepath"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models3d"
+	"github.com/jamiemulcahy/pa-pedia/pkg/papa"
+)
+
+// SilhouetteName returns the file name of a unit's silhouette from a view, exported
+// beside its icon (e.g. tank_silhouette_top.png)
+func SilhouetteName(unitID string, view papa.View) string {
+	return fmt.Sprintf("%s_silhouette_%s.png", unitID, view)
+}
+
+// writeSilhouettes renders a unit's .papa model from above and from the side. Units
+// without a model get none; a model that can't be read or decoded is an error.
+func (e *FactionExporter) writeSilhouettes(unit models.Unit, files fileSink) ([]models.UnitFile, error) {
+	raw, err := e.Loader.GetJSON(unit.ResourceName)
+	if err != nil {
+		return nil, err
+	}
+	modelPath, ok := models3d.ModelPapaPath(raw)
+	if !ok {
+		return nil, nil
+	}
+	data, err := e.Loader.ReadResource(modelPath)
+	if err != nil {
+		return nil, err
+	}
+	mesh, err := papa.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", modelPath, err)
+	}
+
+	unitDir := filepath.ToSlash(filepath.Dir(unit.ResourceName))
+	var rendered []models.UnitFile
+	for _, view := range papa.Views {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, papa.Render(mesh, view, e.Silhouettes)); err != nil {
+			return nil, err
+		}
+		assetPath := e.Layout.AssetPath(unit.ID, unitDir+"/"+SilhouetteName(unit.ID, view))
+		if err := writeSinkFile(files, e.unitFilePath(assetPath), buf.Bytes()); err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, models.UnitFile{
+			Path:      assetPath,
+			Source:    PlaceholderIconSource,
+			Generated: true,
+		})
+	}
+	return rendered, nil
+}
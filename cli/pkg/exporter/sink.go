@@ -0,0 +1,129 @@
+package exporter
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fileSink receives an export's unit files by slash-separated path relative to the faction
+// folder: a folder on disk (dirSink) or an archive being written (zipSink)
+type fileSink interface {
+	// create opens rel for writing; the file is complete once the writer is closed
+	create(rel string) (io.WriteCloser, error)
+}
+
+// writeSinkFile writes data to rel in s
+func writeSinkFile(s fileSink, rel string, data []byte) error {
+	w, err := s.create(rel)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// copyToSink copies r to rel in s, returning the bytes written
+func copyToSink(s fileSink, rel string, r io.Reader) (int64, error) {
+	w, err := s.create(rel)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create destination file: %w", err)
+	}
+	n, err := io.Copy(w, r)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return n, fmt.Errorf("failed to copy file data: %w", err)
+	}
+	return n, nil
+}
+
+// dirSink writes files under a folder, creating parent folders as needed
+type dirSink struct {
+	root string
+}
+
+func (s dirSink) create(rel string) (io.WriteCloser, error) {
+	path := filepath.Join(s.root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return os.Create(path)
+}
+
+// zipSink streams files into a zip archive. A zip.Writer takes one entry at a time, so
+// create holds the archive until the returned writer is closed; concurrent copies queue.
+type zipSink struct {
+	zw    *zip.Writer
+	mu    sync.Mutex
+	names map[string]bool
+}
+
+func newZipSink(w io.Writer) *zipSink {
+	return &zipSink{zw: zip.NewWriter(w), names: make(map[string]bool)}
+}
+
+func (s *zipSink) create(rel string) (io.WriteCloser, error) {
+	s.mu.Lock()
+	if s.names[rel] {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%s is already in the archive", rel)
+	}
+	w, err := s.zw.CreateHeader(&zip.FileHeader{Name: rel, Method: zip.Deflate, Modified: zipEntryTime})
+	if err != nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("failed to add %s to zip: %w", rel, err)
+	}
+	s.names[rel] = true
+	return &zipEntry{Writer: w, sink: s}, nil
+}
+
+// addFolder adds every file under dir in archive order (see zipPaths), skipping paths
+// already in the archive
+func (s *zipSink) addFolder(dir string) error {
+	paths, err := zipPaths(dir)
+	if err != nil {
+		return err
+	}
+	for _, rel := range paths {
+		if s.names[rel] {
+			continue
+		}
+		f, err := os.Open(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		_, err = copyToSink(s, rel, f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to add %s to zip: %w", rel, err)
+		}
+	}
+	return nil
+}
+
+func (s *zipSink) close() error {
+	if err := s.zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish zip: %w", err)
+	}
+	return nil
+}
+
+// zipEntry is an open archive entry; closing it lets the next one start
+type zipEntry struct {
+	io.Writer
+	sink *zipSink
+	once sync.Once
+}
+
+func (e *zipEntry) Close() error {
+	e.once.Do(e.sink.mu.Unlock)
+	return nil
+}
@@ -0,0 +1,101 @@
+package exporter
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// ReferenceIssue is a unit reference in an export that doesn't resolve, or an accessible
+// unit nothing builds
+type ReferenceIssue struct {
+	Unit    string `json:"unit"`          // Unit ID the issue was found on
+	Field   string `json:"field"`         // Field holding the reference, such as buildRelationships.builds
+	Ref     string `json:"ref,omitempty"` // Referenced unit ID or resource path (empty for unbuildable units)
+	Message string `json:"message"`
+}
+
+func (i ReferenceIssue) String() string {
+	if i.Ref == "" {
+		return fmt.Sprintf("%s: %s", i.Unit, i.Message)
+	}
+	return fmt.Sprintf("%s: %s %q %s", i.Unit, i.Field, i.Ref, i.Message)
+}
+
+// ValidateReferences cross-checks the build graph of an index: every unit ID in builds,
+// builtBy, reachability, techPath and buildMenu and every resource path in spawnUnitOnDeath
+// and initialBuildSpec must be a unit in the index, and every accessible unit must have a
+// builder unless it's a commander or spawned by another unit. Addons reference their base
+// factions' units, so pass allowExternal for them to accept IDs the index doesn't have.
+func ValidateReferences(index *models.FactionIndex, allowExternal bool) []ReferenceIssue {
+	ids := make(map[string]bool, len(index.Units))
+	resources := make(map[string]bool, len(index.Units))
+	for _, entry := range index.Units {
+		ids[entry.Identifier] = true
+		resources[entry.Unit.ResourceName] = true
+	}
+
+	var issues []ReferenceIssue
+	checkID := func(unit, field, ref string) {
+		if ref != "" && !ids[ref] && !allowExternal {
+			issues = append(issues, ReferenceIssue{Unit: unit, Field: field, Ref: ref, Message: "is not in the index"})
+		}
+	}
+	checkResource := func(unit, field, ref string) {
+		if ref != "" && !resources[ref] && !allowExternal {
+			issues = append(issues, ReferenceIssue{Unit: unit, Field: field, Ref: ref, Message: "is not an exported unit"})
+		}
+	}
+
+	spawned := make(map[string]bool)
+	for _, entry := range index.Units {
+		u := entry.Unit
+		for _, ref := range u.BuildRelationships.Builds {
+			checkID(u.ID, "buildRelationships.builds", ref)
+		}
+		for _, ref := range u.BuildRelationships.BuiltBy {
+			checkID(u.ID, "buildRelationships.builtBy", ref)
+		}
+		if u.Reachability != nil {
+			checkID(u.ID, "reachability.via", u.Reachability.Via)
+		}
+		if u.TechPath != nil {
+			for _, ref := range u.TechPath.Path {
+				checkID(u.ID, "techPath.path", ref)
+			}
+		}
+		for _, group := range u.BuildMenu {
+			for _, tier := range group.Tiers {
+				for _, ref := range tier.Units {
+					checkID(u.ID, "buildMenu", ref)
+				}
+			}
+		}
+		if u.Specs.Special != nil && u.Specs.Special.SpawnUnitOnDeath != "" {
+			checkResource(u.ID, "specs.special.spawnUnitOnDeath", u.Specs.Special.SpawnUnitOnDeath)
+			spawned[u.Specs.Special.SpawnUnitOnDeath] = true
+		}
+		if u.Specs.Storage != nil && u.Specs.Storage.InitialBuildSpec != "" {
+			checkResource(u.ID, "specs.storage.initialBuildSpec", u.Specs.Storage.InitialBuildSpec)
+			spawned[u.Specs.Storage.InitialBuildSpec] = true
+		}
+	}
+
+	for _, entry := range index.Units {
+		u := entry.Unit
+		if !u.Accessible || u.BaseTemplate || len(u.BuildRelationships.BuiltBy) > 0 || spawned[u.ResourceName] {
+			continue
+		}
+		if u.Reachability != nil && u.Reachability.Method != models.ReachBuilt {
+			continue // commanders and units spawned by projectiles
+		}
+		if hasUnitType(u.UnitTypes, "Commander") {
+			continue
+		}
+		issues = append(issues, ReferenceIssue{Unit: u.ID, Message: "is accessible but no unit builds or spawns it"})
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Unit < issues[j].Unit })
+	return issues
+}
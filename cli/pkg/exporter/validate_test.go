@@ -0,0 +1,103 @@
+package exporter
+
+import (
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestValidateReferences(t *testing.T) {
+	unit := func(id string, builds, builtBy []string) models.UnitIndexEntry {
+		return models.UnitIndexEntry{Identifier: id, Unit: models.Unit{
+			ID:                 id,
+			ResourceName:       "/pa/units/" + id + "/" + id + ".json",
+			Accessible:         true,
+			BuildRelationships: models.BuildRelationships{Builds: builds, BuiltBy: builtBy},
+		}}
+	}
+	valid := func() *models.FactionIndex {
+		commander := unit("commander", []string{"factory"}, nil)
+		commander.Unit.UnitTypes = []string{"Mobile", "Commander"}
+		commander.Unit.Reachability = &models.Reachability{Method: models.ReachCommander}
+		factory := unit("factory", []string{"tank"}, []string{"commander"})
+		factory.Unit.Specs.Storage = &models.StorageSpecs{InitialBuildSpec: "/pa/units/drone/drone.json"}
+		tank := unit("tank", nil, []string{"factory"})
+		tank.Unit.TechPath = &models.TechPath{Path: []string{"commander", "factory", "tank"}}
+		tank.Unit.Specs.Special = &models.SpecialSpecs{SpawnUnitOnDeath: "/pa/units/wreck/wreck.json"}
+		drone := unit("drone", nil, nil)
+		wreck := unit("wreck", nil, nil)
+		template := unit("base_bot", nil, nil)
+		template.Unit.BaseTemplate = true
+		hidden := unit("test_unit", nil, nil)
+		hidden.Unit.Accessible = false
+		return &models.FactionIndex{Units: []models.UnitIndexEntry{commander, factory, tank, drone, wreck, template, hidden}}
+	}
+
+	tests := []struct {
+		name          string
+		edit          func(index *models.FactionIndex)
+		allowExternal bool
+		want          []ReferenceIssue
+	}{
+		{
+			name: "consistent export",
+			edit: func(index *models.FactionIndex) {},
+		},
+		{
+			name: "dangling build",
+			edit: func(index *models.FactionIndex) {
+				index.Units[1].Unit.BuildRelationships.Builds = append(index.Units[1].Unit.BuildRelationships.Builds, "bot")
+			},
+			want: []ReferenceIssue{{Unit: "factory", Field: "buildRelationships.builds", Ref: "bot"}},
+		},
+		{
+			name: "dangling builder and tech path",
+			edit: func(index *models.FactionIndex) {
+				index.Units[2].Unit.BuildRelationships.BuiltBy = []string{"old_factory"}
+				index.Units[2].Unit.TechPath.Path[1] = "old_factory"
+			},
+			want: []ReferenceIssue{
+				{Unit: "tank", Field: "buildRelationships.builtBy", Ref: "old_factory"},
+				{Unit: "tank", Field: "techPath.path", Ref: "old_factory"},
+			},
+		},
+		{
+			name: "missing spawned unit",
+			edit: func(index *models.FactionIndex) {
+				index.Units = append(index.Units[:4], index.Units[5:]...)
+			},
+			want: []ReferenceIssue{{Unit: "tank", Field: "specs.special.spawnUnitOnDeath", Ref: "/pa/units/wreck/wreck.json"}},
+		},
+		{
+			name: "unbuildable accessible unit",
+			edit: func(index *models.FactionIndex) {
+				index.Units[2].Unit.BuildRelationships.BuiltBy = nil
+			},
+			want: []ReferenceIssue{{Unit: "tank"}},
+		},
+		{
+			name: "addon references base units",
+			edit: func(index *models.FactionIndex) {
+				index.Units[1].Unit.BuildRelationships.BuiltBy = []string{"fabrication_bot"}
+				index.Units = index.Units[1:]
+			},
+			allowExternal: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := valid()
+			tt.edit(index)
+			got := ValidateReferences(index, tt.allowExternal)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d issues, want %d: %v", len(got), len(tt.want), got)
+			}
+			for i, w := range tt.want {
+				if got[i].Unit != w.Unit || got[i].Field != w.Field || got[i].Ref != w.Ref {
+					t.Errorf("issue %d = %v, want %+v", i, got[i], w)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// ZipExtension is the file extension of a zipped faction export
+const ZipExtension = ".zip"
+
+// zipEntryTime is the modification time written on every zip entry (the zip epoch), so
+// zipping the same folder twice produces identical bytes
+var zipEntryTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ExportFactionZip exports a faction like ExportFaction but as a single <faction>.zip in
+// OutputDir, returning the archive's path. Unit files are streamed straight into the
+// archive as they're copied, so the export never needs its full size on disk. The small
+// top-level files (units.json, metadata.json, credits) are written to a hidden staging
+// folder, handed to ZipFinish, then added after the unit files. The archive replaces any
+// earlier one atomically.
+func (e *FactionExporter) ExportFactionZip(metadata models.FactionMetadata, units []models.Unit) (string, error) {
+	if e.Layout == nil {
+		e.Layout = MirroredLayout{}
+	}
+	if err := os.MkdirAll(e.OutputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	staging, err := os.MkdirTemp(e.OutputDir, ".zip-staging-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	dest := filepath.Join(e.OutputDir, SanitizeFolderName(metadata.DisplayName)+ZipExtension)
+	tmp := dest + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return "", fmt.Errorf("failed to create zip: %w", err)
+	}
+	err = e.streamZip(out, staging, metadata, units)
+	if closeErr := out.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write zip: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to write zip: %w", err)
+	}
+
+	if e.Verbose {
+		fmt.Printf("Successfully exported faction to %s\n", dest)
+	}
+	return dest, nil
+}
+
+// streamZip writes the export to out: unit files as they're copied, then the top-level
+// files from staging once ZipFinish has run on them
+func (e *FactionExporter) streamZip(out io.Writer, staging string, metadata models.FactionMetadata, units []models.Unit) error {
+	zs := newZipSink(out)
+	if _, err := e.writeFaction(staging, zs, metadata, units); err != nil {
+		return err
+	}
+	if e.ZipFinish != nil {
+		if err := e.ZipFinish(staging); err != nil {
+			return err
+		}
+	}
+	if err := zs.addFolder(staging); err != nil {
+		return err
+	}
+	return zs.close()
+}
+
+// ZipFaction writes the faction folder factionDir to the zip archive dest, replacing it
+// atomically. Unlike ExportFactionZip the folder must already be complete on disk. Files
+// sit at the archive root (metadata.json and units.json first, then the rest by path),
+// which is the layout the web app's zip upload and serve's library read. Dotfiles are
+// skipped.
+func ZipFaction(factionDir, dest string) error {
+	if _, err := os.Stat(filepath.Join(factionDir, "metadata.json")); err != nil {
+		return fmt.Errorf("failed to read metadata file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create zip directory: %w", err)
+	}
+	tmp := dest + ".tmp"
+	if err := writeZip(tmp, factionDir); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write zip: %w", err)
+	}
+	return nil
+}
+
+// zipPaths returns the slash-separated file paths under factionDir in archive order
+func zipPaths(factionDir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(factionDir, func(p string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if p != factionDir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(factionDir, p)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan faction folder: %w", err)
+	}
+
+	rank := func(rel string) int {
+		switch rel {
+		case "metadata.json":
+			return 0
+		case "units.json":
+			return 1
+		}
+		return 2
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		ri, rj := rank(paths[i]), rank(paths[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return paths[i] < paths[j]
+	})
+	return paths, nil
+}
+
+// writeZip streams each file into the archive, so memory use doesn't grow with the export
+func writeZip(dest, factionDir string) error {
+	out, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("failed to create zip: %w", err)
+	}
+	defer out.Close()
+
+	zs := newZipSink(out)
+	if err := zs.addFolder(factionDir); err != nil {
+		return err
+	}
+	if err := zs.close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
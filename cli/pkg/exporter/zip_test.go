@@ -0,0 +1,70 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestZipFaction(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "MLA")
+	files := map[string]string{
+		"units.json":                           `{"units": []}`,
+		"metadata.json":                        `{"identifier": "mla"}`,
+		"CREDITS.json":                         `{}`,
+		"assets/pa/units/land/tank/tank.json":  `{"max_health": 200}`,
+		".pa-pedia/checkpoint.json":            `{}`,
+		"assets/pa/units/land/tank/.gitignore": ``,
+	}
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dest := filepath.Join(t.TempDir(), "out", "MLA.zip")
+	if err := ZipFaction(dir, dest); err != nil {
+		t.Fatalf("ZipFaction() error = %v", err)
+	}
+	zr, err := zip.OpenReader(dest)
+	if err != nil {
+		t.Fatalf("failed to open zip: %v", err)
+	}
+	defer zr.Close()
+
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	want := []string{"metadata.json", "units.json", "CREDITS.json", "assets/pa/units/land/tank/tank.json"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("entries = %v, want %v", names, want)
+	}
+
+	// Zipping the same folder again gives the same bytes
+	first, _ := os.ReadFile(dest)
+	if err := ZipFaction(dir, dest); err != nil {
+		t.Fatalf("ZipFaction() again error = %v", err)
+	}
+	second, _ := os.ReadFile(dest)
+	if !bytes.Equal(first, second) {
+		t.Error("zipping the same folder twice produced different archives")
+	}
+	if _, err := os.Stat(dest + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+}
+
+func TestZipFactionNeedsMetadata(t *testing.T) {
+	dir := t.TempDir()
+	if err := ZipFaction(dir, filepath.Join(t.TempDir(), "empty.zip")); err == nil {
+		t.Error("ZipFaction() of a folder without metadata.json: expected an error")
+	}
+}
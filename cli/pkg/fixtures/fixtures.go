@@ -0,0 +1,222 @@
+// Package fixtures writes compact per-unit assertion files for mod CI (the expected
+// headline stats of each unit, one small JSON file per unit meant to be committed next to
+// the mod) and checks a fresh export against them for the assert command.
+package fixtures
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/stats"
+)
+
+// Fixture is the expected state of one unit. Stats are keyed by stats.Metrics name (dps,
+// health, cost, speed).
+type Fixture struct {
+	ID          string             `json:"id"`
+	DisplayName string             `json:"displayName"`
+	Tier        int                `json:"tier"`
+	Stats       map[string]float64 `json:"stats"`
+}
+
+// FromUnit captures a unit's current stats as a fixture
+func FromUnit(u *models.Unit) Fixture {
+	fixture := Fixture{ID: u.ID, DisplayName: u.DisplayName, Tier: u.Tier, Stats: make(map[string]float64, len(stats.Metrics))}
+	for _, metric := range stats.Metrics {
+		fixture.Stats[metric.Name] = round(metric.Value(u))
+	}
+	return fixture
+}
+
+// FileName is the fixture file for a unit ID, e.g. tank.json
+func FileName(unitID string) string {
+	return unitID + ".json"
+}
+
+// Write rewrites dir to hold one fixture per unit. Other fixtures already in dir are
+// removed, so a unit dropped from the mod doesn't keep a stale fixture; files that don't
+// parse as a fixture (see isFixture), e.g. a modinfo.json, are left alone. Returns the
+// number of fixtures written.
+func Write(dir string, units []*models.Unit) (int, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create fixtures directory %s: %w", dir, err)
+	}
+	written := make(map[string]bool, len(units))
+	for _, u := range units {
+		name := FileName(u.ID)
+		data, err := json.MarshalIndent(FromUnit(u), "", "  ")
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode fixture for %s: %w", u.ID, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), append(data, '\n'), 0644); err != nil {
+			return 0, fmt.Errorf("failed to write fixture %s: %w", name, err)
+		}
+		written[name] = true
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read fixtures directory %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" || written[entry.Name()] {
+			continue
+		}
+		if !isFixture(filepath.Join(dir, entry.Name())) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			return 0, fmt.Errorf("failed to remove stale fixture %s: %w", entry.Name(), err)
+		}
+	}
+	return len(written), nil
+}
+
+// isFixture reports whether path holds a JSON object with the id and stats a fixture has
+func isFixture(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var fields struct {
+		ID    string          `json:"id"`
+		Stats json.RawMessage `json:"stats"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return false
+	}
+	return fields.ID != "" && len(fields.Stats) > 0 && fields.Stats[0] == '{'
+}
+
+// Read loads every fixture in dir, sorted by unit ID. JSON files without stats aren't
+// fixtures and are skipped.
+func Read(dir string) ([]Fixture, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures directory %s: %w", dir, err)
+	}
+	var fixtures []Fixture
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture %s: %w", entry.Name(), err)
+		}
+		var fixture Fixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("failed to parse fixture %s: %w", entry.Name(), err)
+		}
+		if fixture.Stats == nil {
+			continue // Another JSON file kept next to the fixtures, e.g. modinfo.json
+		}
+		if fixture.ID == "" {
+			fixture.ID = strings.TrimSuffix(entry.Name(), ".json")
+		}
+		fixtures = append(fixtures, fixture)
+	}
+	sort.Slice(fixtures, func(i, j int) bool { return fixtures[i].ID < fixtures[j].ID })
+	return fixtures, nil
+}
+
+// UnitRef names a unit in a Result
+type UnitRef struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+}
+
+// Drift is one stat that no longer matches its fixture. Stat "tier" covers a unit that
+// moved tier.
+type Drift struct {
+	UnitRef
+	Stat     string  `json:"stat"`
+	Expected float64 `json:"expected"`
+	Actual   float64 `json:"actual"`
+}
+
+// Result is the outcome of checking an export against fixtures
+type Result struct {
+	Checked int       `json:"checked"` // Units with a fixture present in the export
+	Drift   []Drift   `json:"drift"`
+	Missing []UnitRef `json:"missing"` // Fixtures whose unit is no longer exported
+	New     []UnitRef `json:"new"`     // Exported units without a fixture
+}
+
+// Failed reports whether the result should fail a CI run: any drift or missing unit. New
+// units only need their fixtures generated, so they don't fail it.
+func (r *Result) Failed() bool {
+	return len(r.Drift) > 0 || len(r.Missing) > 0
+}
+
+// Check compares units against fixtures. A stat drifts when it differs from the expected
+// value by more than tolerance percent of it (0 requires an exact match, to the 2 decimal
+// places fixtures are stored with). Stats a fixture doesn't list aren't checked. Lists are
+// sorted by unit ID.
+func Check(fixtures []Fixture, units []*models.Unit, tolerance float64) *Result {
+	result := &Result{Drift: []Drift{}, Missing: []UnitRef{}, New: []UnitRef{}}
+	byID := make(map[string]*models.Unit, len(units))
+	for _, u := range units {
+		byID[u.ID] = u
+	}
+	expected := make(map[string]bool, len(fixtures))
+	for _, fixture := range fixtures {
+		expected[fixture.ID] = true
+		u, ok := byID[fixture.ID]
+		if !ok {
+			result.Missing = append(result.Missing, UnitRef{fixture.ID, fixture.DisplayName})
+			continue
+		}
+		result.Checked++
+		ref := UnitRef{u.ID, u.DisplayName}
+		if u.Tier != fixture.Tier {
+			result.Drift = append(result.Drift, Drift{ref, "tier", float64(fixture.Tier), float64(u.Tier)})
+		}
+		actual := FromUnit(u)
+		for _, metric := range stats.Metrics {
+			want, ok := fixture.Stats[metric.Name]
+			if !ok {
+				continue
+			}
+			if got := actual.Stats[metric.Name]; drifted(want, got, tolerance) {
+				result.Drift = append(result.Drift, Drift{ref, metric.Name, want, got})
+			}
+		}
+	}
+	for _, u := range units {
+		if !expected[u.ID] {
+			result.New = append(result.New, UnitRef{u.ID, u.DisplayName})
+		}
+	}
+
+	sort.SliceStable(result.Drift, func(i, j int) bool { return result.Drift[i].ID < result.Drift[j].ID })
+	sort.Slice(result.Missing, func(i, j int) bool { return result.Missing[i].ID < result.Missing[j].ID })
+	sort.Slice(result.New, func(i, j int) bool { return result.New[i].ID < result.New[j].ID })
+	return result
+}
+
+func drifted(want, got, tolerance float64) bool {
+	allowed := math.Abs(want) * tolerance / 100
+	return math.Abs(got-want) > allowed+1e-9
+}
+
+// Describe renders a drift for the terminal, e.g. "tank: health 200 → 250"
+func (d Drift) Describe() string {
+	return fmt.Sprintf("%s: %s %s → %s", d.ID, d.Stat, formatValue(d.Expected), formatValue(d.Actual))
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// round stores stats to 2 decimal places, so fixtures stay stable across float noise
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
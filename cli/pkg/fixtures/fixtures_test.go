@@ -0,0 +1,105 @@
+package fixtures
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func testUnit(id string, tier int, health, cost, dps float64) *models.Unit {
+	unit := &models.Unit{ID: id, DisplayName: id, Tier: tier, Accessible: true}
+	unit.Specs.Combat = &models.CombatSpecs{Health: health, DPS: dps}
+	unit.Specs.Economy = &models.EconomySpecs{BuildCost: cost}
+	return unit
+}
+
+func TestWriteRead(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "removed_unit.json"), []byte(`{"id": "removed_unit", "stats": {"health": 1}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("fixtures"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "modinfo.json"), []byte(`{"identifier": "com.example.mod"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	units := []*models.Unit{testUnit("tank", 1, 200, 150, 20.004), testUnit("bot", 1, 100, 90, 12)}
+	n, err := Write(dir, units)
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Write wrote %d fixtures, want 2", n)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "removed_unit.json")); !os.IsNotExist(err) {
+		t.Error("stale fixture removed_unit.json was kept")
+	}
+	for _, name := range []string{"README.md", "modinfo.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s was removed", name)
+		}
+	}
+
+	fixtures, err := Read(dir)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	want := []Fixture{
+		{ID: "bot", DisplayName: "bot", Tier: 1, Stats: map[string]float64{"dps": 12, "health": 100, "cost": 90, "speed": 0}},
+		{ID: "tank", DisplayName: "tank", Tier: 1, Stats: map[string]float64{"dps": 20, "health": 200, "cost": 150, "speed": 0}},
+	}
+	if !reflect.DeepEqual(fixtures, want) {
+		t.Errorf("Read = %+v, want %+v", fixtures, want)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	fixtures := []Fixture{
+		{ID: "tank", DisplayName: "tank", Tier: 1, Stats: map[string]float64{"health": 200, "cost": 150, "dps": 20}},
+		{ID: "bot", DisplayName: "bot", Tier: 1, Stats: map[string]float64{"health": 100}},
+		{ID: "removed", DisplayName: "Removed", Tier: 2, Stats: map[string]float64{"health": 1}},
+	}
+	units := []*models.Unit{
+		testUnit("tank", 2, 210, 150, 20),
+		testUnit("bot", 1, 100, 500, 0), // cost and dps aren't in its fixture
+		testUnit("added", 1, 50, 50, 5),
+	}
+
+	tests := []struct {
+		name      string
+		tolerance float64
+		drift     []string
+	}{
+		{"exact", 0, []string{"tank: tier 1 → 2", "tank: health 200 → 210"}},
+		{"within tolerance", 5, []string{"tank: tier 1 → 2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Check(fixtures, units, tt.tolerance)
+			var drift []string
+			for _, d := range result.Drift {
+				drift = append(drift, d.Describe())
+			}
+			if !reflect.DeepEqual(drift, tt.drift) {
+				t.Errorf("drift = %q, want %q", drift, tt.drift)
+			}
+			if result.Checked != 2 {
+				t.Errorf("checked = %d, want 2", result.Checked)
+			}
+			if want := []UnitRef{{"removed", "Removed"}}; !reflect.DeepEqual(result.Missing, want) {
+				t.Errorf("missing = %v, want %v", result.Missing, want)
+			}
+			if want := []UnitRef{{"added", "added"}}; !reflect.DeepEqual(result.New, want) {
+				t.Errorf("new = %v, want %v", result.New, want)
+			}
+			if !result.Failed() {
+				t.Error("Failed() = false with a missing unit")
+			}
+		})
+	}
+}
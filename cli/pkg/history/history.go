@@ -0,0 +1,124 @@
+// Package history follows one unit through the exported versions of a faction for the
+// history command: the value of each numeric stat per version, for balance history graphs.
+package history
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/serve"
+	"github.com/jamiemulcahy/pa-pedia/pkg/table"
+)
+
+// Snapshot is a unit as exported in one faction version
+type Snapshot struct {
+	Version string
+	PABuild string
+	Unit    *models.Unit // nil when the version doesn't have the unit
+}
+
+// Collect reads the unit from each version of a faction (as returned by
+// serve.Library.Versions, newest first) and returns the snapshots oldest first
+func Collect(versions []*serve.FactionVersion, unitID string) ([]Snapshot, error) {
+	snapshots := make([]Snapshot, len(versions))
+	for i, v := range versions {
+		index, err := exporter.ReadFactionIndexFS(v.FS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", v.Source, err)
+		}
+		snapshot := Snapshot{Version: v.Version, PABuild: v.Metadata.PABuild}
+		for j := range index.Units {
+			if index.Units[j].Identifier == unitID {
+				snapshot.Unit = &index.Units[j].Unit
+				break
+			}
+		}
+		snapshots[len(versions)-1-i] = snapshot
+	}
+	return snapshots, nil
+}
+
+// Build turns snapshots (oldest first) into a unit history. Stats are the numeric fields of
+// the unit as flattened by table.Flatten, in field order. With changedOnly, stats that are
+// the same in every version having the unit are left out.
+func Build(faction, unitID string, snapshots []Snapshot, changedOnly bool) (models.UnitHistory, error) {
+	history := models.UnitHistory{Faction: faction, Unit: unitID, Stats: []models.StatHistory{}}
+	var units []*models.Unit
+	var present []int // Snapshot index of each unit
+	for i, s := range snapshots {
+		history.Versions = append(history.Versions, models.HistoryVersion{Version: s.Version, PABuild: s.PABuild, Present: s.Unit != nil})
+		if s.Unit != nil {
+			units = append(units, s.Unit)
+			present = append(present, i)
+			history.DisplayName = s.Unit.DisplayName
+		}
+	}
+	if len(units) == 0 {
+		return history, nil
+	}
+
+	flat, err := table.Flatten(units)
+	if err != nil {
+		return history, fmt.Errorf("failed to flatten %s: %w", unitID, err)
+	}
+	for c, column := range flat.Columns {
+		values := make([]*float64, len(snapshots))
+		numeric := true
+		for r, row := range flat.Rows {
+			if row[c] == "" {
+				continue
+			}
+			v, err := strconv.ParseFloat(row[c], 64)
+			if err != nil {
+				numeric = false
+				break
+			}
+			values[present[r]] = &v
+		}
+		if !numeric {
+			continue
+		}
+		stat := models.StatHistory{Stat: column, Values: values, Changed: changed(values, present)}
+		if changedOnly && !stat.Changed {
+			continue
+		}
+		history.Stats = append(history.Stats, stat)
+	}
+	return history, nil
+}
+
+// changed reports whether values differ between any two of the versions at indices, a
+// missing value counting as different from any number
+func changed(values []*float64, indices []int) bool {
+	first := values[indices[0]]
+	for _, i := range indices[1:] {
+		v := values[i]
+		if (v == nil) != (first == nil) || (v != nil && *v != *first) {
+			return true
+		}
+	}
+	return false
+}
+
+// Table lays a history out for CSV: a row per version with its version, PA build and
+// whether it has the unit, then a column per stat
+func Table(history models.UnitHistory) *table.Table {
+	t := &table.Table{Columns: []string{"version", "paBuild", "present"}}
+	for _, s := range history.Stats {
+		t.Columns = append(t.Columns, s.Stat)
+	}
+	for i, v := range history.Versions {
+		row := []string{v.Version, v.PABuild, strconv.FormatBool(v.Present)}
+		for _, s := range history.Stats {
+			cell := ""
+			if s.Values[i] != nil {
+				cell = strconv.FormatFloat(*s.Values[i], 'f', -1, 64)
+			}
+			row = append(row, cell)
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
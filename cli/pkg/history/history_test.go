@@ -0,0 +1,97 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/serve"
+)
+
+func tank(health, cost float64) *models.Unit {
+	u := &models.Unit{ID: "tank", DisplayName: "Ant", Tier: 1}
+	u.Specs.Combat = &models.CombatSpecs{Health: health}
+	u.Specs.Economy = &models.EconomySpecs{BuildCost: cost}
+	return u
+}
+
+func TestBuild(t *testing.T) {
+	renamed := tank(150, 90)
+	renamed.DisplayName = "Ant II"
+	snapshots := []Snapshot{
+		{Version: "1.0.0", PABuild: "111", Unit: tank(100, 90)},
+		{Version: "1.1.0", PABuild: "111"},
+		{Version: "1.2.0", PABuild: "222", Unit: renamed},
+	}
+
+	history, err := Build("mla", "tank", snapshots, false)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if history.DisplayName != "Ant II" || len(history.Versions) != 3 || history.Versions[1].Present {
+		t.Fatalf("unexpected history header: %+v", history)
+	}
+	stats := make(map[string]models.StatHistory)
+	for _, s := range history.Stats {
+		stats[s.Stat] = s
+	}
+	health, ok := stats["specs.combat.health"]
+	if !ok || !health.Changed {
+		t.Fatalf("expected a changed health stat, got %+v", stats)
+	}
+	if *health.Values[0] != 100 || health.Values[1] != nil || *health.Values[2] != 150 {
+		t.Errorf("health values = %v", health.Values)
+	}
+	if cost := stats["specs.economy.buildCost"]; cost.Changed {
+		t.Errorf("build cost marked changed: %+v", cost)
+	}
+	if _, ok := stats["displayName"]; ok {
+		t.Error("string field listed as a stat")
+	}
+
+	changedOnly, _ := Build("mla", "tank", snapshots, true)
+	for _, s := range changedOnly.Stats {
+		if !s.Changed {
+			t.Errorf("unchanged stat %s kept with changedOnly", s.Stat)
+		}
+	}
+
+	rows := Table(changedOnly).Rows
+	if len(rows) != 3 || rows[1][2] != "false" || rows[2][0] != "1.2.0" {
+		t.Errorf("unexpected CSV rows: %v", rows)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	root := t.TempDir()
+	for i, health := range []float64{100, 120} {
+		dir := filepath.Join(root, "mla", fmt.Sprintf("1.%d.0", i))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		index := models.FactionIndex{Units: []models.UnitIndexEntry{{Identifier: "tank", Unit: *tank(health, 90)}}}
+		data, _ := json.Marshal(index)
+		metadata := fmt.Sprintf(`{"identifier":"mla","displayName":"MLA","version":"1.%d.0"}`, i)
+		if err := os.WriteFile(filepath.Join(dir, "units.json"), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "metadata.json"), []byte(metadata), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lib, errs := serve.ScanLibrary(root, nil)
+	if len(errs) > 0 {
+		t.Fatalf("ScanLibrary: %v", errs)
+	}
+	snapshots, err := Collect(lib.Versions("mla"), "tank")
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if len(snapshots) != 2 || snapshots[0].Version != "1.0.0" || snapshots[1].Unit.Specs.Combat.Health != 120 {
+		t.Errorf("expected snapshots oldest first, got %+v", snapshots)
+	}
+}
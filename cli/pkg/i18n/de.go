@@ -0,0 +1,44 @@
+package i18n
+
+// german holds the German messages
+var german = map[string]string{
+	// Updates
+	"New version available: %s (current: %s)\n": "Neue Version verfügbar: %s (aktuell: %s)\n",
+	"Updating...":                        "Aktualisiere...",
+	"Successfully updated to %s\n\n":     "Erfolgreich auf %s aktualisiert\n\n",
+	"Update failed: %v\n":                "Aktualisierung fehlgeschlagen: %v\n",
+	"Continuing with current version...": "Fahre mit der aktuellen Version fort...",
+
+	// describe-faction
+	"✓ Resuming from checkpoint: %d units parsed at %s\n":                                                 "✓ Setze vom Checkpoint fort: %d Einheiten, eingelesen am %s\n",
+	"No checkpoint to resume from, running a full extraction":                                             "Kein Checkpoint zum Fortsetzen vorhanden, führe eine vollständige Extraktion durch",
+	"⚠ Content QA: %d problem(s) recorded as unit warnings\n":                                             "⚠ Inhaltsprüfung: %d Problem(e) als Einheitenwarnungen vermerkt\n",
+	"✓ Content QA: no problems found\n":                                                                   "✓ Inhaltsprüfung: keine Probleme gefunden\n",
+	"failed to export faction: %w\n\nParsed units were checkpointed; rerun with --resume to skip parsing": "Export der Fraktion fehlgeschlagen: %w\n\nDie eingelesenen Einheiten wurden gesichert; mit --resume neu starten, um das Einlesen zu überspringen",
+	"⚠ %d unit(s) have files from more than one selected mod (see conflicts.json)\n":                      "⚠ %d Einheit(en) haben Dateien aus mehr als einem ausgewählten Mod (siehe conflicts.json)\n",
+	"\n✓ Faction extraction complete!\n":                                                                  "\n✓ Extraktion der Fraktion abgeschlossen!\n",
+	"Faction '%s' exported to: %s\n":                                                                      "Fraktion '%s' exportiert nach: %s\n",
+
+	// Unit conflicts
+	"No unit conflicts between selected mods":                                              "Keine Einheitenkonflikte zwischen den ausgewählten Mods",
+	"%d unit(s) defined by more than one selected mod:\n":                                  "%d Einheit(en) von mehr als einem ausgewählten Mod definiert:\n",
+	"  - %s: using %s (from profile)\n":                                                    "  - %s: verwende %s (aus dem Profil)\n",
+	"  - %s: using %s (overrides %s)\n":                                                    "  - %s: verwende %s (überschreibt %s)\n",
+	"⚠ Could not save conflict decisions: %v\nAdd them to a profile to keep them:\n%s\n\n": "⚠ Konfliktentscheidungen konnten nicht gespeichert werden: %v\nFüge sie einem Profil hinzu, um sie zu behalten:\n%s\n\n",
+	"✓ Saved %d conflict decision(s) to %s\n\n":                                            "✓ %d Konfliktentscheidung(en) in %s gespeichert\n\n",
+	"\n  %s is defined by:\n":                                                              "\n  %s wird definiert von:\n",
+	" (current)":                                                                           " (aktuell)",
+	"  Use which mod? [1]: ":                                                               "  Welchen Mod verwenden? [1]: ",
+	"  Enter a number from 1 to %d\n":                                                      "  Gib eine Zahl von 1 bis %d ein\n",
+	"--conflicts prompt needs a terminal\n\nUse --conflicts report to record the first-wins choices instead": "--conflicts prompt benötigt ein Terminal\n\nVerwende stattdessen --conflicts report, um die Standardauswahl (erster Mod gewinnt) zu speichern",
+	"unknown --conflicts mode '%s' (expected prompt or report)":                                              "unbekannter --conflicts-Modus '%s' (erwartet: prompt oder report)",
+
+	// validate
+	"✓ %d units, all references resolve\n":             "✓ %d Einheiten, alle Verweise aufgelöst\n",
+	"%d reference problem(s) in %s":                    "%d Verweisproblem(e) in %s",
+	"⚠ %d build reference problem(s) in the export:\n": "⚠ %d Bauverweisproblem(e) im Export:\n",
+	"  ... and %d more (run pa-pedia validate %s)\n":   "  ... und %d weitere (pa-pedia validate %s ausführen)\n",
+
+	// crash reports
+	"A crash report was written to %s\nPlease open an issue at %s, describe what you were doing and attach the file.\nPaths under your home folder and secrets are redacted; look inside before posting.": "Ein Absturzbericht wurde nach %s geschrieben\nBitte eröffne ein Issue unter %s, beschreibe, was du getan hast, und hänge die Datei an.\nPfade in deinem Benutzerordner und Geheimnisse sind geschwärzt; sieh vor dem Posten hinein.",
+}
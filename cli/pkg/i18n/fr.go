@@ -0,0 +1,44 @@
+package i18n
+
+// french holds the French messages
+var french = map[string]string{
+	// Updates
+	"New version available: %s (current: %s)\n": "Nouvelle version disponible : %s (actuelle : %s)\n",
+	"Updating...":                        "Mise à jour...",
+	"Successfully updated to %s\n\n":     "Mise à jour vers %s réussie\n\n",
+	"Update failed: %v\n":                "Échec de la mise à jour : %v\n",
+	"Continuing with current version...": "Poursuite avec la version actuelle...",
+
+	// describe-faction
+	"✓ Resuming from checkpoint: %d units parsed at %s\n":                                                 "✓ Reprise depuis le point de contrôle : %d unités analysées le %s\n",
+	"No checkpoint to resume from, running a full extraction":                                             "Aucun point de contrôle à reprendre, extraction complète",
+	"⚠ Content QA: %d problem(s) recorded as unit warnings\n":                                             "⚠ Contrôle du contenu : %d problème(s) enregistré(s) comme avertissements d'unité\n",
+	"✓ Content QA: no problems found\n":                                                                   "✓ Contrôle du contenu : aucun problème trouvé\n",
+	"failed to export faction: %w\n\nParsed units were checkpointed; rerun with --resume to skip parsing": "échec de l'export de la faction : %w\n\nLes unités analysées ont été sauvegardées ; relancez avec --resume pour sauter l'analyse",
+	"⚠ %d unit(s) have files from more than one selected mod (see conflicts.json)\n":                      "⚠ %d unité(s) ont des fichiers provenant de plusieurs mods sélectionnés (voir conflicts.json)\n",
+	"\n✓ Faction extraction complete!\n":                                                                  "\n✓ Extraction de la faction terminée !\n",
+	"Faction '%s' exported to: %s\n":                                                                      "Faction '%s' exportée vers : %s\n",
+
+	// Unit conflicts
+	"No unit conflicts between selected mods":                                              "Aucun conflit d'unités entre les mods sélectionnés",
+	"%d unit(s) defined by more than one selected mod:\n":                                  "%d unité(s) définie(s) par plusieurs mods sélectionnés :\n",
+	"  - %s: using %s (from profile)\n":                                                    "  - %s : utilise %s (depuis le profil)\n",
+	"  - %s: using %s (overrides %s)\n":                                                    "  - %s : utilise %s (remplace %s)\n",
+	"⚠ Could not save conflict decisions: %v\nAdd them to a profile to keep them:\n%s\n\n": "⚠ Impossible d'enregistrer les choix de conflits : %v\nAjoutez-les à un profil pour les conserver :\n%s\n\n",
+	"✓ Saved %d conflict decision(s) to %s\n\n":                                            "✓ %d choix de conflit enregistré(s) dans %s\n\n",
+	"\n  %s is defined by:\n":                                                              "\n  %s est défini par :\n",
+	" (current)":                                                                           " (actuel)",
+	"  Use which mod? [1]: ":                                                               "  Quel mod utiliser ? [1] : ",
+	"  Enter a number from 1 to %d\n":                                                      "  Entrez un nombre de 1 à %d\n",
+	"--conflicts prompt needs a terminal\n\nUse --conflicts report to record the first-wins choices instead": "--conflicts prompt nécessite un terminal\n\nUtilisez --conflicts report pour enregistrer les choix par défaut (le premier mod l'emporte)",
+	"unknown --conflicts mode '%s' (expected prompt or report)":                                              "mode --conflicts inconnu '%s' (attendu : prompt ou report)",
+
+	// validate
+	"✓ %d units, all references resolve\n":             "✓ %d unités, toutes les références sont résolues\n",
+	"%d reference problem(s) in %s":                    "%d problème(s) de référence dans %s",
+	"⚠ %d build reference problem(s) in the export:\n": "⚠ %d problème(s) de référence de construction dans l'export :\n",
+	"  ... and %d more (run pa-pedia validate %s)\n":   "  ... et %d de plus (lancez pa-pedia validate %s)\n",
+
+	// crash reports
+	"A crash report was written to %s\nPlease open an issue at %s, describe what you were doing and attach the file.\nPaths under your home folder and secrets are redacted; look inside before posting.": "Un rapport de plantage a été écrit dans %s\nOuvrez un ticket sur %s, décrivez ce que vous faisiez et joignez le fichier.\nLes chemins de votre dossier personnel et les secrets sont masqués ; vérifiez le contenu avant de le publier.",
+}
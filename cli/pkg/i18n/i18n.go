@@ -0,0 +1,92 @@
+// Package i18n translates the CLI's user-facing messages. Messages are looked up by their
+// English text (the printf format), gettext style, so a message without a translation
+// prints in English unchanged and call sites read the same as plain fmt calls.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Supported are the locales with a catalog, English (the messages themselves) first
+var Supported = []string{"en", "de", "fr"}
+
+// catalogs maps each translated locale to its messages, keyed by the English format
+var catalogs = map[string]map[string]string{
+	"de": german,
+	"fr": french,
+}
+
+var current = "en"
+
+// SetLocale selects the language of translated messages. It accepts a language code or a
+// POSIX/BCP 47 locale such as de_DE.UTF-8 or fr-CA; "", C and POSIX mean English.
+func SetLocale(locale string) error {
+	language := languageOf(locale)
+	if !slices.Contains(Supported, language) {
+		return fmt.Errorf("unsupported locale '%s' (supported: %s)", locale, strings.Join(Supported, ", "))
+	}
+	current = language
+	return nil
+}
+
+// Locale returns the selected language code
+func Locale() string {
+	return current
+}
+
+// Detect returns the language of the environment's locale (LC_ALL, then LC_MESSAGES, then
+// LANG), or "en" when it's unset or has no catalog
+func Detect() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			if language := languageOf(value); slices.Contains(Supported, language) {
+				return language
+			}
+			return "en"
+		}
+	}
+	return "en"
+}
+
+// languageOf reduces a locale to its lower-case language code
+func languageOf(locale string) string {
+	language := strings.ToLower(locale)
+	if i := strings.IndexAny(language, "_-.@"); i >= 0 {
+		language = language[:i]
+	}
+	if language == "" || language == "c" || language == "posix" {
+		return "en"
+	}
+	return language
+}
+
+// T returns message in the selected language
+func T(message string) string {
+	if translated, ok := catalogs[current][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// Printf is fmt.Printf with a translated format
+func Printf(format string, a ...any) {
+	fmt.Printf(T(format), a...)
+}
+
+// Println is fmt.Println of a translated message
+func Println(message string) {
+	fmt.Println(T(message))
+}
+
+// Sprintf is fmt.Sprintf with a translated format
+func Sprintf(format string, a ...any) string {
+	return fmt.Sprintf(T(format), a...)
+}
+
+// Errorf is fmt.Errorf with a translated format; %w wraps as usual
+func Errorf(format string, a ...any) error {
+	return fmt.Errorf(T(format), a...)
+}
@@ -0,0 +1,99 @@
+package i18n
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+// verbPattern matches printf verbs, ignoring the %% escape
+var verbPattern = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z]`)
+
+// TestCatalogsKeepVerbs guards against translations that would misprint their arguments
+func TestCatalogsKeepVerbs(t *testing.T) {
+	for locale, catalog := range catalogs {
+		for english, translated := range catalog {
+			want := verbPattern.FindAllString(english, -1)
+			got := verbPattern.FindAllString(translated, -1)
+			if !slices.Equal(got, want) {
+				t.Errorf("%s: %q has verbs %v, want %v (from %q)", locale, translated, got, want, english)
+			}
+		}
+	}
+}
+
+// TestCatalogsCoverSameMessages keeps every locale translating the same messages
+func TestCatalogsCoverSameMessages(t *testing.T) {
+	for locale, catalog := range catalogs {
+		for english := range german {
+			if _, ok := catalog[english]; !ok {
+				t.Errorf("%s has no translation of %q", locale, english)
+			}
+		}
+		if len(catalog) != len(german) {
+			t.Errorf("%s has %d messages, de has %d", locale, len(catalog), len(german))
+		}
+	}
+}
+
+func TestSetLocale(t *testing.T) {
+	t.Cleanup(func() { current = "en" })
+
+	tests := []struct {
+		locale  string
+		want    string
+		wantErr bool
+	}{
+		{"", "en", false},
+		{"C", "en", false},
+		{"de", "de", false},
+		{"de_DE.UTF-8", "de", false},
+		{"fr-CA", "fr", false},
+		{"FR", "fr", false},
+		{"es", "", true},
+	}
+	for _, tt := range tests {
+		err := SetLocale(tt.locale)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SetLocale(%q) error = %v, wantErr %v", tt.locale, err, tt.wantErr)
+			continue
+		}
+		if err == nil && Locale() != tt.want {
+			t.Errorf("SetLocale(%q) selected %q, want %q", tt.locale, Locale(), tt.want)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	t.Cleanup(func() { current = "en" })
+
+	if err := SetLocale("de"); err != nil {
+		t.Fatal(err)
+	}
+	if got := Sprintf("Faction '%s' exported to: %s\n", "MLA", "./factions"); got != "Fraktion 'MLA' exportiert nach: ./factions\n" {
+		t.Errorf("Sprintf() = %q", got)
+	}
+	if got := T("a message without a translation"); got != "a message without a translation" {
+		t.Errorf("T() = %q, want the English message", got)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		lcAll, lang string
+		want        string
+	}{
+		{"", "fr_FR.UTF-8", "fr"},
+		{"de_AT.UTF-8", "fr_FR.UTF-8", "de"},
+		{"", "ja_JP.UTF-8", "en"},
+		{"", "", "en"},
+	}
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LC_MESSAGES", "")
+		t.Setenv("LANG", tt.lang)
+		if got := Detect(); got != tt.want {
+			t.Errorf("Detect() with LC_ALL=%q LANG=%q = %q, want %q", tt.lcAll, tt.lang, got, tt.want)
+		}
+	}
+}
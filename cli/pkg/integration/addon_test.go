@@ -1,7 +1,10 @@
 package integration_test
 
 import (
+	"io/fs"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
@@ -72,6 +75,20 @@ func TestAddonExtraction(t *testing.T) {
 		}
 	}
 
+	// Addon units are built by the filtered-out base commander
+	edges := addonDB.CrossFactionEdges(baseDB)
+	for _, u := range units {
+		found := false
+		for _, e := range edges {
+			if e.Unit == u.ID && e.Relation == models.RelationBuiltBy && e.BaseUnit == "test_commander" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected a builtBy edge from test_commander to %s, got %+v", u.ID, edges)
+		}
+	}
+
 	// Create metadata
 	profile := &models.FactionProfile{
 		ID:          "test-addon",
@@ -184,6 +201,141 @@ func TestAddonExclusionFilter(t *testing.T) {
 	}
 }
 
+// TestAddonBaseSetFromSharedLoader tests that picking the base game units out of the
+// addon's own parse yields the same comparison set as parsing the base game separately.
+func TestAddonBaseSetFromSharedLoader(t *testing.T) {
+	paRoot := paRootPath(t)
+	dataRoot := dataRootPath(t)
+
+	allMods, _ := loader.FindAllMods(dataRoot, false)
+	addonInfo := allMods["com.test.addon"]
+
+	addonLoader, err := loader.NewMultiSourceLoader(paRoot, "pa_ex1", []*loader.ModInfo{addonInfo})
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	defer addonLoader.Close()
+
+	addonDB := parser.NewDatabase(addonLoader)
+	if err := addonDB.LoadUnitsNoFilter(false); err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	basePaths, err := addonLoader.LoadBaseUnitList()
+	if err != nil {
+		t.Fatalf("LoadBaseUnitList() error = %v", err)
+	}
+	shared := addonDB.Subset(addonDB.UnitIDsForResources(basePaths)).GetUnitIDs()
+
+	baseLoader, err := loader.NewMultiSourceLoader(paRoot, "pa_ex1", nil)
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	defer baseLoader.Close()
+
+	baseDB := parser.NewDatabase(baseLoader)
+	if err := baseDB.LoadUnitsNoFilter(false); err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	separate := baseDB.GetUnitIDs()
+
+	if len(shared) != len(separate) {
+		t.Errorf("shared-loader base set has %d units, separate parse has %d", len(shared), len(separate))
+	}
+	for id := range separate {
+		if !shared[id] {
+			t.Errorf("shared-loader base set is missing %s", id)
+		}
+	}
+
+	// Subset copies, so filtering the addon database afterwards leaves it intact
+	addonDB.FilterOutUnits(shared)
+	if len(addonDB.Units) != 2 {
+		t.Errorf("expected 2 addon units after filtering, got %d", len(addonDB.Units))
+	}
+}
+
+// TestAddonSpawnFromShadowedBaseUnit tests that a new addon unit spawned by a base unit the
+// addon shadows stays in the addon export instead of being counted as a base game unit.
+func TestAddonSpawnFromShadowedBaseUnit(t *testing.T) {
+	paRoot := paRootPath(t)
+
+	// Copy the addon and make its test_tank shadow spawn a new addon_wreck on death
+	dataRoot := t.TempDir()
+	modDir := filepath.Join(dataRoot, "server_mods", "com.test.addon")
+	src := filepath.Join(dataRootPath(t), "server_mods", "com.test.addon")
+	err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, p)
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(modDir, rel), 0755)
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(modDir, rel), data, 0644)
+	})
+	if err != nil {
+		t.Fatalf("failed to copy addon: %v", err)
+	}
+	tankPath := filepath.Join(modDir, "pa", "units", "land", "test_tank", "test_tank.json")
+	tank, err := os.ReadFile(tankPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tank = []byte(strings.Replace(string(tank), "{", `{
+  "spawn_unit_on_death": "/pa/units/land/addon_wreck/addon_wreck.json",`, 1))
+	if err := os.WriteFile(tankPath, tank, 0644); err != nil {
+		t.Fatal(err)
+	}
+	wreckDir := filepath.Join(modDir, "pa", "units", "land", "addon_wreck")
+	if err := os.MkdirAll(wreckDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	wreck := `{"display_name": "Addon Wreck", "max_health": 50, "unit_types": ["UNITTYPE_TestBase", "UNITTYPE_Structure", "UNITTYPE_Land"]}`
+	if err := os.WriteFile(filepath.Join(wreckDir, "addon_wreck.json"), []byte(wreck), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	allMods, err := loader.FindAllMods(dataRoot, false)
+	if err != nil {
+		t.Fatalf("failed to discover mods: %v", err)
+	}
+	addonLoader, err := loader.NewMultiSourceLoader(paRoot, "pa_ex1", []*loader.ModInfo{allMods["com.test.addon"]})
+	if err != nil {
+		t.Fatalf("failed to create addon loader: %v", err)
+	}
+	defer addonLoader.Close()
+
+	addonDB := parser.NewDatabase(addonLoader)
+	if err := addonDB.LoadUnitsNoFilter(false); err != nil {
+		t.Fatalf("failed to load addon units: %v", err)
+	}
+	if _, ok := addonDB.Units["addon_wreck"]; !ok {
+		t.Fatal("addon_wreck was not discovered from test_tank's spawn")
+	}
+
+	basePaths, err := addonLoader.LoadBaseUnitList()
+	if err != nil {
+		t.Fatalf("LoadBaseUnitList() error = %v", err)
+	}
+	baseIDs := addonDB.UnitIDsForResources(basePaths)
+	if baseIDs["addon_wreck"] {
+		t.Error("addon_wreck counted as a base game unit")
+	}
+	if !baseIDs["test_tank"] {
+		t.Error("shadowed test_tank not counted as a base game unit")
+	}
+
+	addonDB.FilterOutUnits(baseIDs)
+	if _, ok := addonDB.Units["addon_wreck"]; !ok {
+		t.Errorf("addon_wreck dropped from the addon export; kept %v", addonDB.GetUnitIDs())
+	}
+}
+
 // TestDetectBaseFactions tests that base factions are correctly detected from unit types.
 func TestDetectBaseFactions(t *testing.T) {
 	paRoot := paRootPath(t)
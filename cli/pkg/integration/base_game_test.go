@@ -1,7 +1,10 @@
 package integration_test
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
@@ -98,6 +101,19 @@ func TestBaseGameExtraction(t *testing.T) {
 	if commander.Unit.DisplayName != "Test Commander" {
 		t.Errorf("commander displayName = %q, want %q", commander.Unit.DisplayName, "Test Commander")
 	}
+
+	// The commander's build menu groups its builds into build bar tabs (the fixture commander
+	// can build itself, since its buildable_types is just "Mobile | Structure")
+	var tabs []string
+	for _, group := range commander.Unit.BuildMenu {
+		for _, row := range group.Tiers {
+			tabs = append(tabs, fmt.Sprintf("%s/T%d:%s", group.Category, row.Tier, strings.Join(row.Units, ",")))
+		}
+	}
+	wantTabs := []string{"factory/T1:test_factory", "utility/T1:test_mex", "vehicle/T1:test_commander,test_tank", "air/T1:test_fighter"}
+	if strings.Join(tabs, " ") != strings.Join(wantTabs, " ") {
+		t.Errorf("commander buildMenu = %v, want %v", tabs, wantTabs)
+	}
 }
 
 // TestExpansionShadowing tests that pa_ex1/ files take priority over pa/ files.
@@ -354,3 +370,155 @@ func TestIconDiscovery(t *testing.T) {
 		assertFileExists(t, iconPath)
 	}
 }
+
+// TestPlaceholderIcons tests that a unit without a buildbar icon in any source gets a
+// generated icon marked in the index.
+func TestPlaceholderIcons(t *testing.T) {
+	setupIconFixtures(t)
+
+	// Copy the base game without test_mex's icon
+	paRoot := filepath.Join(t.TempDir(), "pa_root")
+	if err := os.CopyFS(paRoot, os.DirFS(paRootPath(t))); err != nil {
+		t.Fatalf("failed to copy pa_root: %v", err)
+	}
+	if err := os.Remove(filepath.Join(paRoot, "pa/units/land/test_mex/test_mex_icon_buildbar.png")); err != nil {
+		t.Fatal(err)
+	}
+	outputDir := t.TempDir()
+
+	l, err := loader.NewMultiSourceLoader(paRoot, "pa_ex1", nil)
+	if err != nil {
+		t.Fatalf("failed to create loader: %v", err)
+	}
+	defer l.Close()
+
+	db := parser.NewDatabase(l)
+	if err := db.LoadUnits(false, "TestBase", false); err != nil {
+		t.Fatalf("failed to load units: %v", err)
+	}
+
+	metadata := exporter.CreateBaseGameMetadata("Placeholder Base", "Placeholder icon test")
+	exp := exporter.NewFactionExporter(outputDir, l, false)
+	if err := exp.ExportFaction(metadata, db.GetUnitsArray()); err != nil {
+		t.Fatalf("failed to export faction: %v", err)
+	}
+
+	factionDir := filepath.Join(outputDir, exporter.SanitizeFolderName("Placeholder Base"))
+	index := loadIndex(t, factionDir)
+
+	mex := findUnit(index, "test_mex")
+	if mex == nil {
+		t.Fatal("test_mex not found in index")
+	}
+	wantImage := "assets/pa/units/land/test_mex/test_mex_icon_buildbar.png"
+	if mex.Unit.Image != wantImage {
+		t.Errorf("test_mex image = %q, want %q", mex.Unit.Image, wantImage)
+	}
+	assertFileExists(t, filepath.Join(factionDir, wantImage))
+	generated := 0
+	for _, f := range mex.Files {
+		if f.Generated {
+			generated++
+			if f.Path != "pa/units/land/test_mex/test_mex_icon_buildbar.png" || f.Source != exporter.PlaceholderIconSource {
+				t.Errorf("generated file = %+v", f)
+			}
+		}
+	}
+	if generated != 1 {
+		t.Errorf("test_mex files = %+v, want one generated icon", mex.Files)
+	}
+
+	// Units with art keep it
+	tank := findUnit(index, "test_tank")
+	for _, f := range tank.Files {
+		if f.Generated {
+			t.Errorf("test_tank has a generated file: %+v", f)
+		}
+	}
+}
+
+// TestSilhouettes tests that units with a .papa model get top and side silhouettes
+// when the exporter renders them, and units without one get none.
+func TestSilhouettes(t *testing.T) {
+	setupIconFixtures(t)
+
+	// Point test_tank (the expansion's copy shadows the base game's) at the fixture model
+	paRoot := filepath.Join(t.TempDir(), "pa_root")
+	if err := os.CopyFS(paRoot, os.DirFS(paRootPath(t))); err != nil {
+		t.Fatalf("failed to copy pa_root: %v", err)
+	}
+	tankJSON := filepath.Join(paRoot, "pa_ex1/units/land/test_tank/test_tank.json")
+	data, err := os.ReadFile(tankJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), "{", `{"model": [{"filename": "/pa/units/land/test_tank/test_tank.papa"}],`, 1))
+	if err := os.WriteFile(tankJSON, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	outputDir := t.TempDir()
+
+	l, err := loader.NewMultiSourceLoader(paRoot, "pa_ex1", nil)
+	if err != nil {
+		t.Fatalf("failed to create loader: %v", err)
+	}
+	defer l.Close()
+
+	db := parser.NewDatabase(l)
+	if err := db.LoadUnits(false, "TestBase", false); err != nil {
+		t.Fatalf("failed to load units: %v", err)
+	}
+
+	metadata := exporter.CreateBaseGameMetadata("Silhouette Base", "Silhouette test")
+	exp := exporter.NewFactionExporter(outputDir, l, false)
+	exp.Silhouettes = 64
+	if err := exp.ExportFaction(metadata, db.GetUnitsArray()); err != nil {
+		t.Fatalf("failed to export faction: %v", err)
+	}
+
+	factionDir := filepath.Join(outputDir, exporter.SanitizeFolderName("Silhouette Base"))
+	index := loadIndex(t, factionDir)
+
+	tank := findUnit(index, "test_tank")
+	if tank == nil {
+		t.Fatal("test_tank not found in index")
+	}
+	var silhouettes []string
+	for _, f := range tank.Files {
+		if strings.Contains(f.Path, "_silhouette_") {
+			if !f.Generated || f.Source != exporter.PlaceholderIconSource {
+				t.Errorf("silhouette file = %+v, want generated", f)
+			}
+			silhouettes = append(silhouettes, f.Path)
+			assertFileExists(t, filepath.Join(factionDir, "assets", filepath.FromSlash(f.Path)))
+		}
+	}
+	want := []string{
+		"pa/units/land/test_tank/test_tank_silhouette_top.png",
+		"pa/units/land/test_tank/test_tank_silhouette_side.png",
+	}
+	if fmt.Sprint(silhouettes) != fmt.Sprint(want) {
+		t.Errorf("test_tank silhouettes = %v, want %v", silhouettes, want)
+	}
+
+	// No model, no silhouettes
+	for _, f := range findUnit(index, "test_mex").Files {
+		if strings.Contains(f.Path, "_silhouette_") {
+			t.Errorf("test_mex has a silhouette: %+v", f)
+		}
+	}
+
+	// --no-assets exports nothing rendered from game files either
+	noAssetsDir := t.TempDir()
+	exp = exporter.NewFactionExporter(noAssetsDir, l, false)
+	exp.Silhouettes = 64
+	exp.Assets = exporter.AssetsNone
+	if err := exp.ExportFaction(metadata, db.GetUnitsArray()); err != nil {
+		t.Fatalf("failed to export faction without assets: %v", err)
+	}
+	for _, f := range findUnit(loadIndex(t, filepath.Join(noAssetsDir, exporter.SanitizeFolderName("Silhouette Base"))), "test_tank").Files {
+		if strings.Contains(f.Path, "_silhouette_") {
+			t.Errorf("test_tank has a silhouette with assets none: %+v", f)
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package integration_test
 
 import (
+	"archive/zip"
 	"bytes"
 	"encoding/json"
+	"image/png"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
@@ -11,10 +15,17 @@ import (
 	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
 	"github.com/jamiemulcahy/pa-pedia/pkg/models"
 	"github.com/jamiemulcahy/pa-pedia/pkg/parser"
+	"github.com/jamiemulcahy/pa-pedia/pkg/protoindex"
 )
 
 // exportBaseGameFaction exports the base game faction to the given output directory.
 func exportBaseGameFaction(t *testing.T, outputDir string) string {
+	t.Helper()
+	return exportBaseGameFactionWith(t, outputDir, exporter.IndexOptions{})
+}
+
+// exportBaseGameFactionWith exports the base game faction writing its index files with opts.
+func exportBaseGameFactionWith(t *testing.T, outputDir string, opts exporter.IndexOptions) string {
 	t.Helper()
 	setupIconFixtures(t)
 	paRoot := paRootPath(t)
@@ -43,6 +54,7 @@ func exportBaseGameFaction(t *testing.T, outputDir string) string {
 	}
 
 	exp := exporter.NewFactionExporter(outputDir, l, false)
+	exp.Index = opts
 	if err := exp.ExportFaction(metadata, db.GetUnitsArray()); err != nil {
 		t.Fatalf("failed: %v", err)
 	}
@@ -79,6 +91,310 @@ func TestBaseGameOutputStructure(t *testing.T) {
 	}
 }
 
+// TestFlatLayoutOutputStructure validates the flat units/<id>/ layout and that the index records it.
+func TestFlatLayoutOutputStructure(t *testing.T) {
+	setupIconFixtures(t)
+	paRoot := paRootPath(t)
+	outputDir := t.TempDir()
+
+	l, err := loader.NewMultiSourceLoader(paRoot, "pa_ex1", nil)
+	if err != nil {
+		t.Fatalf("failed to create loader: %v", err)
+	}
+	defer l.Close()
+
+	db := parser.NewDatabase(l)
+	if err := db.LoadUnits(false, "TestBase", false); err != nil {
+		t.Fatalf("failed to load units: %v", err)
+	}
+
+	metadata := exporter.CreateBaseGameMetadata("Flat Base", "Flat layout test")
+	exp, err := exporter.NewExporter(exporter.LayoutFlat, exporter.IndexOptions{}, outputDir, l, false)
+	if err != nil {
+		t.Fatalf("failed to create exporter: %v", err)
+	}
+	if err := exp.ExportFaction(metadata, db.GetUnitsArray()); err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	factionDir := filepath.Join(outputDir, exporter.SanitizeFolderName("Flat Base"))
+	assertFileNotExists(t, filepath.Join(factionDir, "assets"))
+
+	expectedFiles := []string{
+		"units/test_tank/test_tank.json",
+		"units/test_tank/test_tank_icon_buildbar.png",
+		"units/test_tank/test_tank_tool_weapon.json",
+		"units/test_tank/test_tank_ammo.json",
+		"units/test_factory/test_factory.json",
+	}
+	for _, file := range expectedFiles {
+		assertFileExists(t, filepath.Join(factionDir, file))
+	}
+
+	index := loadIndex(t, factionDir)
+	if index.Layout != exporter.LayoutFlat {
+		t.Errorf("index layout = %q, want %q", index.Layout, exporter.LayoutFlat)
+	}
+
+	tank := findUnit(index, "test_tank")
+	if tank == nil {
+		t.Fatal("test_tank not found in index")
+	}
+	if tank.Unit.Image != "units/test_tank/test_tank_icon_buildbar.png" {
+		t.Errorf("test_tank image = %q, want units/test_tank/test_tank_icon_buildbar.png", tank.Unit.Image)
+	}
+	foundPrimary := false
+	for _, f := range tank.Files {
+		if f.Path == "test_tank/test_tank.json" {
+			foundPrimary = true
+		}
+	}
+	if !foundPrimary {
+		t.Errorf("test_tank files should record flat path test_tank/test_tank.json, got %+v", tank.Files)
+	}
+}
+
+// TestStrippedAssetsOutput validates that --strip-icons and --no-assets leave game files out
+// and record it in metadata.json.
+func TestStrippedAssetsOutput(t *testing.T) {
+	tests := []struct {
+		assets    string
+		wantSpecs bool
+	}{
+		{assets: exporter.AssetsIconsStripped, wantSpecs: true},
+		{assets: exporter.AssetsNone, wantSpecs: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.assets, func(t *testing.T) {
+			setupIconFixtures(t)
+			outputDir := t.TempDir()
+
+			l, err := loader.NewMultiSourceLoader(paRootPath(t), "pa_ex1", nil)
+			if err != nil {
+				t.Fatalf("failed to create loader: %v", err)
+			}
+			defer l.Close()
+
+			db := parser.NewDatabase(l)
+			if err := db.LoadUnits(false, "TestBase", false); err != nil {
+				t.Fatalf("failed to load units: %v", err)
+			}
+
+			exp := exporter.NewFactionExporter(outputDir, l, false)
+			exp.Assets = tt.assets
+			metadata := exporter.CreateBaseGameMetadata("Stripped Base", "Stripped assets test")
+			if err := exp.ExportFaction(metadata, db.GetUnitsArray()); err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+			factionDir := filepath.Join(outputDir, exporter.SanitizeFolderName("Stripped Base"))
+
+			if got := loadMetadata(t, factionDir).Assets; got != tt.assets {
+				t.Errorf("metadata assets = %q, want %q", got, tt.assets)
+			}
+
+			// The fixture icons are 1x1; a placeholder is generated in their place
+			iconPath := filepath.Join(factionDir, "assets/pa/units/land/test_tank/test_tank_icon_buildbar.png")
+			f, err := os.Open(iconPath)
+			if err != nil {
+				t.Fatalf("icon missing: %v", err)
+			}
+			defer f.Close()
+			cfg, err := png.DecodeConfig(f)
+			if err != nil {
+				t.Fatalf("failed to decode icon: %v", err)
+			}
+			if cfg.Width != exporter.PlaceholderIconSize {
+				t.Errorf("icon width = %d, want placeholder size %d", cfg.Width, exporter.PlaceholderIconSize)
+			}
+
+			specPath := filepath.Join(factionDir, "assets/pa/units/land/test_tank/test_tank.json")
+			if tt.wantSpecs {
+				assertFileExists(t, specPath)
+			} else {
+				assertFileNotExists(t, specPath)
+			}
+
+			tank := findUnit(loadIndex(t, factionDir), "test_tank")
+			if tank == nil {
+				t.Fatal("test_tank not found in index")
+			}
+			if tank.Unit.Image == "" {
+				t.Error("test_tank should still reference its placeholder icon")
+			}
+		})
+	}
+}
+
+// TestCompactIndexOutput validates that --minify/--prune-empty shrink units.json
+// without changing the data it decodes to.
+func TestCompactIndexOutput(t *testing.T) {
+	prettyDir := exportBaseGameFaction(t, t.TempDir())
+	compactDir := exportBaseGameFactionWith(t, t.TempDir(), exporter.IndexOptions{
+		JSON: exporter.JSONOptions{Minify: true, PruneEmpty: true},
+	})
+
+	pretty, err := os.ReadFile(filepath.Join(prettyDir, "units.json"))
+	if err != nil {
+		t.Fatalf("failed to read pretty units.json: %v", err)
+	}
+	compact, err := os.ReadFile(filepath.Join(compactDir, "units.json"))
+	if err != nil {
+		t.Fatalf("failed to read compact units.json: %v", err)
+	}
+
+	if bytes.Contains(compact, []byte("\n")) {
+		t.Error("minified units.json should not contain newlines")
+	}
+	if len(compact)*2 > len(pretty) {
+		t.Errorf("compact units.json is %d bytes, want at most half of pretty %d bytes", len(compact), len(pretty))
+	}
+
+	// Pruning only drops values that decode back to their zero value, so
+	// re-encoding both indexes the same way must give identical bytes.
+	canonical := exporter.JSONOptions{Minify: true, PruneEmpty: true}
+	prettyIndex := loadIndex(t, prettyDir)
+	compactIndex := loadIndex(t, compactDir)
+	want, err := canonical.Marshal(&prettyIndex)
+	if err != nil {
+		t.Fatalf("failed to re-encode pretty index: %v", err)
+	}
+	got, err := canonical.Marshal(&compactIndex)
+	if err != nil {
+		t.Fatalf("failed to re-encode compact index: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("compact units.json decodes to different data than the pretty export")
+	}
+
+	commander := findUnit(compactIndex, "test_commander")
+	if commander == nil || commander.Unit.Specs.Combat.Health == 0 || len(commander.Unit.UnitTypes) == 0 {
+		t.Errorf("required fields missing from compact commander entry: %+v", commander)
+	}
+}
+
+// TestBinaryIndexOutput validates that --format pb writes a units.pb that decodes
+// to the same index as units.json.
+func TestBinaryIndexOutput(t *testing.T) {
+	factionDir := exportBaseGameFactionWith(t, t.TempDir(), exporter.IndexOptions{Format: exporter.IndexFormatPB})
+
+	data, err := os.ReadFile(filepath.Join(factionDir, "units.pb"))
+	if err != nil {
+		t.Fatalf("failed to read units.pb: %v", err)
+	}
+	var binaryIndex models.FactionIndex
+	if err := protoindex.Unmarshal(data, &binaryIndex); err != nil {
+		t.Fatalf("failed to decode units.pb: %v", err)
+	}
+	jsonIndex := loadIndex(t, factionDir)
+	if jsonIndex.Schema != models.SchemaURL("faction-index") {
+		t.Errorf("units.json $schema = %q, want %q", jsonIndex.Schema, models.SchemaURL("faction-index"))
+	}
+	jsonIndex.Schema = "" // The $schema reference is JSON-only
+
+	// Empty lists decode as nil from protobuf, so compare the pruned encodings
+	canonical := exporter.JSONOptions{Minify: true, PruneEmpty: true}
+	want, err := canonical.Marshal(&jsonIndex)
+	if err != nil {
+		t.Fatalf("failed to encode JSON index: %v", err)
+	}
+	got, err := canonical.Marshal(&binaryIndex)
+	if err != nil {
+		t.Fatalf("failed to encode binary index: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("units.pb decodes to different data than units.json")
+	}
+	if len(data) >= len(want) {
+		t.Errorf("units.pb is %d bytes, want smaller than minified JSON (%d bytes)", len(data), len(want))
+	}
+}
+
+// TestZipExportMatchesFolder tests that ExportFactionZip streams the same files as a folder
+// export, plus what ZipFinish writes, without leaving anything else in the output folder.
+func TestZipExportMatchesFolder(t *testing.T) {
+	folder := exportBaseGameFaction(t, t.TempDir())
+
+	l, err := loader.NewMultiSourceLoader(paRootPath(t), "pa_ex1", nil)
+	if err != nil {
+		t.Fatalf("failed to create loader: %v", err)
+	}
+	defer l.Close()
+	db := parser.NewDatabase(l)
+	if err := db.LoadUnits(false, "TestBase", false); err != nil {
+		t.Fatalf("failed to load units: %v", err)
+	}
+	metadata, err := exporter.CreateMetadataFromProfile(&models.FactionProfile{
+		ID:              "test-base",
+		DisplayName:     "Test Base Game",
+		FactionUnitType: "TestBase",
+		Version:         "1.0.0",
+		Author:          "Test Author",
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	outputDir := t.TempDir()
+	exp := exporter.NewFactionExporter(outputDir, l, false)
+	exp.Parallelism = 4
+	exp.ZipFinish = func(factionDir string) error {
+		return os.WriteFile(filepath.Join(factionDir, "extra.json"), []byte("{}"), 0644)
+	}
+	dest, err := exp.ExportFactionZip(metadata, db.GetUnitsArray())
+	if err != nil {
+		t.Fatalf("ExportFactionZip() error = %v", err)
+	}
+	if want := filepath.Join(outputDir, "Test-Base-Game.zip"); dest != want {
+		t.Errorf("ExportFactionZip() = %s, want %s", dest, want)
+	}
+	if entries, _ := os.ReadDir(outputDir); len(entries) != 1 {
+		t.Errorf("output folder holds %d entries, want only the zip", len(entries))
+	}
+
+	zr, err := zip.OpenReader(dest)
+	if err != nil {
+		t.Fatalf("failed to open zip: %v", err)
+	}
+	defer zr.Close()
+	archived := make(map[string][]byte, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		archived[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+	if string(archived["extra.json"]) != "{}" {
+		t.Error("file written by ZipFinish is missing from the zip")
+	}
+	delete(archived, "extra.json")
+
+	err = filepath.WalkDir(folder, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(folder, p)
+		rel = filepath.ToSlash(rel)
+		want, _ := os.ReadFile(p)
+		got, ok := archived[rel]
+		if !ok {
+			t.Errorf("%s is missing from the zip", rel)
+		} else if !bytes.Equal(got, want) {
+			t.Errorf("%s differs between the zip and the folder", rel)
+		}
+		delete(archived, rel)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rel := range archived {
+		t.Errorf("%s is in the zip but not the folder", rel)
+	}
+}
+
 // TestModFactionOutputStructure validates the output structure for a mod faction.
 func TestModFactionOutputStructure(t *testing.T) {
 	setupIconFixtures(t)
@@ -2,6 +2,7 @@ package integration_test
 
 import (
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
@@ -180,6 +181,31 @@ func TestMetadataCreation(t *testing.T) {
 		}
 	})
 
+	t.Run("resolved mods recorded with version and commit", func(t *testing.T) {
+		profile := &models.FactionProfile{
+			ID:              "test",
+			DisplayName:     "Test",
+			FactionUnitType: "TestBase",
+			Mods:            []string{"com.test.mod", "github.com/owner/repo"},
+		}
+		mods := []*loader.ModInfo{
+			{Identifier: "com.test.mod", Version: "2.5.0", SourceType: loader.ModSourceServerMods},
+			{Identifier: "com.test.remote", Version: "1.0", SourceType: loader.ModSourceGitHub, GitHubURL: "github.com/owner/repo", GitHubRef: "main", GitHubCommit: "0123456789abcdef0123456789abcdef01234567"},
+		}
+
+		metadata, err := exporter.CreateMetadataFromProfile(profile, mods)
+		if err != nil {
+			t.Fatalf("failed: %v", err)
+		}
+		want := []models.ModVersion{
+			{Identifier: "com.test.mod", Version: "2.5.0", Source: "server_mods"},
+			{Identifier: "com.test.remote", Version: "1.0", Source: "github", Commit: "0123456789abcdef0123456789abcdef01234567"},
+		}
+		if !reflect.DeepEqual(metadata.ModVersions, want) {
+			t.Errorf("modVersions = %+v, want %+v", metadata.ModVersions, want)
+		}
+	})
+
 	t.Run("error when no version available", func(t *testing.T) {
 		profile := &models.FactionProfile{
 			ID:              "test",
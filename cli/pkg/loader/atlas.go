@@ -0,0 +1,318 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IconAtlas is a sheet of unit icons with a JSON frame list, typically shipped by mods
+// as ui/.../icon_atlas.json. Two JSON shapes are read:
+//
+//	{"image": "icon_atlas.png", "frames": {"tank": {"x": 0, "y": 0, "w": 128, "h": 128}}}
+//	{"meta": {"image": "icons.png"}, "frames": {"tank.png": {"frame": {"x": 0, "y": 0, "w": 128, "h": 128}}}}
+//
+// The second is TexturePacker's hash format; its array format (frames as a list with a
+// "filename") works too. Frame names are unit IDs, optionally with a folder, .png or
+// _icon_buildbar suffix; rotated frames are skipped. The image is a PNG, relative to
+// the JSON file or a resource path starting with /.
+type IconAtlas struct {
+	Source       string                     // Source identifier providing the atlas
+	ResourcePath string                     // Resource path of the JSON file
+	ImagePath    string                     // Resource path of the image
+	Frames       map[string]image.Rectangle // Unit ID -> icon rectangle
+
+	imageFullPath string // Filesystem path or zip entry of the image
+	isZip         bool
+}
+
+// AtlasFrame locates a unit icon within an atlas image
+type AtlasFrame struct {
+	Atlas *IconAtlas
+	Rect  image.Rectangle
+}
+
+type atlasFrameJSON struct {
+	Filename string `json:"filename"`
+	X        int    `json:"x"`
+	Y        int    `json:"y"`
+	W        int    `json:"w"`
+	H        int    `json:"h"`
+	Frame    *struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+		W int `json:"w"`
+		H int `json:"h"`
+	} `json:"frame"`
+	Rotated bool `json:"rotated"`
+}
+
+func (f atlasFrameJSON) rect() image.Rectangle {
+	if f.Frame != nil {
+		return image.Rect(f.Frame.X, f.Frame.Y, f.Frame.X+f.Frame.W, f.Frame.Y+f.Frame.H)
+	}
+	return image.Rect(f.X, f.Y, f.X+f.W, f.Y+f.H)
+}
+
+// isAtlasFile reports whether a file name looks like an icon atlas description
+func isAtlasFile(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "icon_atlas") && strings.HasSuffix(name, ".json")
+}
+
+// IconAtlases returns the icon atlases in every source, highest priority first, and
+// errors for atlases that couldn't be read (which are skipped). Folders are searched
+// under ui/, zips throughout. Discovery runs once per loader.
+func (l *Loader) IconAtlases() ([]*IconAtlas, []error) {
+	if l.atlasesLoaded {
+		return l.atlases, l.atlasErrors
+	}
+	l.atlasesLoaded = true
+
+	for _, src := range l.sources {
+		var found []string // Paths relative to the source root
+		if src.IsZip {
+			for p := range src.zipIndex {
+				if isAtlasFile(path.Base(p)) {
+					found = append(found, p)
+				}
+			}
+		} else {
+			uiDir := filepath.Join(src.Path, "ui")
+			// A source without ui/ has no atlases; unreadable folders are skipped
+			filepath.WalkDir(uiDir, func(p string, entry fs.DirEntry, err error) error {
+				if err != nil {
+					return nil
+				}
+				if !entry.IsDir() && isAtlasFile(entry.Name()) {
+					if rel, err := filepath.Rel(src.Path, p); err == nil {
+						found = append(found, filepath.ToSlash(rel))
+					}
+				}
+				return nil
+			})
+		}
+		sort.Strings(found)
+
+		for _, rel := range found {
+			atlas, err := l.loadAtlas(src, rel)
+			if err != nil {
+				l.atlasErrors = append(l.atlasErrors, fmt.Errorf("icon atlas %s in %s: %w", rel, src.Identifier, err))
+				continue
+			}
+			l.atlases = append(l.atlases, atlas)
+		}
+	}
+	return l.atlases, l.atlasErrors
+}
+
+// loadAtlas parses an atlas JSON file at a path relative to a source root
+func (l *Loader) loadAtlas(src Source, rel string) (*IconAtlas, error) {
+	data, err := l.readFile(src, l.sourceFullPath(src, rel))
+	if err != nil {
+		return nil, err
+	}
+	var raw struct {
+		Image string `json:"image"`
+		Meta  struct {
+			Image string `json:"image"`
+		} `json:"meta"`
+		Frames json.RawMessage `json:"frames"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	var frames []atlasFrameJSON
+	var byName map[string]atlasFrameJSON
+	if err := json.Unmarshal(raw.Frames, &byName); err == nil {
+		for name, f := range byName {
+			f.Filename = name
+			frames = append(frames, f)
+		}
+	} else if err := json.Unmarshal(raw.Frames, &frames); err != nil {
+		return nil, fmt.Errorf("frames must be an object or a list")
+	}
+
+	resourcePath := sourceResourcePath(src, rel)
+	atlas := &IconAtlas{
+		Source:       src.Identifier,
+		ResourcePath: resourcePath,
+		Frames:       make(map[string]image.Rectangle),
+		isZip:        src.IsZip,
+	}
+
+	imageRef := raw.Image
+	if imageRef == "" {
+		imageRef = raw.Meta.Image
+	}
+	if imageRef == "" {
+		return nil, fmt.Errorf("no image")
+	}
+	imageRel := path.Join(path.Dir(rel), imageRef)
+	if strings.HasPrefix(imageRef, "/") {
+		// A resource path; the atlas image must come from the same source
+		imageRel = strings.TrimPrefix(imageRef, "/")
+		if !src.IsZip && (src.Type == ModSourceBaseGame || src.Type == ModSourceExpansion) {
+			imageRel = strings.TrimPrefix(imageRel, src.Identifier+"/")
+		}
+	}
+	if strings.HasPrefix(imageRel, "../") || imageRel == ".." {
+		return nil, fmt.Errorf("image %q is outside the source", imageRef)
+	}
+	atlas.ImagePath = sourceResourcePath(src, imageRel)
+	atlas.imageFullPath = l.sourceFullPath(src, imageRel)
+
+	for _, f := range frames {
+		if f.Rotated {
+			continue
+		}
+		r := f.rect()
+		if r.Empty() {
+			continue
+		}
+		atlas.Frames[atlasUnitID(f.Filename)] = r
+	}
+	return atlas, nil
+}
+
+// atlasUnitID turns a frame name (tank, units/tank.png, tank_icon_buildbar.png) into a
+// unit ID
+func atlasUnitID(name string) string {
+	id := path.Base(filepath.ToSlash(name))
+	id = strings.TrimSuffix(id, path.Ext(id))
+	return strings.TrimSuffix(id, "_icon_buildbar")
+}
+
+// atlasIcon returns the largest atlas frame for a unit, or nil. Equal sizes go to the
+// higher priority source.
+func (l *Loader) atlasIcon(unitID string) *AtlasFrame {
+	atlases, _ := l.IconAtlases()
+	var best *AtlasFrame
+	for _, atlas := range atlases {
+		r, ok := atlas.Frames[unitID]
+		if !ok {
+			continue
+		}
+		if best == nil || area(r) > area(best.Rect) {
+			best = &AtlasFrame{Atlas: atlas, Rect: r}
+		}
+	}
+	return best
+}
+
+// betterIcon picks between a discovered icon file and an atlas frame for a unit: the
+// atlas wins only if its frame has more pixels than the loose PNG
+func (l *Loader) betterIcon(unitID string, loose *UnitFileInfo) *UnitFileInfo {
+	frame := l.atlasIcon(unitID)
+	if frame == nil {
+		return loose
+	}
+	if loose != nil {
+		data, err := l.ReadUnitFile(loose)
+		if err == nil {
+			if cfg, err := png.DecodeConfig(bytes.NewReader(data)); err == nil && cfg.Width*cfg.Height >= area(frame.Rect) {
+				return loose
+			}
+		}
+	}
+	return &UnitFileInfo{
+		RelativePath: IconName(unitID),
+		FullPath:     frame.Atlas.imageFullPath,
+		Source:       frame.Atlas.Source,
+		IsFromZip:    frame.Atlas.isZip,
+		Atlas:        frame,
+	}
+}
+
+// ReadUnitFile returns a unit file's bytes; for an icon from an atlas, the cropped frame
+// encoded as PNG
+func (l *Loader) ReadUnitFile(info *UnitFileInfo) ([]byte, error) {
+	src, ok := l.source(info.Source)
+	if !ok {
+		if info.IsFromZip {
+			return nil, fmt.Errorf("zip source not found for %s", info.Source)
+		}
+		// Files from outside the sources, such as icon overrides
+		src = Source{Identifier: info.Source}
+	}
+	data, err := l.readFile(src, info.FullPath)
+	if err != nil || info.Atlas == nil {
+		return data, err
+	}
+
+	sheet, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode atlas image %s: %w", info.Atlas.Atlas.ImagePath, err)
+	}
+	r := info.Atlas.Rect
+	if !r.In(sheet.Bounds()) {
+		return nil, fmt.Errorf("frame %v is outside atlas image %s (%v)", r, info.Atlas.Atlas.ImagePath, sheet.Bounds())
+	}
+	icon := image.NewNRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(icon, icon.Bounds(), sheet, r.Min, draw.Src)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, icon); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (l *Loader) source(identifier string) (Source, bool) {
+	for _, src := range l.sources {
+		if src.Identifier == identifier {
+			return src, true
+		}
+	}
+	return Source{}, false
+}
+
+// sourceFullPath maps a path relative to a source root to a zip entry or file path
+func (l *Loader) sourceFullPath(src Source, rel string) string {
+	if src.IsZip {
+		return rel
+	}
+	return filepath.Join(src.Path, filepath.FromSlash(rel))
+}
+
+// readFromSource reads a file from a source: a zip entry (with or without the
+// archive's path prefix) or a filesystem path
+func readFromSource(src Source, fullPath string) ([]byte, error) {
+	if !src.IsZip {
+		return os.ReadFile(fullPath)
+	}
+	normalized := strings.TrimPrefix(filepath.ToSlash(fullPath), "/")
+	if src.zipPathPrefix != "" {
+		normalized = strings.TrimPrefix(normalized, src.zipPathPrefix)
+	}
+	file, ok := src.zipIndex[normalized]
+	if !ok {
+		return nil, fmt.Errorf("file not found in zip %s: %s", src.Identifier, fullPath)
+	}
+	if file.UncompressedSize64 > maxZipEntrySize {
+		return nil, fmt.Errorf("file too large: %s (%d bytes)", fullPath, file.UncompressedSize64)
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// maxZipEntrySize bounds single-file reads from mod zips, matching the exporter's limit
+const maxZipEntrySize = 100 * 1024 * 1024
+
+func area(r image.Rectangle) int {
+	return r.Dx() * r.Dy()
+}
@@ -0,0 +1,145 @@
+package loader
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestPNG(t *testing.T, p string, w, h int, fill func(x, y int) color.NRGBA) {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, fill(x, y))
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAtlasUnitID(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"tank", "tank"},
+		{"tank.png", "tank"},
+		{"units/land/tank_icon_buildbar.png", "tank"},
+		{"/pa/units/land/tank/tank.json", "tank"},
+	}
+	for _, tt := range tests {
+		if got := atlasUnitID(tt.name); got != tt.want {
+			t.Errorf("atlasUnitID(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAtlasIcons(t *testing.T) {
+	paRoot := t.TempDir()
+	red := color.NRGBA{255, 0, 0, 255}
+	blue := color.NRGBA{0, 0, 255, 255}
+	for _, rel := range []string{"pa/units/land/tank/tank.json", "pa/units/land/bot/bot.json", "pa/units/land/boat/boat.json"} {
+		p := filepath.Join(paRoot, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Loose icons: tank's is smaller than its atlas frame, bot's is larger
+	writeTestPNG(t, filepath.Join(paRoot, "pa/units/land/tank/tank_icon_buildbar.png"), 60, 60, func(int, int) color.NRGBA { return blue })
+	writeTestPNG(t, filepath.Join(paRoot, "pa/units/land/bot/bot_icon_buildbar.png"), 200, 200, func(int, int) color.NRGBA { return blue })
+
+	// A 256x128 sheet: red on the left half, blue on the right
+	writeTestPNG(t, filepath.Join(paRoot, "pa/ui/atlas/icon_atlas.png"), 256, 128, func(x, _ int) color.NRGBA {
+		if x < 128 {
+			return red
+		}
+		return blue
+	})
+	atlasJSON := `{"image": "icon_atlas.png", "frames": {
+		"tank": {"x": 0, "y": 0, "w": 128, "h": 128},
+		"bot_icon_buildbar.png": {"x": 128, "y": 0, "w": 128, "h": 128},
+		"boat": {"x": 0, "y": 0, "w": 64, "h": 64}
+	}}`
+	if err := os.WriteFile(filepath.Join(paRoot, "pa/ui/atlas/icon_atlas.json"), []byte(atlasJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A TexturePacker atlas in array form and a broken one
+	packerJSON := `{"meta": {"image": "/pa/ui/atlas/icon_atlas.png"}, "frames": [
+		{"filename": "boat.png", "frame": {"x": 128, "y": 0, "w": 100, "h": 100}},
+		{"filename": "tank.png", "frame": {"x": 0, "y": 0, "w": 128, "h": 128}, "rotated": true}
+	]}`
+	if err := os.WriteFile(filepath.Join(paRoot, "pa/ui/atlas/icon_atlas_packed.json"), []byte(packerJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(paRoot, "pa/ui/atlas/icon_atlas_broken.json"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := NewMultiSourceLoader(paRoot, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	atlases, errs := l.IconAtlases()
+	if len(atlases) != 2 || len(errs) != 1 {
+		t.Fatalf("IconAtlases() = %d atlases, %d errors (%v), want 2 and 1", len(atlases), len(errs), errs)
+	}
+
+	tests := []struct {
+		unitPath  string
+		fromAtlas bool
+		size      int
+		color     color.NRGBA
+	}{
+		// The 128px frame beats the 60px loose icon
+		{"/pa/units/land/tank/tank.json", true, 128, red},
+		// The 200px loose icon beats the 128px frame
+		{"/pa/units/land/bot/bot.json", false, 200, blue},
+		// No loose icon: the larger of two frames, from the TexturePacker atlas
+		{"/pa/units/land/boat/boat.json", true, 100, blue},
+	}
+	for _, tt := range tests {
+		files, err := l.GetAllFilesForUnit(tt.unitPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		icon := files[IconName(filepath.Base(filepath.Dir(tt.unitPath)))]
+		if icon == nil {
+			t.Fatalf("%s: no icon", tt.unitPath)
+		}
+		if (icon.Atlas != nil) != tt.fromAtlas {
+			t.Errorf("%s: from atlas = %v, want %v", tt.unitPath, icon.Atlas != nil, tt.fromAtlas)
+		}
+		data, err := l.ReadUnitFile(icon)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := img.Bounds(); b.Dx() != tt.size || b.Dy() != tt.size {
+			t.Errorf("%s: icon is %v, want %dx%d", tt.unitPath, b, tt.size, tt.size)
+		}
+		if got := color.NRGBAModel.Convert(img.At(0, 0)).(color.NRGBA); got != tt.color {
+			t.Errorf("%s: icon color = %v, want %v", tt.unitPath, got, tt.color)
+		}
+	}
+}
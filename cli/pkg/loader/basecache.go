@@ -0,0 +1,45 @@
+package loader
+
+import "sync"
+
+// BaseCache shares the parsed spec JSON of the base game and expansion folders between
+// loaders, so exporting several factions in one run reads each /pa/ file once. Mod sources
+// are never cached here: they differ between factions. Safe for concurrent use.
+type BaseCache struct {
+	mu    sync.RWMutex
+	files map[string]map[string]interface{} // Full file path -> parsed JSON, nil when missing
+}
+
+// NewBaseCache returns an empty cache to pass to each loader's SetBaseCache
+func NewBaseCache() *BaseCache {
+	return &BaseCache{files: make(map[string]map[string]interface{})}
+}
+
+// SetBaseCache makes the loader read base game and expansion JSON through c. The cached maps
+// are shared with other loaders, so callers must treat GetJSON results as read-only (as the
+// parser does). Call it before reading starts.
+func (l *Loader) SetBaseCache(c *BaseCache) {
+	l.base = c
+}
+
+// lookup returns the cached JSON of a file and whether the file has been looked up before;
+// a file known to be missing gives nil, true
+func (c *BaseCache) lookup(fullPath string) (map[string]interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	data, ok := c.files[fullPath]
+	return data, ok
+}
+
+func (c *BaseCache) store(fullPath string, data map[string]interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files[fullPath] = data
+}
+
+// Len returns how many files the cache has looked up, found or missing
+func (c *BaseCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.files)
+}
@@ -0,0 +1,56 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestBaseCache verifies loaders sharing a cache read each base game file once, while mod
+// files, which differ between factions, are still read by each loader
+func TestBaseCache(t *testing.T) {
+	paRoot := t.TempDir()
+	modDir := t.TempDir()
+	for path, content := range map[string]string{
+		filepath.Join(paRoot, "pa", "units", "land", "tank", "tank.json"): `{"max_health": 200}`,
+		filepath.Join(paRoot, "pa", "units", "land", "bot", "bot.json"):   `{"max_health": 100}`,
+		filepath.Join(modDir, "pa", "units", "land", "bot", "bot.json"):   `{"max_health": 150}`,
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mod := &ModInfo{Identifier: "com.test.mod", Directory: modDir, SourceType: ModSourceServerMods}
+	names := []string{"/pa/units/land/tank/tank.json", "/pa/units/land/bot/bot.json", "/pa/units/land/missing/missing.json"}
+
+	cache := NewBaseCache()
+	reads := func(mods []*ModInfo) map[string]int {
+		l, err := NewMultiSourceLoader(paRoot, "pa_ex1", mods)
+		if err != nil {
+			t.Fatalf("failed to create loader: %v", err)
+		}
+		defer l.Close()
+		l.SetBaseCache(cache)
+		for _, name := range names {
+			l.GetJSON(name)
+		}
+		if data, err := l.GetJSON("/pa/units/land/tank/tank.json"); err != nil || data["max_health"] != float64(200) {
+			t.Errorf("tank = %v, %v; want max_health 200", data, err)
+		}
+		files := make(map[string]int)
+		for _, s := range l.ReadStats() {
+			files[s.Source] = s.Files
+		}
+		return files
+	}
+
+	if got := reads(nil); got["pa"] != 2 {
+		t.Errorf("first loader read %v, want 2 files from pa", got)
+	}
+	if got := reads([]*ModInfo{mod}); got["pa"] != 0 || got["com.test.mod"] != 1 {
+		t.Errorf("second loader read %v, want pa served from the cache and the mod's bot read", got)
+	}
+}
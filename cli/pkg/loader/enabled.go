@@ -0,0 +1,45 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ModsFile is where PA's mod manager records the enabled mods of server_mods and
+// client_mods, as {"mount_order": [identifiers]} in the order they're mounted
+const ModsFile = "mods.json"
+
+// EnabledMods returns the identifiers of the mods enabled under paDataRoot, highest
+// priority first as the loader takes them: server mods before client mods, and within each
+// the last mounted first, since a later mount overrides an earlier one. Folders without a
+// mods.json contribute none.
+func EnabledMods(paDataRoot string) ([]string, error) {
+	var enabled []string
+	seen := make(map[string]bool)
+	for _, folder := range []string{"server_mods", "client_mods"} {
+		path := filepath.Join(paDataRoot, folder, ModsFile)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		var mods struct {
+			MountOrder []string `json:"mount_order"`
+		}
+		if err := json.Unmarshal(data, &mods); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		for i := len(mods.MountOrder) - 1; i >= 0; i-- {
+			if id := mods.MountOrder[i]; id != "" && !seen[id] {
+				seen[id] = true
+				enabled = append(enabled, id)
+			}
+		}
+	}
+	return enabled, nil
+}
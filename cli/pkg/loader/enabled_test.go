@@ -0,0 +1,52 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestEnabledMods(t *testing.T) {
+	write := func(root, folder, content string) {
+		t.Helper()
+		dir := filepath.Join(root, folder)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, ModsFile), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		server  string
+		client  string
+		want    []string
+		wantErr bool
+	}{
+		{"no mods.json", "", "", nil, false},
+		{"last mounted first", `{"mount_order": ["com.base", "com.balance"]}`, "", []string{"com.balance", "com.base"}, false},
+		{"server before client, kept once", `{"mount_order": ["com.units"]}`, `{"mount_order": ["com.ui", "com.units"]}`, []string{"com.units", "com.ui"}, false},
+		{"malformed", `{"mount_order": "com.units"}`, "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if tt.server != "" {
+				write(root, "server_mods", tt.server)
+			}
+			if tt.client != "" {
+				write(root, "client_mods", tt.client)
+			}
+			got, err := EnabledMods(root)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EnabledMods error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EnabledMods = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -230,13 +230,80 @@ func LoadModInfoFromGitHubArchive(src *GitHubSource, zipPath string) (*ModInfo,
 	return &modInfo, nil
 }
 
-// ResolveGitHubMod downloads and resolves a GitHub repository as a mod source
-func ResolveGitHubMod(urlString string, verbose bool) (*ModInfo, error) {
+// gitHubAPI is the GitHub REST API base URL (replaced in tests)
+var gitHubAPI = "https://api.github.com"
+
+// commitSHA matches a full 40-character commit SHA
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// ResolveGitHubCommit returns the commit SHA a GitHub URL's ref (branch, tag or SHA)
+// currently points to, via the GitHub API. GITHUB_TOKEN (or GH_TOKEN) authenticates the
+// request when set, which raises the API's rate limit.
+func ResolveGitHubCommit(urlString string) (string, error) {
+	src, err := ParseGitHubURL(urlString)
+	if err != nil {
+		return "", err
+	}
+	if commitSHA.MatchString(src.Ref) {
+		return src.Ref, nil
+	}
+
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/commits/%s", gitHubAPI, src.Owner, src.Repo, url.PathEscape(src.Ref))
+	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build GitHub API request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github.sha") // Plain-text SHA instead of the commit JSON
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s@%s: %w", src.Owner+"/"+src.Repo, src.Ref, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// Success
+	case http.StatusNotFound, http.StatusUnprocessableEntity:
+		return "", fmt.Errorf("ref %s not found in %s/%s\nEnsure the branch or tag exists and the repository is public", src.Ref, src.Owner, src.Repo)
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		return "", fmt.Errorf("GitHub API refused to resolve %s (HTTP %d)\nThe unauthenticated rate limit may be exhausted; set GITHUB_TOKEN to raise it", src.URL, resp.StatusCode)
+	default:
+		return "", fmt.Errorf("GitHub API returned HTTP %d resolving %s", resp.StatusCode, src.URL)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit for %s: %w", src.URL, err)
+	}
+	sha := strings.TrimSpace(string(body))
+	if !commitSHA.MatchString(sha) {
+		return "", fmt.Errorf("GitHub API returned an unexpected commit %q for %s", sha, src.URL)
+	}
+	return sha, nil
+}
+
+// ResolveGitHubMod downloads and resolves a GitHub repository as a mod source. A non-empty
+// commit downloads that commit instead of the URL's ref (for pinned exports) and is recorded
+// on the ModInfo.
+func ResolveGitHubMod(urlString, commit string, verbose bool) (*ModInfo, error) {
 	// Parse the URL
 	src, err := ParseGitHubURL(urlString)
 	if err != nil {
 		return nil, err
 	}
+	ref := src.Ref
+	if commit != "" {
+		src.Ref = commit // The archive's root folder is named after the commit too
+	}
 
 	// Download the archive
 	zipPath, err := DownloadGitHubArchive(src, verbose)
@@ -249,6 +316,9 @@ func ResolveGitHubMod(urlString string, verbose bool) (*ModInfo, error) {
 	if err != nil {
 		return nil, err
 	}
+	modInfo.GitHubURL = urlString
+	modInfo.GitHubRef = ref
+	modInfo.GitHubCommit = commit
 
 	return modInfo, nil
 }
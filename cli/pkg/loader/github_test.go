@@ -1,6 +1,9 @@
 package loader
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -250,3 +253,59 @@ func TestGetGitHubArchiveURL(t *testing.T) {
 	}
 }
 
+
+func TestResolveGitHubCommit(t *testing.T) {
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		if r.Header.Get("Accept") != "application/vnd.github.sha" {
+			t.Errorf("Accept = %q, want the plain SHA media type", r.Header.Get("Accept"))
+		}
+		switch r.URL.Path {
+		case "/repos/owner/repo/commits/main", "/repos/owner/repo/commits/v1.0":
+			w.Write([]byte(sha))
+		case "/repos/owner/repo/commits/broken":
+			w.Write([]byte("<html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer func(api string) { gitHubAPI = api }(gitHubAPI)
+	gitHubAPI = server.URL
+
+	tests := []struct {
+		name    string
+		url     string
+		want    string
+		wantErr string
+	}{
+		{"default branch", "github.com/owner/repo", sha, ""},
+		{"tag", "github.com/owner/repo/tree/v1.0", sha, ""},
+		{"full SHA needs no request", "github.com/owner/repo/tree/" + strings.Repeat("a", 40), strings.Repeat("a", 40), ""},
+		{"missing ref", "github.com/owner/repo/tree/gone", "", "not found"},
+		{"unexpected body", "github.com/owner/repo/tree/broken", "", "unexpected commit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = nil
+			got, err := ResolveGitHubCommit(tt.url)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveGitHubCommit(%q) error = %v, want %q", tt.url, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveGitHubCommit(%q) failed: %v", tt.url, err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveGitHubCommit(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+			if tt.want == strings.Repeat("a", 40) && len(requests) != 0 {
+				t.Errorf("a full SHA made API requests: %v", requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package loader
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IconName returns the file name PA uses for a unit's build bar icon
+func IconName(unitID string) string {
+	return unitID + "_icon_buildbar.png"
+}
+
+// iconCandidates lists where a directory source may keep a unit's icon, relative to the
+// source root: beside the unit, in a sibling icon_atlas folder, or under ui/mods
+func iconCandidates(trimmedUnitDir, iconName string) []string {
+	return []string{
+		filepath.Join(trimmedUnitDir, iconName),                              // Same directory as unit
+		filepath.Join(filepath.Dir(trimmedUnitDir), "icon_atlas", iconName),  // icon_atlas subdirectory
+		filepath.Join("ui", "mods", filepath.Base(trimmedUnitDir), iconName), // UI mods directory
+	}
+}
+
+// SetIconOverrides maps unit IDs to icon resource paths (e.g. "/ui/mods/my_mod/img/tank.png")
+// that take priority over discovered build bar icons. Paths resolve first-wins across
+// the sources like any other resource; a path no source has is ignored (SearchIcon
+// reports it).
+func (l *Loader) SetIconOverrides(overrides map[string]string) {
+	l.iconOverrides = overrides
+}
+
+// IconOverrideSource is the source recorded for icons from the override folder
+const IconOverrideSource = "icon-overrides"
+
+// SetIconOverrideDir sets a local folder of replacement icons named <unit id>.png or
+// <unit id>_icon_buildbar.png. They take priority over SetIconOverrides and discovered
+// icons.
+func (l *Loader) SetIconOverrideDir(dir string) {
+	l.iconDir = dir
+}
+
+// iconOverrideFiles are the file names checked in the override folder, in order
+func iconOverrideFiles(unitID string) []string {
+	return []string{IconName(unitID), unitID + ".png"}
+}
+
+// overrideIcon resolves a unit's icon from the override folder or mapping, or returns nil
+func (l *Loader) overrideIcon(unitID string) *UnitFileInfo {
+	if l.iconDir != "" {
+		for _, name := range iconOverrideFiles(unitID) {
+			p := filepath.Join(l.iconDir, name)
+			if info, err := os.Stat(p); err == nil && info.Mode().IsRegular() {
+				return &UnitFileInfo{
+					RelativePath: IconName(unitID),
+					FullPath:     p,
+					Source:       IconOverrideSource,
+				}
+			}
+		}
+	}
+
+	resourcePath, ok := l.iconOverrides[unitID]
+	if !ok {
+		return nil
+	}
+	info := l.findSpecSource(resourcePath)
+	if info == nil {
+		return nil
+	}
+	return &UnitFileInfo{
+		RelativePath: IconName(unitID),
+		FullPath:     info.FullPath,
+		Source:       info.Source,
+		IsFromZip:    info.IsFromZip,
+	}
+}
+
+// IconLocation is a place an icon was looked for or found: a source and a PA resource path
+type IconLocation struct {
+	Source       string
+	ResourcePath string
+}
+
+// IconSearch explains how a unit's build bar icon was resolved
+type IconSearch struct {
+	UnitID string
+	// Found is the icon the export uses, nil if there is none
+	Found *UnitFileInfo
+	// Override is the profile's mapped resource path, if any; OverrideMissing is set
+	// when no source has it
+	Override        string
+	OverrideMissing bool
+	// Searched lists the locations checked, highest priority first: files in the override
+	// folder (as file system paths), then resource paths in each source. Zip sources
+	// match the icon name anywhere in the archive, shown as /**/<icon>.
+	Searched []IconLocation
+	// NearMisses are PNG files with a similar name that the search didn't accept, such
+	// as a differently named icon under ui/mods, closest first
+	NearMisses []IconLocation
+}
+
+// maxNearMisses caps the near misses reported per unit
+const maxNearMisses = 5
+
+// SearchIcon repeats the icon discovery of GetAllFilesForUnit for one unit, recording
+// every location checked and similarly named PNGs, so mod authors can see why an icon
+// wasn't found. The first call indexes every PNG in every source.
+func (l *Loader) SearchIcon(unitPath string) (*IconSearch, error) {
+	files, err := l.GetAllFilesForUnit(unitPath)
+	if err != nil {
+		return nil, err
+	}
+	unitID := strings.TrimSuffix(path.Base(unitPath), ".json")
+	iconName := IconName(unitID)
+	unitDir := strings.TrimPrefix(path.Dir(unitPath), "/")
+
+	search := &IconSearch{UnitID: unitID, Found: files[iconName]}
+	if override, ok := l.iconOverrides[unitID]; ok {
+		search.Override = override
+		search.OverrideMissing = l.findSpecSource(override) == nil
+	}
+
+	if l.iconDir != "" {
+		for _, name := range iconOverrideFiles(unitID) {
+			search.Searched = append(search.Searched, IconLocation{IconOverrideSource, filepath.Join(l.iconDir, name)})
+		}
+	}
+	for _, src := range l.sources {
+		if src.IsZip {
+			search.Searched = append(search.Searched, IconLocation{src.Identifier, "/**/" + iconName})
+			continue
+		}
+		trimmed := trimSourceDir(src, unitDir)
+		for _, candidate := range iconCandidates(trimmed, iconName) {
+			search.Searched = append(search.Searched, IconLocation{src.Identifier, sourceResourcePath(src, filepath.ToSlash(candidate))})
+		}
+	}
+
+	if search.Found == nil {
+		search.NearMisses = l.nearMissIcons(unitID)
+	}
+	return search, nil
+}
+
+// nearMissIcons finds PNGs whose name, less icon suffixes, is within a couple of edits
+// of the unit ID
+func (l *Loader) nearMissIcons(unitID string) []IconLocation {
+	type candidate struct {
+		loc      IconLocation
+		distance int
+	}
+	var found []candidate
+	maxDistance := 2
+	if len(unitID) < 6 {
+		maxDistance = 1
+	}
+	for _, src := range l.sources {
+		for _, p := range l.sourcePNGs(src) {
+			stem := iconStem(path.Base(p))
+			if d := editDistance(stem, strings.ToLower(unitID)); d <= maxDistance {
+				found = append(found, candidate{IconLocation{src.Identifier, sourceResourcePath(src, p)}, d})
+			}
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].distance != found[j].distance {
+			return found[i].distance < found[j].distance
+		}
+		return found[i].loc.ResourcePath < found[j].loc.ResourcePath
+	})
+	var misses []IconLocation
+	for _, c := range found {
+		if len(misses) == maxNearMisses {
+			break
+		}
+		misses = append(misses, c.loc)
+	}
+	return misses
+}
+
+// iconStem lowercases a PNG file name and strips the extension and common icon affixes
+// (tank_icon_buildbar.png, tank_icon.png, icon_tank.png and tank.png all give "tank")
+func iconStem(name string) string {
+	stem := strings.ToLower(strings.TrimSuffix(name, path.Ext(name)))
+	for _, suffix := range []string{"_icon_buildbar", "_buildbar", "_icon"} {
+		if trimmed := strings.TrimSuffix(stem, suffix); trimmed != stem {
+			stem = trimmed
+			break
+		}
+	}
+	return strings.TrimPrefix(stem, "icon_")
+}
+
+// sourcePNGs lists the PNG files of a source as slash paths relative to its root,
+// indexed on first use
+func (l *Loader) sourcePNGs(src Source) []string {
+	if pngs, ok := l.pngIndex[src.Identifier]; ok {
+		return pngs
+	}
+	var pngs []string
+	if src.IsZip {
+		for p := range src.zipIndex {
+			if strings.EqualFold(path.Ext(p), ".png") {
+				pngs = append(pngs, p)
+			}
+		}
+	} else {
+		// Unreadable folders are skipped: this is a best-effort hint
+		filepath.WalkDir(src.Path, func(p string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if !entry.IsDir() && strings.EqualFold(filepath.Ext(p), ".png") {
+				if rel, err := filepath.Rel(src.Path, p); err == nil {
+					pngs = append(pngs, filepath.ToSlash(rel))
+				}
+			}
+			return nil
+		})
+	}
+	sort.Strings(pngs)
+	if l.pngIndex == nil {
+		l.pngIndex = make(map[string][]string)
+	}
+	l.pngIndex[src.Identifier] = pngs
+	return pngs
+}
+
+// trimSourceDir maps a unit directory (pa/units/land/tank) to a directory source's
+// layout, as findFilesInDir does
+func trimSourceDir(src Source, unitDir string) string {
+	if strings.HasPrefix(unitDir, src.Identifier+"/") {
+		return strings.TrimPrefix(unitDir, src.Identifier+"/")
+	}
+	if strings.HasPrefix(unitDir, "pa/") && src.Identifier == "pa_ex1" {
+		return strings.TrimPrefix(unitDir, "pa/")
+	}
+	return unitDir
+}
+
+// sourceResourcePath turns a path relative to a source root into a PA resource path.
+// The base game and expansion folders are the /pa/ and /pa_ex1/ roots themselves.
+func sourceResourcePath(src Source, rel string) string {
+	if !src.IsZip && (src.Type == ModSourceBaseGame || src.Type == ModSourceExpansion) {
+		return "/" + src.Identifier + "/" + rel
+	}
+	return "/" + rel
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,169 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIconStem(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"tank_icon_buildbar.png", "tank"},
+		{"Tank_Icon.PNG", "tank"},
+		{"icon_tank.png", "tank"},
+		{"tank_buildbar.png", "tank"},
+		{"tank.png", "tank"},
+		{"tank_diffuse.png", "tank_diffuse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := iconStem(tt.name); got != tt.want {
+				t.Errorf("iconStem(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"tank", "tank", 0},
+		{"tank", "tnak", 2},
+		{"l_tank", "tank", 2},
+		{"", "tank", 4},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSearchIcon(t *testing.T) {
+	paRoot := t.TempDir()
+	write := func(rel string) {
+		t.Helper()
+		p := filepath.Join(paRoot, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("pa/units/land/heavy_tank/heavy_tank.json")
+	write("pa/ui/img/heavy_tank_icon.png")
+	write("pa/ui/img/heavy_tnk.png")
+	write("pa/ui/img/unrelated.png")
+
+	l, err := NewMultiSourceLoader(paRoot, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	const unitPath = "/pa/units/land/heavy_tank/heavy_tank.json"
+	search, err := l.SearchIcon(unitPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if search.Found != nil {
+		t.Fatalf("Found = %+v, want nil", search.Found)
+	}
+	wantSearched := []IconLocation{
+		{"pa", "/pa/units/land/heavy_tank/heavy_tank_icon_buildbar.png"},
+		{"pa", "/pa/units/land/icon_atlas/heavy_tank_icon_buildbar.png"},
+		{"pa", "/pa/ui/mods/heavy_tank/heavy_tank_icon_buildbar.png"},
+	}
+	if !reflect.DeepEqual(search.Searched, wantSearched) {
+		t.Errorf("Searched = %v, want %v", search.Searched, wantSearched)
+	}
+	wantMisses := []IconLocation{
+		{"pa", "/pa/ui/img/heavy_tank_icon.png"},
+		{"pa", "/pa/ui/img/heavy_tnk.png"},
+	}
+	if !reflect.DeepEqual(search.NearMisses, wantMisses) {
+		t.Errorf("NearMisses = %v, want %v", search.NearMisses, wantMisses)
+	}
+
+	// A mapped icon is used for the unit under its standard name
+	l.SetIconOverrides(map[string]string{"heavy_tank": "/pa/ui/img/heavy_tank_icon.png"})
+	files, err := l.GetAllFilesForUnit(unitPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	icon := files["heavy_tank_icon_buildbar.png"]
+	if icon == nil || icon.FullPath != filepath.Join(paRoot, "pa", "ui", "img", "heavy_tank_icon.png") || icon.Source != "pa" {
+		t.Errorf("mapped icon = %+v", icon)
+	}
+
+	l.SetIconOverrides(map[string]string{"heavy_tank": "/pa/ui/img/missing.png"})
+	search, err = l.SearchIcon(unitPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !search.OverrideMissing || search.Override != "/pa/ui/img/missing.png" || search.Found != nil {
+		t.Errorf("missing mapping = %+v", search)
+	}
+}
+
+func TestIconOverrideDir(t *testing.T) {
+	paRoot := t.TempDir()
+	iconDir := t.TempDir()
+	for _, rel := range []string{
+		"pa/units/land/tank/tank.json",
+		"pa/units/land/tank/tank_icon_buildbar.png",
+		"pa/units/land/bot/bot.json",
+		"pa/units/land/bot/bot_icon_buildbar.png",
+		"pa/ui/img/bot.png",
+	} {
+		p := filepath.Join(paRoot, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(iconDir, "tank.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := NewMultiSourceLoader(paRoot, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	l.SetIconOverrides(map[string]string{"tank": "/pa/ui/img/bot.png", "bot": "/pa/ui/img/bot.png"})
+	l.SetIconOverrideDir(iconDir)
+
+	tests := []struct {
+		unitPath string
+		wantPath string
+		source   string
+	}{
+		// The folder beats both the mapping and the discovered icon
+		{"/pa/units/land/tank/tank.json", filepath.Join(iconDir, "tank.png"), IconOverrideSource},
+		// Without a file in the folder the mapping applies
+		{"/pa/units/land/bot/bot.json", filepath.Join(paRoot, "pa", "ui", "img", "bot.png"), "pa"},
+	}
+	for _, tt := range tests {
+		files, err := l.GetAllFilesForUnit(tt.unitPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		icon := files[IconName(filepath.Base(filepath.Dir(tt.unitPath)))]
+		if icon == nil || icon.FullPath != tt.wantPath || icon.Source != tt.source || icon.IsFromZip {
+			t.Errorf("%s icon = %+v, want %s from %s", tt.unitPath, icon, tt.wantPath, tt.source)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package loader
+
+import "github.com/jamiemulcahy/pa-pedia/pkg/parallel"
+
+// SetIOLimit bounds how many files are read from the sources at once (JSON loads, icon and
+// resource reads, and copies made under AcquireIO); n <= 0 removes the bound. Call it before
+// reading starts.
+func (l *Loader) SetIOLimit(n int) {
+	l.io = nil
+	if n > 0 {
+		l.io = make(chan struct{}, n)
+	}
+}
+
+// AcquireIO waits for a slot under the IO limit and returns the function that frees it.
+// Slots aren't reentrant: release before calling back into the loader.
+func (l *Loader) AcquireIO() (release func()) {
+	if l.io == nil {
+		return func() {}
+	}
+	l.io <- struct{}{}
+	return func() { <-l.io }
+}
+
+// Prefetch loads and caches the JSON for the given resources on up to workers goroutines, so
+// the sequential parse that follows finds them in the cache. Safe names aren't assigned here:
+// they depend on parse order. Missing or broken files are left for the parse to report.
+func (l *Loader) Prefetch(resourceNames []string, workers int) {
+	parallel.ForEach(len(resourceNames), workers, func(i int) {
+		l.GetJSON(resourceNames[i])
+	})
+}
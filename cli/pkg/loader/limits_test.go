@@ -0,0 +1,52 @@
+package loader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestPrefetch verifies concurrent prefetching under an IO limit caches every file once
+func TestPrefetch(t *testing.T) {
+	paRoot := t.TempDir()
+	var names []string
+	for i := 0; i < 20; i++ {
+		id := fmt.Sprintf("unit_%d", i)
+		dir := filepath.Join(paRoot, "pa", "units", "land", id)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, id+".json"), []byte(fmt.Sprintf(`{"max_health": %d}`, i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, fmt.Sprintf("/pa/units/land/%s/%s.json", id, id))
+	}
+	names = append(names, "/pa/units/land/missing/missing.json")
+
+	l, err := NewMultiSourceLoader(paRoot, "pa_ex1", nil)
+	if err != nil {
+		t.Fatalf("failed to create loader: %v", err)
+	}
+	defer l.Close()
+	l.SetIOLimit(2)
+
+	l.Prefetch(names, 8)
+
+	stats := l.ReadStats()
+	if len(stats) != 1 || stats[0].Files != 20 {
+		t.Fatalf("expected 20 files read from pa, got %+v", stats)
+	}
+	for i, name := range names[:20] {
+		data, err := l.GetJSON(name)
+		if err != nil {
+			t.Fatalf("GetJSON(%s) failed: %v", name, err)
+		}
+		if got := GetFloat(data, "max_health", -1); got != float64(i) {
+			t.Errorf("%s max_health = %v, want %d", name, got, i)
+		}
+	}
+	if stats := l.ReadStats(); stats[0].Files != 20 {
+		t.Errorf("expected prefetched files to be cached, got %d reads", stats[0].Files)
+	}
+}
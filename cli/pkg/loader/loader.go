@@ -8,7 +8,10 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Source represents a data source (directory or zip file)
@@ -35,12 +38,23 @@ func (s *Source) ZipPathPrefix() string {
 
 // Loader handles loading and caching JSON files from PA installation and mods
 type Loader struct {
-	sources     []Source                        // Priority-ordered sources to search
-	jsonCache   map[string]map[string]interface{} // Cached JSON data
-	sourceCache map[string]*SpecFileInfo        // Cached source info for resources
-	safeNames   map[string]string               // resource path -> safe name
-	fullNames   map[string]string               // safe name -> resource path
-	expansion   string                          // Expansion directory (e.g., "pa_ex1")
+	sources       []Source                          // Priority-ordered sources to search
+	jsonCache     map[string]map[string]interface{} // Cached JSON data
+	sourceCache   map[string]*SpecFileInfo          // Cached source info for resources
+	iconOverrides map[string]string                 // unit ID -> icon resource path (see SetIconOverrides)
+	iconDir       string                            // Folder of replacement icons (see SetIconOverrideDir)
+	pngIndex      map[string][]string               // source identifier -> PNG paths (see SearchIcon)
+	atlases       []*IconAtlas                      // Icon atlases, highest priority first (see IconAtlases)
+	atlasErrors   []error                           // Atlases that couldn't be read
+	atlasesLoaded bool                              // Whether atlases have been discovered
+	safeNames     map[string]string                 // resource path -> safe name
+	fullNames     map[string]string                 // safe name -> resource path
+	expansion     string                            // Expansion directory (e.g., "pa_ex1")
+	stats         readStats                         // Files read per source (see ReadStats)
+	cacheMu       sync.RWMutex                      // Guards jsonCache and sourceCache (see Prefetch)
+	io            chan struct{}                     // Bounds concurrent reads (see SetIOLimit)
+	preferred     map[string]string                 // unit folder -> preferred source (see SetSourcePreferences)
+	base          *BaseCache                        // Shared base game and expansion JSON (see SetBaseCache)
 }
 
 // NewMultiSourceLoader creates a loader from ModInfo array
@@ -163,7 +177,10 @@ func (l *Loader) Sources() []Source {
 // Handles expansion shadowing (pa_ex1 overrides pa files)
 func (l *Loader) GetJSON(resourceName string) (map[string]interface{}, error) {
 	// Check cache first
-	if cached, ok := l.jsonCache[resourceName]; ok {
+	l.cacheMu.RLock()
+	cached, ok := l.jsonCache[resourceName]
+	l.cacheMu.RUnlock()
+	if ok {
 		return cached, nil
 	}
 
@@ -178,7 +195,7 @@ func (l *Loader) GetJSON(resourceName string) (map[string]interface{}, error) {
 	paths = append(paths, resourceName)
 
 	// Try each source in priority order
-	for _, src := range l.sources {
+	for _, src := range l.sourcesFor(resourceName) {
 		for _, resPath := range paths {
 			var data map[string]interface{}
 			var err error
@@ -205,6 +222,7 @@ func (l *Loader) GetJSON(resourceName string) (map[string]interface{}, error) {
 			}
 
 			if err == nil {
+				l.cacheMu.Lock()
 				// Cache under all possible names
 				for _, p := range paths {
 					l.jsonCache[p] = data
@@ -216,6 +234,7 @@ func (l *Loader) GetJSON(resourceName string) (map[string]interface{}, error) {
 					IsFromZip:    src.IsZip,
 					FullPath:     fullPath,
 				}
+				l.cacheMu.Unlock()
 				return data, nil
 			}
 		}
@@ -224,6 +243,32 @@ func (l *Loader) GetJSON(resourceName string) (map[string]interface{}, error) {
 	return nil, fmt.Errorf("resource not found: %s", resourceName)
 }
 
+// PinSafeNames assigns safe names ahead of GetSafeName, so resources keep the IDs an
+// earlier export gave them even when a new resource would now claim the name first. Call
+// it before anything is parsed. Pins are applied in resource path order; a pin whose name
+// another pin already took is skipped, and the skipped resource paths are returned.
+func (l *Loader) PinSafeNames(pins map[string]string) []string {
+	resources := make([]string, 0, len(pins))
+	for resourceName := range pins {
+		resources = append(resources, resourceName)
+	}
+	sort.Strings(resources)
+
+	var skipped []string
+	for _, resourceName := range resources {
+		safeName := pins[resourceName]
+		_, named := l.safeNames[resourceName]
+		_, taken := l.fullNames[safeName]
+		if named || taken || safeName == "" {
+			skipped = append(skipped, resourceName)
+			continue
+		}
+		l.safeNames[resourceName] = safeName
+		l.fullNames[safeName] = resourceName
+	}
+	return skipped
+}
+
 // GetSafeName returns a unique short identifier for a resource path
 // Priority: filename > dirname > dirname_N
 func (l *Loader) GetSafeName(resourceName string) string {
@@ -354,6 +399,21 @@ func Delocalize(text string) string {
 	return text
 }
 
+// LocKey returns the localization key of a string marked for translation: the key of the
+// old-style !LOC(key):text format, or the text itself for !LOC:text, since PA's translation
+// files are keyed by the English string. Unmarked text isn't localized and gives "".
+func LocKey(text string) string {
+	if strings.HasPrefix(text, "!LOC(") {
+		if idx := strings.Index(text, "):"); idx != -1 {
+			return text[5:idx]
+		}
+	}
+	if strings.HasPrefix(text, "!LOC:") {
+		return text[5:]
+	}
+	return ""
+}
+
 // LoadMergedUnitList loads and merges unit_list.json from all sources (Phase 1.5+)
 // Returns deduplicated list of unit paths with provenance tracking
 //
@@ -366,12 +426,41 @@ func (l *Loader) LoadMergedUnitList() ([]string, map[string]string, error) {
 		return nil, nil, fmt.Errorf("no sources configured in loader")
 	}
 
+	unitPaths, provenance := l.mergeUnitLists(l.sources)
+	if len(unitPaths) == 0 {
+		return nil, nil, fmt.Errorf("no unit list found in any source (tried unit_list.json and unit_list_legion.json)")
+	}
+
+	return unitPaths, provenance, nil
+}
+
+// LoadBaseUnitList merges unit_list.json from the base game and expansion sources only,
+// ignoring mods. Used to tell which parsed units belong to the base game without
+// parsing it again through a second loader.
+func (l *Loader) LoadBaseUnitList() ([]string, error) {
+	baseSources := make([]Source, 0, 2)
+	for _, src := range l.sources {
+		if src.Type == ModSourceBaseGame || src.Type == ModSourceExpansion {
+			baseSources = append(baseSources, src)
+		}
+	}
+
+	unitPaths, _ := l.mergeUnitLists(baseSources)
+	if len(unitPaths) == 0 {
+		return nil, fmt.Errorf("no base game unit list found (is --pa-root correct?)")
+	}
+	return unitPaths, nil
+}
+
+// mergeUnitLists merges the unit lists of sources in priority order, skipping duplicates
+// and recording which source first listed each unit
+func (l *Loader) mergeUnitLists(sources []Source) ([]string, map[string]string) {
 	unitPaths := make([]string, 0)
 	seenUnits := make(map[string]bool)
 	provenance := make(map[string]string) // unit path -> source identifier
 
 	// Process sources in priority order
-	for _, src := range l.sources {
+	for _, src := range sources {
 		// Try standard unit_list.json first, then faction-specific alternatives
 		unitListPaths := []string{
 			"/pa/units/unit_list.json",
@@ -420,11 +509,7 @@ func (l *Loader) LoadMergedUnitList() ([]string, map[string]string, error) {
 		}
 	}
 
-	if len(unitPaths) == 0 {
-		return nil, nil, fmt.Errorf("no unit list found in any source (tried unit_list.json and unit_list_legion.json)")
-	}
-
-	return unitPaths, provenance, nil
+	return unitPaths, provenance
 }
 
 // loadJSONFromZip loads a JSON file from a zip archive
@@ -442,6 +527,8 @@ func (l *Loader) loadJSONFromZip(src Source, resourcePath string) (map[string]in
 		return nil, fmt.Errorf("file not found in zip: %s", resourcePath)
 	}
 
+	defer l.AcquireIO()()
+	start := time.Now()
 	rc, err := file.Open()
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file in zip: %w", err)
@@ -452,6 +539,7 @@ func (l *Loader) loadJSONFromZip(src Source, resourcePath string) (map[string]in
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file from zip: %w", err)
 	}
+	l.RecordRead(src.Identifier, int64(len(data)), time.Since(start))
 
 	var result map[string]interface{}
 	if err := json.Unmarshal(data, &result); err != nil {
@@ -474,7 +562,22 @@ func (l *Loader) loadJSONFromDir(src Source, resourcePath string) (map[string]in
 
 	fullPath := filepath.Join(src.Path, filepath.FromSlash(trimmedPath))
 
+	shared := l.base != nil && (src.Type == ModSourceBaseGame || src.Type == ModSourceExpansion)
+	if shared {
+		if data, ok := l.base.lookup(fullPath); ok {
+			if data == nil {
+				return nil, fmt.Errorf("%s: %w", fullPath, os.ErrNotExist)
+			}
+			return data, nil
+		}
+	}
+
+	defer l.AcquireIO()()
+	start := time.Now()
 	if _, err := os.Stat(fullPath); err != nil {
+		if shared && os.IsNotExist(err) {
+			l.base.store(fullPath, nil)
+		}
 		return nil, err
 	}
 
@@ -482,21 +585,26 @@ func (l *Loader) loadJSONFromDir(src Source, resourcePath string) (map[string]in
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
+	l.RecordRead(src.Identifier, int64(len(data)), time.Since(start))
 
 	var result map[string]interface{}
 	if err := json.Unmarshal(data, &result); err != nil {
 		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
+	if shared && result != nil {
+		l.base.store(fullPath, result)
+	}
 
 	return result, nil
 }
 
 // UnitFileInfo represents a discovered file for a unit with its source
 type UnitFileInfo struct {
-	RelativePath string // Relative path within unit folder (e.g., "tank.json", "tank_icon_buildbar.png")
-	FullPath     string // Full filesystem path or zip entry path
-	Source       string // Source identifier (pa, pa_ex1, mod identifier)
-	IsFromZip    bool   // Whether this file comes from a zip
+	RelativePath string      // Relative path within unit folder (e.g., "tank.json", "tank_icon_buildbar.png")
+	FullPath     string      // Full filesystem path or zip entry path
+	Source       string      // Source identifier (pa, pa_ex1, mod identifier)
+	IsFromZip    bool        // Whether this file comes from a zip
+	Atlas        *AtlasFrame // Frame to crop when the icon comes from an atlas (see ReadUnitFile)
 }
 
 // GetAllFilesForUnit discovers all files related to a unit across all sources
@@ -520,7 +628,7 @@ func (l *Loader) GetAllFilesForUnit(unitPath string) (map[string]*UnitFileInfo,
 	unitID := strings.TrimSuffix(filepath.Base(unitPath), ".json")
 
 	// Search all sources for files in the unit directory
-	for _, src := range l.sources {
+	for _, src := range l.sourcesFor(unitPath) {
 		if src.IsZip {
 			// Search in zip file
 			filesInZip := l.findFilesInZip(src, unitDir, unitID)
@@ -540,6 +648,16 @@ func (l *Loader) GetAllFilesForUnit(unitPath string) (map[string]*UnitFileInfo,
 		}
 	}
 
+	// An atlas frame replaces a smaller loose icon
+	if icon := l.betterIcon(unitID, files[IconName(unitID)]); icon != nil {
+		files[icon.RelativePath] = icon
+	}
+
+	// A profile's icon folder or mapping replaces whatever was discovered
+	if info := l.overrideIcon(unitID); info != nil {
+		files[info.RelativePath] = info
+	}
+
 	return files, nil
 }
 
@@ -599,13 +717,7 @@ func (l *Loader) findFilesInDir(src Source, unitDir string, unitID string) map[s
 	// Break after finding first icon to avoid unnecessary filesystem checks
 	iconName := unitID + "_icon_buildbar.png"
 	if _, exists := files[iconName]; !exists {
-		iconPaths := []string{
-			filepath.Join(trimmedUnitDir, iconName),                                    // Same directory as unit
-			filepath.Join(filepath.Dir(trimmedUnitDir), "icon_atlas", iconName),       // icon_atlas subdirectory
-			filepath.Join("ui", "mods", filepath.Base(trimmedUnitDir), iconName),      // UI mods directory
-		}
-
-		for _, iconPath := range iconPaths {
+		for _, iconPath := range iconCandidates(trimmedUnitDir, iconName) {
 			fullIconPath := filepath.Join(src.Path, filepath.FromSlash(iconPath))
 			if _, err := os.Stat(fullIconPath); err == nil {
 				files[iconName] = &UnitFileInfo{
@@ -734,7 +846,10 @@ func (l *Loader) collectSpecsRecursively(resourcePath string, specs map[string]*
 // Uses cached source information from GetJSON calls for performance
 func (l *Loader) findSpecSource(resourcePath string) *SpecFileInfo {
 	// Check source cache first (populated by GetJSON)
-	if cached, ok := l.sourceCache[resourcePath]; ok {
+	l.cacheMu.RLock()
+	cached, ok := l.sourceCache[resourcePath]
+	l.cacheMu.RUnlock()
+	if ok {
 		return cached
 	}
 
@@ -748,51 +863,19 @@ func (l *Loader) findSpecSource(resourcePath string) *SpecFileInfo {
 	paths = append(paths, resourcePath)
 
 	// Try each source in priority order
-	for _, src := range l.sources {
+	for _, src := range l.sourcesFor(resourcePath) {
 		for _, resPath := range paths {
-			if src.IsZip {
-				// Check in zip
-				normalizedPath := strings.TrimPrefix(filepath.ToSlash(resPath), "/")
-				if _, found := src.zipIndex[normalizedPath]; found {
-					info := &SpecFileInfo{
-						ResourcePath: resourcePath,
-						Source:       src.Identifier,
-						IsFromZip:    true,
-						FullPath:     normalizedPath,
-					}
-					l.sourceCache[resourcePath] = info
-					return info
-				}
-			} else {
-				// Check in directory
-				// Different sources have different directory structures:
-				// - Mods: mod_root/pa/units/... (keep /pa/ prefix, just strip leading /)
-				// - Expansion (pa_ex1): paRoot/pa_ex1/units/... (strip /pa/ or /pa_ex1/)
-				// - Base game (pa): paRoot/pa/units/... (strip /pa/)
-				trimmedPath := resPath
-				if src.Identifier == "pa" || src.Identifier == "pa_ex1" {
-					// Base game and expansion have src.Path already including pa/ or pa_ex1/
-					if strings.HasPrefix(resPath, "/pa/") {
-						trimmedPath = strings.TrimPrefix(resPath, "/pa/")
-					} else if strings.HasPrefix(resPath, "/pa_ex1/") {
-						trimmedPath = strings.TrimPrefix(resPath, "/pa_ex1/")
-					}
-				} else {
-					// Mods: just strip leading slash, keep pa/ prefix
-					trimmedPath = strings.TrimPrefix(resPath, "/")
-				}
-
-				fullPath := filepath.Join(src.Path, filepath.FromSlash(trimmedPath))
-				if _, err := os.Stat(fullPath); err == nil {
-					info := &SpecFileInfo{
-						ResourcePath: resourcePath,
-						Source:       src.Identifier,
-						IsFromZip:    false,
-						FullPath:     fullPath,
-					}
-					l.sourceCache[resourcePath] = info
-					return info
+			if fullPath, ok := resolveInSource(src, resPath); ok {
+				info := &SpecFileInfo{
+					ResourcePath: resourcePath,
+					Source:       src.Identifier,
+					IsFromZip:    src.IsZip,
+					FullPath:     fullPath,
 				}
+				l.cacheMu.Lock()
+				l.sourceCache[resourcePath] = info
+				l.cacheMu.Unlock()
+				return info
 			}
 		}
 	}
@@ -800,6 +883,79 @@ func (l *Loader) findSpecSource(resourcePath string) *SpecFileInfo {
 	return nil
 }
 
+// resolveInSource reports whether a single source contains resPath, returning
+// the zip entry path or filesystem path it lives at.
+func resolveInSource(src Source, resPath string) (string, bool) {
+	if src.IsZip {
+		// Check in zip
+		normalizedPath := strings.TrimPrefix(filepath.ToSlash(resPath), "/")
+		if _, found := src.zipIndex[normalizedPath]; found {
+			return normalizedPath, true
+		}
+		return "", false
+	}
+
+	// Check in directory
+	// Different sources have different directory structures:
+	// - Mods: mod_root/pa/units/... (keep /pa/ prefix, just strip leading /)
+	// - Expansion (pa_ex1): paRoot/pa_ex1/units/... (strip /pa/ or /pa_ex1/)
+	// - Base game (pa): paRoot/pa/units/... (strip /pa/)
+	trimmedPath := resPath
+	if src.Identifier == "pa" || src.Identifier == "pa_ex1" {
+		// Base game and expansion have src.Path already including pa/ or pa_ex1/
+		if strings.HasPrefix(resPath, "/pa/") {
+			trimmedPath = strings.TrimPrefix(resPath, "/pa/")
+		} else if strings.HasPrefix(resPath, "/pa_ex1/") {
+			trimmedPath = strings.TrimPrefix(resPath, "/pa_ex1/")
+		}
+	} else {
+		// Mods: just strip leading slash, keep pa/ prefix
+		trimmedPath = strings.TrimPrefix(resPath, "/")
+	}
+
+	fullPath := filepath.Join(src.Path, filepath.FromSlash(trimmedPath))
+	if _, err := os.Stat(fullPath); err == nil {
+		return fullPath, true
+	}
+	return "", false
+}
+
+// UnitConflict describes a unit spec provided by more than one mod source.
+// The first-wins overlay (or a source preference) means only Winner's copy is used;
+// Overridden lists the other mods whose copy of the same file is shadowed.
+type UnitConflict struct {
+	ResourcePath string   // PA resource path of the unit JSON
+	Winner       string   // Source identifier whose file is used
+	Overridden   []string // Lower-priority mod sources that also define the file
+}
+
+// FindUnitConflicts reports unit specs that are defined by two or more mod
+// sources. Base game and expansion sources are ignored: mods shadowing base
+// units is the normal overlay, not a conflict. Results follow unitPaths order,
+// and a unit's Winner honours SetSourcePreferences.
+func (l *Loader) FindUnitConflicts(unitPaths []string) []UnitConflict {
+	var conflicts []UnitConflict
+	for _, unitPath := range unitPaths {
+		var providers []string
+		for _, src := range l.sourcesFor(unitPath) {
+			if src.Type == ModSourceBaseGame || src.Type == ModSourceExpansion {
+				continue
+			}
+			if _, ok := resolveInSource(src, unitPath); ok {
+				providers = append(providers, src.Identifier)
+			}
+		}
+		if len(providers) > 1 {
+			conflicts = append(conflicts, UnitConflict{
+				ResourcePath: unitPath,
+				Winner:       providers[0],
+				Overridden:   providers[1:],
+			})
+		}
+	}
+	return conflicts
+}
+
 // ResolveResource returns provenance info for an arbitrary resource path
 // (JSON or binary such as a .papa model/texture) using the same first-wins
 // priority and expansion shadowing as the rest of the loader. Returns nil if
@@ -825,6 +981,8 @@ func (l *Loader) CopyResourceFile(resourcePath, destPath string) error {
 		return fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
+	defer l.AcquireIO()()
+	start := time.Now()
 	if info.IsFromZip {
 		for _, src := range l.sources {
 			if !src.IsZip || src.Identifier != info.Source {
@@ -846,9 +1004,11 @@ func (l *Loader) CopyResourceFile(resourcePath, destPath string) error {
 			}
 			defer destFile.Close()
 
-			if _, err := io.Copy(destFile, rc); err != nil {
+			n, err := io.Copy(destFile, rc)
+			if err != nil {
 				return fmt.Errorf("failed to copy file data: %w", err)
 			}
+			l.RecordRead(info.Source, n, time.Since(start))
 			return nil
 		}
 		return fmt.Errorf("zip source not found for %s", info.Source)
@@ -867,12 +1027,24 @@ func (l *Loader) CopyResourceFile(resourcePath, destPath string) error {
 	}
 	defer destFile.Close()
 
-	if _, err := io.Copy(destFile, srcFile); err != nil {
+	n, err := io.Copy(destFile, srcFile)
+	if err != nil {
 		return fmt.Errorf("failed to copy file: %w", err)
 	}
+	l.RecordRead(info.Source, n, time.Since(start))
 	return nil
 }
 
+// ReadResource resolves a resource path across all sources (first-wins) and returns its
+// bytes, like CopyResourceFile without the copy
+func (l *Loader) ReadResource(resourcePath string) ([]byte, error) {
+	info := l.findSpecSource(resourcePath)
+	if info == nil {
+		return nil, fmt.Errorf("resource not found in any source: %s", resourcePath)
+	}
+	return l.ReadUnitFile(&UnitFileInfo{FullPath: info.FullPath, Source: info.Source, IsFromZip: info.IsFromZip})
+}
+
 // findFilesInZip finds all files in a unit directory from a zip source
 func (l *Loader) findFilesInZip(src Source, unitDir string, unitID string) map[string]*UnitFileInfo {
 	files := make(map[string]*UnitFileInfo)
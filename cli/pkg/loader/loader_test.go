@@ -1,6 +1,8 @@
 package loader
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -87,6 +89,36 @@ func TestGetSafeName(t *testing.T) {
 	}
 }
 
+// TestPinSafeNames verifies pinned IDs win over names generated first-come, and that a
+// pin whose name is taken is skipped
+func TestPinSafeNames(t *testing.T) {
+	l := &Loader{
+		safeNames: make(map[string]string),
+		fullNames: make(map[string]string),
+	}
+
+	skipped := l.PinSafeNames(map[string]string{
+		"/pa/units/land/tank/tank.json":       "tank",
+		"/pa/units/land/tank_heavy/tank.json": "tank", // Same name as the first pin in path order
+		"/pa/units/air/fighter/fighter.json":  "fighter",
+		"/pa/units/land/bot_old/bot_old.json": "bot",
+	})
+	if len(skipped) != 1 || skipped[0] != "/pa/units/land/tank_heavy/tank.json" {
+		t.Errorf("skipped = %v, want only the second tank pin", skipped)
+	}
+
+	// A new file named bot.json would normally get "bot"; the pin keeps it
+	if got := l.GetSafeName("/pa/units/land/bot/bot.json"); got == "bot" {
+		t.Errorf("new resource took pinned name %q", got)
+	}
+	if got := l.GetSafeName("/pa/units/land/bot_old/bot_old.json"); got != "bot" {
+		t.Errorf("pinned resource got %q, want bot", got)
+	}
+	if got := l.GetSafeName("/pa/units/land/tank_heavy/tank.json"); got != "tank_heavy" {
+		t.Errorf("skipped resource got %q, want the generated tank_heavy", got)
+	}
+}
+
 // TestDelocalize tests localization string stripping
 func TestDelocalize(t *testing.T) {
 	tests := []struct {
@@ -141,6 +173,26 @@ func TestDelocalize(t *testing.T) {
 	}
 }
 
+// TestLocKey tests localization key extraction
+func TestLocKey(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"!LOC:Flame Tank", "Flame Tank"},
+		{"!LOC(units.land.tank.name):Ant", "units.land.tank.name"},
+		{"!LOC(unterminated:Ant", ""},
+		{"Regular Name", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if result := LocKey(tt.input); result != tt.expected {
+			t.Errorf("LocKey(%q) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
 // TestGetHelpers tests the Get* helper functions
 func TestGetString(t *testing.T) {
 	data := map[string]interface{}{
@@ -470,3 +522,86 @@ func TestLoadMergedUnitListNoUnitListFound(t *testing.T) {
 		t.Errorf("Error message should mention unit_list_legion.json, got: %q", errMsg)
 	}
 }
+
+// TestFindUnitConflicts tests that units defined by several mod sources are reported
+func TestFindUnitConflicts(t *testing.T) {
+	writeUnit := func(root, rel string) {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(`{}`), 0644); err != nil {
+			t.Fatalf("failed to write unit: %v", err)
+		}
+	}
+
+	modA := t.TempDir()
+	modB := t.TempDir()
+	paDir := t.TempDir()
+	writeUnit(modA, "pa/units/land/tank/tank.json")
+	writeUnit(modB, "pa/units/land/tank/tank.json")
+	writeUnit(modB, "pa/units/land/bot/bot.json")
+	writeUnit(paDir, "units/land/bot/bot.json")
+
+	l := &Loader{
+		sources: []Source{
+			{Type: ModSourceServerMods, Identifier: "com.a", Path: modA},
+			{Type: ModSourceServerMods, Identifier: "com.b", Path: modB},
+			{Type: ModSourceBaseGame, Identifier: "pa", Path: paDir},
+		},
+	}
+
+	conflicts := l.FindUnitConflicts([]string{
+		"/pa/units/land/tank/tank.json",
+		"/pa/units/land/bot/bot.json",
+	})
+
+	if len(conflicts) != 1 {
+		t.Fatalf("expected 1 conflict (bot only shadows base game), got %d: %+v", len(conflicts), conflicts)
+	}
+	c := conflicts[0]
+	if c.ResourcePath != "/pa/units/land/tank/tank.json" {
+		t.Errorf("ResourcePath = %q, want tank", c.ResourcePath)
+	}
+	if c.Winner != "com.a" {
+		t.Errorf("Winner = %q, want com.a", c.Winner)
+	}
+	if len(c.Overridden) != 1 || c.Overridden[0] != "com.b" {
+		t.Errorf("Overridden = %v, want [com.b]", c.Overridden)
+	}
+}
+
+// TestLoadBaseUnitList tests that mod unit lists are ignored when listing base game units
+func TestLoadBaseUnitList(t *testing.T) {
+	addon := &ModInfo{
+		Identifier: "com.test.addon",
+		Directory:  "../../testdata/data_root/server_mods/com.test.addon",
+		SourceType: ModSourceServerMods,
+	}
+	l, err := NewMultiSourceLoader("../../testdata/pa_root", "pa_ex1", []*ModInfo{addon})
+	if err != nil {
+		t.Fatalf("NewMultiSourceLoader() error = %v", err)
+	}
+	defer l.Close()
+
+	merged, _, err := l.LoadMergedUnitList()
+	if err != nil {
+		t.Fatalf("LoadMergedUnitList() error = %v", err)
+	}
+	base, err := l.LoadBaseUnitList()
+	if err != nil {
+		t.Fatalf("LoadBaseUnitList() error = %v", err)
+	}
+
+	if len(merged) != 7 {
+		t.Errorf("merged list has %d units, want 7", len(merged))
+	}
+	if len(base) != 5 {
+		t.Errorf("base list has %d units, want 5: %v", len(base), base)
+	}
+	for _, p := range base {
+		if strings.Contains(p, "addon_") {
+			t.Errorf("base list includes addon unit %s", p)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Load orders for the selected mods (describe-faction --load-order)
+const (
+	LoadOrderManual = "manual" // As listed: the first mod wins
+	LoadOrderGame   = "game"   // As PA mounts them: by priority, after their dependencies
+)
+
+// DefaultModPriority is the priority PA's mod manager gives mods whose modinfo.json sets none
+const DefaultModPriority = 100
+
+// ModPriority is a modinfo.json priority. Some mods write it as a string, and a value that
+// isn't a number reads as unset rather than failing the whole modinfo.json.
+type ModPriority float64
+
+// UnmarshalJSON accepts a number or a numeric string
+func (p *ModPriority) UnmarshalJSON(data []byte) error {
+	var n float64
+	if err := json.Unmarshal(data, &n); err == nil {
+		*p = ModPriority(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if n, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+			*p = ModPriority(n)
+			return nil
+		}
+	}
+	*p = DefaultModPriority
+	return nil
+}
+
+// priority returns the mod's modinfo.json priority, or DefaultModPriority
+func (m *ModInfo) priority() float64 {
+	if m.Priority == nil {
+		return DefaultModPriority
+	}
+	return float64(*m.Priority)
+}
+
+// GameLoadOrder reorders mods (highest priority first, as the loader takes them) the way
+// PA resolves them: mods are mounted in ascending priority, a mod's dependencies are mounted
+// before it, and a later mount overrides an earlier one. Mods of equal priority keep their
+// listed order. Dependencies that aren't among mods are returned as warnings, since PA would
+// load without them only if they're installed another way; a dependency cycle is an error.
+func GameLoadOrder(mods []*ModInfo) ([]*ModInfo, []string, error) {
+	index := make(map[string]int, len(mods))
+	for i, mod := range mods {
+		index[mod.Identifier] = i
+	}
+
+	// Edges run from a dependency to the mods mounted after it
+	var warnings []string
+	waiting := make([]int, len(mods))
+	dependents := make([][]int, len(mods))
+	for i, mod := range mods {
+		for _, dep := range mod.Dependencies {
+			j, ok := index[dep]
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("%s depends on %s, which isn't selected", mod.Identifier, dep))
+				continue
+			}
+			if j == i {
+				continue
+			}
+			waiting[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	// Mount the lowest priority ready mod next; on ties the one listed later, so the one
+	// listed first is mounted last and still wins
+	mountsBefore := func(a, b int) bool {
+		if pa, pb := mods[a].priority(), mods[b].priority(); pa != pb {
+			return pa < pb
+		}
+		return a > b
+	}
+	var ready []int
+	for i := range mods {
+		if waiting[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	mounted := make([]*ModInfo, 0, len(mods))
+	for len(ready) > 0 {
+		sort.Slice(ready, func(x, y int) bool { return mountsBefore(ready[x], ready[y]) })
+		next := ready[0]
+		ready = ready[1:]
+		mounted = append(mounted, mods[next])
+		for _, d := range dependents[next] {
+			if waiting[d]--; waiting[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
+	}
+	if len(mounted) < len(mods) {
+		var cycle []string
+		for i, mod := range mods {
+			if waiting[i] > 0 {
+				cycle = append(cycle, mod.Identifier)
+			}
+		}
+		return nil, warnings, fmt.Errorf("mod dependency cycle between %s\n\nFix the dependencies in their modinfo.json, or use --load-order manual", strings.Join(cycle, ", "))
+	}
+
+	// The loader takes the last mounted mod first
+	ordered := make([]*ModInfo, len(mounted))
+	for i, mod := range mounted {
+		ordered[len(mounted)-1-i] = mod
+	}
+	return ordered, warnings, nil
+}
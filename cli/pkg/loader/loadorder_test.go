@@ -0,0 +1,98 @@
+package loader
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGameLoadOrder(t *testing.T) {
+	mod := func(id string, priority float64, deps ...string) *ModInfo {
+		p := ModPriority(priority)
+		return &ModInfo{Identifier: id, Priority: &p, Dependencies: deps}
+	}
+	unset := func(id string, deps ...string) *ModInfo {
+		return &ModInfo{Identifier: id, Dependencies: deps}
+	}
+
+	tests := []struct {
+		name     string
+		mods     []*ModInfo
+		want     []string // Loader order, first wins
+		warnings int
+		wantErr  string
+	}{
+		{
+			name: "equal priority keeps the listed order",
+			mods: []*ModInfo{unset("a"), unset("b"), mod("c", 100)},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name: "higher priority mounts later and wins",
+			mods: []*ModInfo{mod("balance", 50), mod("units", 200), unset("ui")},
+			want: []string{"units", "ui", "balance"},
+		},
+		{
+			name: "dependency mounts first even with a higher priority",
+			mods: []*ModInfo{mod("addon", 10, "base"), mod("base", 500)},
+			want: []string{"addon", "base"},
+		},
+		{
+			name:     "missing dependency is a warning",
+			mods:     []*ModInfo{unset("addon", "com.absent"), unset("other")},
+			want:     []string{"addon", "other"},
+			warnings: 1,
+		},
+		{
+			name:    "cycle is an error",
+			mods:    []*ModInfo{unset("a", "b"), unset("b", "a"), unset("c")},
+			wantErr: "a, b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warnings, err := GameLoadOrder(tt.mods)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one naming %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GameLoadOrder failed: %v", err)
+			}
+			ids := make([]string, len(got))
+			for i, m := range got {
+				ids[i] = m.Identifier
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("order = %v, want %v", ids, tt.want)
+			}
+			if len(warnings) != tt.warnings {
+				t.Errorf("warnings = %v, want %d", warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestModPriorityUnmarshal(t *testing.T) {
+	tests := []struct {
+		json string
+		want float64
+	}{
+		{`{"priority": 250}`, 250},
+		{`{"priority": "75"}`, 75},
+		{`{"priority": "high"}`, DefaultModPriority},
+		{`{}`, DefaultModPriority},
+	}
+	for _, tt := range tests {
+		var m ModInfo
+		if err := json.Unmarshal([]byte(tt.json), &m); err != nil {
+			t.Fatalf("Unmarshal(%s) failed: %v", tt.json, err)
+		}
+		if got := m.priority(); got != tt.want {
+			t.Errorf("priority of %s = %v, want %v", tt.json, got, tt.want)
+		}
+	}
+}
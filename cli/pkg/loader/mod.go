@@ -32,12 +32,20 @@ type ModInfo struct {
 	Author        string        `json:"author"`
 	Date          string        `json:"date"`
 	Build         string        `json:"build"`
-	Categories    []string      `json:"category"` // Mod categories (e.g., "balance", "addon", "unit")
-	Directory     string        `json:"-"`        // Not in JSON, added by loader (for extracted mods)
-	ZipPath       string        `json:"-"`        // Path to zip file (for zipped mods)
-	ZipPathPrefix string        `json:"-"`        // Prefix to strip from zip paths (for GitHub archives)
-	SourceType    ModSourceType `json:"-"`        // Where this mod was found
-	IsZipped      bool          `json:"-"`        // Whether this mod is in a zip file
+	Categories    []string      `json:"category"`        // Mod categories (e.g., "balance", "addon", "unit")
+	License       string        `json:"license"`         // License the author distributes the mod under, if stated
+	Forum         string        `json:"forum"`           // Forum thread or project page
+	Export        *bool         `json:"pa_pedia_export"` // false: the author opted out of pa-pedia exports (see OptedOut)
+	Priority      *ModPriority  `json:"priority"`        // Mount priority, lower first (see GameLoadOrder)
+	Dependencies  []string      `json:"dependencies"`    // Identifiers of mods mounted before this one
+	Directory     string        `json:"-"`               // Not in JSON, added by loader (for extracted mods)
+	ZipPath       string        `json:"-"`               // Path to zip file (for zipped mods)
+	ZipPathPrefix string        `json:"-"`               // Prefix to strip from zip paths (for GitHub archives)
+	SourceType    ModSourceType `json:"-"`               // Where this mod was found
+	IsZipped      bool          `json:"-"`               // Whether this mod is in a zip file
+	GitHubURL     string        `json:"-"`               // Mod source as given, for GitHub mods
+	GitHubRef     string        `json:"-"`               // Branch, tag or SHA named in GitHubURL
+	GitHubCommit  string        `json:"-"`               // Commit downloaded when pinned (--pin-github-refs), else empty
 }
 
 // GetDefaultPADataRoot returns the platform-specific default PA data directory
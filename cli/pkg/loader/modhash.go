@@ -0,0 +1,116 @@
+package loader
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ContentHash fingerprints a mod's files: a SHA-256 over each file's slash-separated path
+// and the SHA-256 of its content, in path order. Paths are relative to the mod root (the
+// folder holding modinfo.json), so the same files hash alike whether the mod is a folder,
+// a zip or a GitHub archive, on any machine. Dot-directories such as .git/ are skipped.
+func (m *ModInfo) ContentHash() (string, error) {
+	files := make(map[string]string)
+	var err error
+	if m.IsZipped {
+		err = hashZip(m.ZipPath, m.ZipPathPrefix, files)
+	} else {
+		err = hashDir(m.Directory, files)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to hash mod %s: %w", m.Identifier, err)
+	}
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	h := sha256.New()
+	for _, p := range paths {
+		fmt.Fprintf(h, "%s\x00%s\n", p, files[p])
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashZip(zipPath, prefix string, files map[string]string) error {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	for _, file := range reader.File {
+		name := strings.TrimPrefix(filepath.ToSlash(file.Name), "/")
+		if file.FileInfo().IsDir() || !strings.HasPrefix(name, prefix) || inDotDir(strings.TrimPrefix(name, prefix)) {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return err
+		}
+		sum, err := hashReader(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", file.Name, err)
+		}
+		files[strings.TrimPrefix(name, prefix)] = sum
+	}
+	return nil
+}
+
+func hashDir(dir string, files map[string]string) error {
+	return filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && p != dir && strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		sum, err := hashReader(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+		files[filepath.ToSlash(rel)] = sum
+		return nil
+	})
+}
+
+// inDotDir reports whether a slash-separated path lies under a folder whose name starts
+// with a dot (.git/, .github/, ...)
+func inDotDir(name string) bool {
+	dirs := strings.Split(name, "/")
+	for _, d := range dirs[:len(dirs)-1] {
+		if strings.HasPrefix(d, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+func hashReader(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
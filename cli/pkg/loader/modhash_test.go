@@ -0,0 +1,85 @@
+package loader
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestContentHash verifies a mod hashes the same as a folder, a zip and a prefixed GitHub
+// archive, ignoring dot-directories, and differently once a file changes
+func TestContentHash(t *testing.T) {
+	files := map[string]string{
+		"modinfo.json":                 `{"identifier":"com.test.mod"}`,
+		"pa/units/land/tank/tank.json": `{"max_health":100}`,
+	}
+	ignored := map[string]string{
+		".git/HEAD":           "ref: refs/heads/main",
+		"pa/.cache/tank.json": "{}",
+	}
+	root := t.TempDir()
+
+	dir := filepath.Join(root, "mod")
+	for name, content := range ignored {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeZip := func(name, prefix string) string {
+		p := filepath.Join(root, name)
+		f, err := os.Create(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zw := zip.NewWriter(f)
+		for n, content := range files {
+			w, _ := zw.Create(prefix + n)
+			w.Write([]byte(content))
+		}
+		if prefix != "" {
+			w, _ := zw.Create("other-folder/readme.md")
+			w.Write([]byte("not part of the mod"))
+			w, _ = zw.Create(prefix + ".git/HEAD")
+			w.Write([]byte("ref: refs/heads/main"))
+		}
+		zw.Close()
+		f.Close()
+		return p
+	}
+
+	hash := func(m *ModInfo) string {
+		t.Helper()
+		h, err := m.ContentHash()
+		if err != nil {
+			t.Fatalf("ContentHash failed: %v", err)
+		}
+		return h
+	}
+	fromDir := hash(&ModInfo{Identifier: "com.test.mod", Directory: dir})
+	fromZip := hash(&ModInfo{Identifier: "com.test.mod", ZipPath: writeZip("mod.zip", ""), IsZipped: true})
+	fromGitHub := hash(&ModInfo{Identifier: "com.test.mod", ZipPath: writeZip("gh.zip", "repo-main/server/"), ZipPathPrefix: "repo-main/server/", IsZipped: true})
+	if fromDir != fromZip || fromDir != fromGitHub {
+		t.Errorf("same files hashed differently: dir %s, zip %s, github %s", fromDir, fromZip, fromGitHub)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "pa", "units", "land", "tank", "tank.json"), []byte(`{"max_health":120}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if hash(&ModInfo{Identifier: "com.test.mod", Directory: dir}) == fromDir {
+		t.Error("hash unchanged after editing a file")
+	}
+}
@@ -0,0 +1,34 @@
+package loader
+
+// OptOut is a resolved mod whose author asked not to have its content extracted
+type OptOut struct {
+	Mod    *ModInfo
+	Reason string // Where the opt-out came from, for the error message
+}
+
+// OptedOut reports whether the mod's modinfo.json sets "pa_pedia_export": false. A missing
+// field means the mod may be exported.
+func (m *ModInfo) OptedOut() bool {
+	return m.Export != nil && !*m.Export
+}
+
+// OptedOutMods returns the mods that opted out of extraction, either through their own
+// modinfo.json or by identifier in denylist (a profile's optOutMods, for mods whose modinfo.json
+// can't be changed), in mod priority order.
+func OptedOutMods(mods []*ModInfo, denylist []string) []OptOut {
+	denied := make(map[string]bool, len(denylist))
+	for _, id := range denylist {
+		denied[id] = true
+	}
+
+	var out []OptOut
+	for _, mod := range mods {
+		switch {
+		case mod.OptedOut():
+			out = append(out, OptOut{Mod: mod, Reason: `"pa_pedia_export": false in its modinfo.json`})
+		case denied[mod.Identifier]:
+			out = append(out, OptOut{Mod: mod, Reason: "listed in the profile's optOutMods"})
+		}
+	}
+	return out
+}
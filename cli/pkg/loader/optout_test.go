@@ -0,0 +1,65 @@
+package loader
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestModInfoOptedOut(t *testing.T) {
+	tests := []struct {
+		modinfo string
+		want    bool
+	}{
+		{`{"identifier": "a"}`, false},
+		{`{"identifier": "a", "pa_pedia_export": true}`, false},
+		{`{"identifier": "a", "pa_pedia_export": false}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.modinfo, func(t *testing.T) {
+			var mod ModInfo
+			if err := json.Unmarshal([]byte(tt.modinfo), &mod); err != nil {
+				t.Fatalf("failed to parse modinfo: %v", err)
+			}
+			if got := mod.OptedOut(); got != tt.want {
+				t.Errorf("OptedOut() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptedOutMods(t *testing.T) {
+	no := false
+	mods := []*ModInfo{
+		{Identifier: "com.open"},
+		{Identifier: "com.flagged", Export: &no},
+		{Identifier: "com.denied"},
+	}
+
+	tests := []struct {
+		name     string
+		denylist []string
+		want     []string
+	}{
+		{"modinfo flag", nil, []string{"com.flagged"}},
+		{"profile denylist", []string{"com.denied", "com.unused"}, []string{"com.flagged", "com.denied"}},
+		{"flagged and denied", []string{"com.flagged"}, []string{"com.flagged"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OptedOutMods(mods, tt.denylist)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d opted-out mods, want %v", len(got), tt.want)
+			}
+			for i, id := range tt.want {
+				if got[i].Mod.Identifier != id {
+					t.Errorf("opt-out %d = %s, want %s", i, got[i].Mod.Identifier, id)
+				}
+				if got[i].Reason == "" {
+					t.Errorf("opt-out %d has no reason", i)
+				}
+			}
+		})
+	}
+}
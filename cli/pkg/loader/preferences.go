@@ -0,0 +1,56 @@
+package loader
+
+import (
+	"fmt"
+	"path"
+)
+
+// SetSourcePreferences overrides first-wins for conflicting units: prefs maps a unit JSON
+// resource path to the source identifier whose copy to use. The preferred source is searched
+// first for every file in that unit's folder (the unit JSON, its tools, ammo and icon), so a
+// unit isn't assembled from two mods. Sources not in the loader are ignored.
+func (l *Loader) SetSourcePreferences(prefs map[string]string) {
+	l.preferred = make(map[string]string, len(prefs))
+	for unitPath, source := range prefs {
+		l.preferred[path.Dir(unitPath)] = source
+	}
+}
+
+// sourcesFor returns the sources in the order to search for a resource: priority order, with
+// the preferred source (see SetSourcePreferences) of the resource's folder moved first
+func (l *Loader) sourcesFor(resourcePath string) []Source {
+	preferred, ok := l.preferred[path.Dir(resourcePath)]
+	if !ok {
+		return l.sources
+	}
+	for i, src := range l.sources {
+		if src.Identifier == preferred {
+			ordered := make([]Source, 0, len(l.sources))
+			ordered = append(ordered, src)
+			ordered = append(ordered, l.sources[:i]...)
+			return append(ordered, l.sources[i+1:]...)
+		}
+	}
+	return l.sources
+}
+
+// ModCopies returns every mod source's copy of a resource in search order (the copy used
+// first), leaving out base game and expansion sources as FindUnitConflicts does
+func (l *Loader) ModCopies(resourcePath string) (sources []string, copies [][]byte, err error) {
+	for _, src := range l.sourcesFor(resourcePath) {
+		if src.Type == ModSourceBaseGame || src.Type == ModSourceExpansion {
+			continue
+		}
+		fullPath, ok := resolveInSource(src, resourcePath)
+		if !ok {
+			continue
+		}
+		data, err := l.readFile(src, fullPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s from %s: %w", resourcePath, src.Identifier, err)
+		}
+		sources = append(sources, src.Identifier)
+		copies = append(copies, data)
+	}
+	return sources, copies, nil
+}
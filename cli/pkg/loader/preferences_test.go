@@ -0,0 +1,90 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSetSourcePreferences tests that a preferred mod's copy of a unit folder wins over
+// first-wins priority
+func TestSetSourcePreferences(t *testing.T) {
+	writeFile := func(root, rel, data string) {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	modA := t.TempDir()
+	modB := t.TempDir()
+	for _, mod := range []struct{ root, name string }{{modA, "a"}, {modB, "b"}} {
+		writeFile(mod.root, "pa/units/land/tank/tank.json", `{"from": "`+mod.name+`"}`)
+		writeFile(mod.root, "pa/units/land/tank/tank_tool_weapon.json", `{"from": "`+mod.name+`"}`)
+		writeFile(mod.root, "pa/units/land/bot/bot.json", `{"from": "`+mod.name+`"}`)
+	}
+
+	newLoader := func() *Loader {
+		return &Loader{
+			sources: []Source{
+				{Type: ModSourceServerMods, Identifier: "com.a", Path: modA},
+				{Type: ModSourceServerMods, Identifier: "com.b", Path: modB},
+			},
+			jsonCache:   make(map[string]map[string]interface{}),
+			sourceCache: make(map[string]*SpecFileInfo),
+		}
+	}
+
+	l := newLoader()
+	l.SetSourcePreferences(map[string]string{
+		"/pa/units/land/tank/tank.json": "com.b",
+		"/pa/units/land/bot/bot.json":   "com.missing",
+	})
+
+	tests := []struct {
+		resource string
+		want     string
+	}{
+		{"/pa/units/land/tank/tank.json", "b"},
+		{"/pa/units/land/tank/tank_tool_weapon.json", "b"}, // Same folder follows the unit
+		{"/pa/units/land/bot/bot.json", "a"},               // Unknown source: first-wins
+	}
+	for _, tt := range tests {
+		data, err := l.GetJSON(tt.resource)
+		if err != nil {
+			t.Fatalf("GetJSON(%s) error = %v", tt.resource, err)
+		}
+		if data["from"] != tt.want {
+			t.Errorf("GetJSON(%s) came from %v, want %s", tt.resource, data["from"], tt.want)
+		}
+	}
+
+	files, err := l.GetAllFilesForUnit("/pa/units/land/tank/tank.json")
+	if err != nil {
+		t.Fatalf("GetAllFilesForUnit() error = %v", err)
+	}
+	if f := files["tank.json"]; f == nil || f.Source != "com.b" {
+		t.Errorf("tank.json file = %+v, want source com.b", f)
+	}
+
+	conflicts := l.FindUnitConflicts([]string{"/pa/units/land/tank/tank.json"})
+	if len(conflicts) != 1 || conflicts[0].Winner != "com.b" || conflicts[0].Overridden[0] != "com.a" {
+		t.Errorf("conflicts = %+v, want com.b winning over com.a", conflicts)
+	}
+
+	sources, copies, err := l.ModCopies("/pa/units/land/tank/tank.json")
+	if err != nil {
+		t.Fatalf("ModCopies() error = %v", err)
+	}
+	if len(sources) != 2 || sources[0] != "com.b" || string(copies[0]) != `{"from": "b"}` {
+		t.Errorf("ModCopies() = %v %q, want com.b's copy first", sources, copies)
+	}
+
+	// Without preferences the first source wins
+	if info := newLoader().ResolveResource("/pa/units/land/tank/tank.json"); info == nil || info.Source != "com.a" {
+		t.Errorf("ResolveResource without preferences = %+v, want com.a", info)
+	}
+}
@@ -0,0 +1,98 @@
+package loader
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// SourceReadStats totals the files read from one source during an extraction
+type SourceReadStats struct {
+	Source   string        // Source identifier (pa, pa_ex1, mod identifier)
+	IsZip    bool          // Whether the source is a zip file
+	Files    int           // Files read
+	Bytes    int64         // Bytes read
+	Duration time.Duration // Time spent opening and reading those files
+}
+
+// Slow sources are only flagged once reads take long enough to matter
+const (
+	slowSourceShare   = 0.5
+	slowSourceMinTime = 2 * time.Second
+)
+
+// readStats accumulates SourceReadStats; reads may be recorded from several goroutines
+type readStats struct {
+	mu      sync.Mutex
+	sources map[string]*SourceReadStats
+}
+
+// RecordRead counts a file read from a source outside the loader, such as an exporter copying
+// unit files, towards ReadStats
+func (l *Loader) RecordRead(source string, bytes int64, elapsed time.Duration) {
+	l.stats.mu.Lock()
+	defer l.stats.mu.Unlock()
+	if l.stats.sources == nil {
+		l.stats.sources = make(map[string]*SourceReadStats)
+	}
+	s, ok := l.stats.sources[source]
+	if !ok {
+		src, _ := l.source(source)
+		s = &SourceReadStats{Source: source, IsZip: src.IsZip}
+		l.stats.sources[source] = s
+	}
+	s.Files++
+	s.Bytes += bytes
+	s.Duration += elapsed
+}
+
+// ReadStats returns the files read per source so far, slowest source first
+func (l *Loader) ReadStats() []SourceReadStats {
+	l.stats.mu.Lock()
+	defer l.stats.mu.Unlock()
+	stats := make([]SourceReadStats, 0, len(l.stats.sources))
+	for _, s := range l.stats.sources {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Duration != stats[j].Duration {
+			return stats[i].Duration > stats[j].Duration
+		}
+		return stats[i].Source < stats[j].Source
+	})
+	return stats
+}
+
+// SlowSources returns the sources that took at least half of the total read time, when
+// reading took long enough (2s) to be worth speeding up and more than one source was read.
+// A mod zip on a network drive typically shows up here.
+func SlowSources(stats []SourceReadStats) []SourceReadStats {
+	if len(stats) < 2 {
+		return nil
+	}
+	var total time.Duration
+	for _, s := range stats {
+		total += s.Duration
+	}
+	if total < slowSourceMinTime {
+		return nil
+	}
+	var slow []SourceReadStats
+	for _, s := range stats {
+		if float64(s.Duration) >= float64(total)*slowSourceShare {
+			slow = append(slow, s)
+		}
+	}
+	return slow
+}
+
+// readFile reads a file from a source (see readFromSource), counting it towards ReadStats
+func (l *Loader) readFile(src Source, fullPath string) ([]byte, error) {
+	defer l.AcquireIO()()
+	start := time.Now()
+	data, err := readFromSource(src, fullPath)
+	if err == nil {
+		l.RecordRead(src.Identifier, int64(len(data)), time.Since(start))
+	}
+	return data, err
+}
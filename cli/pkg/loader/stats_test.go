@@ -0,0 +1,98 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestReadStats verifies JSON loads are counted against the source that served them
+func TestReadStats(t *testing.T) {
+	paRoot := t.TempDir()
+	dir := filepath.Join(paRoot, "pa", "units", "land", "tank")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := `{"display_name": "Tank"}`
+	if err := os.WriteFile(filepath.Join(dir, "tank.json"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := NewMultiSourceLoader(paRoot, "pa_ex1", nil)
+	if err != nil {
+		t.Fatalf("failed to create loader: %v", err)
+	}
+	defer l.Close()
+
+	// The second load is served from the cache and isn't a read
+	for i := 0; i < 2; i++ {
+		if _, err := l.GetJSON("/pa/units/land/tank/tank.json"); err != nil {
+			t.Fatalf("GetJSON failed: %v", err)
+		}
+	}
+	l.RecordRead("pa", 100, time.Millisecond)
+
+	stats := l.ReadStats()
+	if len(stats) != 1 {
+		t.Fatalf("expected stats for 1 source, got %+v", stats)
+	}
+	s := stats[0]
+	if s.Source != "pa" || s.IsZip || s.Files != 2 || s.Bytes != int64(len(content))+100 {
+		t.Errorf("stats = %+v, want 2 files and %d bytes from pa", s, len(content)+100)
+	}
+}
+
+// TestSlowSources verifies only a source dominating a long enough read time is flagged
+func TestSlowSources(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats []SourceReadStats
+		want  []string
+	}{
+		{
+			name: "zip dominates",
+			stats: []SourceReadStats{
+				{Source: "mod.zip", IsZip: true, Duration: 9 * time.Second},
+				{Source: "pa", Duration: time.Second},
+			},
+			want: []string{"mod.zip"},
+		},
+		{
+			name: "even split",
+			stats: []SourceReadStats{
+				{Source: "a", Duration: 2 * time.Second},
+				{Source: "b", Duration: 2 * time.Second},
+				{Source: "c", Duration: 2 * time.Second},
+			},
+		},
+		{
+			name: "too quick to matter",
+			stats: []SourceReadStats{
+				{Source: "mod.zip", IsZip: true, Duration: time.Second},
+				{Source: "pa", Duration: 10 * time.Millisecond},
+			},
+		},
+		{
+			name:  "single source",
+			stats: []SourceReadStats{{Source: "pa", Duration: time.Minute}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, s := range SlowSources(tt.stats) {
+				got = append(got, s.Source)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("SlowSources = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("SlowSources = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
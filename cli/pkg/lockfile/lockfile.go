@@ -0,0 +1,147 @@
+// Package lockfile reads and writes pa-pedia.lock (models.Lockfile), the record of the
+// inputs each faction was extracted from, and compares a run's inputs against it for
+// describe-faction --frozen.
+package lockfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// DefaultPath is the lockfile describe-faction writes, relative to the working directory
+const DefaultPath = "pa-pedia.lock"
+
+// Read loads a lockfile. A missing file gives an empty lockfile, not an error.
+func Read(path string) (*models.Lockfile, error) {
+	lock := &models.Lockfile{Factions: make(map[string]models.LockedFaction)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if lock.Factions == nil {
+		lock.Factions = make(map[string]models.LockedFaction)
+	}
+	return lock, nil
+}
+
+// Write saves a lockfile with factions in ID order, so it diffs cleanly under version control
+func Write(path string, lock *models.Lockfile) error {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal lockfile: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Entry captures the inputs of an extraction. Every mod's files are hashed, so this reads
+// each mod in full.
+func Entry(profile *models.FactionProfile, mods []*loader.ModInfo, cliVersion, paBuild string) (models.LockedFaction, error) {
+	profileJSON, err := json.Marshal(profile)
+	if err != nil {
+		return models.LockedFaction{}, fmt.Errorf("failed to hash profile: %w", err)
+	}
+	sum := sha256.Sum256(profileJSON)
+	entry := models.LockedFaction{CLIVersion: cliVersion, PABuild: paBuild, ProfileSHA256: hex.EncodeToString(sum[:])}
+	for _, mod := range mods {
+		hash, err := mod.ContentHash()
+		if err != nil {
+			return entry, err
+		}
+		entry.Mods = append(entry.Mods, models.LockedMod{
+			Identifier: mod.Identifier,
+			Version:    mod.Version,
+			SHA256:     hash,
+			GitHubURL:  mod.GitHubURL,
+			Commit:     mod.GitHubCommit,
+		})
+	}
+	return entry, nil
+}
+
+// GitHubCommits returns the commit each GitHub mod of a locked extraction was downloaded at,
+// keyed by its source URL; mods locked without --pin-github-refs are left out
+func GitHubCommits(locked models.LockedFaction) map[string]string {
+	commits := make(map[string]string)
+	for _, m := range locked.Mods {
+		if m.GitHubURL != "" && m.Commit != "" {
+			commits[m.GitHubURL] = m.Commit
+		}
+	}
+	return commits
+}
+
+// Diff describes how current differs from locked, one line per difference; nil when the
+// inputs match
+func Diff(locked, current models.LockedFaction) []string {
+	var diffs []string
+	if locked.CLIVersion != current.CLIVersion {
+		diffs = append(diffs, fmt.Sprintf("pa-pedia version %s, locked %s", current.CLIVersion, locked.CLIVersion))
+	}
+	if locked.PABuild != current.PABuild {
+		diffs = append(diffs, fmt.Sprintf("PA build %s, locked %s", orNone(current.PABuild), orNone(locked.PABuild)))
+	}
+	if locked.ProfileSHA256 != current.ProfileSHA256 {
+		diffs = append(diffs, "profile changed (fields or flag overrides such as --version)")
+	}
+
+	lockedMods := make(map[string]models.LockedMod, len(locked.Mods))
+	for _, m := range locked.Mods {
+		lockedMods[m.Identifier] = m
+	}
+	currentMods := make(map[string]bool, len(current.Mods))
+	for _, m := range current.Mods {
+		currentMods[m.Identifier] = true
+		old, ok := lockedMods[m.Identifier]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("mod %s is not in the lockfile", m.Identifier))
+		case old.Version != m.Version:
+			diffs = append(diffs, fmt.Sprintf("mod %s version %s, locked %s", m.Identifier, orNone(m.Version), orNone(old.Version)))
+		case old.SHA256 != m.SHA256:
+			diffs = append(diffs, fmt.Sprintf("mod %s files changed (version %s unchanged)", m.Identifier, orNone(m.Version)))
+		}
+	}
+	for _, m := range locked.Mods {
+		if !currentMods[m.Identifier] {
+			diffs = append(diffs, fmt.Sprintf("mod %s is locked but no longer selected", m.Identifier))
+		}
+	}
+	if len(diffs) == 0 && !sameOrder(locked.Mods, current.Mods) {
+		diffs = append(diffs, "mod priority order changed")
+	}
+	return diffs
+}
+
+func sameOrder(a, b []models.LockedMod) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Identifier != b[i].Identifier {
+			return false
+		}
+	}
+	return true
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
@@ -0,0 +1,111 @@
+package lockfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestReadWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	lock, err := Read(path)
+	if err != nil || len(lock.Factions) != 0 {
+		t.Fatalf("Read of a missing lockfile = %+v, %v; want an empty lockfile", lock, err)
+	}
+
+	lock.Factions["mla"] = models.LockedFaction{CLIVersion: "1.0.0", PABuild: "123456", ProfileSHA256: "abc"}
+	if err := Write(path, lock); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	read, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if read.Factions["mla"].PABuild != "123456" {
+		t.Errorf("round trip lost the entry: %+v", read)
+	}
+}
+
+func TestEntry(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "modinfo.json"), []byte(`{"identifier":"com.test.mod"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	profile := &models.FactionProfile{ID: "test", DisplayName: "Test", FactionUnitType: "Custom1"}
+	mods := []*loader.ModInfo{{Identifier: "com.test.mod", Version: "1.0", Directory: dir}}
+
+	a, err := Entry(profile, mods, "dev", "123456")
+	if err != nil {
+		t.Fatalf("Entry failed: %v", err)
+	}
+	if len(a.Mods) != 1 || a.Mods[0].SHA256 == "" || a.ProfileSHA256 == "" {
+		t.Fatalf("incomplete entry: %+v", a)
+	}
+
+	profile.Version = "2.0.0"
+	b, _ := Entry(profile, mods, "dev", "123456")
+	if a.ProfileSHA256 == b.ProfileSHA256 {
+		t.Error("profile hash unchanged after setting a version")
+	}
+}
+
+func TestGitHubCommits(t *testing.T) {
+	locked := models.LockedFaction{Mods: []models.LockedMod{
+		{Identifier: "com.test.pinned", GitHubURL: "github.com/owner/pinned", Commit: "abc123"},
+		{Identifier: "com.test.unpinned", GitHubURL: "github.com/owner/unpinned"},
+		{Identifier: "com.test.local"},
+	}}
+	got := GitHubCommits(locked)
+	if len(got) != 1 || got["github.com/owner/pinned"] != "abc123" {
+		t.Errorf("GitHubCommits = %v, want only the pinned mod", got)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	locked := models.LockedFaction{
+		CLIVersion:    "1.0.0",
+		PABuild:       "123456",
+		ProfileSHA256: "p1",
+		Mods: []models.LockedMod{
+			{Identifier: "com.a", Version: "1.0", SHA256: "a1"},
+			{Identifier: "com.b", Version: "2.0", SHA256: "b1"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*models.LockedFaction)
+		want   []string
+	}{
+		{"same", func(*models.LockedFaction) {}, nil},
+		{"cli", func(c *models.LockedFaction) { c.CLIVersion = "1.1.0" }, []string{"pa-pedia version 1.1.0, locked 1.0.0"}},
+		{"pa build", func(c *models.LockedFaction) { c.PABuild = "" }, []string{"PA build (none), locked 123456"}},
+		{"profile", func(c *models.LockedFaction) { c.ProfileSHA256 = "p2" }, []string{"profile changed"}},
+		{"mod version", func(c *models.LockedFaction) {
+			c.Mods[0] = models.LockedMod{Identifier: "com.a", Version: "1.1", SHA256: "a2"}
+		}, []string{"mod com.a version 1.1, locked 1.0"}},
+		{"mod files", func(c *models.LockedFaction) { c.Mods[1].SHA256 = "b2" }, []string{"mod com.b files changed"}},
+		{"mod swapped", func(c *models.LockedFaction) { c.Mods[1] = models.LockedMod{Identifier: "com.c", SHA256: "c1"} }, []string{"mod com.c is not in the lockfile", "mod com.b is locked but no longer selected"}},
+		{"order", func(c *models.LockedFaction) { c.Mods[0], c.Mods[1] = c.Mods[1], c.Mods[0] }, []string{"mod priority order changed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := locked
+			current.Mods = append([]models.LockedMod(nil), locked.Mods...)
+			tt.modify(&current)
+			diffs := Diff(locked, current)
+			if len(diffs) != len(tt.want) {
+				t.Fatalf("Diff() = %q, want %d differences", diffs, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.HasPrefix(diffs[i], want) {
+					t.Errorf("difference %d = %q, want prefix %q", i, diffs[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package mirror
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/bundle"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// archive is a zip or .pafaction held in memory (faction folders are a few MB). Changes
+// are applied to the entries and the whole archive is rewritten on Commit.
+type archive struct {
+	name      string // File name, to tell .pafaction bundles from plain zips
+	createdBy string
+	entries   map[string][]byte
+	// manifest is the file list of a .pafaction as read, so listing needn't rehash
+	manifest map[string]models.BundleFile
+}
+
+func newArchive(name, createdBy string) *archive {
+	return &archive{name: name, createdBy: createdBy, entries: make(map[string][]byte)}
+}
+
+func (a *archive) isBundle() bool {
+	return strings.EqualFold(filepath.Ext(a.name), bundle.Extension)
+}
+
+// load reads the entries of a zip. A bundle's manifest.json is container metadata, not
+// faction data, so it's kept out of the entries.
+func (a *archive) load(data []byte) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if !safePath(f.Name) {
+			return fmt.Errorf("unsafe path %q in archive", f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		a.entries[f.Name] = content
+	}
+
+	if manifestData, ok := a.entries[bundle.ManifestName]; ok && a.isBundle() {
+		delete(a.entries, bundle.ManifestName)
+		var manifest models.BundleManifest
+		if err := json.Unmarshal(manifestData, &manifest); err != nil {
+			return fmt.Errorf("invalid %s: %w", bundle.ManifestName, err)
+		}
+		a.manifest = make(map[string]models.BundleFile, len(manifest.Files))
+		for _, f := range manifest.Files {
+			a.manifest[f.Path] = f
+		}
+	}
+	return nil
+}
+
+func (a *archive) Files() (map[string]models.BundleFile, error) {
+	if a.manifest != nil {
+		return a.manifest, nil
+	}
+	files := make(map[string]models.BundleFile, len(a.entries))
+	for path, data := range a.entries {
+		files[path] = describe(path, data)
+	}
+	return files, nil
+}
+
+func (a *archive) ReadFile(path string) ([]byte, error) {
+	data, ok := a.entries[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return data, nil
+}
+
+func (a *archive) WriteFile(path string, data []byte) error {
+	if !safePath(path) {
+		return fmt.Errorf("unsafe path %q", path)
+	}
+	a.entries[path] = data
+	a.manifest = nil
+	return nil
+}
+
+func (a *archive) Remove(path string) error {
+	delete(a.entries, path)
+	a.manifest = nil
+	return nil
+}
+
+// encode returns the archive's bytes: a canonical bundle (see bundle.Pack) for
+// .pafaction, otherwise a zip with entries sorted and a fixed timestamp so unchanged
+// data encodes identically
+func (a *archive) encode() ([]byte, error) {
+	if a.isBundle() {
+		return a.encodeBundle()
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	paths := make([]string, 0, len(a.entries))
+	for path := range a.entries {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: path, Method: zip.Deflate, Modified: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(a.entries[path]); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeBundle packs the entries with bundle.Pack, which needs them on disk
+func (a *archive) encodeBundle() ([]byte, error) {
+	tmp, err := os.MkdirTemp("", "pa-pedia-mirror-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	dir := filepath.Join(tmp, "faction")
+	staging := &dirStore{root: dir}
+	for path, data := range a.entries {
+		if err := staging.WriteFile(path, data); err != nil {
+			return nil, err
+		}
+	}
+	dest := filepath.Join(tmp, "faction"+bundle.Extension)
+	if _, err := bundle.Pack(dir, dest, a.createdBy); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(dest)
+}
+
+// archiveStore is a .zip or .pafaction file
+type archiveStore struct {
+	*archive
+	path string
+}
+
+// OpenArchive opens a zip or .pafaction file; a missing file is an empty store that is
+// created on Commit
+func OpenArchive(path, createdBy string) (Store, error) {
+	s := &archiveStore{archive: newArchive(path, createdBy), path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.load(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *archiveStore) String() string { return s.path }
+
+func (s *archiveStore) Commit() error {
+	data, err := s.encode()
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package mirror
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// dirStore is a local folder. Dot-prefixed files and folders (checkpoints, daemon
+// staging) are not part of the mirrored data.
+type dirStore struct {
+	root string
+}
+
+func (d *dirStore) String() string { return d.root }
+
+func (d *dirStore) Files() (map[string]models.BundleFile, error) {
+	files := make(map[string]models.BundleFile)
+	err := filepath.WalkDir(d.root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if p == d.root && os.IsNotExist(err) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if p != d.root && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(d.root, p)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		files[rel] = describe(rel, data)
+		return nil
+	})
+	return files, err
+}
+
+func (d *dirStore) ReadFile(name string) ([]byte, error) {
+	p, err := d.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(p)
+}
+
+// WriteFile writes through a temporary file so a reader never sees half a file
+func (d *dirStore) WriteFile(name string, data []byte) error {
+	p, err := d.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	tmp := filepath.Join(filepath.Dir(p), ".mirror-"+filepath.Base(p))
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Remove deletes a file and any folders it leaves empty
+func (d *dirStore) Remove(name string) error {
+	p, err := d.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	root := filepath.Clean(d.root)
+	for dir := filepath.Dir(p); dir != root && strings.HasPrefix(dir, root); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break // Not empty
+		}
+	}
+	return nil
+}
+
+func (d *dirStore) Commit() error { return nil }
+
+func (d *dirStore) path(name string) (string, error) {
+	if !safePath(name) {
+		return "", fmt.Errorf("unsafe path %q", name)
+	}
+	return filepath.Join(d.root, filepath.FromSlash(name)), nil
+}
+
+// safePath reports whether a slash-separated path stays inside the store
+func safePath(p string) bool {
+	if p == "" || strings.Contains(p, "\\") || path.IsAbs(p) || filepath.IsAbs(p) {
+		return false
+	}
+	for _, part := range strings.Split(p, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
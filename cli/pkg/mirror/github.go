@@ -0,0 +1,191 @@
+package mirror
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/bundle"
+)
+
+// gitHubAPI is the REST API base, replaced in tests
+var gitHubAPI = "https://api.github.com"
+
+// releaseStore is a zip or .pafaction asset of an existing GitHub release. The asset is
+// downloaded on open and replaced (deleted, then uploaded) on Commit.
+type releaseStore struct {
+	*archive
+	target    string
+	repoURL   string // API URL of the repository
+	client    *http.Client
+	token     string
+	uploadURL string // Asset upload endpoint, without the URI template suffix
+	assetID   int64  // 0 if the release doesn't have the asset yet
+}
+
+type gitHubRelease struct {
+	ID        int64  `json:"id"`
+	UploadURL string `json:"upload_url"`
+	Assets    []struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"assets"`
+}
+
+// OpenGitHubRelease opens github://owner/repo/<tag>/<asset>, where the asset is a .zip or
+// .pafaction. The release must already exist; a missing asset is an empty store that is
+// uploaded on Commit. GITHUB_TOKEN (or GH_TOKEN) authenticates requests and is required
+// to write.
+func OpenGitHubRelease(target, createdBy string) (Store, error) {
+	parts := strings.Split(strings.TrimPrefix(target, "github://"), "/")
+	if len(parts) < 4 || parts[0] == "" || parts[1] == "" || parts[len(parts)-1] == "" {
+		return nil, fmt.Errorf("invalid GitHub release target %q\n\nExpected github://owner/repo/<tag>/<asset>.zip", target)
+	}
+	owner, repo := parts[0], parts[1]
+	tag := strings.Join(parts[2:len(parts)-1], "/")
+	asset := parts[len(parts)-1]
+	switch strings.ToLower(filepath.Ext(asset)) {
+	case ".zip", bundle.Extension:
+	default:
+		return nil, fmt.Errorf("GitHub release asset %q must be a .zip or %s", asset, bundle.Extension)
+	}
+
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	s := &releaseStore{
+		archive: newArchive(asset, createdBy),
+		target:  target,
+		repoURL: fmt.Sprintf("%s/repos/%s/%s", gitHubAPI, owner, repo),
+		client:  &http.Client{Timeout: 5 * time.Minute},
+		token:   token,
+	}
+
+	var release gitHubRelease
+	releaseURL := s.repoURL + "/releases/tags/" + url.PathEscape(tag)
+	if err := s.getJSON(releaseURL, &release); err != nil {
+		return nil, fmt.Errorf("failed to find release %s of %s/%s: %w", tag, owner, repo, err)
+	}
+	s.uploadURL, _, _ = strings.Cut(release.UploadURL, "{")
+
+	for _, a := range release.Assets {
+		if a.Name != asset {
+			continue
+		}
+		s.assetID = a.ID
+		data, err := s.download(a.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", asset, err)
+		}
+		if err := s.load(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", target, err)
+		}
+		break
+	}
+	return s, nil
+}
+
+func (s *releaseStore) String() string { return s.target }
+
+// Commit replaces the release asset. GitHub can't overwrite an asset, so the old one is
+// deleted first.
+func (s *releaseStore) Commit() error {
+	if s.token == "" {
+		return fmt.Errorf("GitHub token not found\n\nSet GITHUB_TOKEN to a token with write access to the repository's releases")
+	}
+	data, err := s.encode()
+	if err != nil {
+		return err
+	}
+	if s.assetID != 0 {
+		assetURL := fmt.Sprintf("%s/releases/assets/%d", s.repoURL, s.assetID)
+		if _, err := s.request(http.MethodDelete, assetURL, nil, ""); err != nil {
+			return fmt.Errorf("failed to delete the old asset: %w", err)
+		}
+		s.assetID = 0
+	}
+	uploadURL := s.uploadURL + "?name=" + url.QueryEscape(s.name)
+	body, err := s.request(http.MethodPost, uploadURL, data, "application/zip")
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", s.name, err)
+	}
+	var uploaded struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal(body, &uploaded); err == nil {
+		s.assetID = uploaded.ID
+	}
+	return nil
+}
+
+func (s *releaseStore) getJSON(u string, v any) error {
+	body, err := s.request(http.MethodGet, u, nil, "")
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// download fetches an asset through the API, which redirects to the file
+func (s *releaseStore) download(u string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/octet-stream")
+	s.authorize(req)
+	return s.do(req)
+}
+
+func (s *releaseStore) request(method, u string, data []byte, contentType string) ([]byte, error) {
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.authorize(req)
+	return s.do(req)
+}
+
+func (s *releaseStore) authorize(req *http.Request) {
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+}
+
+func (s *releaseStore) do(req *http.Request) ([]byte, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		msg := strings.TrimSpace(string(body))
+		if len(msg) > 200 {
+			msg = msg[:200]
+		}
+		return nil, fmt.Errorf("%s %s: %s %s", req.Method, req.URL.Path, resp.Status, msg)
+	}
+	return body, nil
+}
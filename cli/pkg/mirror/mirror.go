@@ -0,0 +1,159 @@
+// Package mirror syncs faction data between storage targets (local folders, zip and
+// .pafaction archives, S3 prefixes and GitHub release assets), copying only files whose
+// content hash differs.
+package mirror
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/bundle"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// Store is one end of a mirror: a set of files by slash-separated path. Writes may be
+// buffered until Commit (archives are rewritten once, object stores record their
+// manifest).
+type Store interface {
+	// Files lists every file with its size and SHA-256, keyed by path
+	Files() (map[string]models.BundleFile, error)
+	ReadFile(path string) ([]byte, error)
+	WriteFile(path string, data []byte) error
+	Remove(path string) error
+	// Commit finishes a sync that changed the store
+	Commit() error
+	String() string
+}
+
+// Options configures Sync
+type Options struct {
+	// Delete removes destination files the source doesn't have
+	Delete bool
+	// DryRun reports what would change without writing anything
+	DryRun bool
+	// Logf reports each copied or deleted file; nil for silence
+	Logf func(format string, args ...any)
+}
+
+// Result summarises a sync, paths sorted
+type Result struct {
+	Copied    []string
+	Deleted   []string
+	Unchanged int
+	Bytes     int64 // Total size of copied files
+}
+
+// Changed reports whether the sync wrote anything (or would have, in a dry run)
+func (r *Result) Changed() bool {
+	return len(r.Copied) > 0 || len(r.Deleted) > 0
+}
+
+// Sync makes dst match src. Files are compared by SHA-256 from each store's manifest, so
+// unchanged files are neither read nor written.
+func Sync(src, dst Store, opts Options) (*Result, error) {
+	logf := opts.Logf
+	if logf == nil {
+		logf = func(string, ...any) {}
+	}
+	srcFiles, err := src.Files()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", src, err)
+	}
+	// A missing source lists as empty; mirroring it would delete the destination
+	if len(srcFiles) == 0 {
+		return nil, fmt.Errorf("%s has no files to mirror", src)
+	}
+	dstFiles, err := dst.Files()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dst, err)
+	}
+
+	result := &Result{}
+	for _, path := range sortedPaths(srcFiles) {
+		want := srcFiles[path]
+		if have, ok := dstFiles[path]; ok && have.SHA256 == want.SHA256 {
+			result.Unchanged++
+			continue
+		}
+		result.Copied = append(result.Copied, path)
+		result.Bytes += want.Size
+		logf("  + %s", path)
+		if opts.DryRun {
+			continue
+		}
+		data, err := src.ReadFile(path)
+		if err != nil {
+			return result, fmt.Errorf("failed to read %s from %s: %w", path, src, err)
+		}
+		if err := dst.WriteFile(path, data); err != nil {
+			return result, fmt.Errorf("failed to write %s to %s: %w", path, dst, err)
+		}
+	}
+
+	if opts.Delete {
+		for _, path := range sortedPaths(dstFiles) {
+			if _, ok := srcFiles[path]; ok {
+				continue
+			}
+			result.Deleted = append(result.Deleted, path)
+			logf("  - %s", path)
+			if opts.DryRun {
+				continue
+			}
+			if err := dst.Remove(path); err != nil {
+				return result, fmt.Errorf("failed to delete %s from %s: %w", path, dst, err)
+			}
+		}
+	}
+
+	if result.Changed() && !opts.DryRun {
+		if err := dst.Commit(); err != nil {
+			return result, fmt.Errorf("failed to finish writing %s: %w", dst, err)
+		}
+	}
+	return result, nil
+}
+
+// Open returns the store for a target:
+//
+//	./factions, /srv/data           local folder (created as a destination)
+//	./MLA.zip, ./MLA.pafaction      archive (created as a destination)
+//	s3://bucket/prefix              S3 or S3-compatible object store
+//	github://owner/repo/tag/name.zip  zip asset of a GitHub release
+//
+// createdBy is recorded in .pafaction manifests written as destinations.
+func Open(target, createdBy string) (Store, error) {
+	switch {
+	case strings.HasPrefix(target, "s3://"):
+		return OpenS3(target)
+	case strings.HasPrefix(target, "github://"):
+		return OpenGitHubRelease(target, createdBy)
+	}
+	switch strings.ToLower(filepath.Ext(target)) {
+	case ".zip", bundle.Extension:
+		return OpenArchive(target, createdBy)
+	}
+	if info, err := os.Stat(target); err == nil && !info.IsDir() {
+		return nil, fmt.Errorf("%s is a file but not a .zip or %s archive", target, bundle.Extension)
+	}
+	return &dirStore{root: target}, nil
+}
+
+func sortedPaths(files map[string]models.BundleFile) []string {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func describe(path string, data []byte) models.BundleFile {
+	sum := sha256.Sum256(data)
+	return models.BundleFile{Path: path, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])}
+}
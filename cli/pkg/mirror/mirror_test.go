@@ -0,0 +1,279 @@
+package mirror
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/upload"
+)
+
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readAll(t *testing.T, s Store) map[string]string {
+	t.Helper()
+	files, err := s.Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make(map[string]string, len(files))
+	for p := range files {
+		data, err := s.ReadFile(p)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error = %v", p, err)
+		}
+		out[p] = string(data)
+	}
+	return out
+}
+
+func TestSyncDirectories(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "src")
+	dst := filepath.Join(tmp, "dst")
+	writeTree(t, src, map[string]string{
+		"MLA/metadata.json":       `{"identifier":"MLA"}`,
+		"MLA/units.json":          `[1]`,
+		"MLA/assets/tank.png":     "png",
+		"MLA/.checkpoint/ignored": "x",
+		"Legion/metadata.json":    `{"identifier":"Legion"}`,
+	})
+	writeTree(t, dst, map[string]string{
+		"MLA/units.json":      `[0]`,
+		"MLA/assets/tank.png": "png",
+		"MLA/old/stale.json":  "{}",
+	})
+
+	// Without --delete stale files are left alone
+	result, err := Sync(&dirStore{root: src}, &dirStore{root: dst}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCopied := []string{"Legion/metadata.json", "MLA/metadata.json", "MLA/units.json"}
+	if !reflect.DeepEqual(result.Copied, wantCopied) || result.Unchanged != 1 || len(result.Deleted) != 0 {
+		t.Errorf("Sync = copied %v unchanged %d deleted %v", result.Copied, result.Unchanged, result.Deleted)
+	}
+
+	// Dry run reports the deletion without making it
+	result, err = Sync(&dirStore{root: src}, &dirStore{root: dst}, Options{Delete: true, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Deleted, []string{"MLA/old/stale.json"}) || len(result.Copied) != 0 {
+		t.Errorf("dry run = copied %v deleted %v", result.Copied, result.Deleted)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "MLA", "old", "stale.json")); err != nil {
+		t.Errorf("dry run deleted a file: %v", err)
+	}
+
+	if _, err := Sync(&dirStore{root: src}, &dirStore{root: dst}, Options{Delete: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "MLA", "old")); !os.IsNotExist(err) {
+		t.Errorf("emptied folder not removed: %v", err)
+	}
+	want := readAll(t, &dirStore{root: src})
+	if got := readAll(t, &dirStore{root: dst}); !reflect.DeepEqual(got, want) {
+		t.Errorf("dst = %v, want %v", got, want)
+	}
+	if _, ok := want["MLA/.checkpoint/ignored"]; ok {
+		t.Error("dot folders should not be mirrored")
+	}
+}
+
+func TestSyncArchives(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "MLA")
+	writeTree(t, src, map[string]string{
+		"metadata.json":   `{"identifier":"MLA","displayName":"MLA","version":"1.0.0","type":"base-game"}`,
+		"units.json":      `[]`,
+		"assets/tank.png": "png",
+	})
+
+	for _, name := range []string{"MLA.zip", "MLA.pafaction"} {
+		t.Run(name, func(t *testing.T) {
+			archivePath := filepath.Join(tmp, name)
+			dst, err := Open(archivePath, "pa-pedia test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Sync(&dirStore{root: src}, dst, Options{}); err != nil {
+				t.Fatal(err)
+			}
+			first, err := os.ReadFile(archivePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Unchanged data is not rewritten
+			dst, _ = Open(archivePath, "pa-pedia test")
+			result, err := Sync(&dirStore{root: src}, dst, Options{Delete: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Changed() || result.Unchanged != 3 {
+				t.Errorf("second sync = copied %v deleted %v unchanged %d", result.Copied, result.Deleted, result.Unchanged)
+			}
+
+			// And back out to a folder
+			out := filepath.Join(tmp, "out-"+name)
+			archive, _ := Open(archivePath, "")
+			if _, err := Sync(archive, &dirStore{root: out}, Options{}); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := readAll(t, &dirStore{root: out}), readAll(t, &dirStore{root: src}); !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip = %v, want %v", got, want)
+			}
+			again, _ := os.ReadFile(archivePath)
+			if string(again) != string(first) {
+				t.Error("archive rewritten without changes")
+			}
+		})
+	}
+}
+
+// fakeObjects is an in-memory objectClient
+type fakeObjects struct {
+	objects map[string][]byte
+	puts    []string
+}
+
+func (f *fakeObjects) GetObject(key string) ([]byte, error) {
+	data, ok := f.objects[key]
+	if !ok {
+		return nil, upload.ErrNotFound
+	}
+	return data, nil
+}
+
+func (f *fakeObjects) PutObject(key string, data []byte, contentType, cacheControl string) error {
+	f.objects[key] = data
+	f.puts = append(f.puts, key)
+	return nil
+}
+
+func (f *fakeObjects) DeleteObject(key string) error {
+	delete(f.objects, key)
+	return nil
+}
+
+func TestSyncObjectStore(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	writeTree(t, src, map[string]string{
+		"MLA/units.json":      `[1]`,
+		"MLA/assets/tank.png": "png",
+	})
+	client := &fakeObjects{objects: map[string][]byte{}}
+	open := func() Store {
+		return &objectStore{client: client, target: upload.Target{Scheme: "s3", Bucket: "b", Prefix: "data"}}
+	}
+
+	if _, err := Sync(&dirStore{root: src}, open(), Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := client.objects["data/"+ObjectManifestName]; !ok {
+		t.Fatalf("mirror manifest not written: %v", client.puts)
+	}
+
+	writeTree(t, src, map[string]string{"MLA/units.json": `[2]`})
+	os.Remove(filepath.Join(src, "MLA", "assets", "tank.png"))
+	client.puts = nil
+	result, err := Sync(&dirStore{root: src}, open(), Options{Delete: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantPuts := []string{"data/MLA/units.json", "data/" + ObjectManifestName}
+	if !reflect.DeepEqual(client.puts, wantPuts) || !reflect.DeepEqual(result.Deleted, []string{"MLA/assets/tank.png"}) {
+		t.Errorf("second sync puts %v deleted %v", client.puts, result.Deleted)
+	}
+	if got := readAll(t, open()); !reflect.DeepEqual(got, map[string]string{"MLA/units.json": `[2]`}) {
+		t.Errorf("objects = %v", got)
+	}
+}
+
+func TestOpenGitHubRelease(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "MLA")
+	writeTree(t, src, map[string]string{"units.json": `[]`})
+
+	var asset []byte
+	var deleted int
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/o/r/releases/tags/v1":
+			assets := []map[string]any{}
+			if asset != nil {
+				assets = append(assets, map[string]any{"id": 7, "name": "MLA.zip", "url": server.URL + "/download"})
+			}
+			json.NewEncoder(w).Encode(map[string]any{
+				"id":         1,
+				"upload_url": server.URL + "/repos/o/r/releases/1/assets{?name,label}",
+				"assets":     assets,
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/download":
+			w.Write(asset)
+		case r.Method == http.MethodDelete && r.URL.Path == "/repos/o/r/releases/assets/7":
+			deleted++
+			asset = nil
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/o/r/releases/1/assets":
+			if r.URL.Query().Get("name") != "MLA.zip" || r.Header.Get("Authorization") != "Bearer secret" {
+				http.Error(w, "bad upload", http.StatusBadRequest)
+				return
+			}
+			asset, _ = io.ReadAll(r.Body)
+			fmt.Fprint(w, `{"id":7}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	gitHubAPI = server.URL
+	defer func() { gitHubAPI = "https://api.github.com" }()
+	t.Setenv("GITHUB_TOKEN", "secret")
+
+	for i, content := range []string{`[]`, `[1]`} {
+		writeTree(t, src, map[string]string{"units.json": content})
+		dst, err := Open("github://o/r/v1/MLA.zip", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Sync(&dirStore{root: src}, dst, Options{}); err != nil {
+			t.Fatalf("sync %d: %v", i, err)
+		}
+	}
+	if deleted != 1 {
+		t.Errorf("old asset deleted %d times, want 1", deleted)
+	}
+	release, err := Open("github://o/r/v1/MLA.zip", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, release); got["units.json"] != `[1]` {
+		t.Errorf("release asset = %v", got)
+	}
+
+	if _, err := Open("github://o/r/missing/MLA.zip", ""); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("missing release error = %v", err)
+	}
+	if _, err := Open("github://o/r/MLA.zip", ""); err == nil {
+		t.Error("target without a tag accepted")
+	}
+}
@@ -0,0 +1,118 @@
+package mirror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/upload"
+)
+
+// ObjectManifestName is the object, under a bucket prefix, recording the path, size and
+// SHA-256 of every mirrored file, since object listings don't carry SHA-256 hashes
+const ObjectManifestName = ".pa-pedia-mirror.json"
+
+// objectClient is the part of upload.S3Uploader an object store needs
+type objectClient interface {
+	GetObject(key string) ([]byte, error)
+	PutObject(key string, data []byte, contentType, cacheControl string) error
+	DeleteObject(key string) error
+}
+
+// objectManifest is the content of ObjectManifestName
+type objectManifest struct {
+	Files []models.BundleFile `json:"files"`
+}
+
+// objectStore is a bucket prefix. Its file list is the mirror manifest, so objects
+// changed by other tools aren't noticed.
+type objectStore struct {
+	client objectClient
+	target upload.Target
+	files  map[string]models.BundleFile // Loaded by Files
+}
+
+// OpenS3 opens s3://bucket/prefix with the standard AWS environment variables (see
+// upload.NewS3Uploader)
+func OpenS3(target string) (Store, error) {
+	t, err := upload.ParseTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	if t.Scheme != "s3" {
+		return nil, fmt.Errorf("unsupported mirror target %q", target)
+	}
+	client, err := upload.NewS3Uploader(t.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	return &objectStore{client: client, target: t}, nil
+}
+
+func (o *objectStore) String() string { return o.target.String() }
+
+func (o *objectStore) Files() (map[string]models.BundleFile, error) {
+	o.files = make(map[string]models.BundleFile)
+	data, err := o.client.GetObject(o.target.Key(ObjectManifestName))
+	if errors.Is(err, upload.ErrNotFound) {
+		return o.files, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var manifest objectManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ObjectManifestName, err)
+	}
+	for _, f := range manifest.Files {
+		o.files[f.Path] = f
+	}
+	return o.files, nil
+}
+
+func (o *objectStore) ReadFile(name string) ([]byte, error) {
+	return o.client.GetObject(o.target.Key(name))
+}
+
+func (o *objectStore) WriteFile(name string, data []byte) error {
+	if !safePath(name) {
+		return fmt.Errorf("unsafe path %q", name)
+	}
+	// Index files are recognised by name wherever they sit in the dataset
+	if err := o.client.PutObject(o.target.Key(name), data, upload.ContentType(name), upload.CacheControl(path.Base(name))); err != nil {
+		return err
+	}
+	o.ensureFiles()[name] = describe(name, data)
+	return nil
+}
+
+func (o *objectStore) Remove(name string) error {
+	if err := o.client.DeleteObject(o.target.Key(name)); err != nil {
+		return err
+	}
+	delete(o.ensureFiles(), name)
+	return nil
+}
+
+// Commit writes the mirror manifest
+func (o *objectStore) Commit() error {
+	files := o.ensureFiles()
+	manifest := objectManifest{Files: make([]models.BundleFile, 0, len(files))}
+	for _, p := range sortedPaths(files) {
+		manifest.Files = append(manifest.Files, files[p])
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return o.client.PutObject(o.target.Key(ObjectManifestName), data, "application/json", upload.CacheControlIndex)
+}
+
+func (o *objectStore) ensureFiles() map[string]models.BundleFile {
+	if o.files == nil {
+		o.files = make(map[string]models.BundleFile)
+	}
+	return o.files
+}
@@ -0,0 +1,17 @@
+package models
+
+// IDAliases is id-aliases.json, kept in a faction folder by describe-faction. Unit IDs are
+// short names that depend on which other files exist (see Loader.GetSafeName), so a new
+// mod file can change them; the IDs recorded here are pinned on the next export, and any
+// unit that still ends up under a new ID is listed so old links can be redirected.
+type IDAliases struct {
+	IDs     map[string]string `json:"ids" jsonschema:"required,description=Unit ID by resource name for every unit this folder has exported (kept after a unit is removed so its ID isn't reused)"`
+	Renames []IDRename        `json:"renames" jsonschema:"required,description=Unit ID changes per export with the oldest first"`
+}
+
+// IDRename lists the unit IDs one export changed
+type IDRename struct {
+	Version  string            `json:"version" jsonschema:"required,description=Faction version of the export with the new IDs"`
+	Previous string            `json:"previous,omitempty" jsonschema:"description=Faction version of the export the old IDs come from"`
+	Aliases  map[string]string `json:"aliases" jsonschema:"required,description=New unit ID by old unit ID"`
+}
@@ -0,0 +1,20 @@
+package models
+
+// BundleManifest is manifest.json, the first entry of a .pafaction bundle. It
+// identifies the faction without unpacking and lists a checksum for every other
+// file so a bundle can be verified end to end.
+type BundleManifest struct {
+	FormatVersion int          `json:"formatVersion" jsonschema:"required,minimum=1,description=Version of the bundle container format (not the faction data version)"`
+	Identifier    string       `json:"identifier" jsonschema:"required,description=Faction identifier copied from metadata.json"`
+	DisplayName   string       `json:"displayName" jsonschema:"required,description=Faction display name copied from metadata.json"`
+	Version       string       `json:"version" jsonschema:"required,description=Faction data version copied from metadata.json"`
+	CreatedBy     string       `json:"createdBy,omitempty" jsonschema:"description=Tool and version that packed the bundle (e.g. pa-pedia v1.4.0)"`
+	Files         []BundleFile `json:"files" jsonschema:"required,description=Every file in the bundle except manifest.json sorted by path"`
+}
+
+// BundleFile is one file listed in a bundle manifest
+type BundleFile struct {
+	Path   string `json:"path" jsonschema:"required,description=Slash-separated path relative to the faction folder root (e.g. units.json)"`
+	Size   int64  `json:"size" jsonschema:"required,minimum=0,description=Uncompressed size in bytes"`
+	SHA256 string `json:"sha256" jsonschema:"required,description=Hex-encoded SHA-256 of the uncompressed file"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// ConflictReport is conflicts.json, written beside metadata.json by describe-faction when two
+// or more mods are selected. It lists every file of an exported unit that more than one
+// selected mod provides, so balance conflicts hidden by first-wins resolution can be reviewed.
+type ConflictReport struct {
+	Mods  []string        `json:"mods" jsonschema:"required,description=Selected mod identifiers in priority order"`
+	Units []UnitConflicts `json:"units" jsonschema:"required,description=Exported units with at least one file provided by several mods in export order"`
+}
+
+// UnitConflicts groups the conflicting files of one unit
+type UnitConflicts struct {
+	Identifier   string         `json:"identifier" jsonschema:"required,description=Unit identifier as in units.json"`
+	ResourceName string         `json:"resourceName" jsonschema:"required,description=Resource path of the unit JSON"`
+	Files        []FileConflict `json:"files" jsonschema:"required,description=The unit's files (unit JSON and referenced specs and icon) provided by several mods"`
+}
+
+// FileConflict is one resource provided by several mods
+type FileConflict struct {
+	ResourcePath    string   `json:"resourcePath" jsonschema:"required,description=PA resource path of the file"`
+	Sources         []string `json:"sources" jsonschema:"required,description=Mods providing the file; the first one's copy is used"`
+	Identical       bool     `json:"identical,omitempty" jsonschema:"description=True if every mod's copy is byte-for-byte the same"`
+	DifferingFields []string `json:"differingFields,omitempty" jsonschema:"description=Dotted JSON fields whose values differ between the copies such as max_health or navigation.move_speed (arrays compare whole)"`
+}
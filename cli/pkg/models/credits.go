@@ -0,0 +1,20 @@
+package models
+
+// Credits is CREDITS.json, written beside metadata.json by describe-faction. It names the
+// game and every mod whose data is in the faction folder, so the folder can be
+// redistributed with the attribution their authors ask for.
+type Credits struct {
+	Faction string        `json:"faction" jsonschema:"required,description=Display name of the exported faction"`
+	Sources []CreditEntry `json:"sources" jsonschema:"required,description=Game and mods the data came from in priority order (mods first and the base game last)"`
+}
+
+// CreditEntry credits one source of a faction's data
+type CreditEntry struct {
+	Source  string `json:"source" jsonschema:"required,description=Source identifier as used in units.json file provenance such as pa or pa_ex1 or com.pa.legion-expansion"`
+	Name    string `json:"name" jsonschema:"required,description=Display name of the game or mod"`
+	Author  string `json:"author,omitempty" jsonschema:"description=Author from modinfo.json (Uber Entertainment for the base game)"`
+	Version string `json:"version,omitempty" jsonschema:"description=Mod version from modinfo.json or the PA build for the base game"`
+	License string `json:"license,omitempty" jsonschema:"description=License from modinfo.json. Absent means none was stated and the author should be asked before redistributing"`
+	URL     string `json:"url,omitempty" jsonschema:"description=Forum or project page from modinfo.json"`
+	Units   int    `json:"units" jsonschema:"description=Exported units with at least one file from this source"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// CrossFactionLinks is cross-faction.json in an addon export. An addon's units are built by,
+// and build, units of the factions it extends; those units aren't in the addon's units.json,
+// so each relationship is listed here with the base unit's faction for placing the addon
+// units into that faction's tech tree.
+type CrossFactionLinks struct {
+	BaseFactions []string           `json:"baseFactions" jsonschema:"required,description=Faction display names that this addon extends (same as metadata.json)"`
+	Edges        []CrossFactionEdge `json:"edges" jsonschema:"required,description=Build relationships between addon units and base faction units sorted by addon unit"`
+}
+
+// CrossFactionEdge is one build relationship between an addon unit and a base faction unit
+type CrossFactionEdge struct {
+	Unit        string `json:"unit" jsonschema:"required,description=Unit ID of the addon unit"`
+	Relation    string `json:"relation" jsonschema:"required,enum=builtBy,enum=builds,description=builtBy if the base unit builds the addon unit and builds if the addon unit builds the base unit"`
+	BaseUnit    string `json:"baseUnit" jsonschema:"required,description=Unit ID of the base faction unit"`
+	BaseFaction string `json:"baseFaction,omitempty" jsonschema:"description=Display name of the base unit's faction (omitted when its unit types name no known faction)"`
+}
+
+// Cross-faction relations
+const (
+	RelationBuiltBy = "builtBy" // BaseUnit builds Unit
+	RelationBuilds  = "builds"  // Unit builds BaseUnit
+)
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ExtensionPrefix marks third-party fields. Objects that carry Extensions accept any
+// property starting with it, and the schemas allow them via patternProperties.
+const ExtensionPrefix = "x-"
+
+// Extensions holds x- prefixed fields that third-party tools add to an exported
+// object. They are inlined alongside the regular fields rather than nested, so
+// {"id":"tank","x-balance-note":"buffed"} round-trips through the CLI unchanged.
+type Extensions map[string]any
+
+// marshalWithExtensions encodes v (the object without its extensions) and appends
+// the extension fields in key order.
+func marshalWithExtensions(v any, ext Extensions) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(ext) == 0 {
+		return data, err
+	}
+
+	keys := make([]string, 0, len(ext))
+	for key := range ext {
+		if !strings.HasPrefix(key, ExtensionPrefix) {
+			return nil, fmt.Errorf("extension field %q must start with %q", key, ExtensionPrefix)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.Write(data[:len(data)-1]) // drop the closing brace
+	for _, key := range keys {
+		value, err := json.Marshal(ext[key])
+		if err != nil {
+			return nil, fmt.Errorf("extension field %q: %w", key, err)
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// extractExtensions returns the x- prefixed fields of a JSON object, or nil when it has none.
+func extractExtensions(data []byte) (Extensions, error) {
+	// Cheap check first: most objects have no extensions
+	if !bytes.Contains(data, []byte(`"`+ExtensionPrefix)) {
+		return nil, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	var ext Extensions
+	for key, raw := range fields {
+		if !strings.HasPrefix(key, ExtensionPrefix) {
+			continue
+		}
+		var value any
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("extension field %q: %w", key, err)
+		}
+		if ext == nil {
+			ext = make(Extensions)
+		}
+		ext[key] = value
+	}
+	return ext, nil
+}
+
+// MarshalJSON inlines the unit's extension fields
+func (u Unit) MarshalJSON() ([]byte, error) {
+	type plain Unit
+	return marshalWithExtensions(plain(u), u.Extensions)
+}
+
+// UnmarshalJSON captures x- prefixed fields into Extensions
+func (u *Unit) UnmarshalJSON(data []byte) error {
+	type plain Unit
+	if err := json.Unmarshal(data, (*plain)(u)); err != nil {
+		return err
+	}
+	ext, err := extractExtensions(data)
+	if err != nil {
+		return err
+	}
+	u.Extensions = ext
+	return nil
+}
+
+// MarshalJSON inlines the metadata's extension fields
+func (m FactionMetadata) MarshalJSON() ([]byte, error) {
+	type plain FactionMetadata
+	return marshalWithExtensions(plain(m), m.Extensions)
+}
+
+// UnmarshalJSON captures x- prefixed fields into Extensions
+func (m *FactionMetadata) UnmarshalJSON(data []byte) error {
+	type plain FactionMetadata
+	if err := json.Unmarshal(data, (*plain)(m)); err != nil {
+		return err
+	}
+	ext, err := extractExtensions(data)
+	if err != nil {
+		return err
+	}
+	m.Extensions = ext
+	return nil
+}
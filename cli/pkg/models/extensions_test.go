@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestUnitExtensionsRoundTrip(t *testing.T) {
+	input := `{"id":"tank","resourceName":"/pa/units/land/tank/tank.json","displayName":"Ant","tier":1,"accessible":true,"specs":{},"buildRelationships":{},"x-balance":{"note":"buffed","patch":2},"x-tags":["meta"]}`
+
+	var unit Unit
+	if err := json.Unmarshal([]byte(input), &unit); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if unit.ID != "tank" || unit.Tier != 1 {
+		t.Errorf("regular fields not decoded: %+v", unit)
+	}
+	want := Extensions{
+		"x-balance": map[string]any{"note": "buffed", "patch": float64(2)},
+		"x-tags":    []any{"meta"},
+	}
+	if !reflect.DeepEqual(unit.Extensions, want) {
+		t.Errorf("Extensions = %v, want %v", unit.Extensions, want)
+	}
+
+	out, err := json.Marshal(unit)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(out) != input {
+		t.Errorf("Marshal() = %s\nwant %s", out, input)
+	}
+}
+
+func TestExtensionsEdgeCases(t *testing.T) {
+	tests := []struct {
+		name    string
+		meta    FactionMetadata
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "no extensions",
+			meta: FactionMetadata{Identifier: "a", DisplayName: "A", Version: "1", Type: "mod"},
+			want: `{"identifier":"a","displayName":"A","version":"1","type":"mod"}`,
+		},
+		{
+			name: "sorted extension keys",
+			meta: FactionMetadata{Identifier: "a", DisplayName: "A", Version: "1", Type: "mod", Extensions: Extensions{"x-z": 1, "x-a": true}},
+			want: `{"identifier":"a","displayName":"A","version":"1","type":"mod","x-a":true,"x-z":1}`,
+		},
+		{
+			name:    "key without prefix",
+			meta:    FactionMetadata{Extensions: Extensions{"balance": 1}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := json.Marshal(tt.meta)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Marshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(out) != tt.want {
+				t.Errorf("Marshal() = %s\nwant %s", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadataIgnoresUnknownNonExtensionFields(t *testing.T) {
+	var meta FactionMetadata
+	if err := json.Unmarshal([]byte(`{"identifier":"a","custom":1,"x-ok":"yes"}`), &meta); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(meta.Extensions) != 1 || meta.Extensions["x-ok"] != "yes" {
+		t.Errorf("Extensions = %v, want only x-ok", meta.Extensions)
+	}
+}
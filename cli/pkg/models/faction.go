@@ -10,6 +10,7 @@ type TeamColors struct {
 
 // FactionMetadata represents the metadata.json file for a faction folder
 type FactionMetadata struct {
+	Schema      string   `json:"$schema,omitempty" jsonschema:"description=URL of the JSON Schema for this file so editors can validate it"`
 	Identifier  string   `json:"identifier" jsonschema:"required,description=Unique identifier for the faction (e.g. com.pa.legion-expansion)"`
 	DisplayName string   `json:"displayName" jsonschema:"required,description=Human-readable name for the faction"`
 	Version     string   `json:"version" jsonschema:"required,description=Semantic version of the faction data (e.g. 1.2.0)"`
@@ -17,6 +18,7 @@ type FactionMetadata struct {
 	Description string   `json:"description,omitempty" jsonschema:"description=Brief description of the faction"`
 	DateCreated string   `json:"dateCreated,omitempty" jsonschema:"description=ISO 8601 date when faction was created (YYYY-MM-DD)"`
 	Build       string   `json:"build,omitempty" jsonschema:"description=PA game build number this faction targets"`
+	PABuild     string   `json:"paBuild,omitempty" jsonschema:"description=Build number of the PA installation the data was extracted from (compared by pa-pedia status)"`
 	Type            string   `json:"type" jsonschema:"required,enum=base-game,enum=mod,description=Type of faction (base-game or mod)"`
 	Mods            []string `json:"mods,omitempty" jsonschema:"description=List of mod identifiers that compose this faction"`
 	BackgroundImage string   `json:"backgroundImage,omitempty" jsonschema:"description=Path to faction background image relative to faction folder root"`
@@ -33,6 +35,46 @@ type FactionMetadata struct {
 	// TeamColors is the faction's default team-paint colour pair for the 3D model
 	// viewer. Optional; the web app falls back to a neutral pair if absent.
 	TeamColors *TeamColors `json:"teamColors,omitempty" jsonschema:"description=Default faction team-paint colour pair (primary/secondary hex) for the 3D model viewer"`
+
+	// IPFSCID is the content identifier returned by an IPFS node when the folder was
+	// published with --publish ipfs. It is written after publishing, so the snapshot
+	// behind the CID has this field absent.
+	IPFSCID string `json:"ipfsCid,omitempty" jsonschema:"description=IPFS content identifier of the published faction folder snapshot (the snapshot itself predates this field)"`
+
+	// Assets records game files left out of the export (--strip-icons, --no-assets).
+	// Absent means icons and spec files were copied from the sources as usual.
+	Assets string `json:"assets,omitempty" jsonschema:"enum=icons-stripped,enum=none,description=Game files left out of this export: icons-stripped replaces game icons with generated placeholders and none also omits the copied spec JSON and background image. Absent means a full export."`
+
+	// GitHubCommits records the commit each GitHub mod was downloaded at when exported with
+	// --pin-github-refs. Absent when refs weren't pinned.
+	GitHubCommits []GitHubCommit `json:"githubCommits,omitempty" jsonschema:"description=Commit each GitHub mod source was downloaded at (describe-faction --pin-github-refs)"`
+
+	// ContentHash is the hex SHA-256 of units.json in canonical form (see
+	// exporter.IndexContentHash), so a re-export whose units didn't change keeps its hash
+	// whatever --minify or --prune-empty were used.
+	ContentHash string `json:"contentHash,omitempty" jsonschema:"description=Hex SHA-256 of units.json in canonical form (compact and unpruned and without $schema); unchanged when a re-export produced the same units"`
+
+	// ModVersions records each mod the faction was extracted from as resolved, in priority
+	// order. Absent for the base game.
+	ModVersions []ModVersion `json:"modVersions,omitempty" jsonschema:"description=Version and source of each mod the faction was extracted from in priority order"`
+
+	// Extensions holds x- prefixed fields added by third-party tools (inlined in JSON).
+	Extensions Extensions `json:"-"`
+}
+
+// GitHubCommit is the commit a GitHub mod source's ref resolved to
+type GitHubCommit struct {
+	URL    string `json:"url" jsonschema:"required,description=GitHub mod source as listed in mods"`
+	Ref    string `json:"ref" jsonschema:"required,description=Branch or tag or SHA named by the URL (main when the URL names none)"`
+	Commit string `json:"commit" jsonschema:"required,description=Full commit SHA the files were downloaded at"`
+}
+
+// ModVersion is a mod as it was resolved for an export
+type ModVersion struct {
+	Identifier string `json:"identifier" jsonschema:"required,description=Mod identifier from modinfo.json"`
+	Version    string `json:"version,omitempty" jsonschema:"description=Mod version from modinfo.json"`
+	Source     string `json:"source,omitempty" jsonschema:"enum=server_mods,enum=client_mods,enum=download,enum=github,description=Where the mod was found"`
+	Commit     string `json:"commit,omitempty" jsonschema:"description=Commit a GitHub mod was downloaded at (describe-faction --pin-github-refs)"`
 }
 
 // FactionDatabase represents the units.json file for a faction folder
@@ -46,7 +88,9 @@ type FactionDatabase struct {
 
 // FactionIndex represents the new lightweight units.json index format (Phase 1.5+)
 type FactionIndex struct {
-	Units []UnitIndexEntry `json:"units" jsonschema:"required,description=Lightweight unit index with file provenance"`
+	Schema string           `json:"$schema,omitempty" jsonschema:"description=URL of the JSON Schema for this file so editors can validate it"`
+	Layout string           `json:"layout,omitempty" jsonschema:"enum=mirrored,enum=flat,description=Layout of exported unit files: mirrored (assets/pa/...) or flat (units/<id>/...). File paths are relative to assets/ or units/ respectively. Absent means mirrored."`
+	Units  []UnitIndexEntry `json:"units" jsonschema:"required,description=Lightweight unit index with file provenance"`
 }
 
 // UnitIndexEntry represents a single unit in the faction index
@@ -57,10 +101,12 @@ type UnitIndexEntry struct {
 	Source      string     `json:"source" jsonschema:"required,description=Primary source that first defined this unit such as pa, pa_ex1, or com.pa.legion-expansion. For base game units modified by mods, this reflects the original source. See Files array for complete provenance of all unit files including modifications."`
 	Files       []UnitFile `json:"files" jsonschema:"required,description=All discovered files for this unit with provenance"`
 	Unit        Unit       `json:"unit" jsonschema:"required,description=Complete resolved unit specification with base_spec inheritance merged and all calculations complete. This contains the full parsed Unit object ready for consumption by the web app."`
+	Warnings    []string   `json:"warnings,omitempty" jsonschema:"description=Non-fatal data issues found while parsing this unit (missing ammo spec or zero rate of fire or unresolved tool type or base_spec cycle or malformed buildable_types)"`
 }
 
 // UnitFile represents a single file associated with a unit
 type UnitFile struct {
-	Path   string `json:"path" jsonschema:"required,description=Relative path within the unit folder such as tank.json or tank_icon_buildbar.png"`
-	Source string `json:"source" jsonschema:"required,description=Source that provided this file such as pa, pa_ex1, or com.pa.legion-expansion"`
+	Path      string `json:"path" jsonschema:"required,description=Path relative to the layout folder such as pa/units/land/tank/tank.json (mirrored) or tank/tank.json (flat)"`
+	Source    string `json:"source" jsonschema:"required,description=Source that provided this file such as pa, pa_ex1, or com.pa.legion-expansion"`
+	Generated bool   `json:"generated,omitempty" jsonschema:"description=True if the file was generated during export rather than copied from a source (placeholder icons for units without buildbar art)"`
 }
@@ -0,0 +1,25 @@
+package models
+
+// UnitHistory is one unit's stats across the exported versions of a faction, oldest version
+// first, written by the history command to draw balance history graphs.
+type UnitHistory struct {
+	Faction     string           `json:"faction" jsonschema:"required,description=Faction identifier from metadata.json"`
+	Unit        string           `json:"unit" jsonschema:"required,description=Unit identifier as in units.json"`
+	DisplayName string           `json:"displayName" jsonschema:"required,description=Unit display name in the newest version that has the unit"`
+	Versions    []HistoryVersion `json:"versions" jsonschema:"required,description=Faction versions compared with the oldest first"`
+	Stats       []StatHistory    `json:"stats" jsonschema:"required,description=Numeric unit stats with one value per entry of versions"`
+}
+
+// HistoryVersion is one faction version of a unit history
+type HistoryVersion struct {
+	Version string `json:"version" jsonschema:"required,description=Faction data version from metadata.json"`
+	PABuild string `json:"paBuild,omitempty" jsonschema:"description=Build number of the PA installation the version was extracted from"`
+	Present bool   `json:"present" jsonschema:"required,description=True if the version has the unit"`
+}
+
+// StatHistory is the value of one stat in each version
+type StatHistory struct {
+	Stat    string     `json:"stat" jsonschema:"required,description=Dotted unit field such as specs.combat.health or specs.combat.weapons.0.dps"`
+	Values  []*float64 `json:"values" jsonschema:"required,description=Value per version in versions order; null where the unit or the stat is absent"`
+	Changed bool       `json:"changed,omitempty" jsonschema:"description=True if the value differs between any two versions"`
+}
@@ -0,0 +1,25 @@
+package models
+
+// Lockfile is pa-pedia.lock, written by describe-faction to record the inputs each faction
+// was extracted from. Committed next to a team's profiles, it lets --frozen refuse to
+// extract when a mod, the PA build, the CLI or the profile differs from the locked run.
+type Lockfile struct {
+	Factions map[string]LockedFaction `json:"factions" jsonschema:"required,description=Locked inputs by profile ID"`
+}
+
+// LockedFaction is the inputs of one faction's last extraction
+type LockedFaction struct {
+	CLIVersion    string      `json:"cliVersion" jsonschema:"required,description=pa-pedia version that ran the extraction"`
+	PABuild       string      `json:"paBuild,omitempty" jsonschema:"description=Build number of the PA installation read from version.txt"`
+	ProfileSHA256 string      `json:"profileSha256" jsonschema:"required,description=Hex-encoded SHA-256 of the resolved profile (after --version and other flag overrides)"`
+	Mods          []LockedMod `json:"mods,omitempty" jsonschema:"description=Resolved mods in priority order"`
+}
+
+// LockedMod is one resolved mod of a locked extraction
+type LockedMod struct {
+	Identifier string `json:"identifier" jsonschema:"required,description=Mod identifier from modinfo.json"`
+	Version    string `json:"version,omitempty" jsonschema:"description=Mod version from modinfo.json"`
+	SHA256     string `json:"sha256" jsonschema:"required,description=Hex-encoded content hash of the mod's files (see loader.ModInfo.ContentHash)"`
+	GitHubURL  string `json:"githubUrl,omitempty" jsonschema:"description=GitHub mod source as listed in the profile (GitHub mods only)"`
+	Commit     string `json:"commit,omitempty" jsonschema:"description=Commit the GitHub mod was downloaded at; --pin-github-refs downloads it again instead of the branch's latest"`
+}
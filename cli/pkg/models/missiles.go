@@ -0,0 +1,34 @@
+package models
+
+// MissileReport is missiles.json: the faction's missile launchers and interceptors, what each
+// missile costs to build and which interceptors shoot which missiles down
+type MissileReport struct {
+	Launchers []MissileLauncher `json:"launchers" jsonschema:"required,description=Missile launchers and interceptors sorted by unit ID then weapon"`
+}
+
+// MissileLauncher is one missile-firing weapon of a structure or unit
+type MissileLauncher struct {
+	Unit        string `json:"unit" jsonschema:"required,description=Unit ID of the launcher"`
+	DisplayName string `json:"displayName" jsonschema:"required,description=Display name of the launcher"`
+	Weapon      string `json:"weapon" jsonschema:"required,description=Safe name of the missile weapon"`
+	Role        string `json:"role" jsonschema:"required,enum=missile,enum=interceptor,description=missile for weapons fired on command at ground targets and interceptor for weapons shooting down other missiles"`
+	Missile     string `json:"missile,omitempty" jsonschema:"description=PA resource path of the missile the weapon fires"`
+
+	// Missile economy (missiles built by the launcher's own build arms)
+	MetalCost  float64 `json:"metalCost,omitempty" jsonschema:"description=Metal to build one missile (its build_metal_cost)"`
+	EnergyCost float64 `json:"energyCost,omitempty" jsonschema:"description=Energy to build one missile: buildTime x energyRate"`
+	BuildPower float64 `json:"buildPower,omitempty" jsonschema:"description=Metal per second the launcher's own build arms spend building missiles"`
+	EnergyRate float64 `json:"energyRate,omitempty" jsonschema:"description=Energy per second the launcher's own build arms use while building"`
+	BuildTime  float64 `json:"buildTime,omitempty" jsonschema:"description=Seconds to build one missile at buildPower (metalCost / buildPower; scale by buildPower / your build power when assisted)"`
+	Storage    int     `json:"storage,omitempty" jsonschema:"description=Built missiles the launcher holds ready to fire"`
+
+	// Interception
+	Intercepts    []string `json:"intercepts,omitempty" jsonschema:"description=PA resource paths of the missiles an interceptor shoots down (anti_entity_targets; may name other factions' missiles)"`
+	InterceptedBy []string `json:"interceptedBy,omitempty" jsonschema:"description=Unit IDs of this faction's interceptors that shoot down this launcher's missile"`
+}
+
+// Missile launcher roles
+const (
+	MissileRoleMissile     = "missile"
+	MissileRoleInterceptor = "interceptor"
+)
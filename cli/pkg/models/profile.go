@@ -8,6 +8,9 @@ type FactionProfile struct {
 	// Not stored in JSON - computed at load time.
 	ID string `json:"-"`
 
+	// Path is the file a local profile was loaded from; empty for built-in profiles.
+	Path string `json:"-"`
+
 	// DisplayName is the human-readable faction name shown in output.
 	DisplayName string `json:"displayName" jsonschema:"required,description=Human-readable faction name (e.g. 'MLA' or 'Legion')"`
 
@@ -25,6 +28,20 @@ type FactionProfile struct {
 	// Order determines priority (first = highest). Empty for base game only factions.
 	Mods []string `json:"mods,omitempty" jsonschema:"description=Mod identifiers that layer on base game in priority order (empty for base game only)"`
 
+	// Includes makes this a composite profile: the listed profile IDs are expanded
+	// into one combined faction. Order is priority (first = highest); the composite's
+	// own Mods sit above every included profile. Unit conflicts between included
+	// mods are resolved first-wins and reported during extraction.
+	Includes []string `json:"includes,omitempty" jsonschema:"description=Profile IDs combined into this composite faction in priority order (first wins)"`
+
+	// Commanders pins the unit IDs that seed accessibility analysis. When empty, every
+	// unit tagged Commander is used. Useful for mods that ship test or dev commanders.
+	Commanders []string `json:"commanders,omitempty" jsonschema:"description=Unit IDs that seed accessibility analysis (default: every unit tagged Commander)"`
+
+	// ExcludeCommanders removes commanders from the default seed set. Ignored when
+	// Commanders is set.
+	ExcludeCommanders []string `json:"excludeCommanders,omitempty" jsonschema:"description=Commander unit IDs excluded from the default accessibility seed set"`
+
 	// Author credit for the faction/profile.
 	// For modded factions, auto-detected from primary mod's modinfo.json if not specified.
 	Author string `json:"author,omitempty" jsonschema:"description=Faction or profile author (auto-detected from primary mod if not specified)"`
@@ -54,4 +71,27 @@ type FactionProfile struct {
 	// TeamColors is the faction's default team-paint colour pair (primary/secondary
 	// hex). Copied into FactionMetadata to seed the 3D model viewer's colour picker.
 	TeamColors *TeamColors `json:"teamColors,omitempty" jsonschema:"description=Default faction team-paint colour pair (primary/secondary hex) for the 3D model viewer"`
+
+	// Icons maps unit IDs to icon resource paths within the sources, for mods whose
+	// icons don't follow PA's <id>_icon_buildbar.png layout (e.g. "tank":
+	// "/ui/mods/my_mod/img/tank.png"). Mapped icons take priority over discovered ones;
+	// `pa-pedia missing-icons` suggests mappings.
+	Icons map[string]string `json:"icons,omitempty" jsonschema:"description=Unit ID to icon resource path within mod sources used instead of the discovered buildbar icon (e.g. /ui/mods/my_mod/img/tank.png)"`
+
+	// IconOverrides is a local folder of replacement icons named <unit id>.png or
+	// <unit id>_icon_buildbar.png, so maintainers can supply better art without
+	// changing the mod. They take priority over Icons and discovered icons. A relative
+	// path is resolved against the profile's folder (the working directory for
+	// built-in profiles).
+	IconOverrides string `json:"iconOverrides,omitempty" jsonschema:"description=Folder of replacement icons named <unit id>.png or <unit id>_icon_buildbar.png that take priority over discovered icons (relative to the profile file)"`
+
+	// OptOutMods lists mods whose authors asked not to have their content mirrored, for
+	// mods that can't carry "pa_pedia_export": false in their own modinfo.json. Extraction
+	// refuses them unless each is named with --override-opt-out.
+	OptOutMods []string `json:"optOutMods,omitempty" jsonschema:"description=Mod identifiers whose authors opted out of exports; extraction refuses them unless overridden with --override-opt-out"`
+
+	// ConflictPreferences picks the mod whose copy of a unit is used when several selected
+	// mods define it (unit JSON resource path -> mod identifier), instead of first-wins.
+	// describe-faction --conflicts records these decisions here.
+	ConflictPreferences map[string]string `json:"conflictPreferences,omitempty" jsonschema:"description=Unit JSON resource path to the mod identifier whose copy is used when several mods define the unit (overrides first-wins)"`
 }
@@ -0,0 +1,11 @@
+package models
+
+// SchemaBaseURL is where the generated JSON Schemas are published: the web app deploy
+// serves the repository's schema/ directory under /schema/.
+const SchemaBaseURL = "https://pa-pedia.com/schema/"
+
+// SchemaURL returns the published URL of a generated schema by name (e.g. "unit"), for
+// the $schema reference written into files so editors validate and complete them
+func SchemaURL(name string) string {
+	return SchemaBaseURL + name + ".schema.json"
+}
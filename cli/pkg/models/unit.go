@@ -20,16 +20,36 @@ type Unit struct {
 	UnitTypes       []string `json:"unitTypes,omitempty" jsonschema:"description=Unit type tags (e.g. ['Mobile' 'Tank' 'Land' 'Basic'])"`
 	Accessible      bool     `json:"accessible" jsonschema:"required,description=Whether unit is buildable from commander (excludes test/tutorial units)"`
 	BaseTemplate    bool     `json:"baseTemplate,omitempty" jsonschema:"description=Whether this is a base template file (not a real unit)"`
+	Reachability    *Reachability `json:"reachability,omitempty" jsonschema:"description=How this unit is reached from a commander (omitted for inaccessible units)"`
+	TechPath        *TechPath     `json:"techPath,omitempty" jsonschema:"description=Shortest chain from a commander to this unit (omitted for inaccessible units)"`
 
 	// Specifications (organized into logical groups)
 	Specs UnitSpecs `json:"specs" jsonschema:"required,description=Detailed unit specifications organized by category"`
 
 	// Build Relationships
 	BuildRelationships BuildRelationships `json:"buildRelationships,omitempty" jsonschema:"description=What this unit builds and what builds this unit"`
+	BuildMenu          []BuildMenuGroup   `json:"buildMenu,omitempty" jsonschema:"description=Builds grouped into build bar tabs and tier rows like the in-game build menu (set at export time)"`
 
 	// Build Restrictions (for factories/constructors)
 	BuildableTypes  string `json:"buildableTypes,omitempty" jsonschema:"description=Build restriction grammar (e.g. 'Mobile & Basic')"`
+	BuildRestriction *RestrictionNode `json:"buildRestriction,omitempty" jsonschema:"description=buildableTypes parsed into an expression tree using the same grammar and precedence as the extractor"`
 	AssistBuildOnly *bool  `json:"assistBuildableOnly,omitempty" jsonschema:"description=Whether unit can only assist (not start) builds"`
+
+	// Derived scores (set at export time)
+	CombatValue float64 `json:"combatValue,omitempty" jsonschema:"description=Single combat power score summable across a group (sqrt of DPS times health weighted by range speed and abilities; 0 for unarmed units)"`
+
+	// Localization keys of the delocalized strings above (from PA's !LOC markers)
+	DisplayNameKey string `json:"displayNameKey,omitempty" jsonschema:"description=Localization key of display_name: the name in !LOC(key):text or the English text PA's translation files are keyed by for !LOC:text (omitted when not localized)"`
+	DescriptionKey string `json:"descriptionKey,omitempty" jsonschema:"description=Localization key of the unit's description field in the same form as displayNameKey (the role part of description has no key here)"`
+
+	// Audio/visual flavour (names of PA effect specs and audio cues, not the files themselves)
+	Effects *UnitEffects `json:"effects,omitempty" jsonschema:"description=Effect specs and audio cues the unit plays when it dies"`
+
+	// Warnings collects non-fatal parse issues. Exported on UnitIndexEntry, not here.
+	Warnings []string `json:"-"`
+
+	// Extensions holds x- prefixed fields added by third-party tools (inlined in JSON).
+	Extensions Extensions `json:"-"`
 }
 
 // UnitSpecs organizes unit specifications into logical categories
@@ -40,6 +60,7 @@ type UnitSpecs struct {
 	Recon    *ReconSpecs    `json:"recon,omitempty" jsonschema:"description=Vision and detection specifications"`
 	Storage  *StorageSpecs  `json:"storage,omitempty" jsonschema:"description=Unit transport and storage capabilities"`
 	Special  *SpecialSpecs  `json:"special,omitempty" jsonschema:"description=Special attributes (amphibious hover spawn layers)"`
+	Size     *SizeSpecs     `json:"size,omitempty" jsonschema:"description=Physical dimensions for scale comparisons (omitted when the unit has no mesh bounds or footprint)"`
 }
 
 // CombatSpecs contains combat-related specifications
@@ -64,6 +85,15 @@ type EconomySpecs struct {
 	EnergyRate        float64   `json:"energyRate,omitempty" jsonschema:"description=Net energy production/consumption per second"`
 	BuildArms         []BuildArm `json:"buildArms,omitempty" jsonschema:"description=Construction tools"`
 	BuildRange        float64   `json:"buildRange,omitempty" jsonschema:"description=Maximum construction range"`
+	Assist            []AssistEconomy `json:"assist,omitempty" jsonschema:"description=Combined spending while this mobile builder assists each factory in the export (set at export time)"`
+}
+
+// AssistEconomy is a builder and a factory building together: both units' build arms
+type AssistEconomy struct {
+	Factory           string  `json:"factory" jsonschema:"required,description=Unit ID of the assisted factory"`
+	Metal             float64 `json:"metal" jsonschema:"required,description=Combined metal spent per second by the factory and the builder"`
+	Energy            float64 `json:"energy" jsonschema:"required,description=Combined energy spent per second by the factory and the builder"`
+	BuildInefficiency float64 `json:"buildInefficiency" jsonschema:"required,description=Combined energy per metal (compare with the builder's own buildInefficiency when building directly)"`
 }
 
 // MobilitySpecs contains movement specifications
@@ -87,8 +117,12 @@ type ReconSpecs struct {
 
 // StorageSpecs contains unit storage/transport specifications
 type StorageSpecs struct {
-	UnitStorage    int    `json:"unitStorage,omitempty" jsonschema:"description=Number of units that can be stored"`
-	StoredUnitType string `json:"storedUnitType,omitempty" jsonschema:"description=Type restriction for stored units"`
+	UnitStorage      int    `json:"unitStorage,omitempty" jsonschema:"description=Number of units that can be stored"`
+	StoredUnitType   string `json:"storedUnitType,omitempty" jsonschema:"description=Type restriction for stored units"`
+	InitialBuildSpec string `json:"initialBuildSpec,omitempty" jsonschema:"description=PA resource path the factory starts building when it spawns (factory.initial_build_spec)"`
+	InitialBuildCost float64 `json:"initialBuildCost,omitempty" jsonschema:"description=Metal cost of the initial build spec (its build_metal_cost)"`
+	SpawnInterval    float64 `json:"spawnInterval,omitempty" jsonschema:"description=Seconds between units the structure produces on its own (factory_cooldown_time or factory.spawn_interval)"`
+	SpawnMetalRate   float64 `json:"spawnMetalRate,omitempty" jsonschema:"description=Metal per second to keep producing: initialBuildCost divided by spawnInterval"`
 }
 
 // SpecialSpecs contains special attributes
@@ -97,10 +131,105 @@ type SpecialSpecs struct {
 	Amphibious       bool     `json:"amphibious,omitempty" jsonschema:"description=Can traverse both land and water"`
 	Hover            bool     `json:"hover,omitempty" jsonschema:"description=Hovers above ground"`
 	SpawnUnitOnDeath string   `json:"spawnUnitOnDeath,omitempty" jsonschema:"description=PA resource path of unit spawned when this unit dies"`
+
+	// Mod extension fields (PA ignores them; the mod's scripts implement them)
+	HealthRegen         float64 `json:"healthRegen,omitempty" jsonschema:"description=Health regenerated per second (mod extension field such as health_regen)"`
+	ShieldHealth        float64 `json:"shieldHealth,omitempty" jsonschema:"description=Shield hit points absorbed before health (mod extension field such as shield.max_health)"`
+	ShieldRecharge      float64 `json:"shieldRecharge,omitempty" jsonschema:"description=Shield hit points recharged per second (mod extension field such as shield.recharge_rate)"`
+	ShieldRechargeDelay float64 `json:"shieldRechargeDelay,omitempty" jsonschema:"description=Seconds after taking damage before the shield recharges (mod extension field such as shield.recharge_delay)"`
+}
+
+// SizeSpecs contains physical dimensions in world units
+type SizeSpecs struct {
+	Width             float64 `json:"width,omitempty" jsonschema:"description=Mesh bounds along x (mesh_bounds[0])"`
+	Length            float64 `json:"length,omitempty" jsonschema:"description=Mesh bounds along y (mesh_bounds[1])"`
+	Height            float64 `json:"height,omitempty" jsonschema:"description=Mesh bounds along z (mesh_bounds[2])"`
+	FootprintWidth    float64 `json:"footprintWidth,omitempty" jsonschema:"description=Structure placement footprint along x (placement_size[0])"`
+	FootprintLength   float64 `json:"footprintLength,omitempty" jsonschema:"description=Structure placement footprint along y (placement_size[1])"`
+	SelectionDiameter float64 `json:"selectionDiameter,omitempty" jsonschema:"description=Diameter of the strategic icon and selection circle (selection_icon.diameter)"`
+	SizeClass         string  `json:"sizeClass" jsonschema:"required,enum=small,enum=medium,enum=large,enum=titan,description=Size class from the larger ground dimension (titan for Titan units)"`
+}
+
+// Reachability explains why a unit is accessible: the relationship that first
+// reached it during the walk outward from the commanders.
+type Reachability struct {
+	Method string `json:"method" jsonschema:"required,enum=commander,enum=built,enum=factorySpawn,enum=spawnedOnDeath,enum=projectile,description=How the unit is reached (commander/built/factorySpawn/spawnedOnDeath/projectile)"`
+	Via    string `json:"via,omitempty" jsonschema:"description=Unit ID that builds launches or spawns this unit (omitted for commanders)"`
+}
+
+// TechPath is the shortest chain of units from a commander to this unit, following
+// the same routes as Reachability (e.g. commander → bot factory → adv bot factory → slammer).
+type TechPath struct {
+	Path        []string `json:"path" jsonschema:"required,description=Unit IDs from the starting commander to this unit inclusive"`
+	FactoryCost float64  `json:"factoryCost" jsonschema:"required,description=Total metal cost of the intermediate units on the path (excludes the commander and this unit)"`
 }
 
+// Reachability methods
+const (
+	ReachCommander      = "commander"      // Starting commander
+	ReachBuilt          = "built"          // Built by Via
+	ReachFactorySpawn   = "factorySpawn"   // Via's factory.initial_build_spec
+	ReachSpawnedOnDeath = "spawnedOnDeath" // Spawned when Via dies
+	ReachProjectile     = "projectile"     // Spawned by a projectile Via fires or launches
+)
+
 // BuildRelationships defines build tree connections
 type BuildRelationships struct {
 	Builds  []string `json:"builds,omitempty" jsonschema:"description=List of unit IDs this unit can build"`
 	BuiltBy []string `json:"builtBy,omitempty" jsonschema:"description=List of unit IDs that can build this unit"`
 }
+
+// RestrictionNode is one node of a parsed buildable_types expression. Leaf nodes
+// (Op "type") match units tagged with Type; the operators combine Left and Right.
+// Precedence is already applied: | binds loosest, then &, then - (right-associative).
+type RestrictionNode struct {
+	Op    string           `json:"op" jsonschema:"required,enum=type,enum=and,enum=or,enum=minus,description=Node kind: a unit type leaf or an operator"`
+	Type  string           `json:"type,omitempty" jsonschema:"description=Unit type without the UNITTYPE_ prefix (type nodes only; empty matches nothing)"`
+	Left  *RestrictionNode `json:"left,omitempty" jsonschema:"description=Left operand (operator nodes only)"`
+	Right *RestrictionNode `json:"right,omitempty" jsonschema:"description=Right operand (operator nodes only; for minus the types to exclude)"`
+}
+
+// Restriction node kinds
+const (
+	RestrictionType  = "type"  // Unit has the type tag
+	RestrictionAnd   = "and"   // Both operands match
+	RestrictionOr    = "or"    // Either operand matches
+	RestrictionMinus = "minus" // Left matches and right doesn't
+)
+
+// BuildMenuGroup is one tab of a builder's build menu, mirroring the in-game build bar.
+type BuildMenuGroup struct {
+	Category string          `json:"category" jsonschema:"required,enum=factory,enum=combat,enum=utility,enum=vehicle,enum=bot,enum=air,enum=sea,enum=orbital,description=Build bar tab"`
+	Tiers    []BuildMenuTier `json:"tiers" jsonschema:"required,description=Rows of the tab by ascending tier"`
+}
+
+// BuildMenuTier is one tier row within a build menu tab.
+type BuildMenuTier struct {
+	Tier  int      `json:"tier" jsonschema:"required,minimum=1,maximum=3,description=Unit tier of the row"`
+	Units []string `json:"units" jsonschema:"required,description=Unit IDs in the row sorted by display name"`
+}
+
+// Build menu categories, in build bar tab order
+const (
+	BuildMenuFactory = "factory" // Factory structures
+	BuildMenuCombat  = "combat"  // Defenses, artillery and nukes
+	BuildMenuUtility = "utility" // Economy, intel and other structures
+	BuildMenuVehicle = "vehicle"
+	BuildMenuBot     = "bot"
+	BuildMenuAir     = "air"
+	BuildMenuSea     = "sea"
+	BuildMenuOrbital = "orbital"
+)
+
+// BuildMenuCategories lists the build menu categories in build bar tab order.
+var BuildMenuCategories = []string{
+	BuildMenuFactory, BuildMenuCombat, BuildMenuUtility,
+	BuildMenuVehicle, BuildMenuBot, BuildMenuAir, BuildMenuSea, BuildMenuOrbital,
+}
+
+// UnitEffects names the effect specs and audio cues of a unit's own events (weapon firing
+// effects are on each weapon)
+type UnitEffects struct {
+	Death      []string `json:"death,omitempty" jsonschema:"description=PA resource paths of the effect specs played when the unit dies (.pfx)"`
+	DeathAudio string   `json:"deathAudio,omitempty" jsonschema:"description=Audio cue played when the unit dies"`
+}
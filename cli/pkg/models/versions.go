@@ -0,0 +1,26 @@
+package models
+
+// VersionsManifest is versions.json, kept by the daemon command in each faction's
+// directory of a versioned output folder (<output>/<id>/<version>/). It lists the
+// versions still on disk so hosts and tools don't need to scan every metadata.json.
+type VersionsManifest struct {
+	Identifier  string         `json:"identifier" jsonschema:"required,description=Faction identifier copied from metadata.json"`
+	DisplayName string         `json:"displayName" jsonschema:"required,description=Faction display name from the most recent export"`
+	Latest      string         `json:"latest" jsonschema:"required,description=Version of the most recent export"`
+	Versions    []VersionEntry `json:"versions" jsonschema:"required,description=Versions on disk with the most recently exported first"`
+}
+
+// VersionEntry is one version listed in a versions manifest
+type VersionEntry struct {
+	Version    string   `json:"version" jsonschema:"required,description=Faction data version copied from metadata.json"`
+	Path       string   `json:"path" jsonschema:"required,description=Folder of this version relative to the faction directory"`
+	PABuild    string   `json:"paBuild,omitempty" jsonschema:"description=Build number of the PA installation the version was extracted from"`
+	ExportedAt string   `json:"exportedAt" jsonschema:"required,description=RFC 3339 time the version was last exported"`
+	Pinned     bool     `json:"pinned,omitempty" jsonschema:"description=True if pruning must never remove this version"`
+	Tags       []string `json:"tags,omitempty" jsonschema:"description=Labels such as stable or tournament; tagged versions are never pruned"`
+}
+
+// Protected reports whether pruning must keep the version regardless of age
+func (v *VersionEntry) Protected() bool {
+	return v.Pinned || len(v.Tags) > 0
+}
@@ -33,6 +33,10 @@ type Weapon struct {
 	SelfDestruct   bool `json:"selfDestruct,omitempty" jsonschema:"description=Weapon triggers on unit self-destruct"`
 	DeathExplosion bool `json:"deathExplosion,omitempty" jsonschema:"description=Weapon triggers on unit death"`
 
+	// Availability
+	Enabled        *bool `json:"enabled,omitempty" jsonschema:"description=Whether the weapon is active (only present when the spec sets it; disabled weapons are excluded from unit DPS)"`
+	RequiresToggle bool  `json:"requiresToggle,omitempty" jsonschema:"description=Weapon is off by default until a unit ability switches it on (excluded from unit DPS)"`
+
 	// Ammo System
 	AmmoSource       string  `json:"ammoSource,omitempty" jsonschema:"description=Resource type used for ammo (e.g. 'energy')"`
 	AmmoDemand       float64 `json:"ammoDemand,omitempty" jsonschema:"description=Rate of ammo consumption"`
@@ -61,6 +65,70 @@ type Weapon struct {
 
 	// Buildable Ammo Options (for factory-sourced weapons with multiple ammo types)
 	BuildableAmmo []Ammo `json:"buildableAmmo,omitempty" jsonschema:"description=Available ammo types that can be built for this weapon (factory weapons only)"`
+
+	// Area Damage Profile
+	DamageFalloff []FalloffPoint `json:"damageFalloff,omitempty" jsonschema:"description=Approximate damage against distance from the impact point for splash weapons (the directly hit target first then the splash falloff)"`
+
+	// Targeting Behaviour
+	TargetPriorityTags []string `json:"targetPriorityTags,omitempty" jsonschema:"description=What the first target priority goes for: anti-fabber (builders and commanders) anti-air-first or structure-first"`
+
+	// Manual Fire
+	ManualFire *ManualFire `json:"manualFire,omitempty" jsonschema:"description=How the player fires the weapon when it isn't fired automatically (uber cannons and nukes on command; self-destruct charges)"`
+
+	// Interception
+	AntiEntityTargets []string `json:"antiEntityTargets,omitempty" jsonschema:"description=PA resource paths of the projectiles the weapon shoots down (anti_entity_targets e.g. nuke missiles for an anti-nuke)"`
+
+	// Audio/Visual Flavour
+	Effects *WeaponEffects `json:"effects,omitempty" jsonschema:"description=Effect specs and audio cues the unit plays when this weapon fires"`
+}
+
+// WeaponEffects names what a unit plays when a weapon fires, from the unit's fired and
+// fired<tool index> events. Projectile effects are on the ammo.
+type WeaponEffects struct {
+	MuzzleFlash []string `json:"muzzleFlash,omitempty" jsonschema:"description=PA resource paths of the muzzle flash effect specs (.pfx)"`
+	FireAudio   string   `json:"fireAudio,omitempty" jsonschema:"description=Audio cue played on firing (e.g. /SE/Weapons/tank/tank_fire)"`
+}
+
+// AmmoEffects names the effect specs and audio cues of a projectile
+type AmmoEffects struct {
+	Trail       string   `json:"trail,omitempty" jsonschema:"description=PA resource path of the trail effect spec (fx_trail)"`
+	Beam        string   `json:"beam,omitempty" jsonschema:"description=PA resource path of the beam effect spec (fx_beam_spec)"`
+	Collision   string   `json:"collision,omitempty" jsonschema:"description=PA resource path of the effect spec played where a beam hits (fx_collision_spec)"`
+	Impact      []string `json:"impact,omitempty" jsonschema:"description=PA resource paths of the effect specs played when the projectile dies (its impact or explosion)"`
+	ImpactAudio string   `json:"impactAudio,omitempty" jsonschema:"description=Audio cue played when the projectile dies"`
+	FlightAudio string   `json:"flightAudio,omitempty" jsonschema:"description=Audio cue looped while the projectile flies (audio_loop)"`
+}
+
+// ManualFire describes a weapon the player fires by hand and, for weapons firing built
+// missiles, what each shot costs
+type ManualFire struct {
+	Trigger           string  `json:"trigger" jsonschema:"required,enum=command,enum=self-destruct,description=What fires the weapon: a fire order (manual_fire) or the unit's self-destruct order"`
+	RequiresAmmoBuild bool    `json:"requiresAmmoBuild,omitempty" jsonschema:"description=Each shot needs a missile built first (factory-sourced ammo)"`
+	AmmoMetalCost     float64 `json:"ammoMetalCost,omitempty" jsonschema:"description=Metal to build one missile (its build_metal_cost)"`
+	AmmoEnergyCost    float64 `json:"ammoEnergyCost,omitempty" jsonschema:"description=Energy the unit's build arms use while building one missile"`
+	AmmoBuildTime     float64 `json:"ammoBuildTime,omitempty" jsonschema:"description=Seconds to build one missile with the unit's own build arms"`
+	AmmoStorage       int     `json:"ammoStorage,omitempty" jsonschema:"description=Built missiles the unit can hold ready to fire"`
+}
+
+// Manual fire triggers (ManualFire.Trigger)
+const (
+	ManualFireCommand      = "command"
+	ManualFireSelfDestruct = "self-destruct"
+)
+
+// Target priority tags (Weapon.TargetPriorityTags)
+const (
+	PriorityAntiFabber     = "anti-fabber"
+	PriorityAntiAirFirst   = "anti-air-first"
+	PriorityStructureFirst = "structure-first"
+)
+
+// FalloffPoint is one sample of a splash weapon's damage at a distance from where a shot lands
+type FalloffPoint struct {
+	Distance  float64 `json:"distance" jsonschema:"required,description=Distance from the impact point"`
+	Damage    float64 `json:"damage" jsonschema:"required,description=Damage per projectile to a unit at this distance"`
+	DPS       float64 `json:"dps" jsonschema:"required,description=Damage per second at this distance (damage x rate of fire x projectiles per fire)"`
+	DirectHit bool    `json:"directHit,omitempty" jsonschema:"description=The sample is the directly hit target which takes direct and splash damage"`
 }
 
 // Ammo represents detailed projectile specifications
@@ -83,6 +151,9 @@ type Ammo struct {
 	BurnDamage   float64 `json:"burnDamage,omitempty" jsonschema:"description=Total burn damage dealt over burn duration"`
 	BurnRadius   float64 `json:"burnRadius,omitempty" jsonschema:"description=Radius of burn damage area"`
 	BurnDuration float64 `json:"burnDuration,omitempty" jsonschema:"description=Duration of burn effect in seconds"`
+
+	// Audio/Visual Flavour
+	Effects *AmmoEffects `json:"effects,omitempty" jsonschema:"description=Effect specs and audio cues of the projectile"`
 }
 
 // BuildArm represents a construction tool
@@ -118,10 +118,10 @@ type runResult struct {
 
 type logf func(format string, args ...interface{})
 
-// modelPapaPath returns the primary model .papa resource path from a unit's raw
+// ModelPapaPath returns the primary model .papa resource path from a unit's raw
 // JSON. PA stores "model" as an array of layer variants; the first entry with a
 // .papa filename wins (defensively also accepts a single object).
-func modelPapaPath(unitJSON map[string]interface{}) (string, bool) {
+func ModelPapaPath(unitJSON map[string]interface{}) (string, bool) {
 	raw, ok := unitJSON["model"]
 	if !ok {
 		return "", false
@@ -202,7 +202,7 @@ func buildJobs(r Resolver, units []UnitRef, stageRoot, outDir string, log logf)
 			continue
 		}
 
-		modelPapa, ok := modelPapaPath(raw)
+		modelPapa, ok := ModelPapaPath(raw)
 		if !ok {
 			log("  skip %s: no .papa model reference", u.ID)
 			stats.Skipped++
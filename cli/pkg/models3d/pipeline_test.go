@@ -203,7 +203,7 @@ func TestModelPapaPath(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, ok := modelPapaPath(tt.unit)
+			got, ok := ModelPapaPath(tt.unit)
 			if ok != tt.wantOK {
 				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
 			}
@@ -0,0 +1,156 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// WebhookEnv is the environment variable read when no webhook URL is passed, so the URL
+// (which is a credential) needn't appear on the command line
+const WebhookEnv = "PA_PEDIA_DISCORD_WEBHOOK"
+
+// Discord embed limits (https://discord.com/developers/docs/resources/message#embed-object-embed-limits)
+const (
+	maxTitle       = 256
+	maxDescription = 4096
+)
+
+// defaultMaxItems is the number of units listed per section before "…and N more"
+const defaultMaxItems = 25
+
+// Attachment is a file sent along with a message, e.g. the changelog Markdown
+type Attachment struct {
+	Name string
+	Data []byte
+}
+
+// Discord posts summaries to a Discord channel webhook
+type Discord struct {
+	WebhookURL string
+	Username   string // Overrides the webhook's default name when set
+
+	client *http.Client
+}
+
+// NewDiscord creates a notifier for a webhook URL
+// (https://discord.com/api/webhooks/<id>/<token>).
+func NewDiscord(webhookURL string) (*Discord, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("invalid webhook URL: expected https://discord.com/api/webhooks/<id>/<token>")
+	}
+	return &Discord{
+		WebhookURL: webhookURL,
+		client:     &http.Client{Timeout: time.Minute},
+	}, nil
+}
+
+// discordEmbed and discordMessage are the parts of Discord's execute-webhook payload we use
+type discordEmbed struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+type discordMessage struct {
+	Username string         `json:"username,omitempty"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+// Message builds the webhook payload for a summary, shortening the unit lists until the
+// description fits in an embed.
+func (d *Discord) Message(s Summary) ([]byte, error) {
+	description := ""
+	for items := defaultMaxItems; items >= 1; items-- {
+		description = strings.Join(s.Lines(items), "\n")
+		if len(description) <= maxDescription {
+			break
+		}
+	}
+	description = truncate(description, maxDescription)
+
+	return json.Marshal(discordMessage{
+		Username: d.Username,
+		Embeds:   []discordEmbed{{Title: truncate(s.Title(), maxTitle), Description: description}},
+	})
+}
+
+// Send posts a summary, with an optional attachment (nil for none)
+func (d *Discord) Send(s Summary, attachment *Attachment) error {
+	payload, err := d.Message(s)
+	if err != nil {
+		return err
+	}
+
+	body := &bytes.Buffer{}
+	contentType := "application/json"
+	if attachment != nil {
+		mw := multipart.NewWriter(body)
+		if err := mw.WriteField("payload_json", string(payload)); err != nil {
+			return err
+		}
+		part, err := mw.CreateFormFile("files[0]", attachment.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := part.Write(attachment.Data); err != nil {
+			return err
+		}
+		if err := mw.Close(); err != nil {
+			return err
+		}
+		contentType = mw.FormDataContentType()
+	} else {
+		body.Write(payload)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, d.WebhookURL, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	client := d.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// The URL embeds the webhook token, so don't echo it
+		return fmt.Errorf("failed to reach the Discord webhook: %w", redact(err, d.WebhookURL))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("discord webhook returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// redact removes the webhook URL from an error message
+func redact(err error, webhookURL string) error {
+	return fmt.Errorf("%s", strings.ReplaceAll(err.Error(), webhookURL, "<webhook>"))
+}
+
+// truncate shortens s to at most n bytes on a rune boundary, marking the cut with "…"
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	const ellipsis = "…"
+	cut := n - len(ellipsis)
+	for cut > 0 && !isRuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + ellipsis
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,167 @@
+// Package notify announces faction exports to community chat: a summary of what changed
+// since a previous export (new, removed and rebalanced units) posted to a Discord webhook.
+package notify
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/stats"
+)
+
+// UnitRef names a unit in a summary
+type UnitRef struct {
+	ID          string
+	DisplayName string
+}
+
+// StatChange is one stats.Metrics value that differs from the previous export
+type StatChange struct {
+	Metric string
+	Unit   string
+	Before float64
+	After  float64
+}
+
+// UnitChange lists the changed stats of a unit present in both exports
+type UnitChange struct {
+	UnitRef
+	Stats []StatChange
+}
+
+// Summary is what gets announced for an export
+type Summary struct {
+	Faction  string
+	Version  string
+	PABuild  string
+	Units    int
+	Diffed   bool // Whether a previous export was compared; Added/Removed/Changed are empty otherwise
+	Previous string
+	Added    []UnitRef
+	Removed  []UnitRef
+	Changed  []UnitChange
+}
+
+// Summarize builds the announcement for an export. units and previous are the accessible
+// units (stats.Units) of the new and previous export; previous is nil when there is
+// nothing to compare against. Lists are sorted by unit ID.
+func Summarize(metadata *models.FactionMetadata, units []*models.Unit, previous *models.FactionMetadata, previousUnits []*models.Unit) Summary {
+	summary := Summary{Units: len(units)}
+	if metadata != nil {
+		summary.Faction = metadata.DisplayName
+		summary.Version = metadata.Version
+		summary.PABuild = metadata.PABuild
+	}
+	if previousUnits == nil {
+		return summary
+	}
+	summary.Diffed = true
+	if previous != nil {
+		summary.Previous = previous.Version
+	}
+
+	before := make(map[string]*models.Unit, len(previousUnits))
+	for _, u := range previousUnits {
+		before[u.ID] = u
+	}
+	seen := make(map[string]bool, len(units))
+	for _, u := range units {
+		seen[u.ID] = true
+		old, ok := before[u.ID]
+		if !ok {
+			summary.Added = append(summary.Added, UnitRef{u.ID, u.DisplayName})
+			continue
+		}
+		var changes []StatChange
+		for _, metric := range stats.Metrics {
+			b, a := metric.Value(old), metric.Value(u)
+			if math.Abs(a-b) > 1e-9 {
+				changes = append(changes, StatChange{Metric: metric.Name, Unit: metric.Unit, Before: b, After: a})
+			}
+		}
+		if len(changes) > 0 {
+			summary.Changed = append(summary.Changed, UnitChange{UnitRef{u.ID, u.DisplayName}, changes})
+		}
+	}
+	for _, u := range previousUnits {
+		if !seen[u.ID] {
+			summary.Removed = append(summary.Removed, UnitRef{u.ID, u.DisplayName})
+		}
+	}
+
+	sort.Slice(summary.Added, func(i, j int) bool { return summary.Added[i].ID < summary.Added[j].ID })
+	sort.Slice(summary.Removed, func(i, j int) bool { return summary.Removed[i].ID < summary.Removed[j].ID })
+	sort.Slice(summary.Changed, func(i, j int) bool { return summary.Changed[i].ID < summary.Changed[j].ID })
+	return summary
+}
+
+// Title is the one-line headline of a summary, e.g. "MLA 1.2.0 exported (PA build 123456)"
+func (s Summary) Title() string {
+	title := strings.TrimSpace(s.Faction + " " + s.Version)
+	if s.Previous != "" && s.Previous != s.Version {
+		title += " (was " + s.Previous + ")"
+	}
+	title += " exported"
+	if s.PABuild != "" {
+		title += " for PA build " + s.PABuild
+	}
+	return title
+}
+
+// Lines renders the body of a summary as Markdown lines, with at most maxItems units per
+// section (0 for no limit); the rest are counted in an "…and N more" line.
+func (s Summary) Lines(maxItems int) []string {
+	lines := []string{fmt.Sprintf("%d units", s.Units)}
+	if !s.Diffed {
+		return lines
+	}
+	if len(s.Added) == 0 && len(s.Removed) == 0 && len(s.Changed) == 0 {
+		return append(lines, "No unit changes since the previous export.")
+	}
+
+	section := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		lines = append(lines, "", fmt.Sprintf("**%s (%d)**", title, len(items)))
+		shown := items
+		if maxItems > 0 && len(items) > maxItems {
+			shown = items[:maxItems]
+		}
+		for _, item := range shown {
+			lines = append(lines, "- "+item)
+		}
+		if len(shown) < len(items) {
+			lines = append(lines, fmt.Sprintf("- …and %d more", len(items)-len(shown)))
+		}
+	}
+
+	var added, removed, changed []string
+	for _, u := range s.Added {
+		added = append(added, u.label())
+	}
+	for _, u := range s.Removed {
+		removed = append(removed, u.label())
+	}
+	for _, c := range s.Changed {
+		var parts []string
+		for _, st := range c.Stats {
+			parts = append(parts, fmt.Sprintf("%s %s → %s", st.Metric, stats.FormatStat(st.Before), stats.FormatStat(st.After)))
+		}
+		changed = append(changed, c.label()+": "+strings.Join(parts, ", "))
+	}
+	section("New units", added)
+	section("Removed units", removed)
+	section("Changed stats", changed)
+	return lines
+}
+
+func (u UnitRef) label() string {
+	if u.DisplayName == "" || u.DisplayName == u.ID {
+		return "`" + u.ID + "`"
+	}
+	return fmt.Sprintf("%s (`%s`)", u.DisplayName, u.ID)
+}
@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func testUnit(id, name string, health, dps float64) *models.Unit {
+	unit := &models.Unit{ID: id, DisplayName: name}
+	unit.Specs.Combat = &models.CombatSpecs{Health: health, DPS: dps}
+	return unit
+}
+
+func TestSummarize(t *testing.T) {
+	metadata := &models.FactionMetadata{DisplayName: "MLA", Version: "1.1.0", PABuild: "124664"}
+	previous := &models.FactionMetadata{DisplayName: "MLA", Version: "1.0.0"}
+	before := []*models.Unit{testUnit("tank", "Ant", 200, 40), testUnit("bot", "Dox", 100, 20), testUnit("old", "Old", 10, 0)}
+	after := []*models.Unit{testUnit("tank", "Ant", 250, 40), testUnit("bot", "Dox", 100, 20), testUnit("new", "new", 10, 0)}
+
+	s := Summarize(metadata, after, previous, before)
+	if !reflect.DeepEqual(s.Added, []UnitRef{{"new", "new"}}) || !reflect.DeepEqual(s.Removed, []UnitRef{{"old", "Old"}}) {
+		t.Errorf("Added = %v, Removed = %v", s.Added, s.Removed)
+	}
+	wantChanged := []UnitChange{{UnitRef{"tank", "Ant"}, []StatChange{{Metric: "health", Unit: "hp", Before: 200, After: 250}}}}
+	if !reflect.DeepEqual(s.Changed, wantChanged) {
+		t.Errorf("Changed = %+v, want %+v", s.Changed, wantChanged)
+	}
+	if got, want := s.Title(), "MLA 1.1.0 (was 1.0.0) exported for PA build 124664"; got != want {
+		t.Errorf("Title() = %q, want %q", got, want)
+	}
+
+	wantLines := []string{
+		"3 units",
+		"", "**New units (1)**", "- `new`",
+		"", "**Removed units (1)**", "- Old (`old`)",
+		"", "**Changed stats (1)**", "- Ant (`tank`): health 200 → 250",
+	}
+	if got := s.Lines(0); !reflect.DeepEqual(got, wantLines) {
+		t.Errorf("Lines() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(wantLines, "\n"))
+	}
+
+	if got := Summarize(metadata, after, nil, nil).Lines(0); !reflect.DeepEqual(got, []string{"3 units"}) {
+		t.Errorf("Lines() without previous = %v", got)
+	}
+	if got := Summarize(metadata, after, metadata, after).Lines(0); len(got) != 2 || !strings.HasPrefix(got[1], "No unit changes") {
+		t.Errorf("Lines() unchanged = %v", got)
+	}
+}
+
+func TestLinesLimit(t *testing.T) {
+	var units []*models.Unit
+	for _, id := range []string{"a", "b", "c"} {
+		units = append(units, testUnit(id, id, 1, 0))
+	}
+	lines := Summarize(nil, units, nil, []*models.Unit{}).Lines(2)
+	if got := lines[len(lines)-1]; got != "- …and 1 more" {
+		t.Errorf("last line = %q, want the overflow count", got)
+	}
+}
+
+func TestDiscordSend(t *testing.T) {
+	var got struct {
+		contentType string
+		payload     discordMessage
+		file        string
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.contentType = r.Header.Get("Content-Type")
+		if strings.HasPrefix(got.contentType, "multipart/") {
+			json.Unmarshal([]byte(r.FormValue("payload_json")), &got.payload)
+			if f, _, err := r.FormFile("files[0]"); err == nil {
+				data, _ := io.ReadAll(f)
+				got.file = string(data)
+			}
+		} else {
+			json.NewDecoder(r.Body).Decode(&got.payload)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	d, err := NewDiscord(server.URL + "/api/webhooks/1/token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Username = "PA-Pedia"
+	summary := Summarize(&models.FactionMetadata{DisplayName: "MLA", Version: "1.0.0"}, []*models.Unit{testUnit("tank", "Ant", 1, 1)}, nil, nil)
+
+	if err := d.Send(summary, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got.contentType != "application/json" || got.payload.Username != "PA-Pedia" || got.payload.Embeds[0].Title != "MLA 1.0.0 exported" {
+		t.Errorf("Send() posted %s %+v", got.contentType, got.payload)
+	}
+
+	if err := d.Send(summary, &Attachment{Name: "CHANGELOG.md", Data: []byte("# 1.0.0")}); err != nil {
+		t.Fatal(err)
+	}
+	if got.file != "# 1.0.0" || got.payload.Embeds[0].Description != "1 units" {
+		t.Errorf("Send() with attachment posted file %q and %+v", got.file, got.payload)
+	}
+}
+
+func TestDiscordSendError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"message": "Unknown Webhook"}`)
+	}))
+	defer server.Close()
+
+	d, _ := NewDiscord(server.URL)
+	err := d.Send(Summary{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "Unknown Webhook") {
+		t.Errorf("Send() error = %v, want the Discord message", err)
+	}
+
+	if _, err := NewDiscord("discord.com/api/webhooks/1/token"); err == nil {
+		t.Error("NewDiscord() accepted a URL without scheme")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("héllo", 5); got != "h…" {
+		t.Errorf("truncate() = %q, want %q", got, "h…")
+	}
+	if got := truncate("hello", 5); got != "hello" {
+		t.Errorf("truncate() = %q", got)
+	}
+}
@@ -0,0 +1,284 @@
+// Package papa reads the geometry of Planetary Annihilation .papa model files and renders
+// flat-shaded silhouettes from it.
+//
+// Only what a silhouette needs is decoded: vertex positions, triangle indices, and the
+// mesh-to-model and model-to-scene transforms. Textures, materials, skeletons and
+// animations are skipped, so skinned meshes come out in their bind pose. The layout
+// follows the vendored Blender-PAPA-IO addon (pkg/models3d/blender-papa-io/papafile.py).
+package papa
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Signature is the magic number at the start of every .papa file ("Papa" little-endian)
+const Signature = 0x50617061
+
+const (
+	headerSize        = 104
+	vertexHeaderSize  = 24
+	indexHeaderSize   = 24
+	meshHeaderSize    = 16
+	groupSize         = 16
+	modelHeaderSize   = 80
+	meshBindingSize   = 80
+	primitiveTriangle = 2
+
+	// maxTriangles bounds a decoded mesh; unit models have tens of thousands at most, so
+	// more means a corrupt file binding the same mesh over and over
+	maxTriangles = 2_000_000
+)
+
+// Vec3 is a point in model space (PA is z-up)
+type Vec3 [3]float64
+
+// Triangle is three corners in scene space
+type Triangle [3]Vec3
+
+// Mesh is the triangle soup of every model in a .papa file
+type Mesh struct {
+	Triangles []Triangle
+}
+
+// matrix is a 4x4 transform, m[row][col]
+type matrix [4][4]float64
+
+var identity = matrix{{1, 0, 0, 0}, {0, 1, 0, 0}, {0, 0, 1, 0}, {0, 0, 0, 1}}
+
+func (m matrix) mul(o matrix) matrix {
+	var r matrix
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			for k := 0; k < 4; k++ {
+				r[i][j] += m[i][k] * o[k][j]
+			}
+		}
+	}
+	return r
+}
+
+func (m matrix) apply(p Vec3) Vec3 {
+	var r Vec3
+	for i := 0; i < 3; i++ {
+		r[i] = m[i][0]*p[0] + m[i][1]*p[1] + m[i][2]*p[2] + m[i][3]
+	}
+	return r
+}
+
+// reader reads little-endian values at absolute offsets, reporting the first
+// out-of-range read instead of panicking on truncated files
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) bytes(off int64, n int) []byte {
+	if r.err != nil {
+		return make([]byte, n)
+	}
+	if off < 0 || n < 0 || off > int64(len(r.data)) || int64(n) > int64(len(r.data))-off {
+		r.err = fmt.Errorf("truncated file: %d bytes at offset %d", n, off)
+		return make([]byte, n)
+	}
+	return r.data[off : off+int64(n)]
+}
+
+func (r *reader) u8(off int64) uint8   { return r.bytes(off, 1)[0] }
+func (r *reader) u16(off int64) uint16 { return binary.LittleEndian.Uint16(r.bytes(off, 2)) }
+func (r *reader) u32(off int64) uint32 { return binary.LittleEndian.Uint32(r.bytes(off, 4)) }
+func (r *reader) i64(off int64) int64  { return int64(binary.LittleEndian.Uint64(r.bytes(off, 8))) }
+func (r *reader) f32(off int64) float64 {
+	return float64(math.Float32frombits(r.u32(off)))
+}
+
+// matrix reads 16 floats stored column by column
+func (r *reader) matrix(off int64) matrix {
+	var m matrix
+	for col := 0; col < 4; col++ {
+		for row := 0; row < 4; row++ {
+			m[row][col] = r.f32(off + int64(col*4+row)*4)
+		}
+	}
+	return m
+}
+
+type meshRef struct {
+	vertexBuffer, indexBuffer int
+	groups                    [][2]int // First index and triangle count of each triangle group
+}
+
+// Decode reads the triangles of every model in a .papa file, in scene space. Files with
+// meshes but no models (some effects and props) are read with identity transforms.
+func Decode(data []byte) (*Mesh, error) {
+	r := &reader{data: data}
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("not a papa file: %d bytes", len(data))
+	}
+	if r.u32(0) != Signature {
+		return nil, fmt.Errorf("not a papa file: bad signature")
+	}
+	numVertexBuffers := int(r.u16(10))
+	numIndexBuffers := int(r.u16(12))
+	numMeshes := int(r.u16(16))
+	numModels := int(r.u16(20))
+	offVertices := r.i64(48)
+	offIndices := r.i64(56)
+	offMeshes := r.i64(72)
+	offModels := r.i64(88)
+
+	vertexBuffers := make([][]Vec3, numVertexBuffers)
+	for i := range vertexBuffers {
+		h := offVertices + int64(i*vertexHeaderSize)
+		count := int64(r.u32(h + 4))
+		size := r.i64(h + 8)
+		off := r.i64(h + 16)
+		if count == 0 {
+			continue
+		}
+		// Every vertex format starts with the position; the stride covers the rest
+		stride := size / count
+		if stride < 12 {
+			return nil, fmt.Errorf("vertex buffer %d: %d-byte vertices", i, stride)
+		}
+		if int64(len(data)) < size {
+			return nil, fmt.Errorf("vertex buffer %d: truncated", i)
+		}
+		verts := make([]Vec3, count)
+		for v := range verts {
+			p := off + int64(v)*stride
+			verts[v] = Vec3{r.f32(p), r.f32(p + 4), r.f32(p + 8)}
+		}
+		vertexBuffers[i] = verts
+	}
+
+	indexBuffers := make([][]uint32, numIndexBuffers)
+	for i := range indexBuffers {
+		h := offIndices + int64(i*indexHeaderSize)
+		format := r.u8(h)
+		count := int64(r.u32(h + 4))
+		off := r.i64(h + 16)
+		width := int64(2)
+		if format == 1 {
+			width = 4
+		} else if format != 0 {
+			return nil, fmt.Errorf("index buffer %d: unknown format %d", i, format)
+		}
+		if int64(len(data)) < count*width {
+			return nil, fmt.Errorf("index buffer %d: truncated", i)
+		}
+		indices := make([]uint32, count)
+		for j := range indices {
+			if width == 2 {
+				indices[j] = uint32(r.u16(off + int64(j)*2))
+			} else {
+				indices[j] = r.u32(off + int64(j)*4)
+			}
+		}
+		indexBuffers[i] = indices
+	}
+
+	meshes := make([]meshRef, numMeshes)
+	for i := range meshes {
+		h := offMeshes + int64(i*meshHeaderSize)
+		m := meshRef{vertexBuffer: int(r.u16(h)), indexBuffer: int(r.u16(h + 2))}
+		numGroups := int(r.u16(h + 4))
+		off := r.i64(h + 8)
+		for g := 0; g < numGroups; g++ {
+			gh := off + int64(g*groupSize)
+			if r.u8(gh+12) != primitiveTriangle {
+				continue
+			}
+			m.groups = append(m.groups, [2]int{int(r.u32(gh + 4)), int(r.u32(gh + 8))})
+		}
+		meshes[i] = m
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	mesh := &Mesh{}
+	addMesh := func(index int, transform matrix) error {
+		if index < 0 || index >= len(meshes) {
+			return fmt.Errorf("mesh %d out of range", index)
+		}
+		m := meshes[index]
+		if m.vertexBuffer >= len(vertexBuffers) || m.indexBuffer >= len(indexBuffers) {
+			return fmt.Errorf("mesh %d: buffer out of range", index)
+		}
+		verts, indices := vertexBuffers[m.vertexBuffer], indexBuffers[m.indexBuffer]
+		groups := m.groups
+		if len(groups) == 0 {
+			groups = [][2]int{{0, len(indices) / 3}}
+		}
+		for _, g := range groups {
+			first, count := g[0], g[1]
+			if first < 0 || count < 0 || first+count*3 > len(indices) {
+				return fmt.Errorf("mesh %d: triangles out of range", index)
+			}
+			if len(mesh.Triangles)+count > maxTriangles {
+				return fmt.Errorf("more than %d triangles", maxTriangles)
+			}
+			for t := 0; t < count; t++ {
+				var tri Triangle
+				for c := 0; c < 3; c++ {
+					v := indices[first+t*3+c]
+					if int(v) >= len(verts) {
+						return fmt.Errorf("mesh %d: vertex %d out of range", index, v)
+					}
+					tri[c] = transform.apply(verts[v])
+				}
+				mesh.Triangles = append(mesh.Triangles, tri)
+			}
+		}
+		return nil
+	}
+
+	if numModels == 0 {
+		for i := range meshes {
+			if err := addMesh(i, identity); err != nil {
+				return nil, err
+			}
+		}
+		return mesh, nil
+	}
+	for i := 0; i < numModels; i++ {
+		h := offModels + int64(i*modelHeaderSize)
+		numBindings := int(r.u16(h + 4))
+		modelToScene := r.matrix(h + 8)
+		off := r.i64(h + 72)
+		for b := 0; b < numBindings; b++ {
+			bh := off + int64(b*meshBindingSize)
+			meshIndex := int(r.u16(bh + 2))
+			meshToModel := r.matrix(bh + 8)
+			if r.err != nil {
+				return nil, r.err
+			}
+			if err := addMesh(meshIndex, modelToScene.mul(meshToModel)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return mesh, nil
+}
+
+// Bounds returns the smallest box containing every triangle; ok is false for an empty mesh
+func (m *Mesh) Bounds() (min, max Vec3, ok bool) {
+	if len(m.Triangles) == 0 {
+		return min, max, false
+	}
+	min, max = m.Triangles[0][0], m.Triangles[0][0]
+	for _, tri := range m.Triangles {
+		for _, p := range tri {
+			for i := 0; i < 3; i++ {
+				min[i] = math.Min(min[i], p[i])
+				max[i] = math.Max(max[i], p[i])
+			}
+		}
+	}
+	return min, max, true
+}
@@ -0,0 +1,167 @@
+package papa
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// box returns the 8 corners and 12 triangles of an axis-aligned box
+func box(min, max Vec3) ([]Vec3, []uint16) {
+	var verts []Vec3
+	for i := 0; i < 8; i++ {
+		v := min
+		for axis := 0; axis < 3; axis++ {
+			if i&(1<<axis) != 0 {
+				v[axis] = max[axis]
+			}
+		}
+		verts = append(verts, v)
+	}
+	faces := [][4]uint16{{0, 1, 3, 2}, {4, 5, 7, 6}, {0, 1, 5, 4}, {2, 3, 7, 6}, {0, 2, 6, 4}, {1, 3, 7, 5}}
+	var indices []uint16
+	for _, f := range faces {
+		indices = append(indices, f[0], f[1], f[2], f[0], f[2], f[3])
+	}
+	return verts, indices
+}
+
+// encode writes a .papa file with one mesh bound to one model, positions in
+// Position3Normal3TexCoord2 vertices (32 bytes)
+func encode(verts []Vec3, indices []uint16, meshToModel [16]float32) []byte {
+	le := binary.LittleEndian
+	buf := make([]byte, headerSize)
+	le.PutUint32(buf[0:], Signature)
+	le.PutUint16(buf[10:], 1) // vertex buffers
+	le.PutUint16(buf[12:], 1) // index buffers
+	le.PutUint16(buf[16:], 1) // meshes
+	le.PutUint16(buf[20:], 1) // models
+	for off := 32; off < headerSize; off += 8 {
+		le.PutUint64(buf[off:], math.MaxUint64) // -1: section absent
+	}
+	appendAt := func(header int, data []byte) {
+		le.PutUint64(buf[header:], uint64(len(buf)))
+		buf = append(buf, data...)
+	}
+	u16 := func(v uint16) []byte { return le.AppendUint16(nil, v) }
+	f32s := func(fs ...float32) []byte {
+		var out []byte
+		for _, f := range fs {
+			out = le.AppendUint32(out, math.Float32bits(f))
+		}
+		return out
+	}
+	identity := [16]float32{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1}
+
+	var vertexData []byte
+	for _, v := range verts {
+		vertexData = append(vertexData, f32s(float32(v[0]), float32(v[1]), float32(v[2]), 0, 0, 1, 0, 0)...)
+	}
+	var indexData []byte
+	for _, i := range indices {
+		indexData = append(indexData, u16(i)...)
+	}
+
+	// Data first, then the headers pointing at it
+	vertexOff, indexOff := len(buf), len(buf)+len(vertexData)
+	buf = append(buf, vertexData...)
+	buf = append(buf, indexData...)
+	groupOff := len(buf)
+	group := append(append(u16(0), u16(0)...), le.AppendUint32(le.AppendUint32(nil, 0), uint32(len(indices)/3))...)
+	buf = append(buf, append(group, primitiveTriangle, 0, 0, 0)...)
+	bindingOff := len(buf)
+	binding := append(append(append(u16(0), u16(0)...), u16(0)...), 0, 0)
+	binding = append(binding, f32s(meshToModel[:]...)...)
+	buf = append(buf, le.AppendUint64(binding, 0)...)
+
+	vertexHeader := le.AppendUint32(le.AppendUint32(nil, 5), uint32(len(verts)))
+	vertexHeader = le.AppendUint64(le.AppendUint64(vertexHeader, uint64(len(vertexData))), uint64(vertexOff))
+	appendAt(48, vertexHeader)
+	indexHeader := le.AppendUint32([]byte{0, 0, 0, 0}, uint32(len(indices)))
+	indexHeader = le.AppendUint64(le.AppendUint64(indexHeader, uint64(len(indexData))), uint64(indexOff))
+	appendAt(56, indexHeader)
+	meshHeader := append(append(append(u16(0), u16(0)...), u16(1)...), 0, 0)
+	appendAt(72, le.AppendUint64(meshHeader, uint64(groupOff)))
+	modelHeader := append(append(append(u16(0), u16(0xffff)...), u16(1)...), 0, 0)
+	modelHeader = append(modelHeader, f32s(identity[:]...)...)
+	appendAt(88, le.AppendUint64(modelHeader, uint64(bindingOff)))
+	return buf
+}
+
+func TestDecode(t *testing.T) {
+	verts, indices := box(Vec3{-5, -2, 0}, Vec3{5, 2, 2})
+	// Column-major translation by (1, 2, 3)
+	translate := [16]float32{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 1, 2, 3, 1}
+	mesh, err := Decode(encode(verts, indices, translate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mesh.Triangles) != 12 {
+		t.Fatalf("got %d triangles, want 12", len(mesh.Triangles))
+	}
+	min, max, ok := mesh.Bounds()
+	if !ok || min != (Vec3{-4, 0, 3}) || max != (Vec3{6, 4, 5}) {
+		t.Errorf("Bounds() = %v, %v, want [-4 0 3], [6 4 5]", min, max)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	verts, indices := box(Vec3{0, 0, 0}, Vec3{1, 1, 1})
+	valid := encode(verts, indices, [16]float32{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1})
+
+	badIndex := append([]byte(nil), valid...)
+	indexOff := binary.LittleEndian.Uint64(badIndex[56:]) + 16
+	binary.LittleEndian.PutUint16(badIndex[binary.LittleEndian.Uint64(badIndex[indexOff:]):], 99)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"bad signature", append([]byte("Nope"), valid[4:]...)},
+		{"truncated", valid[:len(valid)-40]},
+		{"vertex out of range", badIndex},
+	}
+	for _, tt := range tests {
+		if _, err := Decode(tt.data); err == nil {
+			t.Errorf("%s: Decode() succeeded, want error", tt.name)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	// 10 wide, 4 long, 2 tall: the width fills the image in both views
+	verts, indices := box(Vec3{-5, -2, 0}, Vec3{5, 2, 2})
+	mesh, err := Decode(encode(verts, indices, [16]float32{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		view   View
+		opaque [][2]int
+		clear  [][2]int
+	}{
+		// 90px across and 36px tall, centred
+		{TopView, [][2]int{{50, 50}, {8, 50}, {91, 50}, {50, 33}}, [][2]int{{3, 50}, {96, 50}, {50, 30}}},
+		// Side on the box is only 36x18
+		{SideView, [][2]int{{50, 50}, {33, 50}, {50, 42}}, [][2]int{{8, 50}, {50, 38}, {50, 62}}},
+	}
+	for _, tt := range tests {
+		img := Render(mesh, tt.view, 100)
+		for _, p := range tt.opaque {
+			if c := img.NRGBAAt(p[0], p[1]); c.A != 255 || c.R == 0 {
+				t.Errorf("%s view: pixel %v = %v, want shaded", tt.view, p, c)
+			}
+		}
+		for _, p := range tt.clear {
+			if c := img.NRGBAAt(p[0], p[1]); c.A != 0 {
+				t.Errorf("%s view: pixel %v = %v, want transparent", tt.view, p, c)
+			}
+		}
+	}
+
+	if img := Render(&Mesh{}, TopView, 16); img.Bounds().Dx() != 16 || img.NRGBAAt(8, 8).A != 0 {
+		t.Error("empty mesh should render a blank image")
+	}
+}
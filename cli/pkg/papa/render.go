@@ -0,0 +1,141 @@
+package papa
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// View is the direction a silhouette is rendered from
+type View int
+
+const (
+	// TopView looks down the z axis: x right, y up the image
+	TopView View = iota
+	// SideView looks along the x axis: y right, z up the image
+	SideView
+)
+
+// String returns the view's file name suffix ("top" or "side")
+func (v View) String() string {
+	if v == SideView {
+		return "side"
+	}
+	return "top"
+}
+
+// Views lists every view, in the order silhouettes are exported
+var Views = []View{TopView, SideView}
+
+// silhouettePadding is the empty margin around a silhouette, as a fraction of the image
+const silhouettePadding = 0.05
+
+// project maps a scene point to image-plane u (right), v (up) and depth (towards the viewer)
+func (v View) project(p Vec3) (u, w, depth float64) {
+	if v == SideView {
+		return p[1], p[2], p[0]
+	}
+	return p[0], p[1], p[2]
+}
+
+// Render draws a flat-shaded grey silhouette of the mesh on a transparent size×size
+// image. Both views of a mesh share one scale (its largest dimension fills the image),
+// so top and side images line up.
+func Render(m *Mesh, view View, size int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	min, max, ok := m.Bounds()
+	if !ok || size <= 0 {
+		return img
+	}
+	extent := math.Max(max[0]-min[0], math.Max(max[1]-min[1], max[2]-min[2]))
+	if extent <= 0 {
+		return img
+	}
+	scale := float64(size) * (1 - 2*silhouettePadding) / extent
+	centre := Vec3{(min[0] + max[0]) / 2, (min[1] + max[1]) / 2, (min[2] + max[2]) / 2}
+	cu, cv, _ := view.project(centre)
+
+	// Light from above the viewer's left shoulder, in view space
+	light := normalize(Vec3{-0.4, 0.5, 0.77})
+
+	depth := make([]float64, size*size)
+	for i := range depth {
+		depth[i] = math.Inf(-1)
+	}
+	half := float64(size) / 2
+
+	for _, tri := range m.Triangles {
+		var px, py, pz [3]float64
+		var viewTri [3]Vec3
+		for i, p := range tri {
+			u, v, d := view.project(p)
+			viewTri[i] = Vec3{u, v, d}
+			px[i] = half + (u-cu)*scale
+			py[i] = half - (v-cv)*scale
+			pz[i] = d
+		}
+
+		// Flat shading from the face normal; either winding faces the light
+		n := normalize(cross(sub(viewTri[1], viewTri[0]), sub(viewTri[2], viewTri[0])))
+		lambert := math.Abs(dot(n, light))
+		grey := uint8(70 + 150*lambert)
+		shade := color.NRGBA{grey, grey, grey, 255}
+
+		area := edge(px[0], py[0], px[1], py[1], px[2], py[2])
+		if area == 0 {
+			continue
+		}
+		x0 := clamp(int(math.Floor(math.Min(px[0], math.Min(px[1], px[2])))), 0, size-1)
+		x1 := clamp(int(math.Ceil(math.Max(px[0], math.Max(px[1], px[2])))), 0, size-1)
+		y0 := clamp(int(math.Floor(math.Min(py[0], math.Min(py[1], py[2])))), 0, size-1)
+		y1 := clamp(int(math.Ceil(math.Max(py[0], math.Max(py[1], py[2])))), 0, size-1)
+		for y := y0; y <= y1; y++ {
+			for x := x0; x <= x1; x++ {
+				sx, sy := float64(x)+0.5, float64(y)+0.5
+				w0 := edge(px[1], py[1], px[2], py[2], sx, sy) / area
+				w1 := edge(px[2], py[2], px[0], py[0], sx, sy) / area
+				w2 := 1 - w0 - w1
+				if w0 < 0 || w1 < 0 || w2 < 0 {
+					continue
+				}
+				z := w0*pz[0] + w1*pz[1] + w2*pz[2]
+				if z <= depth[y*size+x] {
+					continue
+				}
+				depth[y*size+x] = z
+				img.SetNRGBA(x, y, shade)
+			}
+		}
+	}
+	return img
+}
+
+func edge(ax, ay, bx, by, cx, cy float64) float64 {
+	return (bx-ax)*(cy-ay) - (by-ay)*(cx-ax)
+}
+
+func sub(a, b Vec3) Vec3 { return Vec3{a[0] - b[0], a[1] - b[1], a[2] - b[2]} }
+
+func dot(a, b Vec3) float64 { return a[0]*b[0] + a[1]*b[1] + a[2]*b[2] }
+
+func cross(a, b Vec3) Vec3 {
+	return Vec3{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+func normalize(v Vec3) Vec3 {
+	l := math.Sqrt(dot(v, v))
+	if l == 0 {
+		return v
+	}
+	return Vec3{v[0] / l, v[1] / l, v[2] / l}
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,54 @@
+//go:build linux
+
+package parallel
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// Filesystem magic numbers (statfs f_type) of network shares
+const (
+	nfsMagic  = 0x6969
+	smbMagic  = 0x517b
+	cifsMagic = 0xff534d42
+	smb2Magic = 0xfe534d42
+)
+
+// DetectDisk reports a network share from the filesystem type, otherwise whether sysfs marks
+// the block device holding path as rotational. Anything it can't read is DiskUnknown.
+func DetectDisk(path string) DiskKind {
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(path, &fs); err != nil {
+		return DiskUnknown
+	}
+	switch uint32(fs.Type) {
+	case nfsMagic, smbMagic, cifsMagic, smb2Magic:
+		return DiskNetwork
+	}
+
+	var st syscall.Stat_t
+	if err := syscall.Stat(path, &st); err != nil {
+		return DiskUnknown
+	}
+	dev := uint64(st.Dev)
+	major := (dev>>8)&0xfff | (dev>>32)&^uint64(0xfff)
+	minor := dev&0xff | (dev>>12)&^uint64(0xff)
+	dir := fmt.Sprintf("/sys/dev/block/%d:%d", major, minor)
+	// Partitions have no queue/ of their own; it's on the parent disk
+	for _, p := range []string{dir + "/queue/rotational", dir + "/../queue/rotational"} {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		switch strings.TrimSpace(string(data)) {
+		case "0":
+			return DiskSSD
+		case "1":
+			return DiskRotational
+		}
+	}
+	return DiskUnknown
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package parallel
+
+// DetectDisk can't classify storage on this platform; --io-limit falls back to its default
+func DetectDisk(path string) DiskKind {
+	return DiskUnknown
+}
@@ -0,0 +1,97 @@
+// Package parallel bounds the goroutines used for extraction work (parsing, copying,
+// downloads) and picks default limits for the machine and the disk being read.
+package parallel
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// Panic is what ForEach panics with on the calling goroutine when fn panicked on a worker,
+// so the panic can be recovered there with the worker's stack
+type Panic struct {
+	Value any
+	Stack []byte // The worker goroutine's stack when it panicked
+}
+
+func (p *Panic) Error() string {
+	return fmt.Sprintf("panic in worker: %v", p.Value)
+}
+
+// ForEach calls fn for each index in [0, n) on at most workers goroutines and waits for them
+// all. With workers <= 1 the calls run in order on the calling goroutine. If fn panics on a
+// worker, the remaining indexes still run and ForEach then panics with a *Panic holding the
+// first panic.
+func ForEach(n, workers int, fn func(i int)) {
+	if workers <= 1 || n <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+	if workers > n {
+		workers = n
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var panicked *Panic
+	call := func(i int) {
+		defer func() {
+			if r := recover(); r != nil {
+				once.Do(func() { panicked = &Panic{Value: r, Stack: debug.Stack()} })
+			}
+		}()
+		fn(i)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				call(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	if panicked != nil {
+		panic(panicked)
+	}
+}
+
+// DefaultWorkers is the default for --parallelism: one goroutine per usable CPU
+func DefaultWorkers() int {
+	return runtime.GOMAXPROCS(0)
+}
+
+// DiskKind classifies the storage behind a path, for sizing IO concurrency
+type DiskKind string
+
+const (
+	DiskUnknown    DiskKind = "unknown"
+	DiskSSD        DiskKind = "ssd"
+	DiskRotational DiskKind = "rotational" // Spinning disk: concurrent reads mostly add seeks
+	DiskNetwork    DiskKind = "network"    // NFS or SMB share: latency-bound, so more reads in flight help
+)
+
+// DefaultIOLimit is the default for --io-limit: how many files to read at once from the disk
+// holding path (see DetectDisk)
+func DefaultIOLimit(path string) (int, DiskKind) {
+	kind := DetectDisk(path)
+	switch kind {
+	case DiskRotational:
+		return 2, kind
+	case DiskNetwork:
+		return 16, kind
+	case DiskSSD:
+		return max(8, DefaultWorkers()), kind
+	default:
+		return 4, kind
+	}
+}
@@ -0,0 +1,78 @@
+package parallel
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestForEachPanic verifies a worker's panic reaches the caller after the other indexes ran
+func TestForEachPanic(t *testing.T) {
+	var ran atomic.Int32
+	defer func() {
+		p, ok := recover().(*Panic)
+		if !ok {
+			t.Fatalf("ForEach didn't panic with *Panic")
+		}
+		if p.Value != "boom" || !strings.Contains(string(p.Stack), "parallel") {
+			t.Errorf("Panic = %v with stack %.200s", p.Value, p.Stack)
+		}
+		if ran.Load() != 19 {
+			t.Errorf("%d other indexes ran, want 19", ran.Load())
+		}
+	}()
+	ForEach(20, 4, func(i int) {
+		if i == 7 {
+			panic("boom")
+		}
+		ran.Add(1)
+	})
+}
+
+// TestForEach verifies every index runs exactly once and no more than workers run at a time
+func TestForEach(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       int
+		workers int
+	}{
+		{"sequential", 10, 1},
+		{"bounded", 100, 4},
+		{"more workers than work", 3, 16},
+		{"no work", 0, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			seen := make(map[int]int)
+			var running, peak atomic.Int32
+			ForEach(tt.n, tt.workers, func(i int) {
+				now := running.Add(1)
+				for {
+					p := peak.Load()
+					if now <= p || peak.CompareAndSwap(p, now) {
+						break
+					}
+				}
+				mu.Lock()
+				seen[i]++
+				mu.Unlock()
+				running.Add(-1)
+			})
+
+			if len(seen) != tt.n {
+				t.Errorf("ran %d indexes, want %d", len(seen), tt.n)
+			}
+			for i, count := range seen {
+				if count != 1 {
+					t.Errorf("index %d ran %d times", i, count)
+				}
+			}
+			if limit := int32(max(tt.workers, 1)); peak.Load() > limit {
+				t.Errorf("peak concurrency %d exceeds %d workers", peak.Load(), limit)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+)
+
+// baseSpecCycle follows a spec's base_spec chain and returns the loop it ends in, from the
+// first repeated spec round to that spec again (a -> b -> a); nil if the chain terminates.
+func baseSpecCycle(l *loader.Loader, resourceName string) []string {
+	var path []string
+	seen := make(map[string]int)
+	for name := resourceName; name != ""; {
+		if start, ok := seen[name]; ok {
+			return append(path[start:], name)
+		}
+		seen[name] = len(path)
+		path = append(path, name)
+		data, err := l.GetJSON(name)
+		if err != nil {
+			return nil
+		}
+		name = loader.GetString(data, "base_spec", "")
+	}
+	return nil
+}
+
+// cycleWarning describes a base_spec cycle and where the parsers cut it: the last spec
+// before the repeat is parsed without its base.
+func cycleWarning(cycle []string) string {
+	return fmt.Sprintf("base_spec cycle %s: inheritance stops at %s",
+		strings.Join(cycle, " -> "), cycle[len(cycle)-2])
+}
+
+// inChain reports whether following base_spec would revisit a spec already being parsed
+func inChain(chain []string, resourceName, baseSpec string) bool {
+	if baseSpec == resourceName {
+		return true
+	}
+	for _, name := range chain {
+		if name == baseSpec {
+			return true
+		}
+	}
+	return false
+}
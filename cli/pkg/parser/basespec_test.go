@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+)
+
+// TestBaseSpecCycles verifies circular base_spec chains parse without recursing forever,
+// keep what they inherit up to the repeat, and are reported as unit warnings
+func TestBaseSpecCycles(t *testing.T) {
+	paRoot := t.TempDir()
+	files := map[string]string{
+		"pa/units/land/ouro/ouro.json":     `{"base_spec": "/pa/units/land/ouro/ouro.json", "display_name": "Ouro"}`,
+		"pa/units/land/ping/ping.json":     `{"base_spec": "/pa/units/land/pong/pong.json", "display_name": "Ping"}`,
+		"pa/units/land/pong/pong.json":     `{"base_spec": "/pa/units/land/ping/ping.json", "description": "From pong"}`,
+		"pa/units/land/child/child.json":   `{"base_spec": "/pa/units/land/ping/ping.json", "display_name": "Child"}`,
+		"pa/units/land/looper/looper.json": `{"tools": [{"spec_id": "/pa/units/land/looper/looper_weapon.json"}]}`,
+		"pa/units/land/looper/looper_weapon.json": `{
+			"base_spec": "/pa/units/land/looper/looper_weapon.json",
+			"rate_of_fire": 2,
+			"ammo_id": "/pa/units/land/looper/looper_ammo.json"
+		}`,
+		"pa/units/land/looper/looper_ammo.json": `{"base_spec": "/pa/units/land/looper/looper_ammo.json", "damage": 5}`,
+	}
+	writeSpecFiles(t, paRoot, files)
+
+	l, err := loader.NewMultiSourceLoader(paRoot, "pa_ex1", nil)
+	if err != nil {
+		t.Fatalf("failed to create loader: %v", err)
+	}
+	defer l.Close()
+
+	const (
+		ouro   = "/pa/units/land/ouro/ouro.json"
+		ping   = "/pa/units/land/ping/ping.json"
+		pong   = "/pa/units/land/pong/pong.json"
+		weapon = "/pa/units/land/looper/looper_weapon.json"
+		ammo   = "/pa/units/land/looper/looper_ammo.json"
+	)
+
+	tests := []struct {
+		name        string
+		unit        string
+		description string
+		warnings    []string
+	}{
+		{
+			name:     "self reference",
+			unit:     ouro,
+			warnings: []string{"base_spec cycle " + ouro + " -> " + ouro + ": inheritance stops at " + ouro},
+		},
+		{
+			name:        "mutual reference",
+			unit:        ping,
+			description: "From pong",
+			warnings:    []string{"base_spec cycle " + ping + " -> " + pong + " -> " + ping + ": inheritance stops at " + pong},
+		},
+		{
+			name:        "inherits from a cycle",
+			unit:        "/pa/units/land/child/child.json",
+			description: "From pong",
+			warnings:    []string{"base_spec cycle " + ping + " -> " + pong + " -> " + ping + ": inheritance stops at " + pong},
+		},
+		{
+			name: "tool and ammo cycles",
+			unit: "/pa/units/land/looper/looper.json",
+			warnings: []string{
+				"weapon " + weapon + ": base_spec cycle " + weapon + " -> " + weapon + ": inheritance stops at " + weapon,
+				"weapon " + weapon + ": ammo base_spec cycle " + ammo + " -> " + ammo + ": inheritance stops at " + ammo,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unit, err := ParseUnit(l, tt.unit, nil)
+			if err != nil {
+				t.Fatalf("ParseUnit failed: %v", err)
+			}
+			if unit.Description != tt.description {
+				t.Errorf("Description = %q, want %q", unit.Description, tt.description)
+			}
+			if !reflect.DeepEqual(unit.Warnings, tt.warnings) {
+				t.Errorf("Warnings = %q, want %q", unit.Warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+// TestBaseSpecCycleTerminates verifies a chain ending in a missing spec is not a cycle
+func TestBaseSpecCycleTerminates(t *testing.T) {
+	paRoot := t.TempDir()
+	writeSpecFiles(t, paRoot, map[string]string{
+		"pa/a.json": `{"base_spec": "/pa/b.json"}`,
+		"pa/b.json": `{"base_spec": "/pa/missing.json"}`,
+	})
+	l, err := loader.NewMultiSourceLoader(paRoot, "pa_ex1", nil)
+	if err != nil {
+		t.Fatalf("failed to create loader: %v", err)
+	}
+	defer l.Close()
+
+	if cycle := baseSpecCycle(l, "/pa/a.json"); cycle != nil {
+		t.Errorf("baseSpecCycle = %v, want nil for a chain ending in a missing spec", cycle)
+	}
+}
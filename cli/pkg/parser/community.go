@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// communityField is a unit JSON field that PA ignores but some mods add, together with
+// their own scripts, for mechanics the engine lacks (regeneration, shields). Paths are
+// dot-separated keys from the unit JSON root.
+type communityField struct {
+	Paths []string // Spellings used by different mods; the first present wins
+	Apply func(special *models.SpecialSpecs, value float64)
+}
+
+// communityFields are the known extension fields, captured into SpecialSpecs so modded
+// defenses aren't shown as plain HP. To support another mod's spelling, add its path to
+// an entry; for a new mechanic, add an entry and a SpecialSpecs field.
+var communityFields = []communityField{
+	{
+		Paths: []string{"health_regen", "health_regen_rate", "regen_rate", "regeneration.rate"},
+		Apply: func(s *models.SpecialSpecs, v float64) { s.HealthRegen = v },
+	},
+	{
+		Paths: []string{"shield.max_health", "shield.health", "shield.hp", "shield_health", "shield_hp"},
+		Apply: func(s *models.SpecialSpecs, v float64) { s.ShieldHealth = v },
+	},
+	{
+		Paths: []string{"shield.recharge_rate", "shield.regen_rate", "shield_recharge_rate", "shield_recharge"},
+		Apply: func(s *models.SpecialSpecs, v float64) { s.ShieldRecharge = v },
+	},
+	{
+		Paths: []string{"shield.recharge_delay", "shield_recharge_delay"},
+		Apply: func(s *models.SpecialSpecs, v float64) { s.ShieldRechargeDelay = v },
+	},
+}
+
+// parseCommunityFields applies every community field present in the unit JSON. Fields
+// absent here keep the value inherited from base_spec.
+func parseCommunityFields(data map[string]interface{}, unit *models.Unit) {
+	for _, field := range communityFields {
+		for _, path := range field.Paths {
+			if v, ok := lookupNumber(data, path); ok {
+				field.Apply(unit.Specs.Special, v)
+				break
+			}
+		}
+	}
+}
+
+// lookupNumber follows a dot-separated path through nested objects to a number
+func lookupNumber(data map[string]interface{}, path string) (float64, bool) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := data[key].(map[string]interface{})
+		if !ok {
+			return 0, false
+		}
+		data = next
+	}
+	switch v := data[keys[len(keys)-1]].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
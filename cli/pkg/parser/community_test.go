@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestParseCommunityFields(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      map[string]interface{}
+		inherited models.SpecialSpecs
+		want      models.SpecialSpecs
+	}{
+		{
+			name: "flat fields",
+			data: map[string]interface{}{"health_regen": 5.0, "shield_hp": 400.0, "shield_recharge": 20.0},
+			want: models.SpecialSpecs{HealthRegen: 5, ShieldHealth: 400, ShieldRecharge: 20},
+		},
+		{
+			name: "nested shield object",
+			data: map[string]interface{}{
+				"regeneration": map[string]interface{}{"rate": 2.5},
+				"shield":       map[string]interface{}{"max_health": 1000.0, "recharge_rate": 50.0, "recharge_delay": 3.0},
+			},
+			want: models.SpecialSpecs{HealthRegen: 2.5, ShieldHealth: 1000, ShieldRecharge: 50, ShieldRechargeDelay: 3},
+		},
+		{
+			name: "first spelling wins",
+			data: map[string]interface{}{
+				"shield":        map[string]interface{}{"max_health": 1000.0},
+				"shield_health": 10.0,
+			},
+			want: models.SpecialSpecs{ShieldHealth: 1000},
+		},
+		{
+			name:      "absent fields keep inherited values",
+			data:      map[string]interface{}{"shield": map[string]interface{}{"recharge_rate": 80.0}},
+			inherited: models.SpecialSpecs{Hover: true, ShieldHealth: 500, ShieldRecharge: 40},
+			want:      models.SpecialSpecs{Hover: true, ShieldHealth: 500, ShieldRecharge: 80},
+		},
+		{
+			name: "non-numeric values are ignored",
+			data: map[string]interface{}{"health_regen": "fast", "shield": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			special := tt.inherited
+			unit := &models.Unit{Specs: models.UnitSpecs{Special: &special}}
+			parseCommunityFields(tt.data, unit)
+			if got := *unit.Specs.Special; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCommunityFields() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// CheckContent is the optional content QA pass over parsed units' display text. It flags
+// empty descriptions, descriptions that only repeat the display name, !LOC markers
+// Delocalize couldn't strip and control or replacement characters, appending a "content:"
+// warning to the unit for each. Base templates are skipped. It returns the number of
+// warnings added.
+func CheckContent(units []models.Unit) int {
+	added := 0
+	for i := range units {
+		unit := &units[i]
+		if unit.BaseTemplate {
+			continue
+		}
+		for _, issue := range contentIssues(unit) {
+			unit.Warnings = append(unit.Warnings, "content: "+issue)
+			added++
+		}
+	}
+	return added
+}
+
+func contentIssues(unit *models.Unit) []string {
+	var issues []string
+	description := strings.TrimSpace(unit.Description)
+	switch {
+	case description == "":
+		issues = append(issues, "description is empty")
+	case strings.EqualFold(description, strings.TrimSpace(unit.DisplayName)):
+		issues = append(issues, "description only repeats the display name")
+	}
+
+	for _, field := range []struct{ name, text string }{
+		{"display name", unit.DisplayName},
+		{"description", unit.Description},
+	} {
+		if strings.Contains(field.text, "!LOC") {
+			issues = append(issues, fmt.Sprintf("%s has an unstripped localization marker: %q", field.name, field.text))
+		}
+		if r, ok := suspiciousRune(field.text); ok {
+			issues = append(issues, fmt.Sprintf("%s contains character %U", field.name, r))
+		}
+	}
+	return issues
+}
+
+// suspiciousRune returns the first control character (other than newline and tab) or
+// Unicode replacement character, a sign of text decoded with the wrong encoding
+func suspiciousRune(text string) (rune, bool) {
+	for _, r := range text {
+		if r == unicode.ReplacementChar || (unicode.IsControl(r) && r != '\n' && r != '\t') {
+			return r, true
+		}
+	}
+	return 0, false
+}
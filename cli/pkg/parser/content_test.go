@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestCheckContent(t *testing.T) {
+	tests := []struct {
+		name       string
+		unit       models.Unit
+		wantIssues []string
+	}{
+		{
+			name: "clean unit",
+			unit: models.Unit{DisplayName: "Ant", Description: "Tank - Basic tank."},
+		},
+		{
+			name:       "empty description",
+			unit:       models.Unit{DisplayName: "Ant", Description: "  "},
+			wantIssues: []string{"content: description is empty"},
+		},
+		{
+			name:       "description repeats name",
+			unit:       models.Unit{DisplayName: "Ant", Description: "ant"},
+			wantIssues: []string{"content: description only repeats the display name"},
+		},
+		{
+			name:       "unstripped marker",
+			unit:       models.Unit{DisplayName: "!LOC(units.tank.name)Ant", Description: "Basic tank."},
+			wantIssues: []string{`content: display name has an unstripped localization marker: "!LOC(units.tank.name)Ant"`},
+		},
+		{
+			name:       "control and replacement characters",
+			unit:       models.Unit{DisplayName: "Ant\x07", Description: "Basic\ntank �"},
+			wantIssues: []string{"content: display name contains character U+0007", "content: description contains character U+FFFD"},
+		},
+		{
+			name: "base template skipped",
+			unit: models.Unit{DisplayName: "base_tank", BaseTemplate: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			units := []models.Unit{tt.unit}
+			added := CheckContent(units)
+			if added != len(tt.wantIssues) || !reflect.DeepEqual(units[0].Warnings, tt.wantIssues) {
+				t.Errorf("CheckContent() added %d warnings %q, want %q", added, units[0].Warnings, tt.wantIssues)
+			}
+		})
+	}
+}
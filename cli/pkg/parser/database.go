@@ -4,15 +4,32 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
 	"github.com/jamiemulcahy/pa-pedia/pkg/models"
 )
 
-// Database manages unit parsing and relationship building
+// Database manages unit parsing and relationship building.
+//
+// Its methods are safe for concurrent use: loading and filtering take a write
+// lock, queries a read lock. Direct access to Units is not synchronized, so once a
+// database is shared between goroutines read it through the methods or a Snapshot.
 type Database struct {
 	Loader *loader.Loader
 	Units  map[string]*models.Unit // Keyed by unit ID
+
+	mu sync.RWMutex
+
+	// Commanders pins the unit IDs that seed accessibility analysis.
+	// When empty, every unit tagged Commander is used, minus ExcludeCommanders.
+	Commanders        []string
+	ExcludeCommanders []string
+
+	// Parallelism is how many goroutines read unit JSON ahead of parsing (see
+	// loader.Prefetch); parsing itself stays sequential so unit IDs are stable. 0 or 1 reads
+	// each unit as it's parsed.
+	Parallelism int
 }
 
 // NewDatabase creates a new database parser
@@ -28,6 +45,9 @@ func NewDatabase(l *loader.Loader) *Database {
 // factionUnitType must be provided by the caller - validation happens at CLI layer
 // allowEmpty controls whether 0 matching units is an error or just a warning
 func (db *Database) LoadUnits(verbose bool, factionUnitType string, allowEmpty bool) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
 	// Load merged unit list from all sources
 	unitPaths, _, err := db.Loader.LoadMergedUnitList()
 	if err != nil {
@@ -39,6 +59,9 @@ func (db *Database) LoadUnits(verbose bool, factionUnitType string, allowEmpty b
 	}
 
 	// Parse each unit
+	if db.Parallelism > 1 {
+		db.Loader.Prefetch(unitPaths, db.Parallelism)
+	}
 	allUnits := make([]*models.Unit, 0, len(unitPaths))
 	filteredCount := 0
 	for i, unitPath := range unitPaths {
@@ -86,6 +109,9 @@ func (db *Database) LoadUnits(verbose bool, factionUnitType string, allowEmpty b
 	// Discover and add spawned units (units referenced by spawn_unit_on_death)
 	db.discoverSpawnedUnits(verbose)
 
+	// Mark units reachable from commanders (builds, factory spawns, launch payloads)
+	db.markAccessible(verbose)
+
 	// Apply corrections
 	db.applyCorrections()
 
@@ -96,6 +122,9 @@ func (db *Database) LoadUnits(verbose bool, factionUnitType string, allowEmpty b
 // Used for addon mods where filtering is done by exclusion (removing base game units) rather than inclusion.
 // The caller is responsible for filtering out unwanted units after this call.
 func (db *Database) LoadUnitsNoFilter(verbose bool) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
 	// Load merged unit list from all sources
 	unitPaths, _, err := db.Loader.LoadMergedUnitList()
 	if err != nil {
@@ -107,6 +136,9 @@ func (db *Database) LoadUnitsNoFilter(verbose bool) error {
 	}
 
 	// Parse each unit
+	if db.Parallelism > 1 {
+		db.Loader.Prefetch(unitPaths, db.Parallelism)
+	}
 	allUnits := make([]*models.Unit, 0, len(unitPaths))
 	for i, unitPath := range unitPaths {
 		if verbose && i%10 == 0 {
@@ -135,6 +167,9 @@ func (db *Database) LoadUnitsNoFilter(verbose bool) error {
 	// Discover and add spawned units (units referenced by spawn_unit_on_death)
 	db.discoverSpawnedUnits(verbose)
 
+	// Mark units reachable from commanders (builds, factory spawns, launch payloads)
+	db.markAccessible(verbose)
+
 	// Apply corrections
 	db.applyCorrections()
 
@@ -159,10 +194,17 @@ func unitMatchesFactionType(unit *models.Unit, factionUnitType string) bool {
 
 // buildBuildTree establishes build relationships between units
 func (db *Database) buildBuildTree(allUnits []*models.Unit, verbose bool) error {
-	// Sort units by build cost and name for consistent ordering
+	// Sort units by build cost and name for consistent ordering. Economy is always set by
+	// ParseUnit but may be missing from units read back from a pruned export (see Relink).
+	buildCost := func(unit *models.Unit) float64 {
+		if unit.Specs.Economy == nil {
+			return 0
+		}
+		return unit.Specs.Economy.BuildCost
+	}
 	sort.Slice(allUnits, func(i, j int) bool {
-		costI := allUnits[i].Specs.Economy.BuildCost
-		costJ := allUnits[j].Specs.Economy.BuildCost
+		costI := buildCost(allUnits[i])
+		costJ := buildCost(allUnits[j])
 		if costI != costJ {
 			return costI < costJ
 		}
@@ -180,7 +222,16 @@ func (db *Database) buildBuildTree(allUnits []*models.Unit, verbose bool) error
 		fmt.Printf("  Building unit relationships...\n")
 	}
 
-	// Build relationships
+	// Build relationships, matching each distinct restriction once against an index of the
+	// buildable units (see typeIndex)
+	buildable := make([]*models.Unit, 0, len(allUnits))
+	for _, unit := range allUnits {
+		if !unit.BaseTemplate {
+			buildable = append(buildable, unit)
+		}
+	}
+	index := newTypeIndex(buildable)
+
 	processedCount := 0
 	for _, unit := range allUnits {
 		if unit.BaseTemplate {
@@ -197,23 +248,20 @@ func (db *Database) buildBuildTree(allUnits []*models.Unit, verbose bool) error
 			fmt.Printf("    Processing build relationships %d...\r", processedCount)
 		}
 
-		restriction := ParseRestriction(unit.BuildableTypes)
-
-		// Check which units satisfy this restriction
-		builds := make([]string, 0)
-		for _, other := range allUnits {
-			if other.BaseTemplate {
-				continue
-			}
+		matches, diags := index.matchExpression(unit.BuildableTypes)
+		for _, d := range diags {
+			unit.Warnings = append(unit.Warnings, fmt.Sprintf("buildable_types %q: %s", unit.BuildableTypes, d))
+		}
 
-			if restriction.Satisfies(other) {
-				builds = append(builds, other.ID)
-				// Add to other's builtBy list
-				if other.BuildRelationships.BuiltBy == nil {
-					other.BuildRelationships.BuiltBy = make([]string, 0)
-				}
-				other.BuildRelationships.BuiltBy = append(other.BuildRelationships.BuiltBy, unit.ID)
+		// Link the units that satisfy this restriction
+		builds := make([]string, 0, len(matches))
+		for _, other := range matches {
+			builds = append(builds, other.ID)
+			// Add to other's builtBy list
+			if other.BuildRelationships.BuiltBy == nil {
+				other.BuildRelationships.BuiltBy = make([]string, 0)
 			}
+			other.BuildRelationships.BuiltBy = append(other.BuildRelationships.BuiltBy, unit.ID)
 		}
 
 		unit.BuildRelationships.Builds = builds
@@ -223,35 +271,61 @@ func (db *Database) buildBuildTree(allUnits []*models.Unit, verbose bool) error
 		fmt.Printf("\n")
 	}
 
-	// Find all commanders
-	commanders := make([]*models.Unit, 0)
-	for _, unit := range db.Units {
-		for _, ut := range unit.UnitTypes {
-			if ut == "Commander" {
-				commanders = append(commanders, unit)
-				break
-			}
-		}
-	}
+	return nil
+}
+
+// markAccessible marks every unit reachable from a commander as accessible and records
+// how it was first reached. The walk is breadth-first from the commanders and follows
+// build relationships, factory initial build specs, and spawn_unit_on_death references
+// on units and on the projectiles they fire or launch, so the recorded Via is always
+// the shortest route back towards a commander.
+func (db *Database) markAccessible(verbose bool) {
+	commanders := db.findCommanders(verbose)
 
+	// Mark accessible units (units that can be reached starting from commanders)
 	if verbose {
-		fmt.Printf("  Found %d commanders\n", len(commanders))
+		fmt.Printf("  Marking accessible units...\n")
 	}
 
-	// Sort commanders by name
-	sort.Slice(commanders, func(i, j int) bool {
-		return commanders[i].DisplayName < commanders[j].DisplayName
-	})
+	byResource := make(map[string]*models.Unit, len(db.Units))
+	for _, unit := range db.Units {
+		byResource[unit.ResourceName] = unit
+	}
 
-	// Mark accessible units (units that can be built starting from commanders)
-	if verbose {
-		fmt.Printf("  Marking accessible units...\n")
+	queue := make([]*models.Unit, 0, len(db.Units))
+	reach := func(unit *models.Unit, method, via string) {
+		if unit == nil || unit.Accessible || disabledUnits[unit.ID] {
+			return // Unknown, already reached by a shorter route, or disabled
+		}
+		unit.Accessible = true
+		unit.Reachability = &models.Reachability{Method: method, Via: via}
+		queue = append(queue, unit)
 	}
 
 	for _, commander := range commanders {
-		db.setAccessible(commander)
+		reach(commander, models.ReachCommander, "")
+	}
+
+	for len(queue) > 0 {
+		unit := queue[0]
+		queue = queue[1:]
+
+		for _, buildableID := range unit.BuildRelationships.Builds {
+			reach(db.Units[buildableID], models.ReachBuilt, unit.ID)
+		}
+
+		// Ammo initial build specs resolve to nothing here, only unit specs are followed
+		if unit.Specs.Storage != nil && unit.Specs.Storage.InitialBuildSpec != "" {
+			reach(byResource[unit.Specs.Storage.InitialBuildSpec], models.ReachFactorySpawn, unit.ID)
+		}
+
+		for _, ref := range spawnReferences(unit) {
+			reach(byResource[ref.path], ref.method, unit.ID)
+		}
 	}
 
+	db.computeTechPaths()
+
 	// Count accessible units
 	if verbose {
 		accessibleCount := 0
@@ -262,24 +336,117 @@ func (db *Database) buildBuildTree(allUnits []*models.Unit, verbose bool) error
 		}
 		fmt.Printf("  Marked %d units as accessible\n", accessibleCount)
 	}
+}
 
-	return nil
+// computeTechPaths records, for every accessible unit, the chain of units back to its
+// commander by following Reachability.Via, and the metal cost of the intermediate units
+// (the factories and builders you need on the way).
+func (db *Database) computeTechPaths() {
+	for _, unit := range db.Units {
+		if !unit.Accessible || unit.Reachability == nil {
+			continue
+		}
+
+		path := []string{unit.ID}
+		factoryCost := 0.0
+		for step := unit; step.Reachability != nil && step.Reachability.Via != ""; {
+			prev, ok := db.Units[step.Reachability.Via]
+			if !ok || len(path) > len(db.Units) {
+				break // Defensive: the BFS tree cannot contain a cycle
+			}
+			if prev.Reachability != nil && prev.Reachability.Via != "" && prev.Specs.Economy != nil {
+				factoryCost += prev.Specs.Economy.BuildCost
+			}
+			path = append(path, prev.ID)
+			step = prev
+		}
+
+		// Walked backwards from the unit; present commander-first
+		for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+			path[i], path[j] = path[j], path[i]
+		}
+
+		unit.TechPath = &models.TechPath{Path: path, FactoryCost: factoryCost}
+	}
 }
 
-// setAccessible recursively marks a unit and all units it can build as accessible
-func (db *Database) setAccessible(unit *models.Unit) {
-	if unit.Accessible {
-		return // Already processed
+// findCommanders returns the units that seed accessibility analysis, sorted by name.
+// Pinned Commanders are used as-is (missing IDs are warned about); otherwise every unit
+// tagged Commander is used except those in ExcludeCommanders.
+func (db *Database) findCommanders(verbose bool) []*models.Unit {
+	commanders := make([]*models.Unit, 0)
+
+	if len(db.Commanders) > 0 {
+		for _, id := range db.Commanders {
+			unit, ok := db.Units[id]
+			if !ok {
+				fmt.Printf("⚠ WARNING: Pinned commander '%s' not found in loaded units\n", id)
+				continue
+			}
+			commanders = append(commanders, unit)
+		}
+	} else {
+		excluded := make(map[string]bool, len(db.ExcludeCommanders))
+		for _, id := range db.ExcludeCommanders {
+			excluded[id] = true
+		}
+
+		for _, unit := range db.Units {
+			if excluded[unit.ID] {
+				continue
+			}
+			for _, ut := range unit.UnitTypes {
+				if ut == "Commander" {
+					commanders = append(commanders, unit)
+					break
+				}
+			}
+		}
 	}
 
-	unit.Accessible = true
+	if verbose {
+		fmt.Printf("  Found %d commanders\n", len(commanders))
+	}
 
-	// Recursively mark all buildable units as accessible
-	for _, buildableID := range unit.BuildRelationships.Builds {
-		if builtUnit, ok := db.Units[buildableID]; ok {
-			db.setAccessible(builtUnit)
+	// Sort commanders by name
+	sort.Slice(commanders, func(i, j int) bool {
+		return commanders[i].DisplayName < commanders[j].DisplayName
+	})
+
+	return commanders
+}
+
+// spawnReference is a unit resource path spawned by another unit, with the reachability
+// method describing how it is spawned
+type spawnReference struct {
+	path   string
+	method string
+}
+
+// spawnReferences collects the spawn_unit_on_death references of a unit: its own (when it dies)
+// and those of its weapons' ammo, including every buildable ammo option of factory-sourced weapons
+// (launch payloads such as drones and orbital deployments)
+func spawnReferences(unit *models.Unit) []spawnReference {
+	refs := make([]spawnReference, 0)
+
+	if unit.Specs.Special != nil && unit.Specs.Special.SpawnUnitOnDeath != "" {
+		refs = append(refs, spawnReference{unit.Specs.Special.SpawnUnitOnDeath, models.ReachSpawnedOnDeath})
+	}
+
+	if unit.Specs.Combat != nil {
+		for _, weapon := range unit.Specs.Combat.Weapons {
+			if weapon.Ammo != nil && weapon.Ammo.SpawnUnitOnDeath != "" {
+				refs = append(refs, spawnReference{weapon.Ammo.SpawnUnitOnDeath, models.ReachProjectile})
+			}
+			for _, ammo := range weapon.BuildableAmmo {
+				if ammo.SpawnUnitOnDeath != "" {
+					refs = append(refs, spawnReference{ammo.SpawnUnitOnDeath, models.ReachProjectile})
+				}
+			}
 		}
 	}
+
+	return refs
 }
 
 // discoverSpawnedUnits finds and adds units referenced by spawn_unit_on_death fields
@@ -295,27 +462,12 @@ func (db *Database) discoverSpawnedUnits(verbose bool) {
 		visited[unit.ResourceName] = true
 	}
 
-	// Collect initial spawn references from all units
+	// Collect initial spawn references from all units (unit-level and ammo-level)
 	for _, unit := range db.Units {
-		// Unit-level spawn
-		if unit.Specs.Special != nil && unit.Specs.Special.SpawnUnitOnDeath != "" {
-			path := unit.Specs.Special.SpawnUnitOnDeath
-			if !visited[path] {
-				spawnQueue = append(spawnQueue, path)
-				visited[path] = true
-			}
-		}
-
-		// Ammo-level spawns from weapons
-		if unit.Specs.Combat != nil {
-			for _, weapon := range unit.Specs.Combat.Weapons {
-				if weapon.Ammo != nil && weapon.Ammo.SpawnUnitOnDeath != "" {
-					path := weapon.Ammo.SpawnUnitOnDeath
-					if !visited[path] {
-						spawnQueue = append(spawnQueue, path)
-						visited[path] = true
-					}
-				}
+		for _, ref := range spawnReferences(unit) {
+			if !visited[ref.path] {
+				spawnQueue = append(spawnQueue, ref.path)
+				visited[ref.path] = true
 			}
 		}
 	}
@@ -352,7 +504,7 @@ func (db *Database) discoverSpawnedUnits(verbose bool) {
 			continue
 		}
 
-		// Add to database (spawned units are not accessible via build tree)
+		// Add to database (accessibility is decided afterwards by markAccessible)
 		db.Units[unit.ID] = unit
 		addedCount++
 
@@ -361,23 +513,10 @@ func (db *Database) discoverSpawnedUnits(verbose bool) {
 		}
 
 		// Check this unit for further spawn references
-		if unit.Specs.Special != nil && unit.Specs.Special.SpawnUnitOnDeath != "" {
-			path := unit.Specs.Special.SpawnUnitOnDeath
-			if !visited[path] {
-				spawnQueue = append(spawnQueue, path)
-				visited[path] = true
-			}
-		}
-
-		if unit.Specs.Combat != nil {
-			for _, weapon := range unit.Specs.Combat.Weapons {
-				if weapon.Ammo != nil && weapon.Ammo.SpawnUnitOnDeath != "" {
-					path := weapon.Ammo.SpawnUnitOnDeath
-					if !visited[path] {
-						spawnQueue = append(spawnQueue, path)
-						visited[path] = true
-					}
-				}
+		for _, ref := range spawnReferences(unit) {
+			if !visited[ref.path] {
+				spawnQueue = append(spawnQueue, ref.path)
+				visited[ref.path] = true
 			}
 		}
 	}
@@ -387,15 +526,16 @@ func (db *Database) discoverSpawnedUnits(verbose bool) {
 	}
 }
 
+// disabledUnits are tutorial/test units that are never accessible. markAccessible skips
+// them, so nothing else is reached through them or has them on its tech path.
+var disabledUnits = map[string]bool{
+	"tutorial_titan_commander": true,
+	"sea_mine":                 true,
+}
+
 // applyCorrections fixes known inconsistencies in PA unit data
 func (db *Database) applyCorrections() {
-	// Disable certain units (tutorial/test units)
-	disabled := []string{"tutorial_titan_commander", "sea_mine"}
-	for _, id := range disabled {
-		if unit, ok := db.Units[id]; ok {
-			unit.Accessible = false
-		}
-	}
+	// Disabled units (disabledUnits) are never reached by markAccessible
 
 	// Fix titan structure tier and type
 	if unit, ok := db.Units["titan_structure"]; ok {
@@ -429,27 +569,47 @@ func (db *Database) applyCorrections() {
 	}
 }
 
+// baseFactionTypes maps known faction unit type identifiers to display names
+var baseFactionTypes = map[string]string{
+	"Custom58": "MLA",
+	"Custom1":  "Legion",
+	"Custom2":  "Bugs",
+	"Custom6":  "Exiles",
+}
+
+// typeFaction returns the display name of the faction a unit type identifies (compared
+// case-insensitively), or "" if it isn't a known faction type.
+func typeFaction(unitType string) string {
+	for customType, displayName := range baseFactionTypes {
+		if strings.EqualFold(unitType, customType) {
+			return displayName
+		}
+	}
+	return ""
+}
+
+// unitFaction returns the faction of the first faction type among a unit's types, or "".
+func unitFaction(unitTypes []string) string {
+	for _, unitType := range unitTypes {
+		if faction := typeFaction(unitType); faction != "" {
+			return faction
+		}
+	}
+	return ""
+}
+
 // DetectBaseFactions analyzes loaded units and returns the display names of base factions found.
 // This is used for balance mods to identify which factions the mod adds units for.
 // Returns a sorted array of faction display names (e.g., ["Bugs", "Legion", "MLA"]).
 func (db *Database) DetectBaseFactions() []string {
-	// Map of known faction unit type identifiers to display names
-	factionMap := map[string]string{
-		"Custom58": "MLA",
-		"Custom1":  "Legion",
-		"Custom2":  "Bugs",
-		"Custom6":  "Exiles",
-	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
 
 	foundFactions := make(map[string]bool)
 	for _, unit := range db.Units {
 		for _, unitType := range unit.UnitTypes {
-			// Check case-insensitively
-			for customType, displayName := range factionMap {
-				if strings.EqualFold(unitType, customType) {
-					foundFactions[displayName] = true
-					break
-				}
+			if faction := typeFaction(unitType); faction != "" {
+				foundFactions[faction] = true
 			}
 		}
 	}
@@ -462,23 +622,163 @@ func (db *Database) DetectBaseFactions() []string {
 	return result
 }
 
-// FilterOutUnits removes units from this database whose IDs exist in the provided set.
-// Returns the count of units that were filtered out.
-// Used for addon mod extraction to remove base game units.
-func (db *Database) FilterOutUnits(unitIDs map[string]bool) int {
-	filteredCount := 0
-	for id := range db.Units {
-		if unitIDs[id] {
-			delete(db.Units, id)
-			filteredCount++
+// CrossFactionEdges returns the build relationships between the units in db (an addon's own
+// units, after FilterOutUnits removed the base units) and the units in base, tagged with each
+// base unit's faction. The addon units' relationships were built before the filter, so they
+// still name the base units. Edges are sorted by addon unit, relation and base unit.
+func (db *Database) CrossFactionEdges(base *Database) []models.CrossFactionEdge {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	base.mu.RLock()
+	defer base.mu.RUnlock()
+
+	var edges []models.CrossFactionEdge
+	add := func(unit, relation, baseID string) {
+		if _, own := db.Units[baseID]; own {
+			return
+		}
+		if baseUnit, ok := base.Units[baseID]; ok {
+			edges = append(edges, models.CrossFactionEdge{
+				Unit:        unit,
+				Relation:    relation,
+				BaseUnit:    baseID,
+				BaseFaction: unitFaction(baseUnit.UnitTypes),
+			})
+		}
+	}
+	for id, unit := range db.Units {
+		for _, builder := range unit.BuildRelationships.BuiltBy {
+			add(id, models.RelationBuiltBy, builder)
+		}
+		for _, built := range unit.BuildRelationships.Builds {
+			add(id, models.RelationBuilds, built)
 		}
 	}
-	return filteredCount
+
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.Unit != b.Unit {
+			return a.Unit < b.Unit
+		}
+		if a.Relation != b.Relation {
+			return a.Relation < b.Relation
+		}
+		return a.BaseUnit < b.BaseUnit
+	})
+	return edges
+}
+
+// Clone returns a copy of the database sharing the same loader. Each unit is copied
+// so top-level field changes (accessibility, relationships) don't leak between
+// copies; nested spec slices are still shared and must be treated as read-only.
+func (db *Database) Clone() *Database {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	clone := &Database{
+		Loader:            db.Loader,
+		Units:             make(map[string]*models.Unit, len(db.Units)),
+		Commanders:        append([]string(nil), db.Commanders...),
+		ExcludeCommanders: append([]string(nil), db.ExcludeCommanders...),
+	}
+	for id, unit := range db.Units {
+		copied := *unit
+		clone.Units[id] = &copied
+	}
+	return clone
+}
+
+// Subset returns a copy of the database (see Clone) holding only the units whose
+// IDs are in unitIDs.
+func (db *Database) Subset(unitIDs map[string]bool) *Database {
+	clone := db.Clone()
+	clone.FilterToUnits(unitIDs)
+	return clone
+}
+
+// UnitIDsForResources returns the IDs of units parsed from the given resource paths,
+// plus every unit they spawn (transitively) that is in the database. Used with
+// Loader.LoadBaseUnitList to find the base game units among an addon's parsed units.
+//
+// A spawn is only followed when the base game makes it: the spawning unit's specs all
+// resolve from pa/pa_ex1, or the spawned unit's own JSON does. A base unit a mod shadows
+// to spawn one of the mod's new units doesn't pull that unit into the set.
+func (db *Database) UnitIDsForResources(resourcePaths []string) map[string]bool {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	byResource := make(map[string]*models.Unit, len(db.Units))
+	for _, unit := range db.Units {
+		byResource[unit.ResourceName] = unit
+	}
+
+	ids := make(map[string]bool, len(resourcePaths))
+	queue := make([]*models.Unit, 0, len(resourcePaths))
+	for _, path := range resourcePaths {
+		if unit, ok := byResource[path]; ok && !ids[unit.ID] {
+			ids[unit.ID] = true
+			queue = append(queue, unit)
+		}
+	}
+
+	for len(queue) > 0 {
+		unit := queue[0]
+		queue = queue[1:]
+		baseSpecs := db.specsFromBaseGame(unit)
+		for _, ref := range spawnReferences(unit) {
+			spawned, ok := byResource[ref.path]
+			if !ok || ids[spawned.ID] {
+				continue
+			}
+			if !baseSpecs && !db.resolvesFromBaseGame(ref.path) {
+				continue
+			}
+			ids[spawned.ID] = true
+			queue = append(queue, spawned)
+		}
+	}
+
+	return ids
+}
+
+// specsFromBaseGame reports whether every spec file a unit is parsed from (its JSON, base
+// specs, tools and ammo) resolves from the base game or expansion. Without a loader every
+// unit counts as base.
+func (db *Database) specsFromBaseGame(unit *models.Unit) bool {
+	if db.Loader == nil {
+		return true
+	}
+	specs, err := db.Loader.GetReferencedSpecFiles(unit.ResourceName, false)
+	if err != nil || len(specs) == 0 {
+		return false
+	}
+	for _, spec := range specs {
+		if !isBaseGameSource(spec.Source) {
+			return false
+		}
+	}
+	return true
+}
+
+// resolvesFromBaseGame reports whether a resource resolves from the base game or expansion
+func (db *Database) resolvesFromBaseGame(resourcePath string) bool {
+	if db.Loader == nil {
+		return true
+	}
+	info := db.Loader.ResolveResource(resourcePath)
+	return info != nil && isBaseGameSource(info.Source)
+}
+
+func isBaseGameSource(source string) bool {
+	return source == "pa" || source == "pa_ex1"
 }
 
 // GetUnitIDs returns a set of all unit IDs in the database.
 // Used for building comparison sets in addon mod filtering.
 func (db *Database) GetUnitIDs() map[string]bool {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
 	ids := make(map[string]bool, len(db.Units))
 	for id := range db.Units {
 		ids[id] = true
@@ -486,27 +786,15 @@ func (db *Database) GetUnitIDs() map[string]bool {
 	return ids
 }
 
-// GetUnitsArray returns all units as an array (sorted by name)
-func (db *Database) GetUnitsArray() []models.Unit {
-	units := make([]models.Unit, 0, len(db.Units))
-	for _, unit := range db.Units {
-		units = append(units, *unit)
-	}
-
-	// Sort by tier, then by display name, then by ID for deterministic output.
-	// The ID tiebreaker is needed because sort.Slice is not stable and the input
-	// comes from map iteration (random order). Without it, units sharing the same
-	// tier and display name (e.g. bug_boomer/bug_boomer_r) swap between runs,
-	// causing spurious diffs in the update-factions workflow.
-	sort.Slice(units, func(i, j int) bool {
-		if units[i].Tier != units[j].Tier {
-			return units[i].Tier < units[j].Tier
-		}
-		if units[i].DisplayName != units[j].DisplayName {
-			return units[i].DisplayName < units[j].DisplayName
-		}
-		return units[i].ID < units[j].ID
-	})
+// Len returns the number of units in the database.
+func (db *Database) Len() int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return len(db.Units)
+}
 
-	return units
+// GetUnitsArray returns all units as an array (sorted by tier, name, then ID).
+// Equivalent to Snapshot().Units().
+func (db *Database) GetUnitsArray() []models.Unit {
+	return db.Snapshot().Units()
 }
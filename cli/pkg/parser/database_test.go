@@ -1,7 +1,9 @@
 package parser
 
 import (
+	"reflect"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/jamiemulcahy/pa-pedia/pkg/models"
@@ -444,3 +446,292 @@ func copyUnits(units map[string]*models.Unit) map[string]*models.Unit {
 	}
 	return copy
 }
+
+// TestMarkAccessible verifies accessibility follows builds, factory spawns and launch payloads,
+// and records the shortest route as the unit's reachability
+func TestMarkAccessible(t *testing.T) {
+	units := map[string]*models.Unit{
+		"commander": {
+			ID:                 "commander",
+			ResourceName:       "/pa/units/commanders/commander/commander.json",
+			UnitTypes:          []string{"Commander"},
+			BuildRelationships: models.BuildRelationships{Builds: []string{"factory", "launcher"}},
+		},
+		"factory": {
+			ID:                 "factory",
+			ResourceName:       "/pa/units/land/factory/factory.json",
+			BuildRelationships: models.BuildRelationships{Builds: []string{"tank"}},
+			Specs: models.UnitSpecs{
+				Economy: &models.EconomySpecs{BuildCost: 400},
+				Storage: &models.StorageSpecs{InitialBuildSpec: "/pa/units/land/grunt/grunt.json"},
+			},
+		},
+		"tank": {
+			ID:           "tank",
+			ResourceName: "/pa/units/land/tank/tank.json",
+			Specs: models.UnitSpecs{
+				Economy: &models.EconomySpecs{BuildCost: 150},
+				Special: &models.SpecialSpecs{SpawnUnitOnDeath: "/pa/units/land/wreck_bot/wreck_bot.json"},
+			},
+		},
+		"grunt":     {ID: "grunt", ResourceName: "/pa/units/land/grunt/grunt.json"},
+		"wreck_bot": {ID: "wreck_bot", ResourceName: "/pa/units/land/wreck_bot/wreck_bot.json"},
+		"launcher": {
+			ID:           "launcher",
+			ResourceName: "/pa/units/orbital/launcher/launcher.json",
+			Specs: models.UnitSpecs{Combat: &models.CombatSpecs{Weapons: []models.Weapon{{
+				BuildableAmmo: []models.Ammo{
+					{SpawnUnitOnDeath: "/pa/units/orbital/satellite/satellite.json"},
+				},
+			}}}},
+		},
+		"satellite": {ID: "satellite", ResourceName: "/pa/units/orbital/satellite/satellite.json"},
+		"orphan":    {ID: "orphan", ResourceName: "/pa/units/land/orphan/orphan.json"},
+	}
+
+	db := &Database{Units: units}
+	db.markAccessible(false)
+
+	tests := []struct {
+		id     string
+		method string
+		via    string
+	}{
+		{"commander", models.ReachCommander, ""},
+		{"factory", models.ReachBuilt, "commander"},
+		{"tank", models.ReachBuilt, "factory"},
+		{"grunt", models.ReachFactorySpawn, "factory"},
+		{"wreck_bot", models.ReachSpawnedOnDeath, "tank"},
+		{"satellite", models.ReachProjectile, "launcher"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			unit := units[tt.id]
+			if !unit.Accessible {
+				t.Fatalf("%s should be accessible", tt.id)
+			}
+			if unit.Reachability == nil {
+				t.Fatalf("%s should have a reachability explanation", tt.id)
+			}
+			if unit.Reachability.Method != tt.method || unit.Reachability.Via != tt.via {
+				t.Errorf("%s reachability = %+v, want method %q via %q", tt.id, *unit.Reachability, tt.method, tt.via)
+			}
+		})
+	}
+
+	if units["orphan"].Accessible || units["orphan"].Reachability != nil || units["orphan"].TechPath != nil {
+		t.Error("orphan should not be accessible")
+	}
+
+	pathTests := []struct {
+		id          string
+		path        []string
+		factoryCost float64
+	}{
+		{"commander", []string{"commander"}, 0},
+		{"factory", []string{"commander", "factory"}, 0},
+		{"tank", []string{"commander", "factory", "tank"}, 400},
+		{"wreck_bot", []string{"commander", "factory", "tank", "wreck_bot"}, 550},
+	}
+
+	for _, tt := range pathTests {
+		t.Run(tt.id+" tech path", func(t *testing.T) {
+			techPath := units[tt.id].TechPath
+			if techPath == nil {
+				t.Fatalf("%s should have a tech path", tt.id)
+			}
+			if strings.Join(techPath.Path, ">") != strings.Join(tt.path, ">") {
+				t.Errorf("%s path = %v, want %v", tt.id, techPath.Path, tt.path)
+			}
+			if techPath.FactoryCost != tt.factoryCost {
+				t.Errorf("%s factoryCost = %v, want %v", tt.id, techPath.FactoryCost, tt.factoryCost)
+			}
+		})
+	}
+}
+
+// TestMarkAccessibleSkipsDisabledUnits verifies a disabled unit isn't a route to anything:
+// what it builds is reached through another builder or not at all
+func TestMarkAccessibleSkipsDisabledUnits(t *testing.T) {
+	units := map[string]*models.Unit{
+		"commander": {
+			ID:                 "commander",
+			UnitTypes:          []string{"Commander"},
+			BuildRelationships: models.BuildRelationships{Builds: []string{"sea_mine", "factory"}},
+		},
+		"sea_mine": {
+			ID:                 "sea_mine",
+			BuildRelationships: models.BuildRelationships{Builds: []string{"tank", "drone"}},
+		},
+		"factory": {
+			ID:                 "factory",
+			BuildRelationships: models.BuildRelationships{Builds: []string{"tank"}},
+		},
+		"tank":  {ID: "tank"},
+		"drone": {ID: "drone"},
+	}
+
+	db := &Database{Units: units}
+	db.markAccessible(false)
+	db.applyCorrections()
+
+	for _, id := range []string{"sea_mine", "drone"} {
+		if u := units[id]; u.Accessible || u.Reachability != nil || u.TechPath != nil {
+			t.Errorf("%s should not be accessible, got reachability %+v", id, u.Reachability)
+		}
+	}
+	tank := units["tank"]
+	if !tank.Accessible || tank.Reachability == nil || tank.Reachability.Via != "factory" {
+		t.Fatalf("tank reachability = %+v, want via factory", tank.Reachability)
+	}
+	if got := strings.Join(tank.TechPath.Path, ">"); got != "commander>factory>tank" {
+		t.Errorf("tank path = %s, want commander>factory>tank", got)
+	}
+}
+
+// TestFindCommanders verifies the accessibility seed set honours pinned and excluded commanders
+func TestFindCommanders(t *testing.T) {
+	newUnits := func() map[string]*models.Unit {
+		return map[string]*models.Unit{
+			"commander":      {ID: "commander", DisplayName: "Commander", UnitTypes: []string{"Commander"}},
+			"alt_commander":  {ID: "alt_commander", DisplayName: "Alt Commander", UnitTypes: []string{"Commander"}},
+			"test_commander": {ID: "test_commander", DisplayName: "Test Commander", UnitTypes: []string{"Commander"}},
+			"tank":           {ID: "tank", DisplayName: "Tank", UnitTypes: []string{"Tank"}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		pinned   []string
+		excluded []string
+		expected []string
+	}{
+		{
+			name:     "default uses every commander",
+			expected: []string{"alt_commander", "commander", "test_commander"},
+		},
+		{
+			name:     "excluded commanders are skipped",
+			excluded: []string{"test_commander"},
+			expected: []string{"alt_commander", "commander"},
+		},
+		{
+			name:     "pinned commanders replace the default set",
+			pinned:   []string{"commander", "missing_commander"},
+			excluded: []string{"commander"},
+			expected: []string{"commander"},
+		},
+		{
+			name:     "pinned units need not be tagged Commander",
+			pinned:   []string{"tank"},
+			expected: []string{"tank"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &Database{Units: newUnits(), Commanders: tt.pinned, ExcludeCommanders: tt.excluded}
+
+			ids := make([]string, 0)
+			for _, unit := range db.findCommanders(false) {
+				ids = append(ids, unit.ID)
+			}
+			sort.Strings(ids)
+
+			if len(ids) != len(tt.expected) {
+				t.Fatalf("findCommanders() = %v, want %v", ids, tt.expected)
+			}
+			for i := range ids {
+				if ids[i] != tt.expected[i] {
+					t.Errorf("findCommanders() = %v, want %v", ids, tt.expected)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestCloneAndSubset(t *testing.T) {
+	db := &Database{
+		Units: map[string]*models.Unit{
+			"tank":       {ID: "tank", Accessible: true},
+			"addon_tank": {ID: "addon_tank", Accessible: true},
+		},
+		Commanders: []string{"commander"},
+	}
+
+	clone := db.Clone()
+	if len(clone.Units) != 2 {
+		t.Fatalf("Clone() has %d units, want 2", len(clone.Units))
+	}
+	clone.Units["tank"].Accessible = false
+	delete(clone.Units, "addon_tank")
+	clone.Commanders[0] = "other"
+	if !db.Units["tank"].Accessible || db.Units["addon_tank"] == nil || db.Commanders[0] != "commander" {
+		t.Error("changes to the clone leaked into the original database")
+	}
+
+	subset := db.Subset(map[string]bool{"addon_tank": true, "missing": true})
+	if len(subset.Units) != 1 || subset.Units["addon_tank"] == nil {
+		t.Errorf("Subset() units = %v, want only addon_tank", subset.GetUnitIDs())
+	}
+}
+
+func TestUnitIDsForResources(t *testing.T) {
+	db := &Database{
+		Units: map[string]*models.Unit{
+			"tank": {
+				ID:           "tank",
+				ResourceName: "/pa/units/land/tank/tank.json",
+				Specs:        models.UnitSpecs{Special: &models.SpecialSpecs{SpawnUnitOnDeath: "/pa/units/land/wreck/wreck.json"}},
+			},
+			"wreck": {
+				ID:           "wreck",
+				ResourceName: "/pa/units/land/wreck/wreck.json",
+				Specs:        models.UnitSpecs{Special: &models.SpecialSpecs{SpawnUnitOnDeath: "/pa/units/land/scrap/scrap.json"}},
+			},
+			"scrap":      {ID: "scrap", ResourceName: "/pa/units/land/scrap/scrap.json"},
+			"addon_tank": {ID: "addon_tank", ResourceName: "/pa/units/land/addon_tank/addon_tank.json"},
+		},
+	}
+
+	got := db.UnitIDsForResources([]string{"/pa/units/land/tank/tank.json", "/pa/units/land/not_parsed/not_parsed.json"})
+
+	want := map[string]bool{"tank": true, "wreck": true, "scrap": true}
+	if len(got) != len(want) {
+		t.Fatalf("UnitIDsForResources() = %v, want %v", got, want)
+	}
+	for id := range want {
+		if !got[id] {
+			t.Errorf("UnitIDsForResources() missing %s", id)
+		}
+	}
+}
+
+// TestCrossFactionEdges tests that an addon's relationships to filtered-out base units are
+// listed with the base unit's faction, and relationships between addon units are not
+func TestCrossFactionEdges(t *testing.T) {
+	base := &Database{Units: map[string]*models.Unit{
+		"fabrication_bot":   {UnitTypes: []string{"Custom58", "Mobile"}},
+		"l_fabrication_bot": {UnitTypes: []string{"Custom1", "Mobile"}},
+		"tank":              {UnitTypes: []string{"Land"}},
+	}}
+	addon := &Database{Units: map[string]*models.Unit{
+		"addon_factory": {BuildRelationships: models.BuildRelationships{
+			Builds:  []string{"addon_tank", "tank"},
+			BuiltBy: []string{"l_fabrication_bot", "fabrication_bot", "unknown_builder"},
+		}},
+		"addon_tank": {BuildRelationships: models.BuildRelationships{BuiltBy: []string{"addon_factory"}}},
+	}}
+
+	want := []models.CrossFactionEdge{
+		{Unit: "addon_factory", Relation: models.RelationBuilds, BaseUnit: "tank"},
+		{Unit: "addon_factory", Relation: models.RelationBuiltBy, BaseUnit: "fabrication_bot", BaseFaction: "MLA"},
+		{Unit: "addon_factory", Relation: models.RelationBuiltBy, BaseUnit: "l_fabrication_bot", BaseFaction: "Legion"},
+	}
+	got := addon.CrossFactionEdges(base)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CrossFactionEdges() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,159 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// effectFiles returns the resource paths in an effect_spec or effect_specs value. PA lists
+// effects as "<file> <bone> <file> <bone>…" strings (or arrays of them); bones are dropped
+// and repeated files kept once.
+func effectFiles(value interface{}) []string {
+	var specs []string
+	switch v := value.(type) {
+	case string:
+		specs = append(specs, v)
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				specs = append(specs, s)
+			}
+		}
+	}
+
+	var files []string
+	for _, spec := range specs {
+		for _, field := range strings.Fields(spec) {
+			if strings.HasPrefix(field, "/") && !containsString(files, field) {
+				files = append(files, field)
+			}
+		}
+	}
+	return files
+}
+
+// eventEffects returns the effect files and audio cue of one entry in a spec's events
+func eventEffects(event map[string]interface{}) ([]string, string) {
+	files := effectFiles(event["effect_spec"])
+	for _, f := range effectFiles(event["effect_specs"]) {
+		if !containsString(files, f) {
+			files = append(files, f)
+		}
+	}
+	return files, loader.GetString(event, "audio_cue", "")
+}
+
+// applyAmmoEffects reads a projectile's effect specs and audio cues over those inherited
+// from its base_spec
+func applyAmmoEffects(ammo *models.Ammo, data map[string]interface{}) {
+	var effects models.AmmoEffects
+	if ammo.Effects != nil {
+		effects = *ammo.Effects // Copied: the base spec's ammo shares the pointer
+	}
+
+	switch trail := data["fx_trail"].(type) {
+	case string:
+		effects.Trail = trail
+	case map[string]interface{}:
+		effects.Trail = loader.GetString(trail, "filename", effects.Trail)
+	}
+	effects.Beam = loader.GetString(data, "fx_beam_spec", effects.Beam)
+	effects.Collision = loader.GetString(data, "fx_collision_spec", effects.Collision)
+	effects.FlightAudio = loader.GetString(data, "audio_loop", effects.FlightAudio)
+	if events, ok := data["events"].(map[string]interface{}); ok {
+		if died, ok := events["died"].(map[string]interface{}); ok {
+			effects.Impact, effects.ImpactAudio = eventEffects(died)
+		}
+	}
+
+	ammo.Effects = nil
+	if effects.Trail != "" || effects.Beam != "" || effects.Collision != "" || len(effects.Impact) > 0 || effects.ImpactAudio != "" || effects.FlightAudio != "" {
+		ammo.Effects = &effects
+	}
+}
+
+// applyUnitEffects reads a unit's death effects and each weapon's firing effects from the
+// unit's events, merged down its base_spec chain (nearer specs win per event). A weapon gets
+// "fired<n>" for each position n its spec takes in the tools array, then the "fired" event
+// that plays for every weapon; the first audio cue found is kept.
+func applyUnitEffects(l *loader.Loader, resourceName string, unit *models.Unit) {
+	events := make(map[string]map[string]interface{})
+	var tools []interface{}
+	visited := make(map[string]bool)
+	for path := resourceName; path != "" && !visited[path]; {
+		visited[path] = true
+		data, err := l.GetJSON(path)
+		if err != nil {
+			break
+		}
+		if specEvents, ok := data["events"].(map[string]interface{}); ok {
+			for name, event := range specEvents {
+				if e, ok := event.(map[string]interface{}); ok && events[name] == nil {
+					events[name] = e
+				}
+			}
+		}
+		if tools == nil {
+			tools = loader.GetArray(data, "tools")
+		}
+		path = loader.GetString(data, "base_spec", "")
+	}
+
+	unit.Effects = nil
+	if died, ok := events["died"]; ok {
+		files, audio := eventEffects(died)
+		if len(files) > 0 || audio != "" {
+			unit.Effects = &models.UnitEffects{Death: files, DeathAudio: audio}
+		}
+	}
+
+	if unit.Specs.Combat == nil {
+		return
+	}
+	for i := range unit.Specs.Combat.Weapons {
+		w := &unit.Specs.Combat.Weapons[i]
+		w.Effects = nil
+		if w.DeathExplosion {
+			continue
+		}
+		var names []string
+		for n, t := range tools {
+			if tool, ok := t.(map[string]interface{}); ok && loader.GetString(tool, "spec_id", "") == w.ResourceName {
+				names = append(names, fmt.Sprintf("fired%d", n))
+			}
+		}
+		names = append(names, "fired")
+
+		var effects models.WeaponEffects
+		for _, name := range names {
+			event, ok := events[name]
+			if !ok {
+				continue
+			}
+			files, audio := eventEffects(event)
+			for _, f := range files {
+				if !containsString(effects.MuzzleFlash, f) {
+					effects.MuzzleFlash = append(effects.MuzzleFlash, f)
+				}
+			}
+			if effects.FireAudio == "" {
+				effects.FireAudio = audio
+			}
+		}
+		if len(effects.MuzzleFlash) > 0 || effects.FireAudio != "" {
+			w.Effects = &effects
+		}
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestEffectFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  []string
+	}{
+		{"nil", nil, nil},
+		{"file and bone", "/pa/effects/specs/flash.pfx socket_muzzle", []string{"/pa/effects/specs/flash.pfx"}},
+		{"several pairs", "/pa/a.pfx bone_a /pa/b.pfx bone_b /pa/a.pfx bone_c", []string{"/pa/a.pfx", "/pa/b.pfx"}},
+		{"array", []interface{}{"/pa/a.pfx bone", "/pa/b.pfx"}, []string{"/pa/a.pfx", "/pa/b.pfx"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := effectFiles(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("effectFiles(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseEffects verifies death and firing effects come through base_spec, that fired<n>
+// events follow the tool positions, and that ammo effects are overridden field by field
+func TestParseEffects(t *testing.T) {
+	paRoot := t.TempDir()
+	dir := "pa/units/land/tank/"
+	writeSpecFiles(t, paRoot, map[string]string{
+		"pa/units/land/base_vehicle/base_vehicle.json": `{
+			"events": {
+				"died": {"effect_spec": "/pa/effects/specs/default_explosion.pfx", "audio_cue": "/SE/Impacts/vehicle_explode"},
+				"fired": {"audio_cue": "/SE/Weapons/base_fire"}
+			}
+		}`,
+		dir + "tank.json": `{
+			"base_spec": "/pa/units/land/base_vehicle/base_vehicle.json",
+			"tools": [
+				{"spec_id": "/pa/units/land/tank/cannon.json"},
+				{"spec_id": "/pa/units/land/tank/mg.json"},
+				{"spec_id": "/pa/units/land/tank/mg.json"}
+			],
+			"events": {
+				"fired0": {"effect_spec": "/pa/effects/specs/cannon_flash.pfx socket_muzzle", "audio_cue": "/SE/Weapons/cannon"},
+				"fired1": {"effect_specs": ["/pa/effects/specs/mg_flash.pfx bone_l"]},
+				"fired2": {"effect_spec": "/pa/effects/specs/mg_flash.pfx bone_r"}
+			}
+		}`,
+		dir + "cannon.json":    `{"tool_type": "TOOL_Weapon", "rate_of_fire": 1, "ammo_id": "/pa/units/land/tank/shell.json"}`,
+		dir + "mg.json":        `{"tool_type": "TOOL_Weapon", "rate_of_fire": 5, "ammo_id": "/pa/units/land/tank/bullet.json"}`,
+		dir + "base_ammo.json": `{"fx_trail": {"filename": "/pa/effects/specs/trail.pfx"}, "events": {"died": {"effect_spec": "/pa/effects/specs/hit.pfx", "audio_cue": "/SE/Impacts/hit"}}}`,
+		dir + "shell.json":     `{"base_spec": "/pa/units/land/tank/base_ammo.json", "damage": 50, "fx_collision_spec": "/pa/effects/specs/shell_hit.pfx"}`,
+		dir + "bullet.json":    `{"damage": 5}`,
+	})
+
+	l, err := loader.NewMultiSourceLoader(paRoot, "pa_ex1", nil)
+	if err != nil {
+		t.Fatalf("failed to create loader: %v", err)
+	}
+	defer l.Close()
+
+	unit, err := ParseUnit(l, "/pa/units/land/tank/tank.json", nil)
+	if err != nil {
+		t.Fatalf("ParseUnit failed: %v", err)
+	}
+
+	wantUnit := &models.UnitEffects{Death: []string{"/pa/effects/specs/default_explosion.pfx"}, DeathAudio: "/SE/Impacts/vehicle_explode"}
+	if !reflect.DeepEqual(unit.Effects, wantUnit) {
+		t.Errorf("unit effects = %+v, want %+v", unit.Effects, wantUnit)
+	}
+
+	wantWeapons := map[string]*models.WeaponEffects{
+		"cannon": {MuzzleFlash: []string{"/pa/effects/specs/cannon_flash.pfx"}, FireAudio: "/SE/Weapons/cannon"},
+		"mg":     {MuzzleFlash: []string{"/pa/effects/specs/mg_flash.pfx"}, FireAudio: "/SE/Weapons/base_fire"},
+	}
+	wantAmmo := map[string]*models.AmmoEffects{
+		"cannon": {
+			Trail:       "/pa/effects/specs/trail.pfx",
+			Collision:   "/pa/effects/specs/shell_hit.pfx",
+			Impact:      []string{"/pa/effects/specs/hit.pfx"},
+			ImpactAudio: "/SE/Impacts/hit",
+		},
+		"mg": nil,
+	}
+	for _, w := range unit.Specs.Combat.Weapons {
+		if !reflect.DeepEqual(w.Effects, wantWeapons[w.Name]) {
+			t.Errorf("weapon %s effects = %+v, want %+v", w.Name, w.Effects, wantWeapons[w.Name])
+		}
+		if !reflect.DeepEqual(w.Ammo.Effects, wantAmmo[w.Name]) {
+			t.Errorf("weapon %s ammo effects = %+v, want %+v", w.Name, w.Ammo.Effects, wantAmmo[w.Name])
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"math"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// falloffSteps is how many samples the linear part of a splash falloff gets
+const falloffSteps = 4
+
+// damageFalloff approximates a splash weapon's damage against distance from the impact point.
+// The directly hit target takes the direct damage plus full splash damage; other units take
+// full splash damage out to fullDamageRadius, falling linearly to nothing at splashRadius.
+// The curve starts with the direct hit, then samples the splash profile from the impact point
+// to splashRadius. Weapons without splash get no curve.
+func damageFalloff(w *models.Weapon) []models.FalloffPoint {
+	if w.SplashDamage <= 0 || w.SplashRadius <= 0 {
+		return nil
+	}
+	full := min(w.FullDamageRadius, w.SplashRadius)
+	point := func(distance, damage float64) models.FalloffPoint {
+		return models.FalloffPoint{
+			Distance: roundFalloff(distance),
+			Damage:   roundFalloff(damage),
+			DPS:      roundFalloff(damage * w.ROF * float64(w.ProjectilesPerFire)),
+		}
+	}
+
+	direct := point(0, w.Damage+w.SplashDamage)
+	direct.DirectHit = true
+	curve := []models.FalloffPoint{direct, point(0, w.SplashDamage)}
+	if full > 0 {
+		curve = append(curve, point(full, w.SplashDamage))
+	}
+	for i := 1; i <= falloffSteps && full < w.SplashRadius; i++ {
+		distance := full + (w.SplashRadius-full)*float64(i)/falloffSteps
+		curve = append(curve, point(distance, w.SplashDamage*(w.SplashRadius-distance)/(w.SplashRadius-full)))
+	}
+	return curve
+}
+
+func roundFalloff(v float64) float64 {
+	return math.Round(v*100) / 100
+}
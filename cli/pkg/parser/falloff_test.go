@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestDamageFalloff(t *testing.T) {
+	tests := []struct {
+		name   string
+		weapon models.Weapon
+		want   []models.FalloffPoint
+	}{
+		{
+			name:   "no splash",
+			weapon: models.Weapon{Damage: 100, ROF: 1, ProjectilesPerFire: 1},
+		},
+		{
+			name:   "full damage radius then linear falloff",
+			weapon: models.Weapon{Damage: 100, SplashDamage: 50, FullDamageRadius: 2, SplashRadius: 10, ROF: 2, ProjectilesPerFire: 1},
+			want: []models.FalloffPoint{
+				{Distance: 0, Damage: 150, DPS: 300, DirectHit: true},
+				{Distance: 0, Damage: 50, DPS: 100},
+				{Distance: 2, Damage: 50, DPS: 100},
+				{Distance: 4, Damage: 37.5, DPS: 75},
+				{Distance: 6, Damage: 25, DPS: 50},
+				{Distance: 8, Damage: 12.5, DPS: 25},
+				{Distance: 10, Damage: 0, DPS: 0},
+			},
+		},
+		{
+			name:   "falloff from the impact point",
+			weapon: models.Weapon{SplashDamage: 40, SplashRadius: 8, ROF: 0.5, ProjectilesPerFire: 3},
+			want: []models.FalloffPoint{
+				{Distance: 0, Damage: 40, DPS: 60, DirectHit: true},
+				{Distance: 0, Damage: 40, DPS: 60},
+				{Distance: 2, Damage: 30, DPS: 45},
+				{Distance: 4, Damage: 20, DPS: 30},
+				{Distance: 6, Damage: 10, DPS: 15},
+				{Distance: 8, Damage: 0, DPS: 0},
+			},
+		},
+		{
+			name:   "full damage across the whole radius",
+			weapon: models.Weapon{Damage: 10, SplashDamage: 20, FullDamageRadius: 5, SplashRadius: 5, ROF: 1, ProjectilesPerFire: 1},
+			want: []models.FalloffPoint{
+				{Distance: 0, Damage: 30, DPS: 30, DirectHit: true},
+				{Distance: 0, Damage: 20, DPS: 20},
+				{Distance: 5, Damage: 20, DPS: 20},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := damageFalloff(&tt.weapon); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("damageFalloff() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package parser
+
+import "github.com/jamiemulcahy/pa-pedia/pkg/models"
+
+// UnitFilter reports whether a unit matches. Filters select units for
+// Database.FilterOut (remove matches) and Database.FilterTo (keep only matches).
+type UnitFilter func(unit *models.Unit) bool
+
+// ByIDs matches units whose IDs are in the set.
+func ByIDs(unitIDs map[string]bool) UnitFilter {
+	return func(unit *models.Unit) bool {
+		return unitIDs[unit.ID]
+	}
+}
+
+// ByUnitType matches units tagged with a unit type (case-insensitive, without UNITTYPE_).
+func ByUnitType(unitType string) UnitFilter {
+	return func(unit *models.Unit) bool {
+		return unitMatchesFactionType(unit, unitType)
+	}
+}
+
+// ByRestriction matches units satisfying a buildable_types expression,
+// e.g. "Mobile & (Land | Naval) - Commander".
+func ByRestriction(expression string) UnitFilter {
+	restriction := ParseRestriction(expression)
+	return restriction.Satisfies
+}
+
+// Not inverts a filter.
+func Not(filter UnitFilter) UnitFilter {
+	return func(unit *models.Unit) bool {
+		return !filter(unit)
+	}
+}
+
+// FilterOut removes every unit the filter matches and returns how many were removed.
+func (db *Database) FilterOut(filter UnitFilter) int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	removed := 0
+	for id, unit := range db.Units {
+		if filter(unit) {
+			delete(db.Units, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// FilterTo keeps only the units the filter matches and returns how many were removed.
+func (db *Database) FilterTo(filter UnitFilter) int {
+	return db.FilterOut(Not(filter))
+}
+
+// FilterOutUnits removes units whose IDs exist in the provided set.
+// Returns the count of units that were filtered out.
+// Used for addon mod extraction to remove base game units.
+func (db *Database) FilterOutUnits(unitIDs map[string]bool) int {
+	return db.FilterOut(ByIDs(unitIDs))
+}
+
+// FilterToUnits keeps only units whose IDs exist in the provided set.
+// Returns the count of units that were filtered out.
+func (db *Database) FilterToUnits(unitIDs map[string]bool) int {
+	return db.FilterTo(ByIDs(unitIDs))
+}
@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func filterTestUnits() map[string]*models.Unit {
+	return map[string]*models.Unit{
+		"tank":       {ID: "tank", UnitTypes: []string{"Custom58", "Mobile", "Land", "Tank", "Basic"}},
+		"fighter":    {ID: "fighter", UnitTypes: []string{"Custom58", "Mobile", "Air", "Basic"}},
+		"factory":    {ID: "factory", UnitTypes: []string{"Custom58", "Structure", "Factory", "Basic"}},
+		"l_tank":     {ID: "l_tank", UnitTypes: []string{"Custom1", "Mobile", "Land", "Tank", "Basic"}},
+		"commander":  {ID: "commander", UnitTypes: []string{"Commander", "Mobile", "Land"}},
+		"addon_tank": {ID: "addon_tank", UnitTypes: []string{"Custom58", "Mobile", "Land", "Advanced"}},
+	}
+}
+
+func TestFilterOutAndFilterTo(t *testing.T) {
+	tests := []struct {
+		name        string
+		filter      UnitFilter
+		wantOut     []string // Remaining after FilterOut
+		wantTo      []string // Remaining after FilterTo
+		wantRemoved int      // Removed by FilterOut
+	}{
+		{
+			name:        "by IDs",
+			filter:      ByIDs(map[string]bool{"tank": true, "fighter": true, "missing": true}),
+			wantOut:     []string{"addon_tank", "commander", "factory", "l_tank"},
+			wantTo:      []string{"fighter", "tank"},
+			wantRemoved: 2,
+		},
+		{
+			name:        "by unit type",
+			filter:      ByUnitType("custom1"),
+			wantOut:     []string{"addon_tank", "commander", "factory", "fighter", "tank"},
+			wantTo:      []string{"l_tank"},
+			wantRemoved: 1,
+		},
+		{
+			name:        "by restriction",
+			filter:      ByRestriction("Mobile & (Land | Air) & Basic - Custom1"),
+			wantOut:     []string{"addon_tank", "commander", "factory", "l_tank"},
+			wantTo:      []string{"fighter", "tank"},
+			wantRemoved: 2,
+		},
+		{
+			name:        "by predicate",
+			filter:      func(u *models.Unit) bool { return strings.HasPrefix(u.ID, "addon_") },
+			wantOut:     []string{"commander", "factory", "fighter", "l_tank", "tank"},
+			wantTo:      []string{"addon_tank"},
+			wantRemoved: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &Database{Units: filterTestUnits()}
+			if removed := out.FilterOut(tt.filter); removed != tt.wantRemoved {
+				t.Errorf("FilterOut() removed %d, want %d", removed, tt.wantRemoved)
+			}
+			assertUnitIDs(t, "FilterOut", out, tt.wantOut)
+
+			to := &Database{Units: filterTestUnits()}
+			total := len(to.Units)
+			if removed := to.FilterTo(tt.filter); removed != total-len(tt.wantTo) {
+				t.Errorf("FilterTo() removed %d, want %d", removed, total-len(tt.wantTo))
+			}
+			assertUnitIDs(t, "FilterTo", to, tt.wantTo)
+		})
+	}
+}
+
+func TestFilterToUnits(t *testing.T) {
+	db := &Database{Units: filterTestUnits()}
+	removed := db.FilterToUnits(map[string]bool{"tank": true, "commander": true})
+	if removed != 4 {
+		t.Errorf("FilterToUnits() removed %d, want 4", removed)
+	}
+	assertUnitIDs(t, "FilterToUnits", db, []string{"commander", "tank"})
+}
+
+func assertUnitIDs(t *testing.T, label string, db *Database, want []string) {
+	t.Helper()
+	got := make([]string, 0, len(db.Units))
+	for id := range db.Units {
+		got = append(got, id)
+	}
+	sort.Strings(got)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("after %s units = %v, want %v", label, got, want)
+	}
+}
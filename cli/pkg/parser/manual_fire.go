@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"math"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// applyManualFire marks weapons the player fires by hand: manual_fire weapons (uber cannons,
+// nukes) fire on command and self-destruct weapons on the unit's self-destruct order. The
+// flag is inherited through base_spec like the weapon's other fields. Anti-nukes are
+// manual_fire too, but their anti_entity auto task fires them at incoming missiles.
+func applyManualFire(weapon *models.Weapon, data map[string]interface{}) {
+	inherited := weapon.ManualFire != nil && weapon.ManualFire.Trigger == models.ManualFireCommand
+	switch {
+	case len(weapon.AntiEntityTargets) > 0 || loader.GetString(data, "auto_task_type", "") == "anti_entity":
+		weapon.ManualFire = nil
+	case loader.GetBool(data, "manual_fire", inherited):
+		weapon.ManualFire = &models.ManualFire{Trigger: models.ManualFireCommand}
+	case weapon.SelfDestruct:
+		weapon.ManualFire = &models.ManualFire{Trigger: models.ManualFireSelfDestruct}
+	default:
+		weapon.ManualFire = nil
+	}
+}
+
+// priceManualFire fills in what each shot of a unit's manual-fire, factory-sourced weapons
+// costs: the missile's metal, and the time and energy the unit's own build arms take to build
+// it. Runs once the unit's build arms and factory storage are parsed.
+func priceManualFire(unit *models.Unit) {
+	if unit.Specs.Combat == nil {
+		return
+	}
+	var metalRate, energyRate float64
+	if unit.Specs.Economy != nil {
+		for _, arm := range unit.Specs.Economy.BuildArms {
+			metalRate += arm.MetalConsumption * float64(arm.Count)
+			energyRate += arm.EnergyConsumption * float64(arm.Count)
+		}
+	}
+
+	for i := range unit.Specs.Combat.Weapons {
+		w := &unit.Specs.Combat.Weapons[i]
+		if w.ManualFire == nil || w.ManualFire.Trigger != models.ManualFireCommand || w.AmmoSource != "factory" {
+			continue
+		}
+		manual := &models.ManualFire{Trigger: models.ManualFireCommand, RequiresAmmoBuild: true}
+		if w.Ammo != nil {
+			manual.AmmoMetalCost = w.Ammo.MetalCost
+		}
+		if metalRate > 0 && manual.AmmoMetalCost > 0 {
+			buildTime := manual.AmmoMetalCost / metalRate
+			manual.AmmoBuildTime = math.Round(buildTime*100) / 100
+			manual.AmmoEnergyCost = math.Round(buildTime*energyRate*100) / 100
+		}
+		if s := unit.Specs.Storage; s != nil && s.StoredUnitType == "missile" {
+			manual.AmmoStorage = s.UnitStorage
+		}
+		// Weapons share their parsed ManualFire with the base spec, so replace it rather than
+		// writing through it
+		w.ManualFire = manual
+	}
+}
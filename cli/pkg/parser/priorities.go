@@ -0,0 +1,52 @@
+package parser
+
+import "github.com/jamiemulcahy/pa-pedia/pkg/models"
+
+// Representative unit types, matched against target_priorities to tell what a weapon goes
+// for first. Enemies can come from any faction, so these are the stock PA types rather than
+// the exporting faction's units.
+var (
+	priorityFabber    = &models.Unit{UnitTypes: []string{"Mobile", "Land", "Bot", "Fabber", "Construction", "Basic"}}
+	priorityCommander = &models.Unit{UnitTypes: []string{"Mobile", "Land", "Commander", "Construction"}}
+	priorityTank      = &models.Unit{UnitTypes: []string{"Mobile", "Land", "Tank", "Basic"}}
+	priorityBot       = &models.Unit{UnitTypes: []string{"Mobile", "Land", "Bot", "Basic"}}
+	priorityNaval     = &models.Unit{UnitTypes: []string{"Mobile", "Naval", "Basic"}}
+	priorityAir       = &models.Unit{UnitTypes: []string{"Mobile", "Air", "Fighter", "Basic"}}
+	priorityBomber    = &models.Unit{UnitTypes: []string{"Mobile", "Air", "Bomber", "Basic"}}
+	priorityDefense   = &models.Unit{UnitTypes: []string{"Structure", "Land", "Defense", "Basic"}}
+	priorityEconomy   = &models.Unit{UnitTypes: []string{"Structure", "Land", "Economy", "Basic"}}
+)
+
+// priorityTags buckets a weapon's target_priorities by what its first priority goes for:
+// builders (anti-fabber), aircraft (anti-air-first) or structures (structure-first). A
+// bucket only applies when the first priority leaves out ordinary ground combat units, so
+// "Mobile" or "Mobile | Structure" get no tag. Later priorities are fallbacks and don't count.
+func priorityTags(priorities []string) []string {
+	if len(priorities) == 0 {
+		return nil
+	}
+	first := ParseRestriction(priorities[0])
+	matches := func(units ...*models.Unit) bool {
+		for _, u := range units {
+			if first.Satisfies(u) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if matches(priorityTank, priorityBot, priorityNaval) {
+		return nil
+	}
+	var tags []string
+	if matches(priorityFabber, priorityCommander) {
+		tags = append(tags, models.PriorityAntiFabber)
+	}
+	if matches(priorityAir, priorityBomber) {
+		tags = append(tags, models.PriorityAntiAirFirst)
+	}
+	if matches(priorityDefense, priorityEconomy) {
+		tags = append(tags, models.PriorityStructureFirst)
+	}
+	return tags
+}
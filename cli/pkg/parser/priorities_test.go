@@ -0,0 +1,32 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPriorityTags(t *testing.T) {
+	tests := []struct {
+		name       string
+		priorities []string
+		want       []string
+	}{
+		{"none", nil, nil},
+		{"fabbers first", []string{"Fabber | Commander", "Mobile"}, []string{"anti-fabber"}},
+		{"commanders only", []string{"Commander"}, []string{"anti-fabber"}},
+		{"air first", []string{"Air & Mobile", "Mobile"}, []string{"anti-air-first"}},
+		{"structures first", []string{"Structure - Wall", "Mobile"}, []string{"structure-first"}},
+		{"defenses first", []string{"Defense"}, []string{"structure-first"}},
+		{"ground units first", []string{"Mobile - Air", "Structure"}, nil},
+		{"everything mobile", []string{"Mobile"}, nil},
+		{"mixed first priority", []string{"Air | Structure"}, []string{"anti-air-first", "structure-first"}},
+		{"only later priorities count", []string{"Naval", "Air"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := priorityTags(tt.priorities); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("priorityTags(%q) = %v, want %v", tt.priorities, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// Relink recomputes the build tree of already-parsed units, such as units read back from
+// two exports and combined: build relationships from each unit's buildableTypes, then
+// accessibility, reachability and tech paths from the commanders (or every Commander-tagged
+// unit when commanders is empty). Spawned units aren't looked up, so they must be among
+// units. The units are returned in export order; the input is left unchanged.
+func Relink(units []models.Unit, commanders []string, verbose bool) ([]models.Unit, error) {
+	db := NewDatabase(nil)
+	db.Commanders = commanders
+
+	all := make([]*models.Unit, 0, len(units))
+	for i := range units {
+		unit := units[i]
+		unit.BuildRelationships = models.BuildRelationships{}
+		unit.Accessible = false
+		unit.Reachability = nil
+		unit.TechPath = nil
+		unit.Warnings = append([]string(nil), unit.Warnings...)
+		all = append(all, &unit)
+	}
+
+	if err := db.buildBuildTree(all, verbose); err != nil {
+		return nil, fmt.Errorf("failed to build build tree: %w", err)
+	}
+	db.markAccessible(verbose)
+	return db.Snapshot().Units(), nil
+}
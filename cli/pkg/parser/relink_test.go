@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// TestRelink tests that combined units get fresh build relationships, accessibility and
+// tech paths, and that stale ones from the exports they came from are dropped
+func TestRelink(t *testing.T) {
+	unit := func(id string, types []string, buildable string) models.Unit {
+		return models.Unit{
+			ID:             id,
+			ResourceName:   "/pa/units/" + id + "/" + id + ".json",
+			DisplayName:    id,
+			Tier:           1,
+			UnitTypes:      types,
+			BuildableTypes: buildable,
+			Specs:          models.UnitSpecs{Economy: &models.EconomySpecs{BuildCost: 100}},
+		}
+	}
+	commander := unit("commander", []string{"Custom58", "Commander", "Mobile"}, "Custom58 & Structure")
+	factory := unit("factory", []string{"Custom58", "Structure", "Factory"}, "Custom58 & Mobile - Commander")
+	tank := unit("tank", []string{"Custom58", "Mobile", "Tank"}, "")
+	// From the addon: built by the base factory, and a unit for another faction
+	addonTank := unit("addon_tank", []string{"Custom58", "Mobile", "Tank"}, "")
+	addonTank.Specs.Economy = nil
+	addonTank.BuildRelationships.BuiltBy = []string{"some_base_fabber"}
+	legionTank := unit("legion_tank", []string{"Custom1", "Mobile", "Tank"}, "")
+	legionTank.Accessible = true
+
+	input := []models.Unit{commander, factory, tank, addonTank, legionTank}
+	units, err := Relink(input, []string{"commander"}, false)
+	if err != nil {
+		t.Fatalf("Relink failed: %v", err)
+	}
+	byID := make(map[string]models.Unit, len(units))
+	for _, u := range units {
+		byID[u.ID] = u
+	}
+
+	builds := byID["factory"].BuildRelationships.Builds
+	if len(builds) != 2 || !slices.Contains(builds, "tank") || !slices.Contains(builds, "addon_tank") {
+		t.Errorf("factory builds = %v, want tank and addon_tank", builds)
+	}
+	if got := byID["addon_tank"].BuildRelationships.BuiltBy; !reflect.DeepEqual(got, []string{"factory"}) {
+		t.Errorf("addon_tank builtBy = %v, want [factory]", got)
+	}
+	if got := byID["addon_tank"].TechPath; got == nil || !reflect.DeepEqual(got.Path, []string{"commander", "factory", "addon_tank"}) {
+		t.Errorf("addon_tank techPath = %+v", got)
+	}
+	if byID["legion_tank"].Accessible || byID["legion_tank"].Reachability != nil {
+		t.Error("legion_tank should not be accessible from an MLA commander")
+	}
+	if input[3].BuildRelationships.BuiltBy[0] != "some_base_fabber" || !input[4].Accessible {
+		t.Error("Relink modified its input")
+	}
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/jamiemulcahy/pa-pedia/pkg/models"
@@ -9,6 +10,8 @@ import (
 // Restriction represents a buildable type restriction
 type Restriction interface {
 	Satisfies(unit *models.Unit) bool
+	// Node returns the restriction as a JSON-serializable expression tree
+	Node() *models.RestrictionNode
 }
 
 // SimpleRestriction checks for a single unit type
@@ -25,6 +28,10 @@ func (r *SimpleRestriction) Satisfies(unit *models.Unit) bool {
 	return false
 }
 
+func (r *SimpleRestriction) Node() *models.RestrictionNode {
+	return &models.RestrictionNode{Op: models.RestrictionType, Type: r.Category}
+}
+
 // CompoundAnd checks that both restrictions are satisfied
 type CompoundAnd struct {
 	Left  Restriction
@@ -35,6 +42,10 @@ func (r *CompoundAnd) Satisfies(unit *models.Unit) bool {
 	return r.Left.Satisfies(unit) && r.Right.Satisfies(unit)
 }
 
+func (r *CompoundAnd) Node() *models.RestrictionNode {
+	return &models.RestrictionNode{Op: models.RestrictionAnd, Left: r.Left.Node(), Right: r.Right.Node()}
+}
+
 // CompoundOr checks that at least one restriction is satisfied
 type CompoundOr struct {
 	Left  Restriction
@@ -45,6 +56,10 @@ func (r *CompoundOr) Satisfies(unit *models.Unit) bool {
 	return r.Left.Satisfies(unit) || r.Right.Satisfies(unit)
 }
 
+func (r *CompoundOr) Node() *models.RestrictionNode {
+	return &models.RestrictionNode{Op: models.RestrictionOr, Left: r.Left.Node(), Right: r.Right.Node()}
+}
+
 // CompoundMinus checks that left is satisfied but right is not
 type CompoundMinus struct {
 	Left  Restriction
@@ -55,6 +70,29 @@ func (r *CompoundMinus) Satisfies(unit *models.Unit) bool {
 	return r.Left.Satisfies(unit) && !r.Right.Satisfies(unit)
 }
 
+func (r *CompoundMinus) Node() *models.RestrictionNode {
+	return &models.RestrictionNode{Op: models.RestrictionMinus, Left: r.Left.Node(), Right: r.Right.Node()}
+}
+
+// RestrictionFromNode rebuilds a Restriction from an expression tree, e.g. one read back
+// from an exported unit. Unknown node kinds and missing operands match nothing.
+func RestrictionFromNode(node *models.RestrictionNode) Restriction {
+	if node == nil {
+		return &SimpleRestriction{}
+	}
+	switch node.Op {
+	case models.RestrictionType:
+		return &SimpleRestriction{Category: node.Type}
+	case models.RestrictionAnd:
+		return &CompoundAnd{Left: RestrictionFromNode(node.Left), Right: RestrictionFromNode(node.Right)}
+	case models.RestrictionOr:
+		return &CompoundOr{Left: RestrictionFromNode(node.Left), Right: RestrictionFromNode(node.Right)}
+	case models.RestrictionMinus:
+		return &CompoundMinus{Left: RestrictionFromNode(node.Left), Right: RestrictionFromNode(node.Right)}
+	}
+	return &SimpleRestriction{}
+}
+
 // Token represents either a simple string token or a nested group from parentheses
 type Token struct {
 	Value    string  // For simple tokens (operators or category names)
@@ -68,13 +106,43 @@ func (t Token) IsGroup() bool {
 
 // ParseRestriction parses a buildable_types string into a Restriction
 // Example: "(Mobile | Air) & Basic" means mobile or air units that are also basic tier
+// Malformed expressions never panic; they parse as far as they can (see ParseRestrictionDiagnostics).
 func ParseRestriction(text string) Restriction {
-	tokens := tokenize(text)
-	return parseTokens(tokens)
+	restriction, _ := ParseRestrictionDiagnostics(text)
+	return restriction
+}
+
+// ParseRestrictionDiagnostics parses like ParseRestriction and also describes anything malformed
+// in the expression: unbalanced or empty parentheses, operators missing an operand, and unit
+// types with no operator between them. Mod data in the wild has all of these, so they are
+// reported rather than failing the export. Recovery is lenient: an unclosed "(" closes at the
+// end, a stray ")" is dropped, a missing operand matches nothing, and of adjacent unit types
+// only the first is used.
+func ParseRestrictionDiagnostics(text string) (Restriction, []string) {
+	var diags diagnostics
+	tokens := tokenizeDiag(text, &diags)
+	if len(tokens) == 0 {
+		diags.add("empty expression")
+		return &SimpleRestriction{Category: ""}, diags
+	}
+	return parseTokensDiag(tokens, &diags), diags
+}
+
+// diagnostics collects parse problems; a nil *diagnostics discards them
+type diagnostics []string
+
+func (d *diagnostics) add(format string, args ...interface{}) {
+	if d != nil {
+		*d = append(*d, fmt.Sprintf(format, args...))
+	}
 }
 
 // tokenize converts a restriction string into a slice of tokens with nested structure for parentheses
 func tokenize(text string) []Token {
+	return tokenizeDiag(text, nil)
+}
+
+func tokenizeDiag(text string, diags *diagnostics) []Token {
 	special := map[rune]bool{
 		'|': true, '&': true, '-': true, '(': true, ')': true, ' ': true,
 	}
@@ -109,33 +177,44 @@ func tokenize(text string) []Token {
 			current = []Token{}
 		case ")":
 			// Pop from stack and add current as a nested group
-			if len(stack) > 0 {
-				parent := stack[len(stack)-1]
-				stack = stack[:len(stack)-1]
-				parent = append(parent, Token{Children: current})
-				current = parent
+			if len(stack) == 0 {
+				diags.add("unmatched ')' ignored")
+				continue
 			}
+			parent := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			parent = append(parent, Token{Children: current})
+			current = parent
 		default:
 			// Regular token (operator or category name)
 			current = append(current, Token{Value: tok})
 		}
 	}
 
+	// Close any groups left open so their contents aren't lost
+	if len(stack) > 0 {
+		diags.add("%d unclosed '(' closed at end of expression", len(stack))
+	}
+	for len(stack) > 0 {
+		parent := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		current = append(parent, Token{Children: current})
+	}
+
 	return current
 }
 
 // parseTokens recursively parses tokens into a Restriction tree
 func parseTokens(tokens []Token) Restriction {
-	// Handle empty input
-	if len(tokens) == 0 {
-		return &SimpleRestriction{Category: ""}
-	}
+	return parseTokensDiag(tokens, nil)
+}
 
+func parseTokensDiag(tokens []Token, diags *diagnostics) Restriction {
 	// Handle OR (lowest precedence) - find first OR not inside a group
 	for i, token := range tokens {
 		if !token.IsGroup() && token.Value == "|" {
-			left := parseTokens(tokens[:i])
-			right := parseTokens(tokens[i+1:])
+			left := parseOperand(tokens[:i], "|", diags)
+			right := parseOperand(tokens[i+1:], "|", diags)
 			return &CompoundOr{Left: left, Right: right}
 		}
 	}
@@ -143,8 +222,8 @@ func parseTokens(tokens []Token) Restriction {
 	// Handle AND (medium precedence)
 	for i, token := range tokens {
 		if !token.IsGroup() && token.Value == "&" {
-			left := parseTokens(tokens[:i])
-			right := parseTokens(tokens[i+1:])
+			left := parseOperand(tokens[:i], "&", diags)
+			right := parseOperand(tokens[i+1:], "&", diags)
 			return &CompoundAnd{Left: left, Right: right}
 		}
 	}
@@ -152,24 +231,46 @@ func parseTokens(tokens []Token) Restriction {
 	// Handle MINUS (highest precedence, right-associative)
 	for i := len(tokens) - 1; i >= 0; i-- {
 		if !tokens[i].IsGroup() && tokens[i].Value == "-" {
-			left := parseTokens(tokens[:i])
-			right := parseTokens(tokens[i+1:])
+			left := parseOperand(tokens[:i], "-", diags)
+			right := parseOperand(tokens[i+1:], "-", diags)
 			return &CompoundMinus{Left: left, Right: right}
 		}
 	}
 
-	// Base case: single token (either simple or group)
-	if len(tokens) == 1 {
-		if tokens[0].IsGroup() {
-			// Recursively parse the contents of the parenthesized group
-			return parseTokens(tokens[0].Children)
+	// Handle empty input
+	if len(tokens) == 0 {
+		return &SimpleRestriction{Category: ""}
+	}
+
+	if len(tokens) > 1 {
+		diags.add("missing operator before %s (only %s used)", describeToken(tokens[1]), describeToken(tokens[0]))
+	}
+
+	// Base case: a single token (either simple or group); extra tokens were reported above
+	if tokens[0].IsGroup() {
+		if len(tokens[0].Children) == 0 {
+			diags.add("empty parentheses")
+			return &SimpleRestriction{Category: ""}
 		}
-		return &SimpleRestriction{Category: tokens[0].Value}
+		// Recursively parse the contents of the parenthesized group
+		return parseTokensDiag(tokens[0].Children, diags)
+	}
+	return &SimpleRestriction{Category: tokens[0].Value}
+}
+
+// parseOperand parses one side of a binary operator, reporting it if it's missing
+func parseOperand(tokens []Token, op string, diags *diagnostics) Restriction {
+	if len(tokens) == 0 {
+		diags.add("missing operand for '%s'", op)
+		return &SimpleRestriction{Category: ""}
 	}
+	return parseTokensDiag(tokens, diags)
+}
 
-	// Fallback for unexpected cases
-	if len(tokens) > 0 && !tokens[0].IsGroup() {
-		return &SimpleRestriction{Category: tokens[0].Value}
+// describeToken renders a token for diagnostics
+func describeToken(t Token) string {
+	if t.IsGroup() {
+		return "'(...)'"
 	}
-	return &SimpleRestriction{Category: ""}
+	return "'" + t.Value + "'"
 }
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/jamiemulcahy/pa-pedia/pkg/models"
@@ -335,3 +337,147 @@ func TestTokenize(t *testing.T) {
 		})
 	}
 }
+
+// TestRestrictionNode verifies the exported expression tree keeps the parser's precedence
+// and that a tree read back evaluates like the original expression
+func TestRestrictionNode(t *testing.T) {
+	tests := []struct {
+		restriction string
+		want        string
+	}{
+		{"Mobile", `{"op":"type","type":"Mobile"}`},
+		{"Mobile & Basic", `{"op":"and","left":{"op":"type","type":"Mobile"},"right":{"op":"type","type":"Basic"}}`},
+		{"Air | Naval & Basic", `{"op":"or","left":{"op":"type","type":"Air"},"right":{"op":"and","left":{"op":"type","type":"Naval"},"right":{"op":"type","type":"Basic"}}}`},
+		{"(Mobile - Commander) & Land", `{"op":"and","left":{"op":"minus","left":{"op":"type","type":"Mobile"},"right":{"op":"type","type":"Commander"}},"right":{"op":"type","type":"Land"}}`},
+		{"", `{"op":"type"}`},
+	}
+
+	candidates := [][]string{
+		{"Mobile", "Land", "Basic", "Tank"},
+		{"Mobile", "Land", "Commander"},
+		{"Mobile", "Air", "Basic"},
+		{"Mobile", "Naval", "Advanced"},
+		{"Structure", "Land", "Basic"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.restriction, func(t *testing.T) {
+			restriction := ParseRestriction(tt.restriction)
+			data, err := json.Marshal(restriction.Node())
+			if err != nil {
+				t.Fatalf("json.Marshal failed: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Node() =\n  %s\nwant\n  %s", data, tt.want)
+			}
+
+			var node models.RestrictionNode
+			if err := json.Unmarshal(data, &node); err != nil {
+				t.Fatalf("json.Unmarshal failed: %v", err)
+			}
+			rebuilt := RestrictionFromNode(&node)
+			for _, types := range candidates {
+				unit := &models.Unit{UnitTypes: types}
+				if got, want := rebuilt.Satisfies(unit), restriction.Satisfies(unit); got != want {
+					t.Errorf("rebuilt.Satisfies(%v) = %v, original = %v", types, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestParseRestrictionDiagnostics verifies malformed expressions are reported and recovered leniently
+func TestParseRestrictionDiagnostics(t *testing.T) {
+	tests := []struct {
+		name        string
+		restriction string
+		wantDiags   []string
+		wantNode    string
+	}{
+		{"well formed", "(Mobile | Air) & Basic", nil, `{"op":"and","left":{"op":"or","left":{"op":"type","type":"Mobile"},"right":{"op":"type","type":"Air"}},"right":{"op":"type","type":"Basic"}}`},
+		{"unclosed paren keeps outer tokens", "Mobile & (Land | Naval", []string{"1 unclosed '('"}, `{"op":"and","left":{"op":"type","type":"Mobile"},"right":{"op":"or","left":{"op":"type","type":"Land"},"right":{"op":"type","type":"Naval"}}}`},
+		{"stray close paren", "Mobile) & Land", []string{"unmatched ')'"}, `{"op":"and","left":{"op":"type","type":"Mobile"},"right":{"op":"type","type":"Land"}}`},
+		{"dangling operator", "Mobile &", []string{"missing operand for '&'"}, `{"op":"and","left":{"op":"type","type":"Mobile"},"right":{"op":"type"}}`},
+		{"doubled operator", "Mobile && Land", []string{"missing operand for '&'"}, `{"op":"and","left":{"op":"type","type":"Mobile"},"right":{"op":"and","left":{"op":"type"},"right":{"op":"type","type":"Land"}}}`},
+		{"missing operator", "Mobile Land", []string{"missing operator before 'Land'"}, `{"op":"type","type":"Mobile"}`},
+		{"empty parentheses", "()", []string{"empty parentheses"}, `{"op":"type"}`},
+		{"empty", "  ", []string{"empty expression"}, `{"op":"type"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restriction, diags := ParseRestrictionDiagnostics(tt.restriction)
+			if len(diags) != len(tt.wantDiags) {
+				t.Fatalf("diagnostics = %q, want %d matching %q", diags, len(tt.wantDiags), tt.wantDiags)
+			}
+			for i, want := range tt.wantDiags {
+				if !strings.Contains(diags[i], want) {
+					t.Errorf("diagnostic %d = %q, want it to contain %q", i, diags[i], want)
+				}
+			}
+
+			data, err := json.Marshal(restriction.Node())
+			if err != nil {
+				t.Fatalf("json.Marshal failed: %v", err)
+			}
+			if string(data) != tt.wantNode {
+				t.Errorf("Node() =\n  %s\nwant\n  %s", data, tt.wantNode)
+			}
+		})
+	}
+}
+
+// FuzzParseRestriction checks arbitrary expressions never panic and that the exported tree
+// evaluates the same as the parsed restriction
+func FuzzParseRestriction(f *testing.F) {
+	for _, seed := range []string{
+		"Mobile",
+		"(Mobile | Air) & Basic",
+		"Mobile & (Land | Naval) - Commander",
+		"((Mobile)",
+		"Mobile))",
+		"& | -",
+		"Mobile Land",
+		"()",
+		"",
+	} {
+		f.Add(seed)
+	}
+
+	units := []*models.Unit{
+		{UnitTypes: []string{"Mobile", "Land", "Basic"}},
+		{UnitTypes: []string{"Mobile", "Air", "Commander"}},
+		{UnitTypes: []string{"Structure", "Naval"}},
+		{UnitTypes: nil},
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		restriction, _ := ParseRestrictionDiagnostics(text)
+		rebuilt := RestrictionFromNode(restriction.Node())
+		for _, unit := range units {
+			if got, want := rebuilt.Satisfies(unit), restriction.Satisfies(unit); got != want {
+				t.Errorf("%q: rebuilt.Satisfies(%v) = %v, original = %v", text, unit.UnitTypes, got, want)
+			}
+		}
+	})
+}
+
+// FuzzTokenize checks parentheses are always absorbed into groups, never left as tokens
+func FuzzTokenize(f *testing.F) {
+	for _, seed := range []string{"(A | B) & (C | D)", "((A)", "A))(", ")("} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		var walk func(tokens []Token)
+		walk = func(tokens []Token) {
+			for _, tok := range tokens {
+				if tok.Value == "(" || tok.Value == ")" {
+					t.Fatalf("%q: parenthesis left as a token", text)
+				}
+				walk(tok.Children)
+			}
+		}
+		walk(tokenize(text))
+	})
+}
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestParseSize(t *testing.T) {
+	base := &models.SizeSpecs{Width: 4, Length: 6, Height: 3, SelectionDiameter: 20, SizeClass: "small"}
+
+	tests := []struct {
+		name      string
+		data      map[string]interface{}
+		inherited *models.SizeSpecs
+		unitTypes []string
+		want      *models.SizeSpecs
+	}{
+		{
+			name: "mesh bounds and selection",
+			data: map[string]interface{}{
+				"mesh_bounds":    []interface{}{12.0, 9.0, 7.5},
+				"selection_icon": map[string]interface{}{"diameter": 40.0},
+			},
+			want: &models.SizeSpecs{Width: 12, Length: 9, Height: 7.5, SelectionDiameter: 40, SizeClass: "medium"},
+		},
+		{
+			name: "structure footprint sets the class",
+			data: map[string]interface{}{
+				"mesh_bounds":    []interface{}{15.0, 15.0, 10.0},
+				"placement_size": []interface{}{24.0, 20.0},
+			},
+			want: &models.SizeSpecs{Width: 15, Length: 15, Height: 10, FootprintWidth: 24, FootprintLength: 20, SizeClass: "large"},
+		},
+		{
+			name:      "titans are always titan",
+			data:      map[string]interface{}{"mesh_bounds": []interface{}{10.0, 10.0, 30.0}},
+			unitTypes: []string{"Mobile", "Titan"},
+			want:      &models.SizeSpecs{Width: 10, Length: 10, Height: 30, SizeClass: "titan"},
+		},
+		{
+			name:      "inherited from base_spec",
+			data:      map[string]interface{}{"selection_icon": map[string]interface{}{"diameter": 25.0}},
+			inherited: base,
+			want:      &models.SizeSpecs{Width: 4, Length: 6, Height: 3, SelectionDiameter: 25, SizeClass: "small"},
+		},
+		{
+			name: "no dimensions",
+			data: map[string]interface{}{"mesh_bounds": []interface{}{1.0, 2.0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unit := &models.Unit{UnitTypes: tt.unitTypes, Specs: models.UnitSpecs{Size: tt.inherited}}
+			parseSize(tt.data, unit)
+			if !reflect.DeepEqual(unit.Specs.Size, tt.want) {
+				t.Errorf("parseSize() = %+v, want %+v", unit.Specs.Size, tt.want)
+			}
+		})
+	}
+
+	// The base unit's specs are shared by pointer and must not change
+	if base.SelectionDiameter != 20 {
+		t.Errorf("base unit size modified: %+v", base)
+	}
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"sort"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// Snapshot is an immutable, point-in-time view of a Database's units for the
+// exporter and other readers (serve, query). It never changes after creation, so
+// it can be shared between goroutines without locking while the database is
+// filtered or reloaded.
+//
+// Units are copies of the database's units; their nested slices and pointers
+// (weapons, specs) are shared with the database and must be treated as read-only.
+type Snapshot struct {
+	units []models.Unit  // Sorted by tier, display name, then ID
+	index map[string]int // Unit ID -> position in units
+}
+
+// Snapshot captures the database's current units.
+func (db *Database) Snapshot() *Snapshot {
+	db.mu.RLock()
+	units := make([]models.Unit, 0, len(db.Units))
+	for _, unit := range db.Units {
+		units = append(units, *unit)
+	}
+	db.mu.RUnlock()
+
+	// Sort by tier, then by display name, then by ID for deterministic output.
+	// The ID tiebreaker is needed because sort.Slice is not stable and the input
+	// comes from map iteration (random order). Without it, units sharing the same
+	// tier and display name (e.g. bug_boomer/bug_boomer_r) swap between runs,
+	// causing spurious diffs in the update-factions workflow.
+	sort.Slice(units, func(i, j int) bool {
+		if units[i].Tier != units[j].Tier {
+			return units[i].Tier < units[j].Tier
+		}
+		if units[i].DisplayName != units[j].DisplayName {
+			return units[i].DisplayName < units[j].DisplayName
+		}
+		return units[i].ID < units[j].ID
+	})
+
+	index := make(map[string]int, len(units))
+	for i := range units {
+		index[units[i].ID] = i
+	}
+
+	return &Snapshot{units: units, index: index}
+}
+
+// Len returns the number of units in the snapshot.
+func (s *Snapshot) Len() int {
+	return len(s.units)
+}
+
+// Units returns the snapshot's units in export order. The slice is a fresh copy.
+func (s *Snapshot) Units() []models.Unit {
+	units := make([]models.Unit, len(s.units))
+	copy(units, s.units)
+	return units
+}
+
+// Unit returns a copy of the unit with the given ID.
+func (s *Snapshot) Unit(id string) (models.Unit, bool) {
+	i, ok := s.index[id]
+	if !ok {
+		return models.Unit{}, false
+	}
+	return s.units[i], true
+}
+
+// IDs returns the unit IDs in export order.
+func (s *Snapshot) IDs() []string {
+	ids := make([]string, len(s.units))
+	for i := range s.units {
+		ids[i] = s.units[i].ID
+	}
+	return ids
+}
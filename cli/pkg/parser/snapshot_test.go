@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestSnapshot(t *testing.T) {
+	db := &Database{
+		Units: map[string]*models.Unit{
+			"tank":    {ID: "tank", DisplayName: "Ant", Tier: 1},
+			"bot":     {ID: "bot", DisplayName: "Dox", Tier: 1},
+			"tank_t2": {ID: "tank_t2", DisplayName: "Leveler", Tier: 2},
+		},
+	}
+
+	snap := db.Snapshot()
+	if got := fmt.Sprint(snap.IDs()); got != "[tank bot tank_t2]" {
+		t.Errorf("IDs() = %s, want [tank bot tank_t2]", got)
+	}
+
+	// Later database changes don't reach the snapshot
+	db.FilterOutUnits(map[string]bool{"bot": true})
+	db.Units["tank"].DisplayName = "Renamed"
+	if snap.Len() != 3 {
+		t.Errorf("Len() = %d after filtering the database, want 3", snap.Len())
+	}
+	if unit, ok := snap.Unit("tank"); !ok || unit.DisplayName != "Ant" {
+		t.Errorf("Unit(tank) = %q, %v; want Ant", unit.DisplayName, ok)
+	}
+
+	// Callers can't modify the snapshot through Units()
+	units := snap.Units()
+	units[0].DisplayName = "Changed"
+	if unit, _ := snap.Unit(units[0].ID); unit.DisplayName == "Changed" {
+		t.Error("modifying Units() result changed the snapshot")
+	}
+
+	if _, ok := snap.Unit("missing"); ok {
+		t.Error("Unit(missing) should not be found")
+	}
+}
+
+// TestDatabaseConcurrentAccess exercises readers alongside filtering; run with -race
+func TestDatabaseConcurrentAccess(t *testing.T) {
+	db := &Database{Units: make(map[string]*models.Unit)}
+	for i := 0; i < 200; i++ {
+		id := fmt.Sprintf("unit_%03d", i)
+		db.Units[id] = &models.Unit{ID: id, ResourceName: "/pa/units/" + id + ".json", UnitTypes: []string{"Mobile"}}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				db.Snapshot()
+				db.GetUnitIDs()
+				db.Len()
+				db.Clone()
+				db.DetectBaseFactions()
+			}
+		}()
+	}
+	for i := 0; i < 200; i += 2 {
+		db.FilterOutUnits(map[string]bool{fmt.Sprintf("unit_%03d", i): true})
+	}
+	wg.Wait()
+
+	if db.Len() != 100 {
+		t.Errorf("Len() = %d, want 100", db.Len())
+	}
+}
@@ -0,0 +1,126 @@
+package parser
+
+import "github.com/jamiemulcahy/pa-pedia/pkg/models"
+
+// typeIndex buckets units by unit type so restriction matching only visits units carrying
+// the types a restriction names. Matching every builder against every unit is quadratic,
+// which total-conversion mods with thousands of units make noticeable.
+type typeIndex struct {
+	units  []*models.Unit
+	byType map[string][]int // Positions in units, ascending
+	all    []int
+
+	// Builders often share a buildable_types expression (every T1 fabber of a faction, say),
+	// so parsed restrictions and their matches are kept per expression text
+	byExpression map[string]expressionMatch
+}
+
+// expressionMatch is the cached result of matching one buildable_types expression
+type expressionMatch struct {
+	units       []*models.Unit
+	diagnostics []string
+}
+
+// newTypeIndex indexes units, keeping their order for match results
+func newTypeIndex(units []*models.Unit) *typeIndex {
+	ix := &typeIndex{
+		units:        units,
+		byType:       make(map[string][]int),
+		all:          make([]int, len(units)),
+		byExpression: make(map[string]expressionMatch),
+	}
+	for i, unit := range units {
+		ix.all[i] = i
+		for _, unitType := range unit.UnitTypes {
+			bucket := ix.byType[unitType]
+			// Skip repeated tags on the same unit
+			if len(bucket) > 0 && bucket[len(bucket)-1] == i {
+				continue
+			}
+			ix.byType[unitType] = append(bucket, i)
+		}
+	}
+	return ix
+}
+
+// matchExpression parses a buildable_types expression and returns the indexed units that
+// satisfy it, with any parse diagnostics. Results are cached per expression text; callers
+// must not modify the returned slices.
+func (ix *typeIndex) matchExpression(expression string) ([]*models.Unit, []string) {
+	if cached, ok := ix.byExpression[expression]; ok {
+		return cached.units, cached.diagnostics
+	}
+	restriction, diags := ParseRestrictionDiagnostics(expression)
+	result := expressionMatch{units: ix.match(restriction), diagnostics: diags}
+	ix.byExpression[expression] = result
+	return result.units, result.diagnostics
+}
+
+// match returns the indexed units that satisfy r, in index order
+func (ix *typeIndex) match(r Restriction) []*models.Unit {
+	var matches []*models.Unit
+	for _, i := range ix.candidates(r) {
+		if r.Satisfies(ix.units[i]) {
+			matches = append(matches, ix.units[i])
+		}
+	}
+	return matches
+}
+
+// candidates returns the ascending positions of units that may satisfy r: a superset of the
+// matches, narrowed by the types r requires
+func (ix *typeIndex) candidates(r Restriction) []int {
+	switch r := r.(type) {
+	case *SimpleRestriction:
+		return ix.byType[r.Category]
+	case *CompoundAnd:
+		return intersectSorted(ix.candidates(r.Left), ix.candidates(r.Right))
+	case *CompoundOr:
+		return unionSorted(ix.candidates(r.Left), ix.candidates(r.Right))
+	case *CompoundMinus:
+		// Excluded types only remove units, so the left side bounds the result
+		return ix.candidates(r.Left)
+	}
+	// Unknown restriction kinds get checked against every unit
+	return ix.all
+}
+
+// intersectSorted returns the values present in both ascending slices
+func intersectSorted(a, b []int) []int {
+	var out []int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+// unionSorted merges two ascending slices without duplicates
+func unionSorted(a, b []int) []int {
+	out := make([]int, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			out = append(out, a[i])
+			i++
+		case a[i] > b[j]:
+			out = append(out, b[j])
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	out = append(out, a[i:]...)
+	return append(out, b[j:]...)
+}
@@ -0,0 +1,140 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// TestTypeIndexMatchesBruteForce verifies indexed matching finds exactly the units a full scan
+// does, in the same order
+func TestTypeIndexMatchesBruteForce(t *testing.T) {
+	tags := [][]string{
+		{"Mobile", "Land", "Tank", "Basic"},
+		{"Mobile", "Land", "Bot", "Advanced"},
+		{"Mobile", "Air", "Basic", "Fabber"},
+		{"Mobile", "Naval", "Advanced"},
+		{"Structure", "Factory", "Land", "Basic"},
+		{"Structure", "Defense", "Basic", "Basic"}, // repeated tag
+		{"Mobile", "Land", "Commander"},
+		{"Mobile", "Orbital", "Titan"},
+		nil,
+	}
+	units := make([]*models.Unit, 0, len(tags))
+	for i, types := range tags {
+		units = append(units, &models.Unit{ID: fmt.Sprintf("unit_%d", i), UnitTypes: types})
+	}
+	index := newTypeIndex(units)
+
+	expressions := []string{
+		"Mobile",
+		"Mobile & Basic",
+		"(Mobile | Structure) & Land",
+		"Mobile - Commander",
+		"Mobile & Land - Commander - Bot",
+		"Structure & Basic | Air & Fabber",
+		"Titan | Naval",
+		"Missing",
+		"Mobile & (Land",
+		"",
+	}
+
+	for _, expr := range expressions {
+		t.Run(expr, func(t *testing.T) {
+			restriction := ParseRestriction(expr)
+
+			var want []string
+			for _, unit := range units {
+				if restriction.Satisfies(unit) {
+					want = append(want, unit.ID)
+				}
+			}
+
+			var got []string
+			for _, unit := range index.match(restriction) {
+				got = append(got, unit.ID)
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("match(%q) = %v, want %v", expr, got, want)
+			}
+		})
+	}
+}
+
+func TestSortedSetOps(t *testing.T) {
+	a := []int{1, 3, 5, 7}
+	b := []int{2, 3, 4, 7, 9}
+	if got := intersectSorted(a, b); fmt.Sprint(got) != "[3 7]" {
+		t.Errorf("intersectSorted() = %v, want [3 7]", got)
+	}
+	if got := unionSorted(a, b); fmt.Sprint(got) != "[1 2 3 4 5 7 9]" {
+		t.Errorf("unionSorted() = %v, want [1 2 3 4 5 7 9]", got)
+	}
+	if got := unionSorted(nil, b); fmt.Sprint(got) != fmt.Sprint(b) {
+		t.Errorf("unionSorted(nil, b) = %v, want %v", got, b)
+	}
+}
+
+// TestTypeIndexMatchExpressionCache verifies builders sharing an expression reuse one match
+func TestTypeIndexMatchExpressionCache(t *testing.T) {
+	units := []*models.Unit{
+		{ID: "tank", UnitTypes: []string{"Mobile", "Land", "Basic"}},
+		{ID: "factory", UnitTypes: []string{"Structure", "Land", "Basic"}},
+	}
+	index := newTypeIndex(units)
+
+	first, diags := index.matchExpression("Mobile & Basic")
+	if len(first) != 1 || first[0].ID != "tank" || len(diags) != 0 {
+		t.Fatalf("matchExpression() = %v, %v, want [tank] and no diagnostics", first, diags)
+	}
+	second, _ := index.matchExpression("Mobile & Basic")
+	if &first[0] != &second[0] {
+		t.Error("matchExpression() matched the same expression twice, want the cached result")
+	}
+	if len(index.byExpression) != 1 {
+		t.Errorf("cached %d expressions, want 1", len(index.byExpression))
+	}
+
+	_, diags = index.matchExpression("Mobile &")
+	if len(diags) != 1 {
+		t.Errorf("matchExpression(%q) diagnostics = %v, want 1", "Mobile &", diags)
+	}
+}
+
+// TestBuildTreeScales builds relationships for a large synthetic faction, where every builder
+// shares one of a few expressions, and checks the counts a full scan would produce
+func TestBuildTreeScales(t *testing.T) {
+	const n = 3000
+	domains := []string{"Land", "Air", "Naval", "Orbital"}
+	tiers := []string{"Basic", "Advanced"}
+
+	units := make([]*models.Unit, 0, n)
+	for i := 0; i < n; i++ {
+		domain := domains[i%len(domains)]
+		tier := tiers[(i/len(domains))%len(tiers)]
+		unit := &models.Unit{
+			ID:          fmt.Sprintf("unit_%04d", i),
+			DisplayName: fmt.Sprintf("Unit %04d", i),
+			UnitTypes:   []string{"Mobile", domain, tier},
+			Specs:       models.UnitSpecs{Economy: &models.EconomySpecs{BuildCost: float64(i)}},
+		}
+		if i%10 == 0 {
+			unit.BuildableTypes = "Mobile & " + domain + " & " + tier
+		}
+		units = append(units, unit)
+	}
+
+	db := &Database{Units: make(map[string]*models.Unit)}
+	if err := db.buildBuildTree(units, false); err != nil {
+		t.Fatalf("buildBuildTree failed: %v", err)
+	}
+
+	// Each of the 8 domain/tier groups holds n/8 units
+	for _, unit := range units {
+		if unit.BuildableTypes != "" && len(unit.BuildRelationships.Builds) != n/8 {
+			t.Fatalf("%s builds %d units, want %d", unit.ID, len(unit.BuildRelationships.Builds), n/8)
+		}
+	}
+}
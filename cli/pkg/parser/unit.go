@@ -10,8 +10,21 @@ import (
 	"github.com/jamiemulcahy/pa-pedia/pkg/models"
 )
 
-// ParseUnit parses a unit specification from JSON with base_spec inheritance
+// ParseUnit parses a unit specification from JSON with base_spec inheritance. A base_spec
+// cycle is cut where it would revisit a spec and recorded as a unit warning.
 func ParseUnit(l *loader.Loader, resourceName string, baseUnit *models.Unit) (*models.Unit, error) {
+	unit, err := parseUnit(l, resourceName, baseUnit, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cycle := baseSpecCycle(l, resourceName); cycle != nil {
+		unit.Warnings = append(unit.Warnings, cycleWarning(cycle))
+	}
+	return unit, nil
+}
+
+// parseUnit parses a unit; chain holds the specs inheriting from this one
+func parseUnit(l *loader.Loader, resourceName string, baseUnit *models.Unit, chain []string) (*models.Unit, error) {
 	data, err := l.GetJSON(resourceName)
 	if err != nil {
 		return nil, err
@@ -24,8 +37,8 @@ func ParseUnit(l *loader.Loader, resourceName string, baseUnit *models.Unit) (*m
 	}
 
 	// Handle base_spec inheritance
-	if baseSpec, ok := data["base_spec"].(string); ok && baseUnit == nil {
-		baseUnit, _ = ParseUnit(l, baseSpec, nil)
+	if baseSpec, ok := data["base_spec"].(string); ok && baseUnit == nil && !inChain(chain, resourceName, baseSpec) {
+		baseUnit, _ = parseUnit(l, baseSpec, nil, append(chain, resourceName))
 		if baseUnit != nil {
 			// Copy base unit properties
 			*unit = *baseUnit
@@ -42,6 +55,10 @@ func ParseUnit(l *loader.Loader, resourceName string, baseUnit *models.Unit) (*m
 	unit.DisplayName = loader.Delocalize(loader.GetString(data, "display_name", unit.ID))
 	role := loader.Delocalize(loader.GetString(data, "unit_name", unit.DisplayName))
 	description := loader.Delocalize(loader.GetString(data, "description", ""))
+	unit.DisplayNameKey = loader.LocKey(loader.GetString(data, "display_name", ""))
+	if text := loader.GetString(data, "description", ""); text != "" {
+		unit.DescriptionKey = loader.LocKey(text) // Otherwise inherited with the description
+	}
 
 	// Set image path (relative to faction folder, pointing to icon in unit folder)
 	unit.Image = fmt.Sprintf("units/%s/%s_icon_buildbar.png", unit.ID, unit.ID)
@@ -82,6 +99,10 @@ func ParseUnit(l *loader.Loader, resourceName string, baseUnit *models.Unit) (*m
 
 	// Parse buildable types
 	unit.BuildableTypes = loader.GetString(data, "buildable_types", unit.BuildableTypes)
+	unit.BuildRestriction = nil
+	if unit.BuildableTypes != "" {
+		unit.BuildRestriction = ParseRestriction(unit.BuildableTypes).Node()
+	}
 
 	// Parse assist buildable only
 	if val, ok := data["can_only_assist_with_buildable_items"]; ok {
@@ -147,7 +168,7 @@ func ParseUnit(l *loader.Loader, resourceName string, baseUnit *models.Unit) (*m
 	totalDPS := 0.0
 	totalSalvoDamage := 0.0
 	for _, w := range unit.Specs.Combat.Weapons {
-		if !w.DeathExplosion && !w.SelfDestruct {
+		if countsTowardDPS(w) {
 			totalDPS += w.DPS * float64(w.Count)
 		}
 		totalSalvoDamage += w.Damage * float64(w.Count)
@@ -183,10 +204,60 @@ func ParseUnit(l *loader.Loader, resourceName string, baseUnit *models.Unit) (*m
 
 	// Parse factory storage
 	parseStorage(data, unit)
+	parseFactorySpawn(l, data, unit)
+	priceManualFire(unit)
+
+	// Parse death and firing effects
+	applyUnitEffects(l, resourceName, unit)
+
+	// Parse physical size
+	parseSize(data, unit)
+
+	// Parse mod extension fields (regeneration, shields)
+	parseCommunityFields(data, unit)
 
 	return unit, nil
 }
 
+// parseFactorySpawn prices a factory's initial build (a preloaded missile, a unit cannon's
+// first unit) and, for structures that keep producing on their own, the spawn interval
+// and the metal rate it implies
+func parseFactorySpawn(l *loader.Loader, data map[string]interface{}, unit *models.Unit) {
+	storage := unit.Specs.Storage
+	if storage.InitialBuildSpec != "" {
+		storage.InitialBuildCost = specMetalCost(l, storage.InitialBuildSpec)
+	}
+
+	if v, ok := lookupNumber(data, "factory_cooldown_time"); ok {
+		storage.SpawnInterval = v
+	} else if v, ok := lookupNumber(data, "factory.spawn_interval"); ok {
+		storage.SpawnInterval = v
+	}
+
+	storage.SpawnMetalRate = 0
+	if storage.SpawnInterval > 0 && storage.InitialBuildCost > 0 {
+		storage.SpawnMetalRate = math.Round(storage.InitialBuildCost/storage.SpawnInterval*100) / 100
+	}
+}
+
+// specMetalCost returns a unit or ammo spec's build_metal_cost, following base_spec; 0 if
+// the spec can't be loaded or has none
+func specMetalCost(l *loader.Loader, resourcePath string) float64 {
+	visited := make(map[string]bool)
+	for path := resourcePath; path != "" && !visited[path]; {
+		visited[path] = true
+		data, err := l.GetJSON(path)
+		if err != nil {
+			return 0
+		}
+		if cost, ok := lookupNumber(data, "build_metal_cost"); ok {
+			return cost
+		}
+		path = loader.GetString(data, "base_spec", "")
+	}
+	return 0
+}
+
 // parseTools parses weapons and build arms from the tools array
 // buildableProjectiles contains ammo paths that factory-sourced weapons can use
 func parseTools(l *loader.Loader, data map[string]interface{}, unit *models.Unit, buildableProjectiles []string) error {
@@ -205,6 +276,7 @@ func parseTools(l *loader.Loader, data map[string]interface{}, unit *models.Unit
 		}
 		unit.Specs.Combat.Weapons = preservedWeapons
 		unit.Specs.Economy.BuildArms = nil
+		unit.Warnings = nil // Inherited warnings described the replaced tools
 	}
 
 	// Count tool occurrences
@@ -232,15 +304,6 @@ func parseTools(l *loader.Loader, data map[string]interface{}, unit *models.Unit
 	for _, specID := range sortedSpecIDs {
 		tool := toolData[specID]
 		count := toolCounts[specID]
-		name := extractToolName(specID)
-
-		// Determine tool type
-		isWeapon := strings.Contains(name, "weapon") ||
-			strings.Contains(name, "primary_weapon") ||
-			strings.Contains(name, "secondary_weapon") ||
-			strings.Contains(name, "aim_weapon")
-
-		isBuildArm := strings.Contains(name, "build_arm")
 
 		// Check for death_weapon flag
 		isDeathWeapon := false
@@ -248,30 +311,71 @@ func parseTools(l *loader.Loader, data map[string]interface{}, unit *models.Unit
 			isDeathWeapon = dw
 		}
 
-		if isWeapon || isDeathWeapon {
-			if weapon := parseWeaponWithOverrides(l, specID, tool, count, isDeathWeapon, buildableProjectiles); weapon != nil {
-				unit.Specs.Combat.Weapons = append(unit.Specs.Combat.Weapons, *weapon)
-			}
-		} else if isBuildArm {
+		kind := toolKindWeapon
+		if !isDeathWeapon {
+			kind = classifyTool(l, specID)
+		}
+
+		switch kind {
+		case toolKindWeapon:
+			addWeaponTool(l, unit, specID, tool, count, isDeathWeapon, buildableProjectiles)
+		case toolKindBuildArm:
 			buildArm, err := ParseBuildArm(l, specID, nil)
 			if err == nil {
 				buildArm.Count = count
 				unit.Specs.Economy.BuildArms = append(unit.Specs.Economy.BuildArms, *buildArm)
-			}
-		} else {
-			// Check tool_type in the actual tool spec (following base_spec inheritance)
-			toolType := getToolType(l, specID)
-			if toolType == "TOOL_Weapon" {
-				if weapon := parseWeaponWithOverrides(l, specID, tool, count, isDeathWeapon, buildableProjectiles); weapon != nil {
-					unit.Specs.Combat.Weapons = append(unit.Specs.Combat.Weapons, *weapon)
+				if cycle := baseSpecCycle(l, specID); cycle != nil {
+					unit.Warnings = append(unit.Warnings, fmt.Sprintf("build arm %s: %s", specID, cycleWarning(cycle)))
 				}
+			} else {
+				unit.Warnings = append(unit.Warnings, fmt.Sprintf("build arm %s: failed to parse spec: %v", specID, err))
 			}
+		case toolKindUnresolved:
+			unit.Warnings = append(unit.Warnings, fmt.Sprintf("tool %s: tool type unresolved, skipped", specID))
 		}
 	}
 
 	return nil
 }
 
+// addWeaponTool parses a weapon tool onto the unit, recording a warning when the spec fails to
+// parse or the weapon looks broken (no ammo resolved, zero rate of fire)
+func addWeaponTool(l *loader.Loader, unit *models.Unit, specID string, tool map[string]interface{}, count int, isDeathWeapon bool, buildableProjectiles []string) {
+	weapon := parseWeaponWithOverrides(l, specID, tool, count, isDeathWeapon, buildableProjectiles)
+	if weapon == nil {
+		unit.Warnings = append(unit.Warnings, fmt.Sprintf("weapon %s: failed to parse spec", specID))
+		return
+	}
+	unit.Specs.Combat.Weapons = append(unit.Specs.Combat.Weapons, *weapon)
+	if cycle := baseSpecCycle(l, specID); cycle != nil {
+		unit.Warnings = append(unit.Warnings, fmt.Sprintf("weapon %s: %s", specID, cycleWarning(cycle)))
+	}
+	if weapon.Ammo != nil && weapon.Ammo.ResourceName != specID {
+		if cycle := baseSpecCycle(l, weapon.Ammo.ResourceName); cycle != nil {
+			unit.Warnings = append(unit.Warnings, fmt.Sprintf("weapon %s: ammo %s", specID, cycleWarning(cycle)))
+		}
+	}
+
+	if isDeathWeapon {
+		return
+	}
+	if weapon.Ammo == nil {
+		unit.Warnings = append(unit.Warnings, fmt.Sprintf("weapon %s: no ammo spec resolved", specID))
+	}
+	if weapon.ROF == 0 {
+		unit.Warnings = append(unit.Warnings, fmt.Sprintf("weapon %s: zero rate of fire", specID))
+	}
+}
+
+// countsTowardDPS reports whether a weapon is part of the unit's sustained firepower: death and
+// self-destruct weapons fire once, and disabled or ability-toggled weapons are off by default
+func countsTowardDPS(w models.Weapon) bool {
+	if w.DeathExplosion || w.SelfDestruct || w.RequiresToggle {
+		return false
+	}
+	return w.Enabled == nil || *w.Enabled
+}
+
 // parseWeaponWithOverrides parses a weapon and applies tool-level overrides
 // buildableProjectiles is used to override ammo for factory-sourced weapons
 func parseWeaponWithOverrides(l *loader.Loader, specID string, tool map[string]interface{}, count int, isDeathWeapon bool, buildableProjectiles []string) *models.Weapon {
@@ -283,6 +387,9 @@ func parseWeaponWithOverrides(l *loader.Loader, specID string, tool map[string]i
 	weapon.Count = count
 	weapon.DeathExplosion = isDeathWeapon
 
+	// The unit's tool entry can switch a weapon off or gate it behind an ability
+	applyWeaponAvailability(weapon, tool)
+
 	// For factory-sourced weapons, parse ALL buildable_projectiles as ammo options
 	// and use MAX values for weapon stats (since only one fires at a time, show max potential)
 	if weapon.AmmoSource == "factory" && len(buildableProjectiles) > 0 {
@@ -360,45 +467,82 @@ func parseWeaponWithOverrides(l *loader.Loader, specID string, tool map[string]i
 		}
 	}
 
+	weapon.DamageFalloff = damageFalloff(weapon)
 	return weapon
 }
 
-// getToolType returns the tool_type for a spec, following base_spec inheritance
-func getToolType(l *loader.Loader, specID string) string {
-	visited := make(map[string]bool)
-	return getToolTypeRecursive(l, specID, visited)
+// toolKind is how a unit tool is treated when parsing its specs
+type toolKind int
+
+const (
+	toolKindUnresolved toolKind = iota // spec missing or carries no classifying fields
+	toolKindWeapon
+	toolKindBuildArm
+	toolKindOther // recognised tool_type that isn't a weapon or build arm (e.g. TOOL_Radar)
+)
+
+// toolSpecTraits are the classifying fields of a tool spec, merged along its base_spec chain
+type toolSpecTraits struct {
+	toolType           string
+	constructionDemand bool
+	weaponFields       bool
 }
 
-func getToolTypeRecursive(l *loader.Loader, specID string, visited map[string]bool) string {
+// classifyTool decides whether a tool is a weapon or build arm from its spec rather than its
+// file name, since mods (e.g. Legion combat fabbers) name tools freely. An explicit tool_type
+// wins; without one a construction_demand marks a build arm and ammo/rate of fire a weapon.
+func classifyTool(l *loader.Loader, specID string) toolKind {
+	traits, ok := resolveToolSpecTraits(l, specID, make(map[string]bool))
+	if !ok {
+		return toolKindUnresolved
+	}
+
+	switch {
+	case traits.toolType == "TOOL_Weapon":
+		return toolKindWeapon
+	case traits.toolType == "TOOL_BuildArm":
+		return toolKindBuildArm
+	case traits.toolType != "":
+		return toolKindOther
+	case traits.constructionDemand:
+		return toolKindBuildArm
+	case traits.weaponFields:
+		return toolKindWeapon
+	}
+	return toolKindUnresolved
+}
+
+// resolveToolSpecTraits reads a tool spec's classifying fields, following base_spec inheritance.
+// The nearest tool_type wins; the presence flags are set if any spec in the chain defines them.
+func resolveToolSpecTraits(l *loader.Loader, specID string, visited map[string]bool) (toolSpecTraits, bool) {
 	// Prevent infinite loops
 	if visited[specID] {
-		return ""
+		return toolSpecTraits{}, false
 	}
 	visited[specID] = true
 
 	toolSpec, err := l.GetJSON(specID)
 	if err != nil {
-		return ""
+		return toolSpecTraits{}, false
 	}
 
-	// Check if this spec has tool_type directly
-	if toolType := loader.GetString(toolSpec, "tool_type", ""); toolType != "" {
-		return toolType
+	traits := toolSpecTraits{
+		toolType: loader.GetString(toolSpec, "tool_type", ""),
 	}
+	_, traits.constructionDemand = toolSpec["construction_demand"].(map[string]interface{})
+	_, hasAmmo := toolSpec["ammo_id"]
+	_, hasROF := toolSpec["rate_of_fire"]
+	traits.weaponFields = hasAmmo || hasROF
 
-	// Follow base_spec if present
 	if baseSpec := loader.GetString(toolSpec, "base_spec", ""); baseSpec != "" {
-		return getToolTypeRecursive(l, baseSpec, visited)
+		if base, ok := resolveToolSpecTraits(l, baseSpec, visited); ok {
+			if traits.toolType == "" {
+				traits.toolType = base.toolType
+			}
+			traits.constructionDemand = traits.constructionDemand || base.constructionDemand
+			traits.weaponFields = traits.weaponFields || base.weaponFields
+		}
 	}
 
-	return ""
-}
-
-// extractToolName extracts the tool name from a resource path
-func extractToolName(resourcePath string) string {
-	parts := strings.Split(resourcePath, "/")
-	if len(parts) > 0 {
-		return strings.TrimSuffix(parts[len(parts)-1], ".json")
-	}
-	return ""
+	return traits, true
 }
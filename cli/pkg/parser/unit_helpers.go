@@ -182,14 +182,25 @@ func parseRecon(data map[string]interface{}, unit *models.Unit) {
 // parseStorage parses factory storage capabilities
 func parseStorage(data map[string]interface{}, unit *models.Unit) {
 	if factory, ok := data["factory"].(map[string]interface{}); ok {
+		// initial_build_spec is usually a missile ammo, but some factories spawn a unit directly
+		if spec, ok := factory["initial_build_spec"].(string); ok && spec != "" {
+			unit.Specs.Storage.InitialBuildSpec = spec
+		}
+
 		if storeUnits, ok := factory["store_units"].(bool); ok && storeUnits {
 			if spawnPoints, ok := factory["spawn_points"].([]interface{}); ok {
 				unit.Specs.Storage.UnitStorage = len(spawnPoints)
 				unit.Specs.Storage.StoredUnitType = "unit" // Default
 
+				// Spawn points are bone names, either as strings ("missile01") or as map keys
 				if unit.Specs.Storage.UnitStorage > 0 {
-					if spMap, ok := spawnPoints[0].(map[string]interface{}); ok {
-						for key := range spMap {
+					switch sp := spawnPoints[0].(type) {
+					case string:
+						if strings.Contains(sp, "missile") {
+							unit.Specs.Storage.StoredUnitType = "missile"
+						}
+					case map[string]interface{}:
+						for key := range sp {
 							if strings.Contains(key, "missile") {
 								unit.Specs.Storage.StoredUnitType = "missile"
 								break
@@ -201,3 +212,57 @@ func parseStorage(data map[string]interface{}, unit *models.Unit) {
 		}
 	}
 }
+
+// Size class thresholds on the larger ground dimension (mesh bounds or footprint), in
+// world units
+const (
+	smallUnitSize  = 8
+	mediumUnitSize = 20
+)
+
+// parseSize parses mesh bounds, placement footprint and selection size, then derives
+// the size class. Values missing here are kept from base_spec.
+func parseSize(data map[string]interface{}, unit *models.Unit) {
+	size := models.SizeSpecs{}
+	if unit.Specs.Size != nil {
+		size = *unit.Specs.Size // Copy so the base unit isn't modified
+	}
+
+	if bounds := loader.GetArray(data, "mesh_bounds"); len(bounds) >= 3 {
+		size.Width, _ = bounds[0].(float64)
+		size.Length, _ = bounds[1].(float64)
+		size.Height, _ = bounds[2].(float64)
+	}
+	if placement := loader.GetArray(data, "placement_size"); len(placement) >= 2 {
+		size.FootprintWidth, _ = placement[0].(float64)
+		size.FootprintLength, _ = placement[1].(float64)
+	}
+	if icon := loader.GetMap(data, "selection_icon"); icon["diameter"] != nil {
+		size.SelectionDiameter = loader.GetFloat(icon, "diameter", 0)
+	}
+
+	ground := math.Max(math.Max(size.Width, size.Length), math.Max(size.FootprintWidth, size.FootprintLength))
+	if ground == 0 && size.Height == 0 {
+		unit.Specs.Size = nil
+		return
+	}
+	size.SizeClass = sizeClass(ground, unit.UnitTypes)
+	unit.Specs.Size = &size
+}
+
+// sizeClass buckets a unit by its larger ground dimension; Titans are always titan
+func sizeClass(ground float64, unitTypes []string) string {
+	for _, ut := range unitTypes {
+		if ut == "Titan" {
+			return "titan"
+		}
+	}
+	switch {
+	case ground < smallUnitSize:
+		return "small"
+	case ground < mediumUnitSize:
+		return "medium"
+	default:
+		return "large"
+	}
+}
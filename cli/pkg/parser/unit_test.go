@@ -0,0 +1,359 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// TestParseUnitWarnings verifies non-fatal tool problems are recorded on the unit
+func TestParseUnitWarnings(t *testing.T) {
+	paRoot := t.TempDir()
+	files := map[string]string{
+		"pa/units/land/broken/broken.json": `{
+			"display_name": "Broken",
+			"tools": [
+				{"spec_id": "/pa/units/land/broken/broken_tool_weapon.json"},
+				{"spec_id": "/pa/units/land/broken/broken_dummy_weapon.json"},
+				{"spec_id": "/pa/units/land/broken/broken_gizmo.json"}
+			]
+		}`,
+		"pa/units/land/broken/broken_tool_weapon.json":  `{"rate_of_fire": 1, "ammo_id": "/pa/units/land/broken/missing_ammo.json"}`,
+		"pa/units/land/broken/broken_dummy_weapon.json": `{"ammo_id": "/pa/units/land/broken/broken_ammo.json"}`,
+		"pa/units/land/broken/broken_ammo.json":         `{"damage": 10}`,
+	}
+	writeSpecFiles(t, paRoot, files)
+
+	l, err := loader.NewMultiSourceLoader(paRoot, "pa_ex1", nil)
+	if err != nil {
+		t.Fatalf("failed to create loader: %v", err)
+	}
+	defer l.Close()
+
+	unit, err := ParseUnit(l, "/pa/units/land/broken/broken.json", nil)
+	if err != nil {
+		t.Fatalf("ParseUnit failed: %v", err)
+	}
+
+	expected := []string{
+		"broken_dummy_weapon.json: zero rate of fire",
+		"broken_gizmo.json: tool type unresolved",
+		"broken_tool_weapon.json: no ammo spec resolved",
+	}
+	if len(unit.Warnings) != len(expected) {
+		t.Fatalf("expected %d warnings, got %d: %v", len(expected), len(unit.Warnings), unit.Warnings)
+	}
+	for i, want := range expected {
+		if !strings.Contains(unit.Warnings[i], want) {
+			t.Errorf("warning %d = %q, want it to contain %q", i, unit.Warnings[i], want)
+		}
+	}
+}
+
+// TestParseToolsLegionCombatFabber verifies tools are classified by their spec, not their file
+// name. Legion's combat fabricators mix a weapon and build arms whose names don't follow the
+// base game's *_tool_weapon / *_build_arm convention.
+func TestParseToolsLegionCombatFabber(t *testing.T) {
+	paRoot := t.TempDir()
+	writeSpecFiles(t, paRoot, map[string]string{
+		"pa/tools/base_build_arm.json": `{"tool_type": "TOOL_BuildArm", "construction_demand": {"metal": 10, "energy": 100}}`,
+		"pa/units/land/l_combat_fab/l_combat_fab.json": `{
+			"display_name": "Legion Combat Fabricator",
+			"tools": [
+				{"spec_id": "/pa/units/land/l_combat_fab/l_combat_fab_build_arm_gun.json"},
+				{"spec_id": "/pa/units/land/l_combat_fab/l_combat_fab_repair_weapon.json"},
+				{"spec_id": "/pa/units/land/l_combat_fab/l_combat_fab_nanolathe.json"},
+				{"spec_id": "/pa/units/land/l_combat_fab/l_combat_fab_reclaimer.json"},
+				{"spec_id": "/pa/units/land/l_combat_fab/l_combat_fab_radar.json"}
+			]
+		}`,
+		// Named like a build arm, but a weapon
+		"pa/units/land/l_combat_fab/l_combat_fab_build_arm_gun.json": `{"tool_type": "TOOL_Weapon", "rate_of_fire": 2, "ammo_id": "/pa/units/land/l_combat_fab/l_combat_fab_ammo.json"}`,
+		"pa/units/land/l_combat_fab/l_combat_fab_ammo.json":          `{"damage": 15}`,
+		// Named like a weapon, but a build arm
+		"pa/units/land/l_combat_fab/l_combat_fab_repair_weapon.json": `{"tool_type": "TOOL_BuildArm", "construction_demand": {"metal": 6, "energy": 60}, "max_range": 25}`,
+		// No name hint; build arm through base_spec
+		"pa/units/land/l_combat_fab/l_combat_fab_nanolathe.json": `{"base_spec": "/pa/tools/base_build_arm.json", "max_range": 30}`,
+		// No tool_type anywhere; construction_demand alone marks a build arm
+		"pa/units/land/l_combat_fab/l_combat_fab_reclaimer.json": `{"construction_demand": {"metal": 2, "energy": 20}}`,
+		// Other tool types are skipped without a warning
+		"pa/units/land/l_combat_fab/l_combat_fab_radar.json": `{"tool_type": "TOOL_Radar"}`,
+	})
+
+	l, err := loader.NewMultiSourceLoader(paRoot, "pa_ex1", nil)
+	if err != nil {
+		t.Fatalf("failed to create loader: %v", err)
+	}
+	defer l.Close()
+
+	unit, err := ParseUnit(l, "/pa/units/land/l_combat_fab/l_combat_fab.json", nil)
+	if err != nil {
+		t.Fatalf("ParseUnit failed: %v", err)
+	}
+
+	var weapons []string
+	for _, w := range unit.Specs.Combat.Weapons {
+		weapons = append(weapons, w.Name)
+	}
+	wantWeapons := []string{"l_combat_fab_build_arm_gun"}
+	if strings.Join(weapons, ",") != strings.Join(wantWeapons, ",") {
+		t.Errorf("weapons = %v, want %v", weapons, wantWeapons)
+	}
+
+	arms := make(map[string]float64)
+	for _, arm := range unit.Specs.Economy.BuildArms {
+		arms[arm.Name] = arm.MetalConsumption
+	}
+	wantArms := map[string]float64{
+		"l_combat_fab_nanolathe":     10,
+		"l_combat_fab_reclaimer":     2,
+		"l_combat_fab_repair_weapon": 6,
+	}
+	if len(arms) != len(wantArms) {
+		t.Errorf("build arms = %v, want %v", arms, wantArms)
+	}
+	for name, metal := range wantArms {
+		if got, ok := arms[name]; !ok || got != metal {
+			t.Errorf("build arm %s metal = %v (present %v), want %v", name, got, ok, metal)
+		}
+	}
+
+	if len(unit.Warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", unit.Warnings)
+	}
+}
+
+// TestParseToolsAvailability verifies disabled and ability-toggled weapons are listed with their
+// markers but left out of the unit's aggregate DPS
+func TestParseToolsAvailability(t *testing.T) {
+	paRoot := t.TempDir()
+	dir := "pa/units/land/toggler/"
+	writeSpecFiles(t, paRoot, map[string]string{
+		dir + "toggler.json": `{
+			"display_name": "Toggler",
+			"tools": [
+				{"spec_id": "/pa/units/land/toggler/always_on.json"},
+				{"spec_id": "/pa/units/land/toggler/spec_disabled.json"},
+				{"spec_id": "/pa/units/land/toggler/entry_disabled.json", "enabled": false},
+				{"spec_id": "/pa/units/land/toggler/entry_enabled.json", "enabled": true},
+				{"spec_id": "/pa/units/land/toggler/overdrive.json"}
+			]
+		}`,
+		dir + "ammo.json":           `{"damage": 10}`,
+		dir + "always_on.json":      `{"tool_type": "TOOL_Weapon", "rate_of_fire": 1, "ammo_id": "/pa/units/land/toggler/ammo.json"}`,
+		dir + "spec_disabled.json":  `{"tool_type": "TOOL_Weapon", "rate_of_fire": 2, "ammo_id": "/pa/units/land/toggler/ammo.json", "enabled": false}`,
+		dir + "entry_disabled.json": `{"tool_type": "TOOL_Weapon", "rate_of_fire": 3, "ammo_id": "/pa/units/land/toggler/ammo.json"}`,
+		dir + "entry_enabled.json":  `{"base_spec": "/pa/units/land/toggler/spec_disabled.json", "rate_of_fire": 4}`,
+		dir + "overdrive.json":      `{"tool_type": "TOOL_Weapon", "rate_of_fire": 5, "ammo_id": "/pa/units/land/toggler/ammo.json", "toggle_ability": "overdrive"}`,
+	})
+
+	l, err := loader.NewMultiSourceLoader(paRoot, "pa_ex1", nil)
+	if err != nil {
+		t.Fatalf("failed to create loader: %v", err)
+	}
+	defer l.Close()
+
+	unit, err := ParseUnit(l, "/pa/units/land/toggler/toggler.json", nil)
+	if err != nil {
+		t.Fatalf("ParseUnit failed: %v", err)
+	}
+
+	type marker struct {
+		enabled        string
+		requiresToggle bool
+	}
+	got := make(map[string]marker)
+	for _, w := range unit.Specs.Combat.Weapons {
+		m := marker{enabled: "unset", requiresToggle: w.RequiresToggle}
+		if w.Enabled != nil {
+			m.enabled = fmt.Sprint(*w.Enabled)
+		}
+		got[w.Name] = m
+	}
+	want := map[string]marker{
+		"always_on":      {"unset", false},
+		"spec_disabled":  {"false", false},
+		"entry_disabled": {"false", false},
+		"entry_enabled":  {"true", false},
+		"overdrive":      {"unset", true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("weapons = %v, want %v", got, want)
+	}
+	for name, m := range want {
+		if got[name] != m {
+			t.Errorf("weapon %s = %+v, want %+v", name, got[name], m)
+		}
+	}
+
+	// always_on (10) + entry_enabled (40)
+	if unit.Specs.Combat.DPS != 50 {
+		t.Errorf("unit DPS = %v, want 50", unit.Specs.Combat.DPS)
+	}
+}
+
+// writeSpecFiles writes spec JSON files under root, keyed by slash-separated relative path
+func writeSpecFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", rel, err)
+		}
+	}
+}
+
+// TestParseFactorySpawn verifies initial builds are priced through base_spec and that
+// self-producing structures get a spawn interval and metal rate
+func TestParseFactorySpawn(t *testing.T) {
+	paRoot := t.TempDir()
+	files := map[string]string{
+		"pa/units/land/nuke/nuke.json": `{
+			"factory": {"initial_build_spec": "/pa/units/land/nuke/nuke_ammo.json", "store_units": true, "spawn_points": [{"missile": 1}]}
+		}`,
+		"pa/units/land/nuke/nuke_ammo.json":  `{"base_spec": "/pa/ammo/base_missile.json"}`,
+		"pa/ammo/base_missile.json":          `{"build_metal_cost": 36000}`,
+		"pa/units/land/cannon/cannon.json":   `{"factory_cooldown_time": 4, "factory": {"initial_build_spec": "/pa/units/land/dox/dox.json"}}`,
+		"pa/units/land/dox/dox.json":         `{"build_metal_cost": 60}`,
+		"pa/units/land/jig/jig.json":         `{"factory": {"initial_build_spec": "/pa/units/land/dox/dox.json", "spawn_interval": 30}}`,
+		"pa/units/land/missing/missing.json": `{"factory": {"initial_build_spec": "/pa/units/land/gone/gone.json"}}`,
+	}
+	writeSpecFiles(t, paRoot, files)
+
+	l, err := loader.NewMultiSourceLoader(paRoot, "pa_ex1", nil)
+	if err != nil {
+		t.Fatalf("failed to create loader: %v", err)
+	}
+	defer l.Close()
+
+	tests := []struct {
+		unit     string
+		cost     float64
+		interval float64
+		rate     float64
+	}{
+		{"/pa/units/land/nuke/nuke.json", 36000, 0, 0},
+		{"/pa/units/land/cannon/cannon.json", 60, 4, 15},
+		{"/pa/units/land/jig/jig.json", 60, 30, 2},
+		{"/pa/units/land/missing/missing.json", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		unit, err := ParseUnit(l, tt.unit, nil)
+		if err != nil {
+			t.Fatalf("ParseUnit(%s) failed: %v", tt.unit, err)
+		}
+		s := unit.Specs.Storage
+		if s.InitialBuildCost != tt.cost || s.SpawnInterval != tt.interval || s.SpawnMetalRate != tt.rate {
+			t.Errorf("%s: cost %v, interval %v, rate %v; want %v, %v, %v", tt.unit, s.InitialBuildCost, s.SpawnInterval, s.SpawnMetalRate, tt.cost, tt.interval, tt.rate)
+		}
+	}
+}
+
+// TestParseManualFire verifies manual-fire and self-destruct weapons are marked, that a
+// nuke's missile is priced at the launcher's own build rate, and that anti-nukes (manual_fire
+// with an anti_entity auto task) fire on their own
+func TestParseManualFire(t *testing.T) {
+	paRoot := t.TempDir()
+	dir := "pa/units/land/nuke/"
+	writeSpecFiles(t, paRoot, map[string]string{
+		dir + "nuke.json": `{
+			"buildable_projectiles": ["/pa/units/land/nuke/missile.json"],
+			"factory": {"store_units": true, "spawn_points": ["missile01", "missile02"]},
+			"tools": [
+				{"spec_id": "/pa/units/land/nuke/build_arm.json"},
+				{"spec_id": "/pa/units/land/nuke/launcher.json"},
+				{"spec_id": "/pa/units/land/nuke/uber_cannon.json"},
+				{"spec_id": "/pa/units/land/nuke/charge.json"},
+				{"spec_id": "/pa/units/land/nuke/cannon.json"},
+				{"spec_id": "/pa/units/land/nuke/anti_nuke.json"}
+			]
+		}`,
+		dir + "build_arm.json":   `{"tool_type": "TOOL_BuildArm", "construction_demand": {"metal": 40, "energy": 1000}}`,
+		dir + "missile.json":     `{"damage": 20000, "build_metal_cost": 32000}`,
+		dir + "ammo.json":        `{"damage": 10}`,
+		dir + "launcher.json":    `{"tool_type": "TOOL_Weapon", "manual_fire": true, "ammo_source": "factory", "rate_of_fire": 1}`,
+		dir + "base_uber.json":   `{"tool_type": "TOOL_Weapon", "manual_fire": true, "rate_of_fire": 1, "ammo_id": "/pa/units/land/nuke/ammo.json"}`,
+		dir + "uber_cannon.json": `{"base_spec": "/pa/units/land/nuke/base_uber.json", "ammo_source": "energy"}`,
+		dir + "charge.json":      `{"tool_type": "TOOL_Weapon", "self_destruct": true, "ammo_id": "/pa/units/land/nuke/ammo.json"}`,
+		dir + "cannon.json":      `{"tool_type": "TOOL_Weapon", "rate_of_fire": 1, "ammo_id": "/pa/units/land/nuke/ammo.json"}`,
+		dir + "anti_nuke.json":   `{"tool_type": "TOOL_Weapon", "manual_fire": true, "auto_task_type": "anti_entity", "anti_entity_targets": ["/pa/units/land/nuke/missile.json"], "ammo_id": "/pa/units/land/nuke/ammo.json"}`,
+	})
+
+	l, err := loader.NewMultiSourceLoader(paRoot, "pa_ex1", nil)
+	if err != nil {
+		t.Fatalf("failed to create loader: %v", err)
+	}
+	defer l.Close()
+
+	unit, err := ParseUnit(l, "/pa/units/land/nuke/nuke.json", nil)
+	if err != nil {
+		t.Fatalf("ParseUnit failed: %v", err)
+	}
+
+	want := map[string]*models.ManualFire{
+		"launcher": {
+			Trigger:           models.ManualFireCommand,
+			RequiresAmmoBuild: true,
+			AmmoMetalCost:     32000,
+			AmmoBuildTime:     800,
+			AmmoEnergyCost:    800000,
+			AmmoStorage:       2,
+		},
+		"uber_cannon": {Trigger: models.ManualFireCommand},
+		"charge":      {Trigger: models.ManualFireSelfDestruct},
+		"cannon":      nil,
+		"anti_nuke":   nil,
+	}
+	for _, w := range unit.Specs.Combat.Weapons {
+		if !reflect.DeepEqual(w.ManualFire, want[w.Name]) {
+			t.Errorf("weapon %s manualFire = %+v, want %+v", w.Name, w.ManualFire, want[w.Name])
+		}
+		if w.Name == "anti_nuke" && !reflect.DeepEqual(w.AntiEntityTargets, []string{"/pa/units/land/nuke/missile.json"}) {
+			t.Errorf("anti_nuke antiEntityTargets = %v, want the nuke missile", w.AntiEntityTargets)
+		}
+	}
+}
+
+// TestParseUnitLocKeys verifies !LOC keys are kept beside the delocalized strings, and that a
+// description inherited through base_spec keeps its key
+func TestParseUnitLocKeys(t *testing.T) {
+	paRoot := t.TempDir()
+	writeSpecFiles(t, paRoot, map[string]string{
+		"pa/units/land/base_tank/base_tank.json": `{"description": "!LOC(units.base_tank.description):Basic tank."}`,
+		"pa/units/land/tank/tank.json":           `{"base_spec": "/pa/units/land/base_tank/base_tank.json", "display_name": "!LOC:Ant"}`,
+		"pa/units/land/bot/bot.json":             `{"display_name": "Dox", "description": "!LOC:Fast bot."}`,
+	})
+
+	l, err := loader.NewMultiSourceLoader(paRoot, "pa_ex1", nil)
+	if err != nil {
+		t.Fatalf("failed to create loader: %v", err)
+	}
+	defer l.Close()
+
+	tests := []struct {
+		path, name, nameKey, description, descriptionKey string
+	}{
+		{"/pa/units/land/tank/tank.json", "Ant", "Ant", "Basic tank.", "units.base_tank.description"},
+		{"/pa/units/land/bot/bot.json", "Dox", "", "Fast bot.", "Fast bot."},
+	}
+	for _, tt := range tests {
+		unit, err := ParseUnit(l, tt.path, nil)
+		if err != nil {
+			t.Fatalf("ParseUnit(%s) failed: %v", tt.path, err)
+		}
+		if unit.DisplayName != tt.name || unit.DisplayNameKey != tt.nameKey || unit.Description != tt.description || unit.DescriptionKey != tt.descriptionKey {
+			t.Errorf("%s: got name %q (key %q) description %q (key %q), want %q (%q) %q (%q)", tt.path,
+				unit.DisplayName, unit.DisplayNameKey, unit.Description, unit.DescriptionKey,
+				tt.name, tt.nameKey, tt.description, tt.descriptionKey)
+		}
+	}
+}
@@ -10,6 +10,10 @@ import (
 
 // ParseWeapon parses weapon specifications from JSON
 func ParseWeapon(l *loader.Loader, resourceName string, baseWeapon *models.Weapon) (*models.Weapon, error) {
+	return parseWeapon(l, resourceName, baseWeapon, nil)
+}
+
+func parseWeapon(l *loader.Loader, resourceName string, baseWeapon *models.Weapon, chain []string) (*models.Weapon, error) {
 	data, err := l.GetJSON(resourceName)
 	if err != nil {
 		return nil, err
@@ -23,8 +27,8 @@ func ParseWeapon(l *loader.Loader, resourceName string, baseWeapon *models.Weapo
 	}
 
 	// Handle base_spec inheritance
-	if baseSpec, ok := data["base_spec"].(string); ok && baseWeapon == nil {
-		baseWeapon, _ = ParseWeapon(l, baseSpec, nil)
+	if baseSpec, ok := data["base_spec"].(string); ok && baseWeapon == nil && !inChain(chain, resourceName, baseSpec) {
+		baseWeapon, _ = parseWeapon(l, baseSpec, nil, append(chain, resourceName))
 		if baseWeapon != nil {
 			*weapon = *baseWeapon
 			weapon.ResourceName = resourceName
@@ -191,10 +195,24 @@ func ParseWeapon(l *loader.Loader, resourceName string, baseWeapon *models.Weapo
 			}
 		}
 	}
+	weapon.TargetPriorityTags = priorityTags(weapon.TargetPriorities)
+
+	// Parse anti-entity targets (projectiles an interceptor shoots down)
+	if targets, ok := data["anti_entity_targets"].([]interface{}); ok {
+		weapon.AntiEntityTargets = make([]string, 0, len(targets))
+		for _, target := range targets {
+			if targetStr, ok := target.(string); ok && targetStr != "" {
+				weapon.AntiEntityTargets = append(weapon.AntiEntityTargets, targetStr)
+			}
+		}
+	}
 
 	// Parse self-destruct flags
 	weapon.SelfDestruct = loader.GetBool(data, "self_destruct", weapon.SelfDestruct) ||
 		loader.GetBool(data, "only_fire_once", weapon.SelfDestruct)
+	applyManualFire(weapon, data)
+
+	applyWeaponAvailability(weapon, data)
 
 	// Parse turret properties
 	weapon.YawRange = loader.GetFloat(data, "yaw_range", weapon.YawRange)
@@ -210,6 +228,10 @@ func ParseWeapon(l *loader.Loader, resourceName string, baseWeapon *models.Weapo
 
 // ParseAmmo parses ammo specifications from JSON
 func ParseAmmo(l *loader.Loader, resourceName string, baseAmmo *models.Ammo) (*models.Ammo, error) {
+	return parseAmmo(l, resourceName, baseAmmo, nil)
+}
+
+func parseAmmo(l *loader.Loader, resourceName string, baseAmmo *models.Ammo, chain []string) (*models.Ammo, error) {
 	data, err := l.GetJSON(resourceName)
 	if err != nil {
 		return nil, err
@@ -221,8 +243,8 @@ func ParseAmmo(l *loader.Loader, resourceName string, baseAmmo *models.Ammo) (*m
 	}
 
 	// Handle base_spec inheritance
-	if baseSpec, ok := data["base_spec"].(string); ok && baseAmmo == nil {
-		baseAmmo, _ = ParseAmmo(l, baseSpec, nil)
+	if baseSpec, ok := data["base_spec"].(string); ok && baseAmmo == nil && !inChain(chain, resourceName, baseSpec) {
+		baseAmmo, _ = parseAmmo(l, baseSpec, nil, append(chain, resourceName))
 		if baseAmmo != nil {
 			*ammo = *baseAmmo
 			ammo.ResourceName = resourceName
@@ -259,11 +281,29 @@ func ParseAmmo(l *loader.Loader, resourceName string, baseAmmo *models.Ammo) (*m
 	ammo.BurnRadius = loader.GetFloat(data, "burn_radius", ammo.BurnRadius)
 	ammo.BurnDuration = loader.GetFloat(data, "burn_duration", ammo.BurnDuration)
 
+	applyAmmoEffects(ammo, data)
+
 	return ammo, nil
 }
 
+// applyWeaponAvailability reads the enabled state and ability toggle from a weapon spec or a
+// unit's tool entry. A toggle_ability names the unit ability that switches the weapon on, so the
+// weapon starts off.
+func applyWeaponAvailability(weapon *models.Weapon, data map[string]interface{}) {
+	if enabled, ok := data["enabled"].(bool); ok {
+		weapon.Enabled = &enabled
+	}
+	if loader.GetString(data, "toggle_ability", "") != "" {
+		weapon.RequiresToggle = true
+	}
+}
+
 // ParseBuildArm parses build arm (construction tool) specifications from JSON
 func ParseBuildArm(l *loader.Loader, resourceName string, baseBuildArm *models.BuildArm) (*models.BuildArm, error) {
+	return parseBuildArm(l, resourceName, baseBuildArm, nil)
+}
+
+func parseBuildArm(l *loader.Loader, resourceName string, baseBuildArm *models.BuildArm, chain []string) (*models.BuildArm, error) {
 	data, err := l.GetJSON(resourceName)
 	if err != nil {
 		return nil, err
@@ -276,8 +316,8 @@ func ParseBuildArm(l *loader.Loader, resourceName string, baseBuildArm *models.B
 	}
 
 	// Handle base_spec inheritance
-	if baseSpec, ok := data["base_spec"].(string); ok && baseBuildArm == nil {
-		baseBuildArm, _ = ParseBuildArm(l, baseSpec, nil)
+	if baseSpec, ok := data["base_spec"].(string); ok && baseBuildArm == nil && !inChain(chain, resourceName, baseSpec) {
+		baseBuildArm, _ = parseBuildArm(l, baseSpec, nil, append(chain, resourceName))
 		if baseBuildArm != nil {
 			*buildArm = *baseBuildArm
 			buildArm.ResourceName = resourceName
@@ -8,8 +8,11 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 
+	"github.com/invopop/jsonschema"
 	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/schema"
 	"github.com/jamiemulcahy/pa-pedia/profiles/embedded"
 )
 
@@ -17,6 +20,11 @@ import (
 // Should be alphanumeric (e.g., Custom1, Custom58, Tank, etc.)
 var factionUnitTypePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
 
+// profileSchema is faction-profile.schema.json, which local profiles are validated against
+var profileSchema = sync.OnceValue(func() *jsonschema.Schema {
+	return schema.Reflect(schema.Entry{Name: "faction-profile", Type: &models.FactionProfile{}})
+})
+
 // Loader handles profile discovery and loading from embedded and local sources.
 type Loader struct {
 	profiles map[string]*models.FactionProfile // Indexed by ID (lowercase)
@@ -85,12 +93,22 @@ func (l *Loader) LoadLocalProfiles(profileDir string) error {
 		if err != nil {
 			return fmt.Errorf("failed to read profile %s: %w", entry.Name(), err)
 		}
+		if err := validateProfile(data); err != nil {
+			return fmt.Errorf("failed to parse profile %s: %w", path, err)
+		}
 
 		profile, err := parseProfile(data, entry.Name())
 		if err != nil {
 			return fmt.Errorf("failed to parse profile %s: %w", entry.Name(), err)
 		}
 
+		// Icon overrides live next to the profile, wherever it's run from
+		if profile.IconOverrides != "" && !filepath.IsAbs(profile.IconOverrides) {
+			profile.IconOverrides = filepath.Join(profileDir, profile.IconOverrides)
+		}
+
+		profile.Path = path
+
 		// Local profiles override embedded
 		l.profiles[profile.ID] = profile
 	}
@@ -129,6 +147,158 @@ func (l *Loader) GetAllProfiles() []*models.FactionProfile {
 	return profiles
 }
 
+// ExpandComposite resolves a composite profile (one with Includes) into a single
+// profile that can be extracted like any other. Non-composite profiles are
+// returned unchanged.
+//
+// Mods are concatenated in declared priority order: the composite's own mods
+// first, then each included profile's mods in Includes order, keeping the first
+// occurrence of any duplicate. Included profiles may themselves be composite.
+//
+// The composite's own fields always win. factionUnitType comes from the first
+// included profile that defines one; if none does, the result is an addon.
+// Version, build, author, description, dateCreated, backgroundImage,
+// iconOverrides and teamColors fall back to the first included profile that sets
+// them, as do pinned commanders; excluded commanders and opted-out mods accumulate
+// across every included profile, and a result with both pinned and excluded commanders
+// is an error, as in a single profile. Icon mappings and conflict preferences merge per
+// unit, the first profile to set one winning.
+func (l *Loader) ExpandComposite(profile *models.FactionProfile) (*models.FactionProfile, error) {
+	if len(profile.Includes) == 0 {
+		return profile, nil
+	}
+	return l.expandComposite(profile, map[string]bool{profile.ID: true})
+}
+
+func (l *Loader) expandComposite(profile *models.FactionProfile, visiting map[string]bool) (*models.FactionProfile, error) {
+	expanded := *profile
+	expanded.Mods = nil
+	expanded.Includes = append([]string(nil), profile.Includes...)
+	expanded.ExcludeCommanders = append([]string(nil), profile.ExcludeCommanders...)
+	expanded.OptOutMods = append([]string(nil), profile.OptOutMods...)
+	expanded.Icons = nil
+	addIcons := func(icons map[string]string) {
+		for id, icon := range icons {
+			if expanded.Icons == nil {
+				expanded.Icons = make(map[string]string)
+			}
+			if _, ok := expanded.Icons[id]; !ok {
+				expanded.Icons[id] = icon
+			}
+		}
+	}
+	addIcons(profile.Icons)
+	expanded.ConflictPreferences = nil
+	addPreferences := func(prefs map[string]string) {
+		for unit, source := range prefs {
+			if expanded.ConflictPreferences == nil {
+				expanded.ConflictPreferences = make(map[string]string)
+			}
+			if _, ok := expanded.ConflictPreferences[unit]; !ok {
+				expanded.ConflictPreferences[unit] = source
+			}
+		}
+	}
+	addPreferences(profile.ConflictPreferences)
+
+	seenMods := make(map[string]bool)
+	addMods := func(mods []string) {
+		for _, mod := range mods {
+			if !seenMods[mod] {
+				seenMods[mod] = true
+				expanded.Mods = append(expanded.Mods, mod)
+			}
+		}
+	}
+	addMods(profile.Mods)
+
+	for _, id := range profile.Includes {
+		id = strings.ToLower(id)
+		if visiting[id] {
+			return nil, fmt.Errorf("profile '%s' includes itself (cycle via '%s')", profile.ID, id)
+		}
+
+		included, err := l.GetProfile(id)
+		if err != nil {
+			return nil, fmt.Errorf("profile '%s' includes unknown profile '%s'", profile.ID, id)
+		}
+
+		if len(included.Includes) > 0 {
+			visiting[id] = true
+			included, err = l.expandComposite(included, visiting)
+			delete(visiting, id)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		addMods(included.Mods)
+
+		if expanded.FactionUnitType == "" {
+			expanded.FactionUnitType = included.FactionUnitType
+		}
+		if expanded.Version == "" {
+			expanded.Version = included.Version
+		}
+		if expanded.Build == "" {
+			expanded.Build = included.Build
+		}
+		if expanded.Author == "" {
+			expanded.Author = included.Author
+		}
+		if expanded.Description == "" {
+			expanded.Description = included.Description
+		}
+		if expanded.DateCreated == "" {
+			expanded.DateCreated = included.DateCreated
+		}
+		if expanded.BackgroundImage == "" {
+			expanded.BackgroundImage = included.BackgroundImage
+		}
+		if expanded.IconOverrides == "" {
+			expanded.IconOverrides = included.IconOverrides
+		}
+		if expanded.TeamColors == nil {
+			expanded.TeamColors = included.TeamColors
+		}
+		if len(expanded.Commanders) == 0 {
+			expanded.Commanders = included.Commanders
+		}
+		expanded.ExcludeCommanders = append(expanded.ExcludeCommanders, included.ExcludeCommanders...)
+		expanded.OptOutMods = append(expanded.OptOutMods, included.OptOutMods...)
+		addIcons(included.Icons)
+		addPreferences(included.ConflictPreferences)
+	}
+
+	// Each member passed parseProfile on its own, but pinned commanders from one and
+	// exclusions from another still conflict once merged
+	if len(expanded.Commanders) > 0 && len(expanded.ExcludeCommanders) > 0 {
+		return nil, fmt.Errorf("profile '%s' ends up with both commanders and excludeCommanders after merging its includes, which are mutually exclusive (keep only one of them across the composite and its includes)", profile.ID)
+	}
+
+	// With no faction unit type anywhere in the chain, every member is an addon,
+	// so the combined faction must use addon (exclusion) filtering as well.
+	if expanded.FactionUnitType == "" {
+		expanded.IsAddon = true
+	}
+
+	return &expanded, nil
+}
+
+// validateProfile checks a profile file against the profile schema, naming the field and
+// line of each problem, so authors don't have to decode a generic unmarshal error
+func validateProfile(data []byte) error {
+	violations := schema.Validate(profileSchema(), data)
+	if len(violations) == 0 {
+		return nil
+	}
+	lines := make([]string, len(violations))
+	for i, v := range violations {
+		lines[i] = "  " + v.String()
+	}
+	return fmt.Errorf("does not match faction-profile.schema.json:\n%s\n\nSee schema/faction-profile.schema.json or the built-in profiles for the format", strings.Join(lines, "\n"))
+}
+
 // parseProfile parses JSON data into a FactionProfile.
 func parseProfile(data []byte, filename string) (*models.FactionProfile, error) {
 	var profile models.FactionProfile
@@ -144,9 +314,10 @@ func parseProfile(data []byte, filename string) (*models.FactionProfile, error)
 		return nil, fmt.Errorf("displayName is required")
 	}
 
-	// Require factionUnitType unless this is an addon profile.
-	// Addon profiles filter by exclusion (remove base game units) rather than by unit type.
-	if profile.FactionUnitType == "" && !profile.IsAddon {
+	// Require factionUnitType unless this is an addon or composite profile.
+	// Addon profiles filter by exclusion (remove base game units) rather than by unit type;
+	// composite profiles inherit it from their included profiles.
+	if profile.FactionUnitType == "" && !profile.IsAddon && len(profile.Includes) == 0 {
 		return nil, fmt.Errorf("factionUnitType is required (or set isAddon: true for addon mods)")
 	}
 
@@ -155,5 +326,16 @@ func parseProfile(data []byte, filename string) (*models.FactionProfile, error)
 		return nil, fmt.Errorf("factionUnitType must be alphanumeric identifier (e.g., Custom1, Custom58), got: %s", profile.FactionUnitType)
 	}
 
+	// Pinning commanders replaces the default set, so excluding from it is meaningless
+	if len(profile.Commanders) > 0 && len(profile.ExcludeCommanders) > 0 {
+		return nil, fmt.Errorf("commanders and excludeCommanders are mutually exclusive (pin the exact set with commanders, or exclude from the default set)")
+	}
+
+	for id, icon := range profile.Icons {
+		if id == "" || !strings.HasPrefix(icon, "/") {
+			return nil, fmt.Errorf("icons must map unit IDs to resource paths starting with / (e.g. \"tank\": \"/ui/mods/my_mod/img/tank.png\"), got %q: %q", id, icon)
+		}
+	}
+
 	return &profile, nil
 }
@@ -1,7 +1,13 @@
 package profiles
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/profiles/embedded"
 )
 
 // TestParseProfileValidation tests profile validation rules
@@ -39,6 +45,25 @@ func TestParseProfileValidation(t *testing.T) {
 			}`,
 			expectError: false,
 		},
+		{
+			name: "valid icon mapping",
+			json: `{
+				"displayName": "Test Faction",
+				"factionUnitType": "Custom58",
+				"icons": {"tank": "/ui/mods/test/img/tank.png"}
+			}`,
+			expectError: false,
+		},
+		{
+			name: "icon mapping without resource path",
+			json: `{
+				"displayName": "Test Faction",
+				"factionUnitType": "Custom58",
+				"icons": {"tank": "img/tank.png"}
+			}`,
+			expectError: true,
+			errorMsg:    "icons must map unit IDs to resource paths",
+		},
 		{
 			name: "missing displayName",
 			json: `{
@@ -64,6 +89,34 @@ func TestParseProfileValidation(t *testing.T) {
 			expectError: true,
 			errorMsg:    "factionUnitType must be alphanumeric",
 		},
+		{
+			name: "valid composite profile without factionUnitType",
+			json: `{
+				"displayName": "Legion Plus",
+				"includes": ["legion", "second-wave"]
+			}`,
+			expectError: false,
+		},
+		{
+			name: "valid profile with excluded commanders",
+			json: `{
+				"displayName": "Test Faction",
+				"factionUnitType": "Custom1",
+				"excludeCommanders": ["test_commander"]
+			}`,
+			expectError: false,
+		},
+		{
+			name: "pinned and excluded commanders together",
+			json: `{
+				"displayName": "Test Faction",
+				"factionUnitType": "Custom1",
+				"commanders": ["l_commander"],
+				"excludeCommanders": ["test_commander"]
+			}`,
+			expectError: true,
+			errorMsg:    "mutually exclusive",
+		},
 		{
 			name: "valid alphanumeric factionUnitType",
 			json: `{
@@ -130,6 +183,192 @@ func TestParseProfileIDDerivation(t *testing.T) {
 	}
 }
 
+// TestExpandComposite tests that composite profiles merge included profiles in priority order
+func TestExpandComposite(t *testing.T) {
+	l := &Loader{
+		profiles: map[string]*models.FactionProfile{
+			"legion": {
+				ID:              "legion",
+				DisplayName:     "Legion",
+				FactionUnitType: "Custom1",
+				Version:         "1.32.1",
+				Mods:            []string{"com.pa.legion-server", "com.pa.legion-client"},
+				Icons:           map[string]string{"l_tank": "/ui/mods/legion/l_tank.png", "l_bot": "/ui/mods/legion/l_bot.png"},
+				IconOverrides:   "/profiles/icons/legion",
+				ConflictPreferences: map[string]string{
+					"/pa/units/land/l_tank/l_tank.json": "com.pa.legion-server",
+					"/pa/units/land/l_bot/l_bot.json":   "com.pa.legion-client",
+				},
+			},
+			"second-wave": {
+				ID:          "second-wave",
+				DisplayName: "Second Wave",
+				IsAddon:     true,
+				Mods:        []string{"com.pa.second-wave", "com.pa.legion-client"},
+				OptOutMods:  []string{"com.pa.second-wave"},
+			},
+			"fixes": {
+				ID:          "fixes",
+				DisplayName: "Fixes",
+				IsAddon:     true,
+				Mods:        []string{"com.pa.fixes"},
+			},
+			"pinned": {
+				ID:          "pinned",
+				DisplayName: "Pinned",
+				IsAddon:     true,
+				Commanders:  []string{"imperial_delta"},
+			},
+			"no-alpha": {
+				ID:                "no-alpha",
+				DisplayName:       "No Alpha",
+				IsAddon:           true,
+				ExcludeCommanders: []string{"imperial_alpha"},
+			},
+			"loop": {
+				ID:          "loop",
+				DisplayName: "Loop",
+				Includes:    []string{"loop"},
+			},
+		},
+	}
+
+	t.Run("merges mods and inherits faction type", func(t *testing.T) {
+		composite := &models.FactionProfile{
+			ID:          "legion-plus",
+			DisplayName: "Legion Plus",
+			Mods:        []string{"com.pa.local-patch"},
+			Includes:    []string{"fixes", "Legion", "second-wave"},
+			Icons:       map[string]string{"l_tank": "/ui/mods/patch/l_tank.png"},
+			OptOutMods:  []string{"com.pa.local-patch"},
+			ConflictPreferences: map[string]string{
+				"/pa/units/land/l_tank/l_tank.json": "com.pa.local-patch",
+			},
+		}
+
+		expanded, err := l.ExpandComposite(composite)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		wantMods := []string{"com.pa.local-patch", "com.pa.fixes", "com.pa.legion-server", "com.pa.legion-client", "com.pa.second-wave"}
+		if len(expanded.Mods) != len(wantMods) {
+			t.Fatalf("Mods = %v, want %v", expanded.Mods, wantMods)
+		}
+		for i, mod := range wantMods {
+			if expanded.Mods[i] != mod {
+				t.Errorf("Mods[%d] = %q, want %q", i, expanded.Mods[i], mod)
+			}
+		}
+		if expanded.FactionUnitType != "Custom1" {
+			t.Errorf("FactionUnitType = %q, want Custom1", expanded.FactionUnitType)
+		}
+		if expanded.IsAddon {
+			t.Error("Expected composite with a base faction not to be an addon")
+		}
+		if expanded.Version != "1.32.1" {
+			t.Errorf("Version = %q, want 1.32.1", expanded.Version)
+		}
+		if expanded.DisplayName != "Legion Plus" {
+			t.Errorf("DisplayName = %q, want Legion Plus", expanded.DisplayName)
+		}
+		wantIcons := map[string]string{"l_tank": "/ui/mods/patch/l_tank.png", "l_bot": "/ui/mods/legion/l_bot.png"}
+		if !reflect.DeepEqual(expanded.Icons, wantIcons) {
+			t.Errorf("Icons = %v, want %v", expanded.Icons, wantIcons)
+		}
+		if expanded.IconOverrides != "/profiles/icons/legion" {
+			t.Errorf("IconOverrides = %q, want /profiles/icons/legion", expanded.IconOverrides)
+		}
+		wantPrefs := map[string]string{
+			"/pa/units/land/l_tank/l_tank.json": "com.pa.local-patch",
+			"/pa/units/land/l_bot/l_bot.json":   "com.pa.legion-client",
+		}
+		if !reflect.DeepEqual(expanded.ConflictPreferences, wantPrefs) {
+			t.Errorf("ConflictPreferences = %v, want %v", expanded.ConflictPreferences, wantPrefs)
+		}
+		wantOptOut := []string{"com.pa.local-patch", "com.pa.second-wave"}
+		if !reflect.DeepEqual(expanded.OptOutMods, wantOptOut) {
+			t.Errorf("OptOutMods = %v, want %v", expanded.OptOutMods, wantOptOut)
+		}
+		if len(composite.Mods) != 1 {
+			t.Errorf("ExpandComposite modified the input profile: %v", composite.Mods)
+		}
+	})
+
+	t.Run("addon-only composite is an addon", func(t *testing.T) {
+		expanded, err := l.ExpandComposite(&models.FactionProfile{
+			ID:          "addons",
+			DisplayName: "Addons",
+			Includes:    []string{"second-wave", "fixes"},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !expanded.IsAddon {
+			t.Error("Expected addon-only composite to be an addon")
+		}
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		_, err := l.ExpandComposite(&models.FactionProfile{ID: "bad", Includes: []string{"missing"}})
+		if err == nil || !contains(err.Error(), "unknown profile 'missing'") {
+			t.Errorf("Expected unknown profile error, got %v", err)
+		}
+	})
+
+	t.Run("pinned and excluded commanders from different includes", func(t *testing.T) {
+		_, err := l.ExpandComposite(&models.FactionProfile{ID: "mixed", Includes: []string{"pinned", "no-alpha"}})
+		if err == nil || !contains(err.Error(), "mutually exclusive") {
+			t.Errorf("Expected commanders conflict error, got %v", err)
+		}
+	})
+
+	t.Run("include cycle", func(t *testing.T) {
+		_, err := l.ExpandComposite(&models.FactionProfile{ID: "outer", Includes: []string{"loop"}})
+		if err == nil || !contains(err.Error(), "includes itself") {
+			t.Errorf("Expected cycle error, got %v", err)
+		}
+	})
+}
+
+func TestLoadLocalProfilesIconOverrides(t *testing.T) {
+	dir := t.TempDir()
+	profiles := map[string]string{
+		"relative.json": `{"displayName": "Relative", "factionUnitType": "Custom1", "iconOverrides": "icons/relative"}`,
+		"absolute.json": `{"displayName": "Absolute", "factionUnitType": "Custom1", "iconOverrides": "` + filepath.ToSlash(filepath.Join(dir, "elsewhere")) + `"}`,
+	}
+	for name, data := range profiles {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l, err := NewLoader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.LoadLocalProfiles(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"relative", filepath.Join(dir, "icons", "relative")},
+		{"absolute", filepath.Join(dir, "elsewhere")},
+	}
+	for _, tt := range tests {
+		profile, err := l.GetProfile(tt.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Clean(profile.IconOverrides) != tt.want {
+			t.Errorf("%s iconOverrides = %q, want %q", tt.id, profile.IconOverrides, tt.want)
+		}
+	}
+}
+
 // Helper function to check if string contains substring
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
@@ -144,3 +383,79 @@ func searchString(s, substr string) bool {
 	}
 	return false
 }
+
+func TestLoadLocalProfilesSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name: "mods not an array",
+			data: `{
+  "displayName": "Broken",
+  "factionUnitType": "Custom1",
+  "mods": "com.example.mod"
+}`,
+			wantErr: "mods must be an array of strings at line 4",
+		},
+		{
+			name: "misspelled field",
+			data: `{
+  "displayName": "Broken",
+  "factionUnitTyp": "Custom1"
+}`,
+			wantErr: "factionUnitTyp is not a known field at line 3",
+		},
+		{
+			name: "schema reference allowed",
+			data: `{
+  "$schema": "../schema/faction-profile.schema.json",
+  "displayName": "Fine",
+  "factionUnitType": "Custom1"
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "custom.json"), []byte(tt.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			l, err := NewLoader()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = l.LoadLocalProfiles(dir)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("LoadLocalProfiles() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadLocalProfiles() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEmbeddedProfilesMatchSchema(t *testing.T) {
+	entries, err := embedded.Profiles.ReadDir(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := embedded.Profiles.ReadFile(entry.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := validateProfile(data); err != nil {
+			t.Errorf("%s: %v", entry.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package profiles
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/profiles/embedded"
+)
+
+// SaveConflictPreferences writes prefs as the conflictPreferences of a profile's file and
+// returns the path written. A local profile is edited in place; a built-in profile is copied
+// into profileDir with the preferences added, which then overrides it. The profile's other
+// fields keep their order. Profiles built from flags have no file and return an error.
+func SaveConflictPreferences(profile *models.FactionProfile, profileDir string, prefs map[string]string) (string, error) {
+	path := profile.Path
+	var data []byte
+	var err error
+	if path != "" {
+		data, err = os.ReadFile(path)
+	} else {
+		path = filepath.Join(profileDir, profile.ID+".json")
+		data, err = embedded.Profiles.ReadFile(profile.ID + ".json")
+		if err == nil {
+			// The new local copy points editors at the profile schema
+			data, err = setField(data, "$schema", models.SchemaURL("faction-profile"))
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("profile '%s' has no file to save conflict preferences to: %w", profile.ID, err)
+	}
+
+	data, err = setField(data, "conflictPreferences", prefs)
+	if err != nil {
+		return "", fmt.Errorf("failed to update profile %s: %w", path, err)
+	}
+	if _, err := parseProfile(data, filepath.Base(path)); err != nil {
+		return "", fmt.Errorf("updated profile %s is invalid: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write profile %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// setField sets one top-level field of a JSON object, keeping the other fields in their
+// original order, and returns the object indented with two spaces. A new field is added
+// last, except $schema, which goes first.
+func setField(data []byte, key string, value any) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+
+	var keys []string
+	values := make(map[string]json.RawMessage)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		k, _ := tok.(string)
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		if _, seen := values[k]; !seen {
+			keys = append(keys, k)
+		}
+		values[k] = v
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := values[key]; !ok {
+		if key == "$schema" {
+			keys = append([]string{key}, keys...)
+		} else {
+			keys = append(keys, key)
+		}
+	}
+	values[key] = encoded
+
+	var out bytes.Buffer
+	out.WriteString("{\n")
+	for i, k := range keys {
+		name, _ := json.Marshal(k)
+		out.WriteString("  ")
+		out.Write(name)
+		out.WriteString(": ")
+		if err := json.Indent(&out, values[k], "  ", "  "); err != nil {
+			return nil, err
+		}
+		if i < len(keys)-1 {
+			out.WriteString(",")
+		}
+		out.WriteString("\n")
+	}
+	out.WriteString("}\n")
+	return out.Bytes(), nil
+}
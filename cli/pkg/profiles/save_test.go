@@ -0,0 +1,86 @@
+package profiles
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestSaveConflictPreferences(t *testing.T) {
+	prefs := map[string]string{"/pa/units/land/tank/tank.json": "com.test.b"}
+
+	t.Run("local profile edited in place", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "mine.json")
+		original := `{"displayName": "Mine", "factionUnitType": "Custom1", "conflictPreferences": {"/old.json": "x"}, "mods": ["com.test.a", "com.test.b"]}`
+		if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		written, err := SaveConflictPreferences(&models.FactionProfile{ID: "mine", Path: path}, dir, prefs)
+		if err != nil {
+			t.Fatalf("SaveConflictPreferences failed: %v", err)
+		}
+		if written != path {
+			t.Errorf("wrote %s, want %s", written, path)
+		}
+		data, _ := os.ReadFile(path)
+		profile, err := parseProfile(data, "mine.json")
+		if err != nil {
+			t.Fatalf("saved profile doesn't parse: %v", err)
+		}
+		if !reflect.DeepEqual(profile.ConflictPreferences, prefs) {
+			t.Errorf("conflictPreferences = %v, want %v", profile.ConflictPreferences, prefs)
+		}
+		// Existing fields keep their order; the replaced field keeps its place
+		text := string(data)
+		if !(strings.Index(text, "displayName") < strings.Index(text, "conflictPreferences") &&
+			strings.Index(text, "conflictPreferences") < strings.Index(text, `"mods"`)) {
+			t.Errorf("field order changed:\n%s", text)
+		}
+	})
+
+	t.Run("built-in profile copied to profile dir", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "profiles")
+		written, err := SaveConflictPreferences(&models.FactionProfile{ID: "legion"}, dir, prefs)
+		if err != nil {
+			t.Fatalf("SaveConflictPreferences failed: %v", err)
+		}
+		if written != filepath.Join(dir, "legion.json") {
+			t.Errorf("wrote %s, want legion.json in %s", written, dir)
+		}
+		data, err := os.ReadFile(written)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `{
+  "$schema": "` + models.SchemaURL("faction-profile") + `",`; !strings.HasPrefix(string(data), want) {
+			t.Errorf("local copy should start with its $schema reference:\n%s", data)
+		}
+
+		l, err := NewLoader()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := l.LoadLocalProfiles(dir); err != nil {
+			t.Fatal(err)
+		}
+		profile, err := l.GetProfile("legion")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if profile.DisplayName != "Legion" || !reflect.DeepEqual(profile.ConflictPreferences, prefs) {
+			t.Errorf("local copy = %+v, want Legion with %v", profile, prefs)
+		}
+	})
+
+	t.Run("manual profile has no file", func(t *testing.T) {
+		if _, err := SaveConflictPreferences(&models.FactionProfile{ID: "My Faction"}, t.TempDir(), prefs); err == nil {
+			t.Error("expected an error for a profile built from flags")
+		}
+	})
+}
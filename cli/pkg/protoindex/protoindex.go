@@ -0,0 +1,345 @@
+// Package protoindex encodes the faction models in protobuf wire format without
+// generated code. Message layouts are derived from the Go structs by reflection:
+// each exported JSON field gets the field number of its position in the struct
+// (starting at 1), and Schema renders the matching .proto definition.
+//
+// Because numbers follow declaration order, new model fields must be appended
+// to the end of their struct; inserting or reordering fields renumbers the ones
+// after them and breaks previously written .pb files.
+package protoindex
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// field describes one encoded struct field
+type field struct {
+	index    int          // Go struct field index
+	number   int          // protobuf field number
+	jsonName string       // JSON name from the json tag
+	typ      reflect.Type // Go field type
+	doc      string       // jsonschema description, used in Schema
+	omitted  bool         // omitempty: JSON leaves the field out when it is zero
+}
+
+var fieldCache sync.Map // reflect.Type -> []field
+
+// fieldsOf returns the encoded fields of a struct type in field-number order
+func fieldsOf(t reflect.Type) []field {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]field)
+	}
+
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" || strings.HasPrefix(name, "$") { // JSON-only keywords such as $schema
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		doc := ""
+		if _, after, ok := strings.Cut(sf.Tag.Get("jsonschema"), "description="); ok {
+			doc = after
+		}
+		fields = append(fields, field{
+			index:    i,
+			number:   len(fields) + 1,
+			jsonName: name,
+			typ:      sf.Type,
+			doc:      doc,
+			omitted:  strings.Contains(","+opts+",", ",omitempty,"),
+		})
+	}
+
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+// Marshal encodes v, a struct or pointer to struct, as a protobuf message.
+// Zero scalars are omitted (proto3 implicit presence); pointer scalars are
+// written whenever they are non-nil so an explicit false or 0 survives.
+func Marshal(v any) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("cannot marshal nil %s", rv.Type())
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot marshal %s: expected a struct", rv.Type())
+	}
+	return appendMessage(nil, rv)
+}
+
+func appendMessage(buf []byte, rv reflect.Value) ([]byte, error) {
+	var err error
+	for _, f := range fieldsOf(rv.Type()) {
+		buf, err = appendField(buf, f.number, rv.Field(f.index), false)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", rv.Type().Name(), f.jsonName, err)
+		}
+	}
+	return buf, nil
+}
+
+// appendField writes one field. explicit forces zero scalars to be written,
+// which is how pointer scalars keep their presence.
+func appendField(buf []byte, number int, v reflect.Value, explicit bool) ([]byte, error) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.Len() == 0 && !explicit {
+			return buf, nil
+		}
+		buf = appendTag(buf, number, wireBytes)
+		buf = binary.AppendUvarint(buf, uint64(v.Len()))
+		return append(buf, v.String()...), nil
+
+	case reflect.Bool:
+		if !v.Bool() && !explicit {
+			return buf, nil
+		}
+		buf = appendTag(buf, number, wireVarint)
+		if v.Bool() {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		if v.Int() == 0 && !explicit {
+			return buf, nil
+		}
+		buf = appendTag(buf, number, wireVarint)
+		return binary.AppendUvarint(buf, uint64(v.Int())), nil
+
+	case reflect.Float64:
+		if v.Float() == 0 && !explicit {
+			return buf, nil
+		}
+		buf = appendTag(buf, number, wireFixed64)
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(v.Float())), nil
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return buf, nil
+		}
+		return appendField(buf, number, v.Elem(), true)
+
+	case reflect.Struct:
+		msg, err := appendMessage(nil, v)
+		if err != nil {
+			return nil, err
+		}
+		buf = appendTag(buf, number, wireBytes)
+		buf = binary.AppendUvarint(buf, uint64(len(msg)))
+		return append(buf, msg...), nil
+
+	case reflect.Slice:
+		if isPackable(v.Type().Elem()) {
+			return appendPacked(buf, number, v), nil
+		}
+		var err error
+		for i := 0; i < v.Len(); i++ {
+			buf, err = appendField(buf, number, v.Index(i), true)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", v.Type())
+}
+
+// isPackable reports whether a repeated field of t uses packed encoding
+func isPackable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int32, reflect.Int64, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func appendPacked(buf []byte, number int, v reflect.Value) []byte {
+	if v.Len() == 0 {
+		return buf
+	}
+	var packed []byte
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		switch elem.Kind() {
+		case reflect.Bool:
+			if elem.Bool() {
+				packed = append(packed, 1)
+			} else {
+				packed = append(packed, 0)
+			}
+		case reflect.Float64:
+			packed = binary.LittleEndian.AppendUint64(packed, math.Float64bits(elem.Float()))
+		default:
+			packed = binary.AppendUvarint(packed, uint64(elem.Int()))
+		}
+	}
+	buf = appendTag(buf, number, wireBytes)
+	buf = binary.AppendUvarint(buf, uint64(len(packed)))
+	return append(buf, packed...)
+}
+
+func appendTag(buf []byte, number, wireType int) []byte {
+	return binary.AppendUvarint(buf, uint64(number)<<3|uint64(wireType))
+}
+
+// Unmarshal decodes a protobuf message produced by Marshal into v, which must
+// be a non-nil pointer to a struct. Unknown field numbers are skipped, so
+// readers built against an older model still accept newer files.
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot unmarshal into %T: expected a pointer to a struct", v)
+	}
+	return decodeMessage(data, rv.Elem())
+}
+
+func decodeMessage(data []byte, rv reflect.Value) error {
+	fields := fieldsOf(rv.Type())
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("%s: malformed field key", rv.Type().Name())
+		}
+		data = data[n:]
+		number, wireType := int(key>>3), int(key&7)
+
+		raw, rest, err := splitValue(data, wireType)
+		if err != nil {
+			return fmt.Errorf("%s: field %d: %w", rv.Type().Name(), number, err)
+		}
+		data = rest
+
+		if number < 1 || number > len(fields) {
+			continue
+		}
+		f := fields[number-1]
+		if err := decodeField(raw, wireType, rv.Field(f.index)); err != nil {
+			return fmt.Errorf("%s.%s: %w", rv.Type().Name(), f.jsonName, err)
+		}
+	}
+	return nil
+}
+
+// splitValue returns the encoded value at the start of data and the remainder.
+// Varints are returned with their bytes; length-delimited values without the length.
+func splitValue(data []byte, wireType int) (raw, rest []byte, err error) {
+	switch wireType {
+	case wireVarint:
+		_, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, nil, fmt.Errorf("malformed varint")
+		}
+		return data[:n], data[n:], nil
+	case wireFixed64:
+		if len(data) < 8 {
+			return nil, nil, fmt.Errorf("truncated fixed64")
+		}
+		return data[:8], data[8:], nil
+	case wireFixed32:
+		if len(data) < 4 {
+			return nil, nil, fmt.Errorf("truncated fixed32")
+		}
+		return data[:4], data[4:], nil
+	case wireBytes:
+		length, n := binary.Uvarint(data)
+		if n <= 0 || length > uint64(len(data)-n) {
+			return nil, nil, fmt.Errorf("truncated length-delimited value")
+		}
+		end := n + int(length)
+		return data[n:end], data[end:], nil
+	}
+	return nil, nil, fmt.Errorf("unsupported wire type %d", wireType)
+}
+
+func decodeField(raw []byte, wireType int, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeField(raw, wireType, v.Elem())
+
+	case reflect.Slice:
+		elemType := v.Type().Elem()
+		if isPackable(elemType) && wireType == wireBytes {
+			for len(raw) > 0 {
+				elemWire := wireVarint
+				if elemType.Kind() == reflect.Float64 {
+					elemWire = wireFixed64
+				}
+				value, rest, err := splitValue(raw, elemWire)
+				if err != nil {
+					return err
+				}
+				raw = rest
+				elem := reflect.New(elemType).Elem()
+				if err := decodeField(value, elemWire, elem); err != nil {
+					return err
+				}
+				v.Set(reflect.Append(v, elem))
+			}
+			return nil
+		}
+		elem := reflect.New(elemType).Elem()
+		if err := decodeField(raw, wireType, elem); err != nil {
+			return err
+		}
+		v.Set(reflect.Append(v, elem))
+		return nil
+	}
+
+	want := wireBytes
+	switch v.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int32, reflect.Int64:
+		want = wireVarint
+	case reflect.Float64:
+		want = wireFixed64
+	}
+	if wireType != want {
+		return fmt.Errorf("wire type %d does not match %s", wireType, v.Type())
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(string(raw))
+	case reflect.Bool:
+		x, _ := binary.Uvarint(raw)
+		v.SetBool(x != 0)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		x, _ := binary.Uvarint(raw)
+		v.SetInt(int64(x))
+	case reflect.Float64:
+		v.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(raw)))
+	case reflect.Struct:
+		return decodeMessage(raw, v)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
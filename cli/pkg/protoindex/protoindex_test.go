@@ -0,0 +1,197 @@
+package protoindex
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestMarshalWireFormat(t *testing.T) {
+	type sample struct {
+		Name    string   `json:"name"`
+		Tier    int      `json:"tier"`
+		Skipped string   `json:"-"`
+		Enabled *bool    `json:"enabled,omitempty"`
+		Tags    []string `json:"tags"`
+		Rate    float64  `json:"rate"`
+	}
+	disabled := false
+
+	tests := []struct {
+		name  string
+		input sample
+		want  []byte
+	}{
+		{
+			name:  "zero values are omitted",
+			input: sample{},
+			want:  nil,
+		},
+		{
+			name:  "string and varint",
+			input: sample{Name: "ant", Tier: 2},
+			want:  []byte{0x0a, 3, 'a', 'n', 't', 0x10, 2},
+		},
+		{
+			name:  "explicit false pointer is written",
+			input: sample{Enabled: &disabled},
+			want:  []byte{0x18, 0},
+		},
+		{
+			name:  "repeated strings and double",
+			input: sample{Tags: []string{"a", "b"}, Rate: 1},
+			want:  []byte{0x22, 1, 'a', 0x22, 1, 'b', 0x29, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.input)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Marshal() = % x, want % x", got, tt.want)
+			}
+
+			var decoded sample
+			if err := Unmarshal(got, &decoded); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(decoded, tt.input) {
+				t.Errorf("Unmarshal() = %+v, want %+v", decoded, tt.input)
+			}
+		})
+	}
+}
+
+func TestUnmarshalSkipsUnknownFields(t *testing.T) {
+	type v1 struct {
+		Name string `json:"name"`
+	}
+	type v2 struct {
+		Name  string  `json:"name"`
+		Extra float64 `json:"extra"`
+		More  []int   `json:"more"`
+	}
+
+	data, err := Marshal(v2{Name: "ant", Extra: 3, More: []int{1, -1}})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var older v1
+	if err := Unmarshal(data, &older); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if older.Name != "ant" {
+		t.Errorf("Name = %q, want ant", older.Name)
+	}
+
+	var newer v2
+	if err := Unmarshal(data, &newer); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(newer.More, []int{1, -1}) {
+		t.Errorf("More = %v, want [1 -1]", newer.More)
+	}
+}
+
+func TestUnmarshalTruncated(t *testing.T) {
+	type sample struct {
+		Name string `json:"name"`
+	}
+	var s sample
+	if err := Unmarshal([]byte{0x0a, 5, 'a'}, &s); err == nil {
+		t.Error("expected an error for a truncated string")
+	}
+}
+
+func TestFactionIndexRoundTrip(t *testing.T) {
+	enabled := true
+	index := models.FactionIndex{
+		Layout: "mirrored",
+		Units: []models.UnitIndexEntry{{
+			Identifier: "tank",
+			UnitTypes:  []string{"Mobile", "Tank"},
+			Files:      []models.UnitFile{{Path: "pa/units/land/tank/tank.json", Source: "pa"}},
+			Unit: models.Unit{
+				ID:   "tank",
+				Tier: 1,
+				Specs: models.UnitSpecs{
+					Combat: &models.CombatSpecs{
+						Health:  200,
+						Weapons: []models.Weapon{{SafeName: "gun", DPS: 12.5, Enabled: &enabled}},
+					},
+					Economy: &models.EconomySpecs{BuildCost: 150, Production: models.Resources{Energy: -5}},
+				},
+				BuildRestriction: &models.RestrictionNode{
+					Op:    models.RestrictionAnd,
+					Left:  &models.RestrictionNode{Op: models.RestrictionType, Type: "Land"},
+					Right: &models.RestrictionNode{Op: models.RestrictionType, Type: "Mobile"},
+				},
+			},
+		}},
+	}
+
+	data, err := Marshal(&index)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var decoded models.FactionIndex
+	if err := Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, index) {
+		t.Errorf("round trip mismatch:\n got %+v\nwant %+v", decoded, index)
+	}
+}
+
+// TestSchemaUpToDate guards schema/faction-index.proto: model changes must be
+// followed by `just generate-schema` so the committed .proto matches the encoder.
+func TestSchemaUpToDate(t *testing.T) {
+	want, err := Schema("papedia", &models.FactionIndex{})
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join("..", "..", "..", "schema", "faction-index.proto"))
+	if err != nil {
+		t.Fatalf("failed to read committed schema: %v", err)
+	}
+	if string(got) != want {
+		t.Error("schema/faction-index.proto is out of date; run `just generate-schema`")
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	type ammo struct {
+		Damage float64 `json:"damage,omitempty"`
+	}
+	type weapon struct {
+		Name  string   `json:"name"`
+		Ammo  *ammo    `json:"ammo,omitempty"`
+		Tiers []int    `json:"tiers"`
+		Range *float64 `json:"range,omitempty"`
+	}
+
+	d, err := Describe(&weapon{})
+	if err != nil {
+		t.Fatalf("Describe() error = %v", err)
+	}
+	want := &Descriptor{Root: "weapon", Messages: map[string][]DescriptorField{
+		"weapon": {
+			{Number: 1, Name: "name", Type: "string"},
+			{Number: 2, Name: "ammo", Type: "ammo", Optional: true, OmitEmpty: true},
+			{Number: 3, Name: "tiers", Type: "int64", Repeated: true},
+			{Number: 4, Name: "range", Type: "double", Optional: true, OmitEmpty: true},
+		},
+		"ammo": {{Number: 1, Name: "damage", Type: "double", OmitEmpty: true}},
+	}}
+	if !reflect.DeepEqual(d, want) {
+		t.Errorf("Describe() = %+v, want %+v", d, want)
+	}
+}
@@ -0,0 +1,171 @@
+package protoindex
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Schema renders a proto3 definition for root and every struct reachable from
+// it, matching the wire layout Marshal produces. Field names are the JSON names
+// in snake_case, so protoc plugins that camelCase fields (e.g. ts-proto)
+// reproduce the JSON property names.
+func Schema(protoPackage string, root any) (string, error) {
+	t := reflect.TypeOf(root)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return "", fmt.Errorf("cannot describe %s: expected a struct", t)
+	}
+
+	var b strings.Builder
+	b.WriteString("// Code generated by pa-pedia schema from the Go models. DO NOT EDIT.\n")
+	b.WriteString("// Field numbers follow struct declaration order; see pkg/protoindex.\n\n")
+	b.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(&b, "package %s;\n", protoPackage)
+
+	// Breadth-first from the root so messages appear in the order they are first used
+	queue := []reflect.Type{t}
+	seen := map[reflect.Type]bool{t: true}
+	for len(queue) > 0 {
+		msg := queue[0]
+		queue = queue[1:]
+
+		fmt.Fprintf(&b, "\nmessage %s {\n", msg.Name())
+		for _, f := range fieldsOf(msg) {
+			label, typeName, nested, err := protoType(f.typ)
+			if err != nil {
+				return "", fmt.Errorf("%s.%s: %w", msg.Name(), f.jsonName, err)
+			}
+			if nested != nil && !seen[nested] {
+				seen[nested] = true
+				queue = append(queue, nested)
+			}
+			if f.doc != "" {
+				fmt.Fprintf(&b, "  // %s\n", f.doc)
+			}
+			fmt.Fprintf(&b, "  %s%s %s = %d;\n", label, typeName, snakeCase(f.jsonName), f.number)
+		}
+		b.WriteString("}\n")
+	}
+
+	return b.String(), nil
+}
+
+// DescriptorField is one field of a message in a Descriptor
+type DescriptorField struct {
+	Number    int    `json:"number"`
+	Name      string `json:"name"` // JSON property name
+	Type      string `json:"type"` // string, bool, int64, double or a message name
+	Repeated  bool   `json:"repeated,omitempty"`
+	Optional  bool   `json:"optional,omitempty"`  // A pointer: absent unless written
+	OmitEmpty bool   `json:"omitEmpty,omitempty"` // JSON leaves the field out when zero
+}
+
+// Descriptor lists the fields of every message reachable from a root, for decoders that
+// can't run protoc, such as the web app's. It carries what a reader needs to rebuild the
+// JSON encoding of a message: the zero value of a field written to JSON without omitempty
+// is filled in when the field is absent from the wire.
+type Descriptor struct {
+	Root     string                       `json:"root"`
+	Messages map[string][]DescriptorField `json:"messages"`
+}
+
+// Describe builds the Descriptor for root, the counterpart of Schema
+func Describe(root any) (*Descriptor, error) {
+	t := reflect.TypeOf(root)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot describe %s: expected a struct", t)
+	}
+
+	d := &Descriptor{Root: t.Name(), Messages: make(map[string][]DescriptorField)}
+	queue := []reflect.Type{t}
+	seen := map[reflect.Type]bool{t: true}
+	for len(queue) > 0 {
+		msg := queue[0]
+		queue = queue[1:]
+
+		fields := []DescriptorField{}
+		for _, f := range fieldsOf(msg) {
+			label, typeName, nested, err := protoType(f.typ)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", msg.Name(), f.jsonName, err)
+			}
+			if nested != nil && !seen[nested] {
+				seen[nested] = true
+				queue = append(queue, nested)
+			}
+			fields = append(fields, DescriptorField{
+				Number:    f.number,
+				Name:      f.jsonName,
+				Type:      typeName,
+				Repeated:  label == "repeated ",
+				Optional:  f.typ.Kind() == reflect.Pointer,
+				OmitEmpty: f.omitted,
+			})
+		}
+		d.Messages[msg.Name()] = fields
+	}
+	return d, nil
+}
+
+// DescriptorJSON renders Describe(root) as indented JSON
+func DescriptorJSON(root any) ([]byte, error) {
+	d, err := Describe(root)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// protoType maps a Go field type to its proto label and type name. nested is
+// the struct type when the field is a message.
+func protoType(t reflect.Type) (label, typeName string, nested reflect.Type, err error) {
+	switch t.Kind() {
+	case reflect.Slice:
+		_, typeName, nested, err = protoType(t.Elem())
+		return "repeated ", typeName, nested, err
+	case reflect.Pointer:
+		label, typeName, nested, err = protoType(t.Elem())
+		if nested == nil {
+			label = "optional "
+		}
+		return label, typeName, nested, err
+	case reflect.Struct:
+		return "", t.Name(), t, nil
+	case reflect.String:
+		return "", "string", nil, nil
+	case reflect.Bool:
+		return "", "bool", nil, nil
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return "", "int64", nil, nil
+	case reflect.Float64:
+		return "", "double", nil, nil
+	}
+	return "", "", nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// snakeCase converts a camelCase JSON name to snake_case (sustainedDps -> sustained_dps)
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+// FactionFiles are the faction folder files ValidateFaction checks, keyed to their entry in
+// Entries. Each resolved unit is checked as part of units.json, whose entries embed the unit
+// schema.
+var FactionFiles = []struct {
+	Name  string
+	Entry string
+}{
+	{"metadata.json", "faction-metadata"},
+	{"units.json", "faction-index"},
+}
+
+// FactionViolation is a schema violation in one file of a faction folder
+type FactionViolation struct {
+	File    string `json:"file"`
+	Unit    string `json:"unit,omitempty"` // Identifier of the unit the path is under, for units.json
+	Path    string `json:"path"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+// String reads like "units.json: tank: units[3].unit.specs.combat.dps must be a number at line 120"
+func (v FactionViolation) String() string {
+	prefix := v.File + ": "
+	if v.Unit != "" {
+		prefix += v.Unit + ": "
+	}
+	return prefix + Violation{Path: v.Path, Line: v.Line, Column: v.Column, Message: v.Message}.String()
+}
+
+// FactionValidation is the result of ValidateFaction
+type FactionValidation struct {
+	Units      int                `json:"units"` // Entries in units.json
+	Violations []FactionViolation `json:"violations"`
+}
+
+// unitPath matches the index entry a units.json path is under
+var unitPath = regexp.MustCompile(`^units\[(\d+)\]`)
+
+// ValidateFaction checks a faction folder's FactionFiles against the generated schemas and
+// returns every violation in file then document order. A missing file is an error.
+func ValidateFaction(factionDir string) (*FactionValidation, error) {
+	result := &FactionValidation{Violations: []FactionViolation{}}
+	for _, file := range FactionFiles {
+		data, err := os.ReadFile(filepath.Join(factionDir, file.Name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w\n\nPass a folder produced by describe-faction", file.Name, err)
+		}
+		entry, ok := entryNamed(file.Entry)
+		if !ok {
+			return nil, fmt.Errorf("no schema named %s for %s", file.Entry, file.Name)
+		}
+
+		var ids []string
+		if file.Name == "units.json" {
+			ids = unitIdentifiers(data)
+			result.Units = len(ids)
+		}
+		for _, v := range Validate(Reflect(entry), data) {
+			fv := FactionViolation{File: file.Name, Path: v.Path, Line: v.Line, Column: v.Column, Message: v.Message}
+			if m := unitPath.FindStringSubmatch(v.Path); m != nil {
+				if i, err := strconv.Atoi(m[1]); err == nil && i < len(ids) {
+					fv.Unit = ids[i]
+				}
+			}
+			result.Violations = append(result.Violations, fv)
+		}
+	}
+	return result, nil
+}
+
+// unitIdentifiers returns the identifier of each units.json entry in order, as far as the
+// document can be read; entries without a string identifier get ""
+func unitIdentifiers(data []byte) []string {
+	var index struct {
+		Units []map[string]any `json:"units"`
+	}
+	if json.Unmarshal(data, &index) != nil {
+		return nil
+	}
+	ids := make([]string, len(index.Units))
+	for i, u := range index.Units {
+		ids[i], _ = u["identifier"].(string)
+	}
+	return ids
+}
+
+func entryNamed(name string) (Entry, bool) {
+	for _, e := range Entries {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
@@ -0,0 +1,48 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidateFaction(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"metadata.json": `{"identifier": "mla", "displayName": "MLA", "version": "1.0.0", "type": "base-game", "mods": []}`,
+		"units.json": `{"units": [
+  {"identifier": "tank", "displayName": "Ant", "unitTypes": [], "source": "pa", "files": [],
+   "unit": {"id": "tank", "resourceName": "/pa/units/land/tank/tank.json", "displayName": "Ant", "tier": 1, "unitTypes": [], "accessible": true, "specs": {"combat": {"health": "lots"}}}},
+  {"identifier": "bot", "displayName": "Dox", "unitTypes": [], "source": "pa", "files": [], "colour": "red",
+   "unit": {"id": "bot", "resourceName": "/pa/units/land/bot/bot.json", "displayName": "Dox", "tier": 1, "unitTypes": [], "accessible": true, "specs": {}}}
+]}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := ValidateFaction(dir)
+	if err != nil {
+		t.Fatalf("ValidateFaction() error = %v", err)
+	}
+	if result.Units != 2 {
+		t.Errorf("Units = %d, want 2", result.Units)
+	}
+	var got []string
+	for _, v := range result.Violations {
+		got = append(got, v.File+" "+v.Unit+" "+v.Path)
+	}
+	want := []string{"units.json tank units[0].unit.specs.combat.health", "units.json bot units[1].colour"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violations = %q (%v), want %q", got, result.Violations, want)
+	}
+}
+
+func TestValidateFactionMissingFile(t *testing.T) {
+	if _, err := ValidateFaction(t.TempDir()); err == nil {
+		t.Error("ValidateFaction() of an empty folder: expected an error")
+	}
+}
@@ -0,0 +1,134 @@
+// Package schema generates the repository's schema/ directory from the Go models: a JSON
+// Schema per file format the CLI writes or reads, and the protobuf definition of units.pb
+// with a JSON descriptor of it.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/invopop/jsonschema"
+	"github.com/jamiemulcahy/pa-pedia/pkg/combat"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/protoindex"
+	"github.com/jamiemulcahy/pa-pedia/pkg/stats"
+)
+
+// Entry is one generated JSON Schema, written as <Name>.schema.json
+type Entry struct {
+	Name string
+	Type any
+}
+
+// Entries are the generated JSON Schemas. New names go at the end so the generated TypeScript
+// types keep their order.
+var Entries = []Entry{
+	{"faction-metadata", &models.FactionMetadata{}},
+	{"faction-database", &models.FactionDatabase{}},
+	{"faction-index", &models.FactionIndex{}},
+	{"faction-profile", &models.FactionProfile{}},
+	{"unit", &models.Unit{}},
+	{"weapon", &models.Weapon{}},
+	{"build-arm", &models.BuildArm{}},
+	{"bundle-manifest", &models.BundleManifest{}},
+	{"faction-versions", &models.VersionsManifest{}},
+	{"faction-credits", &models.Credits{}},
+	{"faction-conflicts", &models.ConflictReport{}},
+	{"unit-history", &models.UnitHistory{}},
+	{"pa-pedia-lock", &models.Lockfile{}},
+	{"id-aliases", &models.IDAliases{}},
+	{"cross-faction-links", &models.CrossFactionLinks{}},
+	{"counters-report", &combat.CountersReport{}},
+	{"stat-distributions", &stats.DistributionReport{}},
+	{"missile-report", &models.MissileReport{}},
+}
+
+// ProtoFile is the protobuf definition of the binary faction index (units.pb)
+const ProtoFile = "faction-index.proto"
+
+// ProtoDescriptorFile describes the same messages as ProtoFile in JSON
+// (protoindex.Descriptor); the web app decodes units.pb with it
+const ProtoDescriptorFile = "faction-index.pb.json"
+
+// extensibleTypes are the models that carry x- prefixed third-party fields (models.Extensions)
+var extensibleTypes = []string{"Unit", "FactionMetadata"}
+
+// Reflect returns an entry's JSON Schema
+func Reflect(entry Entry) *jsonschema.Schema {
+	reflector := &jsonschema.Reflector{
+		AllowAdditionalProperties: false,
+		DoNotReference:            false,
+	}
+	schema := reflector.Reflect(entry.Type)
+	allowExtensions(schema)
+
+	schema.Title = entry.Name
+	schema.Version = "https://json-schema.org/draft/2020-12/schema"
+	return schema
+}
+
+// Proto returns the contents of ProtoFile
+func Proto() (string, error) {
+	return protoindex.Schema("papedia", &models.FactionIndex{})
+}
+
+// Files renders every generated file, keyed by file name
+func Files() (map[string][]byte, error) {
+	files := make(map[string][]byte, len(Entries)+1)
+	for _, entry := range Entries {
+		data, err := json.MarshalIndent(Reflect(entry), "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal schema for %s: %w", entry.Name, err)
+		}
+		files[entry.Name+".schema.json"] = data
+	}
+	proto, err := Proto()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate protobuf schema: %w", err)
+	}
+	files[ProtoFile] = []byte(proto)
+	descriptor, err := protoindex.DescriptorJSON(&models.FactionIndex{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate protobuf descriptor: %w", err)
+	}
+	files[ProtoDescriptorFile] = descriptor
+	return files, nil
+}
+
+// Generate writes every generated file to outputDir and returns their names in Entries order,
+// then ProtoFile and ProtoDescriptorFile
+func Generate(outputDir string) ([]string, error) {
+	files, err := Files()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create schema directory: %w", err)
+	}
+
+	names := make([]string, 0, len(files))
+	for _, entry := range Entries {
+		names = append(names, entry.Name+".schema.json")
+	}
+	names = append(names, ProtoFile, ProtoDescriptorFile)
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(outputDir, name), files[name], 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return names, nil
+}
+
+// allowExtensions lets extensible definitions accept x- prefixed properties while
+// additionalProperties stays false for everything else.
+func allowExtensions(schema *jsonschema.Schema) {
+	for _, name := range extensibleTypes {
+		if def, ok := schema.Definitions[name]; ok {
+			def.PatternProperties = map[string]*jsonschema.Schema{
+				"^" + models.ExtensionPrefix: jsonschema.TrueSchema,
+			}
+		}
+	}
+}
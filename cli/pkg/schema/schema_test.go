@@ -0,0 +1,38 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSchemasUpToDate guards schema/: model changes must be followed by
+// `just generate-schema` so the committed files match the models.
+func TestSchemasUpToDate(t *testing.T) {
+	files, err := Files()
+	if err != nil {
+		t.Fatalf("Files() error = %v", err)
+	}
+	for name, want := range files {
+		got, err := os.ReadFile(filepath.Join("..", "..", "..", "schema", name))
+		if err != nil {
+			t.Errorf("failed to read committed %s: %v", name, err)
+			continue
+		}
+		if string(got) != string(want) {
+			t.Errorf("schema/%s is out of date; run `just generate-schema`", name)
+		}
+	}
+}
+
+func TestReflectAllowsExtensions(t *testing.T) {
+	for _, entry := range Entries {
+		if entry.Name != "unit" {
+			continue
+		}
+		def := Reflect(entry).Definitions["Unit"]
+		if def == nil || def.PatternProperties["^x-"] == nil {
+			t.Errorf("unit schema does not accept x- properties: %+v", def)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// Violation is one place a JSON document doesn't match its schema
+type Violation struct {
+	Path    string // e.g. "mods", "mods[2]" or "teamColors.primary"; empty for the whole document
+	Line    int
+	Column  int
+	Message string // e.g. "must be an array of strings"
+}
+
+// String reads like "mods must be an array of strings at line 7"
+func (v Violation) String() string {
+	subject := v.Path
+	if subject == "" {
+		subject = "document"
+	}
+	return fmt.Sprintf("%s %s at line %d", subject, v.Message, v.Line)
+}
+
+// Validate checks data against s and returns every violation in document order, each with
+// the line and column of the offending value. It covers the keywords the reflected schemas
+// use: $ref into $defs, type, properties, required, additionalProperties,
+// patternProperties, items, enum, pattern and allOf/anyOf/oneOf (oneOf is treated as anyOf).
+// A "$schema" key at the top level is always accepted, so documents can point editors at
+// their schema. Malformed JSON is a single violation at the syntax error.
+func Validate(s *jsonschema.Schema, data []byte) []Violation {
+	root, err := parseDocument(data)
+	if err != nil {
+		// Unmarshal's errors describe what's wrong where the decoder's tokens don't
+		var probe any
+		if uerr := json.Unmarshal(data, &probe); uerr != nil {
+			err = uerr
+		}
+		var syntax *json.SyntaxError
+		offset := len(data)
+		if errors.As(err, &syntax) {
+			offset = int(syntax.Offset)
+		}
+		line, column := position(data, offset)
+		return []Violation{{Line: line, Column: column, Message: fmt.Sprintf("is not valid JSON (%v)", err)}}
+	}
+	v := &validator{defs: s.Definitions, data: data}
+	v.check(s, root, "")
+	return v.violations
+}
+
+type validator struct {
+	defs       jsonschema.Definitions
+	data       []byte
+	violations []Violation
+}
+
+func (v *validator) add(path string, offset int, format string, args ...any) {
+	line, column := position(v.data, offset)
+	v.violations = append(v.violations, Violation{Path: path, Line: line, Column: column, Message: fmt.Sprintf(format, args...)})
+}
+
+// matches reports whether value satisfies s without recording violations
+func (v *validator) matches(s *jsonschema.Schema, value *node, path string) bool {
+	sub := &validator{defs: v.defs, data: v.data}
+	sub.check(s, value, path)
+	return len(sub.violations) == 0
+}
+
+func (v *validator) check(s *jsonschema.Schema, value *node, path string) {
+	s = v.resolve(s)
+	if s == nil || s == jsonschema.TrueSchema {
+		return
+	}
+	if s == jsonschema.FalseSchema {
+		v.add(path, value.offset, "is not allowed")
+		return
+	}
+
+	for _, sub := range s.AllOf {
+		v.check(sub, value, path)
+	}
+	if alternatives := append(slices.Clone(s.AnyOf), s.OneOf...); len(alternatives) > 0 {
+		matched := false
+		for _, sub := range alternatives {
+			if v.matches(sub, value, path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			described := make([]string, len(alternatives))
+			for i, sub := range alternatives {
+				described[i] = v.describe(sub)
+			}
+			v.add(path, value.offset, "must be %s", strings.Join(described, " or "))
+			return
+		}
+	}
+
+	if s.Type != "" && !hasType(value, s.Type) {
+		v.add(path, value.offset, "must be %s", v.describe(s))
+		return
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, value.equals) {
+		options := make([]string, len(s.Enum))
+		for i, option := range s.Enum {
+			options[i] = fmt.Sprint(option)
+		}
+		v.add(path, value.offset, "must be one of %s", strings.Join(options, ", "))
+	}
+	if s.Pattern != "" && value.kind == "string" {
+		if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(value.str) {
+			v.add(path, value.offset, "must match %s", s.Pattern)
+		}
+	}
+
+	switch value.kind {
+	case "object":
+		v.checkObject(s, value, path)
+	case "array":
+		for i, item := range value.items {
+			v.check(s.Items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+func (v *validator) checkObject(s *jsonschema.Schema, value *node, path string) {
+	for _, name := range s.Required {
+		if _, ok := value.members[name]; !ok {
+			v.add(joinPath(path, name), value.offset, "is required")
+		}
+	}
+	for i, key := range value.keys {
+		if path == "" && key == "$schema" {
+			continue
+		}
+		member := value.members[key]
+		memberPath := joinPath(path, key)
+		if s.Properties != nil {
+			if prop, ok := s.Properties.Get(key); ok {
+				v.check(prop, member, memberPath)
+				continue
+			}
+		}
+		matched := false
+		for pattern, prop := range s.PatternProperties {
+			if re, err := regexp.Compile(pattern); err == nil && re.MatchString(key) {
+				v.check(prop, member, memberPath)
+				matched = true
+			}
+		}
+		switch {
+		case matched:
+		case s.AdditionalProperties == jsonschema.FalseSchema:
+			v.add(memberPath, value.keyOffsets[i], "is not a known field")
+		case s.AdditionalProperties != nil:
+			v.check(s.AdditionalProperties, member, memberPath)
+		}
+	}
+}
+
+// resolve follows $ref into the root's $defs
+func (v *validator) resolve(s *jsonschema.Schema) *jsonschema.Schema {
+	for s != nil && s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, "#/$defs/")
+		if !ok {
+			return nil
+		}
+		s = v.defs[name]
+	}
+	return s
+}
+
+// describe names what s accepts, e.g. "an array of strings"
+func (v *validator) describe(s *jsonschema.Schema) string {
+	s = v.resolve(s)
+	if s == nil {
+		return "a value"
+	}
+	switch s.Type {
+	case "array":
+		if items := v.resolve(s.Items); items != nil && items.Type != "" && items.Type != "array" {
+			return "an array of " + typeNames[items.Type][1]
+		}
+		return "an array"
+	case "":
+		return "a value"
+	}
+	return typeNames[s.Type][0]
+}
+
+// typeNames are the singular and plural phrases for each JSON Schema type
+var typeNames = map[string][2]string{
+	"string":  {"a string", "strings"},
+	"number":  {"a number", "numbers"},
+	"integer": {"a whole number", "whole numbers"},
+	"boolean": {"true or false", "booleans"},
+	"object":  {"an object", "objects"},
+	"null":    {"null", "nulls"},
+}
+
+func hasType(value *node, schemaType string) bool {
+	switch schemaType {
+	case "integer":
+		if value.kind != "number" {
+			return false
+		}
+		_, err := value.num.Int64()
+		return err == nil
+	default:
+		return value.kind == schemaType
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// position converts a byte offset into a 1-based line and column
+func position(data []byte, offset int) (line, column int) {
+	offset = min(offset, len(data))
+	line = 1 + bytes.Count(data[:offset], []byte("\n"))
+	column = offset - bytes.LastIndexByte(data[:offset], '\n')
+	return line, column
+}
+
+// node is a parsed JSON value that remembers where it started
+type node struct {
+	offset     int
+	kind       string // A JSON Schema type name: object, array, string, number, boolean or null
+	str        string
+	num        json.Number
+	boolean    bool
+	keys       []string // Object members in document order
+	keyOffsets []int
+	members    map[string]*node
+	items      []*node
+}
+
+func (n *node) equals(value any) bool {
+	switch value := value.(type) {
+	case string:
+		return n.kind == "string" && n.str == value
+	case bool:
+		return n.kind == "boolean" && n.boolean == value
+	case nil:
+		return n.kind == "null"
+	}
+	return n.kind == "number" && n.num.String() == fmt.Sprint(value)
+}
+
+func parseDocument(data []byte) (*node, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	root, err := parseNode(dec, data)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the document")
+	}
+	return root, nil
+}
+
+func parseNode(dec *json.Decoder, data []byte) (*node, error) {
+	n := &node{offset: nextToken(data, dec.InputOffset())}
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok := tok.(type) {
+	case json.Delim:
+		if tok == '{' {
+			n.kind = "object"
+			n.members = make(map[string]*node)
+			for dec.More() {
+				keyOffset := nextToken(data, dec.InputOffset())
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				member, err := parseNode(dec, data)
+				if err != nil {
+					return nil, err
+				}
+				if _, dup := n.members[key.(string)]; !dup {
+					n.keys = append(n.keys, key.(string))
+					n.keyOffsets = append(n.keyOffsets, keyOffset)
+				}
+				n.members[key.(string)] = member
+			}
+		} else {
+			n.kind = "array"
+			for dec.More() {
+				item, err := parseNode(dec, data)
+				if err != nil {
+					return nil, err
+				}
+				n.items = append(n.items, item)
+			}
+		}
+		if _, err := dec.Token(); err != nil { // Closing delimiter
+			return nil, err
+		}
+	case string:
+		n.kind, n.str = "string", tok
+	case json.Number:
+		n.kind, n.num = "number", tok
+	case bool:
+		n.kind, n.boolean = "boolean", tok
+	case nil:
+		n.kind = "null"
+	}
+	return n, nil
+}
+
+// nextToken skips the whitespace and separators between offset and the next token
+func nextToken(data []byte, offset int64) int {
+	i := int(offset)
+	for i < len(data) && strings.IndexByte(" \t\r\n,:", data[i]) >= 0 {
+		i++
+	}
+	return i
+}
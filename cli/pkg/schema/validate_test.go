@@ -0,0 +1,89 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestValidate(t *testing.T) {
+	profile := Reflect(Entry{"faction-profile", &models.FactionProfile{}})
+
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "valid profile",
+			data: `{
+  "$schema": "../schema/faction-profile.schema.json",
+  "displayName": "Legion",
+  "factionUnitType": "Custom1",
+  "mods": ["com.pa.legion-expansion-server"],
+  "teamColors": {"primary": "#ff0000", "secondary": "#00ff00"},
+  "conflictPreferences": {"/pa/units/land/tank/tank.json": "com.pa.legion-expansion-server"}
+}`,
+		},
+		{
+			name: "wrong type",
+			data: `{
+  "displayName": "Legion",
+  "mods": "com.pa.legion-expansion-server"
+}`,
+			want: []string{"mods must be an array of strings at line 3"},
+		},
+		{
+			name: "wrong item type",
+			data: `{"displayName": "Legion",
+  "mods": ["a",
+    2]}`,
+			want: []string{"mods[1] must be a string at line 3"},
+		},
+		{
+			name: "unknown and missing fields",
+			data: `{
+  "modz": []
+}`,
+			want: []string{"displayName is required at line 1", "modz is not a known field at line 2"},
+		},
+		{
+			name: "nested definition",
+			data: `{
+  "displayName": "Legion",
+  "teamColors": {
+    "primary": 255,
+    "secondary": "#00ff00"
+  }
+}`,
+			want: []string{"teamColors.primary must be a string at line 4"},
+		},
+		{
+			name: "map values",
+			data: `{"displayName": "Legion", "icons": {"tank": true}}`,
+			want: []string{"icons.tank must be a string at line 1"},
+		},
+		{
+			name: "malformed JSON",
+			data: `{
+  "displayName": "Legion",
+}`,
+			want: []string{"document is not valid JSON"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := Validate(profile, []byte(tt.data))
+			if len(violations) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want %v", violations, tt.want)
+			}
+			for i, v := range violations {
+				if !strings.HasPrefix(v.String(), tt.want[i]) {
+					t.Errorf("violation %d = %q, want %q", i, v, tt.want[i])
+				}
+			}
+		})
+	}
+}
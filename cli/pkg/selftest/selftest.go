@@ -0,0 +1,242 @@
+// Package selftest runs a bounded extraction of a few well-known units and checks
+// the results against invariants that should survive any PA balance patch.
+package selftest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/parser"
+)
+
+// Check is a single invariant on a parsed unit.
+type Check struct {
+	Description string
+	Pass        func(u *models.Unit) bool
+}
+
+// Case is a known unit and the invariants it must satisfy.
+type Case struct {
+	Name         string // Human-readable name (e.g. "Ant")
+	ResourcePath string
+	Checks       []Check
+}
+
+// Result is the outcome of one check (or of parsing the unit, when Check is empty).
+type Result struct {
+	Case   string
+	Check  string
+	Passed bool
+	Err    error // Set when the unit could not be found or parsed
+}
+
+// Run parses each case's unit on its own (no database or build tree) and evaluates its checks. Units missing
+// from the merged unit list or failing to parse produce a single failed Result.
+func Run(l *loader.Loader, cases []Case) ([]Result, error) {
+	unitPaths, _, err := l.LoadMergedUnitList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load unit list: %w", err)
+	}
+	listed := make(map[string]bool, len(unitPaths))
+	for _, p := range unitPaths {
+		listed[p] = true
+	}
+
+	var results []Result
+	for _, c := range cases {
+		if !listed[c.ResourcePath] {
+			results = append(results, Result{Case: c.Name, Err: fmt.Errorf("%s is not in the unit list", c.ResourcePath)})
+			continue
+		}
+
+		unit, err := parser.ParseUnit(l, c.ResourcePath, nil)
+		if err != nil {
+			results = append(results, Result{Case: c.Name, Err: fmt.Errorf("failed to parse %s: %w", c.ResourcePath, err)})
+			continue
+		}
+
+		for _, check := range c.Checks {
+			results = append(results, Result{Case: c.Name, Check: check.Description, Passed: check.Pass(unit)})
+		}
+	}
+	return results, nil
+}
+
+// Failures counts the results that did not pass.
+func Failures(results []Result) int {
+	failed := 0
+	for _, r := range results {
+		if !r.Passed {
+			failed++
+		}
+	}
+	return failed
+}
+
+// MLACases are the default base-game invariants.
+func MLACases() []Case {
+	return []Case{
+		{
+			Name:         "Alpha Commander",
+			ResourcePath: "/pa/units/commanders/imperial_alpha/imperial_alpha.json",
+			Checks: []Check{
+				HasUnitType("Commander"),
+				HasBuildArm(),
+				HasWeapons(),
+				Produces("metal"),
+				Produces("energy"),
+			},
+		},
+		{
+			Name:         "Ant",
+			ResourcePath: "/pa/units/land/tank_light_laser/tank_light_laser.json",
+			Checks: []Check{
+				WeaponCount(1),
+				HasUnitType("Tank"),
+				CostsMetal(),
+				HasHealth(),
+			},
+		},
+		{
+			Name:         "Hummingbird",
+			ResourcePath: "/pa/units/air/fighter/fighter.json",
+			Checks: []Check{
+				WeaponCount(1),
+				HasUnitType("Air"),
+			},
+		},
+		{
+			Name:         "Bot Factory",
+			ResourcePath: "/pa/units/land/bot_factory/bot_factory.json",
+			Checks: []Check{
+				HasBuildArm(),
+				BuildsTypes("Bot"),
+			},
+		},
+		{
+			Name:         "Fabrication Bot",
+			ResourcePath: "/pa/units/land/fabrication_bot/fabrication_bot.json",
+			Checks: []Check{
+				HasBuildArm(),
+				BuildsTypes("Structure"),
+			},
+		},
+		{
+			Name:         "Metal Extractor",
+			ResourcePath: "/pa/units/land/metal_extractor/metal_extractor.json",
+			Checks: []Check{
+				Produces("metal"),
+				CostsMetal(),
+			},
+		},
+		{
+			Name:         "Energy Plant",
+			ResourcePath: "/pa/units/land/energy_plant/energy_plant.json",
+			Checks: []Check{
+				Produces("energy"),
+				CostsMetal(),
+			},
+		},
+	}
+}
+
+// WeaponCount requires exactly n weapons (death weapons excluded).
+func WeaponCount(n int) Check {
+	return Check{
+		Description: fmt.Sprintf("has %d weapon(s)", n),
+		Pass: func(u *models.Unit) bool {
+			count := 0
+			if u.Specs.Combat != nil {
+				for _, w := range u.Specs.Combat.Weapons {
+					if !w.DeathExplosion {
+						count++
+					}
+				}
+			}
+			return count == n
+		},
+	}
+}
+
+// HasWeapons requires at least one weapon.
+func HasWeapons() Check {
+	return Check{
+		Description: "has weapons",
+		Pass: func(u *models.Unit) bool {
+			return u.Specs.Combat != nil && len(u.Specs.Combat.Weapons) > 0
+		},
+	}
+}
+
+// HasBuildArm requires at least one build arm.
+func HasBuildArm() Check {
+	return Check{
+		Description: "has a build arm",
+		Pass: func(u *models.Unit) bool {
+			return u.Specs.Economy != nil && len(u.Specs.Economy.BuildArms) > 0
+		},
+	}
+}
+
+// HasUnitType requires a unit type tag (without the UNITTYPE_ prefix).
+func HasUnitType(unitType string) Check {
+	return Check{
+		Description: fmt.Sprintf("is %s", unitType),
+		Pass: func(u *models.Unit) bool {
+			for _, t := range u.UnitTypes {
+				if t == unitType {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// BuildsTypes requires the build restriction to mention a unit type.
+func BuildsTypes(unitType string) Check {
+	return Check{
+		Description: fmt.Sprintf("can build %s units", unitType),
+		Pass: func(u *models.Unit) bool {
+			return strings.Contains(u.BuildableTypes, unitType)
+		},
+	}
+}
+
+// Produces requires positive production of a resource ("metal" or "energy").
+func Produces(resource string) Check {
+	return Check{
+		Description: fmt.Sprintf("produces %s", resource),
+		Pass: func(u *models.Unit) bool {
+			if u.Specs.Economy == nil {
+				return false
+			}
+			if resource == "metal" {
+				return u.Specs.Economy.Production.Metal > 0
+			}
+			return u.Specs.Economy.Production.Energy > 0
+		},
+	}
+}
+
+// CostsMetal requires a positive build cost.
+func CostsMetal() Check {
+	return Check{
+		Description: "has a build cost",
+		Pass: func(u *models.Unit) bool {
+			return u.Specs.Economy != nil && u.Specs.Economy.BuildCost > 0
+		},
+	}
+}
+
+// HasHealth requires positive health.
+func HasHealth() Check {
+	return Check{
+		Description: "has health",
+		Pass: func(u *models.Unit) bool {
+			return u.Specs.Combat != nil && u.Specs.Combat.Health > 0
+		},
+	}
+}
@@ -0,0 +1,57 @@
+package selftest
+
+import (
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+)
+
+func TestRun(t *testing.T) {
+	l, err := loader.NewMultiSourceLoader("../../testdata/pa_root", "pa_ex1", nil)
+	if err != nil {
+		t.Fatalf("failed to create loader: %v", err)
+	}
+	defer l.Close()
+
+	cases := []Case{
+		{
+			Name:         "Test Commander",
+			ResourcePath: "/pa/units/commanders/test_commander/test_commander.json",
+			Checks:       []Check{HasUnitType("Commander"), HasBuildArm(), HasWeapons()},
+		},
+		{
+			Name:         "Test Tank",
+			ResourcePath: "/pa/units/land/test_tank/test_tank.json",
+			Checks:       []Check{WeaponCount(1), CostsMetal(), HasBuildArm()},
+		},
+		{
+			Name:         "Missing",
+			ResourcePath: "/pa/units/land/not_a_unit/not_a_unit.json",
+			Checks:       []Check{HasHealth()},
+		},
+	}
+
+	results, err := Run(l, cases)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// 3 commander checks + 3 tank checks + 1 result for the missing unit
+	if len(results) != 7 {
+		t.Fatalf("got %d results, want 7: %+v", len(results), results)
+	}
+	for _, r := range results[:5] {
+		if !r.Passed {
+			t.Errorf("%s: %q failed (err %v)", r.Case, r.Check, r.Err)
+		}
+	}
+	if results[5].Passed {
+		t.Error("tank should not have a build arm")
+	}
+	if results[6].Passed || results[6].Err == nil {
+		t.Errorf("missing unit should fail with an error, got %+v", results[6])
+	}
+	if got := Failures(results); got != 2 {
+		t.Errorf("Failures() = %d, want 2", got)
+	}
+}
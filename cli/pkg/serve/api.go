@@ -0,0 +1,113 @@
+package serve
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// VersionInfo describes one faction version in API responses
+type VersionInfo struct {
+	Version     string `json:"version"`
+	DisplayName string `json:"displayName"`
+	PABuild     string `json:"paBuild,omitempty"`
+	Ref         string `json:"ref"` // <id>@<version>, for /factions/<ref>/...
+}
+
+// FactionInfo is one entry of the /factions listing
+type FactionInfo struct {
+	ID          string        `json:"id"`
+	DisplayName string        `json:"displayName"`
+	Latest      string        `json:"latest"`
+	Versions    []VersionInfo `json:"versions"` // Newest first
+}
+
+func versionInfo(v *FactionVersion) VersionInfo {
+	return VersionInfo{
+		Version:     v.Version,
+		DisplayName: v.Metadata.DisplayName,
+		PABuild:     v.Metadata.PABuild,
+		Ref:         v.ID + "@" + v.Version,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(v)
+}
+
+// handleFactions lists every faction and its versions
+func (s *Server) handleFactions(w http.ResponseWriter, r *http.Request) {
+	lib := s.freshLibrary()
+	factions := []FactionInfo{}
+	for _, id := range lib.IDs() {
+		versions := lib.Versions(id)
+		info := FactionInfo{ID: id, DisplayName: versions[0].Metadata.DisplayName, Latest: versions[0].Version}
+		for _, v := range versions {
+			info.Versions = append(info.Versions, versionInfo(v))
+		}
+		factions = append(factions, info)
+	}
+	writeJSON(w, factions)
+}
+
+// handleVersions lists the versions of the faction named by {ref}, newest first
+func (s *Server) handleVersions(w http.ResponseWriter, r *http.Request) {
+	v, ok := s.resolve(r.PathValue("ref"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	versions := []VersionInfo{}
+	for _, other := range s.library().Versions(v.ID) {
+		versions = append(versions, versionInfo(other))
+	}
+	writeJSON(w, versions)
+}
+
+// handleUnits returns the units of a faction version as a JSON array, NDJSON stream or
+// CSV table (see negotiateFormat)
+func (s *Server) handleUnits(w http.ResponseWriter, r *http.Request) {
+	v, ok := s.resolve(r.PathValue("ref"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	format, ok := negotiateFormat(r)
+	if !ok {
+		http.Error(w, "unknown format (supported: json, ndjson, csv)", http.StatusBadRequest)
+		return
+	}
+	start := time.Now()
+	index, err := exporter.ReadFactionIndexFS(v.FS)
+	s.metrics.load(v.ID, time.Since(start))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	units := make([]models.Unit, len(index.Units))
+	for i := range index.Units {
+		units[i] = index.Units[i].Unit
+	}
+	writeUnits(w, format, v.ID+"-"+v.Version+"-units", units)
+}
+
+// handleFile serves a file of a faction version
+func (s *Server) handleFile(w http.ResponseWriter, r *http.Request) {
+	v, ok := s.resolve(r.PathValue("ref"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	name := path.Clean("/" + r.PathValue("file"))[1:]
+	if name == "" {
+		http.NotFound(w, r)
+		return
+	}
+	s.serveFile(w, r, v, name)
+}
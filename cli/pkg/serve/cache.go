@@ -0,0 +1,110 @@
+package serve
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// etagLength is how many hex digits of a file's SHA-256 go into its ETag
+const etagLength = 32
+
+// assetManifest is the served files' content hashes, like the file list of a .pafaction
+// bundle manifest. Entries are keyed by source and path and reused while size and
+// modification time are unchanged, so each file is hashed once per re-export rather than
+// once per request.
+type assetManifest struct {
+	mu      sync.Mutex
+	entries map[string]assetEntry
+}
+
+type assetEntry struct {
+	size    int64
+	modTime time.Time
+	sha256  string
+}
+
+// hash returns the hex SHA-256 of a file, reading it through open on a cache miss, and
+// whether it was cached
+func (m *assetManifest) hash(key string, info fs.FileInfo, open func() (io.ReadCloser, error)) (string, bool, error) {
+	m.mu.Lock()
+	entry, ok := m.entries[key]
+	m.mu.Unlock()
+	if ok && entry.size == info.Size() && entry.modTime.Equal(info.ModTime()) {
+		return entry.sha256, true, nil
+	}
+
+	f, err := open()
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", false, err
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	m.mu.Lock()
+	if m.entries == nil {
+		m.entries = make(map[string]assetEntry)
+	}
+	m.entries[key] = assetEntry{size: info.Size(), modTime: info.ModTime(), sha256: sum}
+	m.mu.Unlock()
+	return sum, false, nil
+}
+
+// cacheControl is the Cache-Control value for faction files. With no max age browsers
+// store files but revalidate on every use, which costs a 304 per file and never shows
+// stale data after a re-export.
+func cacheControl(maxAge time.Duration) string {
+	if maxAge <= 0 {
+		return "no-cache"
+	}
+	return fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds()))
+}
+
+// serveFile serves one file of a faction version with a strong ETag (from the content
+// hash) and Cache-Control. http.ServeContent answers If-None-Match and If-Match with 304
+// and 412, and Range requests.
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request, v *FactionVersion, name string) {
+	f, err := v.FS.Open(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		http.NotFound(w, r)
+		return
+	}
+
+	sum, cached := v.hashes[name]
+	if !cached {
+		sum, cached, err = s.assets.hash(v.Source+"\x00"+name, info, func() (io.ReadCloser, error) { return v.FS.Open(name) })
+	}
+	s.metrics.cache(cached)
+	if err == nil && len(sum) >= etagLength {
+		w.Header().Set("ETag", `"`+sum[:etagLength]+`"`)
+	}
+	w.Header().Set("Cache-Control", cacheControl(s.opts.CacheMaxAge))
+
+	// Archive entries can't seek; faction files are small enough to buffer
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		content = bytes.NewReader(data)
+	}
+	http.ServeContent(w, r, info.Name(), info.ModTime(), content)
+}
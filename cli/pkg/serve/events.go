@@ -0,0 +1,148 @@
+package serve
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// Event types pushed to /ws clients
+const (
+	// EventFactionReloaded is sent once per reloaded, added or removed faction folder,
+	// after the EventUnitChanged events of that reload
+	EventFactionReloaded = "faction-reloaded"
+	// EventUnitChanged is sent for every unit added, removed or modified by a reload
+	EventUnitChanged = "unit-changed"
+)
+
+// Kinds of change carried by an Event
+const (
+	ChangeAdded    = "added"
+	ChangeModified = "modified"
+	ChangeRemoved  = "removed"
+)
+
+// Event is one change notification, sent as a JSON text message
+type Event struct {
+	Type    string `json:"type"`
+	Faction string `json:"faction"` // Folder name for plain folders, else <id>@<version>; a /factions/{ref}/
+	Unit    string `json:"unit,omitempty"`
+	Change  string `json:"change"`
+}
+
+// clientBuffer is how many events a slow client may fall behind before it's dropped
+const clientBuffer = 256
+
+// Hub fans events out to connected WebSocket clients
+type Hub struct {
+	mu      sync.Mutex
+	clients map[chan Event]struct{}
+}
+
+// NewHub creates a hub with no clients
+func NewHub() *Hub {
+	return &Hub{clients: make(map[chan Event]struct{})}
+}
+
+// Publish sends an event to every client. A client whose buffer is full is disconnected
+// rather than blocking the others; it can reconnect and refetch.
+func (h *Hub) Publish(e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.clients {
+		select {
+		case ch <- e:
+		default:
+			delete(h.clients, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close disconnects every client (with a close frame), e.g. on server shutdown, since
+// http.Server.Shutdown doesn't track hijacked connections
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.clients {
+		delete(h.clients, ch)
+		close(ch)
+	}
+}
+
+// Clients returns the number of connected clients
+func (h *Hub) Clients() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+func (h *Hub) subscribe() chan Event {
+	ch := make(chan Event, clientBuffer)
+	h.mu.Lock()
+	h.clients[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *Hub) unsubscribe(ch chan Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[ch]; ok {
+		delete(h.clients, ch)
+		close(ch)
+	}
+}
+
+// ServeHTTP upgrades the request to a WebSocket and streams events until either side closes
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, rw, err := upgradeWebSocket(w, r)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	events := h.subscribe()
+	defer h.unsubscribe(events)
+
+	// The reader only answers pings and notices the close handshake; all writes happen
+	// below so frames never interleave
+	control := make(chan []byte, 1)
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			opcode, payload, err := readFrame(rw.Reader)
+			if err != nil || opcode == opClose {
+				return
+			}
+			if opcode == opPing {
+				select {
+				case control <- payload:
+				default:
+				}
+			}
+		}
+	}()
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				writeFrame(rw.Writer, opClose, nil)
+				return
+			}
+			data, _ := json.Marshal(e)
+			if err := writeFrame(rw.Writer, opText, data); err != nil {
+				return
+			}
+		case payload := <-control:
+			if err := writeFrame(rw.Writer, opPong, payload); err != nil {
+				return
+			}
+		case <-closed:
+			writeFrame(rw.Writer, opClose, nil)
+			return
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package serve
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/table"
+)
+
+// Listing formats, chosen with ?format= or the Accept header
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+// formatTypes maps media types to listing formats
+var formatTypes = map[string]string{
+	"application/json":     FormatJSON,
+	"application/x-ndjson": FormatNDJSON,
+	"application/ndjson":   FormatNDJSON,
+	"application/jsonl":    FormatNDJSON,
+	"text/csv":             FormatCSV,
+}
+
+// negotiateFormat picks a listing format: ?format= wins, then the Accept media type with
+// the highest q-value that we support, then JSON. ok is false for an unknown ?format=.
+func negotiateFormat(r *http.Request) (format string, ok bool) {
+	if f := r.URL.Query().Get("format"); f != "" {
+		switch f = strings.ToLower(f); f {
+		case FormatJSON, FormatNDJSON, FormatCSV:
+			return f, true
+		}
+		return "", false
+	}
+
+	type candidate struct {
+		format string
+		q      float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		format, known := formatTypes[mediaType]
+		if !known {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{format, q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	if len(candidates) > 0 {
+		return candidates[0].format, true
+	}
+	return FormatJSON, true
+}
+
+// writeUnits writes units in a listing format. name is the file name suggested for CSV
+// downloads, without extension.
+func writeUnits(w http.ResponseWriter, format, name string, units []models.Unit) {
+	h := w.Header()
+	h.Add("Vary", "Accept")
+	h.Set("Cache-Control", "no-cache")
+
+	switch format {
+	case FormatNDJSON:
+		h.Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		for i := range units {
+			if err := enc.Encode(&units[i]); err != nil {
+				return
+			}
+		}
+	case FormatCSV:
+		t, err := table.Flatten(units)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		h.Set("Content-Type", "text/csv; charset=utf-8")
+		h.Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", name+".csv"))
+		t.WriteCSV(w)
+	default:
+		h.Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(units)
+	}
+}
@@ -0,0 +1,277 @@
+package serve
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/bundle"
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// FactionVersion is one servable version of a faction: an export folder or an opened
+// release zip / .pafaction bundle
+type FactionVersion struct {
+	ID       string // Lower-cased metadata identifier
+	Version  string
+	Metadata *models.FactionMetadata
+	Source   string // Folder or archive path
+	// Folder is the folder name under the root for plain faction folders, which keep
+	// their /factions/<Folder>/ URLs; empty for versioned folders and archives
+	Folder string
+	FS     fs.FS
+
+	modTime time.Time
+	size    int64             // Archive size, to notice replaced archives on rescan
+	hashes  map[string]string // SHA-256 by path from a .pafaction manifest
+}
+
+// Library is every faction version found under a serve root, read-only. Three layouts
+// are recognised side by side:
+//
+//	<root>/<folder>/metadata.json             a describe-faction export
+//	<root>/<id>/<version>/metadata.json        versioned export folders
+//	<root>/<name>.zip, <root>/<name>.pafaction release zips and bundles with the
+//	                                           faction files at the archive root
+//
+// Versions are identified by metadata.json, not by folder or file names. When the same
+// id@version is found twice the first wins, folders before archives.
+type Library struct {
+	factions map[string][]*FactionVersion // By ID, newest version first
+	folders  map[string]*FactionVersion
+}
+
+// ScanLibrary scans root. Archives already opened by previous (nil on the first scan)
+// are reused while their size and modification time are unchanged.
+func ScanLibrary(root string, previous *Library) (*Library, []error) {
+	lib := &Library{factions: make(map[string][]*FactionVersion), folders: make(map[string]*FactionVersion)}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return lib, []error{fmt.Errorf("failed to read factions directory: %w", err)}
+	}
+
+	var errs []error
+	var archives []string
+	seen := make(map[string]bool)
+	add := func(v *FactionVersion) {
+		key := v.ID + "@" + v.Version
+		if seen[key] {
+			errs = append(errs, fmt.Errorf("%s: %s is also served from another source, skipping", v.Source, key))
+			return
+		}
+		seen[key] = true
+		lib.factions[v.ID] = append(lib.factions[v.ID], v)
+		if v.Folder != "" {
+			lib.folders[v.Folder] = v
+		}
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(root, entry.Name())
+		if !entry.IsDir() {
+			if ext := strings.ToLower(filepath.Ext(entry.Name())); ext == ".zip" || ext == bundle.Extension {
+				archives = append(archives, path)
+			}
+			continue
+		}
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if v, err := openFolder(path); err == nil {
+			v.Folder = entry.Name()
+			add(v)
+			continue
+		} else if !os.IsNotExist(err) {
+			errs = append(errs, err)
+			continue
+		}
+		// Not an export itself: look for <id>/<version>/ folders
+		subdirs, err := os.ReadDir(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, sub := range subdirs {
+			if !sub.IsDir() || strings.HasPrefix(sub.Name(), ".") {
+				continue
+			}
+			v, err := openFolder(filepath.Join(path, sub.Name()))
+			switch {
+			case err == nil:
+				add(v)
+			case !os.IsNotExist(err):
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	for _, path := range archives {
+		v, err := openArchive(path, previous)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		add(v)
+	}
+
+	for _, versions := range lib.factions {
+		sort.SliceStable(versions, func(i, j int) bool {
+			if c := CompareVersions(versions[i].Version, versions[j].Version); c != 0 {
+				return c > 0
+			}
+			return versions[i].modTime.After(versions[j].modTime)
+		})
+	}
+	return lib, errs
+}
+
+// openFolder reads an export folder; the error satisfies os.IsNotExist when the folder
+// has no metadata.json
+func openFolder(dir string) (*FactionVersion, error) {
+	info, err := os.Stat(filepath.Join(dir, "metadata.json"))
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := exporter.ReadFactionMetadata(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", dir, err)
+	}
+	return newVersion(metadata, dir, os.DirFS(dir), info.ModTime())
+}
+
+func openArchive(path string, previous *Library) (*FactionVersion, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		for _, versions := range previous.factions {
+			for _, v := range versions {
+				if v.Source == path && v.size == info.Size() && v.modTime.Equal(info.ModTime()) {
+					return v, nil
+				}
+			}
+		}
+	}
+
+	// Replaced archives are left to be closed by the garbage collector, since requests
+	// may still be reading from them
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	metadata, err := exporter.ReadFactionMetadataFS(zr)
+	if err != nil {
+		zr.Close()
+		return nil, fmt.Errorf("%s: %w\n\nFaction archives need metadata.json at their root", path, err)
+	}
+	v, err := newVersion(metadata, path, zr, info.ModTime())
+	if err != nil {
+		zr.Close()
+		return nil, err
+	}
+	v.size = info.Size()
+
+	// A .pafaction manifest already has every file's hash
+	if data, err := fs.ReadFile(zr, bundle.ManifestName); err == nil {
+		var manifest models.BundleManifest
+		if json.Unmarshal(data, &manifest) == nil {
+			v.hashes = make(map[string]string, len(manifest.Files))
+			for _, f := range manifest.Files {
+				v.hashes[f.Path] = f.SHA256
+			}
+		}
+	}
+	return v, nil
+}
+
+func newVersion(metadata *models.FactionMetadata, source string, fsys fs.FS, modTime time.Time) (*FactionVersion, error) {
+	if metadata.Identifier == "" || metadata.Version == "" {
+		return nil, fmt.Errorf("%s: metadata.json needs identifier and version", source)
+	}
+	return &FactionVersion{
+		ID:       strings.ToLower(metadata.Identifier),
+		Version:  metadata.Version,
+		Metadata: metadata,
+		Source:   source,
+		FS:       fsys,
+		modTime:  modTime,
+	}, nil
+}
+
+// IDs returns the faction IDs, sorted
+func (l *Library) IDs() []string {
+	ids := make([]string, 0, len(l.factions))
+	for id := range l.factions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Versions returns the versions of a faction, newest first (nil when unknown)
+func (l *Library) Versions(id string) []*FactionVersion {
+	return l.factions[strings.ToLower(id)]
+}
+
+// Resolve finds the version a URL segment refers to: "<id>@<version>", "<id>" for the
+// newest version, or the name of a plain faction folder (e.g. "MLA")
+func (l *Library) Resolve(ref string) (*FactionVersion, bool) {
+	if v, ok := l.folders[ref]; ok {
+		return v, true
+	}
+	id, version, pinned := strings.Cut(ref, "@")
+	versions := l.Versions(id)
+	if len(versions) == 0 {
+		return nil, false
+	}
+	if !pinned {
+		return versions[0], true
+	}
+	for _, v := range versions {
+		if v.Version == version {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// CompareVersions orders version strings like "1.2.0", "1.10.0-beta" and PA build
+// numbers: dot/dash separated parts compare numerically when both are numbers and
+// lexically otherwise, and a version with more parts sorts after its prefix.
+func CompareVersions(a, b string) int {
+	split := func(s string) []string {
+		return strings.FieldsFunc(s, func(r rune) bool { return r == '.' || r == '-' || r == '+' })
+	}
+	pa, pb := split(a), split(b)
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+		case pa[i] != pb[i]:
+			return strings.Compare(pa[i], pb[i])
+		}
+	}
+	switch {
+	case len(pa) < len(pb):
+		return -1
+	case len(pa) > len(pb):
+		return 1
+	}
+	return 0
+}
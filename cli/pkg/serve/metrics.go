@@ -0,0 +1,232 @@
+package serve
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics counts server activity for /metrics, in the Prometheus text exposition
+// format (written by hand to keep the CLI free of a client library dependency)
+type Metrics struct {
+	mu          sync.Mutex
+	requests    map[requestKey]int64
+	loads       map[string]*loadStats // Unit index loads by faction ID
+	cacheHits   int64                 // Content hashes served from the asset manifest
+	cacheMisses int64                 // Content hashes computed by reading the file
+	notModified int64                 // 304 responses
+	scans       int64
+	lastScan    time.Duration
+}
+
+type requestKey struct {
+	route string
+	code  int
+}
+
+type loadStats struct {
+	count int64
+	total time.Duration
+}
+
+func newMetrics() *Metrics {
+	return &Metrics{requests: make(map[requestKey]int64), loads: make(map[string]*loadStats)}
+}
+
+func (m *Metrics) request(route string, code int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[requestKey{route, code}]++
+	if code == http.StatusNotModified {
+		m.notModified++
+	}
+}
+
+func (m *Metrics) load(faction string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.loads[faction]
+	if s == nil {
+		s = &loadStats{}
+		m.loads[faction] = s
+	}
+	s.count++
+	s.total += d
+}
+
+func (m *Metrics) cache(hit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if hit {
+		m.cacheHits++
+	} else {
+		m.cacheMisses++
+	}
+}
+
+func (m *Metrics) scan(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scans++
+	m.lastScan = d
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Hijack passes /ws upgrades through, recording them as 101 Switching Protocols
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil && r.code == 0 {
+		r.code = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// withMetrics counts requests by the route pattern that served them, so per-unit and
+// per-file paths don't explode the label set
+func (m *Metrics) withMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		if rec.code == 0 {
+			rec.code = http.StatusOK
+		}
+		m.request(route, rec.code)
+	})
+}
+
+// write renders every metric; gauges that live elsewhere are passed in
+func (m *Metrics) write(w io.Writer, factions, versions, clients int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	metric("pa_pedia_http_requests_total", "counter", "HTTP requests by route pattern and status code.")
+	keys := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		return keys[i].code < keys[j].code
+	})
+	for _, k := range keys {
+		fmt.Fprintf(w, "pa_pedia_http_requests_total{route=%s,code=\"%d\"} %d\n", quoteLabel(k.route), k.code, m.requests[k])
+	}
+
+	metric("pa_pedia_http_not_modified_total", "counter", "Responses answered with 304 Not Modified.")
+	fmt.Fprintf(w, "pa_pedia_http_not_modified_total %d\n", m.notModified)
+
+	metric("pa_pedia_asset_hash_cache_hits_total", "counter", "ETag content hashes reused from the asset manifest.")
+	fmt.Fprintf(w, "pa_pedia_asset_hash_cache_hits_total %d\n", m.cacheHits)
+	metric("pa_pedia_asset_hash_cache_misses_total", "counter", "ETag content hashes computed by reading the file.")
+	fmt.Fprintf(w, "pa_pedia_asset_hash_cache_misses_total %d\n", m.cacheMisses)
+
+	metric("pa_pedia_faction_load_seconds", "summary", "Time spent loading units.json for unit listings by faction.")
+	factionIDs := make([]string, 0, len(m.loads))
+	for id := range m.loads {
+		factionIDs = append(factionIDs, id)
+	}
+	sort.Strings(factionIDs)
+	for _, id := range factionIDs {
+		s := m.loads[id]
+		fmt.Fprintf(w, "pa_pedia_faction_load_seconds_sum{faction=%s} %s\n", quoteLabel(id), seconds(s.total))
+		fmt.Fprintf(w, "pa_pedia_faction_load_seconds_count{faction=%s} %d\n", quoteLabel(id), s.count)
+	}
+
+	metric("pa_pedia_library_scans_total", "counter", "Scans of the factions directory.")
+	fmt.Fprintf(w, "pa_pedia_library_scans_total %d\n", m.scans)
+	metric("pa_pedia_library_scan_seconds", "gauge", "Duration of the last factions directory scan.")
+	fmt.Fprintf(w, "pa_pedia_library_scan_seconds %s\n", seconds(m.lastScan))
+
+	metric("pa_pedia_factions", "gauge", "Factions being served.")
+	fmt.Fprintf(w, "pa_pedia_factions %d\n", factions)
+	metric("pa_pedia_faction_versions", "gauge", "Faction versions being served.")
+	fmt.Fprintf(w, "pa_pedia_faction_versions %d\n", versions)
+	metric("pa_pedia_websocket_clients", "gauge", "Connected /ws clients.")
+	fmt.Fprintf(w, "pa_pedia_websocket_clients %d\n", clients)
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+}
+
+// quoteLabel quotes a label value with the exposition format's escapes
+func quoteLabel(v string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v) + `"`
+}
+
+// handleHealthz reports that the process is up and serving requests
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	io.WriteString(w, "ok\n")
+}
+
+// handleReadyz reports whether the factions directory has been scanned and at least one
+// faction loaded, so a load balancer holds traffic until there is data to serve
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	lib := s.current.Load()
+	switch {
+	case lib == nil:
+		http.Error(w, "factions directory not scanned yet", http.StatusServiceUnavailable)
+	case len(lib.factions) == 0:
+		http.Error(w, "no factions loaded", http.StatusServiceUnavailable)
+	default:
+		io.WriteString(w, "ok\n")
+	}
+}
+
+// handleMetrics writes the metrics in the Prometheus text format
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	var factions, versions int
+	if s.current.Load() != nil {
+		factions, versions = s.Count()
+	}
+	s.metrics.write(w, factions, versions, s.Hub.Clients())
+}
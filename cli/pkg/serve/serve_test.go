@@ -0,0 +1,605 @@
+package serve
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// writeFaction writes a minimal faction folder with one index entry per unit, each
+// carrying health
+func writeFaction(t *testing.T, dir string, health map[string]float64) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	index := models.FactionIndex{}
+	for id, h := range health {
+		entry := models.UnitIndexEntry{Identifier: id, Unit: models.Unit{ID: id}}
+		entry.Unit.Specs.Combat = &models.CombatSpecs{Health: h}
+		index.Units = append(index.Units, entry)
+	}
+	data, _ := json.Marshal(index)
+	if err := os.WriteFile(filepath.Join(dir, "units.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "metadata.json"), []byte(`{"identifier":"mla","displayName":"MLA","version":"1.0.0"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	root := t.TempDir()
+	mla := filepath.Join(root, "MLA")
+	writeFaction(t, mla, map[string]float64{"tank": 100, "bot": 50, "old": 10})
+	os.MkdirAll(filepath.Join(root, "not-a-faction"), 0755)
+
+	var events []Event
+	w := &Watcher{Root: root, Publish: func(e Event) { events = append(events, e) }}
+	w.poll(false)
+	w.poll(true)
+	if len(events) != 0 {
+		t.Fatalf("unchanged folders published %v", events)
+	}
+
+	writeFaction(t, mla, map[string]float64{"tank": 200, "bot": 50, "new": 10})
+	// Make the rewrite visible on filesystems with coarse timestamps
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(filepath.Join(mla, "units.json"), future, future)
+	writeFaction(t, filepath.Join(root, "Legion"), map[string]float64{"legion_tank": 100})
+	w.poll(true)
+
+	want := []Event{
+		{Type: EventFactionReloaded, Faction: "Legion", Change: ChangeAdded},
+		{Type: EventUnitChanged, Faction: "MLA", Unit: "new", Change: ChangeAdded},
+		{Type: EventUnitChanged, Faction: "MLA", Unit: "old", Change: ChangeRemoved},
+		{Type: EventUnitChanged, Faction: "MLA", Unit: "tank", Change: ChangeModified},
+		{Type: EventFactionReloaded, Faction: "MLA", Change: ChangeModified},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %+v\nwant %+v", events, want)
+	}
+
+	events = nil
+	os.RemoveAll(filepath.Join(root, "Legion"))
+	os.WriteFile(filepath.Join(mla, "units.json"), []byte(`{"units": [`), 0644)
+	w.poll(true)
+	want = []Event{{Type: EventFactionReloaded, Faction: "Legion", Change: ChangeRemoved}}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %+v, want only the removal while MLA is half-written", events)
+	}
+}
+
+func TestWatcherVersionedAndArchives(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, ".git", "objects"), 0755)
+	var events []Event
+	w := &Watcher{Root: root, Publish: func(e Event) { events = append(events, e) }}
+	w.poll(false)
+
+	writeFaction(t, filepath.Join(root, "mla", "1.0.0"), map[string]float64{"tank": 100})
+	writeArchive(t, filepath.Join(root, "Legion-1.2.0.pafaction"), map[string]string{
+		"metadata.json": `{"identifier":"Legion","displayName":"Legion","version":"1.2.0"}`,
+		"units.json":    `{"units":[{"identifier":"legion_tank","unit":{"id":"legion_tank"}}]}`,
+	})
+	w.poll(true)
+	want := []Event{
+		{Type: EventFactionReloaded, Faction: "legion@1.2.0", Change: ChangeAdded},
+		{Type: EventFactionReloaded, Faction: "mla@1.0.0", Change: ChangeAdded},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %+v\nwant %+v", events, want)
+	}
+
+	events = nil
+	writeFaction(t, filepath.Join(root, "mla", "1.0.0"), map[string]float64{"tank": 200})
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(filepath.Join(root, "mla", "1.0.0", "units.json"), future, future)
+	os.Remove(filepath.Join(root, "Legion-1.2.0.pafaction"))
+	w.poll(true)
+	want = []Event{
+		{Type: EventUnitChanged, Faction: "mla@1.0.0", Unit: "tank", Change: ChangeModified},
+		{Type: EventFactionReloaded, Faction: "mla@1.0.0", Change: ChangeModified},
+		{Type: EventFactionReloaded, Faction: "legion@1.2.0", Change: ChangeRemoved},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %+v\nwant %+v", events, want)
+	}
+}
+
+// dialWebSocket performs a client handshake against a test server
+func dialWebSocket(t *testing.T, serverURL string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(serverURL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(conn, "GET /ws HTTP/1.1\r\nHost: test\r\nUpgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Example key and accept value from RFC 6455 section 1.3
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("handshake response %s %v", resp.Status, resp.Header)
+	}
+	return conn, r
+}
+
+// readServerFrame reads one unmasked frame with a short payload
+func readServerFrame(t *testing.T, r *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		t.Fatal(err)
+	}
+	length := int(head[1])
+	if length == 126 {
+		var ext [2]byte
+		io.ReadFull(r, ext[:])
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatal(err)
+	}
+	return head[0] & 0x0F, payload
+}
+
+func TestHubWebSocket(t *testing.T) {
+	root := t.TempDir()
+	writeFaction(t, filepath.Join(root, "MLA"), map[string]float64{"tank": 100})
+	s := New(root, Options{Watch: true})
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	conn, r := dialWebSocket(t, server.URL)
+	defer conn.Close()
+	for s.Hub.Clients() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	event := Event{Type: EventUnitChanged, Faction: "MLA", Unit: "tank", Change: ChangeModified}
+	s.Hub.Publish(event)
+	opcode, payload := readServerFrame(t, r)
+	var got Event
+	if err := json.Unmarshal(payload, &got); opcode != opText || err != nil || got != event {
+		t.Fatalf("frame %x %s, want %+v", opcode, payload, event)
+	}
+
+	// A masked ping is answered with a pong carrying the same payload
+	mask := []byte{1, 2, 3, 4}
+	ping := []byte("hi")
+	frame := []byte{0x80 | opPing, 0x80 | byte(len(ping))}
+	frame = append(frame, mask...)
+	for i, b := range ping {
+		frame = append(frame, b^mask[i%4])
+	}
+	conn.Write(frame)
+	if opcode, payload := readServerFrame(t, r); opcode != opPong || string(payload) != "hi" {
+		t.Errorf("ping answered with %x %q", opcode, payload)
+	}
+
+	s.Hub.Close()
+	if opcode, _ := readServerFrame(t, r); opcode != opClose {
+		t.Errorf("Close() sent opcode %x, want close", opcode)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	root := t.TempDir()
+	writeFaction(t, filepath.Join(root, "MLA"), map[string]float64{"tank": 100})
+
+	server := httptest.NewServer(New(root, Options{}).Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/factions/MLA/metadata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `"MLA"`) {
+		t.Errorf("GET metadata.json = %s %s", resp.Status, body)
+	}
+
+	// /ws only exists with Watch
+	if resp, _ := http.Get(server.URL + "/ws"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /ws without watch = %s, want 404", resp.Status)
+	}
+}
+
+func TestCaching(t *testing.T) {
+	root := t.TempDir()
+	mla := filepath.Join(root, "MLA")
+	writeFaction(t, mla, map[string]float64{"tank": 100})
+
+	s := New(root, Options{})
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+	url := server.URL + "/factions/MLA/units.json"
+
+	get := func(etag string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := get("")
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || len(etag) != etagLength+2 || strings.HasPrefix(etag, "W/") {
+		t.Fatalf("GET = %s with ETag %q, want 200 and a strong ETag", resp.Status, etag)
+	}
+	if got := resp.Header.Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("Cache-Control = %q, want no-cache", got)
+	}
+	if resp := get(etag); resp.StatusCode != http.StatusNotModified {
+		t.Errorf("GET with matching If-None-Match = %s, want 304", resp.Status)
+	}
+
+	// A re-export with different content changes the ETag
+	writeFaction(t, mla, map[string]float64{"tank": 200})
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(filepath.Join(mla, "units.json"), future, future)
+	resp = get(etag)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == etag {
+		t.Errorf("GET after re-export = %s with ETag %q, want 200 and a new ETag", resp.Status, resp.Header.Get("ETag"))
+	}
+
+	if got := cacheControl(time.Hour); got != "public, max-age=3600" {
+		t.Errorf("cacheControl(1h) = %q", got)
+	}
+}
+
+func TestParseOrigins(t *testing.T) {
+	got, err := ParseOrigins([]string{"https://PA-Pedia.example.com/", "http://localhost:5173", "*"})
+	want := []string{"https://pa-pedia.example.com", "http://localhost:5173", "*"}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseOrigins() = %v, %v, want %v", got, err, want)
+	}
+	for _, bad := range []string{"pa-pedia.example.com", "https://example.com/app", "ftp://example.com"} {
+		if _, err := ParseOrigins([]string{bad}); err == nil {
+			t.Errorf("ParseOrigins(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestCORS(t *testing.T) {
+	root := t.TempDir()
+	writeFaction(t, filepath.Join(root, "MLA"), map[string]float64{"tank": 100})
+
+	request := func(origins []string, method, origin string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, "/factions/MLA/units.json", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", "GET")
+			req.Header.Set("Access-Control-Request-Headers", "if-none-match")
+		}
+		rec := httptest.NewRecorder()
+		New(root, Options{CORSOrigins: origins}).Handler().ServeHTTP(rec, req)
+		return rec.Result()
+	}
+
+	allowed := []string{"https://app.example.com"}
+	resp := request(allowed, http.MethodGet, "https://app.example.com")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		!strings.Contains(resp.Header.Get("Access-Control-Expose-Headers"), "ETag") {
+		t.Errorf("allowed GET = %s %v", resp.Status, resp.Header)
+	}
+
+	resp = request(allowed, http.MethodOptions, "https://app.example.com")
+	if resp.StatusCode != http.StatusNoContent || resp.Header.Get("Access-Control-Allow-Headers") != "if-none-match" {
+		t.Errorf("preflight = %s %v", resp.Status, resp.Header)
+	}
+
+	if resp := request(allowed, http.MethodGet, "https://evil.example.com"); resp.Header.Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("other origin got Access-Control-Allow-Origin %q", resp.Header.Get("Access-Control-Allow-Origin"))
+	}
+	if resp := request([]string{"*"}, http.MethodGet, "https://any.example.com"); resp.Header.Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("wildcard got Access-Control-Allow-Origin %q", resp.Header.Get("Access-Control-Allow-Origin"))
+	}
+	if resp := request(nil, http.MethodGet, "https://app.example.com"); resp.Header.Get("Access-Control-Allow-Origin") != "" {
+		t.Error("CORS headers sent without --cors-origin")
+	}
+}
+
+// writeArchive zips files (name → content) into path
+func writeArchive(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, _ := zw.Create(name)
+		io.WriteString(w, content)
+	}
+	zw.Close()
+	f.Close()
+}
+
+func TestLibrary(t *testing.T) {
+	root := t.TempDir()
+	writeFaction(t, filepath.Join(root, "MLA"), map[string]float64{"tank": 100})
+	legion := func(version string) string {
+		return `{"identifier":"Legion","displayName":"Legion","version":"` + version + `"}`
+	}
+	os.MkdirAll(filepath.Join(root, "legion", "1.2.0"), 0755)
+	os.WriteFile(filepath.Join(root, "legion", "1.2.0", "metadata.json"), []byte(legion("1.2.0")), 0644)
+	writeArchive(t, filepath.Join(root, "Legion-1.10.0-pedia20250101000000.zip"), map[string]string{
+		"metadata.json": legion("1.10.0"),
+		"units.json":    `{"units":[{"identifier":"legion_tank","unit":{"id":"legion_tank"}}]}`,
+	})
+	writeArchive(t, filepath.Join(root, "Legion-1.2.0.pafaction"), map[string]string{"metadata.json": legion("1.2.0")})
+	writeArchive(t, filepath.Join(root, "broken.zip"), map[string]string{"readme.txt": "no metadata"})
+
+	lib, errs := ScanLibrary(root, nil)
+	if len(errs) != 2 {
+		t.Errorf("ScanLibrary() errors = %v, want the duplicate 1.2.0 and the archive without metadata", errs)
+	}
+	if got := lib.IDs(); !reflect.DeepEqual(got, []string{"legion", "mla"}) {
+		t.Errorf("IDs() = %v", got)
+	}
+
+	tests := []struct {
+		ref     string
+		version string
+		ok      bool
+	}{
+		{"legion", "1.10.0", true},
+		{"Legion@1.2.0", "1.2.0", true},
+		{"legion@9.9.9", "", false},
+		{"MLA", "1.0.0", true},
+		{"mla@1.0.0", "1.0.0", true},
+		{"bugs", "", false},
+	}
+	for _, tt := range tests {
+		v, ok := lib.Resolve(tt.ref)
+		if ok != tt.ok || (ok && v.Version != tt.version) {
+			t.Errorf("Resolve(%q) = %v, %v, want %s, %v", tt.ref, v, ok, tt.version, tt.ok)
+		}
+	}
+
+	// Unchanged archives are reused on rescan
+	again, _ := ScanLibrary(root, lib)
+	if a, _ := again.Resolve("legion"); a != lib.Versions("legion")[0] {
+		t.Error("rescan reopened an unchanged archive")
+	}
+}
+
+func TestAPI(t *testing.T) {
+	root := t.TempDir()
+	writeFaction(t, filepath.Join(root, "MLA"), map[string]float64{"tank": 100})
+	writeArchive(t, filepath.Join(root, "mla-0.9.0.zip"), map[string]string{
+		"metadata.json": `{"identifier":"mla","displayName":"MLA","version":"0.9.0"}`,
+		"units.json":    `{"units":[{"identifier":"old_tank","unit":{"id":"old_tank"}}]}`,
+	})
+	server := httptest.NewServer(New(root, Options{}).Handler())
+	defer server.Close()
+
+	get := func(path string, v any) int {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if v != nil && resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+				t.Fatalf("GET %s: %v", path, err)
+			}
+		}
+		return resp.StatusCode
+	}
+
+	var factions []FactionInfo
+	get("/factions", &factions)
+	if len(factions) != 1 || factions[0].Latest != "1.0.0" || len(factions[0].Versions) != 2 || factions[0].Versions[1].Ref != "mla@0.9.0" {
+		t.Errorf("/factions = %+v", factions)
+	}
+
+	var versions []VersionInfo
+	if get("/factions/mla/versions", &versions); len(versions) != 2 || versions[0].Version != "1.0.0" {
+		t.Errorf("/factions/mla/versions = %+v", versions)
+	}
+
+	var units []models.Unit
+	if get("/factions/mla@0.9.0/units", &units); len(units) != 1 || units[0].ID != "old_tank" {
+		t.Errorf("/factions/mla@0.9.0/units = %+v", units)
+	}
+	if get("/factions/mla/units", &units); len(units) != 1 || units[0].ID != "tank" {
+		t.Errorf("/factions/mla/units = %+v", units)
+	}
+
+	// Files of archived versions are served too
+	var metadata models.FactionMetadata
+	if get("/factions/mla@0.9.0/metadata.json", &metadata); metadata.Version != "0.9.0" {
+		t.Errorf("archived metadata.json version = %q", metadata.Version)
+	}
+	for _, path := range []string{"/factions/mla@2.0.0/units", "/factions/bugs/versions", "/factions/MLA/missing.json", "/factions/MLA/../../etc/passwd"} {
+		if code := get(path, nil); code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, code)
+		}
+	}
+
+	// Sources added after the first scan are found without a restart or --watch
+	defer func(interval time.Duration) { rescanInterval = interval }(rescanInterval)
+	rescanInterval = 0
+	writeFaction(t, filepath.Join(root, "legion", "1.2.0"), map[string]float64{"legion_tank": 100})
+	os.WriteFile(filepath.Join(root, "legion", "1.2.0", "metadata.json"), []byte(`{"identifier":"legion","displayName":"Legion","version":"1.2.0"}`), 0644)
+	writeArchive(t, filepath.Join(root, "bugs-2.0.0.pafaction"), map[string]string{
+		"metadata.json": `{"identifier":"bugs","displayName":"Bugs","version":"2.0.0"}`,
+	})
+	writeFaction(t, filepath.Join(root, "Plain"), map[string]float64{"plain_tank": 100})
+	os.WriteFile(filepath.Join(root, "Plain", "metadata.json"), []byte(`{"identifier":"plain","displayName":"Plain","version":"1.0.0"}`), 0644)
+	for _, path := range []string{"/factions/legion@1.2.0/units", "/factions/bugs/versions", "/factions/Plain/metadata.json"} {
+		if code := get(path, nil); code != http.StatusOK {
+			t.Errorf("GET %s after adding it = %d, want 200", path, code)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.0", "1.10.0", -1},
+		{"1.2.0", "1.2.0", 0},
+		{"1.2", "1.2.0", -1},
+		{"124664", "123000", 1},
+		{"1.0.0-beta", "1.0.0-alpha", 1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		query  string
+		accept string
+		want   string
+		ok     bool
+	}{
+		{"", "", FormatJSON, true},
+		{"", "*/*", FormatJSON, true},
+		{"", "text/csv", FormatCSV, true},
+		{"", "application/json;q=0.5, application/x-ndjson", FormatNDJSON, true},
+		{"", "text/csv;q=0.2, application/json;q=0.9", FormatJSON, true},
+		{"", "text/html, text/csv;q=0", FormatJSON, true},
+		{"format=CSV", "application/json", FormatCSV, true},
+		{"format=xml", "", "", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/factions/mla/units?"+tt.query, nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		if got, ok := negotiateFormat(r); got != tt.want || ok != tt.ok {
+			t.Errorf("negotiateFormat(%q, %q) = %q, %v, want %q, %v", tt.query, tt.accept, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestUnitsFormats(t *testing.T) {
+	root := t.TempDir()
+	writeFaction(t, filepath.Join(root, "MLA"), map[string]float64{"tank": 100})
+	handler := New(root, Options{}).Handler()
+
+	get := func(target, accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		return rec
+	}
+
+	rec := get("/factions/mla/units", "application/x-ndjson")
+	if rec.Header().Get("Content-Type") != "application/x-ndjson" || strings.Count(rec.Body.String(), "\n") != 1 ||
+		!strings.HasPrefix(rec.Body.String(), `{"id":"tank"`) {
+		t.Errorf("NDJSON = %s %q", rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+	if !strings.Contains(rec.Header().Get("Vary"), "Accept") {
+		t.Errorf("Vary = %q, want Accept", rec.Header().Get("Vary"))
+	}
+
+	rec = get("/factions/mla/units?format=csv", "")
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/csv") || len(lines) != 2 ||
+		!strings.Contains(lines[0], "specs.combat.health") || !strings.HasPrefix(lines[1], "tank,") {
+		t.Errorf("CSV = %s %q", rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `inline; filename="mla-1.0.0-units.csv"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+
+	if rec := get("/factions/mla/units?format=xml", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown format = %d, want 400", rec.Code)
+	}
+}
+
+func TestHealthAndMetrics(t *testing.T) {
+	root := t.TempDir()
+	s := New(root, Options{})
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return resp.StatusCode, string(body)
+	}
+
+	if code, _ := get("/healthz"); code != http.StatusOK {
+		t.Errorf("GET /healthz = %d, want 200", code)
+	}
+	if code, _ := get("/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("GET /readyz before scanning = %d, want 503", code)
+	}
+	s.Rescan()
+	if code, _ := get("/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("GET /readyz with no factions = %d, want 503", code)
+	}
+	writeFaction(t, filepath.Join(root, "MLA"), map[string]float64{"tank": 100})
+	s.Rescan()
+	if code, _ := get("/readyz"); code != http.StatusOK {
+		t.Errorf("GET /readyz with a faction = %d, want 200", code)
+	}
+
+	get("/factions/MLA/units.json")
+	get("/factions/MLA/units.json")
+	get("/factions/mla/units")
+	get("/factions/missing/units")
+
+	_, body := get("/metrics")
+	for _, want := range []string{
+		`pa_pedia_http_requests_total{route="GET /factions/{ref}/{file...}",code="200"} 2`,
+		`pa_pedia_http_requests_total{route="GET /factions/{ref}/units",code="200"} 1`,
+		`pa_pedia_http_requests_total{route="GET /factions/{ref}/units",code="404"} 1`,
+		`pa_pedia_http_requests_total{route="GET /readyz",code="503"} 2`,
+		"pa_pedia_asset_hash_cache_hits_total 1\n",
+		"pa_pedia_asset_hash_cache_misses_total 1\n",
+		`pa_pedia_faction_load_seconds_count{faction="mla"} 1`,
+		"pa_pedia_library_scans_total 2\n",
+		"pa_pedia_factions 1\n",
+		"# TYPE pa_pedia_http_requests_total counter\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("/metrics is missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package serve implements the serve command: a read-only HTTP server for a directory of
+// exported faction folders, versioned folders and release archives (see Library), laid
+// out at /factions/<folder>/ like the web app expects, with a small version-aware API and
+// an optional WebSocket feed of reloads for hot-reloading during mod development.
+package serve
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Options configures a Server
+type Options struct {
+	// Watch polls the factions directory and pushes changes to /ws clients
+	Watch bool
+	// WatchInterval is the polling interval (DefaultWatchInterval when zero)
+	WatchInterval time.Duration
+	// CacheMaxAge lets browsers reuse faction files without revalidating for this long.
+	// Zero sends Cache-Control: no-cache, so every use is revalidated by ETag.
+	CacheMaxAge time.Duration
+	// CORSOrigins are the origins (see ParseOrigins) whose pages may fetch faction data,
+	// e.g. a hosted web app; none disables CORS headers
+	CORSOrigins []string
+	// TLSCert and TLSKey are PEM files; when both are set the server speaks HTTPS
+	TLSCert string
+	TLSKey  string
+	// Logf reports watcher activity; nil for silence
+	Logf func(format string, args ...any)
+}
+
+// Server serves one directory of faction folders
+type Server struct {
+	Root string
+	Hub  *Hub
+
+	opts    Options
+	assets  assetManifest
+	metrics *Metrics
+	current atomic.Pointer[Library]
+
+	scanMu   sync.Mutex
+	lastScan time.Time
+}
+
+// rescanInterval is the least time between rescans triggered by requests (see
+// freshLibrary), so a burst of lookups for a missing faction scans the directory once
+var rescanInterval = time.Second
+
+// New creates a server for the faction folders under root
+func New(root string, opts Options) *Server {
+	return &Server{Root: root, Hub: NewHub(), opts: opts, metrics: newMetrics()}
+}
+
+// Rescan rebuilds the Library from Root, returning problems with individual sources
+// (which are left out) for the caller to report
+func (s *Server) Rescan() []error {
+	s.scanMu.Lock()
+	defer s.scanMu.Unlock()
+	start := time.Now()
+	lib, errs := ScanLibrary(s.Root, s.current.Load())
+	s.metrics.scan(time.Since(start))
+	s.current.Store(lib)
+	s.lastScan = time.Now()
+	return errs
+}
+
+// Count returns the number of factions and faction versions being served
+func (s *Server) Count() (factions, versions int) {
+	lib := s.library()
+	for _, id := range lib.IDs() {
+		factions++
+		versions += len(lib.Versions(id))
+	}
+	return factions, versions
+}
+
+// library returns the current Library, scanning on first use
+func (s *Server) library() *Library {
+	if lib := s.current.Load(); lib != nil {
+		return lib
+	}
+	s.Rescan()
+	return s.current.Load()
+}
+
+// freshLibrary rescans Root unless that was done within rescanInterval, so folders and
+// archives added without --watch (or that the watcher hasn't reached yet) are served
+// without a restart
+func (s *Server) freshLibrary() *Library {
+	s.scanMu.Lock()
+	stale := time.Since(s.lastScan) >= rescanInterval
+	s.scanMu.Unlock()
+	if stale {
+		s.Rescan()
+	}
+	return s.library()
+}
+
+// resolve looks ref up in the current Library, rescanning on a miss in case it was added
+// since the last scan
+func (s *Server) resolve(ref string) (*FactionVersion, bool) {
+	if v, ok := s.library().Resolve(ref); ok {
+		return v, true
+	}
+	return s.freshLibrary().Resolve(ref)
+}
+
+// Handler returns the HTTP routes. {ref} is "<id>@<version>", "<id>" for the newest
+// version, or a faction folder name (e.g. MLA):
+//
+//	/factions                   JSON list of factions and their versions
+//	/factions/{ref}/versions    JSON list of the faction's versions, newest first
+//	/factions/{ref}/units       the version's units as JSON, NDJSON or CSV (?format= or Accept)
+//	/factions/{ref}/{file...}   files of the version, with ETag and Cache-Control
+//	/ws                         WebSocket feed of Events (with Options.Watch only)
+//	/healthz, /readyz           liveness and readiness probes
+//	/metrics                    Prometheus metrics
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /factions", s.handleFactions)
+	mux.HandleFunc("GET /factions/{$}", s.handleFactions)
+	mux.HandleFunc("GET /factions/{ref}/versions", s.handleVersions)
+	mux.HandleFunc("GET /factions/{ref}/units", s.handleUnits)
+	mux.HandleFunc("GET /factions/{ref}/{file...}", s.handleFile)
+	if s.opts.Watch {
+		mux.Handle("/ws", s.Hub)
+	}
+	mux.HandleFunc("GET /healthz", handleHealthz)
+	mux.HandleFunc("GET /readyz", s.handleReadyz)
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+	mux.Handle("/{$}", http.RedirectHandler("/factions/", http.StatusFound))
+	return s.metrics.withMetrics(withCORS(s.opts.CORSOrigins, mux))
+}
+
+// TLS reports whether the server is configured for HTTPS
+func (s *Server) TLS() bool {
+	return s.opts.TLSCert != "" && s.opts.TLSKey != ""
+}
+
+// ListenAndServe serves on addr until ctx is cancelled, running the watcher alongside
+// when Options.Watch is set.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{Addr: addr, Handler: s.Handler()}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if s.opts.Watch {
+		publish := func(e Event) {
+			// New or removed folders must be in the library before clients refetch
+			if e.Type == EventFactionReloaded {
+				s.Rescan()
+			}
+			s.Hub.Publish(e)
+		}
+		watcher := &Watcher{Root: s.Root, Interval: s.opts.WatchInterval, Publish: publish, Logf: s.opts.Logf}
+		go watcher.Run(ctx)
+	}
+	go func() {
+		<-ctx.Done()
+		s.Hub.Close()
+		shutdownCtx, done := context.WithTimeout(context.Background(), 5*time.Second)
+		defer done()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	var err error
+	if s.TLS() {
+		err = srv.ListenAndServeTLS(s.opts.TLSCert, s.opts.TLSKey)
+	} else {
+		err = srv.ListenAndServe()
+	}
+	if err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
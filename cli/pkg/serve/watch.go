@@ -0,0 +1,269 @@
+package serve
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/bundle"
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+)
+
+// DefaultWatchInterval is how often Watcher polls the factions directory
+const DefaultWatchInterval = time.Second
+
+// Watcher polls a directory of faction folders and publishes an Event for every faction
+// and unit that changed. It covers the layouts Library serves: plain faction folders,
+// <id>/<version>/ folders and release archives. A folder is reloaded when the size or
+// modification time of its metadata.json or units.json changes, an archive when its own
+// does; units are compared by their full index entry. Polling keeps the CLI free of
+// platform-specific file notification dependencies.
+type Watcher struct {
+	Root     string
+	Interval time.Duration
+	Publish  func(Event)
+	// Logf reports reloads and unreadable folders; nil for silence
+	Logf func(format string, args ...any)
+
+	factions map[string]*watchedFaction
+}
+
+type watchedFaction struct {
+	stamp string
+	ref   string // Event.Faction for this source
+	units map[string][sha256.Size]byte
+}
+
+// watchSource is one folder or archive found by a poll
+type watchSource struct {
+	key     string // Folder or archive path relative to Root
+	path    string
+	folder  bool // Plain faction folder, named by its folder name in events
+	archive bool
+	stamp   string
+}
+
+// Run polls until ctx is cancelled. The first poll records the current state without
+// publishing anything.
+func (w *Watcher) Run(ctx context.Context) {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	w.poll(false)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.poll(true)
+		}
+	}
+}
+
+// poll checks every faction source once, publishing events when publish is set
+func (w *Watcher) poll(publish bool) {
+	if w.factions == nil {
+		w.factions = make(map[string]*watchedFaction)
+	}
+	emit := func(e Event) {
+		if publish && w.Publish != nil {
+			w.Publish(e)
+		}
+	}
+
+	sources, err := w.sources()
+	if err != nil {
+		w.logf("⚠ Cannot read %s: %v", w.Root, err)
+		return
+	}
+	present := make(map[string]bool)
+	for _, src := range sources {
+		present[src.key] = true
+
+		previous := w.factions[src.key]
+		if previous != nil && previous.stamp == src.stamp {
+			continue
+		}
+		ref, units, err := readSource(src)
+		if err != nil {
+			// Most likely caught mid-export; keep the old state and retry next poll
+			w.logf("⚠ Skipping %s until it can be read: %v", src.key, err)
+			if previous == nil {
+				delete(present, src.key)
+			}
+			continue
+		}
+		w.factions[src.key] = &watchedFaction{stamp: src.stamp, ref: ref, units: units}
+
+		change := ChangeAdded
+		var changed []Event
+		if previous != nil {
+			change = ChangeModified
+			changed = diffUnits(ref, previous.units, units)
+		}
+		for _, e := range changed {
+			emit(e)
+		}
+		emit(Event{Type: EventFactionReloaded, Faction: ref, Change: change})
+		if publish {
+			w.logf("↻ Reloaded %s (%d units changed)", ref, len(changed))
+		}
+	}
+
+	var removed []string
+	for key := range w.factions {
+		if !present[key] {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(removed)
+	for _, key := range removed {
+		emit(Event{Type: EventFactionReloaded, Faction: w.factions[key].ref, Change: ChangeRemoved})
+		delete(w.factions, key)
+	}
+}
+
+// sources lists the plain and versioned faction folders and the archives under Root, like
+// ScanLibrary
+func (w *Watcher) sources() ([]watchSource, error) {
+	entries, err := os.ReadDir(w.Root)
+	if err != nil {
+		return nil, err
+	}
+	var sources []watchSource
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(w.Root, name)
+		if !entry.IsDir() {
+			if ext := strings.ToLower(filepath.Ext(name)); ext == ".zip" || ext == bundle.Extension {
+				if info, err := entry.Info(); err == nil {
+					sources = append(sources, watchSource{key: name, path: path, archive: true, stamp: fmt.Sprintf("%d:%d", info.Size(), info.ModTime().UnixNano())})
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		if stamp, ok := folderStamp(path); ok {
+			sources = append(sources, watchSource{key: name, path: path, folder: true, stamp: stamp})
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(path, "metadata.json")); err == nil {
+			continue // A plain folder missing units.json, e.g. mid-export
+		}
+		subdirs, err := os.ReadDir(path)
+		if err != nil {
+			continue
+		}
+		for _, sub := range subdirs {
+			if !sub.IsDir() || strings.HasPrefix(sub.Name(), ".") {
+				continue
+			}
+			dir := filepath.Join(path, sub.Name())
+			if stamp, ok := folderStamp(dir); ok {
+				sources = append(sources, watchSource{key: name + "/" + sub.Name(), path: dir, stamp: stamp})
+			}
+		}
+	}
+	return sources, nil
+}
+
+// readSource reads a source's unit hashes and the name events use for it: the folder name
+// for plain folders, as in /factions/<folder>/, else <id>@<version> like Library refs
+func readSource(src watchSource) (string, map[string][sha256.Size]byte, error) {
+	fsys := os.DirFS(src.path)
+	if src.archive {
+		zr, err := zip.OpenReader(src.path)
+		if err != nil {
+			return "", nil, err
+		}
+		defer zr.Close()
+		fsys = zr
+	}
+
+	units, err := unitHashes(fsys)
+	if err != nil {
+		return "", nil, err
+	}
+	if src.folder {
+		return src.key, units, nil
+	}
+	metadata, err := exporter.ReadFactionMetadataFS(fsys)
+	if err != nil {
+		return "", nil, err
+	}
+	if metadata.Identifier == "" || metadata.Version == "" {
+		return "", nil, fmt.Errorf("metadata.json needs identifier and version")
+	}
+	return strings.ToLower(metadata.Identifier) + "@" + metadata.Version, units, nil
+}
+
+func (w *Watcher) logf(format string, args ...any) {
+	if w.Logf != nil {
+		w.Logf(format, args...)
+	}
+}
+
+// folderStamp fingerprints the files whose rewrite means a faction was re-exported. ok is
+// false for folders that aren't faction exports.
+func folderStamp(dir string) (stamp string, ok bool) {
+	for _, name := range []string{"metadata.json", "units.json"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			return "", false
+		}
+		stamp += fmt.Sprintf("%s:%d:%d;", name, info.Size(), info.ModTime().UnixNano())
+	}
+	return stamp, true
+}
+
+// unitHashes hashes every units.json entry by unit ID
+func unitHashes(fsys fs.FS) (map[string][sha256.Size]byte, error) {
+	index, err := exporter.ReadFactionIndexFS(fsys)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[string][sha256.Size]byte, len(index.Units))
+	for i := range index.Units {
+		data, err := json.Marshal(&index.Units[i])
+		if err != nil {
+			return nil, err
+		}
+		hashes[index.Units[i].Identifier] = sha256.Sum256(data)
+	}
+	return hashes, nil
+}
+
+// diffUnits returns unit-changed events sorted by unit ID
+func diffUnits(faction string, before, after map[string][sha256.Size]byte) []Event {
+	var events []Event
+	for id, hash := range after {
+		old, ok := before[id]
+		switch {
+		case !ok:
+			events = append(events, Event{Type: EventUnitChanged, Faction: faction, Unit: id, Change: ChangeAdded})
+		case old != hash:
+			events = append(events, Event{Type: EventUnitChanged, Faction: faction, Unit: id, Change: ChangeModified})
+		}
+	}
+	for id := range before {
+		if _, ok := after[id]; !ok {
+			events = append(events, Event{Type: EventUnitChanged, Faction: faction, Unit: id, Change: ChangeRemoved})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Unit < events[j].Unit })
+	return events
+}
@@ -0,0 +1,112 @@
+// Package shortcuts registers pa-pedia with Windows Explorer for the current user: an
+// "Extract faction here" entry on folders and folder backgrounds that opens the ui command
+// there, and an association that unpacks .pafaction bundles when they're opened. Everything
+// lives under HKEY_CURRENT_USER, so no administrator rights are needed.
+package shortcuts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/bundle"
+)
+
+// ProgID is the file type .pafaction bundles are associated with
+const ProgID = "PAPedia.Faction"
+
+// verb is the Explorer menu entry's key name under shell\
+const verb = "PAPedia.Extract"
+
+// ErrUnsupported is returned by Install and Uninstall outside Windows
+var ErrUnsupported = errors.New("shortcuts can only be installed on Windows")
+
+// Value is one registry value under HKEY_CURRENT_USER
+type Value struct {
+	Key  string // e.g. Software\Classes\.pafaction
+	Name string // Empty for the key's default value
+	Data string
+}
+
+// String reads like `HKCU\Software\Classes\.pafaction (default) = PAPedia.Faction`
+func (v Value) String() string {
+	name := v.Name
+	if name == "" {
+		name = "(default)"
+	}
+	return fmt.Sprintf(`HKCU\%s %s = %s`, v.Key, name, v.Data)
+}
+
+// Values returns the registry values that register exe, the absolute path of pa-pedia.exe.
+// Folder entries run `ui --output <folder>`; opening a bundle unpacks it beside itself in
+// a console window that stays open to show the result.
+func Values(exe string) []Value {
+	quoted := `"` + exe + `"`
+	values := []Value{
+		{Key: `Software\Classes\` + bundle.Extension, Data: ProgID},
+		{Key: `Software\Classes\` + ProgID, Data: "PA-Pedia faction bundle"},
+		{Key: `Software\Classes\` + ProgID + `\shell\open`, Data: "Unpack faction"},
+		// cmd strips the outer quotes; pause keeps the window open after unpack exits
+		{Key: `Software\Classes\` + ProgID + `\shell\open\command`, Data: `cmd.exe /c "` + quoted + ` unpack --bundle "%1" --output "%1\.." & pause"`},
+	}
+	for _, folder := range []struct{ key, arg string }{
+		{`Software\Classes\Directory\shell\` + verb, "%1"},
+		{`Software\Classes\Directory\Background\shell\` + verb, "%V"},
+	} {
+		values = append(values,
+			Value{Key: folder.key, Data: "Extract faction here"},
+			Value{Key: folder.key + `\command`, Data: quoted + ` ui --output "` + folder.arg + `"`},
+		)
+	}
+	return values
+}
+
+// Keys returns every key Values creates, children before their parents, the order
+// Uninstall deletes them in
+func Keys() []string {
+	return []string{
+		`Software\Classes\` + ProgID + `\shell\open\command`,
+		`Software\Classes\` + ProgID + `\shell\open`,
+		`Software\Classes\` + ProgID + `\shell`,
+		`Software\Classes\` + ProgID,
+		`Software\Classes\` + bundle.Extension,
+		`Software\Classes\Directory\shell\` + verb + `\command`,
+		`Software\Classes\Directory\shell\` + verb,
+		`Software\Classes\Directory\Background\shell\` + verb + `\command`,
+		`Software\Classes\Directory\Background\shell\` + verb,
+	}
+}
+
+// AddToPath returns the ;-separated PATH value path with dir appended, and whether it
+// changed. Entries are compared as Windows does: case-insensitively, ignoring a trailing
+// backslash.
+func AddToPath(path, dir string) (string, bool) {
+	for _, entry := range strings.Split(path, ";") {
+		if samePath(entry, dir) {
+			return path, false
+		}
+	}
+	if path == "" || strings.HasSuffix(path, ";") {
+		return path + dir, true
+	}
+	return path + ";" + dir, true
+}
+
+// RemoveFromPath returns path without any entry for dir, and whether it changed
+func RemoveFromPath(path, dir string) (string, bool) {
+	entries := strings.Split(path, ";")
+	kept := entries[:0]
+	for _, entry := range entries {
+		if !samePath(entry, dir) {
+			kept = append(kept, entry)
+		}
+	}
+	if len(kept) == len(entries) {
+		return path, false
+	}
+	return strings.Join(kept, ";"), true
+}
+
+func samePath(a, b string) bool {
+	return strings.EqualFold(strings.TrimRight(a, `\/`), strings.TrimRight(b, `\/`))
+}
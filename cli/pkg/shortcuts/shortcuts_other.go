@@ -0,0 +1,13 @@
+//go:build !windows
+
+package shortcuts
+
+// Install returns ErrUnsupported outside Windows
+func Install(exe string, addToPath bool) error {
+	return ErrUnsupported
+}
+
+// Uninstall returns ErrUnsupported outside Windows
+func Uninstall(exe string, removeFromPath bool) error {
+	return ErrUnsupported
+}
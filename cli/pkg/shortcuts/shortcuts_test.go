@@ -0,0 +1,62 @@
+package shortcuts
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValues(t *testing.T) {
+	exe := `C:\Tools\PA Pedia\pa-pedia.exe`
+	values := Values(exe)
+
+	keys := Keys()
+	for _, v := range values {
+		if !slices.Contains(keys, v.Key) {
+			t.Errorf("Keys() is missing %s, so uninstalling would leave it behind", v.Key)
+		}
+		if strings.HasSuffix(v.Key, `\command`) && !strings.Contains(v.Data, `"`+exe+`"`) {
+			t.Errorf("%s doesn't run the quoted executable", v)
+		}
+	}
+	// Children must be deleted before their parents
+	for i, key := range keys {
+		for _, later := range keys[i+1:] {
+			if strings.HasPrefix(later, key+`\`) {
+				t.Errorf("Keys() deletes %s before its child %s", key, later)
+			}
+		}
+	}
+
+	want := `HKCU\Software\Classes\.pafaction (default) = PAPedia.Faction`
+	if got := values[0].String(); got != want {
+		t.Errorf("values[0] = %q, want %q", got, want)
+	}
+}
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		dir     string
+		added   string
+		removed string
+	}{
+		{"empty", "", `C:\Tools`, `C:\Tools`, ""},
+		{"append", `C:\Windows;C:\Go\bin`, `C:\Tools`, `C:\Windows;C:\Go\bin;C:\Tools`, `C:\Windows;C:\Go\bin`},
+		{"trailing separator", `C:\Windows;`, `C:\Tools`, `C:\Windows;C:\Tools`, `C:\Windows;`},
+		{"present", `C:\Windows;c:\tools\;C:\Go\bin`, `C:\Tools`, `C:\Windows;c:\tools\;C:\Go\bin`, `C:\Windows;C:\Go\bin`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, changed := AddToPath(tt.path, tt.dir)
+			if added != tt.added || changed != (tt.added != tt.path) {
+				t.Errorf("AddToPath() = %q, %v, want %q", added, changed, tt.added)
+			}
+			removed, changed := RemoveFromPath(tt.path, tt.dir)
+			if removed != tt.removed || changed != (tt.removed != tt.path) {
+				t.Errorf("RemoveFromPath() = %q, %v, want %q", removed, changed, tt.removed)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+//go:build windows
+
+package shortcuts
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// Install writes Values(exe) and, with addToPath, appends exe's folder to the user's PATH
+func Install(exe string, addToPath bool) error {
+	for _, v := range Values(exe) {
+		key, _, err := registry.CreateKey(registry.CURRENT_USER, v.Key, registry.SET_VALUE)
+		if err != nil {
+			return fmt.Errorf("failed to create HKCU\\%s: %w", v.Key, err)
+		}
+		err = key.SetStringValue(v.Name, v.Data)
+		key.Close()
+		if err != nil {
+			return fmt.Errorf("failed to set %s: %w", v, err)
+		}
+	}
+	notifyAssociationsChanged()
+	if addToPath {
+		return editPath(func(path string) (string, bool) { return AddToPath(path, filepath.Dir(exe)) })
+	}
+	return nil
+}
+
+// Uninstall deletes Keys() and, with removeFromPath, removes exe's folder from the user's
+// PATH. Keys that don't exist are skipped.
+func Uninstall(exe string, removeFromPath bool) error {
+	for _, k := range Keys() {
+		if err := registry.DeleteKey(registry.CURRENT_USER, k); err != nil && !errors.Is(err, registry.ErrNotExist) {
+			return fmt.Errorf("failed to delete HKCU\\%s: %w", k, err)
+		}
+	}
+	notifyAssociationsChanged()
+	if removeFromPath {
+		return editPath(func(path string) (string, bool) { return RemoveFromPath(path, filepath.Dir(exe)) })
+	}
+	return nil
+}
+
+// editPath rewrites the user's PATH (HKCU\Environment) and tells running programs, such
+// as Explorer, so new terminals see it
+func editPath(edit func(string) (string, bool)) error {
+	key, err := registry.OpenKey(registry.CURRENT_USER, "Environment", registry.QUERY_VALUE|registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to open HKCU\\Environment: %w", err)
+	}
+	defer key.Close()
+
+	path, valueType, err := key.GetStringValue("Path")
+	if err != nil && !errors.Is(err, registry.ErrNotExist) {
+		return fmt.Errorf("failed to read the user PATH: %w", err)
+	}
+	updated, changed := edit(path)
+	if !changed {
+		return nil
+	}
+	if valueType == registry.SZ {
+		err = key.SetStringValue("Path", updated)
+	} else {
+		err = key.SetExpandStringValue("Path", updated) // The default type, keeping %VARS% working
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write the user PATH: %w", err)
+	}
+
+	const hwndBroadcast, wmSettingChange, smtoAbortIfHung = 0xffff, 0x001a, 0x0002
+	env, _ := syscall.UTF16PtrFromString("Environment")
+	windows.NewLazySystemDLL("user32.dll").NewProc("SendMessageTimeoutW").Call(
+		hwndBroadcast, wmSettingChange, 0, uintptr(unsafe.Pointer(env)), smtoAbortIfHung, 5000, 0)
+	return nil
+}
+
+// notifyAssociationsChanged makes Explorer pick up the new menu entries and file type
+func notifyAssociationsChanged() {
+	const shcneAssocChanged, shcnfIDList = 0x08000000, 0
+	windows.NewLazySystemDLL("shell32.dll").NewProc("SHChangeNotify").Call(shcneAssocChanged, shcnfIDList, 0, 0)
+}
@@ -0,0 +1,302 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// Finding kinds
+const (
+	// FindingOutlier is a unit whose efficiency is far from its tier's baseline median
+	FindingOutlier = "outlier"
+	// FindingRegression is a unit whose stats changed far from the baseline unit with the same ID
+	FindingRegression = "regression"
+)
+
+// DefaultThreshold is the ratio (either way) beyond which a metric is flagged
+const DefaultThreshold = 2.0
+
+// EfficiencyMetrics are the cost-efficiency ratios outliers are judged on. They are only
+// defined for units with a build cost.
+var EfficiencyMetrics = []Metric{
+	{Name: "hpPerMetal", Unit: "hp/metal", Value: func(u *models.Unit) float64 { return perMetal(Health(u), u) }},
+	{Name: "dpsPerMetal", Unit: "dps/metal", Value: func(u *models.Unit) float64 { return perMetal(DPS(u), u) }},
+}
+
+func perMetal(v float64, u *models.Unit) float64 {
+	cost := BuildCost(u)
+	if cost <= 0 {
+		return 0
+	}
+	return v / cost
+}
+
+// Finding is one flagged metric of one unit
+type Finding struct {
+	Kind        string  `json:"kind"`
+	UnitID      string  `json:"unitId"`
+	DisplayName string  `json:"displayName"`
+	Tier        int     `json:"tier"`
+	Metric      string  `json:"metric"`
+	MetricUnit  string  `json:"metricUnit"`
+	Value       float64 `json:"value"`
+	Baseline    float64 `json:"baseline"`  // Group median for outliers, the baseline unit's value for regressions
+	Ratio       float64 `json:"ratio"`     // Value / Baseline; ±Inf when the stat was gained or lost
+	Reference   string  `json:"reference"` // What Baseline was measured on, e.g. "the T1 vehicle median (1.30 over 16 units, e.g. Ant 1.33)"
+}
+
+// CompareOptions configures Compare
+type CompareOptions struct {
+	// Threshold is the ratio beyond which a metric is flagged: Ratio >= Threshold or
+	// Ratio <= 1/Threshold. Defaults to DefaultThreshold.
+	Threshold float64
+	// Thresholds overrides Threshold per metric name (e.g. "dpsPerMetal": 1.5)
+	Thresholds map[string]float64
+}
+
+func (o CompareOptions) threshold(metric string) float64 {
+	if t, ok := o.Thresholds[metric]; ok && t > 1 {
+		return t
+	}
+	if o.Threshold > 1 {
+		return o.Threshold
+	}
+	return DefaultThreshold
+}
+
+// ParseThresholds parses a comma-separated "metric=ratio" list (e.g. "hpPerMetal=1.5,dps=3").
+// Metric names are those of Metrics and EfficiencyMetrics; ratios must be above 1.
+func ParseThresholds(spec string) (map[string]float64, error) {
+	known := make(map[string]bool)
+	for _, m := range append(append([]Metric{}, Metrics...), EfficiencyMetrics...) {
+		known[m.Name] = true
+	}
+
+	thresholds := make(map[string]float64)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("threshold %q must be metric=ratio", part)
+		}
+		name = strings.TrimSpace(name)
+		if !known[name] {
+			return nil, fmt.Errorf("unknown metric %q in threshold %q", name, part)
+		}
+		ratio, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || ratio <= 1 {
+			return nil, fmt.Errorf("threshold %q must be a ratio above 1", part)
+		}
+		thresholds[name] = ratio
+	}
+	return thresholds, nil
+}
+
+// Compare flags units of a faction against a baseline (usually the vanilla MLA export).
+// Units sharing an ID with a baseline unit are checked for regressions on every raw and
+// efficiency metric. Other units are outliers when an efficiency metric is beyond the
+// threshold from the median of baseline units with the same tier and build bar category,
+// falling back to the whole tier when the baseline has no such category. Commanders are
+// skipped. Findings are sorted by how far they deviate, largest first.
+func Compare(units, baseline []*models.Unit, opts CompareOptions) []Finding {
+	baselineByID := make(map[string]*models.Unit, len(baseline))
+	for _, unit := range baseline {
+		baselineByID[unit.ID] = unit
+	}
+
+	var findings []Finding
+	for _, unit := range units {
+		if IsCommander(unit) {
+			continue
+		}
+		if vanilla, ok := baselineByID[unit.ID]; ok {
+			findings = append(findings, regressions(unit, vanilla, opts)...)
+			continue
+		}
+		for _, metric := range EfficiencyMetrics {
+			value := metric.Value(unit)
+			if value <= 0 {
+				continue
+			}
+			median, reference := baselineMedian(baseline, metric, unit)
+			if median <= 0 {
+				continue
+			}
+			if f, ok := flag(FindingOutlier, unit, metric, value, median, reference, opts); ok {
+				findings = append(findings, f)
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		di, dj := deviation(findings[i].Ratio), deviation(findings[j].Ratio)
+		if di != dj {
+			return di > dj
+		}
+		if findings[i].UnitID != findings[j].UnitID {
+			return findings[i].UnitID < findings[j].UnitID
+		}
+		return findings[i].Metric < findings[j].Metric
+	})
+	return findings
+}
+
+// regressions compares a unit with the baseline unit of the same ID
+func regressions(unit, vanilla *models.Unit, opts CompareOptions) []Finding {
+	var findings []Finding
+	for _, metric := range append(append([]Metric{}, Metrics...), EfficiencyMetrics...) {
+		value, before := metric.Value(unit), metric.Value(vanilla)
+		reference := fmt.Sprintf("the baseline unit (%s)", FormatStat(before))
+		if before <= 0 || value <= 0 {
+			// Gaining or losing a stat entirely (e.g. a weapon) is always worth a look
+			if (before > 0) != (value > 0) {
+				findings = append(findings, Finding{
+					Kind: FindingRegression, UnitID: unit.ID, DisplayName: unit.DisplayName, Tier: unit.Tier,
+					Metric: metric.Name, MetricUnit: metric.Unit, Value: value, Baseline: before,
+					Ratio: math.Inf(boolSign(value > 0)), Reference: reference,
+				})
+			}
+			continue
+		}
+		if f, ok := flag(FindingRegression, unit, metric, value, before, reference, opts); ok {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// deviation is how far a ratio is from 1 either way; gained and lost stats rank first
+func deviation(ratio float64) float64 {
+	if math.IsInf(ratio, 0) {
+		return math.Inf(1)
+	}
+	return math.Abs(math.Log(ratio))
+}
+
+func boolSign(positive bool) int {
+	if positive {
+		return 1
+	}
+	return -1
+}
+
+// flag returns a finding when value/baseline is beyond the metric's threshold
+func flag(kind string, unit *models.Unit, metric Metric, value, baseline float64, reference string, opts CompareOptions) (Finding, bool) {
+	ratio := value / baseline
+	t := opts.threshold(metric.Name)
+	if ratio < t && ratio > 1/t {
+		return Finding{}, false
+	}
+	return Finding{
+		Kind: kind, UnitID: unit.ID, DisplayName: unit.DisplayName, Tier: unit.Tier,
+		Metric: metric.Name, MetricUnit: metric.Unit, Value: value, Baseline: baseline,
+		Ratio: ratio, Reference: reference,
+	}, true
+}
+
+// baselineMedian returns the median of metric over baseline units in the unit's tier and
+// category, and a description naming the baseline unit closest to it. The whole tier is
+// used when the baseline has no units in that category; when the category exists but none
+// of its units have the metric (e.g. unarmed economy buildings) the median is 0.
+func baselineMedian(baseline []*models.Unit, metric Metric, unit *models.Unit) (float64, string) {
+	category := exporter.BuildMenuCategory(unit.UnitTypes)
+
+	var tier, sameCategory []*models.Unit
+	for _, b := range baseline {
+		if b.Tier != unit.Tier || IsCommander(b) {
+			continue
+		}
+		tier = append(tier, b)
+		if exporter.BuildMenuCategory(b.UnitTypes) == category {
+			sameCategory = append(sameCategory, b)
+		}
+	}
+
+	label := fmt.Sprintf("the T%d %s median", unit.Tier, category)
+	group := sameCategory
+	if len(sameCategory) == 0 {
+		label = fmt.Sprintf("the T%d median", unit.Tier)
+		group = tier
+	}
+	var members []*models.Unit
+	for _, b := range group {
+		if metric.Value(b) > 0 {
+			members = append(members, b)
+		}
+	}
+	if len(members) == 0 {
+		return 0, ""
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		vi, vj := metric.Value(members[i]), metric.Value(members[j])
+		if vi != vj {
+			return vi < vj
+		}
+		return members[i].ID < members[j].ID
+	})
+	values := make([]float64, len(members))
+	for i, m := range members {
+		values[i] = metric.Value(m)
+	}
+	median := Percentile(values, 50)
+
+	closest := members[0]
+	for _, m := range members {
+		if math.Abs(metric.Value(m)-median) < math.Abs(metric.Value(closest)-median) {
+			closest = m
+		}
+	}
+	noun := "units"
+	if len(members) == 1 {
+		noun = "unit"
+	}
+	return median, fmt.Sprintf("%s (%s over %d %s, e.g. %s %s)", label, FormatStat(median), len(members), noun, closest.DisplayName, FormatStat(metric.Value(closest)))
+}
+
+// Describe renders a finding as one review line, e.g.
+// "Bulldog (tank_heavy) T1: hpPerMetal 4.00 hp/metal is 3.1x the T1 vehicle median (1.30 ...)"
+func (f Finding) Describe() string {
+	var change string
+	switch {
+	case math.IsInf(f.Ratio, 1):
+		change = fmt.Sprintf("gained %s (%s %s, baseline unit had none)", f.Metric, FormatStat(f.Value), f.MetricUnit)
+	case math.IsInf(f.Ratio, -1):
+		change = fmt.Sprintf("lost %s (baseline unit had %s %s)", f.Metric, FormatStat(f.Baseline), f.MetricUnit)
+	default:
+		change = fmt.Sprintf("%s %s %s is %sx %s", f.Metric, FormatStat(f.Value), f.MetricUnit, formatRatio(f.Ratio), f.Reference)
+	}
+	return fmt.Sprintf("%s (%s) T%d: %s", f.DisplayName, f.UnitID, f.Tier, change)
+}
+
+// FormatStat prints a value with precision suited to its magnitude, so small ratios
+// like 0.004 dps/metal keep their significant digits
+func FormatStat(v float64) string {
+	switch a := math.Abs(v); {
+	case a >= 100:
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	case a >= 1:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	case a >= 0.01 || a == 0:
+		return strconv.FormatFloat(v, 'f', 3, 64)
+	default:
+		return strconv.FormatFloat(v, 'g', 2, 64)
+	}
+}
+
+// formatRatio prints a ratio with one decimal, keeping two significant digits below 0.1
+func formatRatio(r float64) string {
+	if r >= 0.1 {
+		return strconv.FormatFloat(r, 'f', 1, 64)
+	}
+	return strconv.FormatFloat(r, 'g', 2, 64)
+}
@@ -0,0 +1,159 @@
+package stats
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func testUnits(entries ...models.UnitIndexEntry) []*models.Unit {
+	units := make([]*models.Unit, len(entries))
+	for i := range entries {
+		unit := entries[i].Unit
+		units[i] = &unit
+	}
+	return units
+}
+
+func TestCompare(t *testing.T) {
+	land := []string{"Mobile", "Tank", "Land"}
+	bot := []string{"Mobile", "Bot", "Land"}
+	baseline := testUnits(
+		testUnit("commander", 1, []string{"Commander", "Mobile", "Land"}, 12500, 50000, 300, 10),
+		// T1 vehicles: hp/metal 1, 1.5, 2 (median 1.5); dps/metal 0.1, 0.1, 0.1
+		testUnit("ant", 1, land, 150, 100, 10, 12),
+		testUnit("skitter", 1, land, 300, 200, 20, 20),
+		testUnit("leveler", 1, land, 600, 300, 30, 10),
+		testUnit("dox", 2, bot, 200, 200, 20, 15),
+	)
+
+	tests := []struct {
+		name  string
+		units []*models.Unit
+		opts  CompareOptions
+		want  []string // kind/unit/metric
+	}{
+		{
+			name:  "identical faction",
+			units: baseline,
+			want:  nil,
+		},
+		{
+			name: "outlier against tier and category median",
+			units: testUnits(
+				// hp/metal 4.5 is 3x the 1.5 median; dps/metal 0.1 matches
+				testUnit("bulldog", 1, land, 450, 100, 10, 12),
+				// A T1 bot falls back to the whole T1 median: hp/metal 1.5, dps/metal 0.05 is 0.5x
+				testUnit("grunt", 1, bot, 150, 100, 5, 12),
+			),
+			want: []string{"outlier/bulldog/hpPerMetal", "outlier/grunt/dpsPerMetal"},
+		},
+		{
+			name:  "per-metric threshold",
+			units: testUnits(testUnit("grunt", 1, bot, 150, 100, 5, 12)),
+			opts:  CompareOptions{Thresholds: map[string]float64{"dpsPerMetal": 3}},
+			want:  nil,
+		},
+		{
+			name: "regressions against the same ID",
+			units: testUnits(
+				// Health tripled (so hp/metal too), weapon removed
+				testUnit("ant", 1, land, 450, 100, 0, 12),
+				// Cost and speed within 2x
+				testUnit("skitter", 1, land, 300, 300, 20, 15),
+			),
+			want: []string{"regression/ant/dps", "regression/ant/dpsPerMetal", "regression/ant/health", "regression/ant/hpPerMetal"},
+		},
+		{
+			name:  "commanders and tiers missing from the baseline are skipped",
+			units: testUnits(testUnit("mod_commander", 1, []string{"Commander", "Mobile"}, 1, 1, 1, 1), testUnit("titan", 3, land, 30000, 30000, 1000, 6)),
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range Compare(tt.units, baseline, tt.opts) {
+				got = append(got, f.Kind+"/"+f.UnitID+"/"+f.Metric)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compare() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareFinding(t *testing.T) {
+	baseline := testUnits(
+		testUnit("ant", 1, []string{"Mobile", "Tank", "Land"}, 150, 100, 10, 12),
+		testUnit("skitter", 1, []string{"Mobile", "Tank", "Land"}, 300, 200, 20, 20),
+	)
+	units := testUnits(testUnit("bulldog", 1, []string{"Mobile", "Tank", "Land"}, 600, 100, 10, 12))
+
+	findings := Compare(units, baseline, CompareOptions{})
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1: %+v", len(findings), findings)
+	}
+	f := findings[0]
+	if f.Value != 6 || f.Baseline != 1.5 || f.Ratio != 4 {
+		t.Errorf("finding = %+v, want value 6, baseline 1.5, ratio 4", f)
+	}
+	want := "bulldog (bulldog) T1: hpPerMetal 6.00 hp/metal is 4.0x the T1 vehicle median (1.50 over 2 units, e.g. ant 1.50)"
+	if got := f.Describe(); got != want {
+		t.Errorf("Describe() = %q, want %q", got, want)
+	}
+
+	lost := Finding{UnitID: "ant", DisplayName: "Ant", Tier: 1, Metric: "dps", MetricUnit: "dps", Baseline: 10, Ratio: math.Inf(-1)}
+	if got := lost.Describe(); !strings.Contains(got, "lost dps (baseline unit had 10.00 dps)") {
+		t.Errorf("Describe() = %q", got)
+	}
+}
+
+func TestParseThresholds(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    map[string]float64
+		wantErr bool
+	}{
+		{spec: "", want: map[string]float64{}},
+		{spec: "hpPerMetal=1.5, dps=3", want: map[string]float64{"hpPerMetal": 1.5, "dps": 3}},
+		{spec: "hpPerMetal", wantErr: true},
+		{spec: "armor=2", wantErr: true},
+		{spec: "dps=0.5", wantErr: true},
+		{spec: "dps=abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseThresholds(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseThresholds(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseThresholds(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatStat(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{0, "0.000"},
+		{0.0025, "0.0025"},
+		{0.437, "0.437"},
+		{1.5, "1.50"},
+		{12500, "12500"},
+	}
+	for _, tt := range tests {
+		if got := FormatStat(tt.v); got != tt.want {
+			t.Errorf("FormatStat(%v) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
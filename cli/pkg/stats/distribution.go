@@ -0,0 +1,198 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// Metric is a per-unit statistic that distributions are computed over
+type Metric struct {
+	Name  string
+	Unit  string // Label for values, e.g. "metal"
+	Value func(*models.Unit) float64
+}
+
+// Metrics are the statistics covered by Distributions, in output order
+var Metrics = []Metric{
+	{Name: "dps", Unit: "dps", Value: DPS},
+	{Name: "health", Unit: "hp", Value: Health},
+	{Name: "cost", Unit: "metal", Value: BuildCost},
+	{Name: "speed", Unit: "speed", Value: MoveSpeed},
+}
+
+// DefaultBins is the histogram bucket count used when none is given
+const DefaultBins = 10
+
+// Bin is one histogram bucket covering [Min, Max) (the last bucket includes Max)
+type Bin struct {
+	Min   float64  `json:"min"`
+	Max   float64  `json:"max"`
+	Count int      `json:"count"`
+	Units []string `json:"units"`
+}
+
+// Percentiles are linearly interpolated between the closest ranks
+type Percentiles struct {
+	P10 float64 `json:"p10"`
+	P25 float64 `json:"p25"`
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P90 float64 `json:"p90"`
+}
+
+// Distribution describes one metric over the units of one tier
+type Distribution struct {
+	Metric      string      `json:"metric"`
+	Tier        int         `json:"tier"`
+	Count       int         `json:"count"`
+	Min         float64     `json:"min"`
+	Max         float64     `json:"max"`
+	Mean        float64     `json:"mean"`
+	Percentiles Percentiles `json:"percentiles"`
+	Histogram   []Bin       `json:"histogram"`
+}
+
+// DistributionReport is the distributions.json document
+type DistributionReport struct {
+	Faction       string         `json:"faction"`
+	Bins          int            `json:"bins"`
+	Distributions []Distribution `json:"distributions"`
+}
+
+// Distributions computes every metric's distribution per tier over units. Like the tier
+// averages, commanders are left out and a unit only counts towards a metric when its
+// value is positive (so DPS covers armed units and speed mobile units). Results are
+// ordered by Metrics, then tier; empty metric/tier pairs are omitted.
+func Distributions(units []*models.Unit, bins int) []Distribution {
+	if bins < 1 {
+		bins = DefaultBins
+	}
+
+	type sample struct {
+		id    string
+		value float64
+	}
+
+	var out []Distribution
+	for _, metric := range Metrics {
+		byTier := make(map[int][]sample)
+		for _, unit := range units {
+			if IsCommander(unit) {
+				continue
+			}
+			if v := metric.Value(unit); v > 0 {
+				byTier[unit.Tier] = append(byTier[unit.Tier], sample{id: unit.ID, value: v})
+			}
+		}
+
+		tiers := make([]int, 0, len(byTier))
+		for tier := range byTier {
+			tiers = append(tiers, tier)
+		}
+		sort.Ints(tiers)
+
+		for _, tier := range tiers {
+			samples := byTier[tier]
+			sort.Slice(samples, func(i, j int) bool {
+				if samples[i].value != samples[j].value {
+					return samples[i].value < samples[j].value
+				}
+				return samples[i].id < samples[j].id
+			})
+
+			values := make([]float64, len(samples))
+			sum := 0.0
+			for i, s := range samples {
+				values[i] = s.value
+				sum += s.value
+			}
+
+			d := Distribution{
+				Metric: metric.Name,
+				Tier:   tier,
+				Count:  len(values),
+				Min:    values[0],
+				Max:    values[len(values)-1],
+				Mean:   round(sum / float64(len(values))),
+				Percentiles: Percentiles{
+					P10: round(Percentile(values, 10)),
+					P25: round(Percentile(values, 25)),
+					P50: round(Percentile(values, 50)),
+					P75: round(Percentile(values, 75)),
+					P90: round(Percentile(values, 90)),
+				},
+			}
+
+			d.Histogram = make([]Bin, bins)
+			width := (d.Max - d.Min) / float64(bins)
+			for i := range d.Histogram {
+				d.Histogram[i].Min = round(d.Min + width*float64(i))
+				d.Histogram[i].Max = round(d.Min + width*float64(i+1))
+				d.Histogram[i].Units = []string{}
+			}
+			d.Histogram[bins-1].Max = d.Max
+			for _, s := range samples {
+				b := bins - 1
+				if width > 0 {
+					b = min(int((s.value-d.Min)/width), bins-1)
+				}
+				d.Histogram[b].Count++
+				d.Histogram[b].Units = append(d.Histogram[b].Units, s.id)
+			}
+
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// Percentile returns the p-th percentile (0-100) of sorted values, interpolating
+// linearly between the closest ranks. Returns 0 for no values.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(rank-float64(lo))
+}
+
+// Chart renders a distribution's histogram as ASCII bars scaled so the fullest bin is
+// width characters wide, one line per bin.
+func Chart(d Distribution, width int) []string {
+	peak := 0
+	for _, b := range d.Histogram {
+		peak = max(peak, b.Count)
+	}
+
+	lines := make([]string, 0, len(d.Histogram))
+	for _, b := range d.Histogram {
+		bar := 0
+		if peak > 0 {
+			bar = int(math.Round(float64(b.Count) / float64(peak) * float64(width)))
+		}
+		if b.Count > 0 && bar == 0 {
+			bar = 1
+		}
+		lines = append(lines, fmt.Sprintf("%10s - %-10s |%s %d", formatValue(b.Min), formatValue(b.Max), strings.Repeat("#", bar), b.Count))
+	}
+	return lines
+}
+
+// round rounds to 2 decimal places, matching the economy figures
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// formatValue prints whole numbers without decimals and others with one
+func formatValue(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.1f", v)
+}
@@ -0,0 +1,101 @@
+package stats
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestPercentile(t *testing.T) {
+	values := []float64{10, 20, 30, 40, 50}
+
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0, 10},
+		{25, 20},
+		{50, 30},
+		{90, 46},
+		{100, 50},
+	}
+
+	for _, tt := range tests {
+		if got := Percentile(values, tt.p); got != tt.want {
+			t.Errorf("Percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := Percentile(nil, 50); got != 0 {
+		t.Errorf("Percentile(nil) = %v, want 0", got)
+	}
+}
+
+func TestDistributions(t *testing.T) {
+	var units []*models.Unit
+	for _, e := range []models.UnitIndexEntry{
+		testUnit("commander", 1, []string{"Commander", "Mobile"}, 12500, 50000, 300, 10),
+		testUnit("a", 1, []string{"Mobile", "Tank"}, 100, 100, 10, 10),
+		testUnit("b", 1, []string{"Mobile", "Tank"}, 200, 200, 20, 10),
+		testUnit("c", 1, []string{"Mobile", "Tank"}, 300, 300, 0, 10),
+		testUnit("d", 1, []string{"Structure"}, 1000, 400, 0, 0),
+		testUnit("e", 2, []string{"Mobile", "Tank"}, 500, 900, 50, 5),
+	} {
+		unit := e.Unit
+		units = append(units, &unit)
+	}
+
+	got := Distributions(units, 3)
+
+	var keys []string
+	for _, d := range got {
+		keys = append(keys, fmt.Sprintf("%s/%d", d.Metric, d.Tier))
+	}
+	wantKeys := []string{"dps/1", "dps/2", "health/1", "health/2", "cost/1", "cost/2", "speed/1", "speed/2"}
+	if !reflect.DeepEqual(keys, wantKeys) {
+		t.Fatalf("distributions = %v, want %v", keys, wantKeys)
+	}
+
+	// T1 cost over a, b, c, d (commander excluded): 100..400 in 3 buckets of 100
+	cost := got[4]
+	if cost.Count != 4 || cost.Min != 100 || cost.Max != 400 || cost.Mean != 250 || cost.Percentiles.P50 != 250 {
+		t.Errorf("cost T1 = %+v", cost)
+	}
+	wantBins := []Bin{
+		{Min: 100, Max: 200, Count: 1, Units: []string{"a"}},
+		{Min: 200, Max: 300, Count: 1, Units: []string{"b"}},
+		{Min: 300, Max: 400, Count: 2, Units: []string{"c", "d"}},
+	}
+	if !reflect.DeepEqual(cost.Histogram, wantBins) {
+		t.Errorf("cost T1 histogram = %+v, want %+v", cost.Histogram, wantBins)
+	}
+
+	// DPS T1 only counts the armed units
+	if dps := got[0]; dps.Count != 2 {
+		t.Errorf("dps T1 count = %d, want 2", dps.Count)
+	}
+
+	// A single value lands in the last bucket of a zero-width range
+	single := got[1]
+	if single.Histogram[2].Count != 1 || single.Histogram[0].Count != 0 {
+		t.Errorf("dps T2 histogram = %+v", single.Histogram)
+	}
+}
+
+func TestChart(t *testing.T) {
+	d := Distribution{Histogram: []Bin{
+		{Min: 0, Max: 12.5, Count: 4},
+		{Min: 12.5, Max: 25, Count: 0},
+		{Min: 25, Max: 50, Count: 1},
+	}}
+
+	want := []string{
+		"         0 - 12.5       |######## 4",
+		"      12.5 - 25         | 0",
+		"        25 - 50         |## 1",
+	}
+	if got := Chart(d, 8); !reflect.DeepEqual(got, want) {
+		t.Errorf("Chart() =\n%q\nwant\n%q", got, want)
+	}
+}
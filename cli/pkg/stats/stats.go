@@ -0,0 +1,233 @@
+// Package stats summarises an exported faction for the stats command (unit counts,
+// leaderboards, per-tier averages and missing icons) and compares it against a
+// baseline faction for the outliers command.
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// TopN is the length of each leaderboard in a Summary
+const TopN = 5
+
+// Ranked is one leaderboard entry
+type Ranked struct {
+	ID          string  `json:"id"`
+	DisplayName string  `json:"displayName"`
+	Tier        int     `json:"tier"`
+	Value       float64 `json:"value"`
+}
+
+// TierCount is the number of units in a tier
+type TierCount struct {
+	Tier  int `json:"tier"`
+	Count int `json:"count"`
+}
+
+// CategoryCommander is the category for commanders, which have no build bar tab
+const CategoryCommander = "commander"
+
+// CategoryCount is the number of units in a category: CategoryCommander or a build bar
+// tab (see exporter.BuildMenuCategory)
+type CategoryCount struct {
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+}
+
+// TierAverage holds mean stats for one tier, excluding commanders (factions ship dozens
+// of near-identical commanders that would swamp the T1 figures). DPS averages armed
+// units only and MoveSpeed mobile units only, so structures and unarmed utility units
+// don't drag them to zero.
+type TierAverage struct {
+	Tier      int     `json:"tier"`
+	Units     int     `json:"units"`
+	Health    float64 `json:"health"`
+	BuildCost float64 `json:"buildCost"`
+	DPS       float64 `json:"dps"`
+	MoveSpeed float64 `json:"moveSpeed"`
+}
+
+// Summary is the faction dashboard printed by the stats command
+type Summary struct {
+	Units         int             `json:"units"`        // Accessible units summarised
+	Inaccessible  int             `json:"inaccessible"` // Test, tutorial and template units left out
+	ByTier        []TierCount     `json:"byTier"`
+	ByCategory    []CategoryCount `json:"byCategory"` // Commanders first, then models.BuildMenuCategories order
+	TopDPS        []Ranked        `json:"topDps"`
+	Cheapest      []Ranked        `json:"cheapest"` // Units with a build cost, cheapest first
+	MostExpensive []Ranked        `json:"mostExpensive"`
+	Averages      []TierAverage   `json:"averages"`
+	MissingIcons  []string        `json:"missingIcons"` // Unit IDs whose icon is unset or absent from factionDir
+}
+
+// Health returns a unit's maximum hit points (0 without combat specs)
+func Health(u *models.Unit) float64 {
+	if u.Specs.Combat == nil {
+		return 0
+	}
+	return u.Specs.Combat.Health
+}
+
+// DPS returns a unit's total weapon DPS (0 when unarmed)
+func DPS(u *models.Unit) float64 {
+	if u.Specs.Combat == nil {
+		return 0
+	}
+	return u.Specs.Combat.DPS
+}
+
+// BuildCost returns a unit's metal cost (0 without economy specs)
+func BuildCost(u *models.Unit) float64 {
+	if u.Specs.Economy == nil {
+		return 0
+	}
+	return u.Specs.Economy.BuildCost
+}
+
+// MoveSpeed returns a unit's maximum speed (0 for structures)
+func MoveSpeed(u *models.Unit) float64 {
+	if u.Specs.Mobility == nil {
+		return 0
+	}
+	return u.Specs.Mobility.MoveSpeed
+}
+
+// IsCommander reports whether a unit is a commander
+func IsCommander(u *models.Unit) bool {
+	for _, t := range u.UnitTypes {
+		if t == "Commander" {
+			return true
+		}
+	}
+	return false
+}
+
+// Units returns the accessible, non-template units of an index. These are the units
+// players can build, which every statistic is computed over.
+func Units(index *models.FactionIndex) []*models.Unit {
+	units := make([]*models.Unit, 0, len(index.Units))
+	for i := range index.Units {
+		unit := &index.Units[i].Unit
+		if unit.Accessible && !unit.BaseTemplate {
+			units = append(units, unit)
+		}
+	}
+	return units
+}
+
+// Summarize computes the dashboard for an exported faction. Icons are checked on disk
+// relative to factionDir.
+func Summarize(index *models.FactionIndex, factionDir string) *Summary {
+	units := Units(index)
+	summary := &Summary{
+		Units:        len(units),
+		Inaccessible: len(index.Units) - len(units),
+	}
+
+	tierCounts := make(map[int]int)
+	tiers := make(map[int]*TierAverage)
+	armed := make(map[int]int)
+	mobile := make(map[int]int)
+	categories := make(map[string]int)
+	var dps, costed []Ranked
+
+	for _, unit := range units {
+		tierCounts[unit.Tier]++
+		if d := DPS(unit); d > 0 {
+			dps = append(dps, rank(unit, d))
+		}
+		if c := BuildCost(unit); c > 0 {
+			costed = append(costed, rank(unit, c))
+		}
+
+		if IsCommander(unit) {
+			categories[CategoryCommander]++
+		} else {
+			categories[exporter.BuildMenuCategory(unit.UnitTypes)]++
+
+			avg, ok := tiers[unit.Tier]
+			if !ok {
+				avg = &TierAverage{Tier: unit.Tier}
+				tiers[unit.Tier] = avg
+			}
+			avg.Units++
+			avg.Health += Health(unit)
+			avg.BuildCost += BuildCost(unit)
+			if d := DPS(unit); d > 0 {
+				avg.DPS += d
+				armed[unit.Tier]++
+			}
+			if s := MoveSpeed(unit); s > 0 {
+				avg.MoveSpeed += s
+				mobile[unit.Tier]++
+			}
+		}
+
+		if !iconExists(factionDir, unit.Image) {
+			summary.MissingIcons = append(summary.MissingIcons, unit.ID)
+		}
+	}
+
+	for tier, count := range tierCounts {
+		summary.ByTier = append(summary.ByTier, TierCount{Tier: tier, Count: count})
+	}
+	for _, avg := range tiers {
+		avg.Health /= float64(avg.Units)
+		avg.BuildCost /= float64(avg.Units)
+		if armed[avg.Tier] > 0 {
+			avg.DPS /= float64(armed[avg.Tier])
+		}
+		if mobile[avg.Tier] > 0 {
+			avg.MoveSpeed /= float64(mobile[avg.Tier])
+		}
+		summary.Averages = append(summary.Averages, *avg)
+	}
+	sort.Slice(summary.ByTier, func(i, j int) bool { return summary.ByTier[i].Tier < summary.ByTier[j].Tier })
+	sort.Slice(summary.Averages, func(i, j int) bool { return summary.Averages[i].Tier < summary.Averages[j].Tier })
+
+	for _, category := range append([]string{CategoryCommander}, models.BuildMenuCategories...) {
+		if n := categories[category]; n > 0 {
+			summary.ByCategory = append(summary.ByCategory, CategoryCount{Category: category, Count: n})
+		}
+	}
+
+	summary.TopDPS = top(dps, true)
+	summary.Cheapest = top(costed, false)
+	summary.MostExpensive = top(costed, true)
+	sort.Strings(summary.MissingIcons)
+
+	return summary
+}
+
+func rank(unit *models.Unit, value float64) Ranked {
+	return Ranked{ID: unit.ID, DisplayName: unit.DisplayName, Tier: unit.Tier, Value: value}
+}
+
+// top returns the first TopN entries by value, ties broken by ID so output is stable
+func top(entries []Ranked, descending bool) []Ranked {
+	sorted := append([]Ranked(nil), entries...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Value != sorted[j].Value {
+			return (sorted[i].Value > sorted[j].Value) == descending
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	if len(sorted) > TopN {
+		sorted = sorted[:TopN]
+	}
+	return sorted
+}
+
+// iconExists reports whether a unit image path (relative to the faction folder) is present
+func iconExists(factionDir, image string) bool {
+	if image == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(factionDir, filepath.FromSlash(image)))
+	return err == nil
+}
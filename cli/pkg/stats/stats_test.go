@@ -0,0 +1,103 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// testUnit builds an accessible unit with the given stats; zero values leave specs unset
+func testUnit(id string, tier int, types []string, health, cost, dps, speed float64) models.UnitIndexEntry {
+	unit := models.Unit{
+		ID:          id,
+		DisplayName: id,
+		Tier:        tier,
+		UnitTypes:   types,
+		Accessible:  true,
+		Image:       "assets/" + id + "_icon_buildbar.png",
+	}
+	unit.Specs.Combat = &models.CombatSpecs{Health: health, DPS: dps}
+	if cost > 0 {
+		unit.Specs.Economy = &models.EconomySpecs{BuildCost: cost}
+	}
+	if speed > 0 {
+		unit.Specs.Mobility = &models.MobilitySpecs{MoveSpeed: speed}
+	}
+	return models.UnitIndexEntry{Identifier: id, Unit: unit}
+}
+
+func TestSummarize(t *testing.T) {
+	index := &models.FactionIndex{Units: []models.UnitIndexEntry{
+		testUnit("commander", 1, []string{"Commander", "Mobile", "Land"}, 12500, 50000, 300, 10),
+		testUnit("tank", 1, []string{"Mobile", "Tank", "Land"}, 200, 150, 20, 12),
+		testUnit("fabber", 1, []string{"Mobile", "Tank", "Land", "Fabber"}, 100, 100, 0, 10),
+		testUnit("wall", 1, []string{"Structure", "Wall"}, 1000, 50, 0, 0),
+		testUnit("heavy_tank", 2, []string{"Mobile", "Tank", "Land"}, 1000, 600, 60, 8),
+		testUnit("factory", 1, []string{"Structure", "Factory"}, 3000, 600, 0, 0),
+		testUnit("titan", 3, []string{"Mobile", "Titan", "Land"}, 30000, 30000, 1000, 6),
+	}}
+	hidden := testUnit("tutorial_tank", 1, []string{"Mobile", "Tank", "Land"}, 1, 1, 1, 1)
+	hidden.Unit.Accessible = false
+	index.Units = append(index.Units, hidden)
+
+	// Every icon but the titan's is on disk
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "assets"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"commander", "tank", "fabber", "wall", "heavy_tank", "factory"} {
+		if err := os.WriteFile(filepath.Join(dir, "assets", id+"_icon_buildbar.png"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := Summarize(index, dir)
+
+	if s.Units != 7 || s.Inaccessible != 1 {
+		t.Errorf("Units = %d, Inaccessible = %d, want 7 and 1", s.Units, s.Inaccessible)
+	}
+	wantTiers := []TierCount{{Tier: 1, Count: 5}, {Tier: 2, Count: 1}, {Tier: 3, Count: 1}}
+	if !reflect.DeepEqual(s.ByTier, wantTiers) {
+		t.Errorf("ByTier = %v, want %v", s.ByTier, wantTiers)
+	}
+	wantCategories := []CategoryCount{
+		{Category: CategoryCommander, Count: 1},
+		{Category: models.BuildMenuFactory, Count: 1},
+		{Category: models.BuildMenuUtility, Count: 1},
+		{Category: models.BuildMenuVehicle, Count: 4},
+	}
+	if !reflect.DeepEqual(s.ByCategory, wantCategories) {
+		t.Errorf("ByCategory = %v, want %v", s.ByCategory, wantCategories)
+	}
+
+	if ids := rankedIDs(s.TopDPS); !reflect.DeepEqual(ids, []string{"titan", "commander", "heavy_tank", "tank"}) {
+		t.Errorf("TopDPS = %v", ids)
+	}
+	if ids := rankedIDs(s.Cheapest); !reflect.DeepEqual(ids, []string{"wall", "fabber", "tank", "factory", "heavy_tank"}) {
+		t.Errorf("Cheapest = %v", ids)
+	}
+	if ids := rankedIDs(s.MostExpensive); !reflect.DeepEqual(ids, []string{"commander", "titan", "factory", "heavy_tank", "tank"}) {
+		t.Errorf("MostExpensive = %v", ids)
+	}
+
+	// T1 averages exclude the commander: tank, fabber, wall, factory
+	t1 := s.Averages[0]
+	if t1.Tier != 1 || t1.Units != 4 || t1.Health != 1075 || t1.BuildCost != 225 || t1.DPS != 20 || t1.MoveSpeed != 11 {
+		t.Errorf("T1 averages = %+v", t1)
+	}
+
+	if !reflect.DeepEqual(s.MissingIcons, []string{"titan"}) {
+		t.Errorf("MissingIcons = %v, want [titan]", s.MissingIcons)
+	}
+}
+
+func rankedIDs(entries []Ranked) []string {
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	return ids
+}
@@ -0,0 +1,121 @@
+// Package style colours the CLI's status markers and headings and lays out tables, for
+// output read by people. Colour is only used on a terminal, so piped and logged output
+// stays plain.
+package style
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Status markers that start the CLI's result lines
+const (
+	SuccessMarker = "✓"
+	WarningMarker = "⚠"
+	FailureMarker = "✗"
+)
+
+const (
+	reset  = "\x1b[0m"
+	bold   = "\x1b[1m"
+	red    = "\x1b[31m"
+	green  = "\x1b[32m"
+	yellow = "\x1b[33m"
+)
+
+var enabled bool
+
+// Configure turns colour on when stdout is a terminal, unless noColor (--no-color) is set,
+// NO_COLOR is set to anything (https://no-color.org) or TERM is dumb
+func Configure(noColor bool) {
+	enabled = !noColor && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb" && isTerminal(os.Stdout)
+}
+
+// Enabled reports whether output is coloured
+func Enabled() bool {
+	return enabled
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func paint(code, s string) string {
+	if !enabled || s == "" {
+		return s
+	}
+	return code + s + reset
+}
+
+// Success colours s green
+func Success(s string) string { return paint(green, s) }
+
+// Warning colours s yellow
+func Warning(s string) string { return paint(yellow, s) }
+
+// Failure colours s red
+func Failure(s string) string { return paint(red, s) }
+
+// Bold makes s bold
+func Bold(s string) string { return paint(bold, s) }
+
+// Marked colours the status marker (✓, ⚠ or ✗) that starts s, after any indentation or
+// blank lines. Text without a marker is returned unchanged.
+func Marked(s string) string {
+	start := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsSpace(r) })
+	if start < 0 {
+		return s
+	}
+	for marker, colour := range map[string]func(string) string{
+		SuccessMarker: Success,
+		WarningMarker: Warning,
+		FailureMarker: Failure,
+	} {
+		if strings.HasPrefix(s[start:], marker) {
+			return s[:start] + colour(marker) + s[start+len(marker):]
+		}
+	}
+	return s
+}
+
+// Table writes rows as left-aligned columns two spaces apart, under bold headers. Rows may
+// have fewer cells than there are headers; trailing blanks are trimmed.
+func Table(w io.Writer, headers []string, rows [][]string) error {
+	widths := make([]int, len(headers))
+	for _, row := range append([][]string{headers}, rows...) {
+		for i, cell := range row {
+			if i < len(widths) {
+				widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+			}
+		}
+	}
+
+	writeRow := func(cells []string, decorate func(string) string) error {
+		var b strings.Builder
+		for i, cell := range cells {
+			if i >= len(widths) {
+				break
+			}
+			b.WriteString(decorate(cell))
+			if i < len(cells)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)+2))
+			}
+		}
+		_, err := fmt.Fprintln(w, strings.TrimRight(b.String(), " "))
+		return err
+	}
+	if err := writeRow(headers, Bold); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := writeRow(row, func(s string) string { return s }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package style
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMarked(t *testing.T) {
+	t.Cleanup(func() { enabled = false })
+
+	tests := []struct {
+		name    string
+		enabled bool
+		in      string
+		want    string
+	}{
+		{"plain output", false, "✓ Done\n", "✓ Done\n"},
+		{"success", true, "✓ Done\n", "\x1b[32m✓\x1b[0m Done\n"},
+		{"indented warning", true, "  ⚠ Careful\n", "  \x1b[33m⚠\x1b[0m Careful\n"},
+		{"after blank line", true, "\n✗ Failed\n", "\n\x1b[31m✗\x1b[0m Failed\n"},
+		{"no marker", true, "Nothing to see\n", "Nothing to see\n"},
+		{"marker not first", true, "Done ✓\n", "Done ✓\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enabled = tt.enabled
+			if got := Marked(tt.in); got != tt.want {
+				t.Errorf("Marked(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigureRespectsNoColor(t *testing.T) {
+	t.Cleanup(func() { enabled = false })
+
+	t.Setenv("NO_COLOR", "1")
+	Configure(false)
+	if Enabled() {
+		t.Error("colour enabled despite NO_COLOR")
+	}
+
+	// Test output isn't a terminal, so colour stays off without NO_COLOR too
+	t.Setenv("NO_COLOR", "")
+	Configure(false)
+	if Enabled() {
+		t.Error("colour enabled when stdout is not a terminal")
+	}
+}
+
+func TestTable(t *testing.T) {
+	var buf bytes.Buffer
+	err := Table(&buf, []string{"ID", "NAME"}, [][]string{
+		{"mla", "MLA"},
+		{"second-wave", "Second Wave"},
+		{"bugs"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "ID           NAME\n" +
+		"mla          MLA\n" +
+		"second-wave  Second Wave\n" +
+		"bugs\n"
+	if buf.String() != want {
+		t.Errorf("Table() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,252 @@
+// Package synthetic fabricates a faction's unit specs (random but plausible stats, no game
+// assets) for gen-testdata, so web app developers can test against a realistic export
+// without a PA install.
+package synthetic
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// UnitType is the faction unit type of every generated unit (UNITTYPE_Synthetic)
+const UnitType = "Synthetic"
+
+// Options configures WriteSpecs
+type Options struct {
+	Units int   // Units to generate, including the commander
+	Seed  int64 // The same seed and unit count always produce the same specs
+}
+
+// role is a kind of unit the generator can make
+type role struct {
+	noun   string   // unit_name, e.g. "Tank"
+	domain string   // Folder under /pa/units/: land, air or sea
+	types  []string // unit_types besides the faction and tier
+	armed  bool
+	layers []string // Weapon target layers
+	builds string   // buildable_types, for factories and fabricators
+	speed  float64  // Base move speed; 0 for structures
+	cost   float64  // Base metal cost at tier 1
+	health float64  // Base health at tier 1
+	metal  float64  // Metal produced at tier 1
+	energy float64  // Energy produced at tier 1
+}
+
+var roles = []role{
+	{noun: "Tank", domain: "land", types: []string{"Mobile", "Tank", "Land"}, armed: true, layers: surface, speed: 12, cost: 150, health: 250},
+	{noun: "Bot", domain: "land", types: []string{"Mobile", "Bot", "Land"}, armed: true, layers: surface, speed: 16, cost: 90, health: 120},
+	{noun: "Artillery", domain: "land", types: []string{"Mobile", "Tank", "Land", "Artillery"}, armed: true, layers: surface, speed: 9, cost: 300, health: 200},
+	{noun: "Fighter", domain: "air", types: []string{"Mobile", "Air", "Fighter"}, armed: true, layers: air, speed: 60, cost: 180, health: 150},
+	{noun: "Bomber", domain: "air", types: []string{"Mobile", "Air", "Bomber"}, armed: true, layers: surface, speed: 40, cost: 240, health: 400},
+	{noun: "Frigate", domain: "sea", types: []string{"Mobile", "Naval"}, armed: true, layers: append(surface, air...), speed: 14, cost: 400, health: 1500},
+	{noun: "Fabricator", domain: "land", types: []string{"Mobile", "Land", "Fabber", "Construction"}, builds: "Structure & " + UnitType, speed: 11, cost: 90, health: 150},
+	{noun: "Bot Factory", domain: "land", types: []string{"Structure", "Factory", "Land", "Construction"}, builds: "Mobile & Land & " + UnitType + " - Commander", cost: 600, health: 5000},
+	{noun: "Air Factory", domain: "air", types: []string{"Structure", "Factory", "Land", "Construction"}, builds: "Mobile & Air & " + UnitType, cost: 650, health: 4000},
+	{noun: "Naval Factory", domain: "sea", types: []string{"Structure", "Factory", "Naval", "Construction"}, builds: "Mobile & Naval & " + UnitType, cost: 700, health: 6000},
+	{noun: "Metal Extractor", domain: "land", types: []string{"Structure", "Land", "Economy", "MetalProduction"}, cost: 50, health: 1000, metal: 7},
+	{noun: "Energy Plant", domain: "land", types: []string{"Structure", "Land", "Economy", "EnergyProduction"}, cost: 300, health: 1200, energy: 600},
+	{noun: "Turret", domain: "land", types: []string{"Structure", "Land", "Defense"}, armed: true, layers: surface, cost: 200, health: 2000},
+	{noun: "Flak", domain: "land", types: []string{"Structure", "Land", "Defense"}, armed: true, layers: air, cost: 250, health: 1500},
+	{noun: "Radar", domain: "land", types: []string{"Structure", "Land", "Recon"}, cost: 100, health: 500},
+}
+
+var (
+	surface = []string{"WL_LandHorizontal", "WL_WaterSurface"}
+	air     = []string{"WL_Air"}
+)
+
+var commander = role{
+	noun: "Commander", domain: "land", types: []string{"Mobile", "Land", "Commander", "Construction"},
+	armed: true, layers: surface, builds: "Structure & " + UnitType, speed: 15, cost: 0, health: 12500,
+}
+
+// Name syllables; combined they give searchable, mostly unique display names
+var (
+	namePrefixes = []string{"Ash", "Bolt", "Cinder", "Dusk", "Ember", "Flint", "Gale", "Husk", "Iron", "Jade", "Kestrel", "Lumen", "Mire", "Nova", "Onyx", "Pike", "Quill", "Rust", "Slate", "Thorn", "Umber", "Vex", "Warden", "Zephyr"}
+	nameSuffixes = []string{"", "back", "fang", "horn", "jaw", "lance", "maw", "run", "shade", "spire", "strider", "wing"}
+)
+
+// WriteSpecs writes a unit list and unit, weapon, ammo and build arm specs for a synthetic
+// faction under paRoot/pa, as a PA install would lay them out. It returns the unit resource
+// paths in unit list order.
+func WriteSpecs(paRoot string, opts Options) ([]string, error) {
+	if opts.Units < 1 {
+		return nil, fmt.Errorf("need at least 1 unit, got %d", opts.Units)
+	}
+	g := &generator{
+		root:  paRoot,
+		rng:   rand.New(rand.NewPCG(uint64(opts.Seed), 0)),
+		names: make(map[string]bool),
+	}
+
+	paths := make([]string, 0, opts.Units)
+	path, err := g.writeUnit("commander", commander, 1)
+	if err != nil {
+		return nil, err
+	}
+	paths = append(paths, path)
+	for i := 1; i < opts.Units; i++ {
+		// Cycle through the roles so small rosters still have one of each kind
+		r := roles[(i-1)%len(roles)]
+		tier := 1
+		if (i-1)/len(roles)%2 == 1 {
+			tier = 2
+		}
+		id := fmt.Sprintf("%s_%d", strings.ReplaceAll(strings.ToLower(r.noun), " ", "_"), i)
+		path, err := g.writeUnit(id, r, tier)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+
+	if err := g.writeJSON("/pa/units/unit_list.json", map[string]any{"units": paths}); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+type generator struct {
+	root  string
+	rng   *rand.Rand
+	names map[string]bool
+}
+
+// vary scales a base value by a random factor in [0.75, 1.25], rounded to 2 significant digits
+func (g *generator) vary(base float64) float64 {
+	v := base * (0.75 + g.rng.Float64()/2)
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'g', 2, 64), 64)
+	return rounded
+}
+
+// name returns an unused display name, numbering names once the syllables run short
+func (g *generator) name() string {
+	var name string
+	for try := 0; try < 100; try++ {
+		name = namePrefixes[g.rng.IntN(len(namePrefixes))] + nameSuffixes[g.rng.IntN(len(nameSuffixes))]
+		if !g.names[name] {
+			break
+		}
+	}
+	if g.names[name] {
+		name = fmt.Sprintf("%s %d", name, len(g.names)+1)
+	}
+	g.names[name] = true
+	return name
+}
+
+func (g *generator) writeUnit(id string, r role, tier int) (string, error) {
+	dir := fmt.Sprintf("/pa/units/%s/%s/", r.domain, id)
+	tierType, scale := "Basic", 1.0
+	if tier == 2 {
+		tierType, scale = "Advanced", 3.0
+	}
+
+	types := []string{"UNITTYPE_" + UnitType, "UNITTYPE_" + tierType}
+	for _, t := range r.types {
+		types = append(types, "UNITTYPE_"+t)
+	}
+	name := g.name()
+	unit := map[string]any{
+		"display_name":     name,
+		"unit_name":        r.noun,
+		"description":      fmt.Sprintf("Synthetic %s for testing.", strings.ToLower(r.noun)),
+		"max_health":       g.vary(r.health * scale),
+		"build_metal_cost": g.vary(r.cost * scale),
+		"unit_types":       types,
+		"recon": map[string]any{"observer": map[string]any{"items": []any{
+			map[string]any{"channel": "sight", "layer": "surface_and_air", "radius": g.vary(100)},
+		}}},
+	}
+	if r.speed > 0 {
+		unit["navigation"] = map[string]any{
+			"move_speed":   g.vary(r.speed),
+			"turn_speed":   g.vary(120),
+			"acceleration": g.vary(r.speed * 3),
+			"brake":        g.vary(r.speed * 3),
+		}
+	}
+	if r.builds != "" {
+		unit["buildable_types"] = r.builds
+	}
+	if r.metal > 0 || r.energy > 0 {
+		unit["production"] = map[string]any{"metal": g.vary(r.metal * scale), "energy": g.vary(r.energy * scale)}
+	}
+
+	var tools []any
+	if r.armed {
+		weapon, err := g.writeWeapon(dir, id, r, scale)
+		if err != nil {
+			return "", err
+		}
+		tools = append(tools, map[string]any{"spec_id": weapon})
+	}
+	if r.builds != "" {
+		arm := dir + id + "_build_arm.json"
+		rate := g.vary(10 * scale)
+		err := g.writeJSON(arm, map[string]any{
+			"tool_type":           "TOOL_BuildArm",
+			"construction_demand": map[string]any{"metal": rate, "energy": rate * 15},
+			"max_range":           g.vary(30),
+		})
+		if err != nil {
+			return "", err
+		}
+		tools = append(tools, map[string]any{"spec_id": arm})
+	}
+	if tools != nil {
+		unit["tools"] = tools
+	}
+
+	path := dir + id + ".json"
+	return path, g.writeJSON(path, unit)
+}
+
+func (g *generator) writeWeapon(dir, id string, r role, scale float64) (string, error) {
+	ammo := dir + id + "_ammo.json"
+	damage := g.vary(40 * scale)
+	ammoSpec := map[string]any{
+		"ammo_type":        "AMMO_Projectile",
+		"damage":           damage,
+		"initial_velocity": g.vary(150),
+		"lifetime":         2,
+	}
+	if g.rng.IntN(3) == 0 {
+		ammoSpec["splash_damage"] = damage / 2
+		ammoSpec["splash_radius"] = g.vary(8)
+	}
+	if err := g.writeJSON(ammo, ammoSpec); err != nil {
+		return "", err
+	}
+
+	weapon := dir + id + "_tool_weapon.json"
+	rangeBase := 100.0
+	if r.noun == "Artillery" {
+		rangeBase = 250
+	}
+	return weapon, g.writeJSON(weapon, map[string]any{
+		"tool_type":     "TOOL_Weapon",
+		"rate_of_fire":  g.vary(1),
+		"max_range":     g.vary(rangeBase),
+		"ammo_id":       ammo,
+		"target_layers": r.layers,
+	})
+}
+
+// writeJSON writes a spec at its resource path under the root
+func (g *generator) writeJSON(resourcePath string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(g.root, filepath.FromSlash(strings.TrimPrefix(resourcePath, "/")))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,89 @@
+package synthetic
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/loader"
+	"github.com/jamiemulcahy/pa-pedia/pkg/parser"
+)
+
+// TestWriteSpecsParses verifies every generated unit parses, belongs to the faction and is
+// reachable from the commander
+func TestWriteSpecsParses(t *testing.T) {
+	root := t.TempDir()
+	paths, err := WriteSpecs(root, Options{Units: 40, Seed: 42})
+	if err != nil {
+		t.Fatalf("WriteSpecs failed: %v", err)
+	}
+	if len(paths) != 40 {
+		t.Fatalf("expected 40 unit paths, got %d", len(paths))
+	}
+
+	l, err := loader.NewMultiSourceLoader(root, "", nil)
+	if err != nil {
+		t.Fatalf("failed to create loader: %v", err)
+	}
+	defer l.Close()
+
+	db := parser.NewDatabase(l)
+	if err := db.LoadUnits(false, UnitType, false); err != nil {
+		t.Fatalf("LoadUnits failed: %v", err)
+	}
+	units := db.Snapshot().Units()
+	if len(units) != 40 {
+		t.Fatalf("expected 40 units, got %d", len(units))
+	}
+	names := make(map[string]bool)
+	for _, u := range units {
+		if !u.Accessible {
+			t.Errorf("%s is not buildable from the commander", u.ID)
+		}
+		if len(u.Warnings) > 0 {
+			t.Errorf("%s has warnings: %v", u.ID, u.Warnings)
+		}
+		if names[u.DisplayName] {
+			t.Errorf("display name %q used twice", u.DisplayName)
+		}
+		names[u.DisplayName] = true
+	}
+}
+
+// TestWriteSpecsDeterministic verifies a seed always produces the same files and another
+// seed different ones
+func TestWriteSpecsDeterministic(t *testing.T) {
+	read := func(seed int64) map[string]string {
+		root := t.TempDir()
+		if _, err := WriteSpecs(root, Options{Units: 20, Seed: seed}); err != nil {
+			t.Fatalf("WriteSpecs failed: %v", err)
+		}
+		files := make(map[string]string)
+		filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				data, _ := os.ReadFile(path)
+				rel, _ := filepath.Rel(root, path)
+				files[rel] = string(data)
+			}
+			return nil
+		})
+		return files
+	}
+
+	a, b, c := read(7), read(7), read(8)
+	if len(a) == 0 || len(a) != len(b) {
+		t.Fatalf("expected the same files for one seed, got %d and %d", len(a), len(b))
+	}
+	same := true
+	for path, data := range a {
+		if b[path] != data {
+			t.Errorf("%s differs between runs with the same seed", path)
+		}
+		if c[path] != data {
+			same = false
+		}
+	}
+	if same {
+		t.Error("different seeds produced identical specs")
+	}
+}
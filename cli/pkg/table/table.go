@@ -0,0 +1,164 @@
+// Package table flattens JSON-shaped values (units, weapons, ...) into spreadsheet rows
+// with dotted column names, for CSV output.
+package table
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ListSeparator joins arrays of scalars (e.g. unitTypes) within one cell
+const ListSeparator = ";"
+
+// Table is a header and rows of equal length
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// Flatten turns each item into a row. Items are marshalled to JSON; nested objects become
+// dotted columns (specs.combat.health), arrays of scalars are joined with ListSeparator,
+// and arrays of objects get an index per element (specs.combat.weapons.0.dps). Columns
+// are the union over all items in field order, a column first seen on a later item being
+// placed after its neighbour in that item; cells an item lacks are empty.
+func Flatten[T any](items []T) (*Table, error) {
+	t := &Table{}
+	position := make(map[string]int)
+	var rows []map[string]string
+
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]string)
+		var keys []string
+		if err := flatten(data, "", row, &keys); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+
+		previous := ""
+		for _, key := range keys {
+			if _, ok := position[key]; !ok {
+				at := len(t.Columns)
+				if previous != "" {
+					at = position[previous] + 1
+				}
+				t.Columns = append(t.Columns, "")
+				copy(t.Columns[at+1:], t.Columns[at:])
+				t.Columns[at] = key
+				for i := at; i < len(t.Columns); i++ {
+					position[t.Columns[i]] = i
+				}
+			}
+			previous = key
+		}
+	}
+
+	for _, row := range rows {
+		cells := make([]string, len(t.Columns))
+		for i, column := range t.Columns {
+			cells[i] = row[column]
+		}
+		t.Rows = append(t.Rows, cells)
+	}
+	return t, nil
+}
+
+// flatten adds the cells of one JSON value under prefix to row, recording new keys in
+// the order they appear
+func flatten(data json.RawMessage, prefix string, row map[string]string, keys *[]string) error {
+	set := func(value string) {
+		if _, ok := row[prefix]; !ok {
+			*keys = append(*keys, prefix)
+		}
+		row[prefix] = value
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil
+	}
+	switch data[0] {
+	case '{':
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.Token() // {
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return err
+			}
+			if err := flatten(value, join(prefix, tok.(string)), row, keys); err != nil {
+				return err
+			}
+		}
+		return nil
+	case '[':
+		var elems []json.RawMessage
+		if err := json.Unmarshal(data, &elems); err != nil {
+			return err
+		}
+		scalars := make([]string, 0, len(elems))
+		for _, e := range elems {
+			if e = bytes.TrimSpace(e); len(e) > 0 && (e[0] == '{' || e[0] == '[') {
+				scalars = nil
+				break
+			}
+			scalars = append(scalars, scalar(e))
+		}
+		if scalars != nil {
+			set(strings.Join(scalars, ListSeparator))
+			return nil
+		}
+		for i, e := range elems {
+			if err := flatten(e, join(prefix, fmt.Sprint(i)), row, keys); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		set(scalar(data))
+		return nil
+	}
+}
+
+// scalar renders a JSON string, number, bool or null as cell text
+func scalar(data json.RawMessage) string {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		json.Unmarshal(data, &s)
+		return s
+	}
+	if string(data) == "null" {
+		return ""
+	}
+	return string(data)
+}
+
+func join(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// WriteCSV writes the table with a header row
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Columns); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(t.Rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
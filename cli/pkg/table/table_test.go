@@ -0,0 +1,57 @@
+package table
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type weapon struct {
+	Name string  `json:"name"`
+	DPS  float64 `json:"dps"`
+}
+
+type unit struct {
+	ID      string   `json:"id"`
+	Types   []string `json:"types"`
+	Weapons []weapon `json:"weapons,omitempty"`
+	Speed   *float64 `json:"speed,omitempty"`
+}
+
+func TestFlatten(t *testing.T) {
+	speed := 10.5
+	units := []unit{
+		{ID: "tank", Types: []string{"Mobile", "Land"}, Weapons: []weapon{{"cannon", 20}}},
+		{ID: "bomber", Types: nil, Weapons: []weapon{{"bomb", 50}, {"gun", 5}}, Speed: &speed},
+		{ID: "wall, big", Types: []string{}},
+	}
+
+	got, err := Flatten(units)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Table{
+		// weapons.1.* first appear on the bomber and are placed after weapons.0.*
+		Columns: []string{"id", "types", "weapons.0.name", "weapons.0.dps", "weapons.1.name", "weapons.1.dps", "speed"},
+		Rows: [][]string{
+			{"tank", "Mobile;Land", "cannon", "20", "", "", ""},
+			{"bomber", "", "bomb", "50", "gun", "5", "10.5"},
+			{"wall, big", "", "", "", "", "", ""},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Flatten() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	var buf bytes.Buffer
+	if err := got.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	wantCSV := "id,types,weapons.0.name,weapons.0.dps,weapons.1.name,weapons.1.dps,speed\n" +
+		"tank,Mobile;Land,cannon,20,,,\n" +
+		"bomber,,bomb,50,gun,5,10.5\n" +
+		"\"wall, big\",,,,,,\n"
+	if buf.String() != wantCSV {
+		t.Errorf("WriteCSV() =\n%s\nwant\n%s", buf.String(), wantCSV)
+	}
+}
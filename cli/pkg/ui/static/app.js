@@ -0,0 +1,155 @@
+// Frontend of pa-pedia ui: profile selection and extraction via /api, browsing via the
+// faction server's /factions routes.
+'use strict';
+
+const $ = (id) => document.getElementById(id);
+
+let profiles = [];
+let units = [];
+
+async function getJSON(url) {
+  const resp = await fetch(url);
+  if (!resp.ok) {
+    throw new Error(`${url}: ${resp.status} ${await resp.text()}`);
+  }
+  return resp.json();
+}
+
+async function loadConfig() {
+  const config = await getJSON('/api/config');
+  profiles = config.profiles;
+  const select = $('profile');
+  for (const p of profiles) {
+    const option = document.createElement('option');
+    option.value = p.id;
+    option.textContent = p.isAddon ? `${p.displayName} (addon)` : p.displayName;
+    select.append(option);
+  }
+  select.addEventListener('change', showDescription);
+  showDescription();
+  $('pa-root').value = localStorage.getItem('paRoot') || config.paRoot;
+  $('data-root').value = localStorage.getItem('dataRoot') || config.dataRoot;
+}
+
+function showDescription() {
+  const profile = profiles.find((p) => p.id === $('profile').value);
+  $('profile-description').textContent = profile ? profile.description || '' : '';
+}
+
+async function startExtraction(event) {
+  event.preventDefault();
+  const request = {
+    profile: $('profile').value,
+    paRoot: $('pa-root').value,
+    dataRoot: $('data-root').value,
+  };
+  localStorage.setItem('paRoot', request.paRoot);
+  localStorage.setItem('dataRoot', request.dataRoot);
+
+  const resp = await fetch('/api/extract', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(request),
+  });
+  if (!resp.ok) {
+    showJob({ running: false, output: [], error: await resp.text() });
+    return;
+  }
+  pollJob();
+}
+
+async function pollJob() {
+  const job = await getJSON('/api/extract');
+  showJob(job);
+  if (job.running) {
+    setTimeout(pollJob, 500);
+  } else if (job.finished && !job.error) {
+    loadFactions();
+  }
+}
+
+function showJob(job) {
+  if (!job.profile && !job.error) {
+    return; // Nothing extracted since the server started
+  }
+  $('job').hidden = false;
+  $('extract-button').disabled = job.running;
+  const status = $('job-status');
+  status.className = '';
+  if (job.running) {
+    status.textContent = `Extracting ${job.profile}…`;
+  } else if (job.error) {
+    status.textContent = `Extraction failed: ${job.error}`;
+    status.className = 'error';
+  } else {
+    status.textContent = `Extracted ${job.profile}`;
+    status.className = 'success';
+  }
+  const output = $('job-output');
+  output.textContent = job.output.join('\n');
+  output.scrollTop = output.scrollHeight;
+}
+
+async function loadFactions() {
+  const factions = await getJSON('/factions');
+  const list = $('faction-list');
+  list.replaceChildren();
+  $('no-factions').hidden = factions.length > 0;
+  for (const f of factions) {
+    const button = document.createElement('button');
+    button.textContent = `${f.displayName} ${f.latest}`;
+    button.addEventListener('click', () => {
+      for (const b of list.children) {
+        b.classList.toggle('selected', b === button);
+      }
+      loadUnits(f);
+    });
+    list.append(button);
+  }
+}
+
+async function loadUnits(faction) {
+  units = await getJSON(`/factions/${encodeURIComponent(faction.versions[0].ref)}/units`);
+  units.sort((a, b) => a.tier - b.tier || a.displayName.localeCompare(b.displayName));
+  $('units').hidden = false;
+  $('units-title').textContent = `${faction.displayName} (${units.length} units)`;
+  $('unit-filter').value = '';
+  showUnits();
+}
+
+function showUnits() {
+  const filter = $('unit-filter').value.trim().toLowerCase();
+  const rows = $('unit-rows');
+  rows.replaceChildren();
+  for (const u of units) {
+    const text = [u.displayName, u.id, ...(u.unitTypes || [])].join(' ').toLowerCase();
+    if (filter && !text.includes(filter)) {
+      continue;
+    }
+    const specs = u.specs || {};
+    const row = document.createElement('tr');
+    for (const [value, numeric] of [
+      [u.displayName, false],
+      [u.id, false],
+      [u.tier, true],
+      [specs.economy ? Math.round(specs.economy.buildCost) : '', true],
+      [specs.combat ? Math.round(specs.combat.health) : '', true],
+      [specs.combat && specs.combat.dps ? specs.combat.dps.toFixed(1) : '', true],
+    ]) {
+      const cell = document.createElement('td');
+      cell.textContent = value;
+      if (numeric) {
+        cell.className = 'number';
+      }
+      row.append(cell);
+    }
+    rows.append(row);
+  }
+}
+
+$('extract-form').addEventListener('submit', startExtraction);
+$('unit-filter').addEventListener('input', showUnits);
+
+loadConfig().catch((err) => showJob({ running: false, output: [], error: err.message }));
+loadFactions();
+pollJob();
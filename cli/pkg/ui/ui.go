@@ -0,0 +1,293 @@
+// Package ui implements the ui command: the serve-mode faction server plus a small web
+// frontend, embedded in the binary, for picking a profile, running an extraction and
+// browsing the result without learning describe-faction's flags.
+package ui
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/serve"
+)
+
+//go:embed static
+var static embed.FS
+
+// maxOutputLines bounds the extraction output kept for the frontend; older lines are dropped
+const maxOutputLines = 2000
+
+// Profile is one faction profile the frontend offers
+type Profile struct {
+	ID          string   `json:"id"`
+	DisplayName string   `json:"displayName"`
+	Description string   `json:"description,omitempty"`
+	IsAddon     bool     `json:"isAddon,omitempty"`
+	Mods        []string `json:"mods,omitempty"`
+}
+
+// Request is an extraction the frontend asked for
+type Request struct {
+	Profile  string `json:"profile"`
+	PARoot   string `json:"paRoot"`
+	DataRoot string `json:"dataRoot,omitempty"`
+}
+
+// ExtractFunc runs one extraction, writing its progress output to w. Output ends at the
+// first error the extraction reports.
+type ExtractFunc func(ctx context.Context, req Request, w io.Writer) error
+
+// Options configures a UI
+type Options struct {
+	Profiles []Profile
+	// PARoot and DataRoot prefill the frontend's form
+	PARoot   string
+	DataRoot string
+	Extract  ExtractFunc
+}
+
+// Job is the latest extraction, as GET /api/extract reports it
+type Job struct {
+	Profile  string     `json:"profile,omitempty"` // Empty before the first extraction
+	Running  bool       `json:"running"`
+	Output   []string   `json:"output"` // Lines printed so far, the last maxOutputLines
+	Error    string     `json:"error,omitempty"`
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+}
+
+// UI serves the frontend, its API and the faction server's /factions routes. It runs one
+// extraction at a time.
+type UI struct {
+	server *serve.Server
+	opts   Options
+	ctx    context.Context // Cancels running extractions; set by ListenAndServe
+	addr   string          // The address ListenAndServe was given, whose host is also trusted
+
+	mu      sync.Mutex
+	job     Job
+	partial string // Output after the last line break
+}
+
+// New creates a UI that browses server's factions directory, which extractions must write to
+func New(server *serve.Server, opts Options) *UI {
+	return &UI{server: server, opts: opts, ctx: context.Background(), job: Job{Output: []string{}}}
+}
+
+// Handler returns the HTTP routes:
+//
+//	/                 the frontend
+//	/api/config       JSON profiles and the prefilled PA root and data root
+//	/api/extract      GET the latest Job; POST a Request to start an extraction
+//	/factions/...     the faction server's routes (see serve.Server.Handler)
+func (u *UI) Handler() http.Handler {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err) // static is embedded, so this can't fail at runtime
+	}
+	factions := u.server.Handler()
+
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServerFS(files))
+	mux.HandleFunc("GET /api/config", u.handleConfig)
+	mux.HandleFunc("GET /api/extract", u.handleJob)
+	mux.HandleFunc("POST /api/extract", u.handleExtract)
+	mux.Handle("/factions", factions)
+	mux.Handle("/factions/", factions)
+	return mux
+}
+
+// ListenAndServe serves on addr until ctx is cancelled, which also cancels a running
+// extraction
+func (u *UI) ListenAndServe(ctx context.Context, addr string) error {
+	u.ctx = ctx
+	u.addr = addr
+	srv := &http.Server{Addr: addr, Handler: u.Handler()}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, done := context.WithTimeout(context.Background(), 5*time.Second)
+		defer done()
+		srv.Shutdown(shutdownCtx)
+	}()
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (u *UI) handleConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, struct {
+		Profiles []Profile `json:"profiles"`
+		PARoot   string    `json:"paRoot"`
+		DataRoot string    `json:"dataRoot"`
+	}{u.opts.Profiles, u.opts.PARoot, u.opts.DataRoot})
+}
+
+func (u *UI) handleJob(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, u.snapshot())
+}
+
+func (u *UI) handleExtract(w http.ResponseWriter, r *http.Request) {
+	// Extraction runs commands on this machine, so only the page itself may start one: a
+	// JSON body can't be sent cross-origin without a preflight, and the Origin check
+	// catches pages that reach the port some other way. A DNS-rebound page is same-origin
+	// with its own host name, so the Host must also be one this server answers to.
+	if err := checkSameOrigin(r, u.addr); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	var req Request
+	if err := json.NewDecoder(io.LimitReader(r.Body, 64<<10)).Decode(&req); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.PARoot = strings.TrimSpace(req.PARoot)
+	req.DataRoot = strings.TrimSpace(req.DataRoot)
+	if !slices.ContainsFunc(u.opts.Profiles, func(p Profile) bool { return p.ID == req.Profile }) {
+		http.Error(w, "unknown profile: "+req.Profile, http.StatusBadRequest)
+		return
+	}
+	if req.PARoot == "" {
+		http.Error(w, "the PA media directory is required", http.StatusBadRequest)
+		return
+	}
+	if err := u.start(req); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, u.snapshot())
+}
+
+// checkSameOrigin requires a JSON request sent to a loopback host, localhost or addr's
+// host, whose Origin header names the host it was sent to
+func checkSameOrigin(r *http.Request, addr string) error {
+	if !trustedHost(r.Host, addr) {
+		return errors.New("untrusted Host " + r.Host)
+	}
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return errors.New("Content-Type must be application/json")
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return errors.New("missing Origin header")
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" || !strings.EqualFold(u.Host, r.Host) {
+		return errors.New("cross-origin request from " + origin)
+	}
+	return nil
+}
+
+// trustedHost reports whether host (a Host header, port optional) is a loopback address,
+// localhost or the host part of addr
+func trustedHost(host, addr string) bool {
+	name := hostname(host)
+	if name == "" {
+		return false
+	}
+	if strings.EqualFold(name, "localhost") {
+		return true
+	}
+	if ip := net.ParseIP(name); ip != nil && ip.IsLoopback() {
+		return true
+	}
+	configured := hostname(addr)
+	return configured != "" && strings.EqualFold(name, configured)
+}
+
+// hostname strips the port and IPv6 brackets from a host[:port]
+func hostname(hostport string) string {
+	if host, _, err := net.SplitHostPort(hostport); err == nil {
+		return host
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(hostport, "["), "]")
+}
+
+// start runs req in the background unless an extraction is already running
+func (u *UI) start(req Request) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.job.Running {
+		return errors.New("an extraction of " + u.job.Profile + " is already running")
+	}
+	started := time.Now()
+	u.job = Job{Profile: req.Profile, Running: true, Output: []string{}, Started: &started}
+	u.partial = ""
+
+	go func() {
+		err := u.opts.Extract(u.ctx, req, jobWriter{u})
+		if err == nil {
+			u.server.Rescan() // Pick up the new or re-exported faction folder
+		}
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		if u.partial != "" {
+			u.appendLine(u.partial)
+			u.partial = ""
+		}
+		if err != nil {
+			u.job.Error = err.Error()
+		}
+		finished := time.Now()
+		u.job.Running = false
+		u.job.Finished = &finished
+	}()
+	return nil
+}
+
+// snapshot copies the job so it can be encoded without holding the lock
+func (u *UI) snapshot() Job {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	job := u.job
+	job.Output = slices.Clone(u.job.Output)
+	return job
+}
+
+func (u *UI) appendLine(line string) {
+	u.job.Output = append(u.job.Output, line)
+	if excess := len(u.job.Output) - maxOutputLines; excess > 0 {
+		u.job.Output = slices.Delete(u.job.Output, 0, excess)
+	}
+}
+
+// jobWriter splits extraction output into the job's lines. A carriage return (a progress
+// line redrawing itself) ends a line too.
+type jobWriter struct{ u *UI }
+
+func (w jobWriter) Write(p []byte) (int, error) {
+	w.u.mu.Lock()
+	defer w.u.mu.Unlock()
+	text := w.u.partial + strings.ReplaceAll(string(p), "\r\n", "\n")
+	for {
+		i := strings.IndexAny(text, "\r\n")
+		if i < 0 {
+			break
+		}
+		if line := text[:i]; line != "" || text[i] == '\n' {
+			w.u.appendLine(line)
+		}
+		text = text[i+1:]
+	}
+	w.u.partial = text
+	return len(p), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
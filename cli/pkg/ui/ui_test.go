@@ -0,0 +1,275 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/serve"
+)
+
+// fakeExtract writes a faction folder for the profile into root, or fails for "broken"
+func fakeExtract(root string, release chan struct{}) ExtractFunc {
+	return func(ctx context.Context, req Request, w io.Writer) error {
+		fmt.Fprintf(w, "Extracting %s from %s\n", req.Profile, req.PARoot)
+		fmt.Fprint(w, "Loading units 50%\rLoading units 100%\r\n")
+		if release != nil {
+			<-release
+		}
+		if req.Profile == "broken" {
+			fmt.Fprint(w, "Error: no units found")
+			return errors.New("describe-faction exited with status 1")
+		}
+		dir := filepath.Join(root, "MLA")
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "metadata.json"), []byte(`{"identifier":"mla","displayName":"MLA","version":"1.0.0"}`), 0644)
+		os.WriteFile(filepath.Join(dir, "units.json"), []byte(`{"units":[]}`), 0644)
+		return nil
+	}
+}
+
+func newTestUI(t *testing.T, release chan struct{}) (*httptest.Server, string) {
+	t.Helper()
+	root := t.TempDir()
+	u := New(serve.New(root, serve.Options{}), Options{
+		Profiles: []Profile{{ID: "mla", DisplayName: "MLA"}, {ID: "broken", DisplayName: "Broken"}},
+		PARoot:   "/pa/media",
+		Extract:  fakeExtract(root, release),
+	})
+	server := httptest.NewServer(u.Handler())
+	t.Cleanup(server.Close)
+	return server, root
+}
+
+func getJSON(t *testing.T, url string, v any) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s = %s", url, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func postExtract(t *testing.T, url, body string) (int, string) {
+	t.Helper()
+	return postExtractFrom(t, url, url, "application/json", body)
+}
+
+// postExtractFrom posts like a page at origin would
+func postExtractFrom(t *testing.T, url, origin, contentType, body string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url+"/api/extract", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data)
+}
+
+// waitForJob polls /api/extract until the job has finished
+func waitForJob(t *testing.T, url string) Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var job Job
+		getJSON(t, url+"/api/extract", &job)
+		if !job.Running {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("extraction still running: %+v", job)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFrontendAndConfig(t *testing.T) {
+	server, _ := newTestUI(t, nil)
+
+	resp, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "<title>PA-Pedia</title>") {
+		t.Errorf("GET / = %s %.80s", resp.Status, body)
+	}
+
+	var config struct {
+		Profiles []Profile
+		PARoot   string
+	}
+	getJSON(t, server.URL+"/api/config", &config)
+	if len(config.Profiles) != 2 || config.Profiles[0].ID != "mla" || config.PARoot != "/pa/media" {
+		t.Errorf("GET /api/config = %+v", config)
+	}
+
+	var job Job
+	getJSON(t, server.URL+"/api/extract", &job)
+	if job.Profile != "" || job.Running || job.Output == nil {
+		t.Errorf("job before any extraction = %+v, want empty with output []", job)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	server, _ := newTestUI(t, nil)
+
+	var factions []serve.FactionInfo
+	getJSON(t, server.URL+"/factions", &factions)
+	if len(factions) != 0 {
+		t.Fatalf("factions before extracting = %+v", factions)
+	}
+
+	if status, body := postExtract(t, server.URL, `{"profile":"mla","paRoot":" /pa/media "}`); status != http.StatusAccepted {
+		t.Fatalf("POST /api/extract = %d %s", status, body)
+	}
+	job := waitForJob(t, server.URL)
+	want := []string{"Extracting mla from /pa/media", "Loading units 50%", "Loading units 100%"}
+	if job.Profile != "mla" || job.Error != "" || job.Finished == nil || !reflect.DeepEqual(job.Output, want) {
+		t.Errorf("finished job = %+v, want output %q", job, want)
+	}
+
+	// The new faction folder is served without restarting
+	getJSON(t, server.URL+"/factions", &factions)
+	if len(factions) != 1 || factions[0].DisplayName != "MLA" {
+		t.Errorf("factions after extracting = %+v", factions)
+	}
+}
+
+func TestExtractFailure(t *testing.T) {
+	server, _ := newTestUI(t, nil)
+
+	postExtract(t, server.URL, `{"profile":"broken","paRoot":"/pa/media"}`)
+	job := waitForJob(t, server.URL)
+	if job.Error != "describe-faction exited with status 1" {
+		t.Errorf("job.Error = %q", job.Error)
+	}
+	// Output without a final line break is still reported
+	if last := job.Output[len(job.Output)-1]; last != "Error: no units found" {
+		t.Errorf("last output line = %q", last)
+	}
+}
+
+func TestExtractRejects(t *testing.T) {
+	release := make(chan struct{})
+	server, _ := newTestUI(t, release)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"invalid JSON", `{`, http.StatusBadRequest},
+		{"unknown profile", `{"profile":"nope","paRoot":"/pa/media"}`, http.StatusBadRequest},
+		{"no PA root", `{"profile":"mla","paRoot":"  "}`, http.StatusBadRequest},
+		{"first", `{"profile":"mla","paRoot":"/pa/media"}`, http.StatusAccepted},
+		{"while running", `{"profile":"mla","paRoot":"/pa/media"}`, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		if status, body := postExtract(t, server.URL, tt.body); status != tt.status {
+			t.Errorf("%s: POST /api/extract = %d %s, want %d", tt.name, status, body, tt.status)
+		}
+	}
+	close(release)
+	waitForJob(t, server.URL)
+}
+
+func TestExtractRequiresSameOrigin(t *testing.T) {
+	server, _ := newTestUI(t, nil)
+	body := `{"profile":"mla","paRoot":"/pa/media"}`
+
+	tests := []struct {
+		name        string
+		origin      string
+		contentType string
+	}{
+		{"form post", server.URL, "text/plain"},
+		{"no Origin", "", "application/json"},
+		{"other origin", "http://evil.example.com", "application/json"},
+		{"same host, other port", "http://127.0.0.1:1", "application/json"},
+	}
+	for _, tt := range tests {
+		if status, resp := postExtractFrom(t, server.URL, tt.origin, tt.contentType, body); status != http.StatusForbidden {
+			t.Errorf("%s: POST /api/extract = %d %s, want 403", tt.name, status, resp)
+		}
+	}
+	if status, resp := postExtractFrom(t, server.URL, server.URL, "application/json; charset=utf-8", body); status != http.StatusAccepted {
+		t.Errorf("same-origin POST /api/extract = %d %s, want 202", status, resp)
+	}
+	waitForJob(t, server.URL)
+}
+
+// TestExtractRejectsRebinding checks that a page whose host name was rebound to the
+// loopback address can't start extractions, although its Origin matches its Host
+func TestExtractRejectsRebinding(t *testing.T) {
+	u := New(serve.New(t.TempDir(), serve.Options{}), Options{
+		Profiles: []Profile{{ID: "mla", DisplayName: "MLA"}},
+		Extract:  func(ctx context.Context, req Request, w io.Writer) error { return nil },
+	})
+	u.addr = "pa-pedia.lan:8080"
+	handler := u.Handler()
+	body := `{"profile":"mla","paRoot":"/pa/media"}`
+
+	tests := []struct {
+		host   string
+		status int
+	}{
+		{"evil.example.com:8080", http.StatusForbidden},
+		{"localhost.evil.example.com:8080", http.StatusForbidden},
+		{"127.0.0.1.evil.example.com", http.StatusForbidden},
+		{"localhost:8080", http.StatusAccepted},
+		{"127.0.0.1:8080", http.StatusAccepted},
+		{"[::1]:8080", http.StatusAccepted},
+		{"PA-Pedia.lan:8080", http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/extract", strings.NewReader(body))
+		req.Host = tt.host
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Origin", "http://"+tt.host)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.status {
+			t.Errorf("Host %s: POST /api/extract = %d %s, want %d", tt.host, rec.Code, rec.Body.String(), tt.status)
+		}
+		for u.snapshot().Running {
+			time.Sleep(time.Millisecond) // Let the accepted extraction finish before the next case
+		}
+	}
+}
+
+func TestJobWriterKeepsLastLines(t *testing.T) {
+	u := New(serve.New(t.TempDir(), serve.Options{}), Options{})
+	for i := range maxOutputLines + 5 {
+		fmt.Fprintf(jobWriter{u}, "line %d\n", i)
+	}
+	if got := u.snapshot().Output; len(got) != maxOutputLines || got[0] != "line 5" {
+		t.Errorf("kept %d lines starting %q, want %d starting \"line 5\"", len(got), got[0], maxOutputLines)
+	}
+}
@@ -0,0 +1,70 @@
+package upload
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// GCSUploader uploads to Google Cloud Storage through its XML API with an OAuth access token.
+type GCSUploader struct {
+	Bucket   string
+	Token    string
+	Endpoint string // Defaults to https://storage.googleapis.com
+
+	client *http.Client
+}
+
+// NewGCSUploader creates a GCS uploader. The access token comes from
+// GOOGLE_OAUTH_ACCESS_TOKEN, falling back to `gcloud auth print-access-token`
+// (the active gcloud account or application default login).
+func NewGCSUploader(bucket string) (*GCSUploader, error) {
+	token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")
+	if token == "" {
+		out, err := exec.Command("gcloud", "auth", "print-access-token").Output()
+		if err != nil {
+			return nil, fmt.Errorf("Google Cloud credentials not found\n\nSet GOOGLE_OAUTH_ACCESS_TOKEN or log in with 'gcloud auth login'\ngcloud error: %v", err)
+		}
+		token = strings.TrimSpace(string(out))
+	}
+
+	return &GCSUploader{
+		Bucket:   bucket,
+		Token:    token,
+		Endpoint: "https://storage.googleapis.com",
+		client:   &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+// PutObject uploads data to key.
+func (g *GCSUploader) PutObject(key string, data []byte, contentType, cacheControl string) error {
+	objectURL := fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(g.Endpoint, "/"), g.Bucket, escapeKey(key))
+	req, err := http.NewRequest(http.MethodPut, objectURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+g.Token)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Cache-Control", cacheControl)
+
+	client := g.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("GCS returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package upload
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultIPFSAPI is the HTTP RPC address of a local IPFS (Kubo) node.
+const DefaultIPFSAPI = "http://127.0.0.1:5001"
+
+// IPFSPublisher adds files to an IPFS node through its HTTP RPC API (/api/v0/add).
+// Unlike an Uploader it doesn't choose keys: the node returns a content identifier (CID).
+type IPFSPublisher struct {
+	APIURL string
+
+	client *http.Client
+}
+
+// NewIPFSPublisher creates a publisher for the node at apiURL (DefaultIPFSAPI if empty).
+func NewIPFSPublisher(apiURL string) *IPFSPublisher {
+	if apiURL == "" {
+		apiURL = DefaultIPFSAPI
+	}
+	return &IPFSPublisher{
+		APIURL: strings.TrimSuffix(apiURL, "/"),
+		client: &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+// ipfsAddEntry is one line of the newline-delimited JSON returned by /api/v0/add
+type ipfsAddEntry struct {
+	Name string
+	Hash string
+}
+
+// Publish adds a file or directory (recursively) to the node, pinned, and returns the
+// CIDv1 of its root.
+func (p *IPFSPublisher) Publish(localPath string) (string, error) {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %w", localPath, err)
+	}
+	root := filepath.Base(filepath.Clean(localPath))
+
+	// Stream the multipart body so large exports aren't held in memory
+	body, writer := io.Pipe()
+	mw := multipart.NewWriter(writer)
+	go func() {
+		writer.CloseWithError(writeIPFSParts(mw, localPath, root, info.IsDir()))
+	}()
+
+	query := url.Values{}
+	query.Set("recursive", "true")
+	query.Set("pin", "true")
+	query.Set("cid-version", "1")
+	req, err := http.NewRequest(http.MethodPost, p.APIURL+"/api/v0/add?"+query.Encode(), body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	client := p.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach IPFS node at %s: %w\n\nStart a local node with 'ipfs daemon' or pass --ipfs-api", p.APIURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("IPFS node returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	// The node reports every added file and directory; the root is the one named after localPath
+	decoder := json.NewDecoder(resp.Body)
+	cid := ""
+	for {
+		var entry ipfsAddEntry
+		if err := decoder.Decode(&entry); err == io.EOF {
+			break
+		} else if err != nil {
+			return "", fmt.Errorf("failed to parse IPFS add response: %w", err)
+		}
+		if entry.Name == root {
+			cid = entry.Hash
+		}
+	}
+	if cid == "" {
+		return "", fmt.Errorf("IPFS add response did not include %s", root)
+	}
+
+	return cid, nil
+}
+
+// writeIPFSParts writes one multipart part per directory and file, parents before children,
+// named by their slash-separated path from root as the add endpoint expects
+func writeIPFSParts(mw *multipart.Writer, localPath, root string, isDir bool) error {
+	if !isDir {
+		if err := writeIPFSFile(mw, localPath, root); err != nil {
+			return err
+		}
+		return mw.Close()
+	}
+
+	err := filepath.WalkDir(localPath, func(p string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		rel, err := filepath.Rel(localPath, p)
+		if err != nil {
+			return err
+		}
+		name := root
+		if rel != "." {
+			name = root + "/" + filepath.ToSlash(rel)
+		}
+
+		if d.IsDir() {
+			_, err := mw.CreatePart(ipfsPartHeader(name, "application/x-directory"))
+			return err
+		}
+		return writeIPFSFile(mw, p, name)
+	})
+	if err != nil {
+		return err
+	}
+	return mw.Close()
+}
+
+func writeIPFSFile(mw *multipart.Writer, localPath, name string) error {
+	part, err := mw.CreatePart(ipfsPartHeader(name, "application/octet-stream"))
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", localPath, err)
+	}
+	defer f.Close()
+
+	_, err = io.Copy(part, f)
+	return err
+}
+
+func ipfsPartHeader(name, contentType string) textproto.MIMEHeader {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, url.QueryEscape(name)))
+	h.Set("Content-Type", contentType)
+	return h
+}
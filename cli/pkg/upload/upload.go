@@ -0,0 +1,178 @@
+package upload
+
+import (
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Uploader stores objects in a bucket-style object store.
+type Uploader interface {
+	// PutObject writes data to key, replacing any existing object.
+	PutObject(key string, data []byte, contentType, cacheControl string) error
+}
+
+// Target is a parsed --upload destination such as s3://bucket/prefix.
+type Target struct {
+	Scheme string // "s3" or "gs"
+	Bucket string
+	Prefix string // Key prefix without leading/trailing slashes (may be empty)
+}
+
+// String returns the target in URL form.
+func (t Target) String() string {
+	if t.Prefix == "" {
+		return fmt.Sprintf("%s://%s", t.Scheme, t.Bucket)
+	}
+	return fmt.Sprintf("%s://%s/%s", t.Scheme, t.Bucket, t.Prefix)
+}
+
+// Key joins the target prefix with a slash-separated relative path.
+func (t Target) Key(rel string) string {
+	if t.Prefix == "" {
+		return rel
+	}
+	return t.Prefix + "/" + rel
+}
+
+// ParseTarget parses an s3://bucket/prefix or gs://bucket/prefix URL.
+func ParseTarget(raw string) (Target, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Target{}, fmt.Errorf("invalid upload target %q: %w", raw, err)
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "s3" && scheme != "gs" {
+		return Target{}, fmt.Errorf("unsupported upload target %q\n\nExpected s3://bucket/prefix or gs://bucket/prefix", raw)
+	}
+	if u.Host == "" {
+		return Target{}, fmt.Errorf("upload target %q is missing a bucket name", raw)
+	}
+
+	return Target{
+		Scheme: scheme,
+		Bucket: u.Host,
+		Prefix: strings.Trim(u.Path, "/"),
+	}, nil
+}
+
+// NewUploader creates an uploader for the target using the standard credentials
+// of its provider (see NewS3Uploader and NewGCSUploader).
+func NewUploader(t Target) (Uploader, error) {
+	switch t.Scheme {
+	case "s3":
+		return NewS3Uploader(t.Bucket)
+	case "gs":
+		return NewGCSUploader(t.Bucket)
+	default:
+		return nil, fmt.Errorf("unsupported upload scheme: %s", t.Scheme)
+	}
+}
+
+// Cache-Control values. The index files change on every export and must be revalidated;
+// everything else is versioned alongside them and can be cached briefly.
+const (
+	CacheControlIndex  = "no-cache"
+	CacheControlAssets = "public, max-age=3600"
+)
+
+// ContentType returns the MIME type for an exported file.
+func ContentType(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json":
+		return "application/json"
+	case ".glb":
+		return "model/gltf-binary"
+	case ".zip":
+		return "application/zip"
+	case ".dot":
+		return "text/vnd.graphviz"
+	case ".graphml":
+		return "application/graphml+xml"
+	}
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
+
+// CacheControl returns the Cache-Control header for an exported file's relative path.
+func CacheControl(rel string) string {
+	switch rel {
+	case "metadata.json", "units.json", "units.pb", "models.json", "CREDITS.json", "CREDITS.md", "conflicts.json", "id-aliases.json", "cross-faction.json", "missiles.json", "counters.json", "build-tree.dot", "build-tree.graphml":
+		return CacheControlIndex
+	}
+	if !strings.Contains(rel, "/") && strings.HasSuffix(rel, ".zip") {
+		return CacheControlIndex
+	}
+	return CacheControlAssets
+}
+
+// UploadPath uploads a file or every file under a directory. Object keys are the
+// target prefix, the base name of localPath, then the path relative to it
+// (e.g. s3://bucket/factions + ./out/MLA -> factions/MLA/units.json).
+// Returns the number of files uploaded.
+func UploadPath(u Uploader, t Target, localPath string, verbose bool) (int, error) {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat %s: %w", localPath, err)
+	}
+
+	base := filepath.Base(filepath.Clean(localPath))
+	if !info.IsDir() {
+		if err := uploadFile(u, t, localPath, base, base, verbose); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+
+	count := 0
+	err = filepath.WalkDir(localPath, func(p string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(localPath, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if err := uploadFile(u, t, p, base+"/"+rel, rel, verbose); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+// uploadFile uploads one file; rel (relative to the exported folder) picks its cache policy
+func uploadFile(u Uploader, t Target, localPath, keyPath, rel string, verbose bool) error {
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", localPath, err)
+	}
+
+	key := t.Key(keyPath)
+	if err := u.PutObject(key, data, ContentType(localPath), CacheControl(rel)); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+
+	if verbose {
+		fmt.Printf("  ✓ Uploaded %s://%s/%s\n", t.Scheme, t.Bucket, key)
+	}
+	return nil
+}
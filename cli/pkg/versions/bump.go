@@ -0,0 +1,204 @@
+package versions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/mirror"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// Bump is the part of a semantic version an export's changes call for
+type Bump int
+
+const (
+	BumpNone  Bump = iota // Nothing changed
+	BumpPatch             // Only files changed (icons, copied specs, reports), not unit data
+	BumpMinor             // Unit stats changed
+	BumpMajor             // Units were added or removed
+)
+
+func (b Bump) String() string {
+	switch b {
+	case BumpPatch:
+		return "patch"
+	case BumpMinor:
+		return "minor"
+	case BumpMajor:
+		return "major"
+	}
+	return "none"
+}
+
+// Snapshot is what exports are compared on: metadata, unit index and file hashes
+type Snapshot struct {
+	Metadata *models.FactionMetadata
+	Index    *models.FactionIndex
+	Files    map[string]string // SHA-256 by slash-separated path, without metadata.json
+}
+
+// ReadSnapshot reads an exported faction folder. It returns nil without an error when
+// the folder holds no export yet.
+func ReadSnapshot(factionDir string) (*Snapshot, error) {
+	if _, err := os.Stat(filepath.Join(factionDir, "units.json")); os.IsNotExist(err) {
+		return nil, nil
+	}
+	metadata, err := exporter.ReadFactionMetadata(factionDir)
+	if err != nil {
+		return nil, err
+	}
+	index, err := exporter.ReadFactionIndex(factionDir)
+	if err != nil {
+		return nil, err
+	}
+	store, err := mirror.Open(factionDir, "")
+	if err != nil {
+		return nil, err
+	}
+	files, err := store.Files()
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash %s: %w", factionDir, err)
+	}
+	snapshot := &Snapshot{Metadata: metadata, Index: index, Files: make(map[string]string, len(files))}
+	for path, f := range files {
+		if path != "metadata.json" {
+			snapshot.Files[path] = f.SHA256
+		}
+	}
+	return snapshot, nil
+}
+
+// Change is the difference between two exports of a faction
+type Change struct {
+	Bump    Bump
+	Added   []string // Unit IDs, sorted
+	Removed []string
+	Changed []string // Units present in both whose index entry differs
+	Files   int      // Files added, removed or modified
+}
+
+// Diff compares an export against the previous one: added or removed units call for a
+// major bump, changed unit data for a minor one and any other changed file (icons,
+// copied specs) for a patch. metadata.json is ignored, being where the version lives.
+func Diff(previous, current *Snapshot) Change {
+	var change Change
+	before := make(map[string][]byte, len(previous.Index.Units))
+	for _, entry := range previous.Index.Units {
+		before[entry.Identifier], _ = json.Marshal(entry.Unit)
+	}
+	seen := make(map[string]bool, len(current.Index.Units))
+	for _, entry := range current.Index.Units {
+		seen[entry.Identifier] = true
+		old, ok := before[entry.Identifier]
+		if !ok {
+			change.Added = append(change.Added, entry.Identifier)
+			continue
+		}
+		if data, _ := json.Marshal(entry.Unit); !bytes.Equal(data, old) {
+			change.Changed = append(change.Changed, entry.Identifier)
+		}
+	}
+	for id := range before {
+		if !seen[id] {
+			change.Removed = append(change.Removed, id)
+		}
+	}
+	sort.Strings(change.Added)
+	sort.Strings(change.Removed)
+	sort.Strings(change.Changed)
+
+	for path, sum := range current.Files {
+		if previous.Files[path] != sum {
+			change.Files++
+		}
+	}
+	for path := range previous.Files {
+		if _, ok := current.Files[path]; !ok {
+			change.Files++
+		}
+	}
+
+	switch {
+	case len(change.Added) > 0 || len(change.Removed) > 0:
+		change.Bump = BumpMajor
+	case len(change.Changed) > 0:
+		change.Bump = BumpMinor
+	case change.Files > 0:
+		change.Bump = BumpPatch
+	}
+	return change
+}
+
+// Reason summarises a change in a few words, e.g. "2 units added, 1 unit changed"
+func (c Change) Reason() string {
+	count := func(n int, what string) string {
+		if n == 1 {
+			return "1 " + what
+		}
+		return fmt.Sprintf("%d %ss", n, what)
+	}
+	var parts []string
+	if len(c.Added) > 0 {
+		parts = append(parts, count(len(c.Added), "unit")+" added")
+	}
+	if len(c.Removed) > 0 {
+		parts = append(parts, count(len(c.Removed), "unit")+" removed")
+	}
+	if len(c.Changed) > 0 {
+		parts = append(parts, count(len(c.Changed), "unit")+" changed")
+	}
+	if len(parts) == 0 && c.Files > 0 {
+		parts = append(parts, count(c.Files, "file")+" changed")
+	}
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// NextVersion applies a bump to a semantic version such as "1.2.3" or "v1.2": a major
+// bump gives "2.0.0", minor "1.3.0" and patch "1.2.4". A "v" prefix is kept, missing
+// parts count as 0 and a pre-release or build suffix is dropped. BumpNone returns the
+// version unchanged.
+func NextVersion(version string, bump Bump) (string, error) {
+	if bump == BumpNone {
+		return version, nil
+	}
+	prefix := ""
+	core := version
+	if strings.HasPrefix(core, "v") {
+		prefix, core = "v", core[1:]
+	}
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return "", fmt.Errorf("version '%s' is not a semantic version (MAJOR.MINOR.PATCH)", version)
+	}
+	var numbers [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("version '%s' is not a semantic version (MAJOR.MINOR.PATCH)", version)
+		}
+		numbers[i] = n
+	}
+
+	switch bump {
+	case BumpMajor:
+		numbers = [3]int{numbers[0] + 1, 0, 0}
+	case BumpMinor:
+		numbers = [3]int{numbers[0], numbers[1] + 1, 0}
+	case BumpPatch:
+		numbers[2]++
+	}
+	return fmt.Sprintf("%s%d.%d.%d", prefix, numbers[0], numbers[1], numbers[2]), nil
+}
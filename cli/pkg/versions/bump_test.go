@@ -0,0 +1,105 @@
+package versions
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func snapshot(health map[string]float64, files map[string]string) *Snapshot {
+	index := &models.FactionIndex{}
+	for id, h := range health {
+		entry := models.UnitIndexEntry{Identifier: id, Unit: models.Unit{ID: id}}
+		entry.Unit.Specs.Combat = &models.CombatSpecs{Health: h}
+		index.Units = append(index.Units, entry)
+	}
+	return &Snapshot{Index: index, Files: files}
+}
+
+func TestDiff(t *testing.T) {
+	previous := snapshot(map[string]float64{"tank": 100, "bot": 50}, map[string]string{"units.json": "a", "tank.png": "b"})
+
+	tests := []struct {
+		name    string
+		current *Snapshot
+		want    Bump
+		reason  string
+	}{
+		{"identical", snapshot(map[string]float64{"tank": 100, "bot": 50}, map[string]string{"units.json": "a", "tank.png": "b"}), BumpNone, "no changes"},
+		{"icon replaced", snapshot(map[string]float64{"tank": 100, "bot": 50}, map[string]string{"units.json": "a", "tank.png": "c"}), BumpPatch, "1 file changed"},
+		{"icon removed", snapshot(map[string]float64{"tank": 100, "bot": 50}, map[string]string{"units.json": "a"}), BumpPatch, "1 file changed"},
+		{"stat changed", snapshot(map[string]float64{"tank": 120, "bot": 50}, map[string]string{"units.json": "d", "tank.png": "b"}), BumpMinor, "1 unit changed"},
+		{"unit added", snapshot(map[string]float64{"tank": 120, "bot": 50, "air": 10}, map[string]string{"units.json": "d"}), BumpMajor, "1 unit added, 1 unit changed"},
+		{"units removed", snapshot(map[string]float64{}, map[string]string{"units.json": "d"}), BumpMajor, "2 units removed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := Diff(previous, tt.current)
+			if change.Bump != tt.want {
+				t.Errorf("Bump = %s, want %s", change.Bump, tt.want)
+			}
+			if got := change.Reason(); got != tt.reason {
+				t.Errorf("Reason() = %q, want %q", got, tt.reason)
+			}
+		})
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		bump    Bump
+		want    string
+		wantErr bool
+	}{
+		{"1.2.3", BumpNone, "1.2.3", false},
+		{"1.2.3", BumpPatch, "1.2.4", false},
+		{"1.2.3", BumpMinor, "1.3.0", false},
+		{"1.2.3", BumpMajor, "2.0.0", false},
+		{"v1.2", BumpPatch, "v1.2.1", false},
+		{"1.2.3-beta.1", BumpMinor, "1.3.0", false},
+		{"1.2.3+build5", BumpPatch, "1.2.4", false},
+		{"release-7", BumpPatch, "", true},
+		{"1.2.3.4", BumpPatch, "", true},
+	}
+	for _, tt := range tests {
+		got, err := NextVersion(tt.version, tt.bump)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NextVersion(%q, %s) error = %v, wantErr %v", tt.version, tt.bump, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NextVersion(%q, %s) = %q, want %q", tt.version, tt.bump, got, tt.want)
+		}
+	}
+}
+
+func TestReadSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	if s, err := ReadSnapshot(filepath.Join(dir, "MLA")); s != nil || err != nil {
+		t.Fatalf("ReadSnapshot of a missing folder = %v, %v; want nil, nil", s, err)
+	}
+
+	index, _ := json.Marshal(models.FactionIndex{Units: []models.UnitIndexEntry{{Identifier: "tank"}}})
+	src := writeExport(t, dir, "1.0.0", string(index))
+	if err := os.MkdirAll(filepath.Join(src, ".checkpoint"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, ".checkpoint", "units.gob"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := ReadSnapshot(src)
+	if err != nil {
+		t.Fatalf("ReadSnapshot failed: %v", err)
+	}
+	if s.Metadata.Version != "1.0.0" || len(s.Index.Units) != 1 {
+		t.Errorf("unexpected snapshot: %+v", s)
+	}
+	if len(s.Files) != 1 || s.Files["units.json"] == "" {
+		t.Errorf("expected only units.json hashed (no metadata.json or dot folders), got %v", s.Files)
+	}
+}
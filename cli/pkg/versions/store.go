@@ -0,0 +1,263 @@
+// Package versions maintains a versioned output directory, the layout the daemon command
+// writes and serve reads: <root>/<id>/<version>/ faction folders plus a versions.json
+// manifest (models.VersionsManifest) per faction. It also diffs an export against the
+// previous one to suggest the next semantic version (bump.go).
+package versions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// ManifestName is the versions manifest in each faction directory
+const ManifestName = "versions.json"
+
+// Store is a versioned output directory
+type Store struct {
+	Root string
+}
+
+// FactionDir returns the directory holding the versions of a faction
+func (s *Store) FactionDir(identifier string) string {
+	return filepath.Join(s.Root, folderName(strings.ToLower(identifier)))
+}
+
+// Install moves an exported faction folder into the store as <id>/<version>/ and records
+// it in the manifest as the latest version, returning the updated manifest. Re-exporting
+// a version already in the store replaces that version's folder. src must be on the same
+// filesystem as the store.
+func (s *Store) Install(src string, exportedAt time.Time) (*models.VersionsManifest, error) {
+	metadata, err := exporter.ReadFactionMetadata(src)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.Identifier == "" || metadata.Version == "" {
+		return nil, fmt.Errorf("%s: metadata.json needs identifier and version", src)
+	}
+
+	factionDir := s.FactionDir(metadata.Identifier)
+	if err := os.MkdirAll(factionDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create faction directory: %w", err)
+	}
+	entry := models.VersionEntry{
+		Version:    metadata.Version,
+		Path:       folderName(metadata.Version),
+		PABuild:    metadata.PABuild,
+		ExportedAt: exportedAt.UTC().Format(time.RFC3339),
+	}
+
+	// Swap the folders so the previous export stays whole until the new one is in place
+	dest := filepath.Join(factionDir, entry.Path)
+	replaced := ""
+	if _, err := os.Stat(dest); err == nil {
+		replaced = filepath.Join(factionDir, "."+entry.Path+".replaced")
+		os.RemoveAll(replaced)
+		if err := os.Rename(dest, replaced); err != nil {
+			return nil, fmt.Errorf("failed to move aside previous export of %s: %w", entry.Version, err)
+		}
+	}
+	if err := os.Rename(src, dest); err != nil {
+		if replaced != "" {
+			os.Rename(replaced, dest)
+		}
+		return nil, fmt.Errorf("failed to move export into %s: %w", dest, err)
+	}
+	if replaced != "" {
+		os.RemoveAll(replaced)
+	}
+
+	manifest, err := s.Manifest(metadata.Identifier)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Identifier = metadata.Identifier
+	manifest.DisplayName = metadata.DisplayName
+	kept := []models.VersionEntry{entry}
+	for _, v := range manifest.Versions {
+		if v.Version == entry.Version {
+			// A re-export keeps its pin and tags
+			kept[0].Pinned, kept[0].Tags = v.Pinned, v.Tags
+			continue
+		}
+		kept = append(kept, v)
+	}
+	manifest.Versions = kept
+	if err := s.writeManifest(manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Manifest reads the versions manifest of a faction; a faction with no manifest yet has
+// an empty one
+func (s *Store) Manifest(identifier string) (*models.VersionsManifest, error) {
+	path := filepath.Join(s.FactionDir(identifier), ManifestName)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &models.VersionsManifest{Identifier: identifier, Versions: []models.VersionEntry{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var manifest models.VersionsManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// writeManifest sorts the versions, most recently exported first, and writes the manifest
+// through a temporary file so readers never see it half-written
+func (s *Store) writeManifest(manifest *models.VersionsManifest) error {
+	manifest.Versions = sortedVersions(manifest)
+	manifest.Latest = ""
+	if len(manifest.Versions) > 0 {
+		manifest.Latest = manifest.Versions[0].Version
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.FactionDir(manifest.Identifier), ManifestName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Expired returns the versions of a faction that Prune would remove: those beyond the
+// keep most recently exported, except pinned or tagged ones. keep < 1 expires nothing.
+func (s *Store) Expired(identifier string, keep int) ([]models.VersionEntry, error) {
+	manifest, err := s.Manifest(identifier)
+	if err != nil || keep < 1 {
+		return nil, err
+	}
+	var expired []models.VersionEntry
+	for i, v := range sortedVersions(manifest) {
+		if i >= keep && !v.Protected() {
+			expired = append(expired, v)
+		}
+	}
+	return expired, nil
+}
+
+// Prune deletes the Expired versions of a faction and drops them from its manifest,
+// returning the removed entries
+func (s *Store) Prune(identifier string, keep int) ([]models.VersionEntry, error) {
+	expired, err := s.Expired(identifier, keep)
+	if err != nil || len(expired) == 0 {
+		return nil, err
+	}
+	var removed []models.VersionEntry
+	var removeErr error
+	for _, v := range expired {
+		// The path comes from versions.json, so only ever remove the version's own folder:
+		// an empty or ".." path would take the faction folder or more with it
+		if v.Path == "" || v.Path != folderName(v.Version) {
+			removeErr = fmt.Errorf("refusing to remove %s version %s: its path %q in %s isn't the version's folder %q", identifier, v.Version, v.Path, ManifestName, folderName(v.Version))
+			break
+		}
+		if err := os.RemoveAll(filepath.Join(s.FactionDir(identifier), v.Path)); err != nil {
+			// Stop here; what's left stays listed until the next prune
+			removeErr = fmt.Errorf("failed to remove %s version %s: %w", identifier, v.Version, err)
+			break
+		}
+		removed = append(removed, v)
+	}
+
+	manifest, err := s.Manifest(identifier)
+	if err != nil {
+		return removed, err
+	}
+	gone := make(map[string]bool, len(removed))
+	for _, v := range removed {
+		gone[v.Version] = true
+	}
+	kept := manifest.Versions[:0]
+	for _, v := range manifest.Versions {
+		if !gone[v.Version] {
+			kept = append(kept, v)
+		}
+	}
+	manifest.Versions = kept
+	if err := s.writeManifest(manifest); err != nil {
+		return removed, err
+	}
+	return removed, removeErr
+}
+
+// Edit changes the manifest entry of one version, e.g. to pin or tag it
+func (s *Store) Edit(identifier, version string, edit func(*models.VersionEntry)) error {
+	manifest, err := s.Manifest(identifier)
+	if err != nil {
+		return err
+	}
+	for i := range manifest.Versions {
+		if manifest.Versions[i].Version == version {
+			edit(&manifest.Versions[i])
+			return s.writeManifest(manifest)
+		}
+	}
+	return fmt.Errorf("%s has no version %s in %s", identifier, version, filepath.Join(s.FactionDir(identifier), ManifestName))
+}
+
+// Factions returns the identifiers of the factions with a versions manifest, sorted
+func (s *Store) Factions() ([]string, error) {
+	entries, err := os.ReadDir(s.Root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read versioned output directory: %w", err)
+	}
+	var ids []string
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(s.Root, entry.Name(), ManifestName)); err == nil {
+			manifest, err := s.Manifest(entry.Name())
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, manifest.Identifier)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// sortedVersions returns the versions of a manifest, most recently exported first
+func sortedVersions(manifest *models.VersionsManifest) []models.VersionEntry {
+	versions := append(make([]models.VersionEntry, 0, len(manifest.Versions)), manifest.Versions...)
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].ExportedAt > versions[j].ExportedAt
+	})
+	return versions
+}
+
+// folderName makes a version or identifier safe as a single path segment, keeping dots
+// so "1.2.0" stays readable
+func folderName(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, name)
+	if strings.Trim(safe, ".") == "" {
+		return strings.ReplaceAll(safe, ".", "-")
+	}
+	return safe
+}
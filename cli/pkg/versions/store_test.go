@@ -0,0 +1,185 @@
+package versions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// writeExport writes a minimal export folder and returns its path
+func writeExport(t *testing.T, dir, version, marker string) string {
+	t.Helper()
+	src := filepath.Join(dir, "MLA")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	metadata := `{"identifier":"MLA","displayName":"MLA","version":"` + version + `","type":"base-game"}`
+	if err := os.WriteFile(filepath.Join(src, "metadata.json"), []byte(metadata), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "units.json"), []byte(marker), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+func TestInstallAndPrune(t *testing.T) {
+	root := t.TempDir()
+	staging := filepath.Join(root, ".staging")
+	store := &Store{Root: root}
+	start := time.Date(2025, 1, 1, 4, 0, 0, 0, time.UTC)
+
+	for i, version := range []string{"1.0.0", "1.1.0", "1.2.0"} {
+		manifest, err := store.Install(writeExport(t, staging, version, version), start.Add(time.Duration(i)*24*time.Hour))
+		if err != nil {
+			t.Fatalf("Install(%s) error = %v", version, err)
+		}
+		if manifest.Latest != version || manifest.Versions[0].Path != version {
+			t.Errorf("Install(%s) latest %q path %q", version, manifest.Latest, manifest.Versions[0].Path)
+		}
+	}
+
+	// Re-exporting a version replaces its folder and makes it the latest
+	if _, err := store.Install(writeExport(t, staging, "1.0.0", "re-export"), start.Add(5*24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(root, "mla", "1.0.0", "units.json"))
+	if string(data) != "re-export" {
+		t.Errorf("re-exported units.json = %q", data)
+	}
+
+	manifest, err := store.Manifest("mla")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, v := range manifest.Versions {
+		order = append(order, v.Version)
+	}
+	if manifest.Latest != "1.0.0" || len(order) != 3 || order[0] != "1.0.0" || order[1] != "1.2.0" || order[2] != "1.1.0" {
+		t.Errorf("manifest latest %q versions %v, want 1.0.0 [1.0.0 1.2.0 1.1.0]", manifest.Latest, order)
+	}
+
+	removed, err := store.Prune("MLA", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0].Version != "1.1.0" {
+		t.Errorf("Prune() removed %+v, want 1.1.0", removed)
+	}
+	if _, err := os.Stat(filepath.Join(root, "mla", "1.1.0")); !os.IsNotExist(err) {
+		t.Errorf("pruned folder still exists (err %v)", err)
+	}
+	if manifest, _ := store.Manifest("mla"); len(manifest.Versions) != 2 {
+		t.Errorf("manifest after prune has %d versions, want 2", len(manifest.Versions))
+	}
+	if removed, _ := store.Prune("mla", 0); removed != nil {
+		t.Errorf("Prune(0) removed %+v, want nothing", removed)
+	}
+}
+
+func TestPruneKeepsPinnedAndTagged(t *testing.T) {
+	root := t.TempDir()
+	staging := filepath.Join(root, ".staging")
+	store := &Store{Root: root}
+	start := time.Date(2025, 1, 1, 4, 0, 0, 0, time.UTC)
+	for i, version := range []string{"1", "2", "3", "4", "5"} {
+		if _, err := store.Install(writeExport(t, staging, version, version), start.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Edit("mla", "1", func(v *models.VersionEntry) { v.Pinned = true }); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Edit("mla", "2", func(v *models.VersionEntry) { v.Tags = []string{"tournament"} }); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Edit("mla", "9", func(v *models.VersionEntry) {}); err == nil {
+		t.Error("Edit() of a missing version succeeded")
+	}
+
+	// A re-export keeps the pin
+	if _, err := store.Install(writeExport(t, staging, "1", "again"), start.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	expired, err := store.Expired("mla", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range expired {
+		got = append(got, v.Version)
+	}
+	if strings.Join(got, ",") != "4,3" {
+		t.Errorf("Expired(1) = %v, want [4 3]", got)
+	}
+
+	if _, err := store.Prune("mla", 1); err != nil {
+		t.Fatal(err)
+	}
+	manifest, _ := store.Manifest("mla")
+	got = nil
+	for _, v := range manifest.Versions {
+		got = append(got, v.Version)
+	}
+	if strings.Join(got, ",") != "5,2,1" {
+		t.Errorf("versions after Prune(1) = %v, want [5 2 1]", got)
+	}
+
+	ids, err := store.Factions()
+	if err != nil || len(ids) != 1 || ids[0] != "MLA" {
+		t.Errorf("Factions() = %v, %v, want [MLA]", ids, err)
+	}
+}
+
+func TestPruneRefusesUnsafePaths(t *testing.T) {
+	for _, path := range []string{"", "..", "../mla", "2/.."} {
+		t.Run(path, func(t *testing.T) {
+			root := t.TempDir()
+			staging := filepath.Join(root, ".staging")
+			store := &Store{Root: root}
+			start := time.Date(2025, 1, 1, 4, 0, 0, 0, time.UTC)
+			for i, version := range []string{"1", "2"} {
+				if _, err := store.Install(writeExport(t, staging, version, version), start.Add(time.Duration(i)*time.Hour)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := store.Edit("mla", "1", func(v *models.VersionEntry) { v.Path = path }); err != nil {
+				t.Fatal(err)
+			}
+
+			if removed, err := store.Prune("mla", 1); err == nil || len(removed) != 0 {
+				t.Errorf("Prune() = %+v, %v, want an error and nothing removed", removed, err)
+			}
+			for _, kept := range []string{ManifestName, "1", "2"} {
+				if _, err := os.Stat(filepath.Join(root, "mla", kept)); err != nil {
+					t.Errorf("%s was removed: %v", kept, err)
+				}
+			}
+			if manifest, _ := store.Manifest("mla"); len(manifest.Versions) != 2 {
+				t.Errorf("manifest after refused prune has %d versions, want 2", len(manifest.Versions))
+			}
+		})
+	}
+}
+
+func TestFolderName(t *testing.T) {
+	tests := map[string]string{
+		"1.2.0":          "1.2.0",
+		"124664":         "124664",
+		"v2/beta":        "v2-beta",
+		"..":             "--",
+		"com.pa.legion":  "com.pa.legion",
+		"1.0 (hotfix 2)": "1.0--hotfix-2-",
+	}
+	for in, want := range tests {
+		if got := folderName(in); got != want {
+			t.Errorf("folderName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
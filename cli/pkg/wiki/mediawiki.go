@@ -0,0 +1,177 @@
+// Package wiki renders exported units as wiki markup so community wiki pages can be
+// bulk-updated from extracted data.
+package wiki
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/combat"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+	"github.com/jamiemulcahy/pa-pedia/pkg/stats"
+)
+
+// Output formats
+const (
+	FormatMediaWiki = "mediawiki"
+)
+
+// DefaultTemplate is the infobox template name used when none is given
+const DefaultTemplate = "Infobox unit"
+
+// ParseFormat validates a --format value
+func ParseFormat(format string) (string, error) {
+	switch format {
+	case FormatMediaWiki:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown wiki format %q (supported: %s)", format, FormatMediaWiki)
+	}
+}
+
+// Param is one named infobox parameter
+type Param struct {
+	Name  string
+	Value string
+}
+
+// InfoboxParams returns a unit's infobox parameters in output order, leaving out empty
+// ones. names maps unit IDs to display names for the builds/built_by links; IDs missing
+// from it are linked by ID.
+func InfoboxParams(unit *models.Unit, faction string, names map[string]string) []Param {
+	var params []Param
+	add := func(name, value string) {
+		if value != "" {
+			params = append(params, Param{Name: name, Value: value})
+		}
+	}
+	number := func(name string, v float64) {
+		if v != 0 {
+			add(name, formatNumber(v))
+		}
+	}
+
+	add("name", unit.DisplayName)
+	add("id", unit.ID)
+	if unit.Image != "" {
+		add("image", path.Base(unit.Image))
+	}
+	add("description", unit.Description)
+	add("faction", faction)
+	add("tier", strconv.Itoa(unit.Tier))
+	add("types", strings.Join(unit.UnitTypes, ", "))
+
+	number("health", stats.Health(unit))
+	number("cost", stats.BuildCost(unit))
+	number("dps", stats.DPS(unit))
+	if c := unit.Specs.Combat; c != nil {
+		number("salvo_damage", c.SalvoDamage)
+		var weapons []string
+		var maxRange float64
+		for i := range c.Weapons {
+			w := &c.Weapons[i]
+			if !combat.Active(w) {
+				continue
+			}
+			maxRange = max(maxRange, w.MaxRange)
+			weapons = append(weapons, describeWeapon(w))
+		}
+		number("range", maxRange)
+		add("weapons", strings.Join(weapons, "<br>"))
+	}
+	number("combat_value", unit.CombatValue)
+
+	if m := unit.Specs.Mobility; m != nil {
+		number("speed", m.MoveSpeed)
+		number("acceleration", m.Acceleration)
+		number("turn_speed", m.TurnSpeed)
+	}
+	if r := unit.Specs.Recon; r != nil {
+		number("vision", r.VisionRadius)
+		number("underwater_vision", r.UnderwaterVisionRadius)
+		number("radar", r.RadarRadius)
+		number("sonar", r.SonarRadius)
+	}
+	if e := unit.Specs.Economy; e != nil {
+		number("metal_production", e.Production.Metal)
+		number("energy_production", e.Production.Energy)
+		number("metal_consumption", e.Consumption.Metal)
+		number("energy_consumption", e.Consumption.Energy)
+		number("metal_storage", e.Storage.Metal)
+		number("energy_storage", e.Storage.Energy)
+		number("build_rate", e.BuildRate)
+		number("build_range", e.BuildRange)
+	}
+
+	add("built_by", links(unit.BuildRelationships.BuiltBy, names))
+	add("builds", links(unit.BuildRelationships.Builds, names))
+	return params
+}
+
+// Infobox renders a unit as a MediaWiki template call, one parameter per line
+func Infobox(unit *models.Unit, template, faction string, names map[string]string) string {
+	var b strings.Builder
+	b.WriteString("{{")
+	b.WriteString(template)
+	b.WriteString("\n")
+	for _, p := range InfoboxParams(unit, faction, names) {
+		fmt.Fprintf(&b, "| %s = %s\n", p.Name, Escape(p.Value))
+	}
+	b.WriteString("}}\n")
+	return b.String()
+}
+
+// Escape makes a value safe inside a template parameter: pipes become {{!}} and braces
+// that could close the template are encoded. Link markup ([[...]]) is left alone.
+func Escape(value string) string {
+	return strings.NewReplacer(
+		"|", "{{!}}",
+		"{{", "&#123;&#123;",
+		"}}", "&#125;&#125;",
+	).Replace(value)
+}
+
+// PageTitle is the wiki page a unit's infobox belongs on: its display name, or its ID
+// when it has none
+func PageTitle(unit *models.Unit) string {
+	if unit.DisplayName != "" {
+		return unit.DisplayName
+	}
+	return unit.ID
+}
+
+func describeWeapon(w *models.Weapon) string {
+	name := w.Name
+	if name == "" {
+		name = w.SafeName
+	}
+	if w.Count > 1 {
+		name = fmt.Sprintf("%d× %s", w.Count, name)
+	}
+	details := []string{formatNumber(w.DPS) + " DPS"}
+	if w.MaxRange > 0 {
+		details = append(details, "range "+formatNumber(w.MaxRange))
+	}
+	return fmt.Sprintf("%s (%s)", name, strings.Join(details, ", "))
+}
+
+// links renders unit IDs as links to their pages (see PageTitle)
+func links(ids []string, names map[string]string) string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if name, ok := names[id]; ok && name != "" {
+			out = append(out, "[["+name+"]]")
+		} else {
+			out = append(out, "[["+id+"]]")
+		}
+	}
+	return strings.Join(out, ", ")
+}
+
+// formatNumber prints up to 2 decimal places without trailing zeros
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
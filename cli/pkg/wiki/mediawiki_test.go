@@ -0,0 +1,73 @@
+package wiki
+
+import (
+	"testing"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+func TestInfobox(t *testing.T) {
+	unit := &models.Unit{
+		ID:          "tank_light_laser",
+		DisplayName: "Ant",
+		Description: "Light Tank | Attacks land {{and}} sea",
+		Image:       "assets/units/tank_light_laser/tank_light_laser_icon_buildbar.png",
+		Tier:        1,
+		UnitTypes:   []string{"Mobile", "Tank"},
+	}
+	unit.Specs.Combat = &models.CombatSpecs{Health: 250, DPS: 46.15, Weapons: []models.Weapon{
+		{Name: "Laser", Count: 2, DPS: 46.15, MaxRange: 100},
+		{Name: "Self Destruct", DPS: 1000, MaxRange: 10, DeathExplosion: true},
+	}}
+	unit.Specs.Economy = &models.EconomySpecs{BuildCost: 150}
+	unit.BuildRelationships.BuiltBy = []string{"vehicle_factory", "unknown_factory"}
+
+	got := Infobox(unit, DefaultTemplate, "MLA", map[string]string{"vehicle_factory": "Vehicle Factory"})
+	want := `{{Infobox unit
+| name = Ant
+| id = tank_light_laser
+| image = tank_light_laser_icon_buildbar.png
+| description = Light Tank {{!}} Attacks land &#123;&#123;and&#125;&#125; sea
+| faction = MLA
+| tier = 1
+| types = Mobile, Tank
+| health = 250
+| cost = 150
+| dps = 46.15
+| range = 100
+| weapons = 2× Laser (46.15 DPS, range 100)
+| built_by = [[Vehicle Factory]], [[unknown_factory]]
+}}
+`
+	if got != want {
+		t.Errorf("Infobox() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestInfoboxParamsOmitsEmpty(t *testing.T) {
+	params := InfoboxParams(&models.Unit{ID: "beacon"}, "", nil)
+	var names []string
+	for _, p := range params {
+		names = append(names, p.Name)
+	}
+	if len(names) != 2 || names[0] != "id" || names[1] != "tier" {
+		t.Errorf("InfoboxParams() names = %v, want [id tier]", names)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr bool
+	}{
+		{"mediawiki", false},
+		{"MediaWiki", true},
+		{"markdown", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		if _, err := ParseFormat(tt.format); (err != nil) != tt.wantErr {
+			t.Errorf("ParseFormat(%q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+		}
+	}
+}
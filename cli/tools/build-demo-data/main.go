@@ -0,0 +1,146 @@
+// build-demo-data copies a small subset of an exported MLA faction folder into
+// pkg/demo/data, which is embedded into binaries built with -tags demo.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/jamiemulcahy/pa-pedia/pkg/exporter"
+	"github.com/jamiemulcahy/pa-pedia/pkg/models"
+)
+
+// demoUnits is a starter-base slice of MLA: a commander, T1 factories and
+// fabricators, economy, and a handful of combat units to compare.
+var demoUnits = []string{
+	"imperial_alpha",
+	"fabrication_bot",
+	"fabrication_vehicle",
+	"bot_factory",
+	"vehicle_factory",
+	"air_factory",
+	"metal_extractor",
+	"energy_plant",
+	"metal_storage",
+	"energy_storage",
+	"tank_light_laser",
+	"assault_bot",
+	"bot_grenadier",
+	"tank_armor",
+	"land_scout",
+	"air_scout",
+	"fighter",
+	"bomber",
+	"laser_defense_single",
+	"air_defense",
+	"radar",
+}
+
+func main() {
+	factionDir := flag.String("faction", "../../../factions/MLA", "Exported MLA faction folder to take units from")
+	outputDir := flag.String("output", "../../pkg/demo/data", "Output directory for the demo data")
+	flag.Parse()
+
+	if err := run(*factionDir, *outputDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(factionDir, outputDir string) error {
+	metadata, err := exporter.ReadFactionMetadata(factionDir)
+	if err != nil {
+		return err
+	}
+	index, err := exporter.ReadFactionIndex(factionDir)
+	if err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool, len(demoUnits))
+	for _, id := range demoUnits {
+		keep[id] = true
+	}
+
+	subset := &models.FactionIndex{Layout: index.Layout}
+	for _, entry := range index.Units {
+		if !keep[entry.Identifier] {
+			continue
+		}
+		// Drop relationships to units outside the subset so every link resolves
+		rel := &entry.Unit.BuildRelationships
+		rel.Builds = filterIDs(rel.Builds, keep)
+		rel.BuiltBy = filterIDs(rel.BuiltBy, keep)
+		subset.Units = append(subset.Units, entry)
+	}
+	if len(subset.Units) != len(demoUnits) {
+		return fmt.Errorf("found %d of %d demo units in %s", len(subset.Units), len(demoUnits), factionDir)
+	}
+	sort.Slice(subset.Units, func(i, j int) bool { return subset.Units[i].Identifier < subset.Units[j].Identifier })
+
+	// Team colours stay; the background image isn't copied, so drop its path
+	metadata.BackgroundImage = ""
+	metadata.Description = "A small MLA subset embedded for demo mode"
+
+	destDir := filepath.Join(outputDir, exporter.SanitizeFolderName(metadata.DisplayName))
+	if err := os.RemoveAll(destDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return err
+	}
+	if err := exporter.WriteFactionMetadata(destDir, *metadata); err != nil {
+		return err
+	}
+	if err := writeJSON(filepath.Join(destDir, "units.json"), subset); err != nil {
+		return err
+	}
+
+	assetsDir := "assets"
+	if subset.Layout == exporter.LayoutFlat {
+		assetsDir = "units"
+	}
+	copied := 0
+	for _, entry := range subset.Units {
+		for _, file := range entry.Files {
+			src := filepath.Join(factionDir, assetsDir, filepath.FromSlash(file.Path))
+			data, err := os.ReadFile(src)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", src, err)
+			}
+			dst := filepath.Join(destDir, assetsDir, filepath.FromSlash(file.Path))
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(dst, data, 0644); err != nil {
+				return err
+			}
+			copied++
+		}
+	}
+
+	fmt.Printf("✓ Wrote %d units and %d files to %s\n", len(subset.Units), copied, destDir)
+	return nil
+}
+
+func filterIDs(ids []string, keep map[string]bool) []string {
+	var out []string
+	for _, id := range ids {
+		if keep[id] {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
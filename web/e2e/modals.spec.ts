@@ -18,7 +18,7 @@ test.describe('Upload Modal', () => {
     await page.getByLabel('Upload local faction').click()
 
     await expect(page.getByText('File Requirements')).toBeVisible()
-    await expect(page.getByText('.zip files only')).toBeVisible()
+    await expect(page.getByText('.zip or .pafaction files only')).toBeVisible()
   })
 
   test('upload modal close button works', async ({ page }) => {
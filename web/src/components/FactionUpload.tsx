@@ -20,8 +20,8 @@ export function FactionUpload({
 
   const handleFile = useCallback(
     async (file: File) => {
-      if (!file.name.endsWith('.zip')) {
-        setError('Please select a .zip file')
+      if (!file.name.endsWith('.zip') && !file.name.endsWith('.pafaction')) {
+        setError('Please select a .zip or .pafaction file')
         return
       }
 
@@ -132,7 +132,7 @@ export function FactionUpload({
         <input
           ref={fileInputRef}
           type="file"
-          accept=".zip"
+          accept=".zip,.pafaction"
           onChange={handleFileSelect}
           disabled={isUploading}
           className="hidden"
@@ -215,7 +215,7 @@ export function FactionUpload({
                   </svg>
                   Browse Files
                 </button>
-                <span className="text-sm text-gray-400">.zip files only</span>
+                <span className="text-sm text-gray-400">.zip or .pafaction files only</span>
               </div>
             </>
           )}
@@ -248,7 +248,8 @@ export function FactionUpload({
               File Requirements
             </h3>
             <p className="text-sm text-gray-300">
-              Upload the .zip file generated by the PA-Pedia CLI tool.
+              Upload the .zip file or .pafaction bundle (pa-pedia pack) generated
+              by the PA-Pedia CLI tool.
             </p>
           </div>
 
@@ -63,7 +63,7 @@ describe('FactionUpload', () => {
       renderFactionUpload()
       expect(screen.getByText('Drag and drop faction zip here')).toBeInTheDocument()
       expect(screen.getByText('Browse Files')).toBeInTheDocument()
-      expect(screen.getByText('.zip files only')).toBeInTheDocument()
+      expect(screen.getByText('.zip or .pafaction files only')).toBeInTheDocument()
     })
 
     it('should render About Local Factions section', () => {
@@ -250,7 +250,7 @@ describe('FactionUpload', () => {
       fireEvent.change(fileInput, { target: { files: [file] } })
 
       await waitFor(() => {
-        expect(screen.getByText('Please select a .zip file')).toBeInTheDocument()
+        expect(screen.getByText('Please select a .zip or .pafaction file')).toBeInTheDocument()
       })
     })
 
@@ -273,6 +273,20 @@ describe('FactionUpload', () => {
       })
     })
 
+    it('should accept .pafaction bundles', async () => {
+      mockUploadFaction.mockResolvedValue({ factionId: 'test-faction' })
+      renderFactionUpload()
+
+      const fileInput = document.querySelector('input[type="file"]') as HTMLInputElement
+      const file = new File(['content'], 'faction.pafaction', { type: 'application/zip' })
+
+      fireEvent.change(fileInput, { target: { files: [file] } })
+
+      await waitFor(() => {
+        expect(mockUploadFaction).toHaveBeenCalledWith(file)
+      })
+    })
+
     it('should show error message on upload failure', async () => {
       mockUploadFaction.mockRejectedValue(new Error('Invalid faction data'))
       renderFactionUpload()
@@ -54,6 +54,7 @@ export const OverviewSection: React.FC<OverviewSectionProps> = ({
   const buildCost = groupStats?.totalBuildCost ?? unit?.specs.economy.buildCost ?? 0;
   const dps = groupStats?.totalDps ?? unit?.specs.combat.dps;
   const salvoDamage = groupStats?.totalSalvoDamage ?? unit?.specs.combat.salvoDamage;
+  const combatValue = groupStats?.totalCombatValue ?? unit?.combatValue;
 
   // Get sustained DPS - from groupStats or calculated from unit weapons
   const sustainedDps = isGroupMode
@@ -73,6 +74,7 @@ export const OverviewSection: React.FC<OverviewSectionProps> = ({
   const compareBuildCost = compareGroupStats?.totalBuildCost ?? compareUnit?.specs.economy.buildCost;
   const compareDps = compareGroupStats?.totalDps ?? compareUnit?.specs.combat.dps;
   const compareSalvoDamage = compareGroupStats?.totalSalvoDamage ?? compareUnit?.specs.combat.salvoDamage;
+  const compareCombatValue = compareGroupStats?.totalCombatValue ?? compareUnit?.combatValue;
   const compareSustainedDps = isGroupMode
     ? compareGroupStats?.totalSustainedDps
     : calculateUnitSustainedDps(compareUnit);
@@ -131,12 +133,13 @@ export const OverviewSection: React.FC<OverviewSectionProps> = ({
   const dpsDiff = isDifferent(dps, compareDps);
   const sustainedDpsDiff = isDifferent(sustainedDps, compareSustainedDps);
   const salvoDiff = isDifferent(salvoDamage, compareSalvoDamage);
+  const combatValueDiff = isDifferent(combatValue, compareCombatValue);
   const buildLocDiff = buildLocations.join(',') !== compareBuildLocations.join(',');
   const spawnDiff = !isGroupMode && unit?.specs.special?.spawnUnitOnDeath !== compareUnit?.specs.special?.spawnUnitOnDeath;
 
   // In diff mode with compare, check if we have any visible rows
   const hasAnyDifference = !showDifferencesOnly || !hasCompare ||
-    hpDiff || costDiff || rangeDiff || dpsDiff || sustainedDpsDiff || salvoDiff || buildLocDiff || spawnDiff;
+    hpDiff || costDiff || rangeDiff || dpsDiff || sustainedDpsDiff || salvoDiff || combatValueDiff || buildLocDiff || spawnDiff;
 
   if (!hasAnyDifference) {
     return null;
@@ -191,6 +194,22 @@ export const OverviewSection: React.FC<OverviewSectionProps> = ({
         />
       )}
 
+      {combatValue !== undefined && combatValue > 0 && showRow(combatValueDiff) && (
+        <StatRow
+          label={isGroupMode ? "Total combat value" : "Combat value"}
+          tooltip="Single combat power score from DPS, HP, range, speed and abilities; sums across a group"
+          value={
+            <ComparisonValue
+              value={Math.round(combatValue)}
+              compareValue={compareCombatValue !== undefined ? Math.round(compareCombatValue) : undefined}
+              comparisonType="higher-better"
+              formatDiff={(d) => Math.abs(d).toLocaleString()}
+              hideDiff={hideDiff}
+            />
+          }
+        />
+      )}
+
       {dps !== undefined && dps > 0 && showRow(hasSustainedDps ? sustainedDpsDiff : dpsDiff) && (
         <StatRow
           label={isGroupMode ? "Total DPS" : "DPS"}
@@ -0,0 +1,70 @@
+import { describe, it, expect } from 'vitest'
+import { decodeFactionIndex, type ProtoDescriptor } from '../protoIndex'
+
+describe('protoIndex', () => {
+  describe('decodeFactionIndex', () => {
+    const descriptor: ProtoDescriptor = {
+      root: 'Index',
+      messages: {
+        Index: [
+          { number: 1, name: 'layout', type: 'string', omitEmpty: true },
+          { number: 2, name: 'units', type: 'Entry', repeated: true },
+        ],
+        Entry: [
+          { number: 1, name: 'identifier', type: 'string' },
+          { number: 2, name: 'tier', type: 'int64' },
+          { number: 3, name: 'health', type: 'double', omitEmpty: true },
+          { number: 4, name: 'buildable', type: 'bool', optional: true, omitEmpty: true },
+          { number: 5, name: 'layers', type: 'int64', repeated: true },
+          { number: 6, name: 'tags', type: 'string', repeated: true },
+        ],
+      },
+    }
+
+    it('decodes strings, varints, doubles, packed and repeated fields', () => {
+      const entry = [
+        0x0a, 4, ...'tank'.split('').map(c => c.charCodeAt(0)), // identifier
+        0x10, 2, // tier
+        0x19, 0, 0, 0, 0, 0, 0, 0x59, 0x40, // health 100
+        0x20, 0, // explicit false
+        0x2a, 2, 1, 3, // packed layers
+        0x32, 1, 0x61, 0x32, 1, 0x62, // tags
+      ]
+      const bytes = new Uint8Array([0x12, entry.length, ...entry])
+
+      expect(decodeFactionIndex(bytes, descriptor)).toEqual({
+        units: [{ identifier: 'tank', tier: 2, health: 100, buildable: false, layers: [1, 3], tags: ['a', 'b'] }],
+      })
+    })
+
+    it('fills zero values units.json would carry and skips unknown fields', () => {
+      const entry = [0x0a, 1, 0x78, 0x78, 7] // identifier "x", unknown field 15
+      const bytes = new Uint8Array([0x12, entry.length, ...entry])
+
+      expect(decodeFactionIndex(bytes, descriptor)).toEqual({
+        units: [{ identifier: 'x', tier: 0, layers: [], tags: [] }],
+      })
+    })
+
+    it('decodes negative int64 values', () => {
+      const entry = [0x10, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01] // tier -1
+      const bytes = new Uint8Array([0x12, entry.length, ...entry])
+
+      expect(decodeFactionIndex(bytes, descriptor).units[0]).toMatchObject({ tier: -1 })
+    })
+
+    it('rejects truncated input', () => {
+      expect(() => decodeFactionIndex(new Uint8Array([0x12, 10, 0x0a]), descriptor)).toThrow('truncated')
+    })
+
+    it('reads the generated descriptor by default', () => {
+      // units: [{ identifier: "tank" }]
+      const entry = [0x0a, 4, ...'tank'.split('').map(c => c.charCodeAt(0))]
+      const index = decodeFactionIndex(new Uint8Array([0x12, entry.length, ...entry]))
+
+      expect(index.units).toHaveLength(1)
+      expect(index.units[0].identifier).toBe('tank')
+      expect(index.units[0].unitTypes).toEqual([])
+    })
+  })
+})
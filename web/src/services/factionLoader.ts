@@ -9,6 +9,7 @@
 
 import JSZip from 'jszip'
 import type { FactionMetadata, FactionIndex } from '@/types/faction'
+import { decodeFactionIndex } from './protoIndex'
 import {
   getLocalFactionIds,
   getLocalFactionMetadata,
@@ -180,15 +181,21 @@ async function downloadAndExtractFaction(
   const metadataText = await metadataFile.async('string')
   const metadata: FactionMetadata = JSON.parse(metadataText)
 
-  // Find units.json
-  const unitsFile = zip.file('units.json')
-  if (!unitsFile) {
-    throw new Error('units.json not found in faction zip')
+  // Prefer units.pb (written with --format pb): it decodes much faster than
+  // the multi-MB units.json on mobile
+  let index: FactionIndex
+  const binaryFile = zip.file('units.pb')
+  if (binaryFile) {
+    index = decodeFactionIndex(await binaryFile.async('uint8array'))
+  } else {
+    const unitsFile = zip.file('units.json')
+    if (!unitsFile) {
+      throw new Error('units.json not found in faction zip')
+    }
+    const unitsText = await unitsFile.async('string')
+    index = JSON.parse(unitsText)
   }
 
-  const unitsText = await unitsFile.async('string')
-  const index: FactionIndex = JSON.parse(unitsText)
-
   // Extract assets
   const assets = new Map<string, Blob>()
   const assetsPath = 'assets/'
@@ -0,0 +1,208 @@
+/**
+ * Binary Faction Index Decoder
+ *
+ * Decodes units.pb, the protobuf faction index written by
+ * `pa-pedia describe-faction --format pb`, into the same FactionIndex that
+ * units.json holds. Messages are read with the descriptor the CLI generates
+ * next to faction-index.proto (`just generate-schema`), so no protoc step or
+ * protobuf runtime is needed and the decoder follows model changes.
+ */
+
+import type { FactionIndex } from '@/types/faction'
+import factionIndexDescriptor from '../../../schema/faction-index.pb.json'
+
+export interface ProtoField {
+  number: number
+  /** JSON property name */
+  name: string
+  /** string, bool, int64, double or a message name */
+  type: string
+  repeated?: boolean
+  /** A pointer in the Go model: left out when absent from the wire */
+  optional?: boolean
+  /** JSON omits the field when it is zero */
+  omitEmpty?: boolean
+}
+
+export interface ProtoDescriptor {
+  root: string
+  messages: Record<string, ProtoField[]>
+}
+
+const WIRE_VARINT = 0
+const WIRE_FIXED64 = 1
+const WIRE_BYTES = 2
+const WIRE_FIXED32 = 5
+
+class Reader {
+  private pos = 0
+  private readonly view: DataView
+  private readonly bytes: Uint8Array
+
+  constructor(bytes: Uint8Array) {
+    this.bytes = bytes
+    this.view = new DataView(bytes.buffer, bytes.byteOffset, bytes.byteLength)
+  }
+
+  done(): boolean {
+    return this.pos >= this.bytes.length
+  }
+
+  varint(): bigint {
+    let result = 0n
+    for (let shift = 0n; shift < 70n; shift += 7n) {
+      if (this.pos >= this.bytes.length) {
+        throw new Error('units.pb: truncated varint')
+      }
+      const b = this.bytes[this.pos++]
+      result |= BigInt(b & 0x7f) << shift
+      if (b < 0x80) {
+        return result
+      }
+    }
+    throw new Error('units.pb: malformed varint')
+  }
+
+  double(): number {
+    this.need(8)
+    const value = this.view.getFloat64(this.pos, true)
+    this.pos += 8
+    return value
+  }
+
+  bytesField(): Uint8Array {
+    const length = Number(this.varint())
+    this.need(length)
+    const value = this.bytes.subarray(this.pos, this.pos + length)
+    this.pos += length
+    return value
+  }
+
+  skip(wireType: number): void {
+    switch (wireType) {
+      case WIRE_VARINT:
+        this.varint()
+        return
+      case WIRE_FIXED64:
+        this.need(8)
+        this.pos += 8
+        return
+      case WIRE_FIXED32:
+        this.need(4)
+        this.pos += 4
+        return
+      case WIRE_BYTES:
+        this.bytesField()
+        return
+    }
+    throw new Error(`units.pb: unsupported wire type ${wireType}`)
+  }
+
+  private need(n: number): void {
+    if (this.pos + n > this.bytes.length) {
+      throw new Error('units.pb: truncated value')
+    }
+  }
+}
+
+const textDecoder = new TextDecoder()
+
+/** Int64 fields are written as two's complement varints; JS numbers hold every real value */
+function toInt(value: bigint): number {
+  return Number(BigInt.asIntN(64, value))
+}
+
+function zeroValue(field: ProtoField, descriptor: ProtoDescriptor): unknown {
+  if (field.repeated) return []
+  switch (field.type) {
+    case 'string':
+      return ''
+    case 'bool':
+      return false
+    case 'int64':
+    case 'double':
+      return 0
+  }
+  return decodeMessage(new Uint8Array(), field.type, descriptor)
+}
+
+function decodeScalar(reader: Reader, type: string): unknown {
+  switch (type) {
+    case 'string':
+      return textDecoder.decode(reader.bytesField())
+    case 'bool':
+      return reader.varint() !== 0n
+    case 'int64':
+      return toInt(reader.varint())
+    case 'double':
+      return reader.double()
+  }
+  throw new Error(`units.pb: unsupported type ${type}`)
+}
+
+/** Decodes one message into its JSON object form */
+function decodeMessage(bytes: Uint8Array, name: string, descriptor: ProtoDescriptor): Record<string, unknown> {
+  const fields = descriptor.messages[name]
+  if (!fields) {
+    throw new Error(`units.pb: unknown message ${name}`)
+  }
+  const byNumber = new Map(fields.map(f => [f.number, f]))
+  const result: Record<string, unknown> = {}
+  const reader = new Reader(bytes)
+
+  while (!reader.done()) {
+    const key = reader.varint()
+    const number = Number(key >> 3n)
+    const wireType = Number(key & 7n)
+    const field = byNumber.get(number)
+    if (!field) {
+      // Written by a newer CLI; skipped like the Go decoder does
+      reader.skip(wireType)
+      continue
+    }
+
+    const isMessage = descriptor.messages[field.type] !== undefined
+    let values: unknown[]
+    if (isMessage) {
+      values = [decodeMessage(reader.bytesField(), field.type, descriptor)]
+    } else if (field.repeated && wireType === WIRE_BYTES && field.type !== 'string') {
+      // Packed scalars
+      const packed = new Reader(reader.bytesField())
+      values = []
+      while (!packed.done()) {
+        values.push(decodeScalar(packed, field.type))
+      }
+    } else {
+      values = [decodeScalar(reader, field.type)]
+    }
+
+    if (field.repeated) {
+      const list = (result[field.name] as unknown[] | undefined) ?? []
+      list.push(...values)
+      result[field.name] = list
+    } else {
+      result[field.name] = values[0]
+    }
+  }
+
+  // Zero values aren't written; restore the ones units.json would carry
+  for (const field of fields) {
+    if (field.name in result || field.optional) continue
+    // encoding/json ignores omitempty on struct values
+    const isStruct = !field.repeated && descriptor.messages[field.type] !== undefined
+    if (field.omitEmpty && !isStruct) continue
+    result[field.name] = zeroValue(field, descriptor)
+  }
+  return result
+}
+
+/**
+ * Decodes units.pb into a FactionIndex. The descriptor defaults to the one
+ * generated from the current models.
+ */
+export function decodeFactionIndex(
+  bytes: Uint8Array,
+  descriptor: ProtoDescriptor = factionIndexDescriptor as ProtoDescriptor
+): FactionIndex {
+  return decodeMessage(bytes, descriptor.root, descriptor) as unknown as FactionIndex
+}
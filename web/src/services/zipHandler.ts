@@ -1,6 +1,7 @@
 import JSZip from 'jszip'
 import DOMPurify from 'dompurify'
 import type { FactionMetadata, FactionIndex, Unit, UnitIndexEntry } from '@/types/faction'
+import { decodeFactionIndex } from './protoIndex'
 
 export interface ParsedFaction {
   factionId: string
@@ -158,17 +159,23 @@ export async function parseFactionZip(
       }
     }
 
-    // Parse units index
+    // Parse units index, preferring the smaller binary index when the
+    // faction was exported with --format pb
     let index: FactionIndex
+    const binaryFile = zip.file(`${rootPath}units.pb`)
     try {
-      const indexText = await unitsFile.async('string')
-      index = JSON.parse(indexText)
+      if (binaryFile) {
+        index = decodeFactionIndex(await binaryFile.async('uint8array'))
+      } else {
+        const indexText = await unitsFile.async('string')
+        index = JSON.parse(indexText)
+      }
     } catch {
       return {
         success: false,
         error: {
           type: 'invalid-json',
-          message: 'Failed to parse units.json',
+          message: binaryFile ? 'Failed to decode units.pb' : 'Failed to parse units.json',
         },
       }
     }
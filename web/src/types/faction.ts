@@ -1,5 +1,8 @@
 /* Type definitions for PA-Pedia faction data */
 
+/** Third-party fields (`x-` prefixed) that tools may add to units and metadata */
+export type ExtensionFields = { [key: `x-${string}`]: unknown };
+
 /** Default faction team-paint colour pair (hex) for the 3D model viewer. */
 export interface TeamColors {
   primary: string;
@@ -7,7 +10,8 @@ export interface TeamColors {
 }
 
 // Faction Metadata
-export interface FactionMetadata {
+export interface FactionMetadata extends ExtensionFields {
+  $schema?: string;
   identifier: string;
   displayName: string;
   version: string;
@@ -15,6 +19,8 @@ export interface FactionMetadata {
   description?: string;
   dateCreated?: string;
   build?: string;
+  /** Build of the PA installation the data was extracted from */
+  paBuild?: string;
   type: 'base-game' | 'mod';
   mods?: string[];
   backgroundImage?: string;
@@ -28,12 +34,54 @@ export interface FactionMetadata {
    * Absent → the viewer falls back to a neutral pair.
    */
   teamColors?: TeamColors;
+  /**
+   * IPFS CID of the folder published with `--publish ipfs`.
+   * Written after publishing, so the snapshot behind the CID lacks this field.
+   */
+  ipfsCid?: string;
+  /**
+   * Game files left out of the export: `icons-stripped` swaps game icons for
+   * generated placeholders, `none` also omits the copied spec JSON and the
+   * background image. Absent → a full export.
+   */
+  assets?: 'icons-stripped' | 'none';
+  /** Commit each GitHub mod source was downloaded at (`--pin-github-refs`) */
+  githubCommits?: GitHubCommit[];
+  /**
+   * Hex SHA-256 of units.json in canonical form (compact, unpruned, no
+   * `$schema`). Equal hashes mean a re-export produced the same units.
+   */
+  contentHash?: string;
+  /** Version and source of each mod the faction was extracted from, in priority order */
+  modVersions?: ModVersion[];
+}
+
+export interface GitHubCommit {
+  /** GitHub mod source as listed in mods */
+  url: string;
+  /** Branch, tag or SHA named by the URL (main when it names none) */
+  ref: string;
+  /** Full commit SHA */
+  commit: string;
+}
+
+export interface ModVersion {
+  /** Mod identifier from modinfo.json */
+  identifier: string;
+  /** Mod version from modinfo.json */
+  version?: string;
+  /** Where the mod was found */
+  source?: 'server_mods' | 'client_mods' | 'download' | 'github';
+  /** Commit a GitHub mod was downloaded at (`--pin-github-refs`) */
+  commit?: string;
 }
 
 // Faction Index
 export interface UnitFile {
   path: string;
   source: string;
+  /** Generated during export (placeholder icon for a unit without buildbar art) */
+  generated?: boolean;
 }
 
 export interface UnitIndexEntry {
@@ -43,12 +91,32 @@ export interface UnitIndexEntry {
   source: string;
   files: UnitFile[];
   unit: Unit;
+  warnings?: string[];
 }
 
 export interface FactionIndex {
+  $schema?: string;
+  layout?: 'mirrored' | 'flat';
   units: UnitIndexEntry[];
 }
 
+// .pafaction bundle manifest (manifest.json at the bundle root)
+export interface BundleFile {
+  path: string;
+  size: number;
+  sha256: string;
+}
+
+export interface BundleManifest {
+  /** Version of the bundle container format, not the faction data version */
+  formatVersion: number;
+  identifier: string;
+  displayName: string;
+  version: string;
+  createdBy?: string;
+  files: BundleFile[];
+}
+
 // Unit specifications (resolved data)
 export interface Resources {
   metal?: number;
@@ -75,6 +143,24 @@ export interface Ammo {
   burnRadius?: number;
   /** Duration of burn effect in seconds */
   burnDuration?: number;
+  /** Effect specs and audio cues of the projectile */
+  effects?: AmmoEffects;
+}
+
+/** Names of PA effect specs (.pfx) and audio cues, not the files themselves */
+export interface AmmoEffects {
+  /** Trail effect spec (fx_trail) */
+  trail?: string;
+  /** Beam effect spec (fx_beam_spec) */
+  beam?: string;
+  /** Effect spec played where a beam hits (fx_collision_spec) */
+  collision?: string;
+  /** Effect specs played when the projectile dies (its impact or explosion) */
+  impact?: string[];
+  /** Audio cue played when the projectile dies */
+  impactAudio?: string;
+  /** Audio cue looped while the projectile flies (audio_loop) */
+  flightAudio?: string;
 }
 
 export interface Weapon {
@@ -103,6 +189,10 @@ export interface Weapon {
   selfDestruct?: boolean;
   /** Weapon triggers on unit death (e.g., commander nuke, titan explosions) */
   deathExplosion?: boolean;
+  /** Whether the weapon is active; only present when the spec sets it (disabled weapons are excluded from unit DPS) */
+  enabled?: boolean;
+  /** Weapon is off until a unit ability switches it on (excluded from unit DPS) */
+  requiresToggle?: boolean;
   ammoSource?: string;
   ammoDemand?: number;
   ammoPerShot?: number;
@@ -123,6 +213,51 @@ export interface Weapon {
   ammoDetails?: Ammo;
   /** Available ammo types that can be built for this weapon (factory weapons only) */
   buildableAmmo?: Ammo[];
+  /** Approximate damage against distance from the impact point for splash weapons (the directly hit target first, then the splash falloff) */
+  damageFalloff?: FalloffPoint[];
+  /** What the first target priority goes for: anti-fabber (builders and commanders), anti-air-first or structure-first */
+  targetPriorityTags?: TargetPriorityTag[];
+  /** How the player fires the weapon when it isn't fired automatically (uber cannons and nukes on command; self-destruct charges) */
+  manualFire?: ManualFire;
+  /** PA resource paths of the projectiles the weapon shoots down (anti_entity_targets, e.g. nuke missiles for an anti-nuke) */
+  antiEntityTargets?: string[];
+  /** Effect specs and audio cues the unit plays when this weapon fires */
+  effects?: WeaponEffects;
+}
+
+/** From the unit's fired and fired<tool index> events; projectile effects are on the ammo */
+export interface WeaponEffects {
+  /** Muzzle flash effect specs (.pfx) */
+  muzzleFlash?: string[];
+  /** Audio cue played on firing, e.g. /SE/Weapons/tank/tank_fire */
+  fireAudio?: string;
+}
+
+export type TargetPriorityTag = 'anti-fabber' | 'anti-air-first' | 'structure-first';
+
+export interface ManualFire {
+  /** What fires the weapon: a fire order (manual_fire) or the unit's self-destruct order */
+  trigger: 'command' | 'self-destruct';
+  /** Each shot needs a missile built first (factory-sourced ammo) */
+  requiresAmmoBuild?: boolean;
+  /** Metal to build one missile */
+  ammoMetalCost?: number;
+  /** Energy the unit's build arms use while building one missile */
+  ammoEnergyCost?: number;
+  /** Seconds to build one missile with the unit's own build arms */
+  ammoBuildTime?: number;
+  /** Built missiles the unit can hold ready to fire */
+  ammoStorage?: number;
+}
+
+export interface FalloffPoint {
+  distance: number;
+  /** Damage per projectile to a unit at this distance */
+  damage: number;
+  /** Damage per second at this distance (damage x rate of fire x projectiles per fire) */
+  dps: number;
+  /** The directly hit target, which takes direct and splash damage */
+  directHit?: boolean;
 }
 
 export interface CombatSpecs {
@@ -155,6 +290,14 @@ export interface EconomySpecs {
   energyRate?: number;
   buildArms?: BuildArm[];
   buildRange?: number;
+  assist?: AssistEconomy[];
+}
+
+export interface AssistEconomy {
+  factory: string;
+  metal: number;
+  energy: number;
+  buildInefficiency: number;
 }
 
 export interface MobilitySpecs {
@@ -177,6 +320,10 @@ export interface ReconSpecs {
 export interface StorageSpecs {
   unitStorage?: number;
   storedUnitType?: string;
+  initialBuildSpec?: string;
+  initialBuildCost?: number;
+  spawnInterval?: number;
+  spawnMetalRate?: number;
 }
 
 export interface SpecialSpecs {
@@ -184,6 +331,22 @@ export interface SpecialSpecs {
   amphibious?: boolean;
   hover?: boolean;
   spawnUnitOnDeath?: string;
+  healthRegen?: number;
+  shieldHealth?: number;
+  shieldRecharge?: number;
+  shieldRechargeDelay?: number;
+}
+
+export type SizeClass = 'small' | 'medium' | 'large' | 'titan';
+
+export interface SizeSpecs {
+  width?: number;
+  length?: number;
+  height?: number;
+  footprintWidth?: number;
+  footprintLength?: number;
+  selectionDiameter?: number;
+  sizeClass: SizeClass;
 }
 
 export interface UnitSpecs {
@@ -193,6 +356,19 @@ export interface UnitSpecs {
   recon?: ReconSpecs;
   storage?: StorageSpecs;
   special?: SpecialSpecs;
+  size?: SizeSpecs;
+}
+
+export type ReachabilityMethod = 'commander' | 'built' | 'factorySpawn' | 'spawnedOnDeath' | 'projectile';
+
+export interface Reachability {
+  method: ReachabilityMethod;
+  via?: string;
+}
+
+export interface TechPath {
+  path: string[];
+  factoryCost: number;
 }
 
 export interface BuildRelationships {
@@ -200,7 +376,38 @@ export interface BuildRelationships {
   builds?: string[];
 }
 
-export interface Unit {
+/** Node of a parsed buildable_types expression: a unit type leaf or an operator */
+export interface RestrictionNode {
+  op: 'type' | 'and' | 'or' | 'minus';
+  /** Unit type for type nodes (empty matches nothing) */
+  type?: string;
+  left?: RestrictionNode;
+  /** For minus, the types to exclude */
+  right?: RestrictionNode;
+}
+
+/** Build bar tab, in in-game tab order */
+export type BuildMenuCategory =
+  | 'factory'
+  | 'combat'
+  | 'utility'
+  | 'vehicle'
+  | 'bot'
+  | 'air'
+  | 'sea'
+  | 'orbital'
+
+/** One tab of a builder's build menu, with a row per tier */
+export interface BuildMenuGroup {
+  category: BuildMenuCategory;
+  tiers: {
+    tier: number;
+    /** Unit IDs sorted by display name */
+    units: string[];
+  }[];
+}
+
+export interface Unit extends ExtensionFields {
   id: string;
   resourceName: string;
   displayName: string;
@@ -210,10 +417,71 @@ export interface Unit {
   unitTypes: string[];
   accessible: boolean;
   baseTemplate?: boolean;
+  reachability?: Reachability;
+  techPath?: TechPath;
   specs: UnitSpecs;
   buildRelationships?: BuildRelationships;
+  /** Builds laid out like the in-game build bar (set at export time) */
+  buildMenu?: BuildMenuGroup[];
   buildableTypes?: string;
+  /** buildableTypes parsed into an expression tree (precedence already applied) */
+  buildRestriction?: RestrictionNode;
   assistBuildableOnly?: boolean;
+  /** Combat power score, summable across a group (set at export time; absent for unarmed units) */
+  combatValue?: number;
+  /**
+   * PA localization key of displayName: the key of `!LOC(key):text`, or the
+   * English text for `!LOC:text`. Absent when the name isn't localized.
+   */
+  displayNameKey?: string;
+  /** Localization key of the unit's description field, in the same form */
+  descriptionKey?: string;
+  /** Effect specs and audio cues the unit plays when it dies */
+  effects?: UnitEffects;
+}
+
+export interface UnitEffects {
+  /** Effect specs played when the unit dies (.pfx) */
+  death?: string[];
+  /** Audio cue played when the unit dies */
+  deathAudio?: string;
+}
+
+/** A unit's stats across faction versions, written by `pa-pedia history --output` */
+export interface UnitHistory {
+  faction: string;
+  unit: string;
+  displayName: string;
+  /** Oldest version first */
+  versions: {
+    version: string;
+    paBuild?: string;
+    present: boolean;
+  }[];
+  stats: {
+    /** Dotted unit field, e.g. specs.combat.health */
+    stat: string;
+    /** One value per version; null where the unit or stat is absent */
+    values: (number | null)[];
+    changed?: boolean;
+  }[];
+}
+
+/**
+ * cross-faction.json in an addon export: build relationships between the
+ * addon's units and base faction units, which aren't in the addon's units.json
+ */
+export interface CrossFactionLinks {
+  baseFactions: string[];
+  edges: {
+    /** Addon unit ID */
+    unit: string;
+    /** builtBy: baseUnit builds unit; builds: unit builds baseUnit */
+    relation: 'builtBy' | 'builds';
+    baseUnit: string;
+    /** Display name of baseUnit's faction; absent if its unit types name none */
+    baseFaction?: string;
+  }[];
 }
 
 // Extended types for app usage
@@ -73,6 +73,8 @@ export interface AggregatedGroupStats {
   totalBuildRate: number
   /** Total energy consumed during construction (tool consumption) */
   totalToolEnergyConsumption: number
+  /** Total combat value (sum of each unit's exported combatValue) */
+  totalCombatValue: number
 
   // ===== MIN stats (slowest limits group) =====
   /** Minimum movement speed (slowest unit) */
@@ -142,6 +142,22 @@ describe('groupAggregation', () => {
       expect(result!.distinctUnitTypes).toBe(2)
     })
 
+    it('should sum combat value, treating units without one as 0', () => {
+      const tank = createMockUnit({ id: 'tank', combatValue: 160 })
+      const fabber = createMockUnit({ id: 'fabber' })
+
+      const members: GroupMember[] = [
+        { factionId: 'MLA', unitId: 'tank', quantity: 3 },
+        { factionId: 'MLA', unitId: 'fabber', quantity: 2 },
+      ]
+      const getUnit = (_factionId: string, unitId: string) =>
+        unitId === 'tank' ? tank : unitId === 'fabber' ? fabber : undefined
+
+      const result = aggregateGroupStats(members, getUnit)
+
+      expect(result!.totalCombatValue).toBe(480)
+    })
+
     it('should calculate MIN for mobility stats', () => {
       const fastUnit = createMockUnit({
         id: 'fast',
@@ -1,4 +1,13 @@
-import type { Unit } from '@/types/faction'
+import type { Unit, Weapon } from '@/types/faction'
+
+/**
+ * Whether a weapon is part of a unit's ongoing firepower. Death and self-destruct
+ * weapons fire once; disabled and ability-toggled weapons are off by default.
+ */
+export function countsTowardDps(weapon: Weapon): boolean {
+  return !weapon.selfDestruct && !weapon.deathExplosion &&
+    !weapon.requiresToggle && weapon.enabled !== false
+}
 
 /**
  * Calculate the effective DPS for a unit, accounting for ammo-limited weapons.
@@ -16,14 +25,14 @@ export function getEffectiveUnitDps(unit: Unit): number | undefined {
   if (!weapons?.length) return burstDps
 
   const hasSustainedWeapons = weapons.some(
-    w => !w.selfDestruct && !w.deathExplosion &&
+    w => countsTowardDps(w) &&
          w.sustainedDps !== undefined && w.sustainedDps !== w.dps
   )
 
   if (!hasSustainedWeapons) return burstDps
 
   return weapons.reduce((sum, w) => {
-    if (w.selfDestruct || w.deathExplosion) return sum
+    if (!countsTowardDps(w)) return sum
     return sum + (w.sustainedDps ?? w.dps ?? 0) * (w.count ?? 1)
   }, 0)
 }
@@ -156,6 +156,7 @@ export function aggregateGroupStats(
   let totalEnergyStorage = 0
   let totalBuildRate = 0
   let totalToolEnergyConsumption = 0
+  let totalCombatValue = 0
 
   // Initialize MIN aggregators (undefined until first valid value)
   let minMoveSpeed: number | undefined
@@ -209,6 +210,7 @@ export function aggregateGroupStats(
     totalEnergyStorage += (specs.economy.storage?.energy ?? 0) * qty
     totalBuildRate += (specs.economy.buildRate ?? 0) * qty
     totalToolEnergyConsumption += (specs.economy.toolConsumption?.energy ?? 0) * qty
+    totalCombatValue += (unit.combatValue ?? 0) * qty
 
     // MIN aggregations (only applies once per unit type, not per quantity)
     // Group speed is limited by slowest unit, regardless of how many
@@ -307,6 +309,7 @@ export function aggregateGroupStats(
     totalEnergyStorage,
     totalBuildRate,
     totalToolEnergyConsumption,
+    totalCombatValue,
     minMoveSpeed,
     minAcceleration,
     minBrake,
@@ -1,5 +1,6 @@
 import type { Weapon } from '@/types/faction'
 import type { AggregatedWeapon } from '@/types/group'
+import { countsTowardDps } from '@/utils/effectiveDps'
 
 /** Mapping from PA internal target layer names to human-readable display names */
 export const TARGET_LAYER_DISPLAY_NAMES: Record<string, string> = {
@@ -53,7 +54,7 @@ export function calculateDpsByLayer(weapons: Weapon[] | undefined): DpsByLayer {
   const result: DpsByLayer = {}
 
   for (const weapon of weapons) {
-    if (weapon.selfDestruct || weapon.deathExplosion) continue
+    if (!countsTowardDps(weapon)) continue
     if (!weapon.targetLayers || weapon.targetLayers.length === 0) continue
 
     const count = weapon.count ?? 1